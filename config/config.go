@@ -4,6 +4,7 @@ import (
     "log"
     "os"
     "strconv"
+    "time"
 
     "github.com/joho/godotenv"
 )
@@ -18,8 +19,55 @@ type Config struct {
     DBSSLMode  string
 
     // JWT
-    JWTSecret string
-    JWTExpire int
+    AccessTokenMinutes int // lifetime of an access token
+    RefreshTokenHours  int // a session idle for longer than this must sign in again
+    SessionMaxDays     int // absolute session lifetime, however often it is refreshed
+    JWTIssuer          string
+    JWTAudience        string
+    JWTAlgorithm       string // RS256 or EdDSA, used for newly generated signing keys
+
+    // Encrypts signing private keys at rest when set
+    SigningKeyEncryptionKey string
+
+    // Two-factor authentication
+    MFAIssuer         string // account label shown in authenticator apps
+    MFARequiredRoles  string // comma-separated roles that must pass a second factor
+    MFAEncryptionKey  string // encrypts TOTP secrets at rest when set
+    TrustedDeviceDays int    // how long a remembered device skips the second factor
+
+    // Sign-in protection
+    LoginCaptchaAfter         int    // failures on an account or address before a CAPTCHA is required
+    LoginLockoutThreshold     int    // account failures before a temporary lockout
+    LoginLockoutMinutes       int
+    LoginIPMaxFailures        int    // failures from one address per window before it is blocked
+    LoginFailureWindowMinutes int
+    CaptchaSecret             string // reCAPTCHA, hCaptcha or Turnstile secret; CAPTCHAs are not enforced when empty
+    CaptchaVerifyURL          string
+
+    // Onboarding
+    EmailVerificationHours int // lifetime of the signed link sent to confirm an address
+    InvitationHours        int // lifetime of an invitation link
+
+    // Single sign-on
+    GoogleClientID     string // the built-in Google provider is offered when both are set
+    GoogleClientSecret string
+    GoogleRedirectURL  string
+    SSOEncryptionKey   string // encrypts identity provider client secrets at rest when set
+    SAMLCertFile       string // optional service provider certificate and key, published in SAML
+    SAMLKeyFile        string // metadata and used to decrypt encrypted assertions
+
+    // Password policy
+    PasswordMinLength     int
+    PasswordHistoryCount  int    // previous passwords that may not be reused; 0 allows reuse
+    BreachedPasswordsPath string // k-anonymity range directory or hash list file; the check is off when empty
+    PasswordResetMinutes  int    // lifetime of a password reset link
+
+    // API keys
+    APIKeyRateLimit  int // requests per minute for keys created without their own limit
+    APIKeyExpiryDays int // lifetime of keys created without an expiry; 0 keeps them until revoked
+
+    AppURL      string // where this API is served
+    FrontendURL string // links in emails point here
 
     // SMTP
     SMTPServer   string
@@ -27,6 +75,26 @@ type Config struct {
     SMTPLogin    string
     SMTPPassword string
     SMTPFrom     string
+
+    // School attendance
+    AttendanceCutoffHours int
+    SchoolTimezone        string // IANA zone registers lock in, such as Africa/Lagos
+
+    // Refunds
+    RefundFullWindowDays int
+
+    // Payment gateways
+    StripeAPIKey      string
+    PaystackSecretKey string
+
+    // Store checkout
+    StoreCurrency         string
+    TaxRatePercent        float64
+    ShippingFlatFee       float64
+    FreeShippingThreshold float64
+
+    // Media
+    FFProbePath string
 }
 
 func LoadEnv() *Config {
@@ -37,55 +105,111 @@ func LoadEnv() *Config {
         log.Println("⚠️ No .env file found, falling back to system environment")
     }
 
-    // Parse DB port
-    dbPortStr := os.Getenv("DB_PORT")
-    if dbPortStr == "" {
-        dbPortStr = "5432"
-    }
-    dbPort, err := strconv.Atoi(dbPortStr)
-    if err != nil {
-        log.Fatalf("❌ Invalid DB_PORT: %v", err)
+    // Validate the school time zone registers lock in
+    schoolTimezone := getEnv("SCHOOL_TIMEZONE", "UTC")
+    if _, err := time.LoadLocation(schoolTimezone); err != nil {
+        log.Fatalf("❌ Invalid SCHOOL_TIMEZONE: %v", err)
     }
 
-    // Parse SMTP port
-    smtpPortStr := os.Getenv("SMTP_PORT")
-    if smtpPortStr == "" {
-        smtpPortStr = "587"
-    }
-    smtpPort, err := strconv.Atoi(smtpPortStr)
+    // Parse store tax and shipping defaults
+    taxRate, err := strconv.ParseFloat(getEnv("TAX_RATE_PERCENT", "0"), 64)
     if err != nil {
-        log.Fatalf("❌ Invalid SMTP_PORT: %v", err)
+        log.Fatalf("❌ Invalid TAX_RATE_PERCENT: %v", err)
     }
-
-    // Parse JWT expiration hours
-    jwtExpStr := os.Getenv("JWT_EXPIRATION_HOURS")
-    if jwtExpStr == "" {
-        jwtExpStr = "72"
+    shippingFee, err := strconv.ParseFloat(getEnv("SHIPPING_FLAT_FEE", "0"), 64)
+    if err != nil {
+        log.Fatalf("❌ Invalid SHIPPING_FLAT_FEE: %v", err)
     }
-    jwtExp, err := strconv.Atoi(jwtExpStr)
+    freeShipping, err := strconv.ParseFloat(getEnv("FREE_SHIPPING_THRESHOLD", "0"), 64)
     if err != nil {
-        log.Fatalf("❌ Invalid SMTP_PORT: %v", err)
+        log.Fatalf("❌ Invalid FREE_SHIPPING_THRESHOLD: %v", err)
     }
 
     return &Config{
         // DB
         DBHost:     getEnv("DB_HOST", "localhost"),
-        DBPort:     dbPort,
+        DBPort:     getEnvInt("DB_PORT", 5432),
         DBUser:     getEnv("DB_USER", "postgres"),
         DBPassword: getEnv("DB_PASSWORD", ""),
         DBName:     getEnv("DB_NAME", "go_crm"),
         DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
 
         // JWT
-        JWTSecret: getEnv("JWT_SECRET", "default_secret"),
-        JWTExpire: jwtExp,
+        AccessTokenMinutes: getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15),
+        RefreshTokenHours:  getEnvInt("REFRESH_TOKEN_TTL_HOURS", 72),
+        SessionMaxDays:     getEnvInt("SESSION_MAX_LIFETIME_DAYS", 30),
+        JWTIssuer:          getEnv("JWT_ISSUER", "crm-go"),
+        JWTAudience:        getEnv("JWT_AUDIENCE", "crm-go"),
+        JWTAlgorithm:       getEnv("JWT_SIGNING_ALG", "RS256"),
+
+        SigningKeyEncryptionKey: getEnv("SIGNING_KEY_ENCRYPTION_KEY", ""),
+
+        // Two-factor authentication
+        MFAIssuer:         getEnv("MFA_ISSUER", "CRM Go"),
+        MFARequiredRoles:  getEnv("MFA_REQUIRED_ROLES", "admin"),
+        MFAEncryptionKey:  getEnv("MFA_ENCRYPTION_KEY", ""),
+        TrustedDeviceDays: getEnvInt("MFA_TRUSTED_DEVICE_DAYS", 30),
+
+        // Sign-in protection
+        LoginCaptchaAfter:         getEnvInt("LOGIN_CAPTCHA_AFTER", 3),
+        LoginLockoutThreshold:     getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+        LoginLockoutMinutes:       getEnvInt("LOGIN_LOCKOUT_MINUTES", 15),
+        LoginIPMaxFailures:        getEnvInt("LOGIN_IP_MAX_FAILURES", 50),
+        LoginFailureWindowMinutes: getEnvInt("LOGIN_FAILURE_WINDOW_MINUTES", 15),
+        CaptchaSecret:             getEnv("CAPTCHA_SECRET", ""),
+        CaptchaVerifyURL:          getEnv("CAPTCHA_VERIFY_URL", "https://www.google.com/recaptcha/api/siteverify"),
+
+        // Onboarding
+        EmailVerificationHours: getEnvInt("EMAIL_VERIFICATION_TTL_HOURS", 48),
+        InvitationHours:        getEnvInt("INVITATION_TTL_HOURS", 168),
+
+        // Single sign-on
+        GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
+        GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+        GoogleRedirectURL:  getEnv("GOOGLE_REDIRECT_URL", getEnv("APP_URL", "http://localhost:8080")+"/auth/google/callback"),
+        SSOEncryptionKey:   getEnv("SSO_ENCRYPTION_KEY", ""),
+        SAMLCertFile:       getEnv("SAML_SP_CERT_FILE", ""),
+        SAMLKeyFile:        getEnv("SAML_SP_KEY_FILE", ""),
+
+        // Password policy
+        PasswordMinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+        PasswordHistoryCount:  getEnvInt("PASSWORD_HISTORY_COUNT", 5),
+        BreachedPasswordsPath: getEnv("BREACHED_PASSWORDS_PATH", ""),
+        PasswordResetMinutes:  getEnvInt("PASSWORD_RESET_TTL_MINUTES", 15),
+
+        // API keys
+        APIKeyRateLimit:  getEnvInt("API_KEY_RATE_LIMIT", 120),
+        APIKeyExpiryDays: getEnvInt("API_KEY_EXPIRY_DAYS", 365),
+
+        AppURL:      getEnv("APP_URL", "http://localhost:8080"),
+        FrontendURL: getEnv("FRONTEND_URL", getEnv("APP_URL", "http://localhost:8080")),
 
         // SMTP
         SMTPServer:   getEnv("SMTP_SERVER", "smtp-relay.brevo.com"),
-        SMTPPort:     smtpPort,
+        SMTPPort:     getEnvInt("SMTP_PORT", 587),
         SMTPLogin:    getEnv("SMTP_LOGIN", ""),
         SMTPPassword: getEnv("SMTP_PASSWORD", ""),
         SMTPFrom:     getEnv("FROM_EMAIL", ""),
+
+        // School attendance
+        AttendanceCutoffHours: getEnvInt("ATTENDANCE_CUTOFF_HOURS", 18),
+        SchoolTimezone:        schoolTimezone,
+
+        // Refunds
+        RefundFullWindowDays: getEnvInt("REFUND_FULL_WINDOW_DAYS", 14),
+
+        // Payment gateways
+        StripeAPIKey:      getEnv("STRIPE_API_KEY", ""),
+        PaystackSecretKey: getEnv("PAYSTACK_SECRET_KEY", ""),
+
+        // Store checkout
+        StoreCurrency:         getEnv("STORE_CURRENCY", "USD"),
+        TaxRatePercent:        taxRate,
+        ShippingFlatFee:       shippingFee,
+        FreeShippingThreshold: freeShipping,
+
+        // Media
+        FFProbePath: getEnv("FFPROBE_PATH", "ffprobe"),
     }
 }
 
@@ -97,3 +221,16 @@ func getEnv(key, fallback string) string {
     return fallback
 }
 
+// getEnvInt reads an integer setting, stopping start-up when it is not a number
+func getEnvInt(key string, fallback int) int {
+    value := os.Getenv(key)
+    if value == "" {
+        return fallback
+    }
+    parsed, err := strconv.Atoi(value)
+    if err != nil {
+        log.Fatalf("❌ Invalid %s: %v", key, err)
+    }
+    return parsed
+}
+
@@ -1,28 +0,0 @@
-package config
-
-import (
-	"log"
-	"os"
-
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-)
-
-var GoogleOauthConfig *oauth2.Config
-
-func InitGoogleOauthConfig() {
-	GoogleOauthConfig = &oauth2.Config{
-		RedirectURL:  "http://localhost:8080/auth/google/callback",
-		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		Scopes: []string{
-			"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile",
-		},
-		Endpoint: google.Endpoint,
-	}
-
-	if GoogleOauthConfig.ClientID == "" || GoogleOauthConfig.ClientSecret == "" {
-		log.Fatal("Google OAuth credentials not set in environment variables")
-	}
-}
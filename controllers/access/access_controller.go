@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/middleware"
+	"crm-go/services/access"
+)
+
+type AccessHandler struct {
+	accessService *services.AccessService
+}
+
+func NewAccessHandler(accessService *services.AccessService) *AccessHandler {
+	return &AccessHandler{
+		accessService: accessService,
+	}
+}
+
+// GetCourseAccess handles checking access to a course
+// @Summary Check course access
+// @Description Report whether the signed-in user may open a course's content, and whether access comes from an enrollment, a subscription or the courses:preview permission
+// @Tags Access
+// @Accept json
+// @Produce json
+// @Param course_id path string true "Course ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/access/courses/{course_id} [get]
+func (h *AccessHandler) GetCourseAccess(c *gin.Context) {
+	userID, canPreview, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	courseID, err := uuid.Parse(c.Param("course_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid course ID",
+		})
+		return
+	}
+
+	access, err := h.accessService.CourseAccess(userID, canPreview, courseID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Course access checked successfully",
+		"access":  access,
+	})
+}
+
+// GetModuleAccess handles checking access to a module
+// @Summary Check module access
+// @Description Report whether the signed-in user may open a module. Free modules are open as previews.
+// @Tags Access
+// @Accept json
+// @Produce json
+// @Param module_id path string true "Module ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/access/modules/{module_id} [get]
+func (h *AccessHandler) GetModuleAccess(c *gin.Context) {
+	userID, canPreview, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	access, err := h.accessService.ModuleAccess(userID, canPreview, c.Param("module_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Module access checked successfully",
+		"access":  access,
+	})
+}
+
+// currentUser reads the authenticated user's ID and whether they hold courses:preview,
+// writing a 401 when the ID is missing
+func (h *AccessHandler) currentUser(c *gin.Context) (uuid.UUID, bool, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false, false
+	}
+
+	canPreview, err := middleware.HasPermission(c, "courses:preview")
+	if err != nil {
+		h.handleError(c, err)
+		return uuid.Nil, false, false
+	}
+	return userID, canPreview, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *AccessHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
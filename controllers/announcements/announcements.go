@@ -29,7 +29,7 @@ type AnnouncementInput struct {
 	Title     string    `json:"title" example:"System Maintenance"`
 	Message   string    `json:"message" example:"The platform will be unavailable from 2AM to 4AM."`
 	Type      string    `json:"type" example:"maintenance"` // general, update, maintenance, urgent
-	Audience  string    `json:"audience" example:"all"`      // all, students, tutors, admins
+	Audience  string    `json:"audience" example:"all"`      // all, students, tutors, admins, guardians
 	CreatedBy uuid.UUID `json:"created_by" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
 
 	StartDate *time.Time `json:"start_date,omitempty" example:"2026-01-22T02:00:00Z"`
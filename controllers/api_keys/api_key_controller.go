@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/api_keys"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateServiceAccount handles adding a service account
+// @Summary Create a service account
+// @Description Add a non-person account for an integration such as the SIS or the website. Its role decides what its API keys may do (requires service_accounts:manage).
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateServiceAccountRequest true "Service account details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/service-accounts [post]
+func (h *APIKeyHandler) CreateServiceAccount(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	account, err := h.apiKeyService.CreateServiceAccount(&req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "Service account created successfully",
+		"service_account": account,
+	})
+}
+
+// GetServiceAccounts handles listing service accounts
+// @Summary List service accounts
+// @Description List every service account with its role and number of active keys (requires service_accounts:manage)
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/service-accounts [get]
+func (h *APIKeyHandler) GetServiceAccounts(c *gin.Context) {
+	accounts, err := h.apiKeyService.GetServiceAccounts()
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Service accounts retrieved successfully",
+		"service_accounts": accounts,
+	})
+}
+
+// GetServiceAccount handles fetching one service account
+// @Summary Get a service account
+// @Description Get a service account and its API keys (requires service_accounts:manage)
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Service account ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/service-accounts/{id} [get]
+func (h *APIKeyHandler) GetServiceAccount(c *gin.Context) {
+	account, err := h.apiKeyService.GetServiceAccount(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Service account retrieved successfully",
+		"service_account": account,
+	})
+}
+
+// UpdateServiceAccount handles changing a service account
+// @Summary Update a service account
+// @Description Change a service account's name, description or role, or disable it to stop all of its keys (requires service_accounts:manage)
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Service account ID"
+// @Param request body dto.UpdateServiceAccountRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/service-accounts/{id} [put]
+func (h *APIKeyHandler) UpdateServiceAccount(c *gin.Context) {
+	var req dto.UpdateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	account, err := h.apiKeyService.UpdateServiceAccount(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Service account updated successfully",
+		"service_account": account,
+	})
+}
+
+// DeleteServiceAccount handles removing a service account
+// @Summary Delete a service account
+// @Description Remove a service account and all of its API keys (requires service_accounts:manage)
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Service account ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/service-accounts/{id} [delete]
+func (h *APIKeyHandler) DeleteServiceAccount(c *gin.Context) {
+	if err := h.apiKeyService.DeleteServiceAccount(c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Service account deleted successfully",
+	})
+}
+
+// CreateAPIKey handles issuing an API key
+// @Summary Create an API key
+// @Description Issue an API key for a service account, limited to the given permission scopes, with an optional rate limit and expiry. The key is shown only in this response; send it as "Authorization: Bearer <key>" or in the X-API-Key header (requires service_accounts:manage).
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Service account ID"
+// @Param request body dto.CreateAPIKeyRequest true "Key details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/service-accounts/{id}/keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created successfully; store it now, it will not be shown again",
+		"api_key": key,
+	})
+}
+
+// GetAPIKeys handles listing a service account's API keys
+// @Summary List API keys
+// @Description List a service account's API keys with their scopes, limits and last use; secrets are never shown (requires service_accounts:manage)
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Service account ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/service-accounts/{id}/keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.GetAPIKeys(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "API keys retrieved successfully",
+		"api_keys": keys,
+	})
+}
+
+// RevokeAPIKey handles revoking an API key
+// @Summary Revoke an API key
+// @Description Stop an API key from working; it stays listed for auditing (requires service_accounts:manage)
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Service account ID"
+// @Param key_id path string true "API key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/service-accounts/{id}/keys/{key_id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	key, err := h.apiKeyService.RevokeAPIKey(c.Param("id"), c.Param("key_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+		"api_key": key,
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *APIKeyHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *APIKeyHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"time"
 
 	"crm-go/config"
+	"crm-go/dto"
 	"crm-go/models"
-	"crm-go/utils"
+	mfaServices "crm-go/services/mfa"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -18,14 +20,19 @@ var cfg = config.LoadEnv()
 
 // Login handles user login
 // @Summary User login
-// @Description Authenticate user and return JWT token with session information
+// @Description Authenticate user and return JWT token with session information. Users with two-factor authentication, or whose role requires it, get an MFA challenge instead unless the device is trusted.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param login body models.LoginInput true "Login credentials"
 // @Success 200 {object} models.LoginResponse "Login successful"
+// @Success 200 {object} dto.MFAChallengeResponse "Second factor required; complete at /auth/mfa/verify"
 // @Failure 400 {object} models.ErrorResponse "Invalid input"
-// @Failure 401 {object} models.ErrorResponse "Invalid credentials"
+// @Failure 400 {object} models.LoginErrorResponse "CAPTCHA required"
+// @Failure 401 {object} models.LoginErrorResponse "Invalid credentials"
+// @Failure 403 {object} models.ErrorResponse "Email not verified or account deactivated"
+// @Failure 423 {object} models.LoginErrorResponse "Account temporarily locked"
+// @Failure 429 {object} models.LoginErrorResponse "Too many attempts; retry after the given seconds"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /auth/login [post]
 func Login(c *gin.Context) {
@@ -40,67 +47,64 @@ func Login(c *gin.Context) {
 	}
 
 	// Find user by email
+	attempt := loginAttempt(c, input.Email, "password", input.CaptchaToken)
 	var user models.User
 	if err := config.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Error:   "Invalid credentials",
-			Message: "Invalid email or password",
-		})
+		if guardLogin(c, attempt, nil) {
+			loginFailed(c, attempt, nil, "unknown_email", "Invalid email or password")
+		}
+		return
+	}
+
+	// Refuse locked accounts and throttled callers before looking at the password
+	if !guardLogin(c, attempt, &user) {
 		return
 	}
 
 	// Compare passwords
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Error:   "Invalid credentials",
-			Message: "Invalid email or password",
-		})
+		loginFailed(c, attempt, &user, "invalid_password", "Invalid email or password")
 		return
 	}
-
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID.String(), user.Email, string(user.Role))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "Internal server error",
-			Message: "Failed to generate token",
-		})
+	if !requireVerifiedEmail(c, &user) || !requireActiveAccount(c, &user) {
 		return
 	}
+	loginSucceeded(c, attempt, &user)
 
-	// Create user session
-	session := models.UserSession{
-		SessionToken: token,
-		UserID:       user.ID,
-		UserAgent:    c.Request.UserAgent(),
-		UserIP:       c.ClientIP(),
-		DeviceType:   getDeviceType(c.Request.UserAgent()),
-		DeviceOS:     getOS(c.Request.UserAgent()),
-		Browser:      getBrowser(c.Request.UserAgent()),
-		IsActive:     true,
-		LoginType:    "password",
-		IssuedAt:     time.Now(),
-		ExpiresAt:    time.Now().Add(time.Duration(cfg.JWTExpire) * time.Hour),
-		LastUsedAt:   time.Now(),
-	}
-
-	// Save session to database
-	if err := config.DB.Create(&session).Error; err != nil {
+	// Open a session, or ask for a second factor first
+	trustedDeviceToken := input.TrustedDeviceToken
+	if trustedDeviceToken == "" {
+		trustedDeviceToken, _ = c.Cookie("trusted_device")
+	}
+	client := sessionClient(c)
+	result, err := mfaServices.NewMFAService(config.DB).BeginLogin(&user, "password", client, trustedDeviceToken)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Internal server error",
 			Message: "Failed to create session",
 		})
 		return
 	}
+	if result.Challenge != nil {
+		c.JSON(http.StatusOK, result.Challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse(c, &user, result, client))
+}
 
-	// Set session cookie
-	c.SetCookie("session_token", token, 3600, "/", "", false, true)
+// loginResponse sets the session cookies and builds the body returned once a sign-in completes
+func loginResponse(c *gin.Context, user *models.User, result *dto.LoginResult, client *dto.SessionClient) models.LoginResponse {
+	pair := result.Tokens
+	setSessionCookies(c, pair)
 
-	// Return structured response
 	response := models.LoginResponse{
-		Message:    "Login successful",
-		Token:      token,
-		SessionID:  session.ID,
+		Message:               "Login successful",
+		Token:                 pair.AccessToken,
+		TokenExpiresAt:        pair.AccessTokenExpiresAt,
+		RefreshToken:          pair.RefreshToken,
+		RefreshTokenExpiresAt: pair.RefreshTokenExpiresAt,
+		SessionID:             uuid.MustParse(pair.SessionID),
 		User: models.UserInfo{
 			ID:    user.ID,
 			FirstName:  user.FirstName,
@@ -109,19 +113,24 @@ func Login(c *gin.Context) {
 			Role:  string(user.Role),
 		},
 		Session: models.SessionInfo{
-			ExpiresAt: session.ExpiresAt,
-			Device:    session.DeviceType,
-			Browser:   session.Browser,
-			IPAddress: session.UserIP,
+			ExpiresAt: pair.SessionExpiresAt,
+			Device:    client.DeviceType,
+			Browser:   client.Browser,
+			IPAddress: client.IPAddress,
 		},
 	}
-
-	c.JSON(http.StatusOK, response)
+	if result.TrustedDevice != nil {
+		c.SetCookie("trusted_device", result.TrustedDevice.Token, int(time.Until(result.TrustedDevice.ExpiresAt).Seconds()), "/", "", false, true)
+		response.TrustedDeviceToken = result.TrustedDevice.Token
+		response.TrustedDeviceExpiresAt = &result.TrustedDevice.ExpiresAt
+	}
+	return response
 }
 
 
 type LoginIdInput struct {
-	Password string `json:"password" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+	CaptchaToken string `json:"captcha_token,omitempty"` // required once captcha_required has been returned
 }
 
 type LoginIdResponse struct {
@@ -137,7 +146,9 @@ type LoginIdResponse struct {
 // @Param request body LoginIdInput true "Login credentials"
 // @Success 200 {object} LoginIdResponse
 // @Failure 400 {object} models.ErrorResponse
-// @Failure 401 {object} models.ErrorResponse
+// @Failure 401 {object} models.LoginErrorResponse
+// @Failure 423 {object} models.LoginErrorResponse
+// @Failure 429 {object} models.LoginErrorResponse
 // @Router /auth/login/id [post]
 func LoginId(c *gin.Context) {
 	// Get email from header
@@ -170,23 +181,29 @@ func LoginId(c *gin.Context) {
 	}
 
 	// Find user by email
+	attempt := loginAttempt(c, email, "login_id", input.CaptchaToken)
 	var user models.User
 	if err := config.DB.Where("email = ?", email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Error:   "Invalid credentials",
-			Message: "Invalid email or password",
-		})
+		if guardLogin(c, attempt, nil) {
+			loginFailed(c, attempt, nil, "unknown_email", "Invalid email or password")
+		}
 		return
 	}
 
-	// Compare login IDs (password)
-	if user.LoginID != input.Password {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Error:   "Invalid credentials",
-			Message: "Invalid email or password",
-		})
+	// Refuse locked accounts and throttled callers before looking at the login ID
+	if !guardLogin(c, attempt, &user) {
+		return
+	}
+
+	// Compare login IDs (password); they are stored as bcrypt hashes
+	if user.LoginID == "" || bcrypt.CompareHashAndPassword([]byte(user.LoginID), []byte(input.Password)) != nil {
+		loginFailed(c, attempt, &user, "invalid_login_id", "Invalid email or password")
+		return
+	}
+	if !requireVerifiedEmail(c, &user) || !requireActiveAccount(c, &user) {
 		return
 	}
+	loginSucceeded(c, attempt, &user)
 
 	// Return structured response
 	response := LoginIdResponse{
@@ -198,6 +215,24 @@ func LoginId(c *gin.Context) {
 
 
 
+// sessionClient describes the device making the request
+func sessionClient(c *gin.Context) *dto.SessionClient {
+	userAgent := c.Request.UserAgent()
+	return &dto.SessionClient{
+		UserAgent:  userAgent,
+		IPAddress:  c.ClientIP(),
+		DeviceType: getDeviceType(userAgent),
+		DeviceOS:   getOS(userAgent),
+		Browser:    getBrowser(userAgent),
+	}
+}
+
+// setSessionCookies stores the access token and the refresh token in HTTP-only cookies
+func setSessionCookies(c *gin.Context, pair *dto.TokenPair) {
+	c.SetCookie("session_token", pair.AccessToken, int(time.Until(pair.AccessTokenExpiresAt).Seconds()), "/", "", false, true)
+	c.SetCookie("refresh_token", pair.RefreshToken, int(time.Until(pair.RefreshTokenExpiresAt).Seconds()), "/", "", false, true)
+}
+
 // Helper functions to parse user agent
 func getDeviceType(userAgent string) string {
 
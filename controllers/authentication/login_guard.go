@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/services/activity"
+	loginGuardServices "crm-go/services/login_guard"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UnlockAccount unlocks an account from the emailed link
+// @Summary Unlock account
+// @Description Unlocks an account locked after repeated failed sign-ins, using the token from the emailed link
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.UnlockAccountRequest true "Unlock token"
+// @Success 200 {object} object{message=string} "Account unlocked"
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired link"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /auth/unlock [post]
+func UnlockAccount(c *gin.Context) {
+	var input dto.UnlockAccountRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := loginGuardServices.NewLoginGuardService(config.DB).UnlockWithToken(input.Token); err != nil {
+		status := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "failed to") {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, models.ErrorResponse{
+			Error:   "Unlock failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Your account has been unlocked; you can sign in again"})
+}
+
+// loginAttempt describes the request for brute-force tracking
+func loginAttempt(c *gin.Context, email, method, captchaToken string) *dto.LoginAttemptInfo {
+	return &dto.LoginAttemptInfo{
+		Email:        strings.ToLower(strings.TrimSpace(email)),
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		Method:       method,
+		CaptchaToken: captchaToken,
+	}
+}
+
+// guardLogin runs the brute-force checks before credentials are compared. It writes the
+// response and returns false when the attempt is refused.
+func guardLogin(c *gin.Context, attempt *dto.LoginAttemptInfo, user *models.User) bool {
+	status, err := loginGuardServices.NewLoginGuardService(config.DB).Check(attempt, user)
+	if err == nil {
+		return true
+	}
+
+	msg := err.Error()
+	code := http.StatusTooManyRequests
+	title := "Too many attempts"
+	switch {
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Internal server error",
+			Message: msg,
+		})
+		return false
+	case strings.Contains(msg, "locked"):
+		code, title = http.StatusLocked, "Account locked"
+	case strings.Contains(msg, "CAPTCHA"):
+		code, title = http.StatusBadRequest, "CAPTCHA required"
+	}
+	writeLoginError(c, code, title, msg, status)
+	return false
+}
+
+// loginFailed records a failed sign-in, logs it to the user's activity and writes the 401
+func loginFailed(c *gin.Context, attempt *dto.LoginAttemptInfo, user *models.User, reason string, message string) {
+	status, err := loginGuardServices.NewLoginGuardService(config.DB).RecordFailure(attempt, user, reason)
+	if err != nil {
+		log.Printf("⚠️ Could not record failed sign-in: %v", err)
+		status = &dto.LoginGuardStatus{}
+	}
+	if user != nil {
+		if err := activity.NewService(config.DB).Users.Login(c, user.ID, false, reason); err != nil {
+			log.Printf("⚠️ Could not log failed sign-in for user %s: %v", user.ID, err)
+		}
+	}
+
+	if status.LockedUntil != nil {
+		writeLoginError(c, http.StatusLocked, "Account locked",
+			"Too many failed sign-in attempts; a link to unlock the account has been emailed", status)
+		return
+	}
+	writeLoginError(c, http.StatusUnauthorized, "Invalid credentials", message, status)
+}
+
+// loginSucceeded clears the account's failures and logs the sign-in
+func loginSucceeded(c *gin.Context, attempt *dto.LoginAttemptInfo, user *models.User) {
+	if err := loginGuardServices.NewLoginGuardService(config.DB).RecordSuccess(attempt, user); err != nil {
+		log.Printf("⚠️ Could not record sign-in for user %s: %v", user.ID, err)
+	}
+	if err := activity.NewService(config.DB).Users.Login(c, user.ID, true, ""); err != nil {
+		log.Printf("⚠️ Could not log sign-in for user %s: %v", user.ID, err)
+	}
+}
+
+func writeLoginError(c *gin.Context, code int, title, message string, status *dto.LoginGuardStatus) {
+	if status.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(status.RetryAfter))
+	}
+	c.JSON(code, models.LoginErrorResponse{
+		Error:           title,
+		Message:         message,
+		CaptchaRequired: status.CaptchaRequired,
+		RetryAfter:      status.RetryAfter,
+		LockedUntil:     status.LockedUntil,
+	})
+}
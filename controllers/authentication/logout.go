@@ -3,10 +3,11 @@ package controllers
 import (
 	"crm-go/config"
 	"crm-go/models"
+	sessionServices "crm-go/services/sessions"
+	"crm-go/utils"
 	"net/http"
 	"github.com/gin-gonic/gin"
-	"time"
-	
+	"github.com/google/uuid"
 )
 
 // Logout handles user logout
@@ -31,20 +32,25 @@ func Logout(c *gin.Context) {
 		return
 	}
 
-	// Invalidate session
-	result := config.DB.Model(&models.UserSession{}).
-		Where("session_token = ? AND is_active = true", token).
-		Updates(map[string]interface{}{
-			"is_active":    false,
-			"logged_out_at": time.Now(),
-		})
+	// Invalidate the session the token belongs to, along with its refresh tokens
+	// An expired access token can still sign out the session it was last issued for
+	var sessionID uuid.UUID
+	if claims, err := utils.ParseJWT(token); err == nil {
+		sessionID, _ = uuid.Parse(claims.SessionID)
+	} else {
+		var session models.UserSession
+		if config.DB.Select("id").Where("session_token = ?", token).First(&session).Error == nil {
+			sessionID = session.ID
+		}
+	}
 
-	if result.Error != nil {
+	revoked, err := sessionServices.NewSessionService(config.DB).RevokeSession(sessionID, "logout")
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
 		return
 	}
 
-	 if result.RowsAffected == 0 {
+	 if !revoked {
         // Token was already invalid or doesn't exist
         c.JSON(http.StatusOK, gin.H{
             "message": "Session already invalidated",
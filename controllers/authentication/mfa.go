@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	mfaServices "crm-go/services/mfa"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SendMFAEmailCode emails a one-time code for a pending sign-in
+// @Summary Email a sign-in code
+// @Description Sends a one-time code to the account's email address for a pending MFA challenge, as a fallback to the authenticator app
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAEmailCodeRequest true "MFA challenge"
+// @Success 200 {object} object{message=string} "Code sent"
+// @Failure 400 {object} models.ErrorResponse "Invalid input or code requested too soon"
+// @Failure 401 {object} models.ErrorResponse "Invalid or expired challenge"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /auth/mfa/email [post]
+func SendMFAEmailCode(c *gin.Context) {
+	var input dto.MFAEmailCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := mfaServices.NewMFAService(config.DB).SendEmailCode(&input); err != nil {
+		mfaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "A sign-in code has been sent to your email"})
+}
+
+// VerifyMFA completes a two-step sign-in
+// @Summary Verify second factor
+// @Description Completes a sign-in with an authenticator (totp), emailed (email) or recovery code. With remember_device the device skips the second factor for MFA_TRUSTED_DEVICE_DAYS.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAVerifyRequest true "Challenge and code"
+// @Success 200 {object} models.LoginResponse "Login successful"
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Invalid code or expired challenge"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /auth/mfa/verify [post]
+func VerifyMFA(c *gin.Context) {
+	var input dto.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	client := sessionClient(c)
+	user, result, err := mfaServices.NewMFAService(config.DB).VerifyLogin(&input, client)
+	if err != nil {
+		// Wrong codes count towards the account's lockout like wrong passwords
+		if user != nil {
+			loginFailed(c, loginAttempt(c, user.Email, "mfa_"+input.Method, ""), user, "invalid_mfa_code", err.Error())
+			return
+		}
+		mfaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse(c, user, result, client))
+}
+
+// mfaError maps MFA service errors to responses; a bad code or challenge is a 401
+func mfaError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Internal server error",
+			Message: msg,
+		})
+	case strings.Contains(msg, "deactivated"):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Account deactivated",
+			Message: msg,
+		})
+	case strings.Contains(msg, "challenge") || strings.Contains(msg, "invalid verification code"):
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "MFA verification failed",
+			Message: msg,
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request",
+			Message: msg,
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	sessionServices "crm-go/services/sessions"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshToken renews an access token
+// @Summary Refresh access token
+// @Description Exchanges a refresh token, from the body or the refresh_token cookie, for a new access token and a new refresh token. Each refresh token works once; reusing one revokes the session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest false "Refresh token"
+// @Success 200 {object} dto.TokenPair "Tokens renewed"
+// @Failure 401 {object} models.ErrorResponse "Invalid, reused or expired refresh token"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /auth/refresh [post]
+func RefreshToken(c *gin.Context) {
+	var input dto.RefreshTokenRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid input",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+	if input.RefreshToken == "" {
+		input.RefreshToken, _ = c.Cookie("refresh_token")
+	}
+
+	pair, err := sessionServices.NewSessionService(config.DB).Refresh(input.RefreshToken, sessionClient(c))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "failed to") {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Internal server error",
+				Message: "Failed to refresh token",
+			})
+			return
+		}
+		c.SetCookie("session_token", "", -1, "/", "", false, true)
+		c.SetCookie("refresh_token", "", -1, "/", "", false, true)
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Invalid refresh token",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	setSessionCookies(c, pair)
+	c.JSON(http.StatusOK, pair)
+}
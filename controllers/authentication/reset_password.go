@@ -2,29 +2,34 @@ package controllers
 
 import (
 	"crm-go/config"
-	"crm-go/models"
+	passwordServices "crm-go/services/passwords"
 	"net/http"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
 )
 
-
 type ResetPasswordInput struct {
 	Token       string `json:"token" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"` // may be empty for accounts that have never set a password
+	NewPassword     string `json:"new_password" binding:"required"`
 }
 
 // ResetPassword handles password reset confirmation
 // @Summary Reset user password
-// @Description Confirms password reset using a valid token and sets new password
+// @Description Sets a new password from a reset link, subject to the password policy. The link works once and every session is signed out.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param input body ResetPasswordInput true "Password reset data"
 // @Success 200 {object} object{message=string} "Password reset successful"
-// @Failure 400 {object} object{error=string} "Invalid token, token expired, or invalid input"
+// @Failure 400 {object} object{error=string} "Invalid or expired link, or the password breaks the policy"
+// @Failure 500 {object} object{error=string} "Failed to reset password"
 // @Router /auth/reset-password [post]
 func ResetPassword(c *gin.Context) {
 	var input ResetPasswordInput
@@ -33,29 +38,65 @@ func ResetPassword(c *gin.Context) {
 		return
 	}
 
-	var reset models.PasswordReset
-	if err := config.DB.Where("token = ?", input.Token).First(&reset).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token"})
+	if err := passwordServices.NewPasswordService(config.DB).ResetPassword(input.Token, input.NewPassword); err != nil {
+		passwordError(c, err)
 		return
 	}
 
-	// Check expiry
-	if time.Now().After(reset.ExpiresAt) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Token expired"})
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully; all sessions have been signed out"})
+}
+
+// ChangePassword handles a signed-in user changing their password
+// @Summary Change password
+// @Description Replaces the caller's password after checking the current one, subject to the password policy. Every other session is signed out.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param input body ChangePasswordInput true "Current and new password"
+// @Success 200 {object} object{message=string,revoked_sessions=int} "Password changed"
+// @Failure 400 {object} object{error=string} "Wrong current password, or the password breaks the policy"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 500 {object} object{error=string} "Failed to change password"
+// @Security BearerAuth
+// @Router /auth/change-password [post]
+func ChangePassword(c *gin.Context) {
+	var input ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get user
-	var user models.User
-	config.DB.First(&user, "id = ?", reset.UserID)
+	userIDStr, _ := c.Get("user_id")
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	sessionID, _ := c.Get("session_id")
+	currentSessionID, _ := sessionID.(uuid.UUID)
 
-	// Update password
-	hashed, _ := bcrypt.GenerateFromPassword([]byte(input.NewPassword), 14)
-	user.Password = string(hashed)
-	config.DB.Save(&user)
+	revoked, err := passwordServices.NewPasswordService(config.DB).
+		ChangePassword(userID, currentSessionID, input.CurrentPassword, input.NewPassword)
+	if err != nil {
+		passwordError(c, err)
+		return
+	}
 
-	// Delete used token
-	config.DB.Delete(&reset)
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Password changed successfully; your other sessions have been signed out",
+		"revoked_sessions": revoked,
+	})
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully"})
+// passwordError maps password service errors to HTTP responses
+func passwordError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
 }
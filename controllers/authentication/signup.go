@@ -2,28 +2,36 @@ package controllers
 
 import (
 	"crm-go/config"
+	"crm-go/dto"
 	"crm-go/models"
+	passwordServices "crm-go/services/passwords"
+	verificationServices "crm-go/services/verification"
+	"log"
 	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
-	
 )
 
+// SignUpInput is the public registration form. Every signup is a student account; admin, staff
+// and tutor accounts are created by invitation.
 type SignUpInput struct {
-	FirstName     string       `json:"first_name" binding:"required"`
-	LastName     string       `json:"last_name" binding:"required"`
-	Email    string       `json:"email" binding:"required,email"`
-	Password string       `json:"password" binding:"required,min=6"`
-	Role     string  `json:"role" binding:"required"`
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required"` // checked against the password policy
 }
 
 // SignUp godoc
 // @Summary Register a new user
-// @Description Create a new user account with first name, last name, email, password, and role
+// @Description Create an unverified student account and email a link to verify the address. The account cannot sign in until it is verified.
 // @Tags Authentication
 // @Accept  json
 // @Produce  json
 // @Param   input body SignUpInput true "User signup credentials"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Router /auth/signup [post]
 func SignUp(c *gin.Context) {
 	var input SignUpInput
@@ -32,15 +40,28 @@ func SignUp(c *gin.Context) {
 		return
 	}
 
-	// Hash password
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(input.Password), 14)
+	email := strings.TrimSpace(input.Email)
+	var existing int64
+	config.DB.Unscoped().Model(&models.User{}).Where("LOWER(email) = LOWER(?)", email).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+		return
+	}
+
+	// Check the password policy and hash
+	hashedPassword, err := passwordServices.NewPasswordService(config.DB).HashNewPassword(input.Password)
+	if err != nil {
+		passwordError(c, err)
+		return
+	}
 
 	user := models.User{
-		FirstName:     input.FirstName,
-		LastName:     input.LastName,
-		Email:    input.Email,
-		Password: string(hashedPassword),
-		Role:     input.Role,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Email:     email,
+		Password:  hashedPassword,
+		Role:      "student",
+		Provider:  "local",
 	}
 
 	result := config.DB.Create(&user)
@@ -49,10 +70,99 @@ func SignUp(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User created successfully", "user": user})
+	// The account exists either way; the link can be requested again if this email is lost
+	if err := verificationServices.NewEmailVerificationService(config.DB).SendVerification(&user); err != nil {
+		log.Printf("⚠️ Could not send verification email to user %s: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User created successfully; check your email to verify your address before signing in",
+		"user":    user,
+	})
 }
 
+// VerifyEmail confirms a user's email address from the emailed link
+// @Summary Verify email
+// @Description Marks the account named by a signed verification link as verified
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyEmailRequest true "Token from the verification link"
+// @Success 200 {object} object{message=string} "Email verified"
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired link"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /auth/verify-email [post]
+func VerifyEmail(c *gin.Context) {
+	var input dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid input",
+			Message: err.Error(),
+		})
+		return
+	}
 
+	if _, err := verificationServices.NewEmailVerificationService(config.DB).Verify(input.Token); err != nil {
+		status := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "failed to") {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, models.ErrorResponse{
+			Error:   "Verification failed",
+			Message: err.Error(),
+		})
+		return
+	}
 
+	c.JSON(http.StatusOK, gin.H{"message": "Your email has been verified; you can now sign in"})
+}
 
+// ResendVerification sends a new verification link
+// @Summary Resend verification email
+// @Description Emails a new verification link to an unverified account. Always reports success so addresses cannot be probed.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ResendVerificationRequest true "Email address"
+// @Success 200 {object} object{message=string}
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Router /auth/verify-email/resend [post]
+func ResendVerification(c *gin.Context) {
+	var input dto.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid input",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Failures are logged by the service and not reported, like unknown addresses
+	_ = verificationServices.NewEmailVerificationService(config.DB).Resend(input.Email)
 
+	c.JSON(http.StatusOK, gin.H{"message": "If that account exists and is not yet verified, a new link has been sent"})
+}
+
+// requireActiveAccount stops deactivated accounts from signing in, writing a 403
+func requireActiveAccount(c *gin.Context, user *models.User) bool {
+	if user.IsActive {
+		return true
+	}
+	c.JSON(http.StatusForbidden, models.ErrorResponse{
+		Error:   "Account deactivated",
+		Message: "This account has been deactivated; contact an administrator",
+	})
+	return false
+}
+
+// requireVerifiedEmail stops unverified accounts from signing in, writing a 403
+func requireVerifiedEmail(c *gin.Context, user *models.User) bool {
+	if user.IsVerified {
+		return true
+	}
+	c.JSON(http.StatusForbidden, models.ErrorResponse{
+		Error:   "Email not verified",
+		Message: "Verify your email address with the link we sent before signing in",
+	})
+	return false
+}
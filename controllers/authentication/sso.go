@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"crm-go/config"
+	"crm-go/models"
+	mfaServices "crm-go/services/mfa"
+	ssoServices "crm-go/services/sso"
+	verificationServices "crm-go/services/verification"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ssoStateCookie holds the signed state between leaving for the identity provider and coming back
+const ssoStateCookie = "sso_state"
+
+// SSOProviders lists the single sign-on providers offered on the sign-in page
+// @Summary List sign-in providers
+// @Description Lists the enabled identity providers, with the URL that starts a sign-in at each
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/sso/providers [get]
+func SSOProviders(c *gin.Context) {
+	providers, err := ssoServices.NewSSOService(config.DB).ListEnabledProviders()
+	if err != nil {
+		ssoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"providers": providers})
+}
+
+// SSOLogin starts a sign-in at an identity provider
+// @Summary Start single sign-on
+// @Description Redirects to the identity provider. OIDC sign-ins use PKCE and a nonce; the signed state is kept in a cookie and checked on return.
+// @Tags Authentication
+// @Param provider path string true "Provider slug"
+// @Success 302 {string} string "Redirect to the identity provider"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/sso/{provider}/login [get]
+func SSOLogin(c *gin.Context) {
+	startSSO(c, c.Param("provider"))
+}
+
+// SSOCallback completes an OpenID Connect sign-in
+// @Summary Complete OIDC sign-in
+// @Description Checks the state against the cookie set at the start, redeems the code, verifies the ID token and signs in the linked, matched or newly provisioned account. Roles come from the provider's claim mapping, never from the request.
+// @Tags Authentication
+// @Produce json
+// @Param provider path string true "Provider slug"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State returned by the provider"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/sso/{provider}/callback [get]
+func SSOCallback(c *gin.Context) {
+	finishOIDC(c, c.Param("provider"))
+}
+
+// SAMLAssertionConsumer completes a SAML sign-in
+// @Summary Complete SAML sign-in
+// @Description Assertion consumer service for the HTTP-POST binding. The response must be signed by the IdP and answer the request started from this browser.
+// @Tags Authentication
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param provider path string true "Provider slug"
+// @Param SAMLResponse formData string true "Base64 SAML response"
+// @Param RelayState formData string true "State returned by the IdP"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/sso/{provider}/acs [post]
+func SAMLAssertionConsumer(c *gin.Context) {
+	slug := c.Param("provider")
+	stateCookie, _ := c.Cookie(ssoStateCookie)
+	clearSSOState(c)
+
+	user, created, err := ssoServices.NewSSOService(config.DB).CompleteSAML(
+		c.Request.Context(), slug, stateCookie, c.PostForm("RelayState"), c.PostForm("SAMLResponse"))
+	if err != nil {
+		ssoError(c, err)
+		return
+	}
+	ssoSignIn(c, slug, user, created)
+}
+
+// SAMLMetadata serves the service provider metadata for a SAML provider
+// @Summary SAML service provider metadata
+// @Description Metadata to import at the identity provider: entity ID, assertion consumer service URL and, when configured, the signing certificate
+// @Tags Authentication
+// @Produce xml
+// @Param provider path string true "Provider slug"
+// @Success 200 {string} string "SAML metadata"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /auth/sso/{provider}/metadata [get]
+func SAMLMetadata(c *gin.Context) {
+	metadata, err := ssoServices.NewSSOService(config.DB).SPMetadata(c.Param("provider"))
+	if err != nil {
+		ssoError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// GoogleLoginHandler initiates Google OAuth2 login
+// @Summary Initiate Google OAuth2 login
+// @Description Redirects to Google OAuth2 consent screen. Same as /auth/sso/google/login; new accounts are students unless a registered google provider maps roles.
+// @Tags Authentication
+// @Produce json
+// @Success 302 {string} string "Redirect to Google OAuth2"
+// @Router /auth/google/login [get]
+func GoogleLoginHandler(c *gin.Context) {
+	startSSO(c, "google")
+}
+
+// GoogleCallbackHandler handles Google OAuth2 callback
+// @Summary Handle Google OAuth2 callback
+// @Description Completes a Google sign-in started at /auth/google/login, checking the state against the cookie set there
+// @Tags Authentication
+// @Produce json
+// @Param code query string true "OAuth2 authorization code from Google"
+// @Param state query string true "OAuth2 state parameter"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/google/callback [get]
+func GoogleCallbackHandler(c *gin.Context) {
+	finishOIDC(c, "google")
+}
+
+func startSSO(c *gin.Context, slug string) {
+	start, err := ssoServices.NewSSOService(config.DB).BeginLogin(c.Request.Context(), slug)
+	if err != nil {
+		ssoError(c, err)
+		return
+	}
+
+	// SAML responses are posted back from the IdP's site, so over HTTPS the cookie must be
+	// sent on cross-site requests
+	secure := strings.HasPrefix(cfg.AppURL, "https://")
+	if secure {
+		c.SetSameSite(http.SameSiteNoneMode)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+	}
+	c.SetCookie(ssoStateCookie, start.Cookie, 600, "/auth", "", secure, true)
+	c.Redirect(http.StatusFound, start.RedirectURL)
+}
+
+func finishOIDC(c *gin.Context, slug string) {
+	stateCookie, _ := c.Cookie(ssoStateCookie)
+	clearSSOState(c)
+
+	// The user cancelled or the provider refused
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Sign-in cancelled",
+			Message: strings.TrimSpace(providerErr + " " + c.Query("error_description")),
+		})
+		return
+	}
+
+	user, created, err := ssoServices.NewSSOService(config.DB).CompleteOIDC(
+		c.Request.Context(), slug, stateCookie, c.Query("state"), c.Query("code"))
+	if err != nil {
+		ssoError(c, err)
+		return
+	}
+	ssoSignIn(c, slug, user, created)
+}
+
+// ssoSignIn opens a session for the account an identity provider vouched for, or asks for a
+// second factor first
+func ssoSignIn(c *gin.Context, slug string, user *models.User, created bool) {
+	if created && !user.IsVerified {
+		if err := verificationServices.NewEmailVerificationService(config.DB).SendVerification(user); err != nil {
+			log.Printf("⚠️ Could not send verification email to user %s: %v", user.ID, err)
+		}
+	}
+	if !requireVerifiedEmail(c, user) || !requireActiveAccount(c, user) {
+		return
+	}
+	loginSucceeded(c, loginAttempt(c, user.Email, "sso", ""), user)
+
+	trustedDeviceToken, _ := c.Cookie("trusted_device")
+	client := sessionClient(c)
+	result, err := mfaServices.NewMFAService(config.DB).BeginLogin(user, "sso:"+slug, client, trustedDeviceToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Internal server error",
+			Message: "Failed to create session",
+		})
+		return
+	}
+	if result.Challenge != nil {
+		c.JSON(http.StatusOK, result.Challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse(c, user, result, client))
+}
+
+// clearSSOState removes the state cookie so it is only used once
+func clearSSOState(c *gin.Context) {
+	c.SetCookie(ssoStateCookie, "", -1, "/auth", "", strings.HasPrefix(cfg.AppURL, "https://"), true)
+}
+
+// ssoError maps single sign-on errors to responses
+func ssoError(c *gin.Context, err error) {
+	msg := err.Error()
+	code, title := http.StatusBadRequest, "Sign-in failed"
+	switch {
+	case strings.HasPrefix(msg, "failed to"):
+		code, title = http.StatusInternalServerError, "Internal server error"
+	case strings.Contains(msg, "not found"):
+		code, title = http.StatusNotFound, "Not found"
+	case strings.Contains(msg, "not allowed"), strings.Contains(msg, "disabled"),
+		strings.Contains(msg, "already exists"), strings.Contains(msg, "no account"),
+		strings.Contains(msg, "removed"):
+		code, title = http.StatusForbidden, "Sign-in not allowed"
+	case strings.HasPrefix(msg, "invalid"), strings.Contains(msg, "rejected"):
+		code, title = http.StatusUnauthorized, "Sign-in failed"
+	}
+	c.JSON(code, models.ErrorResponse{
+		Error:   title,
+		Message: msg,
+	})
+}
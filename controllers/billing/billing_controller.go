@@ -0,0 +1,491 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/billing"
+)
+
+type BillingHandler struct {
+	billingService *services.BillingService
+}
+
+func NewBillingHandler(billingService *services.BillingService) *BillingHandler {
+	return &BillingHandler{
+		billingService: billingService,
+	}
+}
+
+// CreateFeeSchedule handles fee schedule creation
+// @Summary Create a fee schedule
+// @Description Define the tuition, levies and optional charges for a class grade in one term
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateFeeScheduleRequest true "Fee schedule details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/fee-schedules [post]
+func (h *BillingHandler) CreateFeeSchedule(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateFeeScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.billingService.CreateFeeSchedule(&req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Fee schedule created successfully",
+		"fee_schedule": schedule,
+	})
+}
+
+// GetFeeSchedules handles listing fee schedules
+// @Summary Get fee schedules
+// @Description List fee schedules, optionally filtered by grade, session, term or status
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param class_grade_id query string false "Filter by class grade ID"
+// @Param academic_session_id query string false "Filter by academic session ID"
+// @Param term query string false "Filter by term"
+// @Param status query string false "Filter by status"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/fee-schedules [get]
+func (h *BillingHandler) GetFeeSchedules(c *gin.Context) {
+	var params dto.FeeScheduleQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	schedules, err := h.billingService.GetFeeSchedules(&params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Fee schedules retrieved successfully",
+		"fee_schedules": schedules,
+	})
+}
+
+// GetFeeScheduleByID handles retrieving a fee schedule
+// @Summary Get a fee schedule
+// @Description Get a fee schedule with its items
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Fee schedule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/fee-schedules/{id} [get]
+func (h *BillingHandler) GetFeeScheduleByID(c *gin.Context) {
+	schedule, err := h.billingService.GetFeeScheduleByID(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Fee schedule retrieved successfully",
+		"fee_schedule": schedule,
+	})
+}
+
+// UpdateFeeSchedule handles fee schedule updates
+// @Summary Update a fee schedule
+// @Description Update a fee schedule. Items can only be replaced before any student is invoiced.
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Fee schedule ID"
+// @Param request body dto.UpdateFeeScheduleRequest true "Fields to update"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/fee-schedules/{id} [put]
+func (h *BillingHandler) UpdateFeeSchedule(c *gin.Context) {
+	var req dto.UpdateFeeScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.billingService.UpdateFeeSchedule(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Fee schedule updated successfully",
+		"fee_schedule": schedule,
+	})
+}
+
+// DeleteFeeSchedule handles fee schedule deletion
+// @Summary Delete a fee schedule
+// @Description Delete a fee schedule that has no live invoices
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Fee schedule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/fee-schedules/{id} [delete]
+func (h *BillingHandler) DeleteFeeSchedule(c *gin.Context) {
+	if err := h.billingService.DeleteFeeSchedule(c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Fee schedule deleted successfully",
+	})
+}
+
+// GenerateInvoices handles billing students against a fee schedule
+// @Summary Generate invoices
+// @Description Invoice the listed students, or every student seated in the schedule's grade. Students already invoiced are skipped; siblings receive the schedule's discount.
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param request body dto.GenerateInvoicesRequest true "Invoice generation details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/invoices/generate [post]
+func (h *BillingHandler) GenerateInvoices(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.GenerateInvoicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.billingService.GenerateInvoices(&req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Invoices generated successfully",
+		"result":  result,
+	})
+}
+
+// GetInvoices handles listing invoices
+// @Summary Get invoices
+// @Description List invoices with pagination and filters
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param student_id query string false "Filter by student ID"
+// @Param schedule_id query string false "Filter by fee schedule ID"
+// @Param academic_session_id query string false "Filter by academic session ID"
+// @Param term query string false "Filter by term"
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/invoices [get]
+func (h *BillingHandler) GetInvoices(c *gin.Context) {
+	var params dto.InvoiceQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	invoices, err := h.billingService.GetInvoices(&params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invoices retrieved successfully",
+		"data":    invoices,
+	})
+}
+
+// GetInvoiceByID handles retrieving an invoice
+// @Summary Get an invoice
+// @Description Get an invoice with its lines and installment plan
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/invoices/{id} [get]
+func (h *BillingHandler) GetInvoiceByID(c *gin.Context) {
+	invoice, err := h.billingService.GetInvoiceByID(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invoice retrieved successfully",
+		"invoice": invoice,
+	})
+}
+
+// SetInstallments handles replacing an invoice's installment plan
+// @Summary Set an installment plan
+// @Description Replace an invoice's installment plan. Amounts must add up to the invoice total; payments already made are carried over.
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Param request body dto.SetInstallmentsRequest true "Installment plan"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/invoices/{id}/installments [put]
+func (h *BillingHandler) SetInstallments(c *gin.Context) {
+	var req dto.SetInstallmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	invoice, err := h.billingService.SetInstallments(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Installment plan updated successfully",
+		"invoice": invoice,
+	})
+}
+
+// CancelInvoice handles cancelling an unpaid invoice
+// @Summary Cancel an invoice
+// @Description Cancel an invoice that has no payments and reverse it on the student's ledger
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/invoices/{id}/cancel [post]
+func (h *BillingHandler) CancelInvoice(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.billingService.CancelInvoice(c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invoice cancelled successfully",
+		"invoice": invoice,
+	})
+}
+
+// RecordPayment handles recording a payment against an invoice
+// @Summary Record a payment
+// @Description Record a full or partial payment against an invoice. Payments settle installments earliest first.
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Param request body dto.RecordPaymentRequest true "Payment details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/invoices/{id}/payments [post]
+func (h *BillingHandler) RecordPayment(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RecordPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	payment, err := h.billingService.RecordPayment(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment recorded successfully",
+		"payment": payment,
+	})
+}
+
+// GetStatement handles retrieving a student's account statement
+// @Summary Get a student's statement
+// @Description Ledger of charges, discounts and payments with a running balance, plus outstanding invoices. Students see their own; guardians see linked students.
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param student_id path string true "Student ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/students/{student_id}/statement [get]
+func (h *BillingHandler) GetStatement(c *gin.Context) {
+	statement, err := h.billingService.GetStatement(c.Param("student_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Statement retrieved successfully",
+		"statement": statement,
+	})
+}
+
+// GetReceipt handles downloading a payment receipt
+// @Summary Download a payment receipt
+// @Description Download a PDF receipt for a payment made on a student's invoice
+// @Tags Billing
+// @Produce application/pdf
+// @Param student_id path string true "Student ID"
+// @Param payment_id path string true "Payment ID"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/billing/students/{student_id}/payments/{payment_id}/receipt [get]
+func (h *BillingHandler) GetReceipt(c *gin.Context) {
+	pdf, filename, err := h.billingService.GetReceiptPDF(c.Param("student_id"), c.Param("payment_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *BillingHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *BillingHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "already exists"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/middleware"
+	"crm-go/services/class_attendance"
+)
+
+type ClassAttendanceHandler struct {
+	attendanceService *services.ClassAttendanceService
+}
+
+func NewClassAttendanceHandler(attendanceService *services.ClassAttendanceService) *ClassAttendanceHandler {
+	return &ClassAttendanceHandler{
+		attendanceService: attendanceService,
+	}
+}
+
+// SubmitRegister handles taking or amending an arm's register
+// @Summary Submit an attendance register
+// @Description Mark every student in an arm present, absent, late or excused for a day or period. Registers lock for teachers after the cutoff.
+// @Tags Class Attendance
+// @Accept json
+// @Produce json
+// @Param request body dto.SubmitAttendanceRequest true "Register submission"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-attendance [post]
+func (h *ClassAttendanceHandler) SubmitRegister(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	canManage, err := middleware.HasPermission(c, "attendance:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	var req dto.SubmitAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	register, err := h.attendanceService.SubmitRegister(&req, userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Attendance register saved successfully",
+		"register": register,
+	})
+}
+
+// GetRegisterByID handles fetching a single register
+// @Summary Get attendance register by ID
+// @Description Get a register with every student's mark
+// @Tags Class Attendance
+// @Accept json
+// @Produce json
+// @Param id path string true "Register ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-attendance/{id} [get]
+func (h *ClassAttendanceHandler) GetRegisterByID(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Register ID is required",
+		})
+		return
+	}
+
+	register, err := h.attendanceService.GetRegisterByID(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Attendance register retrieved successfully",
+		"register": register,
+	})
+}
+
+// GetArmRegisters handles listing an arm's registers
+// @Summary Get attendance registers for an arm
+// @Description List an arm's registers, newest first, optionally within a date range
+// @Tags Class Attendance
+// @Accept json
+// @Produce json
+// @Param arm_id path string true "Arm ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param period query int false "Period (0 for the daily register)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-attendance/arm/{arm_id} [get]
+func (h *ClassAttendanceHandler) GetArmRegisters(c *gin.Context) {
+	armID := c.Param("arm_id")
+	if armID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Arm ID is required",
+		})
+		return
+	}
+
+	var params dto.AttendanceRegisterQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	registers, err := h.attendanceService.GetArmRegisters(armID, &params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Attendance registers retrieved successfully",
+		"registers": registers,
+	})
+}
+
+// GetArmSummary handles attendance totals for an arm
+// @Summary Get attendance summary for an arm
+// @Description Get each student's attendance totals and percentage over a term (defaults to the whole session)
+// @Tags Class Attendance
+// @Accept json
+// @Produce json
+// @Param arm_id path string true "Arm ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Param from query string false "Term start (YYYY-MM-DD)"
+// @Param to query string false "Term end (YYYY-MM-DD)"
+// @Param period query int false "Period (0 for the daily register)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-attendance/arm/{arm_id}/summary [get]
+func (h *ClassAttendanceHandler) GetArmSummary(c *gin.Context) {
+	armID := c.Param("arm_id")
+	if armID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Arm ID is required",
+		})
+		return
+	}
+
+	var params dto.AttendanceSummaryQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	summary, err := h.attendanceService.GetArmSummary(armID, &params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attendance summary retrieved successfully",
+		"summary": summary,
+	})
+}
+
+// GetStudentSummary handles attendance totals for a student
+// @Summary Get attendance summary for a student
+// @Description Get a student's attendance totals and percentage over a term (defaults to the current session)
+// @Tags Class Attendance
+// @Accept json
+// @Produce json
+// @Param student_id path string true "Student ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Param from query string false "Term start (YYYY-MM-DD)"
+// @Param to query string false "Term end (YYYY-MM-DD)"
+// @Param period query int false "Period (0 for the daily register)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-attendance/student/{student_id}/summary [get]
+func (h *ClassAttendanceHandler) GetStudentSummary(c *gin.Context) {
+	studentID := c.Param("student_id")
+	if studentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Student ID is required",
+		})
+		return
+	}
+
+	var params dto.AttendanceSummaryQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	summary, err := h.attendanceService.GetStudentSummary(studentID, &params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attendance summary retrieved successfully",
+		"summary": summary,
+	})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ClassAttendanceHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.Contains(msg, "locked"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
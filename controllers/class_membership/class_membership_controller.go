@@ -0,0 +1,342 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/class_membership"
+)
+
+type ClassMembershipHandler struct {
+	membershipService *services.ClassMembershipService
+}
+
+func NewClassMembershipHandler(membershipService *services.ClassMembershipService) *ClassMembershipHandler {
+	return &ClassMembershipHandler{
+		membershipService: membershipService,
+	}
+}
+
+// PlaceStudent handles placing a student in an arm
+// @Summary Place a student in an arm
+// @Description Seat a student in an arm for the arm's academic session, enforcing arm capacity
+// @Tags Class Memberships
+// @Accept json
+// @Produce json
+// @Param request body dto.PlaceStudentRequest true "Placement request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-memberships [post]
+func (h *ClassMembershipHandler) PlaceStudent(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.PlaceStudentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	membership, err := h.membershipService.PlaceStudent(&req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Student placed successfully",
+		"membership": membership,
+	})
+}
+
+// BulkPlaceStudents handles placing several students in one arm
+// @Summary Bulk place students in an arm
+// @Description Seat several students in one arm; fails without changes if capacity would be exceeded
+// @Tags Class Memberships
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkPlaceStudentsRequest true "Bulk placement request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-memberships/bulk [post]
+func (h *ClassMembershipHandler) BulkPlaceStudents(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.BulkPlaceStudentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	memberships, err := h.membershipService.BulkPlaceStudents(&req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Students placed successfully",
+		"memberships": memberships,
+	})
+}
+
+// TransferStudent handles moving a student to another arm
+// @Summary Transfer a student to another arm
+// @Description Move an active class membership to another arm in the same academic session
+// @Tags Class Memberships
+// @Accept json
+// @Produce json
+// @Param id path string true "Class membership ID"
+// @Param request body dto.TransferStudentRequest true "Transfer request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-memberships/{id}/transfer [put]
+func (h *ClassMembershipHandler) TransferStudent(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Class membership ID is required",
+		})
+		return
+	}
+
+	var req dto.TransferStudentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	membership, err := h.membershipService.TransferStudent(id, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Student transferred successfully",
+		"membership": membership,
+	})
+}
+
+// WithdrawStudent handles withdrawing a student from an arm
+// @Summary Withdraw a student from an arm
+// @Description Free the student's seat while keeping the membership history
+// @Tags Class Memberships
+// @Accept json
+// @Produce json
+// @Param id path string true "Class membership ID"
+// @Param request body dto.WithdrawStudentRequest false "Withdrawal request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-memberships/{id}/withdraw [put]
+func (h *ClassMembershipHandler) WithdrawStudent(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Class membership ID is required",
+		})
+		return
+	}
+
+	var req dto.WithdrawStudentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	membership, err := h.membershipService.WithdrawStudent(id, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Student withdrawn successfully",
+		"membership": membership,
+	})
+}
+
+// GetClassList handles fetching the students of an arm
+// @Summary Get class list for an arm
+// @Description Get the active students of an arm with capacity and occupancy
+// @Tags Class Memberships
+// @Accept json
+// @Produce json
+// @Param arm_id path string true "Arm ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-memberships/arm/{arm_id} [get]
+func (h *ClassMembershipHandler) GetClassList(c *gin.Context) {
+	armID := c.Param("arm_id")
+	if armID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Arm ID is required",
+		})
+		return
+	}
+
+	classList, err := h.membershipService.GetClassList(armID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Class list retrieved successfully",
+		"class_list": classList,
+	})
+}
+
+// GetStudentMemberships handles fetching a student's placement history
+// @Summary Get class memberships for a student
+// @Description Get every arm a student has been placed in across academic sessions
+// @Tags Class Memberships
+// @Accept json
+// @Produce json
+// @Param student_id path string true "Student ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-memberships/student/{student_id} [get]
+func (h *ClassMembershipHandler) GetStudentMemberships(c *gin.Context) {
+	studentID := c.Param("student_id")
+	if studentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Student ID is required",
+		})
+		return
+	}
+
+	memberships, err := h.membershipService.GetStudentMemberships(studentID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Class memberships retrieved successfully",
+		"memberships": memberships,
+	})
+}
+
+// BalanceArms handles redistributing students across the arms of a grade
+// @Summary Balance arms of a grade
+// @Description Redistribute active students across a grade's arms by gender mix or surname blocks
+// @Tags Class Memberships
+// @Accept json
+// @Produce json
+// @Param request body dto.BalanceArmsRequest true "Balancing request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/class-memberships/balance [post]
+func (h *ClassMembershipHandler) BalanceArms(c *gin.Context) {
+	var req dto.BalanceArmsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.membershipService.BalanceArms(&req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	message := "Arms balanced successfully"
+	if req.DryRun {
+		message = "Arm balancing preview generated successfully"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"balance": result,
+	})
+}
+
+// handleError maps service errors to HTTP status codes
+func (h *ClassMembershipHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "already exists"),
+		strings.Contains(err.Error(), "capacity exceeded"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
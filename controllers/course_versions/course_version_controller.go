@@ -0,0 +1,358 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/middleware"
+	"crm-go/services/course_versions"
+)
+
+type CourseVersionHandler struct {
+	versionService *services.CourseVersionService
+}
+
+func NewCourseVersionHandler(versionService *services.CourseVersionService) *CourseVersionHandler {
+	return &CourseVersionHandler{
+		versionService: versionService,
+	}
+}
+
+// CloneCourse handles copying a course
+// @Summary Clone a course
+// @Description Deep-copy a course with its modules, lessons, topics, materials, assignments, objective questions and release rules into a new course, e.g. to rerun it for a new cohort. Tutors may copy their own courses; admins may hand the copy to another tutor.
+// @Tags Course Versions
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body dto.CloneCourseRequest false "Clone options"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/courses/{id}/clone [post]
+func (h *CourseVersionHandler) CloneCourse(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canEdit, ok := h.canEdit(c, "course")
+	if !ok {
+		return
+	}
+	canAssignTutor, err := middleware.HasPermission(c, "courses:write")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	var req dto.CloneCourseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	clone, err := h.versionService.CloneCourse(c.Param("id"), &req, userID, canEdit, canAssignTutor)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Course cloned successfully",
+		"clone":   clone,
+	})
+}
+
+// CreateVersion handles starting a draft version of a course
+// @Summary Create a draft course version
+// @Description Copy the published version of a course into a draft that can be edited through the usual course, module, lesson and topic endpoints without affecting enrolled students
+// @Tags Course Versions
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body dto.CreateCourseVersionRequest false "Version notes"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/courses/{id}/versions [post]
+func (h *CourseVersionHandler) CreateVersion(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canEdit, ok := h.canEdit(c, "course")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateCourseVersionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	version, err := h.versionService.CreateVersion(c.Param("id"), &req, userID, canEdit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Course version created successfully",
+		"version": version,
+	})
+}
+
+// GetVersions handles listing a course's versions
+// @Summary List course versions
+// @Description List every version in the course's lineage, newest first, with the number of students pinned to each
+// @Tags Course Versions
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/courses/{id}/versions [get]
+func (h *CourseVersionHandler) GetVersions(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canEdit, ok := h.canEdit(c, "course")
+	if !ok {
+		return
+	}
+
+	versions, err := h.versionService.GetVersions(c.Param("id"), userID, canEdit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Course versions retrieved successfully",
+		"versions": versions,
+	})
+}
+
+// GetVersionByID handles retrieving a course version
+// @Summary Get a course version
+// @Description Get a single course version
+// @Tags Course Versions
+// @Accept json
+// @Produce json
+// @Param id path string true "Version ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/course-versions/{id} [get]
+func (h *CourseVersionHandler) GetVersionByID(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canEdit, ok := h.canEdit(c, "course_version")
+	if !ok {
+		return
+	}
+
+	version, err := h.versionService.GetVersionByID(c.Param("id"), userID, canEdit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Course version retrieved successfully",
+		"version": version,
+	})
+}
+
+// SubmitVersion handles sending a draft version for review
+// @Summary Submit a course version for review
+// @Description Move a draft course version into review
+// @Tags Course Versions
+// @Accept json
+// @Produce json
+// @Param id path string true "Version ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/course-versions/{id}/submit [post]
+func (h *CourseVersionHandler) SubmitVersion(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canEdit, ok := h.canEdit(c, "course_version")
+	if !ok {
+		return
+	}
+
+	version, err := h.versionService.SubmitVersion(c.Param("id"), userID, canEdit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Course version submitted for review",
+		"version": version,
+	})
+}
+
+// PublishVersion handles publishing a reviewed version
+// @Summary Publish a course version
+// @Description Make a reviewed version the live course. The previous version is superseded but stays open to the students enrolled on it, and new enrollments land on the new version (Admin only).
+// @Tags Course Versions
+// @Accept json
+// @Produce json
+// @Param id path string true "Version ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/course-versions/{id}/publish [post]
+func (h *CourseVersionHandler) PublishVersion(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	version, err := h.versionService.PublishVersion(c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Course version published successfully",
+		"version": version,
+	})
+}
+
+// DiscardVersion handles abandoning a draft version
+// @Summary Discard a course version
+// @Description Abandon a draft or in-review course version
+// @Tags Course Versions
+// @Accept json
+// @Produce json
+// @Param id path string true "Version ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/course-versions/{id}/discard [post]
+func (h *CourseVersionHandler) DiscardVersion(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canEdit, ok := h.canEdit(c, "course_version")
+	if !ok {
+		return
+	}
+
+	version, err := h.versionService.DiscardVersion(c.Param("id"), userID, canEdit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Course version discarded successfully",
+		"version": version,
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *CourseVersionHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// canEdit reports whether the user may edit the course a request names, directly or through
+// one of its versions, writing the error response when the check fails
+func (h *CourseVersionHandler) canEdit(c *gin.Context, resourceType string) (bool, bool) {
+	allowed, err := middleware.HasPermission(c, "courses:write", &dto.PermissionResource{Type: resourceType, ID: c.Param("id")})
+	if err != nil {
+		h.handleError(c, err)
+		return false, false
+	}
+	return allowed, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *CourseVersionHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
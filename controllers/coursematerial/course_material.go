@@ -2,7 +2,9 @@ package coursematerial
 
 import (
 	"crm-go/config"
+	"crm-go/middleware"
 	"crm-go/models"
+	accessServices "crm-go/services/access"
 	"encoding/json"
 	"net/http"
 	"github.com/gin-gonic/gin"
@@ -40,12 +42,6 @@ func CreateCourseMaterial(c *gin.Context) {
 			"error": "A course material with this title already exists for this course"})
 		return
 	}
-	// ✅ Check for duplicate by title
-	var existingTitle models.CourseMaterial
-	if err := db.Where("title = ?", req.Title).First(&existingTitle).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Course material with same title already exists"})
-		return
-	}
 
 
 	// Optional: validate material type
@@ -89,7 +85,7 @@ func CreateCourseMaterial(c *gin.Context) {
 
 // GetCourseMaterials godoc
 // @Summary      Get course materials
-// @Description  Get all course materials, optionally filtered by course, module, or topic
+// @Description  Get all course materials, optionally filtered by course, module, or topic. File links are only included for viewers who can open the course.
 // @Tags         Course Materials
 // @Produce      json
 // @Param        course_id   query   string  false  "Course ID"
@@ -160,9 +156,19 @@ func GetCourseMaterials(c *gin.Context) {
 		return
 	}
 
+	gate := accessServices.NewAccessService(config.DB).Gate(middleware.CurrentViewer(c))
+
 	// Map to response
 	for _, m := range materials {
-		responses = append(responses, models.CourseMaterialResponse{
+		open, err := gate.CanOpenCourse(m.CourseID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check content access",
+			})
+			return
+		}
+
+		response := models.CourseMaterialResponse{
 			ID:          m.ID,
 			CourseID:    m.CourseID,
 			Title:       m.Title,
@@ -171,7 +177,12 @@ func GetCourseMaterials(c *gin.Context) {
 			FileURL:     m.FileURL,
 			Status:      m.Status,
 			CreatedAt:   m.CreatedAt,
-		})
+		}
+		if !open {
+			response.FileURL = ""
+			response.Locked = true
+		}
+		responses = append(responses, response)
 	}
 
 	c.JSON(http.StatusOK, responses)
@@ -179,7 +190,7 @@ func GetCourseMaterials(c *gin.Context) {
 
 // GetCourseMaterialByID godoc
 // @Summary      Get course material details
-// @Description  Get course material with module and topic details
+// @Description  Get course material with course details. The file link is only included for viewers who can open the course.
 // @Tags         Course Materials
 // @Produce      json
 // @Param        id path string true "Course Material ID"
@@ -202,8 +213,6 @@ func GetCourseMaterialByID(c *gin.Context) {
 
 	if err := config.DB.
 		Preload("Course").
-		Preload("Module").
-		Preload("Topic").
 		First(&material, "id = ?", materialID).Error; err != nil {
 
 		c.JSON(http.StatusNotFound, gin.H{
@@ -212,6 +221,14 @@ func GetCourseMaterialByID(c *gin.Context) {
 		return
 	}
 
+	open, err := accessServices.NewAccessService(config.DB).Gate(middleware.CurrentViewer(c)).CanOpenCourse(material.CourseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check content access",
+		})
+		return
+	}
+
 	// Build response
 	response := models.CourseMaterialViewResponse{
 		ID:          material.ID,
@@ -228,6 +245,10 @@ func GetCourseMaterialByID(c *gin.Context) {
 			Title: material.Course.Title,
 		},
 	}
+	if !open {
+		response.FileURL = ""
+		response.Locked = true
+	}
 
 
 
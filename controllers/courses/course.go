@@ -3,7 +3,10 @@ package controllers
 import (
 	"net/http"
 	"crm-go/config"
+	"crm-go/middleware"
 	"crm-go/models"
+	accessServices "crm-go/services/access"
+	versionServices "crm-go/services/course_versions"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	
@@ -27,11 +30,15 @@ type CourseResponse struct {
     TutorID         string   `json:"tutor_id"`
     LearningOutcomes []string `json:"learning_outcomes"`
     Requirements    []string `json:"requirements"`
+    TotalModules    int      `json:"total_modules"`
+    TotalLessons    int      `json:"total_lessons"`
+    TotalTopics     int      `json:"total_topics"`
+    TotalDuration   int      `json:"total_duration"` // minutes, rolled up from topic durations
 }
 
 // CreateCourse godoc
 // @Summary      Create a new course
-// @Description  Admin creates a new course. Prevents duplicate titles.
+// @Description  Admin creates a new course. Prevents duplicate titles. New courses start as drafts and go live through the publishing workflow.
 // @Tags         Courses
 // @Accept       json
 // @Produce      json
@@ -51,6 +58,16 @@ func CreateCourse(c *gin.Context) {
 
 	db := config.DB
 
+	// Status only changes through the publishing workflow
+	course.Status = "draft"
+	course.ReviewerID = nil
+	course.SubmittedAt = nil
+	course.PublishedAt = nil
+	course.ArchivedAt = nil
+
+	// Totals are rolled up from the course content
+	course.TotalModules, course.TotalLessons, course.TotalTopics, course.TotalDuration = 0, 0, 0, 0
+
 	// ✅ Check for duplicate by title
 	var existingCourse models.Course
 	if err := db.Where("title = ?", course.Title).First(&existingCourse).Error; err == nil {
@@ -70,22 +87,36 @@ func CreateCourse(c *gin.Context) {
 
 // GetCourses godoc
 // @Summary      List all courses
-// @Description  Retrieve all courses available in the system
+// @Description  Retrieve published courses. Admins and tutors see every status and may filter by it. Unpublished and superseded course versions are left out.
 // @Tags         Courses
 // @Produce      json
+// @Param        status  query     string  false  "draft, review, published or archived (viewers holding courses:preview only)"
 // @Success      200  {array}   CourseResponse
 // @Failure      500  {object}  map[string]string "Failed to fetch courses"
 // @Router       /courses [get]
 func GetCourses(c *gin.Context) {
 	var courses []models.Course
 	db := config.DB
-	db.Find(&courses)
+
+	query := db.Where("id NOT IN (?)", versionServices.HiddenCourseIDs(db))
+	if _, canPreview := middleware.CurrentViewer(c); canPreview {
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+	} else {
+		query = query.Where("status = ?", "published")
+	}
+
+	if err := query.Find(&courses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch courses"})
+		return
+	}
 	c.JSON(http.StatusOK, courses)
 }
 
 // GetCourseByID godoc
 // @Summary      Get a course by ID
-// @Description  Retrieve details of a specific course using its ID
+// @Description  Retrieve details of a specific course using its ID, including module, lesson and topic counts and the total duration in minutes. Unpublished courses are only visible to staff and enrolled students.
 // @Tags         Courses
 // @Produce      json
 // @Param        id   path      string  true  "Course ID"
@@ -109,6 +140,20 @@ func GetCourseByID(c *gin.Context) {
 		return
 	}
 
+	// Drafts and archived courses stay visible to staff and to students enrolled on them
+	if course.Status != "published" {
+		viewerID, canPreview := middleware.CurrentViewer(c)
+		access, err := accessServices.NewAccessService(db).CourseAccess(viewerID, canPreview, course.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check course access"})
+			return
+		}
+		if viewerID == uuid.Nil || !access.Allowed {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, course)
 }
 
@@ -200,11 +245,21 @@ func UpdateCourse(c *gin.Context) {
 		return
 	}
 
+	// Keep the workflow fields and content totals; status only changes through the publishing workflow
+	status, reviewerID := course.Status, course.ReviewerID
+	submittedAt, publishedAt, archivedAt := course.SubmittedAt, course.PublishedAt, course.ArchivedAt
+	totalModules, totalLessons, totalTopics, totalDuration := course.TotalModules, course.TotalLessons, course.TotalTopics, course.TotalDuration
+
 	if err := c.ShouldBindJSON(&course); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	course.ID = uid
+	course.Status, course.ReviewerID = status, reviewerID
+	course.SubmittedAt, course.PublishedAt, course.ArchivedAt = submittedAt, publishedAt, archivedAt
+	course.TotalModules, course.TotalLessons, course.TotalTopics, course.TotalDuration = totalModules, totalLessons, totalTopics, totalDuration
+
 	db.Save(&course)
 	c.JSON(http.StatusOK, course)
 }
@@ -148,10 +148,11 @@ func (ctl *GradeController) GetAllGrades(c *gin.Context) {
 // @Tags grades
 // @Accept json
 // @Produce json
+// @Param id path string true "Grade ID"
 // @Param student_id query string false "Filter by student ID"
 // @Param course_id query string false "Filter by course ID"
 // @Success 200 {object} models.GradeStats
-// @Router /grades/stats [get]
+// @Router /grades/{id} [get]
 // @Security BearerAuth
 func (ctl *GradeController) GetGradeStats(c *gin.Context) {
     var filters models.GradeFilters
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/guardian_portal"
+)
+
+type GuardianPortalHandler struct {
+	portalService *services.GuardianPortalService
+}
+
+func NewGuardianPortalHandler(portalService *services.GuardianPortalService) *GuardianPortalHandler {
+	return &GuardianPortalHandler{
+		portalService: portalService,
+	}
+}
+
+// GetWards handles listing the signed-in guardian's wards
+// @Summary Get my wards
+// @Description List the students linked to the signed-in guardian, with their current arm
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/guardian-portal/wards [get]
+func (h *GuardianPortalHandler) GetWards(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	wards, err := h.portalService.GetWards(userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Wards retrieved successfully",
+		"wards":   wards,
+	})
+}
+
+// GetWardGrades handles listing a ward's grades
+// @Summary Get ward grades
+// @Description List a linked student's grades for a term (defaults to the current session)
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Param student_id path string true "Student ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Param from query string false "Term start (YYYY-MM-DD)"
+// @Param to query string false "Term end (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/guardian-portal/wards/{student_id}/grades [get]
+func (h *GuardianPortalHandler) GetWardGrades(c *gin.Context) {
+	var params dto.WardQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	grades, err := h.portalService.GetWardGrades(c.Param("student_id"), &params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Grades retrieved successfully",
+		"grades":  grades,
+	})
+}
+
+// GetWardReportCard handles a ward's term report card
+// @Summary Get ward report card
+// @Description Get a linked student's course averages and attendance for a term (defaults to the current session)
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Param student_id path string true "Student ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Param from query string false "Term start (YYYY-MM-DD)"
+// @Param to query string false "Term end (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/guardian-portal/wards/{student_id}/report-card [get]
+func (h *GuardianPortalHandler) GetWardReportCard(c *gin.Context) {
+	var params dto.WardQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reportCard, err := h.portalService.GetWardReportCard(c.Param("student_id"), &params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Report card retrieved successfully",
+		"report_card": reportCard,
+	})
+}
+
+// GetWardAttendance handles a ward's attendance summary
+// @Summary Get ward attendance
+// @Description Get a linked student's attendance totals for a term (defaults to the current session)
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Param student_id path string true "Student ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Param from query string false "Term start (YYYY-MM-DD)"
+// @Param to query string false "Term end (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/guardian-portal/wards/{student_id}/attendance [get]
+func (h *GuardianPortalHandler) GetWardAttendance(c *gin.Context) {
+	var params dto.WardQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	attendance, err := h.portalService.GetWardAttendance(c.Param("student_id"), &params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Attendance retrieved successfully",
+		"attendance": attendance,
+	})
+}
+
+// GetWardAssignments handles a ward's assignment status
+// @Summary Get ward assignments
+// @Description List assignments in a linked student's courses with their submission status
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Param student_id path string true "Student ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/guardian-portal/wards/{student_id}/assignments [get]
+func (h *GuardianPortalHandler) GetWardAssignments(c *gin.Context) {
+	assignments, err := h.portalService.GetWardAssignments(c.Param("student_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Assignments retrieved successfully",
+		"assignments": assignments,
+	})
+}
+
+// GetWardFees handles a ward's fee position
+// @Summary Get ward fees
+// @Description Get the payments made for a linked student
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Param student_id path string true "Student ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/guardian-portal/wards/{student_id}/fees [get]
+func (h *GuardianPortalHandler) GetWardFees(c *gin.Context) {
+	fees, err := h.portalService.GetWardFees(c.Param("student_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Fees retrieved successfully",
+		"fees":    fees,
+	})
+}
+
+// GetAnnouncements handles announcements for guardians
+// @Summary Get guardian announcements
+// @Description List current announcements addressed to everyone or to guardians
+// @Tags Guardian Portal
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/guardian-portal/announcements [get]
+func (h *GuardianPortalHandler) GetAnnouncements(c *gin.Context) {
+	announcements, err := h.portalService.GetAnnouncements()
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Announcements retrieved successfully",
+		"announcements": announcements,
+	})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *GuardianPortalHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
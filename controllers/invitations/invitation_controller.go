@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/invitations"
+)
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+}
+
+func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+	}
+}
+
+// CreateInvitation handles inviting someone to create an account
+// @Summary Invite a user
+// @Description Email a single-use link to create an account with the given role, optionally placed in a class arm or course (Admin only)
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateInvitationRequest true "Invitation details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(&req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Invitation sent successfully",
+		"invitation": invitation,
+	})
+}
+
+// GetInvitations handles listing invitations
+// @Summary List invitations
+// @Description List invitations, newest first (Admin only)
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, accepted, revoked, expired)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/invitations [get]
+func (h *InvitationHandler) GetInvitations(c *gin.Context) {
+	invitations, err := h.invitationService.GetInvitations(c.Query("status"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Invitations retrieved successfully",
+		"invitations": invitations,
+	})
+}
+
+// ResendInvitation handles sending a fresh invitation link
+// @Summary Resend an invitation
+// @Description Email a new link for an unaccepted invitation; the previous link stops working and the expiry restarts (Admin only)
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/invitations/{id}/resend [post]
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	invitation, err := h.invitationService.ResendInvitation(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Invitation resent successfully",
+		"invitation": invitation,
+	})
+}
+
+// RevokeInvitation handles cancelling an invitation
+// @Summary Revoke an invitation
+// @Description Stop an unaccepted invitation link from being used (Admin only)
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/invitations/{id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	invitation, err := h.invitationService.RevokeInvitation(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Invitation revoked successfully",
+		"invitation": invitation,
+	})
+}
+
+// GetInvitation handles showing an invitation on the accept page
+// @Summary View an invitation
+// @Description Show who an invitation link is for and the role it grants, before the invitee sets a password
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Param token path string true "Invitation token from the emailed link"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/invitations/{token} [get]
+func (h *InvitationHandler) GetInvitation(c *gin.Context) {
+	invitation, err := h.invitationService.PreviewInvitation(c.Param("token"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Invitation retrieved successfully",
+		"invitation": invitation,
+	})
+}
+
+// AcceptInvitation handles creating an account from an invitation
+// @Summary Accept an invitation
+// @Description Create the invited account with the invitation's role and assignments, setting its password. The link works once.
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Param request body dto.AcceptInvitationRequest true "Token and new account details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req dto.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.invitationService.AcceptInvitation(&req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Account created successfully; you can now sign in",
+		"user":    user,
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *InvitationHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *InvitationHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
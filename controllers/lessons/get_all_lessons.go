@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"crm-go/middleware"
 	"crm-go/models"
+	accessServices "crm-go/services/access"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -12,7 +14,7 @@ import (
 
 // GetAllLessons retrieves all lessons with optional filtering
 // @Summary Get all lessons
-// @Description Get all lessons with optional filtering
+// @Description Get all lessons with optional filtering. Lessons are marked locked when the viewer cannot open their module.
 // @Tags lessons
 // @Accept json
 // @Produce json
@@ -78,5 +80,20 @@ func (ctl *LessonController) GetAllLessons(c *gin.Context) {
 		return
 	}
 
+	// Lessons are outline only; flag the ones whose topics the viewer cannot open
+	gate := accessServices.NewAccessService(ctl.db).Gate(middleware.CurrentViewer(c))
+	for i := range result.Data {
+		status, err := gate.ModuleStatusByID(result.Data[i].ModuleID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check content access",
+			})
+			return
+		}
+		result.Data[i].Locked = !status.Open
+		result.Data[i].UnlockAt = status.UnlockAt
+		result.Data[i].LockReason = status.Reason
+	}
+
 	c.JSON(http.StatusOK, result)
 }
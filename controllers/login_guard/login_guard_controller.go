@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"crm-go/services/login_guard"
+)
+
+type LoginGuardHandler struct {
+	loginGuardService *services.LoginGuardService
+}
+
+func NewLoginGuardHandler(loginGuardService *services.LoginGuardService) *LoginGuardHandler {
+	return &LoginGuardHandler{
+		loginGuardService: loginGuardService,
+	}
+}
+
+// GetLoginSecurity handles showing a user's failed sign-ins and lockout
+// @Summary Get a user's sign-in security
+// @Description Show a user's failed sign-in count, lockout and most recent sign-in attempts (Admin only)
+// @Tags Login Security
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/login-security [get]
+func (h *LoginGuardHandler) GetLoginSecurity(c *gin.Context) {
+	security, err := h.loginGuardService.GetLoginSecurity(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Login security retrieved successfully",
+		"login_security": security,
+	})
+}
+
+// UnlockUser handles lifting a user's sign-in lockout
+// @Summary Unlock a user
+// @Description Lift a user's sign-in lockout and clear their failed attempt count (Admin only)
+// @Tags Login Security
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/unlock [post]
+func (h *LoginGuardHandler) UnlockUser(c *gin.Context) {
+	security, err := h.loginGuardService.UnlockUser(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "User unlocked successfully",
+		"login_security": security,
+	})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *LoginGuardHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
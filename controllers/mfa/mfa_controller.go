@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/mfa"
+)
+
+type MFAHandler struct {
+	mfaService *services.MFAService
+}
+
+func NewMFAHandler(mfaService *services.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// GetMFAStatus handles reading the caller's second-factor settings
+// @Summary Get my MFA settings
+// @Description Whether the caller's role requires a second factor, which methods are on, and how many recovery codes and trusted devices remain
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/mfa [get]
+func (h *MFAHandler) GetMFAStatus(c *gin.Context) {
+	userID, role, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(userID, role)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "MFA settings retrieved successfully",
+		"mfa":     status,
+	})
+}
+
+// SetupTOTP handles starting authenticator app enrollment
+// @Summary Start authenticator enrollment
+// @Description Generate a TOTP secret and an otpauth:// provisioning URI to show as a QR code. Confirm with a code to turn it on.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/mfa/totp/setup [post]
+func (h *MFAHandler) SetupTOTP(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	setup, err := h.mfaService.SetupTOTP(userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan the QR code with your authenticator app, then confirm with a code",
+		"totp":    setup,
+	})
+}
+
+// ConfirmTOTP handles turning the authenticator app on
+// @Summary Confirm authenticator enrollment
+// @Description Turn on the authenticator app with a first code from it. Returns recovery codes, shown only once.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(userID, req.Code)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Authenticator app enabled. Store these recovery codes somewhere safe.",
+		"recovery_codes": codes.RecoveryCodes,
+	})
+}
+
+// DisableTOTP handles turning the authenticator app off
+// @Summary Disable authenticator app
+// @Description Turn off the authenticator app and delete recovery codes, confirmed with a current TOTP or recovery code. Roles that require MFA fall back to emailed codes.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "Current TOTP or recovery code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/mfa/totp [delete]
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.mfaService.DisableTOTP(userID, req.Code); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Authenticator app disabled",
+	})
+}
+
+// SetEmailOTP handles turning emailed sign-in codes on or off
+// @Summary Toggle emailed sign-in codes
+// @Description Ask for a code sent by email at each sign-in from an untrusted device
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAEmailSettingRequest true "Enabled"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/mfa/email [put]
+func (h *MFAHandler) SetEmailOTP(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFAEmailSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	status, err := h.mfaService.SetEmailOTP(userID, *req.Enabled)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "MFA settings updated successfully",
+		"mfa":     status,
+	})
+}
+
+// RegenerateRecoveryCodes handles replacing the caller's recovery codes
+// @Summary Regenerate recovery codes
+// @Description Replace every recovery code, confirmed with a current TOTP or recovery code. The new codes are shown only once.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "Current TOTP or recovery code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Recovery codes regenerated. Store them somewhere safe.",
+		"recovery_codes": codes.RecoveryCodes,
+	})
+}
+
+// GetTrustedDevices handles listing the caller's remembered devices
+// @Summary List trusted devices
+// @Description List devices that skip the second factor at sign-in
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/mfa/trusted-devices [get]
+func (h *MFAHandler) GetTrustedDevices(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	sessionID, _ := c.Get("session_id")
+	currentID, _ := sessionID.(uuid.UUID)
+	devices, err := h.mfaService.GetTrustedDevices(userID, currentID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Trusted devices retrieved successfully",
+		"devices": devices,
+	})
+}
+
+// RevokeTrustedDevice handles forgetting a remembered device
+// @Summary Revoke trusted device
+// @Description Forget a remembered device; its next sign-in asks for a second factor
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param id path string true "Trusted device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/mfa/trusted-devices/{id} [delete]
+func (h *MFAHandler) RevokeTrustedDevice(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.RevokeTrustedDevice(userID, c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Trusted device revoked successfully",
+	})
+}
+
+// currentUser reads the authenticated user's ID and role, writing a 401 when they are missing
+func (h *MFAHandler) currentUser(c *gin.Context) (uuid.UUID, string, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, "", false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, "", false
+	}
+
+	role, _ := c.Get("role")
+	roleStr, _ := role.(string)
+	return userID, roleStr, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *MFAHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
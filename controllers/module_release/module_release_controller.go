@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/middleware"
+	"crm-go/services/module_release"
+)
+
+type ModuleReleaseHandler struct {
+	releaseService *services.ModuleReleaseService
+}
+
+func NewModuleReleaseHandler(releaseService *services.ModuleReleaseService) *ModuleReleaseHandler {
+	return &ModuleReleaseHandler{
+		releaseService: releaseService,
+	}
+}
+
+// SetReleaseRule handles setting a module's release rule
+// @Summary Set module release rule
+// @Description Hold a module back until N days after the student's enrollment, until a fixed date, or until a prerequisite module or quiz is completed. Replaces any existing rule (Admin only).
+// @Tags Module Release
+// @Accept json
+// @Produce json
+// @Param id path string true "Module ID"
+// @Param request body dto.SetReleaseRuleRequest true "Release rule"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/modules/{id}/release-rule [put]
+func (h *ModuleReleaseHandler) SetReleaseRule(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SetReleaseRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.releaseService.SetRule(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Release rule saved successfully",
+		"rule":    rule,
+	})
+}
+
+// GetReleaseRule handles retrieving a module's release rule
+// @Summary Get module release rule
+// @Description Get the release rule set on a module (Admin only)
+// @Tags Module Release
+// @Accept json
+// @Produce json
+// @Param id path string true "Module ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/modules/{id}/release-rule [get]
+func (h *ModuleReleaseHandler) GetReleaseRule(c *gin.Context) {
+	rule, err := h.releaseService.GetRule(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Release rule retrieved successfully",
+		"rule":    rule,
+	})
+}
+
+// DeleteReleaseRule handles removing a module's release rule
+// @Summary Delete module release rule
+// @Description Remove a module's release rule so it opens to everyone with course access (Admin only)
+// @Tags Module Release
+// @Accept json
+// @Produce json
+// @Param id path string true "Module ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/modules/{id}/release-rule [delete]
+func (h *ModuleReleaseHandler) DeleteReleaseRule(c *gin.Context) {
+	if err := h.releaseService.DeleteRule(c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Release rule deleted successfully",
+	})
+}
+
+// CompleteModule handles marking a module as completed
+// @Summary Complete a module
+// @Description Record that the signed-in student has finished a module they can open. Completing a module unlocks modules that list it as a prerequisite.
+// @Tags Module Release
+// @Accept json
+// @Produce json
+// @Param id path string true "Module ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/modules/{id}/complete [post]
+func (h *ModuleReleaseHandler) CompleteModule(c *gin.Context) {
+	userID, canPreview, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	completion, err := h.releaseService.CompleteModule(c.Param("id"), userID, canPreview)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Module completed successfully",
+		"completion": completion,
+	})
+}
+
+// currentUser reads the authenticated user's ID and whether they hold courses:preview,
+// writing a 401 when the ID is missing
+func (h *ModuleReleaseHandler) currentUser(c *gin.Context) (uuid.UUID, bool, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false, false
+	}
+
+	canPreview, err := middleware.HasPermission(c, "courses:preview")
+	if err != nil {
+		h.handleError(c, err)
+		return uuid.Nil, false, false
+	}
+	return userID, canPreview, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ModuleReleaseHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
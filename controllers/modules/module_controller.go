@@ -2,7 +2,10 @@ package modules
 
 import (
 	"crm-go/config"
+	"crm-go/middleware"
 	"crm-go/models"
+	accessServices "crm-go/services/access"
+	rollupServices "crm-go/services/rollup"
 	"errors"
 	"net/http"
 
@@ -76,14 +79,33 @@ func CreateModule(c *gin.Context) {
 		Description:   input.Description,
 		ModuleNumber:  input.ModuleNumber,
 		IsFree:        input.IsFree,
-		Status:        input.Status,
+		Status:        "draft", // published through the publishing workflow
 		EstimatedTime: input.EstimatedTime,
 	}
 
-	if err := config.DB.Create(&module).Error; err != nil {
+	// Create the module and count it into the course totals together
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := tx.Create(&module).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := rollupServices.RecomputeCourses(tx, module.CourseID); err != nil {
+		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create module"})
+		return
+	}
 
 	c.JSON(http.StatusCreated, models.SuccessResponse{
 		Message: "Module created successfully",
@@ -92,7 +114,7 @@ func CreateModule(c *gin.Context) {
 
 // GetAllModules handles the retrieval of all modules
 // @Summary Get all modules
-// @Description Get all modules
+// @Description Get all modules. Admins and tutors see every module; everyone else sees published modules of published courses, or of courses they are enrolled on. Each module is marked locked when the viewer cannot open its content.
 // @Tags Modules
 // @Accept json
 // @Produce json
@@ -104,7 +126,7 @@ func GetAllModules(c *gin.Context) {
 
 	if err := config.DB.
 		Preload("Course", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "title")
+			return db.Select("id", "title", "status")
 		}).
 		Order("module_number ASC").
 		Find(&modules).Error; err != nil {
@@ -112,9 +134,27 @@ func GetAllModules(c *gin.Context) {
 		return
 	}
 
+	gate := accessServices.NewAccessService(config.DB).Gate(middleware.CurrentViewer(c))
+
 	var response []models.ModuleResponse
 
 	for _, ch := range modules {
+		visible, err := gate.CanSeeModule(&ch)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check content access"})
+			return
+		}
+		if !visible {
+			continue
+		}
+
+		status, err := gate.ModuleStatus(&ch)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check content access"})
+			return
+		}
+
+
 		var r models.ModuleResponse
 		r.ID = ch.ID
 		r.CourseID = ch.CourseID
@@ -129,6 +169,9 @@ func GetAllModules(c *gin.Context) {
 		r.TotalDuration = ch.TotalDuration
 		r.CreatedAt = ch.CreatedAt
 		r.UpdatedAt = ch.UpdatedAt
+		r.Locked = !status.Open
+		r.UnlockAt = status.UnlockAt
+		r.LockReason = status.Reason
 
 		response = append(response, r)
 	}
@@ -138,7 +181,7 @@ func GetAllModules(c *gin.Context) {
 
 // GetModuleByID handles the retrieval of a single module by ID
 // @Summary Get a single module by ID
-// @Description Get a single module by ID
+// @Description Get a single module by ID. Unpublished modules are only visible to staff. Topic content is only included when the viewer can open the module.
 // @Tags Modules
 // @Accept json
 // @Produce json
@@ -174,6 +217,23 @@ func GetModuleByID(c *gin.Context) {
 		Title: module.Course.Title,
 	}
 
+	gate := accessServices.NewAccessService(config.DB).Gate(middleware.CurrentViewer(c))
+	visible, err := gate.CanSeeModule(&module)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check content access"})
+		return
+	}
+	if !visible {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+
+	status, err := gate.ModuleStatus(&module)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check content access"})
+		return
+	}
+
 	// Optional: pick one topic (or latest)
 	var topic *models.TopicMiniResponse
 	if module.Topics != nil && len(*module.Topics) > 0 {
@@ -181,7 +241,9 @@ func GetModuleByID(c *gin.Context) {
 			ID:          (*module.Topics)[0].ID,
 			Title:       (*module.Topics)[0].Title,
 			ContentType: (*module.Topics)[0].ContentType,
-			ContentURL:  (*module.Topics)[0].ContentURL,
+		}
+		if status.Open {
+			topic.ContentURL = (*module.Topics)[0].ContentURL
 		}
 	}
 
@@ -195,9 +257,13 @@ func GetModuleByID(c *gin.Context) {
 		IsFree:        module.IsFree,
 		Status:        module.Status,
 		EstimatedTime: module.EstimatedTime,
+		TotalTopics:   module.TotalTopics,
 		TotalDuration: module.TotalDuration,
 		CreatedAt:     module.CreatedAt,
 		UpdatedAt:     module.UpdatedAt,
+		Locked:        !status.Open,
+		UnlockAt:      status.UnlockAt,
+		LockReason:    status.Reason,
 		Course:        course,
 		Topics:        topic,
 	}
@@ -263,14 +329,34 @@ func UpdateModule(c *gin.Context) {
 		"description":    input.Description,
 		"module_number":  input.ModuleNumber,
 		"is_free":        input.IsFree,
-		"status":         input.Status,
 		"estimated_time": input.EstimatedTime,
 	}
 
-	if err := config.DB.Model(&module).Updates(updates).Error; err != nil {
+	previousCourseID := module.CourseID
+
+	// Moving a module to another course changes both courses' totals
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := tx.Model(&module).Updates(updates).Error; err != nil {
+		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := rollupServices.RecomputeCourses(tx, previousCourseID, input.CourseID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update module"})
+		return
+	}
 
 	// Reload with relations
 	if err := config.DB.
@@ -306,15 +392,33 @@ func DeleteModule(c *gin.Context) {
 		return
 	}
 
-	result := config.DB.Delete(&models.Module{}, "id = ?", moduleID)
+	var module models.Module
+	if err := config.DB.Select("id, course_id").First(&module, "id = ?", moduleID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
 
-	if result.Error != nil {
+	// Delete the module and take it out of the course totals together
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := tx.Delete(&models.Module{}, "id = ?", module.ID).Error; err != nil {
+		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete module"})
 		return
 	}
-
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+	if err := rollupServices.RecomputeCourses(tx, module.CourseID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete module"})
 		return
 	}
 
@@ -0,0 +1,556 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/middleware"
+	"crm-go/services/orders"
+)
+
+type OrderHandler struct {
+	orderService *services.OrderService
+}
+
+func NewOrderHandler(orderService *services.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+	}
+}
+
+// GetCart handles retrieving the signed-in user's cart
+// @Summary Get my cart
+// @Description Get the cart with current prices and an estimate of tax and shipping
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/cart [get]
+func (h *OrderHandler) GetCart(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.orderService.GetCart(userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart retrieved successfully",
+		"cart":    cart,
+	})
+}
+
+// AddCartItem handles adding a product to the cart
+// @Summary Add to cart
+// @Description Add a product to the cart. Products that sell a course can only be added once and not when already enrolled.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param request body dto.AddCartItemRequest true "Product and quantity"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/cart/items [post]
+func (h *OrderHandler) AddCartItem(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	cart, err := h.orderService.AddCartItem(userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product added to cart",
+		"cart":    cart,
+	})
+}
+
+// UpdateCartItem handles changing a cart line's quantity
+// @Summary Update cart quantity
+// @Description Set the quantity of a product in the cart
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Param request body dto.UpdateCartItemRequest true "New quantity"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/cart/items/{product_id} [put]
+func (h *OrderHandler) UpdateCartItem(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	cart, err := h.orderService.UpdateCartItem(userID, c.Param("product_id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart updated successfully",
+		"cart":    cart,
+	})
+}
+
+// RemoveCartItem handles removing a product from the cart
+// @Summary Remove from cart
+// @Description Remove a product from the cart
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/cart/items/{product_id} [delete]
+func (h *OrderHandler) RemoveCartItem(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.orderService.RemoveCartItem(userID, c.Param("product_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product removed from cart",
+		"cart":    cart,
+	})
+}
+
+// ClearCart handles emptying the cart
+// @Summary Clear cart
+// @Description Remove every product from the cart
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/cart [delete]
+func (h *OrderHandler) ClearCart(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.orderService.ClearCart(userID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart cleared successfully",
+	})
+}
+
+// Checkout handles turning the cart into an order
+// @Summary Checkout
+// @Description Place an order for the cart. Free orders and orders charged to a saved gateway customer are paid at once and their courses enrolled; others stay pending until paid.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param request body dto.CheckoutRequest true "Checkout details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 402 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/orders/checkout [post]
+func (h *OrderHandler) Checkout(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.orderService.Checkout(userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Order placed successfully",
+		"order":   order,
+	})
+}
+
+// GetMyOrders handles listing the signed-in user's orders
+// @Summary Get my orders
+// @Description List the signed-in user's orders
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/orders/my [get]
+func (h *OrderHandler) GetMyOrders(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var params dto.OrderQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	params.UserID = userID.String()
+
+	orders, err := h.orderService.GetOrders(&params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Orders retrieved successfully",
+		"data":    orders,
+	})
+}
+
+// GetOrders handles listing all orders
+// @Summary Get orders
+// @Description List orders with pagination and filters
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Filter by customer ID"
+// @Param status query string false "Filter by status"
+// @Param from query string false "Placed on or after (YYYY-MM-DD)"
+// @Param to query string false "Placed on or before (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/orders [get]
+func (h *OrderHandler) GetOrders(c *gin.Context) {
+	var params dto.OrderQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	orders, err := h.orderService.GetOrders(&params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Orders retrieved successfully",
+		"data":    orders,
+	})
+}
+
+// GetOrderByID handles retrieving an order
+// @Summary Get an order
+// @Description Get an order by ID. Non-admins may only view their own.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/orders/{id} [get]
+func (h *OrderHandler) GetOrderByID(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "orders:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	order, err := h.orderService.GetOrderByID(c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order retrieved successfully",
+		"order":   order,
+	})
+}
+
+// PayOrder handles charging a pending order
+// @Summary Pay an order
+// @Description Charge a pending order to a saved gateway customer and fulfil it
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param request body dto.PayOrderRequest true "Payment details"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 402 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/orders/{id}/pay [post]
+func (h *OrderHandler) PayOrder(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PayOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.orderService.PayOrder(c.Param("id"), userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order paid successfully",
+		"order":   order,
+	})
+}
+
+// RecordPayment handles settling an order paid outside the platform
+// @Summary Record an order payment
+// @Description Record a bank transfer, cash or other external payment for a pending order and fulfil it
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param request body dto.RecordOrderPaymentRequest true "Payment details"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/orders/{id}/payments [post]
+func (h *OrderHandler) RecordPayment(c *gin.Context) {
+	adminID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RecordOrderPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.orderService.RecordPayment(c.Param("id"), adminID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment recorded successfully",
+		"order":   order,
+	})
+}
+
+// CancelOrder handles cancelling an unpaid order
+// @Summary Cancel an order
+// @Description Cancel a pending order. Paid orders are refunded through refund requests instead.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "orders:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order cancelled successfully",
+		"order":   order,
+	})
+}
+
+// UpdateOrderStatus handles moving an order through fulfilment
+// @Summary Update order status
+// @Description Move a paid order that ships to processing, shipped or completed. The customer is emailed when it ships.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param request body dto.UpdateOrderStatusRequest true "New status"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/orders/{id}/status [put]
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	var req dto.UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.orderService.UpdateOrderStatus(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order status updated successfully",
+		"order":   order,
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *OrderHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *OrderHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "payment failed"):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
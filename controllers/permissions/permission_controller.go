@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/permissions"
+)
+
+type PermissionHandler struct {
+	permissionService *services.PermissionService
+}
+
+func NewPermissionHandler(permissionService *services.PermissionService) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
+
+// GetPermissionCatalog handles listing the permissions roles can hold
+// @Summary List permissions
+// @Description List every permission routes can require, and whether it can be limited to the user's own resources (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/permissions [get]
+func (h *PermissionHandler) GetPermissionCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Permissions retrieved successfully",
+		"permissions": services.Catalog(),
+	})
+}
+
+// GetMyPermissions handles listing the signed-in user's permissions
+// @Summary My permissions
+// @Description List the permissions the signed-in user holds through their primary role and any additional role assignments
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/permissions/me [get]
+func (h *PermissionHandler) GetMyPermissions(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	roles, err := h.permissionService.GetUserRoles(userID.String())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Permissions retrieved successfully",
+		"roles":   roles,
+	})
+}
+
+// GetRoles handles listing roles
+// @Summary List roles
+// @Description List every role with its permissions and how many users hold it (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/roles [get]
+func (h *PermissionHandler) GetRoles(c *gin.Context) {
+	roles, err := h.permissionService.GetRoles()
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Roles retrieved successfully",
+		"roles":   roles,
+	})
+}
+
+// GetRole handles fetching one role
+// @Summary Get a role
+// @Description Get a role and its permissions (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/roles/{id} [get]
+func (h *PermissionHandler) GetRole(c *gin.Context) {
+	role, err := h.permissionService.GetRole(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role retrieved successfully",
+		"role":    role,
+	})
+}
+
+// CreateRole handles adding a role
+// @Summary Create a role
+// @Description Add a role as a set of permissions, each for all resources or only the user's own (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateRoleRequest true "Role details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/roles [post]
+func (h *PermissionHandler) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	role, err := h.permissionService.CreateRole(&req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"role":    role,
+	})
+}
+
+// UpdateRole handles changing a role
+// @Summary Update a role
+// @Description Change a role's name for display, description or permission set; given permissions replace the existing ones. The admin role's permissions cannot change (requires roles:manage).
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body dto.UpdateRoleRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/roles/{id} [put]
+func (h *PermissionHandler) UpdateRole(c *gin.Context) {
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	role, err := h.permissionService.UpdateRole(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"role":    role,
+	})
+}
+
+// DeleteRole handles removing a role
+// @Summary Delete a role
+// @Description Remove a custom role and its assignments; built-in roles and roles that are still a user's primary role cannot be deleted (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/roles/{id} [delete]
+func (h *PermissionHandler) DeleteRole(c *gin.Context) {
+	if err := h.permissionService.DeleteRole(c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role deleted successfully",
+	})
+}
+
+// GetUserRoles handles showing a user's roles
+// @Summary Get a user's roles
+// @Description Show a user's primary role, additional role assignments and the permissions they add up to (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/roles [get]
+func (h *PermissionHandler) GetUserRoles(c *gin.Context) {
+	roles, err := h.permissionService.GetUserRoles(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User roles retrieved successfully",
+		"roles":   roles,
+	})
+}
+
+// SetPrimaryRole handles changing a user's primary role
+// @Summary Change a user's primary role
+// @Description Change the role a user holds everywhere; their sessions are signed out so new tokens carry the role (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.SetPrimaryRoleRequest true "New role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/role [put]
+func (h *PermissionHandler) SetPrimaryRole(c *gin.Context) {
+	var req dto.SetPrimaryRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	roles, err := h.permissionService.SetPrimaryRole(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role changed successfully",
+		"roles":   roles,
+	})
+}
+
+// AssignRole handles giving a user an additional role
+// @Summary Assign a role
+// @Description Give a user an additional role, everywhere or only within one department or course (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.AssignRoleRequest true "Role and optional scope"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/roles [post]
+func (h *PermissionHandler) AssignRole(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	assignment, err := h.permissionService.AssignRole(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Role assigned successfully",
+		"assignment": assignment,
+	})
+}
+
+// RemoveAssignment handles taking an additional role away from a user
+// @Summary Remove a role assignment
+// @Description Take an additional role away from a user; their primary role is unaffected (requires roles:manage)
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param assignment_id path string true "Role assignment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/roles/{assignment_id} [delete]
+func (h *PermissionHandler) RemoveAssignment(c *gin.Context) {
+	if err := h.permissionService.RemoveAssignment(c.Param("id"), c.Param("assignment_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role assignment removed successfully",
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *PermissionHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *PermissionHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/promotion"
+)
+
+type PromotionHandler struct {
+	promotionService *services.PromotionService
+}
+
+func NewPromotionHandler(promotionService *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// RolloverSession handles cloning class structure into a new academic session
+// @Summary Roll over an academic session
+// @Description Clone class grades, arms and grade-subject allocations from one session into another
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param request body dto.SessionRolloverRequest true "Session rollover request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/promotions/rollover [post]
+func (h *PromotionHandler) RolloverSession(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.SessionRolloverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.promotionService.RolloverSession(&req, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Session rolled over successfully",
+		"rollover": result,
+	})
+}
+
+// PreviewPromotion handles a dry run of the promotion rules
+// @Summary Preview student promotion
+// @Description Compute promotion outcomes for every student in the source session without saving anything
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param request body dto.PromotionRequest true "Promotion rules"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/promotions/preview [post]
+func (h *PromotionHandler) PreviewPromotion(c *gin.Context) {
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	preview, err := h.promotionService.PreviewPromotion(&req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Promotion preview generated successfully",
+		"promotion": preview,
+	})
+}
+
+// ExecutePromotion handles applying the promotion rules
+// @Summary Execute student promotion
+// @Description Move students into the target session according to the promotion rules and record an audit run
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param request body dto.PromotionRequest true "Promotion rules"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/promotions/execute [post]
+func (h *PromotionHandler) ExecutePromotion(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.promotionService.ExecutePromotion(&req, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Promotion executed successfully",
+		"promotion": result,
+	})
+}
+
+// GetPromotionRuns handles fetching executed promotion runs
+// @Summary Get promotion runs
+// @Description Get a paginated audit list of executed promotion runs
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param from_session_id query string false "Filter by source session ID"
+// @Param to_session_id query string false "Filter by target session ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} dto.PromotionRunListResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/promotions/runs [get]
+func (h *PromotionHandler) GetPromotionRuns(c *gin.Context) {
+	var params dto.PromotionRunQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.promotionService.GetPromotionRuns(&params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Promotion runs retrieved successfully",
+		"data":    response,
+	})
+}
+
+// GetPromotionRunByID handles fetching a single promotion run
+// @Summary Get promotion run by ID
+// @Description Get a promotion run with the decision recorded for every student
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion run ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/promotions/runs/{id} [get]
+func (h *PromotionHandler) GetPromotionRunByID(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Promotion run ID is required",
+		})
+		return
+	}
+
+	run, err := h.promotionService.GetPromotionRunByID(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Promotion run retrieved successfully",
+		"promotion": run,
+	})
+}
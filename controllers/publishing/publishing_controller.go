@@ -0,0 +1,518 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/middleware"
+	"crm-go/services/publishing"
+)
+
+type PublishingHandler struct {
+	publishingService *services.PublishingService
+}
+
+func NewPublishingHandler(publishingService *services.PublishingService) *PublishingHandler {
+	return &PublishingHandler{
+		publishingService: publishingService,
+	}
+}
+
+// GetStatus handles retrieving an item's workflow status
+// @Summary Get publishing status
+// @Description Get where a course or module is in the draft, review, published, archived workflow
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id} [get]
+func (h *PublishingHandler) GetStatus(c *gin.Context) {
+	userID, canManage, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.publishingService.GetStatus(itemType, c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publishing status retrieved successfully",
+		"status":  status,
+	})
+}
+
+// GetChecks handles running the publish checks
+// @Summary Run publish checks
+// @Description List the checks a course (image, modules, lessons, price) or module (lessons, topics) must pass before it can be submitted or published
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id}/checks [get]
+func (h *PublishingHandler) GetChecks(c *gin.Context) {
+	userID, canManage, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+
+	checks, err := h.publishingService.Checks(itemType, c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publish checks completed",
+		"checks":  checks,
+	})
+}
+
+// Submit handles sending a draft for review
+// @Summary Submit for review
+// @Description Send a draft course or module for review. The publish checks must pass.
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Param request body dto.WorkflowNoteRequest false "Note for the reviewer"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id}/submit [post]
+func (h *PublishingHandler) Submit(c *gin.Context) {
+	userID, canManage, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+	var req dto.WorkflowNoteRequest
+	if !h.bindOptional(c, &req) {
+		return
+	}
+
+	status, err := h.publishingService.Submit(itemType, c.Param("id"), userID, canManage, req.Note)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Submitted for review",
+		"status":  status,
+	})
+}
+
+// AssignReviewer handles assigning a reviewer
+// @Summary Assign a reviewer
+// @Description Assign the admin or tutor who reviews a course or module. The course tutor cannot review their own work (Admin only).
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Param request body dto.AssignReviewerRequest true "Reviewer"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id}/reviewer [put]
+func (h *PublishingHandler) AssignReviewer(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AssignReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	status, err := h.publishingService.AssignReviewer(itemType, c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reviewer assigned successfully",
+		"status":  status,
+	})
+}
+
+// RequestChanges handles sending an item back to draft
+// @Summary Request changes
+// @Description Send a course or module in review back to draft with feedback for the tutor (assigned reviewer or admin)
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Param request body dto.ReviewCommentRequest true "Feedback"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id}/request-changes [post]
+func (h *PublishingHandler) RequestChanges(c *gin.Context) {
+	userID, canManage, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReviewCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	status, err := h.publishingService.RequestChanges(itemType, c.Param("id"), &req, userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Changes requested",
+		"status":  status,
+	})
+}
+
+// Publish handles publishing an item in review
+// @Summary Publish
+// @Description Publish a course or module in review. The publish checks must pass (assigned reviewer or admin).
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id}/publish [post]
+func (h *PublishingHandler) Publish(c *gin.Context) {
+	userID, canManage, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.publishingService.Publish(itemType, c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Published successfully",
+		"status":  status,
+	})
+}
+
+// Archive handles archiving a published item
+// @Summary Archive
+// @Description Take a published course or module out of listings. Students already enrolled keep their access (Admin only).
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Param request body dto.WorkflowNoteRequest false "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id}/archive [post]
+func (h *PublishingHandler) Archive(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+	var req dto.WorkflowNoteRequest
+	if !h.bindOptional(c, &req) {
+		return
+	}
+
+	status, err := h.publishingService.Archive(itemType, c.Param("id"), userID, req.Note)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Archived successfully",
+		"status":  status,
+	})
+}
+
+// AddComment handles adding a review comment
+// @Summary Add a review comment
+// @Description Comment on a course or module's review thread (course tutor, assigned reviewer or admin)
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Param request body dto.ReviewCommentRequest true "Comment"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id}/comments [post]
+func (h *PublishingHandler) AddComment(c *gin.Context) {
+	userID, canManage, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReviewCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	comment, err := h.publishingService.AddComment(itemType, c.Param("id"), &req, userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Comment added successfully",
+		"comment": comment,
+	})
+}
+
+// GetComments handles retrieving the review thread
+// @Summary Get review comments
+// @Description Get a course or module's review thread, including submissions, change requests and publish events
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param item_type path string true "courses or modules"
+// @Param id path string true "Course or module ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/{item_type}/{id}/comments [get]
+func (h *PublishingHandler) GetComments(c *gin.Context) {
+	userID, canManage, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	itemType, ok := h.itemType(c)
+	if !ok {
+		return
+	}
+
+	comments, err := h.publishingService.GetComments(itemType, c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Review comments retrieved successfully",
+		"comments": comments,
+	})
+}
+
+// GetQueue handles listing items waiting for review
+// @Summary Get review queue
+// @Description List courses and modules in review. Tutors see the items assigned to them; admins can filter by reviewer or unassigned items.
+// @Tags Publishing
+// @Accept json
+// @Produce json
+// @Param type query string false "course or module"
+// @Param reviewer_id query string false "Reviewer ID (admin only)"
+// @Param unassigned query bool false "Only items without a reviewer (admin only)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/publishing/queue [get]
+func (h *PublishingHandler) GetQueue(c *gin.Context) {
+	userID, canManage, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var params dto.ReviewQueueParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	queue, err := h.publishingService.GetQueue(&params, userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Review queue retrieved successfully",
+		"queue":   queue,
+	})
+}
+
+// itemType maps the item_type path segment to the service's item type, writing a 400 when unknown
+func (h *PublishingHandler) itemType(c *gin.Context) (string, bool) {
+	switch c.Param("item_type") {
+	case "courses":
+		return "course", true
+	case "modules":
+		return "module", true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": "item type must be courses or modules",
+	})
+	return "", false
+}
+
+// bindOptional binds a JSON body when one was sent, writing a 400 when it is invalid
+func (h *PublishingHandler) bindOptional(c *gin.Context, req interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// currentUser reads the authenticated user's ID and whether they manage publishing for
+// everyone, writing the error response when either cannot be determined
+func (h *PublishingHandler) currentUser(c *gin.Context) (uuid.UUID, bool, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false, false
+	}
+
+	canManage, err := middleware.HasPermission(c, "publishing:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return uuid.Nil, false, false
+	}
+	return userID, canManage, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *PublishingHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,327 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/middleware"
+	"crm-go/services/refunds"
+)
+
+type RefundHandler struct {
+	refundService *services.RefundService
+}
+
+func NewRefundHandler(refundService *services.RefundService) *RefundHandler {
+	return &RefundHandler{
+		refundService: refundService,
+	}
+}
+
+// QuoteRefund handles previewing the refund an enrollment qualifies for
+// @Summary Preview a refund
+// @Description Show whether an enrollment can be refunded and how much the policy allows: full within the refund window, pro-rated by progress after it, nothing once a certificate is issued
+// @Tags Refunds
+// @Accept json
+// @Produce json
+// @Param enrollment_id path string true "Enrollment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/refunds/quote/{enrollment_id} [get]
+func (h *RefundHandler) QuoteRefund(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "refunds:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	quote, err := h.refundService.QuoteRefund(c.Param("enrollment_id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund quote retrieved successfully",
+		"quote":   quote,
+	})
+}
+
+// RequestRefund handles opening a refund request
+// @Summary Request a refund
+// @Description Ask to cancel a paid enrollment. An admin reviews the request before any money is returned.
+// @Tags Refunds
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateRefundRequest true "Refund request details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/refunds [post]
+func (h *RefundHandler) RequestRefund(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "refunds:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	var req dto.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	refund, err := h.refundService.RequestRefund(&req, userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Refund requested successfully",
+		"refund":  refund,
+	})
+}
+
+// GetRefunds handles listing refund requests
+// @Summary Get refund requests
+// @Description List refund requests. Students only see their own.
+// @Tags Refunds
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param student_id query string false "Filter by student ID (admin only)"
+// @Param course_id query string false "Filter by course ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/refunds [get]
+func (h *RefundHandler) GetRefunds(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var params dto.RefundQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	canManage, err := middleware.HasPermission(c, "refunds:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if !canManage {
+		params.StudentID = userID.String()
+	}
+
+	refunds, err := h.refundService.GetRefunds(&params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund requests retrieved successfully",
+		"data":    refunds,
+	})
+}
+
+// GetRefundByID handles retrieving a refund request
+// @Summary Get a refund request
+// @Description Get a refund request by ID
+// @Tags Refunds
+// @Accept json
+// @Produce json
+// @Param id path string true "Refund request ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/refunds/{id} [get]
+func (h *RefundHandler) GetRefundByID(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "refunds:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	refund, err := h.refundService.GetRefundByID(c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund request retrieved successfully",
+		"refund":  refund,
+	})
+}
+
+// ApproveRefund handles approving a refund request
+// @Summary Approve a refund
+// @Description Re-check the refund policy, refund through the payment gateway, cancel the enrollment and revoke course access. Failed gateway refunds can be retried.
+// @Tags Refunds
+// @Accept json
+// @Produce json
+// @Param id path string true "Refund request ID"
+// @Param request body dto.ReviewRefundRequest false "Review note"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/refunds/{id}/approve [post]
+func (h *RefundHandler) ApproveRefund(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReviewRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	refund, err := h.refundService.ApproveRefund(c.Param("id"), userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund processed successfully",
+		"refund":  refund,
+	})
+}
+
+// RejectRefund handles rejecting a refund request
+// @Summary Reject a refund
+// @Description Decline a refund request with a note; the enrollment stays active
+// @Tags Refunds
+// @Accept json
+// @Produce json
+// @Param id path string true "Refund request ID"
+// @Param request body dto.ReviewRefundRequest true "Rejection note"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/refunds/{id}/reject [post]
+func (h *RefundHandler) RejectRefund(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReviewRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	refund, err := h.refundService.RejectRefund(c.Param("id"), userID, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund request rejected",
+		"refund":  refund,
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *RefundHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *RefundHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "gateway refund failed"):
+		c.JSON(http.StatusBadGateway, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
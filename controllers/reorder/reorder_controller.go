@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/reorder"
+)
+
+type ReorderHandler struct {
+	reorderService *services.ReorderService
+}
+
+func NewReorderHandler(reorderService *services.ReorderService) *ReorderHandler {
+	return &ReorderHandler{
+		reorderService: reorderService,
+	}
+}
+
+// ReorderModules handles reordering a course's modules
+// @Summary Reorder course modules
+// @Description Set the order of a course's modules. The list must name every module of the course exactly once; modules are renumbered 1..n in one transaction (Admin or course tutor).
+// @Tags Reorder
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body dto.ReorderRequest true "Ordered module IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/courses/{id}/modules/order [put]
+func (h *ReorderHandler) ReorderModules(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.reorderService.ReorderModules(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Modules reordered successfully",
+		"order":   order,
+	})
+}
+
+// ReorderLessons handles reordering a module's lessons
+// @Summary Reorder module lessons
+// @Description Set the order of a module's lessons. The list must include every lesson of the module and may add lessons from other modules of the same course to move them in; every affected module is renumbered without gaps (Admin or course tutor).
+// @Tags Reorder
+// @Accept json
+// @Produce json
+// @Param id path string true "Module ID"
+// @Param request body dto.ReorderRequest true "Ordered lesson IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/modules/{id}/lessons/order [put]
+func (h *ReorderHandler) ReorderLessons(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.reorderService.ReorderLessons(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Lessons reordered successfully",
+		"order":   order,
+	})
+}
+
+// ReorderTopics handles reordering a lesson's topics
+// @Summary Reorder lesson topics
+// @Description Set the order of a lesson's topics. The list must include every topic of the lesson and may add topics from other lessons of the same course to move them in; every affected lesson is renumbered without gaps (Admin or course tutor).
+// @Tags Reorder
+// @Accept json
+// @Produce json
+// @Param id path string true "Lesson ID"
+// @Param request body dto.ReorderRequest true "Ordered topic IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/lessons/{id}/topics/order [put]
+func (h *ReorderHandler) ReorderTopics(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.reorderService.ReorderTopics(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Topics reordered successfully",
+		"order":   order,
+	})
+}
+
+// MoveLesson handles moving a lesson to another module
+// @Summary Move a lesson
+// @Description Move a lesson, with its topics, questions and assignments, to a position in another module of the same course (Admin or course tutor).
+// @Tags Reorder
+// @Accept json
+// @Produce json
+// @Param id path string true "Lesson ID"
+// @Param request body dto.MoveLessonRequest true "Target module and position"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/lessons/{id}/move [post]
+func (h *ReorderHandler) MoveLesson(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MoveLessonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.reorderService.MoveLesson(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Lesson moved successfully",
+		"order":   order,
+	})
+}
+
+// MoveTopic handles moving a topic to another lesson
+// @Summary Move a topic
+// @Description Move a topic, with its assignments, to a position in another lesson of the same course (Admin or course tutor).
+// @Tags Reorder
+// @Accept json
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Param request body dto.MoveTopicRequest true "Target lesson and position"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/topics/{id}/move [post]
+func (h *ReorderHandler) MoveTopic(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MoveTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	order, err := h.reorderService.MoveTopic(c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Topic moved successfully",
+		"order":   order,
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *ReorderHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ReorderHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
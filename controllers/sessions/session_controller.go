@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/services/sessions"
+)
+
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+func NewSessionHandler(sessionService *services.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// GetMySessions handles listing the caller's signed-in devices
+// @Summary List my sessions
+// @Description List the devices the authenticated user is signed in on, most recently used first. The session making the request is marked current.
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/sessions [get]
+func (h *SessionHandler) GetMySessions(c *gin.Context) {
+	userID, sessionID, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(userID, sessionID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Sessions retrieved successfully",
+		"sessions": sessions,
+	})
+}
+
+// RevokeMySession handles signing out one of the caller's devices
+// @Summary Revoke a session
+// @Description Sign out one of the authenticated user's sessions; its refresh token stops working at once
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	userID, _, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeUserSession(userID, c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions handles signing the caller out everywhere else
+// @Summary Log out everywhere else
+// @Description Sign out every session of the authenticated user except the one making the request
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/me/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, sessionID, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.sessionService.RevokeOtherSessions(userID, sessionID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Other sessions revoked successfully",
+		"result":  result,
+	})
+}
+
+// ForceLogoutUser handles signing a user out of every session
+// @Summary Force logout a user
+// @Description Sign a user out of every device and void their refresh tokens (Admin only)
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/logout [post]
+func (h *SessionHandler) ForceLogoutUser(c *gin.Context) {
+	result, err := h.sessionService.ForceLogout(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User signed out of all sessions",
+		"result":  result,
+	})
+}
+
+// currentSession reads the authenticated user's ID and session, writing a 401 when they are missing
+func (h *SessionHandler) currentSession(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	sessionID, _ := c.Get("session_id")
+	currentID, _ := sessionID.(uuid.UUID)
+	return userID, currentID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *SessionHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/signing_keys"
+)
+
+type SigningKeyHandler struct {
+	keyService *services.SigningKeyService
+}
+
+func NewSigningKeyHandler(keyService *services.SigningKeyService) *SigningKeyHandler {
+	return &SigningKeyHandler{
+		keyService: keyService,
+	}
+}
+
+// JWKS handles publishing the token verification keys
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens, including keys retiring after a rotation. Match a token's kid header to a key; tokens are RS256 or EdDSA signed with iss, aud, sub and jti claims.
+// @Tags Signing Keys
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /.well-known/jwks.json [get]
+func (h *SigningKeyHandler) JWKS(c *gin.Context) {
+	set, err := h.keyService.JWKS()
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// Short cache so verifiers notice a rotation within minutes
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+}
+
+// GetSigningKeys handles listing signing keys
+// @Summary List signing keys
+// @Description List active, retiring and retired token signing keys. Private keys are never returned (Admin only).
+// @Tags Signing Keys
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/signing-keys [get]
+func (h *SigningKeyHandler) GetSigningKeys(c *gin.Context) {
+	keys, err := h.keyService.GetKeys()
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Signing keys retrieved successfully",
+		"keys":    keys,
+	})
+}
+
+// RotateSigningKey handles rotating the token signing key
+// @Summary Rotate signing key
+// @Description Generate a new RS256 or EdDSA key to sign new tokens. The previous key keeps verifying until the tokens it signed expire (Admin only).
+// @Tags Signing Keys
+// @Accept json
+// @Produce json
+// @Param request body dto.RotateSigningKeyRequest false "Key algorithm"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/signing-keys/rotate [post]
+func (h *SigningKeyHandler) RotateSigningKey(c *gin.Context) {
+	userID, _, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RotateSigningKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	key, err := h.keyService.RotateKey(&req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Signing key rotated successfully",
+		"key":     key,
+	})
+}
+
+// RetireSigningKey handles withdrawing a retiring signing key early
+// @Summary Retire signing key
+// @Description Stop accepting tokens signed by a retiring key straight away, for example after a suspected leak. The active key must be rotated out first (Admin only).
+// @Tags Signing Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Signing key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/signing-keys/{id}/retire [post]
+func (h *SigningKeyHandler) RetireSigningKey(c *gin.Context) {
+	key, err := h.keyService.RetireKey(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Signing key retired successfully",
+		"key":     key,
+	})
+}
+
+// currentUser reads the authenticated user's ID and role, writing a 401 when they are missing
+func (h *SigningKeyHandler) currentUser(c *gin.Context) (uuid.UUID, string, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, "", false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, "", false
+	}
+
+	role, _ := c.Get("role")
+	roleStr, _ := role.(string)
+	return userID, roleStr, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *SigningKeyHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/sso"
+)
+
+type SSOHandler struct {
+	ssoService *services.SSOService
+}
+
+func NewSSOHandler(ssoService *services.SSOService) *SSOHandler {
+	return &SSOHandler{
+		ssoService: ssoService,
+	}
+}
+
+// CreateProvider handles registering an identity provider
+// @Summary Register a single sign-on provider
+// @Description Add an OpenID Connect issuer or SAML 2.0 identity provider users can sign in with, with its account linking and role/department mapping rules (Admin only)
+// @Tags Single Sign-On
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateSSOProviderRequest true "Provider details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/sso/providers [post]
+func (h *SSOHandler) CreateProvider(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateSSOProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	provider, err := h.ssoService.CreateProvider(&req, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Provider created successfully",
+		"provider": provider,
+	})
+}
+
+// GetProviders handles listing identity providers
+// @Summary List single sign-on providers
+// @Description List the registered identity providers, with the URLs to configure at each (Admin only)
+// @Tags Single Sign-On
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/sso/providers [get]
+func (h *SSOHandler) GetProviders(c *gin.Context) {
+	providers, err := h.ssoService.GetProviders()
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Providers retrieved successfully",
+		"providers": providers,
+	})
+}
+
+// GetProvider handles fetching one identity provider
+// @Summary Get a single sign-on provider
+// @Description Get an identity provider's settings; secrets are never returned (Admin only)
+// @Tags Single Sign-On
+// @Accept json
+// @Produce json
+// @Param id path string true "Provider ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/sso/providers/{id} [get]
+func (h *SSOHandler) GetProvider(c *gin.Context) {
+	provider, err := h.ssoService.GetProvider(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Provider retrieved successfully",
+		"provider": provider,
+	})
+}
+
+// UpdateProvider handles changing an identity provider
+// @Summary Update a single sign-on provider
+// @Description Change an identity provider's settings; omitted fields are kept and the slug cannot change (Admin only)
+// @Tags Single Sign-On
+// @Accept json
+// @Produce json
+// @Param id path string true "Provider ID"
+// @Param request body dto.UpdateSSOProviderRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/sso/providers/{id} [put]
+func (h *SSOHandler) UpdateProvider(c *gin.Context) {
+	var req dto.UpdateSSOProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	provider, err := h.ssoService.UpdateProvider(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Provider updated successfully",
+		"provider": provider,
+	})
+}
+
+// DeleteProvider handles removing an identity provider
+// @Summary Delete a single sign-on provider
+// @Description Remove an identity provider and unlink the accounts that used it; the accounts themselves are kept (Admin only)
+// @Tags Single Sign-On
+// @Accept json
+// @Produce json
+// @Param id path string true "Provider ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/admin/sso/providers/{id} [delete]
+func (h *SSOHandler) DeleteProvider(c *gin.Context) {
+	if err := h.ssoService.DeleteProvider(c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Provider deleted successfully",
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *SSOHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *SSOHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
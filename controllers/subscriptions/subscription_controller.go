@@ -0,0 +1,541 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/middleware"
+	"crm-go/services/subscriptions"
+)
+
+type SubscriptionHandler struct {
+	subscriptionService *services.SubscriptionService
+}
+
+func NewSubscriptionHandler(subscriptionService *services.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// CreatePlan handles subscription plan creation
+// @Summary Create a subscription plan
+// @Description Create a monthly or yearly plan bundling products or course categories, with optional trial, grace period and payment retries
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param request body dto.CreatePlanRequest true "Plan details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscription-plans [post]
+func (h *SubscriptionHandler) CreatePlan(c *gin.Context) {
+	var req dto.CreatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	plan, err := h.subscriptionService.CreatePlan(&req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Plan created successfully",
+		"plan":    plan,
+	})
+}
+
+// GetPlans handles listing subscription plans
+// @Summary Get subscription plans
+// @Description List subscription plans. Non-admins only see active plans.
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status (admin only)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscription-plans [get]
+func (h *SubscriptionHandler) GetPlans(c *gin.Context) {
+	var params dto.PlanQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	canManage, err := middleware.HasPermission(c, "subscriptions:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if !canManage {
+		params.Status = "active"
+	}
+
+	plans, err := h.subscriptionService.GetPlans(&params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Plans retrieved successfully",
+		"plans":   plans,
+	})
+}
+
+// GetPlanByID handles retrieving a subscription plan
+// @Summary Get a subscription plan
+// @Description Get a subscription plan and what it bundles
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscription-plans/{id} [get]
+func (h *SubscriptionHandler) GetPlanByID(c *gin.Context) {
+	plan, err := h.subscriptionService.GetPlanByID(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Plan retrieved successfully",
+		"plan":    plan,
+	})
+}
+
+// UpdatePlan handles subscription plan updates
+// @Summary Update a subscription plan
+// @Description Update a plan. Price changes apply from each subscriber's next renewal; items replace the bundle.
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Param request body dto.UpdatePlanRequest true "Fields to update"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscription-plans/{id} [put]
+func (h *SubscriptionHandler) UpdatePlan(c *gin.Context) {
+	var req dto.UpdatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	plan, err := h.subscriptionService.UpdatePlan(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Plan updated successfully",
+		"plan":    plan,
+	})
+}
+
+// DeletePlan handles subscription plan deletion
+// @Summary Delete a subscription plan
+// @Description Delete a plan with no live subscriptions
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscription-plans/{id} [delete]
+func (h *SubscriptionHandler) DeletePlan(c *gin.Context) {
+	if err := h.subscriptionService.DeletePlan(c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Plan deleted successfully",
+	})
+}
+
+// Subscribe handles starting a subscription
+// @Summary Subscribe to a plan
+// @Description Start a trial or a paid subscription. Paid plans charge the saved gateway customer; admins may record an external payment reference or subscribe another user.
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param request body dto.SubscribeRequest true "Subscription details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscriptions [post]
+func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "subscriptions:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	var req dto.SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	subscription, err := h.subscriptionService.Subscribe(userID, canManage, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Subscription started successfully",
+		"subscription": subscription,
+	})
+}
+
+// GetMySubscriptions handles listing the signed-in user's subscriptions
+// @Summary Get my subscriptions
+// @Description List the signed-in user's subscriptions
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscriptions/my [get]
+func (h *SubscriptionHandler) GetMySubscriptions(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var params dto.SubscriptionQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+	params.UserID = userID.String()
+
+	subscriptions, err := h.subscriptionService.GetSubscriptions(&params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Subscriptions retrieved successfully",
+		"data":    subscriptions,
+	})
+}
+
+// GetSubscriptions handles listing all subscriptions
+// @Summary Get subscriptions
+// @Description List subscriptions with pagination and filters
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Filter by user ID"
+// @Param plan_id query string false "Filter by plan ID"
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscriptions [get]
+func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	var params dto.SubscriptionQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	subscriptions, err := h.subscriptionService.GetSubscriptions(&params)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Subscriptions retrieved successfully",
+		"data":    subscriptions,
+	})
+}
+
+// GetSubscriptionByID handles retrieving a subscription
+// @Summary Get a subscription
+// @Description Get a subscription by ID. Non-admins may only view their own.
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscriptions/{id} [get]
+func (h *SubscriptionHandler) GetSubscriptionByID(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "subscriptions:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	subscription, err := h.subscriptionService.GetSubscriptionByID(c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Subscription retrieved successfully",
+		"subscription": subscription,
+	})
+}
+
+// CancelSubscription handles cancelling a subscription
+// @Summary Cancel a subscription
+// @Description Stop renewal; access continues to the end of the paid period. Admins may end it immediately.
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param request body dto.CancelSubscriptionRequest false "Cancellation options"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "subscriptions:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	var req dto.CancelSubscriptionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	subscription, err := h.subscriptionService.CancelSubscription(c.Param("id"), userID, canManage, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Subscription cancelled successfully",
+		"subscription": subscription,
+	})
+}
+
+// ResumeSubscription handles undoing a pending cancellation
+// @Summary Resume a subscription
+// @Description Undo a cancellation before the current period ends
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscriptions/{id}/resume [post]
+func (h *SubscriptionHandler) ResumeSubscription(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	canManage, err := middleware.HasPermission(c, "subscriptions:manage")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	subscription, err := h.subscriptionService.ResumeSubscription(c.Param("id"), userID, canManage)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Subscription resumed successfully",
+		"subscription": subscription,
+	})
+}
+
+// RecordPayment handles settling a renewal collected outside the automatic charge
+// @Summary Record a subscription payment
+// @Description Record a renewal payment taken manually, clearing any past-due state and starting the next period
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param request body dto.SubscriptionPaymentRequest true "Payment details"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscriptions/{id}/payments [post]
+func (h *SubscriptionHandler) RecordPayment(c *gin.Context) {
+	var req dto.SubscriptionPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	subscription, err := h.subscriptionService.RecordPayment(c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Payment recorded successfully",
+		"subscription": subscription,
+	})
+}
+
+// RunRenewals handles running the renewal and dunning job on demand
+// @Summary Run subscription renewals
+// @Description Renew due subscriptions, retry failed payments and end lapsed subscriptions now instead of waiting for the background job
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/subscriptions/renewals/run [post]
+func (h *SubscriptionHandler) RunRenewals(c *gin.Context) {
+	result, err := h.subscriptionService.ProcessRenewals(time.Now())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Renewals processed successfully",
+		"result":  result,
+	})
+}
+
+// currentUser reads the authenticated user's ID, writing a 401 when it is missing
+func (h *SubscriptionHandler) currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *SubscriptionHandler) handleError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+	case strings.Contains(msg, "already"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "payment failed"):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "failed to"):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,360 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/teacher_allocation"
+)
+
+type TeacherAllocationHandler struct {
+	allocationService *services.TeacherAllocationService
+}
+
+func NewTeacherAllocationHandler(allocationService *services.TeacherAllocationService) *TeacherAllocationHandler {
+	return &TeacherAllocationHandler{
+		allocationService: allocationService,
+	}
+}
+
+// CreateAllocation handles allocating a teacher to a subject and arm
+// @Summary Create a teacher allocation
+// @Description Allocate a teacher to teach a subject to an arm for the arm's academic session
+// @Tags Teacher Allocations
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateTeacherAllocationRequest true "Allocation request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/teacher-allocations [post]
+func (h *TeacherAllocationHandler) CreateAllocation(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.CreateTeacherAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	allocation, err := h.allocationService.CreateAllocation(&req, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Teacher allocation created successfully",
+		"allocation": allocation,
+	})
+}
+
+// GetAllAllocations handles fetching teacher allocations
+// @Summary Get all teacher allocations
+// @Description Get a paginated list of teacher allocations with optional filtering
+// @Tags Teacher Allocations
+// @Accept json
+// @Produce json
+// @Param teacher_id query string false "Filter by teacher ID"
+// @Param subject_id query string false "Filter by subject ID"
+// @Param arm_id query string false "Filter by arm ID"
+// @Param academic_session_id query string false "Filter by academic session ID"
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} dto.TeacherAllocationListResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/teacher-allocations [get]
+func (h *TeacherAllocationHandler) GetAllAllocations(c *gin.Context) {
+	var params dto.TeacherAllocationQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.allocationService.GetAllAllocations(&params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Teacher allocations retrieved successfully",
+		"data":    response,
+	})
+}
+
+// GetAllocationByID handles fetching a single teacher allocation
+// @Summary Get teacher allocation by ID
+// @Description Get a single teacher allocation by its ID
+// @Tags Teacher Allocations
+// @Accept json
+// @Produce json
+// @Param id path string true "Allocation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/teacher-allocations/{id} [get]
+func (h *TeacherAllocationHandler) GetAllocationByID(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Allocation ID is required",
+		})
+		return
+	}
+
+	allocation, err := h.allocationService.GetAllocationByID(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Teacher allocation retrieved successfully",
+		"allocation": allocation,
+	})
+}
+
+// UpdateAllocation handles updating a teacher allocation
+// @Summary Update a teacher allocation
+// @Description Change the teacher, weekly periods or status of an allocation
+// @Tags Teacher Allocations
+// @Accept json
+// @Produce json
+// @Param id path string true "Allocation ID"
+// @Param request body dto.UpdateTeacherAllocationRequest true "Allocation update request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/teacher-allocations/{id} [put]
+func (h *TeacherAllocationHandler) UpdateAllocation(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Allocation ID is required",
+		})
+		return
+	}
+
+	var req dto.UpdateTeacherAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	allocation, err := h.allocationService.UpdateAllocation(id, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Teacher allocation updated successfully",
+		"allocation": allocation,
+	})
+}
+
+// DeleteAllocation handles deleting a teacher allocation
+// @Summary Delete a teacher allocation
+// @Description Soft delete an allocation and remove its timetable periods
+// @Tags Teacher Allocations
+// @Accept json
+// @Produce json
+// @Param id path string true "Allocation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/teacher-allocations/{id} [delete]
+func (h *TeacherAllocationHandler) DeleteAllocation(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Allocation ID is required",
+		})
+		return
+	}
+
+	if err := h.allocationService.DeleteAllocation(id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Teacher allocation deleted successfully",
+	})
+}
+
+// GetWorkloads handles fetching workload summaries for all teachers
+// @Summary Get teacher workloads
+// @Description Get the teaching load of every allocated teacher in a session (defaults to the current session)
+// @Tags Teacher Allocations
+// @Accept json
+// @Produce json
+// @Param academic_session_id query string false "Academic session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/teacher-allocations/workload [get]
+func (h *TeacherAllocationHandler) GetWorkloads(c *gin.Context) {
+	var params dto.WorkloadQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	workloads, err := h.allocationService.GetWorkloads(&params)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Teacher workloads retrieved successfully",
+		"workloads": workloads,
+	})
+}
+
+// GetTeacherWorkload handles fetching the workload summary of one teacher
+// @Summary Get workload for a teacher
+// @Description Get the allocations and weekly periods of a teacher in a session (defaults to the current session)
+// @Tags Teacher Allocations
+// @Accept json
+// @Produce json
+// @Param teacher_id path string true "Teacher ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/teacher-allocations/teacher/{teacher_id}/workload [get]
+func (h *TeacherAllocationHandler) GetTeacherWorkload(c *gin.Context) {
+	teacherID := c.Param("teacher_id")
+	if teacherID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Teacher ID is required",
+		})
+		return
+	}
+
+	var params dto.WorkloadQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	workload, err := h.allocationService.GetTeacherWorkload(teacherID, &params)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Teacher workload retrieved successfully",
+		"workload": workload,
+	})
+}
@@ -0,0 +1,378 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/services/timetable"
+)
+
+type TimetableHandler struct {
+	timetableService *services.TimetableService
+}
+
+func NewTimetableHandler(timetableService *services.TimetableService) *TimetableHandler {
+	return &TimetableHandler{
+		timetableService: timetableService,
+	}
+}
+
+// GenerateTimetable handles generating timetables from teacher allocations
+// @Summary Generate timetables
+// @Description Assign periods to every active allocation of a session without teacher clashes
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Param request body dto.GenerateTimetableRequest true "Generation request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/timetables/generate [post]
+func (h *TimetableHandler) GenerateTimetable(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.GenerateTimetableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.timetableService.GenerateTimetable(&req, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusCreated
+	message := "Timetable generated successfully"
+	if req.DryRun {
+		status = http.StatusOK
+		message = "Timetable preview generated successfully"
+	}
+
+	c.JSON(status, gin.H{
+		"message":   message,
+		"timetable": result,
+	})
+}
+
+// GetArmTimetable handles fetching the timetable of an arm
+// @Summary Get timetable for an arm
+// @Description Get the weekly timetable of an arm grouped by day
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Param arm_id path string true "Arm ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/timetables/arm/{arm_id} [get]
+func (h *TimetableHandler) GetArmTimetable(c *gin.Context) {
+	armID := c.Param("arm_id")
+	if armID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Arm ID is required",
+		})
+		return
+	}
+
+	var params dto.TimetableQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	timetable, err := h.timetableService.GetArmTimetable(armID, &params)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Timetable retrieved successfully",
+		"timetable": timetable,
+	})
+}
+
+// GetTeacherTimetable handles fetching the timetable of a teacher
+// @Summary Get timetable for a teacher
+// @Description Get the weekly timetable of a teacher grouped by day (defaults to the current session)
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Param teacher_id path string true "Teacher ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/timetables/teacher/{teacher_id} [get]
+func (h *TimetableHandler) GetTeacherTimetable(c *gin.Context) {
+	teacherID := c.Param("teacher_id")
+	if teacherID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Teacher ID is required",
+		})
+		return
+	}
+
+	var params dto.TimetableQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	timetable, err := h.timetableService.GetTeacherTimetable(teacherID, &params)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Timetable retrieved successfully",
+		"timetable": timetable,
+	})
+}
+
+// ClearArmTimetable handles removing the timetable of an arm
+// @Summary Clear timetable for an arm
+// @Description Remove every period of an arm's timetable
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Param arm_id path string true "Arm ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/timetables/arm/{arm_id} [delete]
+func (h *TimetableHandler) ClearArmTimetable(c *gin.Context) {
+	armID := c.Param("arm_id")
+	if armID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Arm ID is required",
+		})
+		return
+	}
+
+	if err := h.timetableService.ClearArmTimetable(armID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Timetable cleared successfully",
+	})
+}
+
+// CreateUnavailability handles blocking a teacher's time
+// @Summary Add teacher unavailability
+// @Description Block a teacher from being timetabled on a day (period 0) or a single period
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateUnavailabilityRequest true "Unavailability request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/timetables/unavailability [post]
+func (h *TimetableHandler) CreateUnavailability(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized: user ID not found",
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.CreateUnavailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	block, err := h.timetableService.CreateUnavailability(&req, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":        "Teacher unavailability saved successfully",
+		"unavailability": block,
+	})
+}
+
+// GetTeacherUnavailability handles listing a teacher's blocked periods
+// @Summary Get teacher unavailability
+// @Description List the days and periods a teacher cannot be timetabled
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Param teacher_id path string true "Teacher ID"
+// @Param academic_session_id query string false "Academic session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/timetables/unavailability/teacher/{teacher_id} [get]
+func (h *TimetableHandler) GetTeacherUnavailability(c *gin.Context) {
+	teacherID := c.Param("teacher_id")
+	if teacherID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Teacher ID is required",
+		})
+		return
+	}
+
+	var params dto.TimetableQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	blocks, err := h.timetableService.GetTeacherUnavailability(teacherID, &params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Teacher unavailability retrieved successfully",
+		"unavailability": blocks,
+	})
+}
+
+// DeleteUnavailability handles removing a blocked period
+// @Summary Delete teacher unavailability
+// @Description Remove a blocked day or period
+// @Tags Timetables
+// @Accept json
+// @Produce json
+// @Param id path string true "Unavailability ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/timetables/unavailability/{id} [delete]
+func (h *TimetableHandler) DeleteUnavailability(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Unavailability ID is required",
+		})
+		return
+	}
+
+	if err := h.timetableService.DeleteUnavailability(id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Teacher unavailability deleted successfully",
+	})
+}
@@ -2,7 +2,10 @@ package topic
 
 import (
 	"crm-go/config"
+	"crm-go/middleware"
 	"crm-go/models"
+	accessServices "crm-go/services/access"
+	rollupServices "crm-go/services/rollup"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -44,6 +47,17 @@ func CreateTopic(c *gin.Context) {
 		return
 	}
 
+	// Order must be free within the lesson
+	if err := config.DB.
+		Where("lesson_id = ? AND \"order\" = ?", input.LessonID, input.Order).
+		First(&existing).Error; err == nil {
+
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "A topic with this order already exists in this lesson",
+		})
+		return
+	}
+
 	topic := models.Topics{
 		ID:          uuid.New(),
 		CourseID:    input.CourseID,
@@ -55,12 +69,38 @@ func CreateTopic(c *gin.Context) {
 		ContentURL:  input.ContentURL,
 		ContentText: input.ContentText,
 		Order:         input.Order,
+		EstimatedDuration: input.EstimatedDuration,
+		Duration:          models.TopicDuration(input.EstimatedDuration, 0),
 	}
 
-	if err := config.DB.Create(&topic).Error; err != nil {
+	// Create the topic and roll its duration up into the module and course together
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := tx.Create(&topic).Error; err != nil {
+		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
 		return
 	}
+	if err := rollupServices.RecomputeModules(tx, topic.ModuleID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save topic"})
+		return
+	}
+
+	// Video lengths are measured in the background and replace the estimate when found
+	if topic.ContentType == "video" {
+		go rollupServices.ProbeTopic(config.DB, topic.ID)
+	}
 
 	c.JSON(http.StatusCreated, models.SuccessResponse{
 		Message: "Topic created successfully",
@@ -69,7 +109,7 @@ func CreateTopic(c *gin.Context) {
 
 // GetTopics godoc
 // @Summary      Get topics
-// @Description  Get all topics, optionally filtered by course or module
+// @Description  Get all topics, optionally filtered by course or module. Content is blanked and the topic marked locked when the viewer cannot open its module.
 // @Tags         Topics
 // @Produce      json
 // @Param        course_id   query   string  false  "Course ID"
@@ -104,9 +144,19 @@ func GetAllTopics(c *gin.Context) {
 		return
 	}
 
+	gate := accessServices.NewAccessService(config.DB).Gate(middleware.CurrentViewer(c))
+
 	responses := make([]models.TopicResponse, 0, len(topics))
 	for _, topic := range topics {
-		responses = append(responses, models.TopicResponse{
+		open, err := gate.CanOpenModuleID(topic.ModuleID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check content access",
+			})
+			return
+		}
+
+		response := models.TopicResponse{
 			ID:          topic.ID,
 			ModuleID:    topic.ModuleID,
 			CourseID:    topic.CourseID,
@@ -117,9 +167,18 @@ func GetAllTopics(c *gin.Context) {
 			ContentType: topic.ContentType,
 			ContentURL:  topic.ContentURL,
 			Order:       topic.Order,
+			EstimatedDuration: topic.EstimatedDuration,
+			VideoDuration:     topic.VideoDuration,
+			Duration:          topic.Duration,
 			CreatedAt:   topic.CreatedAt,
 			UpdatedAt:   topic.UpdatedAt,
-		})
+		}
+		if !open {
+			response.ContentURL = ""
+			response.ContentText = ""
+			response.Locked = true
+		}
+		responses = append(responses, response)
 	}
 
 	c.JSON(http.StatusOK, responses)
@@ -127,7 +186,7 @@ func GetAllTopics(c *gin.Context) {
 
 // GetTopicByID godoc
 // @Summary      Get topic details
-// @Description  Get topic with module and course details
+// @Description  Get topic with module and course details. Content is only included when the viewer can open the topic's module.
 // @Tags         Topics
 // @Produce      json
 // @Param        id path string true "Topic ID"
@@ -161,6 +220,14 @@ func GetTopicByID(c *gin.Context) {
 		return
 	}
 
+	open, err := accessServices.NewAccessService(config.DB).Gate(middleware.CurrentViewer(c)).CanOpenModule(&topic.Module)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check content access",
+		})
+		return
+	}
+
 	// Map to DTO
 	response := models.TopicViewResponse{
 		ID:          topic.ID,
@@ -173,6 +240,9 @@ func GetTopicByID(c *gin.Context) {
 		ContentType: topic.ContentType,
 		ContentURL:  topic.ContentURL,
 		Order:       topic.Order,
+		EstimatedDuration: topic.EstimatedDuration,
+		VideoDuration:     topic.VideoDuration,
+		Duration:          topic.Duration,
 		CreatedAt:   topic.CreatedAt,
 		UpdatedAt:   topic.UpdatedAt,
 		Course: models.CourseMiniResponse{
@@ -185,6 +255,11 @@ func GetTopicByID(c *gin.Context) {
 			ModuleNumber: topic.Module.ModuleNumber,
 		},
 	}
+	if !open {
+		response.ContentURL = ""
+		response.ContentText = ""
+		response.Locked = true
+	}
 
 	c.JSON(http.StatusOK, response)
 }
@@ -200,6 +275,7 @@ func GetTopicByID(c *gin.Context) {
 // @Success      200 {object} models.TopicResponse
 // @Failure      400 {object} models.ErrorResponse
 // @Failure      404 {object} models.NotFoundResponse
+// @Failure      409 {object} models.ConflictResponse
 // @Failure      500 {object} models.FailureResponse
 // @Router       /api/topics/{id} [put]
 // @Security BearerAuth
@@ -233,13 +309,68 @@ func UpdateTopic(c *gin.Context) {
 		return
 	}
 
-	// 3️⃣ Apply updates safely
-	if err := config.DB.Model(&topic).Updates(input).Error; err != nil {
+	// Order must stay free within the lesson
+	var clash models.Topics
+	if err := config.DB.
+		Where("lesson_id = ? AND \"order\" = ? AND id <> ?", topic.LessonID, input.Order, topic.ID).
+		First(&clash).Error; err == nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "A topic with this order already exists in this lesson",
+		})
+		return
+	}
+
+	// A new video needs measuring again; until then the estimate counts
+	videoDuration := topic.VideoDuration
+	if input.ContentType != "video" || input.ContentURL != topic.ContentURL {
+		videoDuration = 0
+	}
+	probe := input.ContentType == "video" && videoDuration == 0
+
+	// 3️⃣ Apply updates and roll the duration up in one transaction
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := tx.Model(&topic).Updates(input).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update topic",
+		})
+		return
+	}
+	if err := tx.Model(&topic).UpdateColumns(map[string]interface{}{
+		"estimated_duration": input.EstimatedDuration,
+		"video_duration":     videoDuration,
+		"duration":           models.TopicDuration(input.EstimatedDuration, videoDuration),
+	}).Error; err != nil {
+		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to update topic",
 		})
 		return
 	}
+	if err := rollupServices.RecomputeModules(tx, topic.ModuleID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update topic",
+		})
+		return
+	}
+
+	if probe {
+		go rollupServices.ProbeTopic(config.DB, topic.ID)
+	}
 
 	// 4️⃣ Reload topic with relations
 	if err := config.DB.
@@ -265,6 +396,9 @@ func UpdateTopic(c *gin.Context) {
 		ContentType: topic.ContentType,
 		ContentURL:  topic.ContentURL,
 		Order:       topic.Order,
+		EstimatedDuration: topic.EstimatedDuration,
+		VideoDuration:     topic.VideoDuration,
+		Duration:          topic.Duration,
 		CreatedAt:   topic.CreatedAt,
 		UpdatedAt:   topic.UpdatedAt,
 
@@ -307,18 +441,40 @@ func DeleteTopic(c *gin.Context) {
 		return
 	}
 
-	result := config.DB.Delete(&models.Topics{}, "id = ?", topicID)
+	var topic models.Topics
+	if err := config.DB.Select("id, module_id").First(&topic, "id = ?", topicID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Topic not found",
+		})
+		return
+	}
+
+	// Delete the topic and take it out of the module and course totals together
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
 
-	if result.Error != nil {
+	if err := tx.Delete(&models.Topics{}, "id = ?", topic.ID).Error; err != nil {
+		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to delete topic",
 		})
 		return
 	}
-
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error: "Topic not found",
+	if err := rollupServices.RecomputeModules(tx, topic.ModuleID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete topic",
 		})
 		return
 	}
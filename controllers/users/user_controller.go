@@ -104,6 +104,56 @@ func (h *UserHandler) GetUsersByRole(c *gin.Context) {
 
 
 
+// UpdateUserStatus handles activating or deactivating a user
+// @Summary Activate or deactivate a user
+// @Description Set whether a user is active. Deactivating a user signs them out of every session.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.UpdateUserStatusRequest true "New status"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/users/{id}/status [put]
+func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
+	var req dto.UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userService.UpdateUserStatus(c.Param("id"), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User status updated successfully",
+		"user":    user,
+	})
+}
+
 // DeleteUser handles deleting a user (soft delete)
 // @Summary Delete a user
 // @Description Soft delete a user by ID
@@ -6,7 +6,11 @@ import (
 
 	"crm-go/config"
 	"crm-go/models"
+	permissionServices "crm-go/services/permissions"
+	rollupServices "crm-go/services/rollup"
 
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -31,24 +35,77 @@ func MigrateDatabase() {
 	}
 
 	// Run migrations to database
+	// Accounts created before email verification existed are treated as verified
+	hadVerifiedAt := db.Migrator().HasColumn(&models.User{}, "verified_at")
+	// Accounts were never marked active before they could be deactivated, so all start out active
+	hadDeactivatedAt := db.Migrator().HasColumn(&models.User{}, "deactivated_at")
 	db.AutoMigrate(&models.User{})
+	if !hadVerifiedAt {
+		db.Exec("UPDATE users SET is_verified = true, verified_at = COALESCE(verified_at, created_at)")
+	}
+	if !hadDeactivatedAt {
+		db.Exec("UPDATE users SET is_active = true")
+	}
+	hashLoginIDs(db)
+	// Reset tokens used to be stored in plain text; outstanding links are dropped with them
+	if db.Migrator().HasColumn(&models.PasswordReset{}, "token") {
+		db.Exec("DELETE FROM password_resets")
+		db.Migrator().DropColumn(&models.PasswordReset{}, "token")
+	}
 	db.AutoMigrate(&models.PasswordReset{})
+	db.AutoMigrate(&models.PasswordHistory{})
+	db.AutoMigrate(&models.LoginAttempt{})
+	db.AutoMigrate(&models.AccountUnlockToken{})
+	// Courses created before the publishing workflow were already live, so they start out published
+	hadCourseStatus := db.Migrator().HasColumn(&models.Course{}, "status")
 	db.AutoMigrate(&models.Course{})
+	if !hadCourseStatus {
+		db.Model(&models.Course{}).Where("status = ?", "draft").Update("status", "published")
+	}
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Category{})
 	db.AutoMigrate(&models.CourseProductTable{})
 	db.AutoMigrate(&models.CourseCategoryTable{})
 	db.AutoMigrate(&models.UserSession{})
+	db.AutoMigrate(&models.RefreshToken{})
+	db.AutoMigrate(&models.UserMFA{})
+	db.AutoMigrate(&models.MFARecoveryCode{})
+	db.AutoMigrate(&models.MFAChallenge{})
+	db.AutoMigrate(&models.TrustedDevice{})
+	db.AutoMigrate(&models.SSOProvider{})
+	db.AutoMigrate(&models.SSOIdentity{})
+	db.AutoMigrate(&models.Role{})
+	db.AutoMigrate(&models.RolePermission{})
+	db.AutoMigrate(&models.RoleAssignment{})
+	db.AutoMigrate(&models.ServiceAccount{})
+	db.AutoMigrate(&models.APIKey{})
+	db.AutoMigrate(&models.APIKeyUsage{})
+	permissionServices.SeedBuiltinRoles(db)
 	db.AutoMigrate(&models.Enrollment{})
 	db.AutoMigrate(&models.ActivityLog{})
 	db.AutoMigrate(&models.Announcement{})
 	db.AutoMigrate(&models.Assignment{})
 	db.AutoMigrate(&models.AssignmentSubmission{})
 	db.AutoMigrate(&models.Module{})
+	renumberSiblings(db, &models.Topics{}, "idx_topic_lesson_order", "topics", `"order"`, "module_id, lesson_id")
+	hadTopicDuration := db.Migrator().HasColumn(&models.Topics{}, "duration")
 	db.AutoMigrate(&models.Topics{})
+	// Material titles used to be unique across all courses, which blocked course copies
+	if db.Migrator().HasIndex(&models.CourseMaterial{}, "idx_course_material_unique") {
+		db.Migrator().DropIndex(&models.CourseMaterial{}, "idx_course_material_unique")
+	}
 	db.AutoMigrate(&models.CourseMaterial{})
 	db.AutoMigrate(&models.DeletedRecord{})
+	renumberSiblings(db, &models.Lesson{}, "idx_lesson_module_order", "lessons", `"order"`, "module_id")
 	db.AutoMigrate(&models.Lesson{})
+	// Module and course totals were never filled in before topics carried a duration
+	if !hadTopicDuration {
+		var courseIDs []uuid.UUID
+		db.Model(&models.Course{}).Pluck("id", &courseIDs)
+		if err := rollupServices.RecomputeCourses(db, courseIDs...); err != nil {
+			log.Printf("⚠️ Failed to backfill course totals: %v", err)
+		}
+	}
 	db.AutoMigrate(&models.Grade{})
 	db.AutoMigrate(&models.LiveClass{})
 	db.AutoMigrate(&models.ObjectiveQuestion{})
@@ -61,7 +118,74 @@ func MigrateDatabase() {
 	db.AutoMigrate(&models.Address{})
 	db.AutoMigrate(&models.AcademicSession{})
 	db.AutoMigrate(&models.GradeSubject{})
+	db.AutoMigrate(&models.ClassMembership{})
+	db.AutoMigrate(&models.Invitation{})
+	db.AutoMigrate(&models.PromotionRun{})
+	db.AutoMigrate(&models.PromotionDecision{})
+	db.AutoMigrate(&models.TeacherAllocation{})
+	db.AutoMigrate(&models.TeacherUnavailability{})
+	db.AutoMigrate(&models.TimetableSlot{})
+	db.AutoMigrate(&models.AttendanceRegister{})
+	db.AutoMigrate(&models.AttendanceRecord{})
+	db.AutoMigrate(&models.Payment{})
+	db.AutoMigrate(&models.FeeSchedule{})
+	db.AutoMigrate(&models.FeeItem{})
+	db.AutoMigrate(&models.Invoice{})
+	db.AutoMigrate(&models.InvoiceLine{})
+	db.AutoMigrate(&models.InvoiceInstallment{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.RefundRequest{})
+	db.AutoMigrate(&models.SubscriptionPlan{})
+	db.AutoMigrate(&models.SubscriptionPlanItem{})
+	db.AutoMigrate(&models.Subscription{})
+	db.AutoMigrate(&models.Cart{})
+	db.AutoMigrate(&models.CartItem{})
+	db.AutoMigrate(&models.Order{})
+	db.AutoMigrate(&models.OrderItem{})
+	db.AutoMigrate(&models.ModuleReleaseRule{})
+	db.AutoMigrate(&models.ModuleCompletion{})
+	db.AutoMigrate(&models.CourseVersion{})
+	db.AutoMigrate(&models.ReviewComment{})
+	db.AutoMigrate(&models.SigningKey{})
+	// Only one key may sign new tokens at a time
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_key_active ON signing_keys (status) WHERE status = 'active'")
 
 	log.Println("✅ Database migrated successfully")
 
 }
+
+// hashLoginIDs replaces login IDs stored in plain text with bcrypt hashes
+func hashLoginIDs(db *gorm.DB) {
+	var users []models.User
+	if err := db.Select("id, login_id").
+		Where("login_id <> '' AND login_id NOT LIKE '$2_$%'").
+		Find(&users).Error; err != nil {
+		log.Printf("⚠️ Could not load login IDs to hash: %v", err)
+		return
+	}
+	for _, user := range users {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(user.LoginID), bcrypt.DefaultCost)
+		if err != nil {
+			log.Printf("⚠️ Could not hash login ID for user %s: %v", user.ID, err)
+			continue
+		}
+		db.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumn("login_id", string(hashed))
+	}
+	if len(users) > 0 {
+		log.Printf("✅ Hashed %d login IDs", len(users))
+	}
+}
+
+// renumberSiblings numbers rows 1..n within each parent, breaking ties by creation time,
+// so the unique order index can be created over data entered before it existed
+func renumberSiblings(db *gorm.DB, model interface{}, index, table, column, partition string) {
+	if !db.Migrator().HasTable(model) || db.Migrator().HasIndex(model, index) {
+		return
+	}
+	db.Exec(fmt.Sprintf(
+		`UPDATE %[1]s t SET %[2]s = r.position
+		FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY %[3]s ORDER BY %[2]s, created_at, id) AS position FROM %[1]s) r
+		WHERE t.id = r.id AND t.%[2]s <> r.position`,
+		table, column, partition,
+	))
+}
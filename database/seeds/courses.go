@@ -49,6 +49,7 @@ func SeedCourses() {
 			TutorID:          tutorID,
 			LearningOutcomes: datatypes.JSON([]byte(`["Understand Go syntax", "Work with Go routines", "Build a simple web server"]`)),
 			Requirements:     datatypes.JSON([]byte(`["Basic programming knowledge", "Familiarity with command line", "Willingness to learn", "No prior Go experience required"]`)),
+			Status:           "published",
 		},
 		{
 			ID:               courseID2,
@@ -59,6 +60,7 @@ func SeedCourses() {
 			TutorID:          tutorID,
 			LearningOutcomes: datatypes.JSON([]byte(`["Understand advanced React patterns", "State management with Redux", "Performance optimization"]`)),
 			Requirements:     datatypes.JSON([]byte(`["Basic React knowledge", "Familiarity with JavaScript ES6+", "Understanding of web development concepts", "Experience with building React applications"]`)),
+			Status:           "published",
 		},
 		{
 			ID:               courseID3,
@@ -69,6 +71,7 @@ func SeedCourses() {
 			TutorID:          tutorID,
 			LearningOutcomes: datatypes.JSON([]byte(`["Understand Python basics", "Data manipulation with Pandas", "Data visualization with Matplotlib"]`)),
 			Requirements:     datatypes.JSON([]byte(`["Basic programming knowledge", "Familiarity with command line", "Willingness to learn", "No prior Python experience required"]`)),
+			Status:           "published",
 		},
 		{
 			ID:          courseID4,
@@ -79,6 +82,7 @@ func SeedCourses() {
 			TutorID:     tutorID,
 			LearningOutcomes: datatypes.JSON([]byte(`[ "Set up FastAPI project", "Create API endpoints", "Validate requests with Pydantic", "Handle path and query parameters", "Deploy FastAPI applications"]`)),
 			Requirements: datatypes.JSON([]byte(`[ "Basic Python knowledge", "Familiarity with web development concepts", "Understanding of REST APIs", "No prior FastAPI experience required"]`)),
+			Status:      "published",
 		},
 		{
 			ID:          courseID5,
@@ -89,6 +93,7 @@ func SeedCourses() {
 			TutorID:     tutorID,
 			LearningOutcomes: datatypes.JSON([]byte(`[ "Collect and clean datasets", "Perform exploratory data analysis", "Use Python libraries like Pandas and Matplotlib", "Create insightful data visualizations", "Draw actionable conclusions from data"]`)),
 			Requirements: datatypes.JSON([]byte(`[ "Basic Python knowledge", "Familiarity with spreadsheets or data concepts", "Willingness to learn Python libraries", "No prior data analysis experience required"]`)),
+			Status:      "published",
 		},
 	}
 
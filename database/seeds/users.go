@@ -44,10 +44,11 @@ func SeedUsers() {
 			LastName: 	"Okereke",
 			Email:       "eokereke47@gmail.com",
         	Password:  	 hashPassword("mypassword"), 
-			LoginID:  "QWERTY",
+			LoginID:  hashPassword("QWERTY"),
 			Picture:     "https://lh3.googleusercontent.com/a/ACg8ocIucwnbi0gu-NdunUN5er6sqCwOouqNOuQ2dpU-1qR_yH0Kpw=s96-c",
 			Role:        "admin",
 			Provider:   "local",
+			IsVerified:  true,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
@@ -57,10 +58,11 @@ func SeedUsers() {
 			LastName: 	"Chigoziem",
 			Email:       "upskill@ehizuahub.com",
         	Password:  	 hashPassword("mypassword"), 
-			LoginID:  "QWERTY",
+			LoginID:  hashPassword("QWERTY"),
 			Picture:     "https://lh3.googleusercontent.com/a/ACg8ocIucwnbi0gu-NdunUN5er6sqCwOouqNOuQ2dpU-1qR_yH0Kpw=s96-c",
 			Role:        "staff",
 			Provider:   "local",
+			IsVerified:  true,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
@@ -70,10 +72,11 @@ func SeedUsers() {
 			LastName: 	"Kachimside",
 			Email:       "hanniebeke47@gmail.com",
         	Password:  	 hashPassword("mypassword"), 
-			LoginID:  "QWERTY",
+			LoginID:  hashPassword("QWERTY"),
 			Picture:     "https://lh3.googleusercontent.com/a/ACg8ocIucwnbi0gu-NdunUN5er6sqCwOouqNOuQ2dpU-1qR_yH0Kpw=s96-c",
 			Role:        "student",
 			Provider:   "local",
+			IsVerified:  true,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
@@ -0,0 +1,84 @@
+// dto/api_key_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// CreateServiceAccountRequest represents the request body for adding a service account
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=100"`
+	Description string `json:"description"`
+	Role        string `json:"role" binding:"required,max=20"` // decides what the account's keys may do
+}
+
+// UpdateServiceAccountRequest represents the request body for changing a service account.
+// Disabling an account stops all of its keys without revoking them.
+type UpdateServiceAccountRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=2,max=100"`
+	Description *string `json:"description"`
+	Role        *string `json:"role" binding:"omitempty,max=20"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// ServiceAccountResponse represents a service account
+type ServiceAccountResponse struct {
+	ID          string           `json:"id"`
+	UserID      string           `json:"user_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Role        string           `json:"role"`
+	IsActive    bool             `json:"is_active"`
+	DisabledAt  *time.Time       `json:"disabled_at,omitempty"`
+	ActiveKeys  int64            `json:"active_keys"`
+	Keys        []APIKeyResponse `json:"keys,omitempty"`
+	CreatedBy   string           `json:"created_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// CreateAPIKeyRequest represents the request body for issuing an API key. Scopes are
+// permissions from the catalog; the key can do nothing else, even when the account's role
+// allows more.
+type CreateAPIKeyRequest struct {
+	Name               string     `json:"name" binding:"required,max=100"`
+	Scopes             []string   `json:"scopes" binding:"required,min=1,dive,required,max=100"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute" binding:"omitempty,min=1,max=100000"` // defaults to API_KEY_RATE_LIMIT
+	ExpiresAt          *time.Time `json:"expires_at"`                                                 // defaults to API_KEY_EXPIRY_DAYS from now
+	NeverExpires       bool       `json:"never_expires"`
+}
+
+// APIKeyResponse represents an API key without its secret
+type APIKeyResponse struct {
+	ID                 string     `json:"id"`
+	ServiceAccountID   string     `json:"service_account_id"`
+	Name               string     `json:"name"`
+	Prefix             string     `json:"prefix"`
+	Scopes             []string   `json:"scopes"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt         *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP         string     `json:"last_used_ip,omitempty"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	Status             string     `json:"status"` // active, expired or revoked
+	CreatedBy          string     `json:"created_by"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse carries a new key. The key is shown only this once.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipal is who an API key authenticates as
+type APIKeyPrincipal struct {
+	KeyID              string
+	ServiceAccountID   string
+	UserID             string
+	Role               string
+	Scopes             []string
+	RateLimitPerMinute int
+	Remaining          int // requests left in the current minute
+	ResetAt            time.Time
+}
@@ -0,0 +1,204 @@
+// dto/billing_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// FeeItemRequest represents a charge on a fee schedule
+type FeeItemRequest struct {
+	Name     string  `json:"name" binding:"required,min=2,max=255"`
+	Category string  `json:"category" binding:"required,oneof=tuition levy optional"`
+	Amount   float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// CreateFeeScheduleRequest represents the request body for creating a fee schedule
+type CreateFeeScheduleRequest struct {
+	Name                   string           `json:"name" binding:"required,min=2,max=255"`
+	ClassGradeID           string           `json:"class_grade_id" binding:"required"`
+	AcademicSessionID      string           `json:"academic_session_id" binding:"required"`
+	Term                   string           `json:"term" binding:"required,max=50"`
+	Currency               string           `json:"currency" binding:"omitempty,len=3"`
+	SiblingDiscountPercent float64          `json:"sibling_discount_percent" binding:"min=0,max=100"`
+	DueDate                string           `json:"due_date"` // Format: "2006-01-02"
+	Items                  []FeeItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// UpdateFeeScheduleRequest represents the request body for updating a fee schedule
+type UpdateFeeScheduleRequest struct {
+	Name                   string           `json:"name" binding:"omitempty,min=2,max=255"`
+	SiblingDiscountPercent *float64         `json:"sibling_discount_percent" binding:"omitempty,min=0,max=100"`
+	DueDate                string           `json:"due_date"` // Format: "2006-01-02"
+	Status                 string           `json:"status" binding:"omitempty,oneof=active inactive"`
+	Items                  []FeeItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+// FeeItemResponse represents a fee schedule item
+type FeeItemResponse struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+}
+
+// FeeScheduleResponse represents the fee schedule response
+type FeeScheduleResponse struct {
+	ID                     string            `json:"id"`
+	Name                   string            `json:"name"`
+	ClassGradeID           string            `json:"class_grade_id"`
+	AcademicSessionID      string            `json:"academic_session_id"`
+	Term                   string            `json:"term"`
+	Currency               string            `json:"currency"`
+	SiblingDiscountPercent float64           `json:"sibling_discount_percent"`
+	DueDate                *time.Time        `json:"due_date,omitempty"`
+	Status                 string            `json:"status"`
+	RequiredTotal          float64           `json:"required_total"`
+	Items                  []FeeItemResponse `json:"items"`
+	CreatedBy              string            `json:"created_by"`
+	CreatedAt              time.Time         `json:"created_at"`
+	UpdatedAt              time.Time         `json:"updated_at"`
+}
+
+// FeeScheduleQueryParams represents query parameters for filtering fee schedules
+type FeeScheduleQueryParams struct {
+	ClassGradeID      string `form:"class_grade_id"`
+	AcademicSessionID string `form:"academic_session_id"`
+	Term              string `form:"term"`
+	Status            string `form:"status"`
+}
+
+// InstallmentRequest represents one part-payment in an installment plan
+type InstallmentRequest struct {
+	DueDate string  `json:"due_date" binding:"required"` // Format: "2006-01-02"
+	Amount  float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// GenerateInvoicesRequest represents the request body for billing students against a schedule
+type GenerateInvoicesRequest struct {
+	ScheduleID      string               `json:"schedule_id" binding:"required"`
+	StudentIDs      []string             `json:"student_ids"`       // defaults to every student seated in the grade
+	OptionalItemIDs []string             `json:"optional_item_ids"` // optional items to include
+	Installments    []InstallmentRequest `json:"installments" binding:"omitempty,dive"`
+}
+
+// SetInstallmentsRequest represents the request body for replacing an invoice's installment plan
+type SetInstallmentsRequest struct {
+	Installments []InstallmentRequest `json:"installments" binding:"required,min=1,dive"`
+}
+
+// RecordPaymentRequest represents the request body for recording a payment against an invoice
+type RecordPaymentRequest struct {
+	Amount           float64 `json:"amount" binding:"required,gt=0"`
+	PaymentMethod    string  `json:"payment_method" binding:"required,oneof=credit_card debit_card paypal bank_transfer wallet crypto cash check"`
+	Gateway          string  `json:"gateway" binding:"omitempty,oneof=stripe paypal razorpay paystack flutterwave bank manual"`
+	GatewayReference string  `json:"gateway_reference" binding:"max=200"`
+}
+
+// InvoiceLineResponse represents a line on an invoice
+type InvoiceLineResponse struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	Amount      float64 `json:"amount"`
+}
+
+// InstallmentResponse represents an installment on an invoice
+type InstallmentResponse struct {
+	ID         string    `json:"id"`
+	Sequence   int       `json:"sequence"`
+	DueDate    time.Time `json:"due_date"`
+	Amount     float64   `json:"amount"`
+	AmountPaid float64   `json:"amount_paid"`
+	Status     string    `json:"status"`
+}
+
+// InvoiceResponse represents the invoice response
+type InvoiceResponse struct {
+	ID                string                `json:"id"`
+	InvoiceNumber     string                `json:"invoice_number"`
+	StudentID         string                `json:"student_id"`
+	StudentName       string                `json:"student_name,omitempty"`
+	ScheduleID        string                `json:"schedule_id"`
+	AcademicSessionID string                `json:"academic_session_id"`
+	Term              string                `json:"term"`
+	Currency          string                `json:"currency"`
+	Subtotal          float64               `json:"subtotal"`
+	DiscountAmount    float64               `json:"discount_amount"`
+	TotalAmount       float64               `json:"total_amount"`
+	AmountPaid        float64               `json:"amount_paid"`
+	Balance           float64               `json:"balance"`
+	Status            string                `json:"status"`
+	DueDate           *time.Time            `json:"due_date,omitempty"`
+	IssuedAt          time.Time             `json:"issued_at"`
+	CancelledAt       *time.Time            `json:"cancelled_at,omitempty"`
+	Lines             []InvoiceLineResponse `json:"lines"`
+	Installments      []InstallmentResponse `json:"installments"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+}
+
+// InvoiceListResponse represents paginated invoice list response
+type InvoiceListResponse struct {
+	Invoices   []InvoiceResponse `json:"invoices"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
+}
+
+// InvoiceQueryParams represents query parameters for filtering invoices
+type InvoiceQueryParams struct {
+	StudentID         string `form:"student_id"`
+	ScheduleID        string `form:"schedule_id"`
+	AcademicSessionID string `form:"academic_session_id"`
+	Term              string `form:"term"`
+	Status            string `form:"status"`
+	Page              int    `form:"page"`
+	Limit             int    `form:"limit"`
+}
+
+// GenerateInvoicesResponse represents the outcome of bulk invoice generation
+type GenerateInvoicesResponse struct {
+	ScheduleID string            `json:"schedule_id"`
+	Created    int               `json:"created"`
+	Skipped    []string          `json:"skipped"` // students already invoiced for the schedule
+	Invoices   []InvoiceResponse `json:"invoices"`
+}
+
+// PaymentResponse represents a payment recorded against an invoice
+type PaymentResponse struct {
+	ID               string     `json:"id"`
+	PaymentID        string     `json:"payment_id"`
+	InvoiceID        string     `json:"invoice_id"`
+	Amount           float64    `json:"amount"`
+	Currency         string     `json:"currency"`
+	PaymentMethod    string     `json:"payment_method"`
+	Gateway          string     `json:"gateway"`
+	GatewayReference string     `json:"gateway_reference,omitempty"`
+	Status           string     `json:"status"`
+	ProcessedAt      *time.Time `json:"processed_at,omitempty"`
+}
+
+// LedgerEntryResponse represents a ledger movement with the running balance after it
+type LedgerEntryResponse struct {
+	ID          string    `json:"id"`
+	EntryType   string    `json:"entry_type"`
+	InvoiceID   *string   `json:"invoice_id,omitempty"`
+	PaymentID   *string   `json:"payment_id,omitempty"`
+	Description string    `json:"description"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Balance     float64   `json:"balance"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StatementResponse represents a student's account statement
+type StatementResponse struct {
+	StudentID           string                `json:"student_id"`
+	StudentName         string                `json:"student_name"`
+	TotalDebits         float64               `json:"total_debits"`
+	TotalCredits        float64               `json:"total_credits"`
+	OutstandingBalance  float64               `json:"outstanding_balance"`
+	OutstandingInvoices []InvoiceResponse     `json:"outstanding_invoices"`
+	Entries             []LedgerEntryResponse `json:"entries"`
+}
@@ -0,0 +1,89 @@
+// dto/class_attendance_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// AttendanceMark represents one student's mark in a register submission
+type AttendanceMark struct {
+	StudentID string `json:"student_id" binding:"required"`
+	Status    string `json:"status" binding:"required,oneof=present absent late excused"`
+	Reason    string `json:"reason" binding:"max=255"`
+}
+
+// SubmitAttendanceRequest represents the request body for taking an arm's register
+type SubmitAttendanceRequest struct {
+	ArmID             string           `json:"arm_id" binding:"required"`
+	Date              string           `json:"date" binding:"required"` // Format: "2006-01-02"
+	Period            int              `json:"period" binding:"min=0,max=20"`
+	Marks             []AttendanceMark `json:"marks" binding:"required,min=1,dive"`
+	MarkOthersPresent bool             `json:"mark_others_present"`
+}
+
+// AttendanceRecordResponse represents a single student's mark
+type AttendanceRecordResponse struct {
+	ID                 string        `json:"id"`
+	StudentID          string        `json:"student_id"`
+	Status             string        `json:"status"`
+	Reason             string        `json:"reason,omitempty"`
+	GuardianNotifiedAt *time.Time    `json:"guardian_notified_at,omitempty"`
+	Student            *UserResponse `json:"student,omitempty"`
+}
+
+// AttendanceRegisterResponse represents a register with its marks
+type AttendanceRegisterResponse struct {
+	ID                string                     `json:"id"`
+	ArmID             string                     `json:"arm_id"`
+	AcademicSessionID string                     `json:"academic_session_id"`
+	Date              string                     `json:"date"`
+	Period            int                        `json:"period"`
+	LocksAt           time.Time                  `json:"locks_at"`
+	Locked            bool                       `json:"locked"`
+	TakenBy           string                     `json:"taken_by"`
+	UpdatedBy         *string                    `json:"updated_by,omitempty"`
+	Present           int                        `json:"present"`
+	Absent            int                        `json:"absent"`
+	Late              int                        `json:"late"`
+	Excused           int                        `json:"excused"`
+	Records           []AttendanceRecordResponse `json:"records"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+}
+
+// AttendanceRegisterQueryParams represents query parameters for listing an arm's registers
+type AttendanceRegisterQueryParams struct {
+	From   string `form:"from"` // Format: "2006-01-02"
+	To     string `form:"to"`   // Format: "2006-01-02"
+	Period *int   `form:"period" binding:"omitempty,min=0"`
+}
+
+// AttendanceSummaryQueryParams represents query parameters for attendance summaries
+type AttendanceSummaryQueryParams struct {
+	AcademicSessionID string `form:"academic_session_id"`
+	From              string `form:"from"` // Format: "2006-01-02", defaults to the session start
+	To                string `form:"to"`   // Format: "2006-01-02", defaults to the session end
+	Period            int    `form:"period" binding:"min=0"`
+}
+
+// StudentAttendanceSummary represents a student's attendance totals over a term
+type StudentAttendanceSummary struct {
+	StudentID   string  `json:"student_id"`
+	StudentName string  `json:"student_name"`
+	Total       int     `json:"total"`
+	Present     int     `json:"present"`
+	Absent      int     `json:"absent"`
+	Late        int     `json:"late"`
+	Excused     int     `json:"excused"`
+	Percentage  float64 `json:"percentage"`
+}
+
+// ArmAttendanceSummaryResponse represents the attendance totals of every student in an arm
+type ArmAttendanceSummaryResponse struct {
+	ArmID             string                     `json:"arm_id"`
+	AcademicSessionID string                     `json:"academic_session_id"`
+	From              string                     `json:"from"`
+	To                string                     `json:"to"`
+	Registers         int                        `json:"registers"`
+	Students          []StudentAttendanceSummary `json:"students"`
+}
@@ -0,0 +1,85 @@
+// dto/class_membership_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// PlaceStudentRequest represents the request body for placing a student in an arm
+type PlaceStudentRequest struct {
+	StudentID string `json:"student_id" binding:"required"`
+	ArmID     string `json:"arm_id" binding:"required"`
+	Reason    string `json:"reason" binding:"max=255"`
+}
+
+// BulkPlaceStudentsRequest represents the request body for placing several students in one arm
+type BulkPlaceStudentsRequest struct {
+	StudentIDs []string `json:"student_ids" binding:"required,min=1"`
+	ArmID      string   `json:"arm_id" binding:"required"`
+}
+
+// TransferStudentRequest represents the request body for moving a student to another arm
+type TransferStudentRequest struct {
+	ArmID  string `json:"arm_id" binding:"required"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// WithdrawStudentRequest represents the request body for withdrawing a student from an arm
+type WithdrawStudentRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// BalanceArmsRequest represents the request body for redistributing students across a grade's arms
+type BalanceArmsRequest struct {
+	GradeID  string `json:"grade_id" binding:"required"`
+	Strategy string `json:"strategy" binding:"required,oneof=gender surname"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// ClassMembershipResponse represents the class membership response
+type ClassMembershipResponse struct {
+	ID                string        `json:"id"`
+	StudentID         string        `json:"student_id"`
+	ArmID             string        `json:"arm_id"`
+	AcademicSessionID string        `json:"academic_session_id"`
+	Status            string        `json:"status"`
+	Reason            string        `json:"reason,omitempty"`
+	PlacedAt          time.Time     `json:"placed_at"`
+	WithdrawnAt       *time.Time    `json:"withdrawn_at,omitempty"`
+	CreatedBy         string        `json:"created_by"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	Student           *UserResponse `json:"student,omitempty"`
+	Arm               *ArmResponse  `json:"arm,omitempty"`
+}
+
+// ClassListResponse represents the students currently seated in an arm
+type ClassListResponse struct {
+	ArmID     string                    `json:"arm_id"`
+	ArmName   string                    `json:"arm_name"`
+	GradeID   string                    `json:"grade_id"`
+	Capacity  int                       `json:"capacity"`
+	Occupied  int                       `json:"occupied"`
+	Available int                       `json:"available"`
+	Students  []ClassMembershipResponse `json:"students"`
+}
+
+// BalanceAssignment represents a single student's seat after balancing
+type BalanceAssignment struct {
+	StudentID   string `json:"student_id"`
+	StudentName string `json:"student_name"`
+	Gender      string `json:"gender,omitempty"`
+	FromArmID   string `json:"from_arm_id"`
+	ToArmID     string `json:"to_arm_id"`
+	Moved       bool   `json:"moved"`
+}
+
+// BalanceArmsResponse represents the outcome of balancing a grade
+type BalanceArmsResponse struct {
+	GradeID     string              `json:"grade_id"`
+	Strategy    string              `json:"strategy"`
+	DryRun      bool                `json:"dry_run"`
+	TotalMoved  int                 `json:"total_moved"`
+	ArmCounts   map[string]int      `json:"arm_counts"`
+	Assignments []BalanceAssignment `json:"assignments"`
+}
@@ -0,0 +1,60 @@
+// dto/course_version_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// CloneCourseRequest represents the request body for copying a course for a new cohort
+type CloneCourseRequest struct {
+	Title   string `json:"title" binding:"max=255"` // defaults to "<title> (Copy)"
+	TutorID string `json:"tutor_id"`                // admins may hand the copy to another tutor
+}
+
+// CloneSummary counts the rows copied into a new course
+type CloneSummary struct {
+	Modules      int `json:"modules"`
+	Lessons      int `json:"lessons"`
+	Topics       int `json:"topics"`
+	Materials    int `json:"materials"`
+	Assignments  int `json:"assignments"`
+	Questions    int `json:"questions"`
+	Options      int `json:"options"`
+	ReleaseRules int `json:"release_rules"`
+}
+
+// CloneCourseResponse represents a copied course and the ID remapping applied to it
+type CloneCourseResponse struct {
+	CourseID       string            `json:"course_id"`
+	SourceCourseID string            `json:"source_course_id"`
+	Title          string            `json:"title"`
+	TutorID        string            `json:"tutor_id"`
+	Copied         CloneSummary      `json:"copied"`
+	IDMap          map[string]string `json:"id_map"` // source ID to copy ID for every copied row
+}
+
+// CreateCourseVersionRequest represents the request body for starting a draft version of a course
+type CreateCourseVersionRequest struct {
+	Notes string `json:"notes" binding:"max=2000"`
+}
+
+// CourseVersionResponse represents a course version
+type CourseVersionResponse struct {
+	ID             string        `json:"id"`
+	LineageID      string        `json:"lineage_id"`
+	CourseID       string        `json:"course_id"`
+	CourseTitle    string        `json:"course_title,omitempty"`
+	SourceCourseID *string       `json:"source_course_id,omitempty"`
+	VersionNumber  int           `json:"version_number"`
+	Status         string        `json:"status"`
+	Notes          string        `json:"notes,omitempty"`
+	CreatedBy      string        `json:"created_by"`
+	SubmittedAt    *time.Time    `json:"submitted_at,omitempty"`
+	PublishedAt    *time.Time    `json:"published_at,omitempty"`
+	PublishedBy    *string       `json:"published_by,omitempty"`
+	DiscardedAt    *time.Time    `json:"discarded_at,omitempty"`
+	Enrollments    int64         `json:"enrollments"` // students pinned to this version
+	Copied         *CloneSummary `json:"copied,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
@@ -0,0 +1,104 @@
+// dto/guardian_portal_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// WardResponse represents a student linked to the signed-in guardian
+type WardResponse struct {
+	StudentID    string        `json:"student_id"`
+	Relationship string        `json:"relationship"`
+	IsPrimary    bool          `json:"is_primary"`
+	IsEmergency  bool          `json:"is_emergency"`
+	Student      *UserResponse `json:"student,omitempty"`
+	CurrentArm   *ArmResponse  `json:"current_arm,omitempty"`
+}
+
+// WardQueryParams represents query parameters shared by the guardian portal views
+type WardQueryParams struct {
+	AcademicSessionID string `form:"academic_session_id"`
+	From              string `form:"from"` // Format: "2006-01-02"
+	To                string `form:"to"`   // Format: "2006-01-02"
+}
+
+// WardGradeResponse represents a single graded piece of work
+type WardGradeResponse struct {
+	ID           string    `json:"id"`
+	CourseID     string    `json:"course_id"`
+	CourseTitle  string    `json:"course_title"`
+	AssignmentID *string   `json:"assignment_id,omitempty"`
+	Score        float64   `json:"score"`
+	Grade        string    `json:"grade"`
+	Remarks      string    `json:"remarks"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ReportCardLine represents a course's result on a report card
+type ReportCardLine struct {
+	CourseID     string  `json:"course_id"`
+	CourseTitle  string  `json:"course_title"`
+	Assessments  int     `json:"assessments"`
+	AverageScore float64 `json:"average_score"`
+	Grade        string  `json:"grade"`
+}
+
+// ReportCardResponse represents a ward's term report card
+type ReportCardResponse struct {
+	StudentID         string                   `json:"student_id"`
+	StudentName       string                   `json:"student_name"`
+	AcademicSessionID string                   `json:"academic_session_id"`
+	From              string                   `json:"from"`
+	To                string                   `json:"to"`
+	Arm               *ArmResponse             `json:"arm,omitempty"`
+	Lines             []ReportCardLine         `json:"lines"`
+	OverallAverage    float64                  `json:"overall_average"`
+	Attendance        StudentAttendanceSummary `json:"attendance"`
+}
+
+// WardAssignmentResponse represents an assignment and the ward's submission status
+type WardAssignmentResponse struct {
+	AssignmentID string     `json:"assignment_id"`
+	CourseID     string     `json:"course_id"`
+	CourseTitle  string     `json:"course_title"`
+	Title        string     `json:"title"`
+	Type         string     `json:"type"`
+	DueDate      time.Time  `json:"due_date"`
+	Status       string     `json:"status"` // not_submitted, overdue, or the submission status
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+}
+
+// WardPaymentResponse represents a payment made for a ward
+type WardPaymentResponse struct {
+	ID            string     `json:"id"`
+	PaymentID     string     `json:"payment_id"`
+	Amount        float64    `json:"amount"`
+	Currency      string     `json:"currency"`
+	Status        string     `json:"status"`
+	PaymentMethod string     `json:"payment_method"`
+	CourseName    string     `json:"course_name,omitempty"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// WardFeesResponse represents a ward's fee position
+type WardFeesResponse struct {
+	StudentID           string                `json:"student_id"`
+	OutstandingBalance  float64               `json:"outstanding_balance"`
+	OutstandingInvoices []InvoiceResponse     `json:"outstanding_invoices"`
+	TotalPaid           float64               `json:"total_paid"`
+	Payments            []WardPaymentResponse `json:"payments"`
+}
+
+// PortalAnnouncementResponse represents an announcement visible to guardians
+type PortalAnnouncementResponse struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	Type      string     `json:"type"`
+	Audience  string     `json:"audience"`
+	IsPinned  bool       `json:"is_pinned"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
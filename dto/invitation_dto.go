@@ -0,0 +1,65 @@
+// dto/invitation_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// CreateInvitationRequest represents the request body for inviting someone to create an account.
+// ArmID and CourseID are optional and only apply to tutor and student invitations.
+type CreateInvitationRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Role      string `json:"role" binding:"required,oneof=admin staff tutor student"`
+	FirstName string `json:"first_name" binding:"max=100"`
+	LastName  string `json:"last_name" binding:"max=100"`
+	ArmID     string `json:"arm_id"`
+	CourseID  string `json:"course_id"`
+}
+
+// AcceptInvitationRequest represents the request body for creating an account from an invitation
+type AcceptInvitationRequest struct {
+	Token     string `json:"token" binding:"required"`
+	FirstName string `json:"first_name" binding:"max=100"` // defaults to the name on the invitation
+	LastName  string `json:"last_name" binding:"max=100"`
+	Password  string `json:"password" binding:"required"` // checked against the password policy
+}
+
+// InvitationResponse represents an invitation as seen by admins
+type InvitationResponse struct {
+	ID             string     `json:"id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	ArmID          *string    `json:"arm_id,omitempty"`
+	CourseID       *string    `json:"course_id,omitempty"`
+	Status         string     `json:"status"` // pending, accepted, revoked, expired
+	InvitedBy      string     `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	SentAt         time.Time  `json:"sent_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedUserID *string    `json:"accepted_user_id,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// InvitationPreviewResponse is what the accept page shows before the password is set
+type InvitationPreviewResponse struct {
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	ArmName     string    `json:"arm_name,omitempty"`
+	CourseTitle string    `json:"course_title,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// VerifyEmailRequest represents the request body for confirming an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest represents the request body for sending a new verification link
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
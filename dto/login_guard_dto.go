@@ -0,0 +1,49 @@
+// dto/login_guard_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// LoginAttemptInfo describes a sign-in try for brute-force tracking
+type LoginAttemptInfo struct {
+	Email        string
+	IPAddress    string
+	UserAgent    string
+	Method       string // password, login_id
+	CaptchaToken string
+}
+
+// LoginGuardStatus tells the client what the next sign-in attempt needs
+type LoginGuardStatus struct {
+	CaptchaRequired bool       `json:"captcha_required"`
+	RetryAfter      int        `json:"retry_after,omitempty"` // seconds before the next attempt is accepted
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+}
+
+// UnlockAccountRequest represents the request body for unlocking an account from the emailed link
+type UnlockAccountRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// LoginAttemptResponse represents a recorded sign-in try
+type LoginAttemptResponse struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Method    string    `json:"method"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginSecurityResponse summarises an account's failed sign-ins and lockout
+type LoginSecurityResponse struct {
+	UserID            string                 `json:"user_id"`
+	FailedLoginCount  int                    `json:"failed_login_count"`
+	LastFailedLoginAt *time.Time             `json:"last_failed_login_at,omitempty"`
+	LockedUntil       *time.Time             `json:"locked_until,omitempty"`
+	Locked            bool                   `json:"locked"`
+	RecentAttempts    []LoginAttemptResponse `json:"recent_attempts"`
+}
@@ -0,0 +1,85 @@
+// dto/mfa_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// MFAChallengeResponse is the first half of a two-step sign-in: the password was right and a
+// second factor is needed. The challenge token goes back with the code.
+type MFAChallengeResponse struct {
+	MFARequired    bool      `json:"mfa_required"`
+	ChallengeToken string    `json:"challenge_token"`
+	Methods        []string  `json:"methods"` // totp, email, recovery
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// LoginResult is either a new session or a second-factor challenge
+type LoginResult struct {
+	Challenge     *MFAChallengeResponse
+	Tokens        *TokenPair
+	TrustedDevice *TrustedDeviceGrant
+}
+
+// TrustedDeviceGrant is the token that lets a remembered device skip the second factor
+type TrustedDeviceGrant struct {
+	Token     string    `json:"trusted_device_token"`
+	ExpiresAt time.Time `json:"trusted_device_expires_at"`
+}
+
+// MFAEmailCodeRequest represents the request body for emailing a sign-in code
+type MFAEmailCodeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// MFAVerifyRequest represents the request body for completing a two-step sign-in
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Method         string `json:"method" binding:"required,oneof=totp email recovery"`
+	Code           string `json:"code" binding:"required"`
+	RememberDevice bool   `json:"remember_device"`
+}
+
+// MFACodeRequest represents a request confirmed with a current TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAEmailSettingRequest represents the request body for turning emailed sign-in codes on or off
+type MFAEmailSettingRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// MFAStatusResponse describes a user's second-factor settings
+type MFAStatusResponse struct {
+	Required               bool       `json:"required"` // the user's role must use a second factor
+	TOTPEnabled            bool       `json:"totp_enabled"`
+	TOTPConfirmedAt        *time.Time `json:"totp_confirmed_at,omitempty"`
+	EmailOTPEnabled        bool       `json:"email_otp_enabled"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+	TrustedDevices         int64      `json:"trusted_devices"`
+}
+
+// TOTPSetupResponse carries a new authenticator secret. Render ProvisioningURI as a QR code.
+type TOTPSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse carries freshly generated recovery codes; they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TrustedDeviceResponse represents a remembered device
+type TrustedDeviceResponse struct {
+	ID         string    `json:"id"`
+	DeviceType string    `json:"device_type"`
+	DeviceOS   string    `json:"device_os"`
+	Browser    string    `json:"browser"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"` // the device making this request
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+// dto/module_release_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// SetReleaseRuleRequest represents the request body for setting a module's release rule.
+// after_enrollment needs delay_days, fixed_date needs release_at, and prerequisite needs
+// a prerequisite module, a prerequisite quiz or exam with a minimum score, or both.
+type SetReleaseRuleRequest struct {
+	RuleType                 string     `json:"rule_type" binding:"required,oneof=after_enrollment fixed_date prerequisite"`
+	DelayDays                int        `json:"delay_days" binding:"min=0,max=3650"`
+	ReleaseAt                *time.Time `json:"release_at"`
+	PrerequisiteModuleID     string     `json:"prerequisite_module_id"`
+	PrerequisiteAssignmentID string     `json:"prerequisite_assignment_id"`
+	MinScore                 float64    `json:"min_score" binding:"min=0,max=100"`
+}
+
+// ReleaseRuleResponse represents a module's release rule
+type ReleaseRuleResponse struct {
+	ID                       string     `json:"id"`
+	ModuleID                 string     `json:"module_id"`
+	CourseID                 string     `json:"course_id"`
+	RuleType                 string     `json:"rule_type"`
+	DelayDays                int        `json:"delay_days"`
+	ReleaseAt                *time.Time `json:"release_at,omitempty"`
+	PrerequisiteModuleID     *string    `json:"prerequisite_module_id,omitempty"`
+	PrerequisiteAssignmentID *string    `json:"prerequisite_assignment_id,omitempty"`
+	MinScore                 float64    `json:"min_score"`
+	CreatedBy                string     `json:"created_by"`
+	CreatedAt                time.Time  `json:"created_at"`
+	UpdatedAt                time.Time  `json:"updated_at"`
+}
+
+// ModuleCompletionResponse represents a student's completion of a module
+type ModuleCompletionResponse struct {
+	ModuleID    string    `json:"module_id"`
+	StudentID   string    `json:"student_id"`
+	CourseID    string    `json:"course_id"`
+	CompletedAt time.Time `json:"completed_at"`
+}
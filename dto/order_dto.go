@@ -0,0 +1,137 @@
+// dto/order_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// AddCartItemRequest represents the request body for adding a product to the cart
+type AddCartItemRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"omitempty,min=1,max=100"` // defaults to 1
+}
+
+// UpdateCartItemRequest represents the request body for changing a cart line's quantity
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1,max=100"`
+}
+
+// CartItemResponse represents a priced cart line
+type CartItemResponse struct {
+	ID               string  `json:"id"`
+	ProductID        string  `json:"product_id"`
+	ProductName      string  `json:"product_name"`
+	Image            string  `json:"image,omitempty"`
+	UnitPrice        float64 `json:"unit_price"`
+	Quantity         int     `json:"quantity"`
+	LineTotal        float64 `json:"line_total"`
+	RequiresShipping bool    `json:"requires_shipping"`
+	IsCourse         bool    `json:"is_course"`
+	Available        bool    `json:"available"` // false once the product is no longer on sale
+}
+
+// CartResponse represents the cart with a price estimate
+type CartResponse struct {
+	ID               string             `json:"id"`
+	Currency         string             `json:"currency"`
+	Items            []CartItemResponse `json:"items"`
+	Subtotal         float64            `json:"subtotal"`
+	TaxAmount        float64            `json:"tax_amount"`
+	ShippingAmount   float64            `json:"shipping_amount"`
+	TotalAmount      float64            `json:"total_amount"`
+	RequiresShipping bool               `json:"requires_shipping"`
+}
+
+// CheckoutRequest represents the request body for turning the cart into an order.
+// Orders on the manual or bank gateway, or without a saved gateway customer, stay
+// pending until a payment is recorded.
+type CheckoutRequest struct {
+	ShippingAddressID    string `json:"shipping_address_id"` // required when any line ships
+	Notes                string `json:"notes" binding:"max=2000"`
+	PaymentMethod        string `json:"payment_method" binding:"omitempty,oneof=credit_card debit_card paypal bank_transfer wallet crypto cash check"`
+	Gateway              string `json:"gateway" binding:"omitempty,oneof=stripe paystack bank manual"`
+	GatewayCustomerID    string `json:"gateway_customer_id" binding:"max=200"`
+	GatewayPaymentMethod string `json:"gateway_payment_method" binding:"max=200"`
+}
+
+// PayOrderRequest represents the request body for charging a pending order
+type PayOrderRequest struct {
+	PaymentMethod        string `json:"payment_method" binding:"required,oneof=credit_card debit_card paypal bank_transfer wallet crypto cash check"`
+	Gateway              string `json:"gateway" binding:"required,oneof=stripe paystack"`
+	GatewayCustomerID    string `json:"gateway_customer_id" binding:"required,max=200"`
+	GatewayPaymentMethod string `json:"gateway_payment_method" binding:"max=200"`
+}
+
+// RecordOrderPaymentRequest represents the request body for settling an order paid outside the platform
+type RecordOrderPaymentRequest struct {
+	PaymentMethod    string `json:"payment_method" binding:"required,oneof=credit_card debit_card paypal bank_transfer wallet crypto cash check"`
+	Gateway          string `json:"gateway" binding:"omitempty,oneof=stripe paypal razorpay paystack flutterwave bank manual"`
+	GatewayReference string `json:"gateway_reference" binding:"max=200"`
+}
+
+// UpdateOrderStatusRequest represents the request body for moving a paid order through fulfilment
+type UpdateOrderStatusRequest struct {
+	Status         string `json:"status" binding:"required,oneof=processing shipped completed"`
+	TrackingNumber string `json:"tracking_number" binding:"max=100"`
+}
+
+// OrderItemResponse represents an order line
+type OrderItemResponse struct {
+	ID               string     `json:"id"`
+	ProductID        string     `json:"product_id"`
+	ProductName      string     `json:"product_name"`
+	UnitPrice        float64    `json:"unit_price"`
+	Quantity         int        `json:"quantity"`
+	LineTotal        float64    `json:"line_total"`
+	RequiresShipping bool       `json:"requires_shipping"`
+	IsCourse         bool       `json:"is_course"`
+	FulfilledAt      *time.Time `json:"fulfilled_at,omitempty"`
+}
+
+// OrderResponse represents the order response
+type OrderResponse struct {
+	ID                string              `json:"id"`
+	OrderNumber       string              `json:"order_number"`
+	UserID            string              `json:"user_id"`
+	CustomerName      string              `json:"customer_name,omitempty"`
+	Status            string              `json:"status"`
+	Currency          string              `json:"currency"`
+	Subtotal          float64             `json:"subtotal"`
+	TaxAmount         float64             `json:"tax_amount"`
+	ShippingAmount    float64             `json:"shipping_amount"`
+	TotalAmount       float64             `json:"total_amount"`
+	RequiresShipping  bool                `json:"requires_shipping"`
+	ShippingAddressID *string             `json:"shipping_address_id,omitempty"`
+	ShippingAddress   string              `json:"shipping_address,omitempty"`
+	TrackingNumber    string              `json:"tracking_number,omitempty"`
+	Notes             string              `json:"notes,omitempty"`
+	Gateway           string              `json:"gateway"`
+	PaymentMethod     string              `json:"payment_method,omitempty"`
+	PaymentID         *string             `json:"payment_id,omitempty"`
+	PaidAt            *time.Time          `json:"paid_at,omitempty"`
+	ShippedAt         *time.Time          `json:"shipped_at,omitempty"`
+	CompletedAt       *time.Time          `json:"completed_at,omitempty"`
+	CancelledAt       *time.Time          `json:"cancelled_at,omitempty"`
+	Items             []OrderItemResponse `json:"items"`
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+}
+
+// OrderListResponse represents paginated order list response
+type OrderListResponse struct {
+	Orders     []OrderResponse `json:"orders"`
+	Total      int64           `json:"total"`
+	Page       int             `json:"page"`
+	Limit      int             `json:"limit"`
+	TotalPages int             `json:"total_pages"`
+}
+
+// OrderQueryParams represents query parameters for filtering orders
+type OrderQueryParams struct {
+	UserID string `form:"user_id"`
+	Status string `form:"status"`
+	From   string `form:"from"` // Format: "2006-01-02"
+	To     string `form:"to"`   // Format: "2006-01-02"
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
@@ -0,0 +1,99 @@
+// dto/permission_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// PermissionInfo describes one permission in the catalog. Scopes lists the grant scopes it
+// supports: "all", and "own" where the permission can be limited to the user's own resources.
+type PermissionInfo struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes"`
+}
+
+// PermissionResource names the resource a request acts on, for scoped permission checks
+type PermissionResource struct {
+	Type string // course, course_version, grade, subject, department, student, user or address
+	ID   string
+}
+
+// PermissionGrant is one permission held by a role. Permission may end in ":*" to cover a
+// whole resource, or be "*" for everything.
+type PermissionGrant struct {
+	Permission string `json:"permission" binding:"required,max=100"`
+	Scope      string `json:"scope" binding:"omitempty,oneof=all own"` // defaults to all
+}
+
+// CreateRoleRequest represents the request body for adding a role
+type CreateRoleRequest struct {
+	Name        string            `json:"name" binding:"required,min=2,max=20"` // lower-case letters, digits and underscores
+	DisplayName string            `json:"display_name" binding:"required,max=100"`
+	Description string            `json:"description"`
+	Permissions []PermissionGrant `json:"permissions" binding:"dive"`
+}
+
+// UpdateRoleRequest represents the request body for changing a role. Permissions, when given,
+// replace the role's whole permission set.
+type UpdateRoleRequest struct {
+	DisplayName *string           `json:"display_name" binding:"omitempty,max=100"`
+	Description *string           `json:"description"`
+	Permissions []PermissionGrant `json:"permissions" binding:"omitempty,dive"`
+}
+
+// RoleResponse represents a role and its permissions
+type RoleResponse struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	DisplayName string            `json:"display_name"`
+	Description string            `json:"description"`
+	IsSystem    bool              `json:"is_system"`
+	Permissions []PermissionGrant `json:"permissions"`
+	UserCount   int64             `json:"user_count"`       // users whose primary role this is
+	Assignments int64             `json:"assignment_count"` // additional assignments of this role
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// SetPrimaryRoleRequest represents the request body for changing a user's primary role
+type SetPrimaryRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// AssignRoleRequest represents the request body for giving a user an additional role,
+// everywhere or only within one department or course
+type AssignRoleRequest struct {
+	Role      string `json:"role" binding:"required"`
+	ScopeType string `json:"scope_type" binding:"omitempty,oneof=department course"`
+	ScopeID   string `json:"scope_id" binding:"omitempty,uuid"`
+}
+
+// RoleAssignmentResponse represents an additional role held by a user
+type RoleAssignmentResponse struct {
+	ID          string    `json:"id"`
+	Role        string    `json:"role"`
+	DisplayName string    `json:"display_name"`
+	ScopeType   string    `json:"scope_type,omitempty"`
+	ScopeID     *string   `json:"scope_id,omitempty"`
+	ScopeName   string    `json:"scope_name,omitempty"`
+	AssignedBy  string    `json:"assigned_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserRolesResponse represents everything that decides a user's permissions
+type UserRolesResponse struct {
+	UserID      string                   `json:"user_id"`
+	PrimaryRole string                   `json:"primary_role"`
+	Assignments []RoleAssignmentResponse `json:"assignments"`
+	Permissions []EffectivePermission    `json:"permissions"`
+}
+
+// EffectivePermission is one permission a user holds, and where it comes from
+type EffectivePermission struct {
+	Permission string  `json:"permission"`
+	Scope      string  `json:"scope"` // all or own
+	Role       string  `json:"role"`
+	ScopeType  string  `json:"scope_type,omitempty"` // set when the role was assigned for one department or course
+	ScopeID    *string `json:"scope_id,omitempty"`
+}
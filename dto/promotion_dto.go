@@ -0,0 +1,94 @@
+// dto/promotion_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// SessionRolloverRequest represents the request body for cloning class structure into a new session
+type SessionRolloverRequest struct {
+	FromSessionID       string `json:"from_session_id" binding:"required"`
+	ToSessionID         string `json:"to_session_id" binding:"required"`
+	IncludeArms         *bool  `json:"include_arms"`
+	IncludeSubjects     *bool  `json:"include_subjects"`
+	SkipInactiveRecords bool   `json:"skip_inactive_records"`
+}
+
+// SessionRolloverResponse summarises what a rollover created or skipped
+type SessionRolloverResponse struct {
+	FromSessionID        string            `json:"from_session_id"`
+	ToSessionID          string            `json:"to_session_id"`
+	GradesCreated        int               `json:"grades_created"`
+	GradesSkipped        int               `json:"grades_skipped"`
+	ArmsCreated          int               `json:"arms_created"`
+	GradeSubjectsCreated int               `json:"grade_subjects_created"`
+	GradeMapping         map[string]string `json:"grade_mapping"`
+	ArmMapping           map[string]string `json:"arm_mapping"`
+}
+
+// PromotionOverride forces the outcome for a single student
+type PromotionOverride struct {
+	StudentID   string `json:"student_id" binding:"required"`
+	Outcome     string `json:"outcome" binding:"required,oneof=promoted repeated graduated skipped"`
+	TargetArmID string `json:"target_arm_id"`
+	Reason      string `json:"reason"`
+}
+
+// PromotionRequest represents the rules used to move students into the next session
+type PromotionRequest struct {
+	FromSessionID      string              `json:"from_session_id" binding:"required"`
+	ToSessionID        string              `json:"to_session_id" binding:"required"`
+	PassMark           *float64            `json:"pass_mark" binding:"omitempty,min=0,max=100"` // defaults to 50
+	FinalLevel         int                 `json:"final_level" binding:"omitempty,min=1"`
+	RepeatWithoutScore bool                `json:"repeat_without_score"`
+	GradeIDs           []string            `json:"grade_ids"`
+	Overrides          []PromotionOverride `json:"overrides" binding:"omitempty,dive"`
+}
+
+// PromotionDecisionResponse represents the computed outcome for a student
+type PromotionDecisionResponse struct {
+	StudentID    string   `json:"student_id"`
+	StudentName  string   `json:"student_name"`
+	FromArmID    string   `json:"from_arm_id"`
+	FromArmName  string   `json:"from_arm_name"`
+	FromLevel    int      `json:"from_level"`
+	ToArmID      string   `json:"to_arm_id,omitempty"`
+	ToArmName    string   `json:"to_arm_name,omitempty"`
+	Outcome      string   `json:"outcome"`
+	AverageScore *float64 `json:"average_score,omitempty"`
+	Reason       string   `json:"reason"`
+}
+
+// PromotionResponse represents a promotion preview or executed run
+type PromotionResponse struct {
+	RunID          string                      `json:"run_id,omitempty"`
+	DryRun         bool                        `json:"dry_run"`
+	FromSessionID  string                      `json:"from_session_id"`
+	ToSessionID    string                      `json:"to_session_id"`
+	PassMark       float64                     `json:"pass_mark"`
+	TotalStudents  int                         `json:"total_students"`
+	PromotedCount  int                         `json:"promoted_count"`
+	RepeatedCount  int                         `json:"repeated_count"`
+	GraduatedCount int                         `json:"graduated_count"`
+	SkippedCount   int                         `json:"skipped_count"`
+	Decisions      []PromotionDecisionResponse `json:"decisions"`
+	ExecutedBy     string                      `json:"executed_by,omitempty"`
+	CreatedAt      *time.Time                  `json:"created_at,omitempty"`
+}
+
+// PromotionRunListResponse represents paginated promotion run list response
+type PromotionRunListResponse struct {
+	Runs       []PromotionResponse `json:"runs"`
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+// PromotionRunQueryParams represents query parameters for filtering promotion runs
+type PromotionRunQueryParams struct {
+	FromSessionID string `form:"from_session_id"`
+	ToSessionID   string `form:"to_session_id"`
+	Page          int    `form:"page" binding:"omitempty,min=1"`
+	Limit         int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
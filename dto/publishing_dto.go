@@ -0,0 +1,70 @@
+// dto/publishing_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// AssignReviewerRequest represents the request body for assigning a reviewer to a course or module
+type AssignReviewerRequest struct {
+	ReviewerID string `json:"reviewer_id" binding:"required"`
+}
+
+// ReviewCommentRequest represents the request body for commenting on a course or module under review
+type ReviewCommentRequest struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
+
+// PublishCheck is one requirement an item must meet before it can be submitted or published
+type PublishCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// PublishChecksResponse lists the publish checks for a course or module
+type PublishChecksResponse struct {
+	ItemType string         `json:"item_type"`
+	ItemID   string         `json:"item_id"`
+	Status   string         `json:"status"`
+	Ready    bool           `json:"ready"` // every check passed
+	Checks   []PublishCheck `json:"checks"`
+}
+
+// PublishingStatusResponse represents where a course or module is in the publishing workflow
+type PublishingStatusResponse struct {
+	ItemType    string     `json:"item_type"`
+	ItemID      string     `json:"item_id"`
+	CourseID    string     `json:"course_id"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	OwnerID     string     `json:"owner_id"` // tutor of the course
+	ReviewerID  *string    `json:"reviewer_id,omitempty"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+}
+
+// ReviewCommentResponse represents an entry in a review thread
+type ReviewCommentResponse struct {
+	ID         string    `json:"id"`
+	ItemType   string    `json:"item_type"`
+	ItemID     string    `json:"item_id"`
+	AuthorID   string    `json:"author_id"`
+	AuthorName string    `json:"author_name,omitempty"`
+	Action     string    `json:"action"`
+	Body       string    `json:"body,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ReviewQueueParams represents query parameters for the review queue
+type ReviewQueueParams struct {
+	Type       string `form:"type" binding:"omitempty,oneof=course module"`
+	ReviewerID string `form:"reviewer_id"` // admins only; tutors always see their own assignments
+	Unassigned bool   `form:"unassigned"`
+}
+
+// WorkflowNoteRequest represents an optional note recorded with a submission or archive
+type WorkflowNoteRequest struct {
+	Note string `json:"note" binding:"max=2000"`
+}
@@ -0,0 +1,76 @@
+// dto/refund_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// CreateRefundRequest represents the request body for asking to cancel a paid enrollment
+type CreateRefundRequest struct {
+	EnrollmentID string `json:"enrollment_id" binding:"required"`
+	Reason       string `json:"reason" binding:"required,min=5,max=2000"`
+}
+
+// ReviewRefundRequest represents the request body for approving or rejecting a refund
+type ReviewRefundRequest struct {
+	Note string `json:"note" binding:"max=2000"`
+}
+
+// RefundQuoteResponse shows what the refund policy allows for an enrollment right now
+type RefundQuoteResponse struct {
+	EnrollmentID       string     `json:"enrollment_id"`
+	Eligible           bool       `json:"eligible"`
+	PolicyRule         string     `json:"policy_rule,omitempty"` // full or prorated
+	AmountPaid         float64    `json:"amount_paid"`
+	RefundAmount       float64    `json:"refund_amount"`
+	Currency           string     `json:"currency"`
+	ProgressPercentage int        `json:"progress_percentage"`
+	FullRefundUntil    *time.Time `json:"full_refund_until,omitempty"`
+	Reason             string     `json:"reason,omitempty"` // why the enrollment is not eligible
+}
+
+// RefundResponse represents the refund request response
+type RefundResponse struct {
+	ID                string     `json:"id"`
+	EnrollmentID      string     `json:"enrollment_id"`
+	StudentID         string     `json:"student_id"`
+	StudentName       string     `json:"student_name,omitempty"`
+	CourseID          string     `json:"course_id"`
+	CourseTitle       string     `json:"course_title,omitempty"`
+	PaymentID         *string    `json:"payment_id,omitempty"`
+	Reason            string     `json:"reason"`
+	Status            string     `json:"status"`
+	PolicyRule        string     `json:"policy_rule"`
+	AmountPaid        float64    `json:"amount_paid"`
+	RefundAmount      float64    `json:"refund_amount"`
+	Currency          string     `json:"currency"`
+	ProgressAtRequest int        `json:"progress_at_request"`
+	Gateway           string     `json:"gateway,omitempty"`
+	GatewayRefundID   string     `json:"gateway_refund_id,omitempty"`
+	FailureReason     string     `json:"failure_reason,omitempty"`
+	RequestedBy       string     `json:"requested_by"`
+	ReviewedBy        *string    `json:"reviewed_by,omitempty"`
+	ReviewNote        string     `json:"review_note,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
+	ProcessedAt       *time.Time `json:"processed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// RefundListResponse represents paginated refund request list response
+type RefundListResponse struct {
+	Refunds    []RefundResponse `json:"refunds"`
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+	TotalPages int              `json:"total_pages"`
+}
+
+// RefundQueryParams represents query parameters for filtering refund requests
+type RefundQueryParams struct {
+	Status    string `form:"status"`
+	StudentID string `form:"student_id"`
+	CourseID  string `form:"course_id"`
+	Page      int    `form:"page"`
+	Limit     int    `form:"limit"`
+}
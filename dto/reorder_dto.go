@@ -0,0 +1,37 @@
+// dto/reorder_dto.go
+package dto
+
+// ReorderRequest lists item IDs in their new order within a parent. Modules must list
+// every module of the course; lessons and topics must list every current child and may
+// add items from elsewhere in the same course to move them in.
+type ReorderRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1,dive,uuid"`
+}
+
+// MoveLessonRequest represents the request body for moving a lesson to another module.
+// Position is 1-based; 0 or a position past the end appends the lesson.
+type MoveLessonRequest struct {
+	ModuleID string `json:"module_id" binding:"required,uuid"`
+	Position int    `json:"position" binding:"min=0"`
+}
+
+// MoveTopicRequest represents the request body for moving a topic to another lesson.
+// Position is 1-based; 0 or a position past the end appends the topic.
+type MoveTopicRequest struct {
+	LessonID string `json:"lesson_id" binding:"required,uuid"`
+	Position int    `json:"position" binding:"min=0"`
+}
+
+// OrderedItem represents one item and its position within its parent
+type OrderedItem struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Position int    `json:"position"`
+}
+
+// ReorderResponse represents the children of a parent in their saved order
+type ReorderResponse struct {
+	ParentType string        `json:"parent_type"`
+	ParentID   string        `json:"parent_id"`
+	Items      []OrderedItem `json:"items"`
+}
@@ -0,0 +1,53 @@
+// dto/session_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// SessionClient describes the device a session is opened from
+type SessionClient struct {
+	UserAgent  string
+	IPAddress  string
+	DeviceType string
+	DeviceOS   string
+	Browser    string
+}
+
+// TokenPair is an access token together with the refresh token that renews it
+type TokenPair struct {
+	SessionID             string    `json:"session_id"`
+	AccessToken           string    `json:"token"`
+	AccessTokenExpiresAt  time.Time `json:"token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	SessionExpiresAt      time.Time `json:"session_expires_at"`
+}
+
+// RefreshTokenRequest represents the request body for renewing an access token.
+// The refresh token may instead be sent in the refresh_token cookie.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SessionResponse represents a signed-in device
+type SessionResponse struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	DeviceType    string    `json:"device_type"`
+	DeviceOS      string    `json:"device_os"`
+	Browser       string    `json:"browser"`
+	UserAgent     string    `json:"user_agent"`
+	IPAddress     string    `json:"ip_address"`
+	LoginType     string    `json:"login_type"`
+	Current       bool      `json:"current"` // the session making this request
+	IssuedAt      time.Time `json:"issued_at"`
+	LastUsedAt    time.Time `json:"last_used_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	IdleExpiresAt time.Time `json:"idle_expires_at"` // when the session lapses unless used again
+}
+
+// RevokeSessionsResponse reports how many sessions were signed out
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
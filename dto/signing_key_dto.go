@@ -0,0 +1,27 @@
+// dto/signing_key_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// RotateSigningKeyRequest represents the request body for rotating the token signing key.
+// Algorithm defaults to the configured JWT_SIGNING_ALG.
+type RotateSigningKeyRequest struct {
+	Algorithm string `json:"algorithm" binding:"omitempty,oneof=RS256 EdDSA"`
+}
+
+// SigningKeyResponse represents a signing key without its private half
+type SigningKeyResponse struct {
+	ID          string     `json:"id"`
+	KID         string     `json:"kid"`
+	Algorithm   string     `json:"algorithm"`
+	Status      string     `json:"status"`
+	PublicKey   string     `json:"public_key"`
+	Encrypted   bool       `json:"encrypted"`
+	ActivatedAt time.Time  `json:"activated_at"`
+	RetireAt    *time.Time `json:"retire_at,omitempty"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+	CreatedBy   *string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
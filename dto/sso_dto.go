@@ -0,0 +1,103 @@
+// dto/sso_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// CreateSSOProviderRequest represents the request body for registering an identity provider.
+// OIDC providers need Issuer and ClientID; SAML providers need IDP metadata by URL or XML.
+type CreateSSOProviderRequest struct {
+	Slug    string `json:"slug" binding:"required,min=2,max=40"` // lower-case letters, digits and dashes
+	Name    string `json:"name" binding:"required,max=100"`
+	Type    string `json:"type" binding:"required,oneof=oidc saml"`
+	Enabled *bool  `json:"enabled"` // defaults to true
+
+	Issuer       string `json:"issuer" binding:"omitempty,url"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Scopes       string `json:"scopes"` // extra scopes besides openid, email and profile
+
+	IDPMetadataURL string `json:"idp_metadata_url" binding:"omitempty,url"`
+	IDPMetadataXML string `json:"idp_metadata_xml"`
+
+	AllowedDomains    []string          `json:"allowed_domains"`
+	AutoProvision     bool              `json:"auto_provision"`
+	TrustEmail        bool              `json:"trust_email"`
+	RoleClaim         string            `json:"role_claim"`
+	RoleMapping       map[string]string `json:"role_mapping"` // claim value to admin, staff, tutor or student
+	DefaultRole       string            `json:"default_role" binding:"omitempty,oneof=admin staff tutor student"`
+	DepartmentClaim   string            `json:"department_claim"`
+	DepartmentMapping map[string]string `json:"department_mapping"` // claim value to department code
+	SyncAttributes    bool              `json:"sync_attributes"`
+}
+
+// UpdateSSOProviderRequest represents the request body for changing an identity provider.
+// Omitted fields are left unchanged; the slug and type cannot change.
+type UpdateSSOProviderRequest struct {
+	Name    *string `json:"name" binding:"omitempty,max=100"`
+	Enabled *bool   `json:"enabled"`
+
+	Issuer       *string `json:"issuer" binding:"omitempty,url"`
+	ClientID     *string `json:"client_id"`
+	ClientSecret *string `json:"client_secret"`
+	Scopes       *string `json:"scopes"`
+
+	IDPMetadataURL *string `json:"idp_metadata_url"`
+	IDPMetadataXML *string `json:"idp_metadata_xml"`
+
+	AllowedDomains    []string          `json:"allowed_domains"`
+	AutoProvision     *bool             `json:"auto_provision"`
+	TrustEmail        *bool             `json:"trust_email"`
+	RoleClaim         *string           `json:"role_claim"`
+	RoleMapping       map[string]string `json:"role_mapping"`
+	DefaultRole       *string           `json:"default_role" binding:"omitempty,oneof=admin staff tutor student"`
+	DepartmentClaim   *string           `json:"department_claim"`
+	DepartmentMapping map[string]string `json:"department_mapping"`
+	SyncAttributes    *bool             `json:"sync_attributes"`
+}
+
+// SSOProviderResponse represents an identity provider as seen by admins. Secrets are never returned.
+type SSOProviderResponse struct {
+	ID                string            `json:"id"`
+	Slug              string            `json:"slug"`
+	Name              string            `json:"name"`
+	Type              string            `json:"type"`
+	Enabled           bool              `json:"enabled"`
+	Issuer            string            `json:"issuer,omitempty"`
+	ClientID          string            `json:"client_id,omitempty"`
+	HasClientSecret   bool              `json:"has_client_secret"`
+	Scopes            string            `json:"scopes,omitempty"`
+	IDPMetadataURL    string            `json:"idp_metadata_url,omitempty"`
+	HasIDPMetadataXML bool              `json:"has_idp_metadata_xml"`
+	AllowedDomains    []string          `json:"allowed_domains"`
+	AutoProvision     bool              `json:"auto_provision"`
+	TrustEmail        bool              `json:"trust_email"`
+	RoleClaim         string            `json:"role_claim,omitempty"`
+	RoleMapping       map[string]string `json:"role_mapping"`
+	DefaultRole       string            `json:"default_role"`
+	DepartmentClaim   string            `json:"department_claim,omitempty"`
+	DepartmentMapping map[string]string `json:"department_mapping"`
+	SyncAttributes    bool              `json:"sync_attributes"`
+	LoginURL          string            `json:"login_url"`
+	CallbackURL       string            `json:"callback_url,omitempty"` // OIDC redirect URI to register at the provider
+	EntityID          string            `json:"entity_id,omitempty"`    // SAML service provider entity ID and metadata URL
+	ACSURL            string            `json:"acs_url,omitempty"`      // SAML assertion consumer service URL
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+// SSOProviderSummary is what the sign-in page needs to offer a provider
+type SSOProviderSummary struct {
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	LoginURL string `json:"login_url"`
+}
+
+// SSOLoginStart is the redirect that begins a sign-in at the identity provider. Cookie is the
+// signed state, which the browser must send back with the callback.
+type SSOLoginStart struct {
+	RedirectURL string
+	Cookie      string
+}
@@ -0,0 +1,162 @@
+// dto/subscription_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// PlanItemRequest bundles a product or course category into a plan
+type PlanItemRequest struct {
+	ItemType string `json:"item_type" binding:"required,oneof=product category"`
+	ItemID   string `json:"item_id" binding:"required"`
+}
+
+// CreatePlanRequest represents the request body for creating a subscription plan
+type CreatePlanRequest struct {
+	Name             string            `json:"name" binding:"required,min=2,max=255"`
+	Slug             string            `json:"slug" binding:"required,min=2,max=255"`
+	Description      string            `json:"description"`
+	BillingInterval  string            `json:"billing_interval" binding:"required,oneof=monthly yearly"`
+	Price            float64           `json:"price" binding:"gte=0"`
+	Currency         string            `json:"currency" binding:"omitempty,len=3"`
+	TrialDays        int               `json:"trial_days" binding:"min=0,max=365"`
+	GracePeriodDays  *int              `json:"grace_period_days" binding:"omitempty,min=0,max=60"`
+	MaxRetryAttempts *int              `json:"max_retry_attempts" binding:"omitempty,min=0,max=10"`
+	Items            []PlanItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// UpdatePlanRequest represents the request body for updating a subscription plan.
+// Price and interval changes apply from each subscriber's next renewal.
+type UpdatePlanRequest struct {
+	Name             string            `json:"name" binding:"omitempty,min=2,max=255"`
+	Description      *string           `json:"description"`
+	Price            *float64          `json:"price" binding:"omitempty,gte=0"`
+	TrialDays        *int              `json:"trial_days" binding:"omitempty,min=0,max=365"`
+	GracePeriodDays  *int              `json:"grace_period_days" binding:"omitempty,min=0,max=60"`
+	MaxRetryAttempts *int              `json:"max_retry_attempts" binding:"omitempty,min=0,max=10"`
+	Status           string            `json:"status" binding:"omitempty,oneof=active inactive"`
+	Items            []PlanItemRequest `json:"items" binding:"omitempty,dive"` // replaces the bundle when provided
+}
+
+// PlanItemResponse represents a bundled product or category
+type PlanItemResponse struct {
+	ItemType string `json:"item_type"`
+	ItemID   string `json:"item_id"`
+	Name     string `json:"name,omitempty"`
+}
+
+// PlanResponse represents the subscription plan response
+type PlanResponse struct {
+	ID               string             `json:"id"`
+	Name             string             `json:"name"`
+	Slug             string             `json:"slug"`
+	Description      string             `json:"description"`
+	BillingInterval  string             `json:"billing_interval"`
+	Price            float64            `json:"price"`
+	Currency         string             `json:"currency"`
+	TrialDays        int                `json:"trial_days"`
+	GracePeriodDays  int                `json:"grace_period_days"`
+	MaxRetryAttempts int                `json:"max_retry_attempts"`
+	Status           string             `json:"status"`
+	Items            []PlanItemResponse `json:"items"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// PlanQueryParams represents query parameters for filtering plans
+type PlanQueryParams struct {
+	Status string `form:"status"`
+}
+
+// SubscribeRequest represents the request body for starting a subscription.
+// Plans with a trial start without payment; otherwise a saved gateway customer is charged now.
+// Admins may subscribe another user and record the reference of a payment taken elsewhere.
+type SubscribeRequest struct {
+	PlanID               string `json:"plan_id" binding:"required"`
+	UserID               string `json:"user_id"` // admin only
+	Gateway              string `json:"gateway" binding:"omitempty,oneof=stripe paypal razorpay paystack flutterwave bank manual"`
+	PaymentMethod        string `json:"payment_method" binding:"omitempty,oneof=credit_card debit_card paypal bank_transfer wallet crypto cash check"`
+	GatewayReference     string `json:"gateway_reference" binding:"max=200"`
+	GatewayCustomerID    string `json:"gateway_customer_id" binding:"max=200"`
+	GatewayPaymentMethod string `json:"gateway_payment_method" binding:"max=200"`
+}
+
+// CancelSubscriptionRequest represents the request body for cancelling a subscription
+type CancelSubscriptionRequest struct {
+	Immediately bool `json:"immediately"` // admins only; otherwise access continues to the period end
+}
+
+// SubscriptionPaymentRequest represents a renewal payment collected outside the automatic charge
+type SubscriptionPaymentRequest struct {
+	PaymentMethod    string `json:"payment_method" binding:"required,oneof=credit_card debit_card paypal bank_transfer wallet crypto cash check"`
+	Gateway          string `json:"gateway" binding:"omitempty,oneof=stripe paypal razorpay paystack flutterwave bank manual"`
+	GatewayReference string `json:"gateway_reference" binding:"max=200"`
+}
+
+// SubscriptionResponse represents the subscription response
+type SubscriptionResponse struct {
+	ID                 string        `json:"id"`
+	UserID             string        `json:"user_id"`
+	UserName           string        `json:"user_name,omitempty"`
+	PlanID             string        `json:"plan_id"`
+	Plan               *PlanResponse `json:"plan,omitempty"`
+	Status             string        `json:"status"`
+	HasAccess          bool          `json:"has_access"`
+	CurrentPeriodStart time.Time     `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time     `json:"current_period_end"`
+	TrialEndsAt        *time.Time    `json:"trial_ends_at,omitempty"`
+	GraceEndsAt        *time.Time    `json:"grace_ends_at,omitempty"`
+	CancelAtPeriodEnd  bool          `json:"cancel_at_period_end"`
+	CancelledAt        *time.Time    `json:"cancelled_at,omitempty"`
+	EndedAt            *time.Time    `json:"ended_at,omitempty"`
+	FailedPaymentCount int           `json:"failed_payment_count"`
+	NextRetryAt        *time.Time    `json:"next_retry_at,omitempty"`
+	Gateway            string        `json:"gateway"`
+	PaymentMethod      string        `json:"payment_method,omitempty"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+}
+
+// SubscriptionListResponse represents paginated subscription list response
+type SubscriptionListResponse struct {
+	Subscriptions []SubscriptionResponse `json:"subscriptions"`
+	Total         int64                  `json:"total"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+	TotalPages    int                    `json:"total_pages"`
+}
+
+// SubscriptionQueryParams represents query parameters for filtering subscriptions
+type SubscriptionQueryParams struct {
+	UserID string `form:"user_id"`
+	PlanID string `form:"plan_id"`
+	Status string `form:"status"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
+
+// RenewalRunResponse summarises one pass of the renewal and dunning job
+type RenewalRunResponse struct {
+	Renewed int `json:"renewed"`
+	Failed  int `json:"failed"`  // charges that failed and were scheduled for retry
+	Ended   int `json:"ended"`   // cancelled at period end
+	Expired int `json:"expired"` // retries or grace period exhausted
+}
+
+// CourseAccessResponse explains whether a user may open a course's content
+type CourseAccessResponse struct {
+	CourseID       string     `json:"course_id"`
+	Allowed        bool       `json:"allowed"`
+	Source         string     `json:"source"` // staff, enrollment, subscription or none
+	EnrollmentID   *string    `json:"enrollment_id,omitempty"`
+	SubscriptionID *string    `json:"subscription_id,omitempty"`
+	AccessUntil    *time.Time `json:"access_until,omitempty"`
+}
+
+// ModuleAccessResponse explains whether a user may open a module
+type ModuleAccessResponse struct {
+	ModuleID string `json:"module_id"`
+	CourseAccessResponse
+	UnlockAt   *time.Time `json:"unlock_at,omitempty"`   // when a scheduled module opens
+	LockReason string     `json:"lock_reason,omitempty"` // sign_in_required, not_enrolled, scheduled or prerequisite
+}
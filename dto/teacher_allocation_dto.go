@@ -0,0 +1,77 @@
+// dto/teacher_allocation_dto.go
+package dto
+
+import (
+	"time"
+)
+
+// CreateTeacherAllocationRequest represents the request body for allocating a teacher to a subject and arm
+type CreateTeacherAllocationRequest struct {
+	TeacherID      string `json:"teacher_id" binding:"required"`
+	SubjectID      string `json:"subject_id" binding:"required"`
+	ArmID          string `json:"arm_id" binding:"required"`
+	PeriodsPerWeek int    `json:"periods_per_week" binding:"omitempty,min=1,max=20"`
+	Status         string `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// UpdateTeacherAllocationRequest represents the request body for updating a teacher allocation
+type UpdateTeacherAllocationRequest struct {
+	TeacherID      string `json:"teacher_id"`
+	PeriodsPerWeek int    `json:"periods_per_week" binding:"omitempty,min=1,max=20"`
+	Status         string `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// TeacherAllocationResponse represents the teacher allocation response
+type TeacherAllocationResponse struct {
+	ID                string    `json:"id"`
+	TeacherID         string    `json:"teacher_id"`
+	TeacherName       string    `json:"teacher_name,omitempty"`
+	SubjectID         string    `json:"subject_id"`
+	SubjectName       string    `json:"subject_name,omitempty"`
+	ArmID             string    `json:"arm_id"`
+	ArmName           string    `json:"arm_name,omitempty"`
+	AcademicSessionID string    `json:"academic_session_id"`
+	PeriodsPerWeek    int       `json:"periods_per_week"`
+	Status            string    `json:"status"`
+	CreatedBy         string    `json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TeacherAllocationListResponse represents paginated teacher allocation list response
+type TeacherAllocationListResponse struct {
+	Allocations []TeacherAllocationResponse `json:"allocations"`
+	Total       int64                       `json:"total"`
+	Page        int                         `json:"page"`
+	Limit       int                         `json:"limit"`
+	TotalPages  int                         `json:"total_pages"`
+}
+
+// TeacherAllocationQueryParams represents query parameters for filtering teacher allocations
+type TeacherAllocationQueryParams struct {
+	TeacherID         string `form:"teacher_id"`
+	SubjectID         string `form:"subject_id"`
+	ArmID             string `form:"arm_id"`
+	AcademicSessionID string `form:"academic_session_id"`
+	Status            string `form:"status" binding:"omitempty,oneof=active inactive"`
+	Page              int    `form:"page" binding:"omitempty,min=1"`
+	Limit             int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// TeacherWorkloadResponse summarises the teaching load of a teacher in a session
+type TeacherWorkloadResponse struct {
+	TeacherID         string                      `json:"teacher_id"`
+	TeacherName       string                      `json:"teacher_name"`
+	AcademicSessionID string                      `json:"academic_session_id"`
+	TotalAllocations  int                         `json:"total_allocations"`
+	TotalPeriods      int                         `json:"total_periods_per_week"`
+	ScheduledPeriods  int                         `json:"scheduled_periods"`
+	SubjectCount      int                         `json:"subject_count"`
+	ArmCount          int                         `json:"arm_count"`
+	Allocations       []TeacherAllocationResponse `json:"allocations,omitempty"`
+}
+
+// WorkloadQueryParams represents query parameters for workload summaries
+type WorkloadQueryParams struct {
+	AcademicSessionID string `form:"academic_session_id"`
+}
@@ -0,0 +1,90 @@
+// dto/timetable_dto.go
+package dto
+
+// GenerateTimetableRequest represents the request body for generating timetables
+type GenerateTimetableRequest struct {
+	AcademicSessionID string   `json:"academic_session_id" binding:"required"`
+	ArmIDs            []string `json:"arm_ids"`
+	DaysPerWeek       int      `json:"days_per_week" binding:"omitempty,min=1,max=7"`
+	PeriodsPerDay     int      `json:"periods_per_day" binding:"omitempty,min=1,max=12"`
+	ReplaceExisting   bool     `json:"replace_existing"`
+	DryRun            bool     `json:"dry_run"`
+}
+
+// UnplacedPeriod reports allocation periods the generator could not fit
+type UnplacedPeriod struct {
+	AllocationID string `json:"allocation_id"`
+	ArmID        string `json:"arm_id"`
+	SubjectID    string `json:"subject_id"`
+	TeacherID    string `json:"teacher_id"`
+	Missing      int    `json:"missing_periods"`
+}
+
+// GenerateTimetableResponse summarises a timetable generation
+type GenerateTimetableResponse struct {
+	AcademicSessionID string                  `json:"academic_session_id"`
+	DryRun            bool                    `json:"dry_run"`
+	DaysPerWeek       int                     `json:"days_per_week"`
+	PeriodsPerDay     int                     `json:"periods_per_day"`
+	ArmCount          int                     `json:"arm_count"`
+	PlacedPeriods     int                     `json:"placed_periods"`
+	RequiredPeriods   int                     `json:"required_periods"`
+	Unplaced          []UnplacedPeriod        `json:"unplaced"`
+	Slots             []TimetableSlotResponse `json:"slots,omitempty"`
+}
+
+// TimetableSlotResponse represents a single timetable period
+type TimetableSlotResponse struct {
+	ID           string `json:"id,omitempty"`
+	DayOfWeek    int    `json:"day_of_week"`
+	DayName      string `json:"day_name"`
+	Period       int    `json:"period"`
+	ArmID        string `json:"arm_id"`
+	ArmName      string `json:"arm_name,omitempty"`
+	SubjectID    string `json:"subject_id"`
+	SubjectName  string `json:"subject_name,omitempty"`
+	TeacherID    string `json:"teacher_id"`
+	TeacherName  string `json:"teacher_name,omitempty"`
+	AllocationID string `json:"allocation_id"`
+}
+
+// TimetableDayResponse groups the periods of one day
+type TimetableDayResponse struct {
+	DayOfWeek int                     `json:"day_of_week"`
+	DayName   string                  `json:"day_name"`
+	Periods   []TimetableSlotResponse `json:"periods"`
+}
+
+// TimetableViewResponse represents a weekly timetable for an arm or teacher
+type TimetableViewResponse struct {
+	OwnerType         string                 `json:"owner_type"` // arm or teacher
+	OwnerID           string                 `json:"owner_id"`
+	AcademicSessionID string                 `json:"academic_session_id"`
+	TotalPeriods      int                    `json:"total_periods"`
+	Days              []TimetableDayResponse `json:"days"`
+}
+
+// TimetableQueryParams represents query parameters for timetable views
+type TimetableQueryParams struct {
+	AcademicSessionID string `form:"academic_session_id"`
+}
+
+// CreateUnavailabilityRequest represents the request body for blocking a teacher's time
+type CreateUnavailabilityRequest struct {
+	TeacherID         string `json:"teacher_id" binding:"required"`
+	AcademicSessionID string `json:"academic_session_id" binding:"required"`
+	DayOfWeek         int    `json:"day_of_week" binding:"required,min=1,max=7"`
+	Period            int    `json:"period" binding:"omitempty,min=0,max=12"`
+	Reason            string `json:"reason" binding:"max=255"`
+}
+
+// UnavailabilityResponse represents a teacher unavailability entry
+type UnavailabilityResponse struct {
+	ID                string `json:"id"`
+	TeacherID         string `json:"teacher_id"`
+	AcademicSessionID string `json:"academic_session_id"`
+	DayOfWeek         int    `json:"day_of_week"`
+	DayName           string `json:"day_name"`
+	Period            int    `json:"period"`
+	Reason            string `json:"reason"`
+}
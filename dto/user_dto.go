@@ -58,4 +58,9 @@ type UpdateUserRequest struct {
 	IsActive   *bool  `json:"is_active"`
 	IsVerified *bool  `json:"is_verified"`
 	Location   string `json:"location"`
+}
+
+// UpdateUserStatusRequest activates or deactivates a user
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
 }
\ No newline at end of file
@@ -6,6 +6,7 @@ import (
 	"crm-go/database/seeds"
 	"crm-go/middleware"
 	"crm-go/routes"
+	"crm-go/utils"
 	"flag"
 	"github.com/gin-contrib/cors"
 	"time"
@@ -46,8 +47,10 @@ func main() {
 	// Initialize DB connection
 	config.ConnectDB()
 
-	// Init Google OAuth
-	config.InitGoogleOauthConfig()
+	// Load token signing keys, creating the first one on a fresh database
+	if err := utils.EnsureSigningKey(); err != nil {
+		log.Fatalf("❌ Failed to load signing keys: %v", err)
+	}
 
 	// Initialize Gin router
 	r := gin.New()
@@ -109,6 +112,28 @@ func main() {
 	routes.AddressRoutes(&r.RouterGroup, config.DB)
 	routes.AcademicSessionRoutes(&r.RouterGroup, config.DB)
 	routes.GradeSubjectRoutes(&r.RouterGroup, config.DB)
+	routes.PromotionRoutes(&r.RouterGroup, config.DB)
+	routes.ClassMembershipRoutes(&r.RouterGroup, config.DB)
+	routes.TimetableRoutes(&r.RouterGroup, config.DB)
+	routes.ClassAttendanceRoutes(&r.RouterGroup, config.DB)
+	routes.GuardianPortalRoutes(&r.RouterGroup, config.DB)
+	routes.BillingRoutes(&r.RouterGroup, config.DB)
+	routes.RefundRoutes(&r.RouterGroup, config.DB)
+	routes.SubscriptionRoutes(&r.RouterGroup, config.DB)
+	routes.AccessRoutes(&r.RouterGroup, config.DB)
+	routes.OrderRoutes(&r.RouterGroup, config.DB)
+	routes.ModuleReleaseRoutes(&r.RouterGroup, config.DB)
+	routes.CourseVersionRoutes(&r.RouterGroup, config.DB)
+	routes.PublishingRoutes(&r.RouterGroup, config.DB)
+	routes.ReorderRoutes(&r.RouterGroup, config.DB)
+	routes.SigningKeyRoutes(&r.RouterGroup, config.DB)
+	routes.SessionRoutes(&r.RouterGroup, config.DB)
+	routes.MFARoutes(&r.RouterGroup, config.DB)
+	routes.LoginGuardRoutes(&r.RouterGroup, config.DB)
+	routes.InvitationRoutes(&r.RouterGroup, config.DB)
+	routes.SSORoutes(&r.RouterGroup, config.DB)
+	routes.PermissionRoutes(&r.RouterGroup, config.DB)
+	routes.APIKeyRoutes(&r.RouterGroup, config.DB)
 
 	// Example curl command to clear DB (replace with your server address):
 	// curl -X DELETE "http://localhost:8080/admin/clear-db" \
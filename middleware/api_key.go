@@ -0,0 +1,75 @@
+// middleware/api_key.go
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"crm-go/config"
+	apiKeyServices "crm-go/services/api_keys"
+)
+
+// apiKeyFromRequest returns the API key sent in the X-API-Key header, or as a bearer token
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if apiKeyServices.IsAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
+// authenticateAPIKey signs a request in as the key's service account, setting the same user_id
+// and role as a token would, plus the key's scopes for RequirePermission
+func authenticateAPIKey(c *gin.Context, key string) {
+	principal, err := apiKeyServices.NewAPIKeyService(config.DB).Authenticate(key, c.ClientIP())
+	if principal != nil {
+		c.Header("X-RateLimit-Limit", strconv.Itoa(principal.RateLimitPerMinute))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(principal.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(principal.ResetAt.Unix(), 10))
+	}
+	if err != nil {
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "rate limit"):
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(principal.ResetAt).Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": msg})
+		case strings.HasPrefix(msg, "failed to"):
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify API key"})
+		default:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+		}
+		c.Abort()
+		return
+	}
+
+	// A key is limited to its scopes, which only RequirePermission can check
+	if !hasPermissionCheck(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot be used on this route"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", principal.UserID)
+	c.Set("role", principal.Role)
+	c.Set("service_account_id", principal.ServiceAccountID)
+	c.Set("api_key_id", principal.KeyID)
+	c.Set("api_key_scopes", principal.Scopes)
+	c.Next()
+}
+
+// hasPermissionCheck reports whether a RequirePermission check is among the route's handlers
+func hasPermissionCheck(c *gin.Context) bool {
+	for _, name := range c.HandlerNames() {
+		if strings.HasPrefix(name, requirePermissionName) {
+			return true
+		}
+	}
+	return false
+}
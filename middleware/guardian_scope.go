@@ -0,0 +1,113 @@
+// middleware/guardian_scope.go
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"crm-go/config"
+	"crm-go/models"
+)
+
+// IsGuardianRole reports whether a role belongs to a guardian account.
+// "parent" is kept for guardian accounts created before the guardian role existed.
+func IsGuardianRole(role string) bool {
+	return role == "guardian" || role == "parent"
+}
+
+// guardianPortalPrefix is where guardian accounts read about their wards
+const guardianPortalPrefix = "/api/guardian-portal/"
+
+// guardianRoutes are the only signed-in routes outside the guardian portal that guardian
+// accounts may use, keyed by method and route pattern: their own sessions, two-factor
+// settings, password and permissions, and their wards' billing statements. Public routes
+// behind OptionalAuthMiddleware are open to guardians as they are to anonymous visitors.
+// Routes here that name a student must also use WardAccessMiddleware.
+var guardianRoutes = map[string]bool{
+	"GET /api/permissions/me":                                            true,
+	"GET /api/me/sessions":                                               true,
+	"DELETE /api/me/sessions":                                            true,
+	"DELETE /api/me/sessions/:id":                                        true,
+	"GET /api/me/mfa":                                                    true,
+	"POST /api/me/mfa/totp/setup":                                        true,
+	"POST /api/me/mfa/totp/confirm":                                      true,
+	"DELETE /api/me/mfa/totp":                                            true,
+	"PUT /api/me/mfa/email":                                              true,
+	"POST /api/me/mfa/recovery-codes":                                    true,
+	"GET /api/me/mfa/trusted-devices":                                    true,
+	"DELETE /api/me/mfa/trusted-devices/:id":                             true,
+	"POST /auth/change-password":                                         true,
+	"GET /api/billing/students/:student_id/statement":                    true,
+	"GET /api/billing/students/:student_id/payments/:payment_id/receipt": true,
+}
+
+// enforceGuardianScope keeps guardian tokens to the guardian portal, the routes in
+// guardianRoutes and public reads; everything else is refused. It writes the response and
+// returns false when the request must stop.
+func enforceGuardianScope(c *gin.Context) bool {
+	if c.GetBool(optionalAuthKey) {
+		return true
+	}
+
+	route := c.FullPath()
+	if strings.HasPrefix(route, guardianPortalPrefix) || guardianRoutes[c.Request.Method+" "+route] {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "Guardian accounts can only use the guardian portal"})
+	c.Abort()
+	return false
+}
+
+// isLinkedGuardian checks for an active guardian link between a user and a student
+func isLinkedGuardian(userID, studentID string) bool {
+	guardianUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return false
+	}
+	wardID, err := uuid.Parse(studentID)
+	if err != nil {
+		return false
+	}
+
+	var linked int64
+	if err := config.DB.Model(&models.Guardian{}).
+		Where("user_id = ? AND student_id = ? AND status = ? AND deleted_at IS NULL", guardianUserID, wardID, "active").
+		Count(&linked).Error; err != nil {
+		return false
+	}
+	return linked > 0
+}
+
+// WardAccessMiddleware limits guardians to students they are linked to, named by the
+// student_id path parameter. Other roles are refused in the guardian portal and elsewhere pass
+// through to the route's own permission checks.
+func WardAccessMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		roleStr, _ := role.(string)
+		if !IsGuardianRole(roleStr) {
+			if strings.HasPrefix(c.FullPath(), guardianPortalPrefix) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		userID, _ := c.Get("user_id")
+		userIDStr, _ := userID.(string)
+		studentID := c.Param("student_id")
+		if studentID != "" && !isLinkedGuardian(userIDStr, studentID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not linked to this student"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEnforceGuardianScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	scoped := func(c *gin.Context) {
+		if enforceGuardianScope(c) {
+			c.Status(http.StatusOK)
+		}
+	}
+	optional := func(c *gin.Context) {
+		c.Set(optionalAuthKey, true)
+		c.Next()
+	}
+
+	r := gin.New()
+	r.GET("/api/guardian-portal/wards", scoped)
+	r.GET("/api/me/sessions", scoped)
+	r.DELETE("/api/me/sessions/:id", scoped)
+	r.GET("/api/users", scoped)
+	r.POST("/api/me/sessions", scoped)
+	r.GET("/topics", optional, scoped)
+	r.GET("/lessons", optional, scoped)
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/guardian-portal/wards", http.StatusOK},
+		{http.MethodGet, "/api/me/sessions", http.StatusOK},
+		{http.MethodDelete, "/api/me/sessions/123", http.StatusOK},
+		{http.MethodGet, "/api/users", http.StatusForbidden},
+		{http.MethodPost, "/api/me/sessions", http.StatusForbidden},
+		{http.MethodGet, "/topics", http.StatusOK},
+		{http.MethodGet, "/lessons", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+	}
+}
@@ -5,21 +5,26 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
 
 	"crm-go/config"
 	"crm-go/models"
+	sessionServices "crm-go/services/sessions"
+	"crm-go/utils"
 	"errors"
 	"fmt"
 	"time"
 )
 
-var jwtSecret = []byte("supersecretkey") // 🔥 should come from ENV in production
-
-// AuthMiddleware verifies the JWT token AND checks session activity
+// AuthMiddleware verifies the JWT token AND checks session activity. Service accounts may send an
+// API key instead, in the X-API-Key header or as the bearer token.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key := apiKeyFromRequest(c); key != "" {
+			authenticateAPIKey(c, key)
+			return
+		}
+
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -36,29 +41,14 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Parse token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
+		// Verify signature, issuer, audience and expiry
+		claims, err := utils.ParseJWT(tokenString)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
-		}
-
 		fmt.Println("========== AUTH USER ==========")
 		// fmt.Printf("User ID: %v\n", claims["user_id"])
 		// fmt.Printf("Email: %v\n", claims["email"])
@@ -68,10 +58,19 @@ func AuthMiddleware() gin.HandlerFunc {
 		// fmt.Printf("Expires At: %v\n", claims["exp"])
 		// fmt.Println("===============================")
 
-		// ✅ NEW: Check if session is still active in database
+		// Access tokens are always issued for a session
+		if claims.SessionID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		// ✅ NEW: Check the token's session is still active and has not sat idle past the refresh window
+		now := time.Now()
 		var session models.UserSession
-		result := config.DB.Where("session_token = ? AND is_active = true AND expires_at > ?",
-			tokenString, time.Now()).
+		result := config.DB.Where("id = ? AND user_id = ? AND is_active = true AND expires_at > ? AND last_used_at > ?",
+			claims.SessionID, claims.Subject, now, sessionServices.IdleCutoff(now)).
+			Where("EXISTS (SELECT 1 FROM users WHERE users.id = user_sessions.user_id AND users.is_active AND users.deleted_at IS NULL)").
 			First(&session)
 
 		if result.Error != nil {
@@ -96,84 +95,40 @@ func AuthMiddleware() gin.HandlerFunc {
 		// fmt.Println("=============================")
 
 		// ✅ NEW: Update last used timestamp (optional but recommended)
-		config.DB.Model(&session).Update("last_used_at", time.Now())
+		config.DB.Model(&session).Update("last_used_at", now)
 
 		// Save claims into context
-		c.Set("user_id", claims["user_id"])
-		c.Set("role", claims["role"])
+		c.Set("user_id", claims.Subject)
+		c.Set("role", claims.Role)
 		c.Set("session_id", session.ID) // ✅ Add session ID to context
 
+		// Guardians may only use the guardian portal and a few self-service routes
+		if IsGuardianRole(claims.Role) {
+			if !enforceGuardianScope(c) {
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
 
-// verifyTokenWithEmail validates the JWT token and checks if the email matches
-func VerifyTokenWithEmail(tokenString string, expectedEmail string) (bool, uint, error) {
-	// Parse token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		// Use the same secret as your middleware
-		return []byte("supersecretkey"), nil // Should come from env
-	})
-
+// VerifyTokenWithEmail validates the JWT token and checks the email matches, returning the user ID
+func VerifyTokenWithEmail(tokenString string, expectedEmail string) (bool, string, error) {
+	claims, err := utils.ParseJWT(tokenString)
 	if err != nil {
-		return false, 0, err
+		return false, "", err
 	}
 
-	// Extract claims
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		// Check if token is expired
-		if exp, ok := claims["exp"].(float64); ok {
-			if int64(exp) < time.Now().Unix() {
-				return false, 0, jwt.ErrTokenExpired
-			}
-		}
-
-		// Get email from claims (assuming it's stored as "email" or "localEmail")
-		email, emailOk := claims["email"].(string)
-		if !emailOk {
-			// Try alternative claim name
-			email, emailOk = claims["localEmail"].(string)
-			if !emailOk {
-				return false, 0, jwt.ErrInvalidType
-			}
-		}
-
-		// Verify email matches
-		if email != expectedEmail {
-			return false, 0, errors.New("email mismatch")
-		}
-
-		// Get user_id from claims
-		userIDFloat, userIDOk := claims["user_id"].(float64)
-		if !userIDOk {
-			// Try int format
-			userIDInt, ok := claims["user_id"].(int)
-			if !ok {
-				return false, 0, jwt.ErrInvalidType
-			}
-			return true, uint(userIDInt), nil
-		}
-		return true, uint(userIDFloat), nil
+	// Verify email matches
+	if claims.Email != expectedEmail {
+		return false, "", errors.New("email mismatch")
 	}
-
-	return false, 0, jwt.ErrTokenInvalidClaims
+	return true, claims.Subject, nil
 }
 
-// Optional: Helper function to generate tokens (for testing)
-func GenerateTestToken(userID uint, email string, role string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"email":   email,
-		"role":    role,
-		"exp":     time.Now().Add(48 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
-		"issuer":  "crm-go",
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte("supersecretkey"))
+// Optional: Helper function to generate tokens for an existing session (for testing)
+func GenerateTestToken(userID string, email string, role string, sessionID string) (string, error) {
+	token, _, err := utils.GenerateJWT(userID, email, role, sessionID)
+	return token, err
 }
@@ -4,6 +4,7 @@ package middleware
 import (
 	"crm-go/config"
 	"crm-go/models"
+	sessionServices "crm-go/services/sessions"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,12 +30,14 @@ func SessionMiddleware() gin.HandlerFunc {
 			}
 
 			// Update session last used time
+			// Sessions already idle past the refresh window are not revived
+			now := time.Now()
 			var session models.UserSession
-			if err := config.DB.Where("session_token = ? AND is_active = true AND expires_at > ?", 
-				token, time.Now()).First(&session).Error; err == nil {
+			if err := config.DB.Where("session_token = ? AND is_active = true AND expires_at > ? AND last_used_at > ?", 
+				token, now, sessionServices.IdleCutoff(now)).First(&session).Error; err == nil {
 				
 				// Update last used time
-				config.DB.Model(&session).Update("last_used_at", time.Now())
+				config.DB.Model(&session).Update("last_used_at", now)
 			}
 		}
 
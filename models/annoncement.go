@@ -11,7 +11,7 @@ type Announcement struct {
 	Title     string    `gorm:"type:varchar(255);not null" json:"title"`
 	Message   string    `gorm:"type:text;not null" json:"message"`
 	Type      string    `gorm:"type:varchar(50);not null" json:"type"` // general, update, maintenance, urgent
-	Audience  string    `gorm:"type:varchar(50);not null" json:"audience"` // all, students, tutors, admins
+	Audience  string    `gorm:"type:varchar(50);not null" json:"audience"` // all, students, tutors, admins, guardians
 	CreatedBy uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
 
 	StartDate *time.Time `json:"start_date"`
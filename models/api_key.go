@@ -0,0 +1,68 @@
+// models/api_key.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceAccount is a non-person account that integrations such as the SIS or the website use to
+// call the API. It is backed by a user that cannot sign in, so controllers see the same user ID
+// and role as for a person and the account's role decides what its keys may do.
+type ServiceAccount struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Name        string     `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	IsActive    bool       `gorm:"not null;default:true" json:"is_active"`
+	CreatedBy   uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	User User     `gorm:"foreignKey:UserID" json:"-"`
+	Keys []APIKey `gorm:"foreignKey:ServiceAccountID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name
+func (ServiceAccount) TableName() string {
+	return "service_accounts"
+}
+
+// APIKey is a credential for a service account. Keys read "crm_<prefix>_<secret>"; the prefix
+// finds the key and only a hash of the secret is stored.
+type APIKey struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ServiceAccountID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"service_account_id"`
+	Name               string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix             string     `gorm:"type:varchar(16);not null;uniqueIndex" json:"prefix"`
+	SecretHash         string     `gorm:"type:varchar(64);not null" json:"-"` // SHA-256 of the secret
+	Scopes             string     `gorm:"type:text;not null" json:"scopes"`   // comma separated permissions; the key can do nothing else
+	RateLimitPerMinute int        `gorm:"not null" json:"rate_limit_per_minute"`
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt         *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP         string     `gorm:"type:varchar(45)" json:"last_used_ip,omitempty"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy          uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt          time.Time  `json:"created_at"`
+
+	ServiceAccount ServiceAccount `gorm:"foreignKey:ServiceAccountID" json:"-"`
+}
+
+// TableName specifies the table name
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// APIKeyUsage counts a key's requests in one minute, for its rate limit
+type APIKeyUsage struct {
+	APIKeyID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	WindowStart time.Time `gorm:"primaryKey"`
+	Count       int       `gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name
+func (APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
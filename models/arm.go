@@ -2,29 +2,31 @@
 package models
 
 import (
-	"time"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Arm struct {
-	ID          uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
-	Description string         `gorm:"type:text" json:"description"`
-	Code        string         `gorm:"type:varchar(50)" json:"code"`
-	GradeID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"grade_id"`
-	Status      string         `gorm:"type:varchar(20);default:'active';check:status IN ('active', 'inactive', 'archived')" json:"status"`
-	Capacity    int            `gorm:"default:30" json:"capacity"`
-	CreatedBy   uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name          string         `gorm:"type:varchar(255);not null" json:"name"`
+	Description   string         `gorm:"type:text" json:"description"`
+	Code          string         `gorm:"type:varchar(50)" json:"code"`
+	GradeID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"grade_id"`
+	Status        string         `gorm:"type:varchar(20);default:'active';check:status IN ('active', 'inactive', 'archived')" json:"status"`
+	Capacity      int            `gorm:"default:30" json:"capacity"`
+	FormTeacherID *uuid.UUID     `gorm:"type:uuid;index" json:"form_teacher_id"`
+	CreatedBy     uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
-	Grade ClassGrade `gorm:"foreignKey:GradeID" json:"grade,omitempty"`
+	Grade       ClassGrade `gorm:"foreignKey:GradeID" json:"grade,omitempty"`
+	FormTeacher *User      `gorm:"foreignKey:FormTeacherID" json:"form_teacher,omitempty"`
 }
 
 // TableName specifies the table name
 func (Arm) TableName() string {
 	return "arms"
-}
\ No newline at end of file
+}
@@ -0,0 +1,139 @@
+// models/billing.go
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// FeeSchedule lists what a class grade is charged for one term of a session
+type FeeSchedule struct {
+	ID                     uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name                   string         `gorm:"type:varchar(255);not null" json:"name"`
+	ClassGradeID           uuid.UUID      `gorm:"type:uuid;not null;index:idx_fee_schedule_term,unique,where:deleted_at IS NULL" json:"class_grade_id"`
+	AcademicSessionID      uuid.UUID      `gorm:"type:uuid;not null;index:idx_fee_schedule_term,unique,where:deleted_at IS NULL" json:"academic_session_id"`
+	Term                   string         `gorm:"type:varchar(50);not null;index:idx_fee_schedule_term,unique,where:deleted_at IS NULL" json:"term"`
+	Currency               string         `gorm:"type:varchar(3);not null;default:'NGN'" json:"currency"`
+	SiblingDiscountPercent float64        `gorm:"type:decimal(5,2);not null;default:0" json:"sibling_discount_percent"`
+	DueDate                *time.Time     `gorm:"type:date" json:"due_date"`
+	Status                 string         `gorm:"type:varchar(20);not null;default:'active';check:status IN ('active', 'inactive')" json:"status"`
+	CreatedBy              uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt              time.Time      `json:"created_at"`
+	UpdatedAt              time.Time      `json:"updated_at"`
+	DeletedAt              gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	ClassGrade      ClassGrade      `gorm:"foreignKey:ClassGradeID" json:"class_grade,omitempty"`
+	AcademicSession AcademicSession `gorm:"foreignKey:AcademicSessionID" json:"academic_session,omitempty"`
+	Items           []FeeItem       `gorm:"foreignKey:ScheduleID" json:"items,omitempty"`
+}
+
+// FeeItem is a single charge on a fee schedule
+type FeeItem struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ScheduleID uuid.UUID `gorm:"type:uuid;not null;index" json:"schedule_id"`
+	Name       string    `gorm:"type:varchar(255);not null" json:"name"`
+	Category   string    `gorm:"type:varchar(20);not null;check:category IN ('tuition', 'levy', 'optional')" json:"category"`
+	Amount     float64   `gorm:"type:decimal(12,2);not null" json:"amount"`
+	SortOrder  int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Invoice bills one student against a fee schedule
+type Invoice struct {
+	ID                uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	InvoiceNumber     string         `gorm:"type:varchar(50);not null;uniqueIndex" json:"invoice_number"`
+	StudentID         uuid.UUID      `gorm:"type:uuid;not null;index" json:"student_id"`
+	ScheduleID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"schedule_id"`
+	AcademicSessionID uuid.UUID      `gorm:"type:uuid;not null;index" json:"academic_session_id"`
+	Term              string         `gorm:"type:varchar(50);not null" json:"term"`
+	Currency          string         `gorm:"type:varchar(3);not null" json:"currency"`
+	Subtotal          float64        `gorm:"type:decimal(12,2);not null" json:"subtotal"`
+	DiscountAmount    float64        `gorm:"type:decimal(12,2);not null;default:0" json:"discount_amount"`
+	TotalAmount       float64        `gorm:"type:decimal(12,2);not null" json:"total_amount"`
+	AmountPaid        float64        `gorm:"type:decimal(12,2);not null;default:0" json:"amount_paid"`
+	Status            string         `gorm:"type:varchar(20);not null;default:'issued';check:status IN ('issued', 'partially_paid', 'paid', 'cancelled')" json:"status"`
+	DueDate           *time.Time     `gorm:"type:date" json:"due_date"`
+	IssuedAt          time.Time      `gorm:"not null" json:"issued_at"`
+	CancelledAt       *time.Time     `json:"cancelled_at"`
+	CreatedBy         uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Student      User                 `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	Schedule     FeeSchedule          `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+	Lines        []InvoiceLine        `gorm:"foreignKey:InvoiceID" json:"lines,omitempty"`
+	Installments []InvoiceInstallment `gorm:"foreignKey:InvoiceID" json:"installments,omitempty"`
+}
+
+// InvoiceLine is a charge or discount copied onto an invoice
+type InvoiceLine struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	InvoiceID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"invoice_id"`
+	FeeItemID   *uuid.UUID `gorm:"type:uuid" json:"fee_item_id"`
+	Description string     `gorm:"type:varchar(255);not null" json:"description"`
+	Category    string     `gorm:"type:varchar(20);not null;check:category IN ('tuition', 'levy', 'optional', 'discount')" json:"category"`
+	Amount      float64    `gorm:"type:decimal(12,2);not null" json:"amount"` // negative for discounts
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// InvoiceInstallment is one scheduled part-payment of an invoice
+type InvoiceInstallment struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	InvoiceID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_installment_sequence" json:"invoice_id"`
+	Sequence   int       `gorm:"not null;uniqueIndex:idx_installment_sequence" json:"sequence"`
+	DueDate    time.Time `gorm:"type:date;not null" json:"due_date"`
+	Amount     float64   `gorm:"type:decimal(12,2);not null" json:"amount"`
+	AmountPaid float64   `gorm:"type:decimal(12,2);not null;default:0" json:"amount_paid"`
+	Status     string    `gorm:"type:varchar(20);not null;default:'pending';check:status IN ('pending', 'partially_paid', 'paid')" json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// LedgerEntry is an append-only movement on a student's account; the balance is debits minus credits
+type LedgerEntry struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	StudentID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"student_id"`
+	InvoiceID   *uuid.UUID `gorm:"type:uuid;index" json:"invoice_id"`
+	PaymentID   *uuid.UUID `gorm:"type:uuid;index" json:"payment_id"`
+	EntryType   string     `gorm:"type:varchar(20);not null;check:entry_type IN ('invoice', 'discount', 'payment', 'reversal', 'adjustment')" json:"entry_type"`
+	Debit       float64    `gorm:"type:decimal(12,2);not null;default:0" json:"debit"`
+	Credit      float64    `gorm:"type:decimal(12,2);not null;default:0" json:"credit"`
+	Description string     `gorm:"type:varchar(255)" json:"description"`
+	CreatedBy   uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (FeeSchedule) TableName() string {
+	return "fee_schedules"
+}
+
+// TableName specifies the table name
+func (FeeItem) TableName() string {
+	return "fee_items"
+}
+
+// TableName specifies the table name
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// TableName specifies the table name
+func (InvoiceLine) TableName() string {
+	return "invoice_lines"
+}
+
+// TableName specifies the table name
+func (InvoiceInstallment) TableName() string {
+	return "invoice_installments"
+}
+
+// TableName specifies the table name
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
@@ -0,0 +1,50 @@
+// models/class_attendance.go
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// AttendanceRegister is one roll call for an arm; Period 0 is the daily register
+type AttendanceRegister struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ArmID             uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_attendance_register_slot" json:"arm_id"`
+	AcademicSessionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"academic_session_id"`
+	Date              time.Time  `gorm:"type:date;not null;uniqueIndex:idx_attendance_register_slot" json:"date"`
+	Period            int        `gorm:"not null;default:0;uniqueIndex:idx_attendance_register_slot" json:"period"`
+	LocksAt           time.Time  `gorm:"not null" json:"locks_at"`
+	TakenBy           uuid.UUID  `gorm:"type:uuid;not null" json:"taken_by"`
+	UpdatedBy         *uuid.UUID `gorm:"type:uuid" json:"updated_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relationships
+	Arm     Arm                `gorm:"foreignKey:ArmID" json:"arm,omitempty"`
+	Records []AttendanceRecord `gorm:"foreignKey:RegisterID" json:"records,omitempty"`
+}
+
+// AttendanceRecord is a single student's mark on a register
+type AttendanceRecord struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RegisterID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_attendance_record_student" json:"register_id"`
+	StudentID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_attendance_record_student;index" json:"student_id"`
+	Status             string     `gorm:"type:varchar(20);not null;check:status IN ('present', 'absent', 'late', 'excused')" json:"status"`
+	Reason             string     `gorm:"type:varchar(255)" json:"reason"`
+	GuardianNotifiedAt *time.Time `json:"guardian_notified_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	// Relationships
+	Student User `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+}
+
+// TableName specifies the table name
+func (AttendanceRegister) TableName() string {
+	return "attendance_registers"
+}
+
+// TableName specifies the table name
+func (AttendanceRecord) TableName() string {
+	return "attendance_records"
+}
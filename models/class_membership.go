@@ -0,0 +1,34 @@
+// models/class_membership.go
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ClassMembership places a student in an arm for a given academic session
+type ClassMembership struct {
+	ID                uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	StudentID         uuid.UUID      `gorm:"type:uuid;not null;index:idx_class_membership_student_session,unique" json:"student_id"`
+	ArmID             uuid.UUID      `gorm:"type:uuid;not null;index" json:"arm_id"`
+	AcademicSessionID uuid.UUID      `gorm:"type:uuid;not null;index:idx_class_membership_student_session,unique" json:"academic_session_id"`
+	Status            string         `gorm:"type:varchar(20);not null;default:'active';check:status IN ('active', 'promoted', 'repeated', 'graduated', 'transferred', 'withdrawn')" json:"status"`
+	Reason            string         `gorm:"type:varchar(255)" json:"reason"`
+	PlacedAt          time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"placed_at"`
+	WithdrawnAt       *time.Time     `json:"withdrawn_at,omitempty"`
+	CreatedBy         uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Student         User            `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	Arm             Arm             `gorm:"foreignKey:ArmID" json:"arm,omitempty"`
+	AcademicSession AcademicSession `gorm:"foreignKey:AcademicSessionID" json:"academic_session,omitempty"`
+}
+
+// TableName specifies the table name
+func (ClassMembership) TableName() string {
+	return "class_memberships"
+}
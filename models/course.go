@@ -16,6 +16,19 @@ type Course struct {
 	LearningOutcomes datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"learning_outcomes"`
 	Requirements datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"requirements"`
 
+	// Publishing workflow: draft -> review -> published -> archived
+	Status      string     `gorm:"type:varchar(20);not null;default:'draft';index;check:status IN ('draft', 'review', 'published', 'archived')" json:"status"`
+	ReviewerID  *uuid.UUID `gorm:"type:uuid;index" json:"reviewer_id,omitempty"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+
+	// Content totals, recomputed whenever modules, lessons or topics change
+	TotalModules  int `gorm:"default:0" json:"total_modules"`
+	TotalLessons  int `gorm:"default:0" json:"total_lessons"`
+	TotalTopics   int `gorm:"default:0" json:"total_topics"`
+	TotalDuration int `gorm:"default:0" json:"total_duration"` // minutes
+
 	// Relationships
     Products []CourseProductTable `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
     Categories []CourseCategoryTable `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
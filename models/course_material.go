@@ -8,8 +8,8 @@ import (
 
 type CourseMaterial struct {
 	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	CourseID    uuid.UUID  `gorm:"type:uuid;not null;index"`
-	Title       string     `gorm:"type:varchar(255);not null;index:idx_course_material_unique,unique"`
+	CourseID    uuid.UUID  `gorm:"type:uuid;not null;index;index:idx_course_material_title,unique"`
+	Title       string     `gorm:"type:varchar(255);not null;index:idx_course_material_title,unique"` // unique within a course
 	Description string     `gorm:"type:text"`
 	Type        string     `gorm:"type:varchar(50);not null;check:type IN ('document', 'video', 'audio', 'image', 'code', 'presentation', 'spreadsheet', 'archive', 'link', 'external', 'exercise', 'quiz', 'template')"`
 	FileURL     string     `gorm:"type:varchar(500)"` // Direct file URL
@@ -42,6 +42,7 @@ type CourseMaterialResponse struct {
 	Type        string     `json:"type"`
 	FileURL     string     `json:"file_url"`
 	Status      string     `json:"status"`
+	Locked      bool       `json:"locked"` // file hidden from this viewer
 	CreatedAt   time.Time
 }
 
@@ -53,6 +54,7 @@ type CourseMaterialViewResponse struct {
 	Type        string     `json:"type"`
 	FileURL     string     `json:"file_url"`
 	Status      string     `json:"status"`
+	Locked      bool       `json:"locked"` // file hidden from this viewer
 	CreatedAt   time.Time  `json:"created_at"`
 
 	// Relationships
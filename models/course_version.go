@@ -0,0 +1,36 @@
+// models/course_version.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CourseVersion tracks one copy of a course's content within a lineage. Each version is
+// its own Course row, so students enrolled on an earlier version keep their content
+// while a new draft is edited, reviewed and published for new enrollments.
+type CourseVersion struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	LineageID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_course_version_number" json:"lineage_id"` // course the lineage started from
+	CourseID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"course_id"`                            // course row holding this version
+	SourceCourseID *uuid.UUID `gorm:"type:uuid" json:"source_course_id,omitempty"`                                // version the draft was copied from
+	VersionNumber  int        `gorm:"not null;uniqueIndex:idx_course_version_number" json:"version_number"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'draft';index;check:status IN ('draft', 'in_review', 'published', 'superseded', 'discarded')" json:"status"`
+	Notes          string     `gorm:"type:text" json:"notes,omitempty"`
+	CreatedBy      uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	SubmittedAt    *time.Time `json:"submitted_at,omitempty"`
+	PublishedAt    *time.Time `json:"published_at,omitempty"`
+	PublishedBy    *uuid.UUID `gorm:"type:uuid" json:"published_by,omitempty"`
+	DiscardedAt    *time.Time `json:"discarded_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	Course Course `gorm:"foreignKey:CourseID" json:"-"`
+}
+
+// TableName specifies the table name
+func (CourseVersion) TableName() string {
+	return "course_versions"
+}
@@ -0,0 +1,39 @@
+// models/invitation.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation lets someone create an account with a role chosen by an admin. Privileged
+// accounts are only created this way. ArmID and CourseID pre-assign a class or course.
+type Invitation struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Email          string     `gorm:"type:varchar(255);not null;index" json:"email"`
+	Role           string     `gorm:"type:varchar(20);not null" json:"role"`
+	FirstName      string     `gorm:"type:varchar(100)" json:"first_name"`
+	LastName       string     `gorm:"type:varchar(100)" json:"last_name"`
+	ArmID          *uuid.UUID `gorm:"type:uuid;index" json:"arm_id,omitempty"`
+	CourseID       *uuid.UUID `gorm:"type:uuid;index" json:"course_id,omitempty"`
+	TokenHash      string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"` // SHA-256 of the link token
+	InvitedBy      uuid.UUID  `gorm:"type:uuid;not null" json:"invited_by"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	SentAt         time.Time  `gorm:"not null" json:"sent_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedUserID *uuid.UUID `gorm:"type:uuid" json:"accepted_user_id,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	Arm     *Arm    `gorm:"foreignKey:ArmID" json:"arm,omitempty"`
+	Course  *Course `gorm:"foreignKey:CourseID" json:"course,omitempty"`
+	Inviter User    `gorm:"foreignKey:InvitedBy" json:"-"`
+}
+
+// TableName specifies the table name
+func (Invitation) TableName() string {
+	return "invitations"
+}
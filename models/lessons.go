@@ -9,12 +9,12 @@ import (
 type Lesson struct {
 	ID       uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	CourseID uuid.UUID `gorm:"type:uuid;not null;index"`
-	ModuleID uuid.UUID `gorm:"type:uuid;not null;index"`
+	ModuleID uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_lesson_module_order"`
 	TutorID  uuid.UUID `gorm:"type:uuid;not null;index"`
 
 	Title       string `gorm:"type:varchar(255);not null"`
 	Description string `gorm:"type:text"`
-	Order       int    `gorm:"not null"` // Controls lesson sequence within a module
+	Order       int    `gorm:"not null;uniqueIndex:idx_lesson_module_order"` // Controls lesson sequence within a module
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -35,15 +35,18 @@ type LessonInput struct {
 }
 
 type LessonResponse struct {
-	ID          uuid.UUID `json:"id"`
-	CourseID    uuid.UUID `json:"course_id"`
-	ModuleID    uuid.UUID `json:"module_id"`
-	TutorID     uuid.UUID `json:"tutor_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Order       int       `json:"order"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID  `json:"id"`
+	CourseID    uuid.UUID  `json:"course_id"`
+	ModuleID    uuid.UUID  `json:"module_id"`
+	TutorID     uuid.UUID  `json:"tutor_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Order       int        `json:"order"`
+	Locked      bool       `json:"locked"`                // content hidden from this viewer
+	UnlockAt    *time.Time `json:"unlock_at,omitempty"`   // when a scheduled module opens
+	LockReason  string     `json:"lock_reason,omitempty"` // why the content is locked
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 type LessonMiniResponse struct {
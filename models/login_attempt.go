@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt records every sign-in try. Failures within the window drive the per-address
+// limits; UserID is empty when the email matched no account.
+type LoginAttempt struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    *uuid.UUID `gorm:"type:uuid;index"`
+	Email     string     `gorm:"type:varchar(255);index"`
+	IPAddress string     `gorm:"type:varchar(45);index:idx_login_attempt_ip_time"`
+	UserAgent string     `gorm:"type:text"`
+	Method    string     `gorm:"type:varchar(20)"` // password, login_id
+	Success   bool       `gorm:"default:false"`
+	Reason    string     `gorm:"type:varchar(50)"` // why a failure failed
+	CreatedAt time.Time  `gorm:"index:idx_login_attempt_ip_time"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// AccountUnlockToken is the single-use link emailed when an account locks. Only its
+// SHA-256 hash is stored.
+type AccountUnlockToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (AccountUnlockToken) TableName() string {
+	return "account_unlock_tokens"
+}
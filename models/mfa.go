@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA holds a user's second-factor settings. The TOTP secret is set at enrollment and
+// only counts once confirmed with a first code.
+type UserMFA struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID          uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	TOTPSecret      string    `gorm:"type:text" json:"-"`
+	TOTPEncrypted   bool      `gorm:"default:false"`
+	TOTPEnabled     bool      `gorm:"default:false"`
+	TOTPConfirmedAt *time.Time
+	TOTPLastStep    int64 `gorm:"default:0"` // last accepted time step, so a code works once
+	EmailOTPEnabled bool  `gorm:"default:false"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode is a single-use code for signing in without the usual second factor.
+// Only its SHA-256 hash is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	CodeHash  string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFAChallenge is a sign-in that passed the password check and is waiting for a second
+// factor. The client holds the opaque challenge token; only its hash is stored.
+type MFAChallenge struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	TokenHash     string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	LoginType     string    `gorm:"type:varchar(50);not null"`
+	EmailCodeHash string    `gorm:"type:varchar(64)"`
+	EmailSentAt   *time.Time
+	Attempts      int       `gorm:"default:0"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	CompletedAt   *time.Time
+	CreatedAt     time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (MFAChallenge) TableName() string {
+	return "mfa_challenges"
+}
+
+// TrustedDevice is a browser a user chose to remember after passing a second factor. It is
+// tied to the session it was created in; sessions it later opens record it in
+// UserSession.TrustedDeviceID, and revoking either side for cause forgets the device.
+type TrustedDevice struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	SessionID  *uuid.UUID `gorm:"type:uuid;index"` // session it was created in; cleared when that session is purged
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex"`
+	DeviceType string     `gorm:"type:varchar(50)"`
+	DeviceOS   string     `gorm:"type:varchar(100)"`
+	Browser    string     `gorm:"type:varchar(100)"`
+	UserIP     string     `gorm:"type:varchar(45)"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	LastUsedAt time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	User    User         `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Session *UserSession `gorm:"foreignKey:SessionID;constraint:OnDelete:SET NULL"`
+}
+
+func (TrustedDevice) TableName() string {
+	return "trusted_devices"
+}
@@ -16,8 +16,14 @@ type Module struct {
 	IsFree        bool      `gorm:"default:false"` // Free preview module
 	Status        string    `gorm:"type:varchar(20);default:'draft';check:status IN ('draft', 'review', 'approved', 'published', 'archived')"`
 	EstimatedTime int       `gorm:"default:0"` // Estimated minutes to complete
-	TotalTopics   int       `gorm:"default:0"` // Auto-calculated topic count
-	TotalDuration int       `gorm:"default:0"` // Auto-calculated total minutes
+	TotalTopics   int       `gorm:"default:0"` // Auto-calculated topic count, see services/rollup
+	TotalDuration int       `gorm:"default:0"` // Auto-calculated total minutes of its topics
+
+	// Publishing workflow; approved is kept for modules approved before review assignment existed
+	ReviewerID  *uuid.UUID `gorm:"type:uuid;index"`
+	SubmittedAt *time.Time
+	PublishedAt *time.Time
+	ArchivedAt  *time.Time
 
 	// Relationships
 	Course Course    `gorm:"foreignKey:CourseID"`
@@ -35,24 +41,26 @@ type ModuleInput struct {
 	Description   string    `json:"description"`
 	ModuleNumber  int       `json:"module_number"`
 	IsFree        bool      `json:"is_free"`
-	Status        string    `json:"status" default:"draft"`
 	EstimatedTime int       `json:"estimated_time"`
 }
 
 type ModuleResponse struct {
-	ID            uuid.UUID `json:"id"`
-	CourseID      uuid.UUID `json:"course_id"`
-	Title         string    `json:"title"`
-	Slug          string    `json:"slug"`
-	Description   string    `json:"description"`
-	ModuleNumber  int       `json:"module_number"`
-	IsFree        bool      `json:"is_free"`
-	Status        string    `json:"status"`
-	EstimatedTime int       `json:"estimated_time"`
-	TotalTopics   int       `json:"total_topics"`
-	TotalDuration int       `json:"total_duration"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uuid.UUID  `json:"id"`
+	CourseID      uuid.UUID  `json:"course_id"`
+	Title         string     `json:"title"`
+	Slug          string     `json:"slug"`
+	Description   string     `json:"description"`
+	ModuleNumber  int        `json:"module_number"`
+	IsFree        bool       `json:"is_free"`
+	Status        string     `json:"status"`
+	EstimatedTime int        `json:"estimated_time"`
+	TotalTopics   int        `json:"total_topics"`
+	TotalDuration int        `json:"total_duration"`
+	Locked        bool       `json:"locked"`                // content hidden from this viewer
+	UnlockAt      *time.Time `json:"unlock_at,omitempty"`   // when a scheduled module opens
+	LockReason    string     `json:"lock_reason,omitempty"` // why the content is locked
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
 type ModuleMiniResponse struct {
@@ -62,18 +70,22 @@ type ModuleMiniResponse struct {
 }
 
 type ModuleViewResponse struct {
-	ID            uuid.UUID `json:"id"`
-	CourseID      uuid.UUID `json:"course_id"`
-	Title         string    `json:"title"`
-	Slug          string    `json:"slug"`
-	Description   string    `json:"description"`
-	ModuleNumber  int       `json:"module_number"`
-	IsFree        bool      `json:"is_free"`
-	Status        string    `json:"status"`
-	EstimatedTime int       `json:"estimated_time"`
-	TotalDuration int       `json:"total_duration"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uuid.UUID  `json:"id"`
+	CourseID      uuid.UUID  `json:"course_id"`
+	Title         string     `json:"title"`
+	Slug          string     `json:"slug"`
+	Description   string     `json:"description"`
+	ModuleNumber  int        `json:"module_number"`
+	IsFree        bool       `json:"is_free"`
+	Status        string     `json:"status"`
+	EstimatedTime int        `json:"estimated_time"`
+	TotalTopics   int        `json:"total_topics"`
+	TotalDuration int        `json:"total_duration"`
+	Locked        bool       `json:"locked"`                // content hidden from this viewer
+	UnlockAt      *time.Time `json:"unlock_at,omitempty"`   // when a scheduled module opens
+	LockReason    string     `json:"lock_reason,omitempty"` // why the content is locked
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 
 	Course CourseMiniResponse `json:"course"`
 	Topics *TopicMiniResponse `json:"topics,omitempty"`
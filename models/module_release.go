@@ -0,0 +1,47 @@
+// models/module_release.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ModuleReleaseRule holds a module back from enrolled students until a drip delay
+// has passed, a fixed date arrives, or a prerequisite module or quiz is completed
+type ModuleReleaseRule struct {
+	ID                       uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ModuleID                 uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"module_id"`
+	CourseID                 uuid.UUID  `gorm:"type:uuid;not null;index" json:"course_id"`
+	RuleType                 string     `gorm:"type:varchar(30);not null;check:rule_type IN ('after_enrollment', 'fixed_date', 'prerequisite')" json:"rule_type"`
+	DelayDays                int        `gorm:"not null;default:0;check:delay_days >= 0" json:"delay_days"`  // after_enrollment
+	ReleaseAt                *time.Time `json:"release_at,omitempty"`                                        // fixed_date
+	PrerequisiteModuleID     *uuid.UUID `gorm:"type:uuid;index" json:"prerequisite_module_id,omitempty"`     // prerequisite
+	PrerequisiteAssignmentID *uuid.UUID `gorm:"type:uuid;index" json:"prerequisite_assignment_id,omitempty"` // prerequisite quiz
+	MinScore                 float64    `gorm:"type:decimal(5,2);not null;default:0" json:"min_score"`       // pass mark for the quiz
+	CreatedBy                uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt                time.Time  `json:"created_at"`
+	UpdatedAt                time.Time  `json:"updated_at"`
+
+	// Relationships
+	Module Module `gorm:"foreignKey:ModuleID" json:"-"`
+}
+
+// ModuleCompletion records that a student has finished a module
+type ModuleCompletion struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ModuleID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_module_completion" json:"module_id"`
+	StudentID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_module_completion;index" json:"student_id"`
+	CourseID    uuid.UUID `gorm:"type:uuid;not null;index" json:"course_id"`
+	CompletedAt time.Time `gorm:"not null" json:"completed_at"`
+}
+
+// TableName specifies the table name
+func (ModuleReleaseRule) TableName() string {
+	return "module_release_rules"
+}
+
+// TableName specifies the table name
+func (ModuleCompletion) TableName() string {
+	return "module_completions"
+}
@@ -0,0 +1,100 @@
+// models/order.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cart holds the products a user intends to buy; each user has at most one
+type Cart struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Items []CartItem `gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// CartItem is a product and quantity in a cart; prices are read at checkout
+type CartItem struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	CartID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_cart_item_product" json:"cart_id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_cart_item_product" json:"product_id"`
+	Quantity  int       `gorm:"not null;default:1;check:quantity > 0" json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Product Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+// Order is a checked-out cart with prices, tax and shipping fixed at checkout
+type Order struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OrderNumber       string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"order_number"`
+	UserID            uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status            string     `gorm:"type:varchar(20);not null;default:'pending';index;check:status IN ('pending', 'charging', 'paid', 'processing', 'shipped', 'completed', 'cancelled')" json:"status"`
+	Currency          string     `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	Subtotal          float64    `gorm:"type:decimal(12,2);not null;default:0" json:"subtotal"`
+	TaxAmount         float64    `gorm:"type:decimal(12,2);not null;default:0" json:"tax_amount"`
+	ShippingAmount    float64    `gorm:"type:decimal(12,2);not null;default:0" json:"shipping_amount"`
+	TotalAmount       float64    `gorm:"type:decimal(12,2);not null;default:0" json:"total_amount"`
+	RequiresShipping  bool       `gorm:"not null;default:false" json:"requires_shipping"`
+	ShippingAddressID *uuid.UUID `gorm:"type:uuid" json:"shipping_address_id,omitempty"`
+	ShippingAddress   string     `gorm:"type:text" json:"shipping_address,omitempty"` // snapshot taken at checkout
+	TrackingNumber    string     `gorm:"type:varchar(100)" json:"tracking_number,omitempty"`
+	Notes             string     `gorm:"type:text" json:"notes,omitempty"`
+	Gateway           string     `gorm:"type:varchar(50);not null;default:'manual'" json:"gateway"`
+	PaymentMethod     string     `gorm:"type:varchar(50)" json:"payment_method,omitempty"`
+	PaymentID         *uuid.UUID `gorm:"type:uuid;index" json:"payment_id,omitempty"`
+	PaymentAttempts   int        `gorm:"not null;default:0" json:"-"` // gateway charges started, numbering each charge's reference
+	PaidAt            *time.Time `json:"paid_at,omitempty"`
+	ShippedAt         *time.Time `json:"shipped_at,omitempty"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	CancelledAt       *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy       *uuid.UUID `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relationships
+	User  User        `gorm:"foreignKey:UserID" json:"-"`
+	Items []OrderItem `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// OrderItem is a product line on an order; course lines are fulfilled as enrollments
+type OrderItem struct {
+	ID               uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OrderID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"`
+	ProductID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	ProductName      string     `gorm:"type:varchar(255);not null" json:"product_name"`
+	UnitPrice        float64    `gorm:"type:decimal(12,2);not null" json:"unit_price"`
+	Quantity         int        `gorm:"not null;default:1;check:quantity > 0" json:"quantity"`
+	LineTotal        float64    `gorm:"type:decimal(12,2);not null" json:"line_total"`
+	RequiresShipping bool       `gorm:"not null;default:false" json:"requires_shipping"`
+	IsCourse         bool       `gorm:"not null;default:false" json:"is_course"` // product grants one or more courses
+	FulfilledAt      *time.Time `json:"fulfilled_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name
+func (Cart) TableName() string {
+	return "carts"
+}
+
+// TableName specifies the table name
+func (CartItem) TableName() string {
+	return "cart_items"
+}
+
+// TableName specifies the table name
+func (Order) TableName() string {
+	return "orders"
+}
+
+// TableName specifies the table name
+func (OrderItem) TableName() string {
+	return "order_items"
+}
@@ -0,0 +1,23 @@
+// models/password_history.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordHistory keeps the hashes of a user's previous passwords so they cannot be reused
+type PasswordHistory struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"type:text;not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"` // when this password was replaced
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
@@ -0,0 +1,61 @@
+// models/permission.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is a named set of permissions that admins can edit. Every user holds the role named by
+// User.Role everywhere; RoleAssignment grants further roles, optionally limited to one resource.
+type Role struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(20);not null;uniqueIndex" json:"name"` // the value stored in users.role
+	DisplayName string    `gorm:"type:varchar(100);not null" json:"display_name"`
+	Description string    `gorm:"type:text" json:"description"`
+	IsSystem    bool      `gorm:"not null;default:false" json:"is_system"` // built-in; cannot be renamed or deleted
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Permissions []RolePermission `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"permissions,omitempty"`
+}
+
+// TableName specifies the table name
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RolePermission grants one permission to a role. Scope "own" limits it to resources the user
+// is responsible for, such as courses they tutor or the department they head.
+type RolePermission struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RoleID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_role_permission" json:"role_id"`
+	Permission string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_role_permission" json:"permission"` // e.g. grades:write
+	Scope      string    `gorm:"type:varchar(10);not null;default:'all';check:scope IN ('all', 'own')" json:"scope"`
+}
+
+// TableName specifies the table name
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// RoleAssignment gives a user a role in addition to their primary one. With a scope it only
+// applies to that department or course and what belongs to it.
+type RoleAssignment struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	RoleID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"role_id"`
+	ScopeType  string     `gorm:"type:varchar(20);not null;default:'';check:scope_type IN ('', 'department', 'course')" json:"scope_type,omitempty"`
+	ScopeID    *uuid.UUID `gorm:"type:uuid;index" json:"scope_id,omitempty"`
+	AssignedBy uuid.UUID  `gorm:"type:uuid;not null" json:"assigned_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Role Role `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"role,omitempty"`
+}
+
+// TableName specifies the table name
+func (RoleAssignment) TableName() string {
+	return "role_assignments"
+}
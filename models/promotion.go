@@ -0,0 +1,50 @@
+// models/promotion.go
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"time"
+)
+
+// PromotionRun is the audit record of an executed promotion between two sessions
+type PromotionRun struct {
+	ID             uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	FromSessionID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"from_session_id"`
+	ToSessionID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"to_session_id"`
+	PassMark       float64        `gorm:"type:decimal(5,2);not null" json:"pass_mark"`
+	Rules          datatypes.JSON `gorm:"type:jsonb" json:"rules"`
+	TotalStudents  int            `gorm:"default:0" json:"total_students"`
+	PromotedCount  int            `gorm:"default:0" json:"promoted_count"`
+	RepeatedCount  int            `gorm:"default:0" json:"repeated_count"`
+	GraduatedCount int            `gorm:"default:0" json:"graduated_count"`
+	SkippedCount   int            `gorm:"default:0" json:"skipped_count"`
+	ExecutedBy     uuid.UUID      `gorm:"type:uuid;not null" json:"executed_by"`
+	CreatedAt      time.Time      `json:"created_at"`
+
+	// Relationships
+	Decisions []PromotionDecision `gorm:"foreignKey:RunID" json:"decisions,omitempty"`
+}
+
+// PromotionDecision records the outcome for a single student within a run
+type PromotionDecision struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RunID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"run_id"`
+	StudentID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"student_id"`
+	FromArmID    uuid.UUID  `gorm:"type:uuid;not null" json:"from_arm_id"`
+	ToArmID      *uuid.UUID `gorm:"type:uuid" json:"to_arm_id,omitempty"`
+	Outcome      string     `gorm:"type:varchar(20);not null;check:outcome IN ('promoted', 'repeated', 'graduated', 'skipped')" json:"outcome"`
+	AverageScore *float64   `gorm:"type:decimal(5,2)" json:"average_score,omitempty"`
+	Reason       string     `gorm:"type:varchar(255)" json:"reason"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name
+func (PromotionRun) TableName() string {
+	return "promotion_runs"
+}
+
+// TableName specifies the table name
+func (PromotionDecision) TableName() string {
+	return "promotion_decisions"
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is one link in a session's chain of refresh tokens. Only the SHA-256 hash of
+// the opaque token is stored. Each refresh marks the presented token used and issues its
+// successor, so a used token turning up again means it was copied and the session is revoked.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	SessionID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ParentID  *uuid.UUID `gorm:"type:uuid"` // the token this one replaced
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	Session UserSession `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
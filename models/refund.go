@@ -0,0 +1,44 @@
+// models/refund.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundRequest tracks a request to cancel a paid enrollment and return money to the student
+type RefundRequest struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EnrollmentID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"enrollment_id"`
+	StudentID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"student_id"`
+	CourseID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"course_id"`
+	PaymentID         *uuid.UUID `gorm:"type:uuid;index" json:"payment_id"`
+	Reason            string     `gorm:"type:text;not null" json:"reason"`
+	Status            string     `gorm:"type:varchar(20);not null;default:'pending';check:status IN ('pending', 'rejected', 'processed', 'failed')" json:"status"`
+	PolicyRule        string     `gorm:"type:varchar(20);not null;check:policy_rule IN ('full', 'prorated')" json:"policy_rule"`
+	AmountPaid        float64    `gorm:"type:decimal(12,2);not null" json:"amount_paid"`
+	RefundAmount      float64    `gorm:"type:decimal(12,2);not null" json:"refund_amount"`
+	Currency          string     `gorm:"type:varchar(3);not null" json:"currency"`
+	ProgressAtRequest int        `gorm:"not null;default:0" json:"progress_at_request"`
+	Gateway           string     `gorm:"type:varchar(50)" json:"gateway"`
+	GatewayRefundID   string     `gorm:"type:varchar(200)" json:"gateway_refund_id"`
+	FailureReason     string     `gorm:"type:varchar(255)" json:"failure_reason"`
+	RequestedBy       uuid.UUID  `gorm:"type:uuid;not null" json:"requested_by"`
+	ReviewedBy        *uuid.UUID `gorm:"type:uuid" json:"reviewed_by"`
+	ReviewNote        string     `gorm:"type:text" json:"review_note"`
+	ReviewedAt        *time.Time `json:"reviewed_at"`
+	ProcessedAt       *time.Time `json:"processed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relationships
+	Enrollment Enrollment `gorm:"foreignKey:EnrollmentID" json:"enrollment,omitempty"`
+	Student    User       `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	Course     Course     `gorm:"foreignKey:CourseID" json:"course,omitempty"`
+}
+
+// TableName specifies the table name
+func (RefundRequest) TableName() string {
+	return "refund_requests"
+}
@@ -11,6 +11,8 @@ import (
 type LoginInput struct {
 	Email    string `json:"email" binding:"required,email" example:"eokereke47@gmail.com"`
 	Password string `json:"password" binding:"required" example:"123456"`
+	TrustedDeviceToken string `json:"trusted_device_token,omitempty"` // skips the second factor on a remembered device; the trusted_device cookie also works
+	CaptchaToken       string `json:"captcha_token,omitempty"`        // required once captcha_required has been returned
 }
 
 
@@ -20,6 +22,11 @@ type LoginInput struct {
 type LoginResponse struct {
 	Message   string     `json:"message" example:"Login successful"`
 	Token     string     `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenExpiresAt        time.Time `json:"token_expires_at" example:"2024-01-20T15:19:05Z"`
+	RefreshToken          string    `json:"refresh_token" example:"Jb3x0Xz6r0m2c1QhV6nM0p8Yw4kT1sLq9eRfUa7dGhI"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at" example:"2024-01-23T15:04:05Z"`
+	TrustedDeviceToken     string     `json:"trusted_device_token,omitempty"`
+	TrustedDeviceExpiresAt *time.Time `json:"trusted_device_expires_at,omitempty"`
 	SessionID uuid.UUID  `json:"session_id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	User      UserInfo   `json:"user"`
 	Session   SessionInfo `json:"session"`
@@ -44,6 +51,16 @@ type SessionInfo struct {
 	IPAddress string    `json:"ip_address" example:"192.168.1.1"`
 }
 
+// LoginErrorResponse represents a refused sign-in with what the next attempt needs
+// @Description Failed or throttled sign-in
+type LoginErrorResponse struct {
+	Error           string     `json:"error" example:"Invalid credentials"`
+	Message         string     `json:"message,omitempty" example:"Invalid email or password"`
+	CaptchaRequired bool       `json:"captcha_required"`
+	RetryAfter      int        `json:"retry_after,omitempty" example:"4"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+}
+
 // ErrorResponse represents an error response
 // @Description Error response structure
 type ErrorResponse struct {
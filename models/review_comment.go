@@ -0,0 +1,28 @@
+// models/review_comment.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewComment is an entry in a course or module's review thread: a reviewer or author
+// comment, or a workflow event such as a submission, a change request or a publish
+type ReviewComment struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ItemType  string    `gorm:"type:varchar(20);not null;index:idx_review_comment_item;check:item_type IN ('course', 'module')" json:"item_type"`
+	ItemID    uuid.UUID `gorm:"type:uuid;not null;index:idx_review_comment_item" json:"item_id"`
+	AuthorID  uuid.UUID `gorm:"type:uuid;not null;index" json:"author_id"`
+	Action    string    `gorm:"type:varchar(30);not null;default:'comment';check:action IN ('comment', 'submitted', 'reviewer_assigned', 'changes_requested', 'published', 'archived')" json:"action"`
+	Body      string    `gorm:"type:text" json:"body,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Author User `gorm:"foreignKey:AuthorID" json:"-"`
+}
+
+// TableName specifies the table name
+func (ReviewComment) TableName() string {
+	return "review_comments"
+}
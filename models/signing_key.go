@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SigningKey is an asymmetric key pair used to sign access tokens. The active key signs new
+// tokens; after a rotation the previous key is retiring and still verifies the tokens it
+// signed until RetireAt, when it is retired and dropped from the JWKS.
+type SigningKey struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	KID         string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"kid"`
+	Algorithm   string     `gorm:"type:varchar(10);not null;check:algorithm IN ('RS256', 'EdDSA')" json:"algorithm"`
+	Status      string     `gorm:"type:varchar(20);not null;default:'active';index;check:status IN ('active', 'retiring', 'retired')" json:"status"`
+	PublicKey   string     `gorm:"type:text;not null" json:"public_key"` // PEM-encoded PKIX
+	PrivateKey  string     `gorm:"type:text;not null" json:"-"`          // PEM-encoded PKCS #8, sealed when Encrypted
+	Encrypted   bool       `gorm:"default:false" json:"encrypted"`
+	ActivatedAt time.Time  `json:"activated_at"`
+	RetireAt    *time.Time `json:"retire_at,omitempty"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+	CreatedBy   *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (SigningKey) TableName() string {
+	return "signing_keys"
+}
@@ -0,0 +1,71 @@
+// models/sso.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// SSOProvider is an external identity provider users can sign in with: any OpenID Connect
+// issuer, or a SAML 2.0 identity provider such as a school district's
+type SSOProvider struct {
+	ID      uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Slug    string    `gorm:"type:varchar(50);not null;uniqueIndex" json:"slug"` // used in sign-in URLs; fixed once created
+	Name    string    `gorm:"type:varchar(100);not null" json:"name"`
+	Type    string    `gorm:"type:varchar(10);not null;check:type IN ('oidc', 'saml')" json:"type"`
+	Enabled bool      `gorm:"not null;default:true" json:"enabled"`
+
+	// OpenID Connect
+	Issuer          string `gorm:"type:varchar(255)" json:"issuer,omitempty"`
+	ClientID        string `gorm:"type:varchar(255)" json:"client_id,omitempty"`
+	ClientSecret    string `gorm:"type:text" json:"-"`
+	SecretEncrypted bool   `gorm:"not null;default:false" json:"-"`
+	Scopes          string `gorm:"type:varchar(255)" json:"scopes,omitempty"` // extra scopes, space separated
+
+	// SAML 2.0; metadata is fetched from the URL unless the XML is given
+	IDPMetadataURL string `gorm:"type:varchar(500)" json:"idp_metadata_url,omitempty"`
+	IDPMetadataXML string `gorm:"type:text" json:"-"`
+
+	// Account linking and provisioning
+	AllowedDomains string `gorm:"type:varchar(500)" json:"allowed_domains,omitempty"` // comma separated; empty allows any
+	AutoProvision  bool   `gorm:"not null;default:false" json:"auto_provision"`       // create accounts on first sign-in
+	TrustEmail     bool   `gorm:"not null;default:false" json:"trust_email"`          // SAML has no verified-email claim
+
+	// Role and department mapping from IdP claims; values not in a mapping are ignored
+	RoleClaim         string         `gorm:"type:varchar(255)" json:"role_claim,omitempty"`
+	RoleMapping       datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"role_mapping"`
+	DefaultRole       string         `gorm:"type:varchar(20);not null;default:'student'" json:"default_role"`
+	DepartmentClaim   string         `gorm:"type:varchar(255)" json:"department_claim,omitempty"`
+	DepartmentMapping datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"department_mapping"` // claim value to department code
+	SyncAttributes    bool           `gorm:"not null;default:false" json:"sync_attributes"`     // re-apply the mappings at every sign-in
+
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (SSOProvider) TableName() string {
+	return "sso_providers"
+}
+
+// SSOIdentity links a user to their subject at an identity provider. Provider is the provider
+// slug, so the built-in Google provider needs no row of its own.
+type SSOIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider    string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_sso_identity_subject" json:"provider"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_sso_identity_subject" json:"subject"`
+	Email       string     `gorm:"type:varchar(255)" json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name
+func (SSOIdentity) TableName() string {
+	return "sso_identities"
+}
@@ -0,0 +1,82 @@
+// models/subscription.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SubscriptionPlan is a recurring plan granting access to bundled products or course categories
+type SubscriptionPlan struct {
+	ID               uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name             string         `gorm:"type:varchar(255);not null" json:"name"`
+	Slug             string         `gorm:"type:varchar(255);not null;index:idx_subscription_plan_slug,unique,where:deleted_at IS NULL" json:"slug"`
+	Description      string         `gorm:"type:text" json:"description"`
+	BillingInterval  string         `gorm:"type:varchar(20);not null;check:billing_interval IN ('monthly', 'yearly')" json:"billing_interval"`
+	Price            float64        `gorm:"type:decimal(12,2);not null" json:"price"`
+	Currency         string         `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	TrialDays        int            `gorm:"not null;default:0" json:"trial_days"`
+	GracePeriodDays  int            `gorm:"not null;default:3" json:"grace_period_days"`
+	MaxRetryAttempts int            `gorm:"not null;default:3" json:"max_retry_attempts"` // dunning retries before the subscription ends
+	Status           string         `gorm:"type:varchar(20);not null;default:'active';check:status IN ('active', 'inactive')" json:"status"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Items []SubscriptionPlanItem `gorm:"foreignKey:PlanID" json:"items,omitempty"`
+}
+
+// SubscriptionPlanItem bundles a product or a whole course category into a plan
+type SubscriptionPlanItem struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	PlanID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_subscription_plan_item" json:"plan_id"`
+	ItemType  string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_subscription_plan_item;check:item_type IN ('product', 'category')" json:"item_type"`
+	ItemID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_subscription_plan_item;index" json:"item_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscription is a user's membership of a plan and its billing cycle
+type Subscription struct {
+	ID                   uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID               uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	PlanID               uuid.UUID  `gorm:"type:uuid;not null;index" json:"plan_id"`
+	Status               string     `gorm:"type:varchar(20);not null;default:'active';index;check:status IN ('trialing', 'active', 'past_due', 'cancelled', 'expired')" json:"status"`
+	CurrentPeriodStart   time.Time  `gorm:"not null" json:"current_period_start"`
+	CurrentPeriodEnd     time.Time  `gorm:"not null;index" json:"current_period_end"`
+	TrialEndsAt          *time.Time `json:"trial_ends_at"`
+	GraceEndsAt          *time.Time `json:"grace_ends_at"`
+	CancelAtPeriodEnd    bool       `gorm:"not null;default:false" json:"cancel_at_period_end"`
+	CancelledAt          *time.Time `json:"cancelled_at"`
+	EndedAt              *time.Time `json:"ended_at"`
+	FailedPaymentCount   int        `gorm:"not null;default:0" json:"failed_payment_count"`
+	NextRetryAt          *time.Time `gorm:"index" json:"next_retry_at"`
+	Gateway              string     `gorm:"type:varchar(50);not null;default:'manual'" json:"gateway"`
+	PaymentMethod        string     `gorm:"type:varchar(50)" json:"payment_method"`
+	GatewayCustomerID    string     `gorm:"type:varchar(200)" json:"-"` // saved customer or authorization for renewals
+	GatewayPaymentMethod string     `gorm:"type:varchar(200)" json:"-"`
+	LastPaymentID        *uuid.UUID `gorm:"type:uuid" json:"last_payment_id"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Plan SubscriptionPlan `gorm:"foreignKey:PlanID" json:"plan,omitempty"`
+}
+
+// TableName specifies the table name
+func (SubscriptionPlan) TableName() string {
+	return "subscription_plans"
+}
+
+// TableName specifies the table name
+func (SubscriptionPlanItem) TableName() string {
+	return "subscription_plan_items"
+}
+
+// TableName specifies the table name
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
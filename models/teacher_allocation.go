@@ -0,0 +1,51 @@
+// models/teacher_allocation.go
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// TeacherAllocation records which teacher teaches a subject to an arm in a session
+type TeacherAllocation struct {
+	ID                uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TeacherID         uuid.UUID      `gorm:"type:uuid;not null;index" json:"teacher_id"`
+	SubjectID         uuid.UUID      `gorm:"type:uuid;not null;index:idx_teacher_allocation_unique,unique,where:deleted_at IS NULL" json:"subject_id"`
+	ArmID             uuid.UUID      `gorm:"type:uuid;not null;index:idx_teacher_allocation_unique,unique,where:deleted_at IS NULL" json:"arm_id"`
+	AcademicSessionID uuid.UUID      `gorm:"type:uuid;not null;index:idx_teacher_allocation_unique,unique,where:deleted_at IS NULL" json:"academic_session_id"`
+	PeriodsPerWeek    int            `gorm:"not null;default:4" json:"periods_per_week"`
+	Status            string         `gorm:"type:varchar(20);not null;default:'active';check:status IN ('active', 'inactive')" json:"status"`
+	CreatedBy         uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Teacher         User            `gorm:"foreignKey:TeacherID" json:"teacher,omitempty"`
+	Subject         Subject         `gorm:"foreignKey:SubjectID" json:"subject,omitempty"`
+	Arm             Arm             `gorm:"foreignKey:ArmID" json:"arm,omitempty"`
+	AcademicSession AcademicSession `gorm:"foreignKey:AcademicSessionID" json:"academic_session,omitempty"`
+}
+
+// TeacherUnavailability blocks a teacher from being timetabled on a day or period
+type TeacherUnavailability struct {
+	ID                uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TeacherID         uuid.UUID `gorm:"type:uuid;not null;index" json:"teacher_id"`
+	AcademicSessionID uuid.UUID `gorm:"type:uuid;not null;index" json:"academic_session_id"`
+	DayOfWeek         int       `gorm:"not null;check:day_of_week BETWEEN 1 AND 7" json:"day_of_week"` // 1 = Monday
+	Period            int       `gorm:"not null;default:0" json:"period"`                              // 0 = whole day
+	Reason            string    `gorm:"type:varchar(255)" json:"reason"`
+	CreatedBy         uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name
+func (TeacherAllocation) TableName() string {
+	return "teacher_allocations"
+}
+
+// TableName specifies the table name
+func (TeacherUnavailability) TableName() string {
+	return "teacher_unavailabilities"
+}
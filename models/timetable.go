@@ -0,0 +1,31 @@
+// models/timetable.go
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// TimetableSlot assigns one period of an arm's week to a subject and teacher
+type TimetableSlot struct {
+	ID                uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	AcademicSessionID uuid.UUID `gorm:"type:uuid;not null;index;index:idx_timetable_teacher_slot,unique" json:"academic_session_id"`
+	ArmID             uuid.UUID `gorm:"type:uuid;not null;index:idx_timetable_arm_slot,unique" json:"arm_id"`
+	AllocationID      uuid.UUID `gorm:"type:uuid;not null;index" json:"allocation_id"`
+	SubjectID         uuid.UUID `gorm:"type:uuid;not null" json:"subject_id"`
+	TeacherID         uuid.UUID `gorm:"type:uuid;not null;index:idx_timetable_teacher_slot,unique" json:"teacher_id"`
+	DayOfWeek         int       `gorm:"not null;index:idx_timetable_arm_slot,unique;index:idx_timetable_teacher_slot,unique" json:"day_of_week"` // 1 = Monday
+	Period            int       `gorm:"not null;index:idx_timetable_arm_slot,unique;index:idx_timetable_teacher_slot,unique" json:"period"`
+	GeneratedBy       uuid.UUID `gorm:"type:uuid;not null" json:"generated_by"`
+	CreatedAt         time.Time `json:"created_at"`
+
+	// Relationships
+	Arm     Arm     `gorm:"foreignKey:ArmID" json:"arm,omitempty"`
+	Subject Subject `gorm:"foreignKey:SubjectID" json:"subject,omitempty"`
+	Teacher User    `gorm:"foreignKey:TeacherID" json:"teacher,omitempty"`
+}
+
+// TableName specifies the table name
+func (TimetableSlot) TableName() string {
+	return "timetable_slots"
+}
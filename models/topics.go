@@ -9,14 +9,17 @@ import (
 type Topics struct {
 	ID       uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	CourseID uuid.UUID `gorm:"type:uuid;not null;index"`
-	ModuleID uuid.UUID `gorm:"type:uuid;not null;index"`
-	LessonID uuid.UUID `gorm:"type:uuid;index"`
+	ModuleID uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_topic_lesson_order"`
+	LessonID uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_topic_lesson_order"`
 	TutorID  uuid.UUID `gorm:"type:uuid;not null;index"`
 	Title       string `gorm:"type:varchar(255);not null"`
 	ContentType string `gorm:"type:varchar(50);not null"`
 	ContentURL  string `gorm:"type:varchar(500);not null"`
 	ContentText string `gorm:"type:text;not null"`
-	Order       int    				`gorm:"not null"` // Controls topic sequence within a lesson
+	Order       int    				`gorm:"not null;uniqueIndex:idx_topic_lesson_order"` // Controls topic sequence within a lesson
+	EstimatedDuration int `gorm:"default:0"` // Author's estimate in minutes
+	VideoDuration     int `gorm:"default:0"` // Probed video length in seconds
+	Duration          int `gorm:"default:0"` // Minutes counted towards module and course totals
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -35,6 +38,7 @@ type TopicInput struct {
 	ContentURL  string    `json:"content_url" binding:"required"`
 	ContentText string    `json:"content_text" binding:"required"`
 	Order       int       `json:"order" binding:"required"`
+	EstimatedDuration int `json:"estimated_duration" binding:"min=0"` // minutes
 }
 
 type TopicResponse struct {
@@ -48,6 +52,10 @@ type TopicResponse struct {
 	ContentURL  string    `json:"content_url"`
 	ContentText string    `json:"content_text"`
 	Order       int       `json:"order"`
+	EstimatedDuration int `json:"estimated_duration"` // minutes
+	VideoDuration     int `json:"video_duration"`     // seconds, when probed
+	Duration          int `json:"duration"`           // minutes
+	Locked      bool      `json:"locked"` // content hidden from this viewer
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -69,6 +77,10 @@ type TopicViewResponse struct {
 	ContentURL  string              `json:"content_url"`
 	ContentText string              `json:"content_text"`
 	Order 	 	int                 `json:"order"`
+	EstimatedDuration int           `json:"estimated_duration"` // minutes
+	VideoDuration     int           `json:"video_duration"`     // seconds, when probed
+	Duration          int           `json:"duration"`           // minutes
+	Locked      bool                `json:"locked"` // content hidden from this viewer
 	CreatedAt   time.Time           `json:"created_at"`
 	UpdatedAt   time.Time           `json:"updated_at"`
 	Course      CourseMiniResponse  `json:"course"`
@@ -81,9 +93,19 @@ type TopicUpdateInput struct {
 	ContentURL  string `json:"content_url" binding:"required"`
 	ContentText string `json:"content_text" binding:"required"`
 	Order       int    `json:"order" binding:"required"`
+	EstimatedDuration int `json:"estimated_duration" binding:"min=0"` // minutes
 }
 
 // TableName specifies the table name
 func (Topics) TableName() string {
 	return "topics"
 }
+
+// TopicDuration returns the minutes a topic counts for: the probed video length when known,
+// otherwise the author's estimate
+func TopicDuration(estimated, videoSeconds int) int {
+	if videoSeconds > 0 {
+		return (videoSeconds + 59) / 60
+	}
+	return estimated
+}
@@ -17,26 +17,35 @@ type User struct {
 	MiddleName string         `gorm:"type:varchar(100)" json:"middle_name"`
 	Email      string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
 	Password   string         `gorm:"type:text" json:"-"`
-	LoginID    string         `gorm:"type:text" json:"login_id,omitempty"`
+	LoginID    string         `gorm:"type:text" json:"-"` // bcrypt hash
 	Picture    string         `gorm:"type:text" json:"picture,omitempty"`
 	Provider   string         `gorm:"type:varchar(50);default:'local'" json:"provider"`
 	Role       string         `gorm:"type:varchar(20);default:'user'" json:"role"`
 	Phone      string         `gorm:"type:varchar(20)" json:"phone"`
 	IsVerified bool           `gorm:"default:false" json:"is_verified"`
-	IsActive bool           `gorm:"default:false" json:"is_active"`
+	VerifiedAt *time.Time     `json:"verified_at,omitempty"`
+	IsActive bool           `gorm:"default:true" json:"is_active"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
 	Location   string         `gorm:"type:varchar(255)" json:"location"`
+	DepartmentID *uuid.UUID   `gorm:"type:uuid;index" json:"department_id,omitempty"` // set from single sign-on claims
 	LastLoginAt *time.Time    `json:"last_login_at"`
+	PasswordChangedAt *time.Time `json:"-"`
+	FailedLoginCount  int        `gorm:"default:0" json:"-"` // consecutive failures since the last success
+	LastFailedLoginAt *time.Time `json:"-"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  time.Time      `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 
+// PasswordReset is a single-use reset link; only a hash of the emailed token is stored
 type PasswordReset struct {
 	ID        string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	UserID    string    `gorm:"type:uuid;not null"`
-	Token     string    `gorm:"uniqueIndex;not null"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);uniqueIndex;not null"` // SHA-256 of the emailed token
 	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
 }
 
 
@@ -9,7 +9,7 @@ import (
 
 type UserSession struct {
 	ID           uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	SessionToken string         `gorm:"type:varchar(500);uniqueIndex;not null"`
+	SessionToken string         `gorm:"type:text;uniqueIndex;not null"` // signed JWTs outgrow varchar(500) with RS256
 	UserID       uuid.UUID      `gorm:"type:uuid;not null;index"`
 	UserAgent    string         `gorm:"type:text"`
 	UserIP       string         `gorm:"type:varchar(45)"`
@@ -23,6 +23,9 @@ type UserSession struct {
 	LastUsedAt   time.Time      `gorm:"default:CURRENT_TIMESTAMP;index"`
 	CreatedAt    time.Time
     LoggedOutAt  *time.Time     `gorm:"index"`  // ✅ Changed to pointer
+	RevokedReason string        `gorm:"type:varchar(50)"` // logout, refresh_token_reuse, expired
+	MFAMethod     string        `gorm:"type:varchar(20)"` // second factor passed at sign-in: totp, email, recovery, trusted_device
+	TrustedDeviceID *uuid.UUID  `gorm:"type:uuid;index"`  // remembered device that skipped the second factor
 
 	User         User           `gorm:"foreignKey:UserID"`
 }
@@ -0,0 +1,23 @@
+// routes/access_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/access"
+	"crm-go/middleware"
+	"crm-go/services/access"
+)
+
+func AccessRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	accessService := services.NewAccessService(db)
+	accessHandler := controllers.NewAccessHandler(accessService)
+
+	accessGroup := router.Group("/api/access")
+	accessGroup.Use(middleware.AuthMiddleware())
+	{
+		accessGroup.GET("/courses/:course_id", accessHandler.GetCourseAccess)
+		accessGroup.GET("/modules/:module_id", accessHandler.GetModuleAccess)
+	}
+}
@@ -21,16 +21,16 @@ func AddressRoutes(router *gin.RouterGroup, db *gorm.DB) {
 		addressGroup.POST("/addresses", addressHandler.CreateAddress)
 
 		// Get all addresses with pagination and filters
-		addressGroup.GET("/addresses", addressHandler.GetAllAddresses)
+		addressGroup.GET("/addresses", middleware.RequirePermission("addresses:read"), addressHandler.GetAllAddresses)
 
 		// Get addresses by user
-		addressGroup.GET("/addresses/user/:user_id", addressHandler.GetAddressesByUser)
+		addressGroup.GET("/addresses/user/:user_id", middleware.RequirePermission("addresses:read", middleware.ResourceParam("user", "user_id")), addressHandler.GetAddressesByUser)
 
 		// Get primary address by user
-		addressGroup.GET("/addresses/user/:user_id/primary", addressHandler.GetPrimaryAddressByUser)
+		addressGroup.GET("/addresses/user/:user_id/primary", middleware.RequirePermission("addresses:read", middleware.ResourceParam("user", "user_id")), addressHandler.GetPrimaryAddressByUser)
 
 		// Get address by ID
-		addressGroup.GET("/addresses/:id", addressHandler.GetAddressByID)
+		addressGroup.GET("/addresses/:id", middleware.RequirePermission("addresses:read", middleware.ResourceParam("address", "id")), addressHandler.GetAddressByID)
 
 		// Update address
 		addressGroup.PUT("/addresses/:id", addressHandler.UpdateAddress)
@@ -15,7 +15,7 @@ func AdminDangerRoutes(r *gin.Engine) {
 
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
-		protected.Use(middleware.RoleMiddleware("admin"))	
+		protected.Use(middleware.RequirePermission("admin:access"))	
 		protected.GET("/export/excel", admin.ExportExcelHandler)
 
 	}
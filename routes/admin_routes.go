@@ -11,7 +11,7 @@ func AdminRoutes(r *gin.Engine) {
 	protected.Use(middleware.AuthMiddleware())
 	{
 	// Course routes (admin only ideally)
-	protected.GET("/admin", middleware.RoleMiddleware("admin"), func(c *gin.Context) {
+	protected.GET("/admin", middleware.RequirePermission("admin:access"), func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "Welcome Admin!"})
 	})
 
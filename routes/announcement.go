@@ -16,9 +16,9 @@ func AnnouncementRoutes(r *gin.Engine) {
 		// Protected routes
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
-		protected.POST("/announcements", middleware.RoleMiddleware("admin"), announcementController.CreateAnnouncement)
-		protected.PUT("/announcements/:id", middleware.RoleMiddleware("admin"), announcementController.UpdateAnnouncement)
-		protected.DELETE("/announcements/:id", middleware.RoleMiddleware("admin"), announcementController.DeleteAnnouncement)
+		protected.POST("/announcements", middleware.RequirePermission("announcements:write"), announcementController.CreateAnnouncement)
+		protected.PUT("/announcements/:id", middleware.RequirePermission("announcements:write"), announcementController.UpdateAnnouncement)
+		protected.DELETE("/announcements/:id", middleware.RequirePermission("announcements:write"), announcementController.DeleteAnnouncement)
 
 	}
 }
@@ -0,0 +1,30 @@
+// routes/api_key_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/api_keys"
+	"crm-go/middleware"
+	"crm-go/services/api_keys"
+)
+
+func APIKeyRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	apiKeyService := services.NewAPIKeyService(db)
+	apiKeyHandler := controllers.NewAPIKeyHandler(apiKeyService)
+
+	accountGroup := router.Group("/api/admin/service-accounts")
+	accountGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("service_accounts:manage"))
+	{
+		accountGroup.POST("", apiKeyHandler.CreateServiceAccount)
+		accountGroup.GET("", apiKeyHandler.GetServiceAccounts)
+		accountGroup.GET("/:id", apiKeyHandler.GetServiceAccount)
+		accountGroup.PUT("/:id", apiKeyHandler.UpdateServiceAccount)
+		accountGroup.DELETE("/:id", apiKeyHandler.DeleteServiceAccount)
+
+		accountGroup.POST("/:id/keys", apiKeyHandler.CreateAPIKey)
+		accountGroup.GET("/:id/keys", apiKeyHandler.GetAPIKeys)
+		accountGroup.DELETE("/:id/keys/:key_id", apiKeyHandler.RevokeAPIKey)
+	}
+}
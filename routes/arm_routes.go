@@ -18,24 +18,24 @@ func ArmRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	armGroup.Use(middleware.AuthMiddleware())
 	{
 		// Create arm
-		armGroup.POST("/arms", armHandler.CreateArm)
+		armGroup.POST("/arms", middleware.RequirePermission("classes:write"), armHandler.CreateArm)
 
 		// Get all arms with pagination and filters
-		armGroup.GET("/arms", armHandler.GetAllArms)
+		armGroup.GET("/arms", middleware.RequirePermission("classes:read"), armHandler.GetAllArms)
 
 		// Get arms by grade
-		armGroup.GET("/arms/grade/:grade_id", armHandler.GetArmsByGrade)
+		armGroup.GET("/arms/grade/:grade_id", middleware.RequirePermission("classes:read"), armHandler.GetArmsByGrade)
 
 		// Get arm by ID
-		armGroup.GET("/arms/:id", armHandler.GetArmByID)
+		armGroup.GET("/arms/:id", middleware.RequirePermission("classes:read"), armHandler.GetArmByID)
 
 		// Update arm
-		armGroup.PUT("/arms/:id", armHandler.UpdateArm)
+		armGroup.PUT("/arms/:id", middleware.RequirePermission("classes:write"), armHandler.UpdateArm)
 
 		// Soft Delete arm
-		armGroup.DELETE("/arms/:id", armHandler.DeleteArm)
+		armGroup.DELETE("/arms/:id", middleware.RequirePermission("classes:write"), armHandler.DeleteArm)
 		
 		// Permanent Delete arm
-		armGroup.DELETE("/arms/permanent/:id", armHandler.DeleteArmPermanently)
+		armGroup.DELETE("/arms/permanent/:id", middleware.RequirePermission("classes:write"), armHandler.DeleteArmPermanently)
 	}
 }
\ No newline at end of file
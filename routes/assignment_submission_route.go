@@ -28,9 +28,9 @@ func AssignmentSubmissionRoutes(r *gin.Engine, db *gorm.DB) {
 		config.DB,
 		activitySvc,
 	)
-		protected.POST("/assignment_submissions", middleware.RoleMiddleware("admin"), assignmentController.CreateAssignmentSubmission)
-		// protected.PUT("/assignment_submissions/:id", middleware.RoleMiddleware("admin"), assignmentController.UpdateAssignmentSubmission)
-		// protected.DELETE("/assignment_submissions/:id", middleware.RoleMiddleware("admin"), assignmentController.DeleteAssignmentSubmission)
+		protected.POST("/assignment_submissions", middleware.RequirePermission("assignments:write"), assignmentController.CreateAssignmentSubmission)
+		// protected.PUT("/assignment_submissions/:id", middleware.RequirePermission("assignments:write"), assignmentController.UpdateAssignmentSubmission)
+		// protected.DELETE("/assignment_submissions/:id", middleware.RequirePermission("assignments:write"), assignmentController.DeleteAssignmentSubmission)
 
 	}
 }
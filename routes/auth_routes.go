@@ -2,6 +2,7 @@ package routes
 
 import (
 	"crm-go/controllers/authentication"
+	"crm-go/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,13 +10,25 @@ func RegisterAuthRoutes(r *gin.Engine) {
 	auth := r.Group("/auth")
 	{
 		auth.POST("/signup", controllers.SignUp)
+		auth.POST("/verify-email", controllers.VerifyEmail)
+		auth.POST("/verify-email/resend", controllers.ResendVerification)
 		auth.POST("/login", controllers.Login)
 		auth.POST("/login/id", controllers.LoginId)
 		auth.POST("/logout", controllers.Logout)
+		auth.POST("/refresh", controllers.RefreshToken)
+		auth.POST("/mfa/email", controllers.SendMFAEmailCode)
+		auth.POST("/mfa/verify", controllers.VerifyMFA)
+		auth.POST("/unlock", controllers.UnlockAccount)
 		auth.GET("/google/login", controllers.GoogleLoginHandler)
 		auth.GET("/google/callback", controllers.GoogleCallbackHandler)
+		auth.GET("/sso/providers", controllers.SSOProviders)
+		auth.GET("/sso/:provider/login", controllers.SSOLogin)
+		auth.GET("/sso/:provider/callback", controllers.SSOCallback)
+		auth.POST("/sso/:provider/acs", controllers.SAMLAssertionConsumer)
+		auth.GET("/sso/:provider/metadata", controllers.SAMLMetadata)
 		auth.POST("/forgot-password", controllers.ForgotPassword)
 		auth.POST("/reset-password", controllers.ResetPassword)
+		auth.POST("/change-password", middleware.AuthMiddleware(), controllers.ChangePassword)
 
 	}
 }
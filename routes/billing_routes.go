@@ -0,0 +1,47 @@
+// routes/billing_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/billing"
+	"crm-go/middleware"
+	"crm-go/services/billing"
+)
+
+func BillingRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	billingService := services.NewBillingService(db)
+	billingHandler := controllers.NewBillingHandler(billingService)
+
+	billingGroup := router.Group("/api/billing")
+	billingGroup.Use(middleware.AuthMiddleware())
+	{
+		// Admin only: fee schedules
+		adminGroup := billingGroup.Group("")
+		adminGroup.Use(middleware.RequirePermission("billing:manage"))
+		{
+			adminGroup.POST("/fee-schedules", billingHandler.CreateFeeSchedule)
+			adminGroup.GET("/fee-schedules", billingHandler.GetFeeSchedules)
+			adminGroup.GET("/fee-schedules/:id", billingHandler.GetFeeScheduleByID)
+			adminGroup.PUT("/fee-schedules/:id", billingHandler.UpdateFeeSchedule)
+			adminGroup.DELETE("/fee-schedules/:id", billingHandler.DeleteFeeSchedule)
+
+			// Invoices and payments
+			adminGroup.POST("/invoices/generate", billingHandler.GenerateInvoices)
+			adminGroup.GET("/invoices", billingHandler.GetInvoices)
+			adminGroup.GET("/invoices/:id", billingHandler.GetInvoiceByID)
+			adminGroup.PUT("/invoices/:id/installments", billingHandler.SetInstallments)
+			adminGroup.POST("/invoices/:id/cancel", billingHandler.CancelInvoice)
+			adminGroup.POST("/invoices/:id/payments", billingHandler.RecordPayment)
+		}
+
+		// Statements and receipts for the student, their guardians and admins
+		accountGroup := billingGroup.Group("/students/:student_id")
+		accountGroup.Use(middleware.WardAccessMiddleware(), middleware.RequirePermission("billing:account", middleware.ResourceParam("student", "student_id")))
+		{
+			accountGroup.GET("/statement", billingHandler.GetStatement)
+			accountGroup.GET("/payments/:payment_id/receipt", billingHandler.GetReceipt)
+		}
+	}
+}
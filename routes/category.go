@@ -16,9 +16,9 @@ func CategoryRoutes(r *gin.Engine) {
 		// Protected routes
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
-		protected.POST("/categories", middleware.RoleMiddleware("admin"), controllers.CreateCategory)
-		protected.PUT("/categories/:id", middleware.RoleMiddleware("admin"), controllers.UpdateCategory)
-		protected.DELETE("/categories/:id", middleware.RoleMiddleware("admin"), controllers.DeleteCategory)
+		protected.POST("/categories", middleware.RequirePermission("categories:write"), controllers.CreateCategory)
+		protected.PUT("/categories/:id", middleware.RequirePermission("categories:write"), controllers.UpdateCategory)
+		protected.DELETE("/categories/:id", middleware.RequirePermission("categories:write"), controllers.DeleteCategory)
 
 	}
 }
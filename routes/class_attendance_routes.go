@@ -0,0 +1,32 @@
+// routes/class_attendance_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/class_attendance"
+	"crm-go/middleware"
+	"crm-go/services/class_attendance"
+)
+
+func ClassAttendanceRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	attendanceService := services.NewClassAttendanceService(db)
+	attendanceHandler := controllers.NewClassAttendanceHandler(attendanceService)
+
+	attendanceGroup := router.Group("/api/class-attendance")
+	attendanceGroup.Use(middleware.AuthMiddleware())
+	attendanceGroup.Use(middleware.RequirePermission("attendance:write"))
+	{
+		// Take the register
+		attendanceGroup.POST("", attendanceHandler.SubmitRegister)
+
+		// Registers
+		attendanceGroup.GET("/:id", attendanceHandler.GetRegisterByID)
+		attendanceGroup.GET("/arm/:arm_id", attendanceHandler.GetArmRegisters)
+
+		// Summaries
+		attendanceGroup.GET("/arm/:arm_id/summary", attendanceHandler.GetArmSummary)
+		attendanceGroup.GET("/student/:student_id/summary", attendanceHandler.GetStudentSummary)
+	}
+}
@@ -18,12 +18,12 @@ func ClassGradeRoutes(router *gin.Engine, db *gorm.DB) {
 		// Create class grade
 	protected := router.Group("/api/class-grades")
 	protected.Use(middleware.AuthMiddleware())
-	protected.GET("", middleware.RoleMiddleware("admin"), classGradeController.GetAllClassGrades)
-	protected.GET("/levels", middleware.RoleMiddleware("admin"), classGradeController.GetLevels)
-	protected.GET("/:id", middleware.RoleMiddleware("admin"), classGradeController.GetClassGradeByID)
-	protected.POST("", middleware.RoleMiddleware("admin"), classGradeController.CreateClassGrade)
-	protected.PUT("/:id", middleware.RoleMiddleware("admin"), classGradeController.UpdateClassGrade)
-	protected.DELETE("/:id", middleware.RoleMiddleware("admin"), classGradeController.DeleteClassGrade)
+	protected.GET("", middleware.RequirePermission("classes:read"), classGradeController.GetAllClassGrades)
+	protected.GET("/levels", middleware.RequirePermission("classes:read"), classGradeController.GetLevels)
+	protected.GET("/:id", middleware.RequirePermission("classes:read"), classGradeController.GetClassGradeByID)
+	protected.POST("", middleware.RequirePermission("classes:write"), classGradeController.CreateClassGrade)
+	protected.PUT("/:id", middleware.RequirePermission("classes:write"), classGradeController.UpdateClassGrade)
+	protected.DELETE("/:id", middleware.RequirePermission("classes:write"), classGradeController.DeleteClassGrade)
 		
 		// classGradeGroup.GET("/", classGradeController.GetAllClassGrades)
 		// classGradeGroup.GET("/:id", classGradeController.GetClassGradeByID)
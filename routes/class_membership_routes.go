@@ -0,0 +1,35 @@
+// routes/class_membership_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/class_membership"
+	"crm-go/middleware"
+	"crm-go/services/class_membership"
+)
+
+func ClassMembershipRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	membershipService := services.NewClassMembershipService(db)
+	membershipHandler := controllers.NewClassMembershipHandler(membershipService)
+
+	membershipGroup := router.Group("/api/class-memberships")
+	membershipGroup.Use(middleware.AuthMiddleware())
+	{
+		// Class list and placement history
+		membershipGroup.GET("/arm/:arm_id", middleware.RequirePermission("classes:read"), membershipHandler.GetClassList)
+		membershipGroup.GET("/student/:student_id", middleware.RequirePermission("classes:read", middleware.ResourceParam("student", "student_id")), membershipHandler.GetStudentMemberships)
+
+		// Place students
+		membershipGroup.POST("", middleware.RequirePermission("classes:write"), membershipHandler.PlaceStudent)
+		membershipGroup.POST("/bulk", middleware.RequirePermission("classes:write"), membershipHandler.BulkPlaceStudents)
+
+		// Balance arms of a grade
+		membershipGroup.POST("/balance", middleware.RequirePermission("classes:write"), membershipHandler.BalanceArms)
+
+		// Transfer and withdraw
+		membershipGroup.PUT("/:id/transfer", middleware.RequirePermission("classes:write"), membershipHandler.TransferStudent)
+		membershipGroup.PUT("/:id/withdraw", middleware.RequirePermission("classes:write"), membershipHandler.WithdrawStudent)
+	}
+}
@@ -11,8 +11,8 @@ func CourseCategoryRoutes(r *gin.Engine) {
         // Protected routes
         protected := r.Group("/api")
         protected.Use(middleware.AuthMiddleware())
-        protected.POST("/category-courses", middleware.RoleMiddleware("admin"), controllers.CreateCourseCategory)
-        protected.DELETE("/category-courses/:id", middleware.RoleMiddleware("admin"), controllers.DeleteCourseCategory)
+        protected.POST("/category-courses", middleware.RequirePermission("courses:write"), controllers.CreateCourseCategory)
+        protected.DELETE("/category-courses/:id", middleware.RequirePermission("courses:write"), controllers.DeleteCourseCategory)
 
     }
 }
\ No newline at end of file
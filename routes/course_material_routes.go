@@ -8,17 +8,19 @@ import (
 
 func CourseMaterialRoutes(r *gin.Engine) {
 	{
+		// Public listings; file links are only shown to viewers who can open the course
 		courseMaterials := r.Group("/course-materials")
+		courseMaterials.Use(middleware.OptionalAuthMiddleware())
 		courseMaterials.GET("/", courseMaterialController.GetCourseMaterials)
 		courseMaterials.GET("/:id", courseMaterialController.GetCourseMaterialByID)
 
 		// Protected routes
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
-		protected.POST("/course-materials", middleware.RoleMiddleware("admin"), courseMaterialController.CreateCourseMaterial)
-		protected.PUT("/course-materials/:id", middleware.RoleMiddleware("admin"), courseMaterialController.UpdateCourseMaterial)
-		protected.DELETE("/course-materials/:id", middleware.RoleMiddleware("admin"), courseMaterialController.DeleteCourseMaterial)
-		protected.POST("/deleted-records/:id/restore", middleware.RoleMiddleware("admin"), courseMaterialController.RestoreCourseMaterial)
+		protected.POST("/course-materials", middleware.RequirePermission("content:write"), courseMaterialController.CreateCourseMaterial)
+		protected.PUT("/course-materials/:id", middleware.RequirePermission("content:write"), courseMaterialController.UpdateCourseMaterial)
+		protected.DELETE("/course-materials/:id", middleware.RequirePermission("content:write"), courseMaterialController.DeleteCourseMaterial)
+		protected.POST("/deleted-records/:id/restore", middleware.RequirePermission("content:write"), courseMaterialController.RestoreCourseMaterial)
 
 	}
 }
@@ -12,8 +12,8 @@ func CourseProductRoutes(r *gin.Engine) {
 		// Protected routes
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
-		protected.POST("/course-products", middleware.RoleMiddleware("admin"), controllers.CreateCourseProduct)
-		protected.DELETE("/course-products/:id", middleware.RoleMiddleware("admin"), controllers.DeleteCourseProduct)
+		protected.POST("/course-products", middleware.RequirePermission("products:write"), controllers.CreateCourseProduct)
+		protected.DELETE("/course-products/:id", middleware.RequirePermission("products:write"), controllers.DeleteCourseProduct)
 
 	}
 }
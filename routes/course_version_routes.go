@@ -0,0 +1,33 @@
+// routes/course_version_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/course_versions"
+	"crm-go/middleware"
+	"crm-go/services/course_versions"
+)
+
+func CourseVersionRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	versionService := services.NewCourseVersionService(db)
+	versionHandler := controllers.NewCourseVersionHandler(versionService)
+
+	courseGroup := router.Group("/api/courses/:id")
+	courseGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("course_versions:write"))
+	{
+		courseGroup.POST("/clone", versionHandler.CloneCourse)
+		courseGroup.POST("/versions", versionHandler.CreateVersion)
+		courseGroup.GET("/versions", versionHandler.GetVersions)
+	}
+
+	versionGroup := router.Group("/api/course-versions")
+	versionGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("course_versions:write"))
+	{
+		versionGroup.GET("/:id", versionHandler.GetVersionByID)
+		versionGroup.POST("/:id/submit", versionHandler.SubmitVersion)
+		versionGroup.POST("/:id/discard", versionHandler.DiscardVersion)
+		versionGroup.POST("/:id/publish", middleware.RequirePermission("course_versions:publish"), versionHandler.PublishVersion)
+	}
+}
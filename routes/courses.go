@@ -9,16 +9,16 @@ import (
 func CourseRoutes(r *gin.Engine) {
 	{
 		courses := r.Group("/courses")
-		courses.GET("", courseController.GetCourses)
-		courses.GET("/:id", courseController.GetCourseByID)
+		courses.GET("", middleware.OptionalAuthMiddleware(), courseController.GetCourses)
+		courses.GET("/:id", middleware.OptionalAuthMiddleware(), courseController.GetCourseByID)
 		courses.GET("/:id/products", courseController.GetProductsWithRalatedCourseID)
 
 		// Protected routes
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
-		protected.POST("/courses", middleware.RoleMiddleware("admin"), courseController.CreateCourse)
-		protected.PUT("/courses/:id", middleware.RoleMiddleware("admin"), courseController.UpdateCourse)
-		protected.DELETE("/courses/:id", middleware.RoleMiddleware("admin"), courseController.DeleteCourse)
+		protected.POST("/courses", middleware.RequirePermission("courses:write"), courseController.CreateCourse)
+		protected.PUT("/courses/:id", middleware.RequirePermission("courses:write", middleware.ResourceParam("course", "id")), courseController.UpdateCourse)
+		protected.DELETE("/courses/:id", middleware.RequirePermission("courses:write", middleware.ResourceParam("course", "id")), courseController.DeleteCourse)
 
 	}
 }
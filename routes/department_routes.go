@@ -18,24 +18,24 @@ func DepartmentRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	departmentGroup.Use(middleware.AuthMiddleware()) // Your auth middleware
 	{
 		// 1. Create department
-		departmentGroup.POST("", handler.CreateDepartment)
+		departmentGroup.POST("", middleware.RequirePermission("departments:write"), handler.CreateDepartment)
 
 		// 2. Get all departments with pagination
-		departmentGroup.GET("", handler.GetAllDepartments)
+		departmentGroup.GET("", middleware.RequirePermission("departments:read"), handler.GetAllDepartments)
 
 		// 3. Get department with subjects
-		departmentGroup.GET("/:id/subjects", handler.GetDepartmentWithSubjects)
+		departmentGroup.GET("/:id/subjects", middleware.RequirePermission("departments:read"), handler.GetDepartmentWithSubjects)
 
 		// 4. Get department with head and subjects
-		departmentGroup.GET("/:id/head-subjects", handler.GetDepartmentWithHeadAndSubjects)
+		departmentGroup.GET("/:id/head-subjects", middleware.RequirePermission("departments:read"), handler.GetDepartmentWithHeadAndSubjects)
 
 		// 5. Get department by ID with all details
-		departmentGroup.GET("/:id", handler.GetDepartmentByID)
+		departmentGroup.GET("/:id", middleware.RequirePermission("departments:read"), handler.GetDepartmentByID)
 
 		// 6. Update department
-		departmentGroup.PUT("/:id", handler.UpdateDepartment)
+		departmentGroup.PUT("/:id", middleware.RequirePermission("departments:write", middleware.ResourceParam("department", "id")), handler.UpdateDepartment)
 
 		// 7. Delete department
-		departmentGroup.DELETE("/:id", handler.DeleteDepartment)
+		departmentGroup.DELETE("/:id", middleware.RequirePermission("departments:write", middleware.ResourceParam("department", "id")), handler.DeleteDepartment)
 	}
 }
\ No newline at end of file
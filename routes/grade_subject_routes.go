@@ -18,27 +18,27 @@ func GradeSubjectRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	gradeSubjectGroup.Use(middleware.AuthMiddleware())
 	{
 		// Create single grade-subject mapping
-		gradeSubjectGroup.POST("/grade-subjects", gradeSubjectHandler.CreateGradeSubject)
+		gradeSubjectGroup.POST("/grade-subjects", middleware.RequirePermission("subjects:write"), gradeSubjectHandler.CreateGradeSubject)
 
 		// Bulk create grade-subject mappings
-		gradeSubjectGroup.POST("/grade-subjects/bulk", gradeSubjectHandler.BulkCreateGradeSubjects)
+		gradeSubjectGroup.POST("/grade-subjects/bulk", middleware.RequirePermission("subjects:write"), gradeSubjectHandler.BulkCreateGradeSubjects)
 
 		// Get all grade-subject mappings with pagination and filters
-		gradeSubjectGroup.GET("/grade-subjects", gradeSubjectHandler.GetAllGradeSubjects)
+		gradeSubjectGroup.GET("/grade-subjects", middleware.RequirePermission("subjects:read"), gradeSubjectHandler.GetAllGradeSubjects)
 
 		// Get subjects by grade
-		gradeSubjectGroup.GET("/grade-subjects/grade/:grade_id", gradeSubjectHandler.GetSubjectsByGrade)
+		gradeSubjectGroup.GET("/grade-subjects/grade/:grade_id", middleware.RequirePermission("subjects:read"), gradeSubjectHandler.GetSubjectsByGrade)
 
 		// Get grades by subject
-		gradeSubjectGroup.GET("/grade-subjects/subject/:subject_id", gradeSubjectHandler.GetGradesBySubject)
+		gradeSubjectGroup.GET("/grade-subjects/subject/:subject_id", middleware.RequirePermission("subjects:read"), gradeSubjectHandler.GetGradesBySubject)
 
 		// Get grade-subject mapping by ID
-		gradeSubjectGroup.GET("/grade-subjects/:id", gradeSubjectHandler.GetGradeSubjectByID)
+		gradeSubjectGroup.GET("/grade-subjects/:id", middleware.RequirePermission("subjects:read"), gradeSubjectHandler.GetGradeSubjectByID)
 
 		// Update grade-subject mapping
-		gradeSubjectGroup.PUT("/grade-subjects/:id", gradeSubjectHandler.UpdateGradeSubject)
+		gradeSubjectGroup.PUT("/grade-subjects/:id", middleware.RequirePermission("subjects:write"), gradeSubjectHandler.UpdateGradeSubject)
 
 		// Delete grade-subject mapping
-		gradeSubjectGroup.DELETE("/grade-subjects/:id", gradeSubjectHandler.DeleteGradeSubject)
+		gradeSubjectGroup.DELETE("/grade-subjects/:id", middleware.RequirePermission("subjects:write"), gradeSubjectHandler.DeleteGradeSubject)
 	}
 }
\ No newline at end of file
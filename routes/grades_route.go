@@ -19,17 +19,19 @@ func GradeRoutes(r *gin.Engine, db *gorm.DB) {
 	gradeController := controllers.NewGradeController(db, gradeService, activityService)
 
 	grades := r.Group("/grades")
-		grades.GET("/", gradeController.GetAllGrades)
-		grades.GET("/:id", gradeController.GetGradeStats)
-		grades.GET("/student/:student_id/grades", gradeController.GetStudentGrades)
-		grades.GET("/courses/:course_id/grades", gradeController.GetCourseGrades)
+		grades.Use(middleware.AuthMiddleware())
+
+		grades.GET("/", middleware.RequirePermission("grades:read"), gradeController.GetAllGrades)
+		grades.GET("/:id", middleware.RequirePermission("grades:read", middleware.ResourceParam("grade", "id")), gradeController.GetGradeStats)
+		grades.GET("/student/:student_id/grades", middleware.RequirePermission("grades:read", middleware.ResourceParam("student", "student_id")), gradeController.GetStudentGrades)
+		grades.GET("/courses/:course_id/grades", middleware.RequirePermission("grades:read", middleware.ResourceParam("course", "course_id")), gradeController.GetCourseGrades)
 
 	// Protected routes
 	protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
 
-		protected.POST("/grades", middleware.RoleMiddleware("admin"), gradeController.CreateGrade)
+		protected.POST("/grades", middleware.RequirePermission("grades:write", middleware.ResourceBody("course", "course_id")), gradeController.CreateGrade)
 
 		// New update routes
-		protected.PUT("/grades/:id", middleware.RoleMiddleware("admin"), gradeController.UpdateGrade)
+		protected.PUT("/grades/:id", middleware.RequirePermission("grades:write", middleware.ResourceParam("grade", "id"), middleware.ResourceBody("course", "course_id")), gradeController.UpdateGrade)
 }
@@ -0,0 +1,32 @@
+// routes/guardian_portal_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/guardian_portal"
+	"crm-go/middleware"
+	"crm-go/services/guardian_portal"
+)
+
+func GuardianPortalRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	portalService := services.NewGuardianPortalService(db)
+	portalHandler := controllers.NewGuardianPortalHandler(portalService)
+
+	portalGroup := router.Group("/api/guardian-portal")
+	portalGroup.Use(middleware.AuthMiddleware())
+	portalGroup.Use(middleware.WardAccessMiddleware())
+	{
+		// Linked students and school-wide notices
+		portalGroup.GET("/wards", portalHandler.GetWards)
+		portalGroup.GET("/announcements", portalHandler.GetAnnouncements)
+
+		// Per-ward views, only for linked students
+		portalGroup.GET("/wards/:student_id/grades", portalHandler.GetWardGrades)
+		portalGroup.GET("/wards/:student_id/report-card", portalHandler.GetWardReportCard)
+		portalGroup.GET("/wards/:student_id/attendance", portalHandler.GetWardAttendance)
+		portalGroup.GET("/wards/:student_id/assignments", portalHandler.GetWardAssignments)
+		portalGroup.GET("/wards/:student_id/fees", portalHandler.GetWardFees)
+	}
+}
@@ -17,21 +17,21 @@ func GuardianRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	guardianGroup.Use(middleware.AuthMiddleware())
 	{
 		// Create guardian
-		guardianGroup.POST("/guardians", guardianHandler.CreateGuardian)
+		guardianGroup.POST("/guardians", middleware.RequirePermission("guardians:write"), guardianHandler.CreateGuardian)
 
 		// Get all guardians with pagination and filters
-		guardianGroup.GET("/guardians", guardianHandler.GetAllGuardians)
+		guardianGroup.GET("/guardians", middleware.RequirePermission("guardians:read"), guardianHandler.GetAllGuardians)
 
 		// Get guardians by student
-		guardianGroup.GET("/guardians/student/:student_id", guardianHandler.GetGuardiansByStudent)
+		guardianGroup.GET("/guardians/student/:student_id", middleware.RequirePermission("guardians:read"), guardianHandler.GetGuardiansByStudent)
 
 		// Get guardian by ID
-		guardianGroup.GET("/guardians/:id", guardianHandler.GetGuardianByID)
+		guardianGroup.GET("/guardians/:id", middleware.RequirePermission("guardians:read"), guardianHandler.GetGuardianByID)
 
 		// Update guardian
-		guardianGroup.PUT("/guardians/:id", guardianHandler.UpdateGuardian)
+		guardianGroup.PUT("/guardians/:id", middleware.RequirePermission("guardians:write"), guardianHandler.UpdateGuardian)
 
 		// Delete guardian
-		guardianGroup.DELETE("/guardians/:id", guardianHandler.DeleteGuardian)
+		guardianGroup.DELETE("/guardians/:id", middleware.RequirePermission("guardians:write"), guardianHandler.DeleteGuardian)
 	}
 }
\ No newline at end of file
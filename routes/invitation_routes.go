@@ -0,0 +1,32 @@
+// routes/invitation_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/invitations"
+	"crm-go/middleware"
+	"crm-go/services/invitations"
+)
+
+func InvitationRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	invitationService := services.NewInvitationService(db)
+	invitationHandler := controllers.NewInvitationHandler(invitationService)
+
+	// The accept page is public; the token in the link is the credential
+	publicGroup := router.Group("/api/invitations")
+	{
+		publicGroup.GET("/:token", invitationHandler.GetInvitation)
+		publicGroup.POST("/accept", invitationHandler.AcceptInvitation)
+	}
+
+	adminGroup := router.Group("/api/admin/invitations")
+	adminGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("invitations:manage"))
+	{
+		adminGroup.POST("", invitationHandler.CreateInvitation)
+		adminGroup.GET("", invitationHandler.GetInvitations)
+		adminGroup.POST("/:id/resend", invitationHandler.ResendInvitation)
+		adminGroup.DELETE("/:id", invitationHandler.RevokeInvitation)
+	}
+}
@@ -13,7 +13,9 @@ import (
 func LessonRoutes(r *gin.Engine, db *gorm.DB) {
 	activitySvc := activity.NewService(db)
 
+	// Public outlines; signed-in viewers also see which lessons they can open
 	lessons := r.Group("/lessons")
+	lessons.Use(middleware.OptionalAuthMiddleware())
 
 	{
 		createLessonSvc := services.NewCreateLessonService(db)
@@ -35,9 +37,9 @@ func LessonRoutes(r *gin.Engine, db *gorm.DB) {
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
 
-		protected.POST("/lessons", middleware.RoleMiddleware("admin"), lessonCtrl.CreateLesson)
-		protected.PUT("/lessons/:id", middleware.RoleMiddleware("admin"), lessonCtrl.UpdateLesson)
-		// protected.DELETE("/lessons/:id", middleware.RoleMiddleware("admin"), lessonController.DeleteLesson)
+		protected.POST("/lessons", middleware.RequirePermission("content:write"), lessonCtrl.CreateLesson)
+		protected.PUT("/lessons/:id", middleware.RequirePermission("content:write"), lessonCtrl.UpdateLesson)
+		// protected.DELETE("/lessons/:id", middleware.RequirePermission("content:write"), lessonController.DeleteLesson)
 
 	}
 }
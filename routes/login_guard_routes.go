@@ -0,0 +1,23 @@
+// routes/login_guard_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/login_guard"
+	"crm-go/middleware"
+	"crm-go/services/login_guard"
+)
+
+func LoginGuardRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	loginGuardService := services.NewLoginGuardService(db)
+	loginGuardHandler := controllers.NewLoginGuardHandler(loginGuardService)
+
+	adminGroup := router.Group("/api/admin/users")
+	adminGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("security:manage"))
+	{
+		adminGroup.GET("/:id/login-security", loginGuardHandler.GetLoginSecurity)
+		adminGroup.POST("/:id/unlock", loginGuardHandler.UnlockUser)
+	}
+}
@@ -0,0 +1,29 @@
+// routes/mfa_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/mfa"
+	"crm-go/middleware"
+	"crm-go/services/mfa"
+)
+
+func MFARoutes(router *gin.RouterGroup, db *gorm.DB) {
+	mfaService := services.NewMFAService(db)
+	mfaHandler := controllers.NewMFAHandler(mfaService)
+
+	mfaGroup := router.Group("/api/me/mfa")
+	mfaGroup.Use(middleware.AuthMiddleware())
+	{
+		mfaGroup.GET("", mfaHandler.GetMFAStatus)
+		mfaGroup.POST("/totp/setup", mfaHandler.SetupTOTP)
+		mfaGroup.POST("/totp/confirm", mfaHandler.ConfirmTOTP)
+		mfaGroup.DELETE("/totp", mfaHandler.DisableTOTP)
+		mfaGroup.PUT("/email", mfaHandler.SetEmailOTP)
+		mfaGroup.POST("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+		mfaGroup.GET("/trusted-devices", mfaHandler.GetTrustedDevices)
+		mfaGroup.DELETE("/trusted-devices/:id", mfaHandler.RevokeTrustedDevice)
+	}
+}
@@ -0,0 +1,26 @@
+// routes/module_release_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/module_release"
+	"crm-go/middleware"
+	"crm-go/services/module_release"
+)
+
+func ModuleReleaseRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	releaseService := services.NewModuleReleaseService(db)
+	releaseHandler := controllers.NewModuleReleaseHandler(releaseService)
+
+	moduleGroup := router.Group("/api/modules/:id")
+	moduleGroup.Use(middleware.AuthMiddleware())
+	{
+		moduleGroup.POST("/complete", middleware.RequirePermission("modules:complete"), releaseHandler.CompleteModule)
+
+		moduleGroup.PUT("/release-rule", middleware.RequirePermission("content:write"), releaseHandler.SetReleaseRule)
+		moduleGroup.GET("/release-rule", middleware.RequirePermission("content:write"), releaseHandler.GetReleaseRule)
+		moduleGroup.DELETE("/release-rule", middleware.RequirePermission("content:write"), releaseHandler.DeleteReleaseRule)
+	}
+}
@@ -8,13 +8,14 @@ import (
 )
 
 func ModuleRoutes(r *gin.Engine) {
-	r.GET("/modules", modules.GetAllModules)
-	r.GET("/modules/:id", modules.GetModuleByID)
+	// Public outlines; signed-in viewers also get the content they can open
+	r.GET("/modules", middleware.OptionalAuthMiddleware(), modules.GetAllModules)
+	r.GET("/modules/:id", middleware.OptionalAuthMiddleware(), modules.GetModuleByID)
 
 	// Protected routes
 	protected := r.Group("/api")
 	protected.Use(middleware.AuthMiddleware())
-	protected.POST("/modules", middleware.RoleMiddleware("admin"), modules.CreateModule)
-	protected.PUT("/modules/:id", middleware.RoleMiddleware("admin"), modules.UpdateModule)
-	protected.DELETE("/modules/:id", middleware.RoleMiddleware("admin"), modules.DeleteModule)
+	protected.POST("/modules", middleware.RequirePermission("content:write"), modules.CreateModule)
+	protected.PUT("/modules/:id", middleware.RequirePermission("content:write"), modules.UpdateModule)
+	protected.DELETE("/modules/:id", middleware.RequirePermission("content:write"), modules.DeleteModule)
 }
@@ -0,0 +1,43 @@
+// routes/order_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/orders"
+	"crm-go/middleware"
+	"crm-go/services/activity"
+	"crm-go/services/orders"
+)
+
+func OrderRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	orderService := services.NewOrderService(db, activity.NewService(db))
+	orderHandler := controllers.NewOrderHandler(orderService)
+
+	cartGroup := router.Group("/api/cart")
+	cartGroup.Use(middleware.AuthMiddleware())
+	{
+		cartGroup.GET("", orderHandler.GetCart)
+		cartGroup.DELETE("", orderHandler.ClearCart)
+		cartGroup.POST("/items", orderHandler.AddCartItem)
+		cartGroup.PUT("/items/:product_id", orderHandler.UpdateCartItem)
+		cartGroup.DELETE("/items/:product_id", orderHandler.RemoveCartItem)
+	}
+
+	orderGroup := router.Group("/api/orders")
+	orderGroup.Use(middleware.AuthMiddleware())
+	{
+		// Customers place, pay and cancel their own orders
+		orderGroup.POST("/checkout", orderHandler.Checkout)
+		orderGroup.GET("/my", orderHandler.GetMyOrders)
+		orderGroup.GET("/:id", orderHandler.GetOrderByID)
+		orderGroup.POST("/:id/pay", orderHandler.PayOrder)
+		orderGroup.POST("/:id/cancel", orderHandler.CancelOrder)
+
+		// Admin only
+		orderGroup.GET("", middleware.RequirePermission("orders:manage"), orderHandler.GetOrders)
+		orderGroup.POST("/:id/payments", middleware.RequirePermission("orders:manage"), orderHandler.RecordPayment)
+		orderGroup.PUT("/:id/status", middleware.RequirePermission("orders:manage"), orderHandler.UpdateOrderStatus)
+	}
+}
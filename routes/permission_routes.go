@@ -0,0 +1,35 @@
+// routes/permission_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/permissions"
+	"crm-go/middleware"
+	"crm-go/services/permissions"
+)
+
+func PermissionRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	permissionService := services.NewPermissionService(db)
+	permissionHandler := controllers.NewPermissionHandler(permissionService)
+
+	router.GET("/api/permissions/me", middleware.AuthMiddleware(), permissionHandler.GetMyPermissions)
+
+	adminGroup := router.Group("/api/admin")
+	adminGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("roles:manage"))
+	{
+		adminGroup.GET("/permissions", permissionHandler.GetPermissionCatalog)
+
+		adminGroup.GET("/roles", permissionHandler.GetRoles)
+		adminGroup.POST("/roles", permissionHandler.CreateRole)
+		adminGroup.GET("/roles/:id", permissionHandler.GetRole)
+		adminGroup.PUT("/roles/:id", permissionHandler.UpdateRole)
+		adminGroup.DELETE("/roles/:id", permissionHandler.DeleteRole)
+
+		adminGroup.GET("/users/:id/roles", permissionHandler.GetUserRoles)
+		adminGroup.PUT("/users/:id/role", permissionHandler.SetPrimaryRole)
+		adminGroup.POST("/users/:id/roles", permissionHandler.AssignRole)
+		adminGroup.DELETE("/users/:id/roles/:assignment_id", permissionHandler.RemoveAssignment)
+	}
+}
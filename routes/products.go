@@ -17,9 +17,9 @@ func ProductRoutes(r *gin.Engine) {
 		// Protected routes
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
-		protected.POST("/products", middleware.RoleMiddleware("admin"), controllers.CreateProduct)
-		protected.PUT("/products/:id", middleware.RoleMiddleware("admin"), controllers.UpdateProduct)
-		protected.DELETE("/products/:id", middleware.RoleMiddleware("admin"), controllers.DeleteProduct)
+		protected.POST("/products", middleware.RequirePermission("products:write"), controllers.CreateProduct)
+		protected.PUT("/products/:id", middleware.RequirePermission("products:write"), controllers.UpdateProduct)
+		protected.DELETE("/products/:id", middleware.RequirePermission("products:write"), controllers.DeleteProduct)
 
 	}
 }
@@ -0,0 +1,34 @@
+// routes/promotion_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/promotion"
+	"crm-go/middleware"
+	"crm-go/services/promotion"
+)
+
+func PromotionRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	promotionService := services.NewPromotionService(db)
+	promotionHandler := controllers.NewPromotionHandler(promotionService)
+
+	promotionGroup := router.Group("/api/promotions")
+	promotionGroup.Use(middleware.AuthMiddleware())
+	promotionGroup.Use(middleware.RequirePermission("promotions:manage"))
+	{
+		// Clone class structure into a new session
+		promotionGroup.POST("/rollover", promotionHandler.RolloverSession)
+
+		// Dry run of the promotion rules
+		promotionGroup.POST("/preview", promotionHandler.PreviewPromotion)
+
+		// Execute promotion
+		promotionGroup.POST("/execute", promotionHandler.ExecutePromotion)
+
+		// Audit trail of executed promotions
+		promotionGroup.GET("/runs", promotionHandler.GetPromotionRuns)
+		promotionGroup.GET("/runs/:id", promotionHandler.GetPromotionRunByID)
+	}
+}
@@ -0,0 +1,34 @@
+// routes/publishing_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/publishing"
+	"crm-go/middleware"
+	"crm-go/services/publishing"
+)
+
+func PublishingRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	publishingService := services.NewPublishingService(db)
+	publishingHandler := controllers.NewPublishingHandler(publishingService)
+
+	publishingGroup := router.Group("/api/publishing")
+	publishingGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("publishing:submit"))
+	{
+		publishingGroup.GET("/queue", publishingHandler.GetQueue)
+
+		// item_type is courses or modules
+		publishingGroup.GET("/:item_type/:id", publishingHandler.GetStatus)
+		publishingGroup.GET("/:item_type/:id/checks", publishingHandler.GetChecks)
+		publishingGroup.POST("/:item_type/:id/submit", publishingHandler.Submit)
+		publishingGroup.POST("/:item_type/:id/request-changes", publishingHandler.RequestChanges)
+		publishingGroup.POST("/:item_type/:id/publish", publishingHandler.Publish)
+		publishingGroup.GET("/:item_type/:id/comments", publishingHandler.GetComments)
+		publishingGroup.POST("/:item_type/:id/comments", publishingHandler.AddComment)
+
+		publishingGroup.PUT("/:item_type/:id/reviewer", middleware.RequirePermission("publishing:manage"), publishingHandler.AssignReviewer)
+		publishingGroup.POST("/:item_type/:id/archive", middleware.RequirePermission("publishing:manage"), publishingHandler.Archive)
+	}
+}
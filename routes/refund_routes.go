@@ -0,0 +1,39 @@
+// routes/refund_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/refunds"
+	"crm-go/middleware"
+	"crm-go/services/activity"
+	"crm-go/services/refunds"
+)
+
+func RefundRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	refundService := services.NewRefundService(db, activity.NewService(db))
+	refundHandler := controllers.NewRefundHandler(refundService)
+
+	refundGroup := router.Group("/api/refunds")
+	refundGroup.Use(middleware.AuthMiddleware())
+	{
+		// Students request refunds for their own enrollments
+		studentGroup := refundGroup.Group("")
+		studentGroup.Use(middleware.RequirePermission("refunds:request"))
+		{
+			studentGroup.GET("/quote/:enrollment_id", refundHandler.QuoteRefund)
+			studentGroup.POST("", refundHandler.RequestRefund)
+			studentGroup.GET("", refundHandler.GetRefunds)
+			studentGroup.GET("/:id", refundHandler.GetRefundByID)
+		}
+
+		// Admin review
+		adminGroup := refundGroup.Group("")
+		adminGroup.Use(middleware.RequirePermission("refunds:manage"))
+		{
+			adminGroup.POST("/:id/approve", refundHandler.ApproveRefund)
+			adminGroup.POST("/:id/reject", refundHandler.RejectRefund)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// routes/reorder_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/reorder"
+	"crm-go/middleware"
+	"crm-go/services/reorder"
+)
+
+func ReorderRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	reorderService := services.NewReorderService(db)
+	reorderHandler := controllers.NewReorderHandler(reorderService)
+
+	reorderGroup := router.Group("/api")
+	reorderGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("content:reorder"))
+	{
+		reorderGroup.PUT("/courses/:id/modules/order", reorderHandler.ReorderModules)
+		reorderGroup.PUT("/modules/:id/lessons/order", reorderHandler.ReorderLessons)
+		reorderGroup.PUT("/lessons/:id/topics/order", reorderHandler.ReorderTopics)
+
+		reorderGroup.POST("/lessons/:id/move", reorderHandler.MoveLesson)
+		reorderGroup.POST("/topics/:id/move", reorderHandler.MoveTopic)
+	}
+}
@@ -0,0 +1,30 @@
+// routes/session_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/sessions"
+	"crm-go/middleware"
+	"crm-go/services/sessions"
+)
+
+func SessionRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	sessionService := services.NewSessionService(db)
+	sessionHandler := controllers.NewSessionHandler(sessionService)
+
+	meGroup := router.Group("/api/me/sessions")
+	meGroup.Use(middleware.AuthMiddleware())
+	{
+		meGroup.GET("", sessionHandler.GetMySessions)
+		meGroup.DELETE("", sessionHandler.RevokeOtherSessions)
+		meGroup.DELETE("/:id", sessionHandler.RevokeMySession)
+	}
+
+	adminGroup := router.Group("/api/admin/users")
+	adminGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("security:manage"))
+	{
+		adminGroup.POST("/:id/logout", sessionHandler.ForceLogoutUser)
+	}
+}
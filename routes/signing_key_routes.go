@@ -0,0 +1,27 @@
+// routes/signing_key_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/signing_keys"
+	"crm-go/middleware"
+	"crm-go/services/signing_keys"
+)
+
+func SigningKeyRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	keyService := services.NewSigningKeyService(db)
+	keyHandler := controllers.NewSigningKeyHandler(keyService)
+
+	// Public so other services can verify our tokens
+	router.GET("/.well-known/jwks.json", keyHandler.JWKS)
+
+	keyGroup := router.Group("/api/admin/signing-keys")
+	keyGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("security:manage"))
+	{
+		keyGroup.GET("", keyHandler.GetSigningKeys)
+		keyGroup.POST("/rotate", keyHandler.RotateSigningKey)
+		keyGroup.POST("/:id/retire", keyHandler.RetireSigningKey)
+	}
+}
@@ -0,0 +1,27 @@
+// routes/sso_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/sso"
+	"crm-go/middleware"
+	"crm-go/services/sso"
+)
+
+// SSORoutes registers identity provider management; the sign-in flow itself is under /auth/sso
+func SSORoutes(router *gin.RouterGroup, db *gorm.DB) {
+	ssoService := services.NewSSOService(db)
+	ssoHandler := controllers.NewSSOHandler(ssoService)
+
+	adminGroup := router.Group("/api/admin/sso/providers")
+	adminGroup.Use(middleware.AuthMiddleware(), middleware.RequirePermission("security:manage"))
+	{
+		adminGroup.POST("", ssoHandler.CreateProvider)
+		adminGroup.GET("", ssoHandler.GetProviders)
+		adminGroup.GET("/:id", ssoHandler.GetProvider)
+		adminGroup.PUT("/:id", ssoHandler.UpdateProvider)
+		adminGroup.DELETE("/:id", ssoHandler.DeleteProvider)
+	}
+}
@@ -17,19 +17,19 @@ func SubjectRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	subjectGroup.Use(middleware.AuthMiddleware()) // Your auth middleware
 	{
 		// 1. Create subject
-		subjectGroup.POST("", handler.CreateSubject)
+		subjectGroup.POST("", middleware.RequirePermission("subjects:write", middleware.ResourceBody("department", "department_id")), handler.CreateSubject)
 
 		// 2. Get all subjects with pagination
-		subjectGroup.GET("", handler.GetAllSubjects)
+		subjectGroup.GET("", middleware.RequirePermission("subjects:read"), handler.GetAllSubjects)
 
 		// 3. Get subject by ID with department and head
-		subjectGroup.GET("/department/head-of-department/:id", handler.GetSubjectWithDepartmentAndHead)
+		subjectGroup.GET("/department/head-of-department/:id", middleware.RequirePermission("subjects:read"), handler.GetSubjectWithDepartmentAndHead)
 
 		// 4. Update subject
-		subjectGroup.PUT("/:id", handler.UpdateSubject)
+		subjectGroup.PUT("/:id", middleware.RequirePermission("subjects:write", middleware.ResourceParam("subject", "id"), middleware.ResourceBody("department", "department_id")), handler.UpdateSubject)
 
 		// 5. Delete subject
-		subjectGroup.DELETE("/:id", handler.DeleteSubject)
+		subjectGroup.DELETE("/:id", middleware.RequirePermission("subjects:write", middleware.ResourceParam("subject", "id")), handler.DeleteSubject)
 	}
 }
 
@@ -0,0 +1,50 @@
+// routes/subscription_routes.go
+package routes
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"crm-go/controllers/subscriptions"
+	"crm-go/middleware"
+	"crm-go/services/activity"
+	"crm-go/services/subscriptions"
+)
+
+func SubscriptionRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	subscriptionService := services.NewSubscriptionService(db, activity.NewService(db))
+	subscriptionHandler := controllers.NewSubscriptionHandler(subscriptionService)
+
+	// Renewals, retries of failed payments and period-end cancellations run in the background
+	subscriptionService.StartRenewalWorker(time.Hour)
+
+	planGroup := router.Group("/api/subscription-plans")
+	planGroup.Use(middleware.AuthMiddleware())
+	{
+		planGroup.GET("", subscriptionHandler.GetPlans)
+		planGroup.GET("/:id", subscriptionHandler.GetPlanByID)
+
+		// Admin only
+		planGroup.POST("", middleware.RequirePermission("subscriptions:manage"), subscriptionHandler.CreatePlan)
+		planGroup.PUT("/:id", middleware.RequirePermission("subscriptions:manage"), subscriptionHandler.UpdatePlan)
+		planGroup.DELETE("/:id", middleware.RequirePermission("subscriptions:manage"), subscriptionHandler.DeletePlan)
+	}
+
+	subscriptionGroup := router.Group("/api/subscriptions")
+	subscriptionGroup.Use(middleware.AuthMiddleware())
+	{
+		// Subscribers manage their own subscriptions
+		subscriptionGroup.POST("", middleware.RequirePermission("subscriptions:subscribe"), subscriptionHandler.Subscribe)
+		subscriptionGroup.GET("/my", subscriptionHandler.GetMySubscriptions)
+		subscriptionGroup.GET("/:id", subscriptionHandler.GetSubscriptionByID)
+		subscriptionGroup.POST("/:id/cancel", subscriptionHandler.CancelSubscription)
+		subscriptionGroup.POST("/:id/resume", subscriptionHandler.ResumeSubscription)
+
+		// Admin only
+		subscriptionGroup.GET("", middleware.RequirePermission("subscriptions:manage"), subscriptionHandler.GetSubscriptions)
+		subscriptionGroup.POST("/:id/payments", middleware.RequirePermission("subscriptions:manage"), subscriptionHandler.RecordPayment)
+		subscriptionGroup.POST("/renewals/run", middleware.RequirePermission("subscriptions:manage"), subscriptionHandler.RunRenewals)
+	}
+}
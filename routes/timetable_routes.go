@@ -0,0 +1,53 @@
+// routes/timetable_routes.go
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	allocationControllers "crm-go/controllers/teacher_allocation"
+	timetableControllers "crm-go/controllers/timetable"
+	"crm-go/middleware"
+	allocationServices "crm-go/services/teacher_allocation"
+	timetableServices "crm-go/services/timetable"
+)
+
+func TimetableRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	allocationService := allocationServices.NewTeacherAllocationService(db)
+	allocationHandler := allocationControllers.NewTeacherAllocationHandler(allocationService)
+
+	timetableService := timetableServices.NewTimetableService(db)
+	timetableHandler := timetableControllers.NewTimetableHandler(timetableService)
+
+	allocationGroup := router.Group("/api/teacher-allocations")
+	allocationGroup.Use(middleware.AuthMiddleware())
+	{
+		// Workload summaries
+		allocationGroup.GET("/workload", allocationHandler.GetWorkloads)
+		allocationGroup.GET("/teacher/:teacher_id/workload", allocationHandler.GetTeacherWorkload)
+
+		// Allocations
+		allocationGroup.GET("", allocationHandler.GetAllAllocations)
+		allocationGroup.GET("/:id", allocationHandler.GetAllocationByID)
+		allocationGroup.POST("", middleware.RequirePermission("timetable:write"), allocationHandler.CreateAllocation)
+		allocationGroup.PUT("/:id", middleware.RequirePermission("timetable:write"), allocationHandler.UpdateAllocation)
+		allocationGroup.DELETE("/:id", middleware.RequirePermission("timetable:write"), allocationHandler.DeleteAllocation)
+	}
+
+	timetableGroup := router.Group("/api/timetables")
+	timetableGroup.Use(middleware.AuthMiddleware())
+	{
+		// Timetable views
+		timetableGroup.GET("/arm/:arm_id", timetableHandler.GetArmTimetable)
+		timetableGroup.GET("/teacher/:teacher_id", timetableHandler.GetTeacherTimetable)
+
+		// Generation
+		timetableGroup.POST("/generate", middleware.RequirePermission("timetable:write"), timetableHandler.GenerateTimetable)
+		timetableGroup.DELETE("/arm/:arm_id", middleware.RequirePermission("timetable:write"), timetableHandler.ClearArmTimetable)
+
+		// Teacher availability
+		timetableGroup.GET("/unavailability/teacher/:teacher_id", timetableHandler.GetTeacherUnavailability)
+		timetableGroup.POST("/unavailability", middleware.RequirePermission("timetable:write"), timetableHandler.CreateUnavailability)
+		timetableGroup.DELETE("/unavailability/:id", middleware.RequirePermission("timetable:write"), timetableHandler.DeleteUnavailability)
+	}
+}
@@ -9,16 +9,18 @@ import (
 
 func TopicRoutes(r *gin.Engine) {
 	{
+		// Public outlines; signed-in viewers also get the content they can open
 		topics := r.Group("/topics")
+		topics.Use(middleware.OptionalAuthMiddleware())
 		topics.GET("/", topicController.GetAllTopics)
 		topics.GET("/:id", topicController.GetTopicByID)
 
 		// Protected routes
 		protected := r.Group("/api")
 		protected.Use(middleware.AuthMiddleware())
-		protected.POST("/topics", middleware.RoleMiddleware("admin"), topicController.CreateTopic)
-		protected.PUT("/topics/:id", middleware.RoleMiddleware("admin"), topicController.UpdateTopic)
-		protected.DELETE("/topics/:id", middleware.RoleMiddleware("admin"), topicController.DeleteTopic)
+		protected.POST("/topics", middleware.RequirePermission("content:write"), topicController.CreateTopic)
+		protected.PUT("/topics/:id", middleware.RequirePermission("content:write"), topicController.UpdateTopic)
+		protected.DELETE("/topics/:id", middleware.RequirePermission("content:write"), topicController.DeleteTopic)
 
 	}
 }
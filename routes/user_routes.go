@@ -18,12 +18,15 @@ func UserRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	userGroup.Use(middleware.AuthMiddleware())
 	{
 		// Get all users with pagination and filters
-		userGroup.GET("/users", userHandler.GetAllUsers)
+		userGroup.GET("/users", middleware.RequirePermission("users:read"), userHandler.GetAllUsers)
 
 		// Get users by role
-		userGroup.GET("/users/role/:role", userHandler.GetUsersByRole)
+		userGroup.GET("/users/role/:role", middleware.RequirePermission("users:read"), userHandler.GetUsersByRole)
+
+		// Activate or deactivate user
+		userGroup.PUT("/users/:id/status", middleware.RequirePermission("users:write"), userHandler.UpdateUserStatus)
 
 		// Delete user
-		userGroup.DELETE("/users/:id", userHandler.DeleteUser)
+		userGroup.DELETE("/users/:id", middleware.RequirePermission("users:write"), userHandler.DeleteUser)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,139 @@
+// services/access_service.go
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+	subscriptionServices "crm-go/services/subscriptions"
+)
+
+// AccessService decides whether a user may open course content
+type AccessService struct {
+	db *gorm.DB
+}
+
+func NewAccessService(db *gorm.DB) *AccessService {
+	return &AccessService{db: db}
+}
+
+// CourseAccess checks the courses:preview permission the caller resolved, then an active enrollment, then a subscription whose
+// plan bundles one of the course's products or categories
+func (s *AccessService) CourseAccess(userID uuid.UUID, canPreview bool, courseID uuid.UUID) (*dto.CourseAccessResponse, error) {
+	access := &dto.CourseAccessResponse{
+		CourseID: courseID.String(),
+		Source:   "none",
+	}
+
+	if canPreview {
+		access.Allowed = true
+		access.Source = "staff"
+		return access, nil
+	}
+	if userID == uuid.Nil {
+		return access, nil
+	}
+
+	now := time.Now()
+
+	var enrollment models.Enrollment
+	err := s.db.Where("student_id = ? AND course_id = ? AND status IN ?", userID, courseID, []string{"active", "completed"}).
+		Where("expiration_date IS NULL OR expiration_date > ?", now).
+		Where("access_level <> ? OR trial_ends_at IS NULL OR trial_ends_at > ?", "trial", now).
+		First(&enrollment).Error
+	if err == nil {
+		enrollmentID := enrollment.ID.String()
+		access.Allowed = true
+		access.Source = "enrollment"
+		access.EnrollmentID = &enrollmentID
+		access.AccessUntil = enrollment.ExpirationDate
+		if enrollment.AccessLevel == "trial" && enrollment.TrialEndsAt != nil {
+			access.AccessUntil = enrollment.TrialEndsAt
+		}
+		return access, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to check enrollment: " + err.Error())
+	}
+
+	subscription, err := s.coveringSubscription(userID, courseID, now)
+	if err != nil {
+		return nil, err
+	}
+	if subscription != nil {
+		subscriptionID := subscription.ID.String()
+		until := subscription.CurrentPeriodEnd
+		if subscription.Status == "past_due" && subscription.GraceEndsAt != nil {
+			until = *subscription.GraceEndsAt
+		}
+		access.Allowed = true
+		access.Source = "subscription"
+		access.SubscriptionID = &subscriptionID
+		access.AccessUntil = &until
+	}
+	return access, nil
+}
+
+// ModuleAccess checks whether a user may open a module; free modules are open to any
+// signed-in user, and other modules also have to pass their release rule
+func (s *AccessService) ModuleAccess(userID uuid.UUID, canPreview bool, moduleID string) (*dto.ModuleAccessResponse, error) {
+	id, err := uuid.Parse(moduleID)
+	if err != nil {
+		return nil, errors.New("invalid module ID")
+	}
+
+	var module models.Module
+	if err := s.db.Select("id, course_id, is_free").Where("id = ?", id).First(&module).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("module not found")
+		}
+		return nil, errors.New("failed to fetch module: " + err.Error())
+	}
+
+	courseAccess, err := s.CourseAccess(userID, canPreview, module.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.moduleStatus(userID, &module, courseAccess)
+	if err != nil {
+		return nil, err
+	}
+	if status.Open && !courseAccess.Allowed {
+		courseAccess.Source = "free_preview"
+	}
+	courseAccess.Allowed = status.Open
+
+	return &dto.ModuleAccessResponse{
+		ModuleID:             module.ID.String(),
+		CourseAccessResponse: *courseAccess,
+		UnlockAt:             status.UnlockAt,
+		LockReason:           status.Reason,
+	}, nil
+}
+
+// coveringSubscription finds a subscription currently granting access to a course, if any
+func (s *AccessService) coveringSubscription(userID, courseID uuid.UUID, now time.Time) (*models.Subscription, error) {
+	productIDs := s.db.Model(&models.CourseProductTable{}).Select("product_id").Where("course_id = ?", courseID)
+	categoryIDs := s.db.Model(&models.CourseCategoryTable{}).Select("category_id").Where("course_id = ?", courseID)
+	planIDs := s.db.Model(&models.SubscriptionPlanItem{}).Select("plan_id").
+		Where("(item_type = ? AND item_id IN (?)) OR (item_type = ? AND item_id IN (?))", "product", productIDs, "category", categoryIDs)
+
+	var subscriptions []models.Subscription
+	if err := s.db.Where("user_id = ? AND plan_id IN (?) AND status IN ?", userID, planIDs, []string{"trialing", "active", "past_due"}).
+		Order("current_period_end DESC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, errors.New("failed to check subscriptions: " + err.Error())
+	}
+
+	for i := range subscriptions {
+		if subscriptionServices.HasAccess(&subscriptions[i], now) {
+			return &subscriptions[i], nil
+		}
+	}
+	return nil, nil
+}
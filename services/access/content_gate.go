@@ -0,0 +1,137 @@
+// services/content_gate.go
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+)
+
+// ContentGate applies the content access policy for one viewer across many items,
+// caching course and module lookups for the length of a request:
+//   - anonymous viewers only ever see outlines
+//   - free modules are open as previews to any signed-in viewer
+//   - full content needs live course access (staff, enrollment or subscription)
+//   - modules with a release rule stay locked until their date or prerequisite is met
+type ContentGate struct {
+	service    *AccessService
+	userID     uuid.UUID
+	canPreview bool
+	courses    map[uuid.UUID]*dto.CourseAccessResponse
+	modules    map[uuid.UUID]*ModuleStatus
+}
+
+// Gate returns a content gate for a viewer; pass uuid.Nil for anonymous requests and
+// canPreview for viewers holding courses:preview
+func (s *AccessService) Gate(userID uuid.UUID, canPreview bool) *ContentGate {
+	return &ContentGate{
+		service:    s,
+		userID:     userID,
+		canPreview: canPreview,
+		courses:    make(map[uuid.UUID]*dto.CourseAccessResponse),
+		modules:    make(map[uuid.UUID]*ModuleStatus),
+	}
+}
+
+// CanOpenCourse reports whether the viewer may open content that belongs to the whole course
+func (g *ContentGate) CanOpenCourse(courseID uuid.UUID) (bool, error) {
+	if g.userID == uuid.Nil {
+		return false, nil
+	}
+	access, err := g.courseAccess(courseID)
+	if err != nil {
+		return false, err
+	}
+	return access.Allowed, nil
+}
+
+// ModuleStatus reports whether the viewer may open a module's content and, if not, why
+func (g *ContentGate) ModuleStatus(module *models.Module) (*ModuleStatus, error) {
+	if status, ok := g.modules[module.ID]; ok {
+		return status, nil
+	}
+
+	access := &dto.CourseAccessResponse{CourseID: module.CourseID.String(), Source: "none"}
+	if g.userID != uuid.Nil {
+		var err error
+		if access, err = g.courseAccess(module.CourseID); err != nil {
+			return nil, err
+		}
+	}
+
+	status, err := g.service.moduleStatus(g.userID, module, access)
+	if err != nil {
+		return nil, err
+	}
+	g.modules[module.ID] = status
+	return status, nil
+}
+
+// ModuleStatusByID is ModuleStatus for callers holding only the module's ID
+func (g *ContentGate) ModuleStatusByID(moduleID uuid.UUID) (*ModuleStatus, error) {
+	if status, ok := g.modules[moduleID]; ok {
+		return status, nil
+	}
+
+	var module models.Module
+	if err := g.service.db.Select("id, course_id, is_free").Where("id = ?", moduleID).First(&module).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status := &ModuleStatus{Reason: "not_found"}
+			g.modules[moduleID] = status
+			return status, nil
+		}
+		return nil, errors.New("failed to fetch module: " + err.Error())
+	}
+	return g.ModuleStatus(&module)
+}
+
+// CanOpenModule reports whether the viewer may open a module's content
+func (g *ContentGate) CanOpenModule(module *models.Module) (bool, error) {
+	status, err := g.ModuleStatus(module)
+	if err != nil {
+		return false, err
+	}
+	return status.Open, nil
+}
+
+// CanOpenModuleID is CanOpenModule for callers holding only the module's ID
+func (g *ContentGate) CanOpenModuleID(moduleID uuid.UUID) (bool, error) {
+	status, err := g.ModuleStatusByID(moduleID)
+	if err != nil {
+		return false, err
+	}
+	return status.Open, nil
+}
+
+// CanSeeModule reports whether a module appears to the viewer at all: staff see every
+// module, everyone else only published modules of published courses or of courses they
+// can open. The module's Course must be loaded.
+func (g *ContentGate) CanSeeModule(module *models.Module) (bool, error) {
+	if g.canPreview {
+		return true, nil
+	}
+	if module.Status != "published" {
+		return false, nil
+	}
+	if module.Course.Status == "published" {
+		return true, nil
+	}
+	return g.CanOpenCourse(module.CourseID)
+}
+
+// courseAccess returns the viewer's cached course access
+func (g *ContentGate) courseAccess(courseID uuid.UUID) (*dto.CourseAccessResponse, error) {
+	if access, ok := g.courses[courseID]; ok {
+		return access, nil
+	}
+	access, err := g.service.CourseAccess(g.userID, g.canPreview, courseID)
+	if err != nil {
+		return nil, err
+	}
+	g.courses[courseID] = access
+	return access, nil
+}
@@ -0,0 +1,121 @@
+// services/release.go
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+)
+
+// ModuleStatus is the result of applying course access and the module's release rule
+// for one viewer. Reason is empty when the module is open, otherwise one of
+// sign_in_required, not_enrolled, scheduled or prerequisite.
+type ModuleStatus struct {
+	Open     bool
+	UnlockAt *time.Time // set for scheduled modules
+	Reason   string
+}
+
+// moduleStatus decides whether a viewer with the given course access may open a module.
+// Staff and free previews skip release rules; everyone else waits for the drip schedule
+// or prerequisite the module was given.
+func (s *AccessService) moduleStatus(userID uuid.UUID, module *models.Module, access *dto.CourseAccessResponse) (*ModuleStatus, error) {
+	if userID == uuid.Nil {
+		return &ModuleStatus{Reason: "sign_in_required"}, nil
+	}
+	if access.Source == "staff" || module.IsFree {
+		return &ModuleStatus{Open: true}, nil
+	}
+	if !access.Allowed {
+		return &ModuleStatus{Reason: "not_enrolled"}, nil
+	}
+
+	var rule models.ModuleReleaseRule
+	if err := s.db.Where("module_id = ?", module.ID).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ModuleStatus{Open: true}, nil
+		}
+		return nil, errors.New("failed to fetch release rule: " + err.Error())
+	}
+
+	now := time.Now()
+	switch rule.RuleType {
+	case "after_enrollment":
+		start, err := s.accessStart(access)
+		if err != nil {
+			return nil, err
+		}
+		unlockAt := start.AddDate(0, 0, rule.DelayDays)
+		if now.Before(unlockAt) {
+			return &ModuleStatus{UnlockAt: &unlockAt, Reason: "scheduled"}, nil
+		}
+	case "fixed_date":
+		if rule.ReleaseAt != nil && now.Before(*rule.ReleaseAt) {
+			unlockAt := *rule.ReleaseAt
+			return &ModuleStatus{UnlockAt: &unlockAt, Reason: "scheduled"}, nil
+		}
+	case "prerequisite":
+		met, err := s.prerequisiteMet(userID, &rule)
+		if err != nil {
+			return nil, err
+		}
+		if !met {
+			return &ModuleStatus{Reason: "prerequisite"}, nil
+		}
+	}
+	return &ModuleStatus{Open: true}, nil
+}
+
+// accessStart is when the viewer's access to the course began, used as the drip anchor
+func (s *AccessService) accessStart(access *dto.CourseAccessResponse) (time.Time, error) {
+	if access.EnrollmentID != nil {
+		var enrollment models.Enrollment
+		if err := s.db.Select("id, enrollment_date, start_date").Where("id = ?", *access.EnrollmentID).First(&enrollment).Error; err != nil {
+			return time.Time{}, errors.New("failed to fetch enrollment: " + err.Error())
+		}
+		if enrollment.StartDate != nil {
+			return *enrollment.StartDate, nil
+		}
+		return enrollment.EnrollmentDate, nil
+	}
+	if access.SubscriptionID != nil {
+		var subscription models.Subscription
+		if err := s.db.Select("id, created_at").Where("id = ?", *access.SubscriptionID).First(&subscription).Error; err != nil {
+			return time.Time{}, errors.New("failed to fetch subscription: " + err.Error())
+		}
+		return subscription.CreatedAt, nil
+	}
+	return time.Now(), nil
+}
+
+// prerequisiteMet checks a completed prerequisite module and a passing quiz score, whichever the rule names
+func (s *AccessService) prerequisiteMet(userID uuid.UUID, rule *models.ModuleReleaseRule) (bool, error) {
+	if rule.PrerequisiteModuleID != nil {
+		var count int64
+		if err := s.db.Model(&models.ModuleCompletion{}).
+			Where("module_id = ? AND student_id = ?", *rule.PrerequisiteModuleID, userID).
+			Count(&count).Error; err != nil {
+			return false, errors.New("failed to check module completion: " + err.Error())
+		}
+		if count == 0 {
+			return false, nil
+		}
+	}
+	if rule.PrerequisiteAssignmentID != nil {
+		var count int64
+		if err := s.db.Model(&models.Grade{}).
+			Where("assignment_id = ? AND student_id = ? AND score >= ?", *rule.PrerequisiteAssignmentID, userID, rule.MinScore).
+			Count(&count).Error; err != nil {
+			return false, errors.New("failed to check quiz score: " + err.Error())
+		}
+		if count == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
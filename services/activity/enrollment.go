@@ -0,0 +1,75 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"crm-go/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EnrollmentActivity struct {
+	logger *Logger
+}
+
+func (a *EnrollmentActivity) Unenrolled(
+	tx *gorm.DB,
+	userID uuid.UUID,
+	enrollment models.Enrollment,
+	refund models.RefundRequest,
+) error {
+
+	metadata := map[string]interface{}{
+		"enrollment_id":     enrollment.ID,
+		"student_id":        enrollment.StudentID,
+		"course_id":         enrollment.CourseID,
+		"refund_request_id": refund.ID,
+		"refund_amount":     refund.RefundAmount,
+		"policy_rule":       refund.PolicyRule,
+	}
+
+	return a.logger.LogWithTx(
+		context.Background(),
+		tx,
+		Event{
+			UserID:     userID,
+			Action:     models.ActionCourseUnenroll,
+			EntityID:   enrollment.ID,
+			EntityType: "enrollments",
+			Details:    fmt.Sprintf("Cancelled enrollment for student %s with a %.2f %s refund", enrollment.StudentID, refund.RefundAmount, refund.Currency),
+			Metadata:   metadata,
+		},
+	)
+}
+
+func (a *EnrollmentActivity) Enrolled(
+	tx *gorm.DB,
+	userID uuid.UUID,
+	enrollment models.Enrollment,
+	order models.Order,
+) error {
+
+	metadata := map[string]interface{}{
+		"enrollment_id": enrollment.ID,
+		"student_id":    enrollment.StudentID,
+		"course_id":     enrollment.CourseID,
+		"order_id":      order.ID,
+		"order_number":  order.OrderNumber,
+		"price_paid":    enrollment.PricePaid,
+	}
+
+	return a.logger.LogWithTx(
+		context.Background(),
+		tx,
+		Event{
+			UserID:     userID,
+			Action:     models.ActionCourseEnroll,
+			EntityID:   enrollment.ID,
+			EntityType: "enrollments",
+			Details:    fmt.Sprintf("Enrolled student %s from order %s", enrollment.StudentID, order.OrderNumber),
+			Metadata:   metadata,
+		},
+	)
+}
@@ -0,0 +1,44 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"crm-go/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrderActivity struct {
+	logger *Logger
+}
+
+func (a *OrderActivity) Paid(
+	tx *gorm.DB,
+	userID uuid.UUID,
+	order models.Order,
+) error {
+
+	metadata := map[string]interface{}{
+		"order_id":     order.ID,
+		"order_number": order.OrderNumber,
+		"customer_id":  order.UserID,
+		"total_amount": order.TotalAmount,
+		"currency":     order.Currency,
+		"payment_id":   order.PaymentID,
+	}
+
+	return a.logger.LogWithTx(
+		context.Background(),
+		tx,
+		Event{
+			UserID:     userID,
+			Action:     models.ActionPaymentSuccess,
+			EntityID:   order.ID,
+			EntityType: "orders",
+			Details:    fmt.Sprintf("Order %s paid: %.2f %s", order.OrderNumber, order.TotalAmount, order.Currency),
+			Metadata:   metadata,
+		},
+	)
+}
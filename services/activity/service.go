@@ -10,6 +10,9 @@ type Service struct {
 	Grades             *GradeActivity
 	LiveClasses        *LiveClassActivity
 	ObjectiveQuestions *ObjectiveActivity
+	Enrollments        *EnrollmentActivity
+	Subscriptions      *SubscriptionActivity
+	Orders             *OrderActivity
 }
 
 func NewService(db *gorm.DB) *Service {
@@ -23,5 +26,8 @@ func NewService(db *gorm.DB) *Service {
 		Grades:             &GradeActivity{logger},
 		LiveClasses:        &LiveClassActivity{logger},
 		ObjectiveQuestions: &ObjectiveActivity{logger},
+		Enrollments:        &EnrollmentActivity{logger},
+		Subscriptions:      &SubscriptionActivity{logger},
+		Orders:             &OrderActivity{logger},
 	}
 }
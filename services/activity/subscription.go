@@ -0,0 +1,70 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"crm-go/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SubscriptionActivity struct {
+	logger *Logger
+}
+
+func (a *SubscriptionActivity) Started(
+	tx *gorm.DB,
+	userID uuid.UUID,
+	subscription models.Subscription,
+) error {
+
+	metadata := map[string]interface{}{
+		"subscription_id": subscription.ID,
+		"plan_id":         subscription.PlanID,
+		"status":          subscription.Status,
+		"period_end":      subscription.CurrentPeriodEnd,
+	}
+
+	return a.logger.LogWithTx(
+		context.Background(),
+		tx,
+		Event{
+			UserID:     userID,
+			Action:     models.ActionSubscriptionStart,
+			EntityID:   subscription.ID,
+			EntityType: "subscriptions",
+			Details:    fmt.Sprintf("Started subscription to plan %s (%s)", subscription.PlanID, subscription.Status),
+			Metadata:   metadata,
+		},
+	)
+}
+
+func (a *SubscriptionActivity) Ended(
+	tx *gorm.DB,
+	userID uuid.UUID,
+	subscription models.Subscription,
+	reason string,
+) error {
+
+	metadata := map[string]interface{}{
+		"subscription_id": subscription.ID,
+		"plan_id":         subscription.PlanID,
+		"status":          subscription.Status,
+		"reason":          reason,
+	}
+
+	return a.logger.LogWithTx(
+		context.Background(),
+		tx,
+		Event{
+			UserID:     userID,
+			Action:     models.ActionSubscriptionEnd,
+			EntityID:   subscription.ID,
+			EntityType: "subscriptions",
+			Details:    fmt.Sprintf("Subscription to plan %s ended: %s", subscription.PlanID, reason),
+			Metadata:   metadata,
+		},
+	)
+}
@@ -15,11 +15,12 @@ func (u *UserActivity) Login(
 	c *gin.Context,
 	userID uuid.UUID,
 	success bool,
+	reason string,
 ) error {
 
 	details := "User logged in"
 	if !success {
-		details = "Failed login attempt"
+		details = "Failed login attempt: " + reason
 	}
 
 	return u.logger.LogFromRequest(
@@ -32,6 +33,7 @@ func (u *UserActivity) Login(
 			Details:    details,
 			Metadata: map[string]interface{}{
 				"success": success,
+				"reason":  reason,
 			},
 		},
 	)
@@ -0,0 +1,589 @@
+// services/api_key_service.go
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	permissionServices "crm-go/services/permissions"
+)
+
+var cfg = config.LoadEnv()
+
+const (
+	keyPrefix       = "crm_"
+	serviceProvider = "service" // users.provider of the users backing service accounts
+)
+
+// APIKeyService manages service accounts and their API keys, and authenticates requests that
+// carry a key
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{db: db}
+}
+
+// CreateServiceAccount adds a service account and the user that backs it (Admin)
+func (s *APIKeyService) CreateServiceAccount(req *dto.CreateServiceAccountRequest, createdBy uuid.UUID) (*dto.ServiceAccountResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkName(s.db, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+	role, err := s.checkRole(s.db, req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// The backing user has no password, so it cannot sign in
+	userID := uuid.New()
+	now := time.Now()
+	user := &models.User{
+		ID:         userID,
+		FirstName:  name,
+		LastName:   "Service Account",
+		Email:      "svc-" + userID.String() + "@service-accounts.invalid",
+		Provider:   serviceProvider,
+		Role:       role,
+		IsVerified: true,
+		VerifiedAt: &now,
+		IsActive:   true,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create service account user: " + err.Error())
+	}
+
+	account := &models.ServiceAccount{
+		UserID:      userID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		IsActive:    true,
+		CreatedBy:   createdBy,
+	}
+	if err := tx.Create(account).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create service account: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to create service account: " + err.Error())
+	}
+	account.User = *user
+	return s.toAccountResponse(account, nil)
+}
+
+// GetServiceAccounts lists every service account (Admin)
+func (s *APIKeyService) GetServiceAccounts() ([]dto.ServiceAccountResponse, error) {
+	var accounts []models.ServiceAccount
+	if err := s.db.Preload("User").Order("name ASC").Find(&accounts).Error; err != nil {
+		return nil, errors.New("failed to fetch service accounts: " + err.Error())
+	}
+
+	responses := make([]dto.ServiceAccountResponse, 0, len(accounts))
+	for i := range accounts {
+		response, err := s.toAccountResponse(&accounts[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// GetServiceAccount returns a service account with its keys (Admin)
+func (s *APIKeyService) GetServiceAccount(accountID string) (*dto.ServiceAccountResponse, error) {
+	account, err := s.findAccount(s.db, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []models.APIKey
+	if err := s.db.Where("service_account_id = ?", account.ID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, errors.New("failed to fetch API keys: " + err.Error())
+	}
+	return s.toAccountResponse(account, keys)
+}
+
+// UpdateServiceAccount changes a service account's details, role or whether it is enabled (Admin)
+func (s *APIKeyService) UpdateServiceAccount(accountID string, req *dto.UpdateServiceAccountRequest) (*dto.ServiceAccountResponse, error) {
+	account, err := s.findAccount(s.db, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	accountUpdates := map[string]interface{}{}
+	userUpdates := map[string]interface{}{}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := s.checkName(s.db, name, account.ID); err != nil {
+			return nil, err
+		}
+		accountUpdates["name"] = name
+		userUpdates["first_name"] = name
+	}
+	if req.Description != nil {
+		accountUpdates["description"] = strings.TrimSpace(*req.Description)
+	}
+	if req.Role != nil {
+		role, err := s.checkRole(s.db, *req.Role)
+		if err != nil {
+			return nil, err
+		}
+		userUpdates["role"] = role
+	}
+	if req.IsActive != nil && *req.IsActive != account.IsActive {
+		accountUpdates["is_active"] = *req.IsActive
+		if *req.IsActive {
+			accountUpdates["disabled_at"] = nil
+		} else {
+			accountUpdates["disabled_at"] = time.Now()
+		}
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if len(accountUpdates) > 0 {
+		if err := tx.Model(&models.ServiceAccount{}).Where("id = ?", account.ID).Updates(accountUpdates).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update service account: " + err.Error())
+		}
+	}
+	if len(userUpdates) > 0 {
+		if err := tx.Model(&models.User{}).Where("id = ?", account.UserID).Updates(userUpdates).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update service account user: " + err.Error())
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to update service account: " + err.Error())
+	}
+	return s.GetServiceAccount(accountID)
+}
+
+// DeleteServiceAccount removes a service account, its keys and its backing user (Admin)
+func (s *APIKeyService) DeleteServiceAccount(accountID string) error {
+	account, err := s.findAccount(s.db, accountID)
+	if err != nil {
+		return err
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("api_key_id IN (?)", tx.Model(&models.APIKey{}).Select("id").Where("service_account_id = ?", account.ID)).
+		Delete(&models.APIKeyUsage{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete API key usage: " + err.Error())
+	}
+	if err := tx.Where("service_account_id = ?", account.ID).Delete(&models.APIKey{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete API keys: " + err.Error())
+	}
+	if err := tx.Delete(&models.ServiceAccount{}, "id = ?", account.ID).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete service account: " + err.Error())
+	}
+	if err := tx.Delete(&models.User{}, "id = ?", account.UserID).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete service account user: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to delete service account: " + err.Error())
+	}
+	return nil
+}
+
+// CreateAPIKey issues a key for a service account. The key is returned only here; afterwards
+// only its prefix is known (Admin)
+func (s *APIKeyService) CreateAPIKey(accountID string, req *dto.CreateAPIKeyRequest, createdBy uuid.UUID) (*dto.CreatedAPIKeyResponse, error) {
+	account, err := s.findAccount(s.db, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if err := permissionServices.ValidatePermission(scope); err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	now := time.Now()
+	var expiresAt *time.Time
+	switch {
+	case req.NeverExpires && req.ExpiresAt != nil:
+		return nil, errors.New("give either expires_at or never_expires, not both")
+	case req.ExpiresAt != nil:
+		if !req.ExpiresAt.After(now) {
+			return nil, errors.New("expires_at must be in the future")
+		}
+		expiresAt = req.ExpiresAt
+	case !req.NeverExpires && cfg.APIKeyExpiryDays > 0:
+		expiry := now.AddDate(0, 0, cfg.APIKeyExpiryDays)
+		expiresAt = &expiry
+	}
+
+	rateLimit := req.RateLimitPerMinute
+	if rateLimit == 0 {
+		rateLimit = cfg.APIKeyRateLimit
+	}
+
+	prefix, err := randomHex(6)
+	if err != nil {
+		return nil, errors.New("failed to generate API key: " + err.Error())
+	}
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, errors.New("failed to generate API key: " + err.Error())
+	}
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	key := &models.APIKey{
+		ServiceAccountID:   account.ID,
+		Name:               strings.TrimSpace(req.Name),
+		Prefix:             prefix,
+		SecretHash:         hashSecret(secret),
+		Scopes:             strings.Join(scopes, ","),
+		RateLimitPerMinute: rateLimit,
+		ExpiresAt:          expiresAt,
+		CreatedBy:          createdBy,
+	}
+	if err := s.db.Create(key).Error; err != nil {
+		return nil, errors.New("failed to create API key: " + err.Error())
+	}
+
+	return &dto.CreatedAPIKeyResponse{
+		APIKeyResponse: toKeyResponse(key, now),
+		Key:            formatAPIKey(prefix, secret),
+	}, nil
+}
+
+// GetAPIKeys lists a service account's keys, newest first (Admin)
+func (s *APIKeyService) GetAPIKeys(accountID string) ([]dto.APIKeyResponse, error) {
+	account, err := s.findAccount(s.db, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []models.APIKey
+	if err := s.db.Where("service_account_id = ?", account.ID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, errors.New("failed to fetch API keys: " + err.Error())
+	}
+
+	now := time.Now()
+	responses := make([]dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		responses = append(responses, toKeyResponse(&keys[i], now))
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey stops a key from working. Revoked keys stay listed for auditing (Admin)
+func (s *APIKeyService) RevokeAPIKey(accountID string, keyID string) (*dto.APIKeyResponse, error) {
+	account, err := s.findAccount(s.db, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var key models.APIKey
+	if err := s.db.Where("id = ? AND service_account_id = ?", keyID, account.ID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("API key not found")
+		}
+		return nil, errors.New("failed to fetch API key: " + err.Error())
+	}
+	if key.RevokedAt != nil {
+		return nil, errors.New("API key is already revoked")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&key).Update("revoked_at", now).Error; err != nil {
+		return nil, errors.New("failed to revoke API key: " + err.Error())
+	}
+	key.RevokedAt = &now
+
+	response := toKeyResponse(&key, now)
+	return &response, nil
+}
+
+// IsAPIKey reports whether a credential looks like an API key rather than a JWT
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, keyPrefix)
+}
+
+// Authenticate checks an API key and counts the request against the key's rate limit. A
+// rejected request returns a principal only when the rate limit was hit, so the caller can say
+// when to retry.
+func (s *APIKeyService) Authenticate(rawKey string, ipAddress string) (*dto.APIKeyPrincipal, error) {
+	prefix, secret, err := parseAPIKey(rawKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var key models.APIKey
+	if err := s.db.Preload("ServiceAccount").Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid API key")
+		}
+		return nil, errors.New("failed to fetch API key: " + err.Error())
+	}
+	now := time.Now()
+	if err := verifyAPIKey(&key, secret, now); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "role", "is_active").Where("id = ?", key.ServiceAccount.UserID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("service account is disabled")
+		}
+		return nil, errors.New("failed to fetch service account user: " + err.Error())
+	}
+	if !user.IsActive {
+		return nil, errors.New("service account is disabled")
+	}
+	if user.Role == "admin" {
+		return nil, errors.New("service accounts cannot hold the admin role")
+	}
+	// Roles given to the user directly, rather than the account's role, must not grant everything either
+	held, err := permissionServices.NewPermissionService(s.db).EffectivePermissions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range held {
+		if permission.Permission == "*" {
+			return nil, errors.New("service accounts cannot hold the admin role")
+		}
+	}
+
+	// Fixed one-minute windows, counted in the database so every server shares the limit
+	window := now.Truncate(time.Minute)
+	var count int
+	if err := s.db.Raw(`INSERT INTO api_key_usage (api_key_id, window_start, count) VALUES (?, ?, 1)
+		ON CONFLICT (api_key_id, window_start) DO UPDATE SET count = api_key_usage.count + 1
+		RETURNING count`, key.ID, window).Scan(&count).Error; err != nil {
+		return nil, errors.New("failed to record API key usage: " + err.Error())
+	}
+	if count == 1 {
+		// First request of a new window; earlier windows are no longer needed
+		s.db.Where("api_key_id = ? AND window_start < ?", key.ID, window).Delete(&models.APIKeyUsage{})
+	}
+
+	principal := &dto.APIKeyPrincipal{
+		KeyID:              key.ID.String(),
+		ServiceAccountID:   key.ServiceAccountID.String(),
+		UserID:             user.ID.String(),
+		Role:               user.Role,
+		Scopes:             splitScopes(key.Scopes),
+		RateLimitPerMinute: key.RateLimitPerMinute,
+		Remaining:          key.RateLimitPerMinute - count,
+		ResetAt:            window.Add(time.Minute),
+	}
+	if principal.Remaining < 0 {
+		principal.Remaining = 0
+		return principal, errors.New("API key rate limit exceeded")
+	}
+
+	s.db.Model(&models.APIKey{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"last_used_at": now,
+		"last_used_ip": ipAddress,
+	})
+	return principal, nil
+}
+
+// checkName rejects a blank name or one another service account already uses
+func (s *APIKeyService) checkName(db *gorm.DB, name string, exceptID uuid.UUID) error {
+	if len(name) < 2 {
+		return errors.New("name must be at least 2 characters")
+	}
+	var count int64
+	if err := db.Model(&models.ServiceAccount{}).Where("name = ? AND id <> ?", name, exceptID).Count(&count).Error; err != nil {
+		return errors.New("failed to check service account name: " + err.Error())
+	}
+	if count > 0 {
+		return errors.New("a service account with this name already exists")
+	}
+	return nil
+}
+
+// checkRole returns the role's name when service accounts may hold it. Admin is refused so a
+// key is never trusted beyond its scopes, and guardian roles because their access depends on
+// linked students.
+func (s *APIKeyService) checkRole(db *gorm.DB, name string) (string, error) {
+	var role models.Role
+	if err := db.Where("name = ?", strings.TrimSpace(name)).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("role not found")
+		}
+		return "", errors.New("failed to fetch role: " + err.Error())
+	}
+	if role.Name == "admin" || role.Name == "guardian" || role.Name == "parent" {
+		return "", errors.New("service accounts cannot hold the " + role.Name + " role")
+	}
+	return role.Name, nil
+}
+
+func (s *APIKeyService) findAccount(db *gorm.DB, accountID string) (*models.ServiceAccount, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, errors.New("invalid service account ID")
+	}
+
+	var account models.ServiceAccount
+	if err := db.Preload("User").Where("id = ?", id).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("service account not found")
+		}
+		return nil, errors.New("failed to fetch service account: " + err.Error())
+	}
+	return &account, nil
+}
+
+func (s *APIKeyService) toAccountResponse(account *models.ServiceAccount, keys []models.APIKey) (*dto.ServiceAccountResponse, error) {
+	now := time.Now()
+	var activeKeys int64
+	if err := s.db.Model(&models.APIKey{}).
+		Where("service_account_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", account.ID, now).
+		Count(&activeKeys).Error; err != nil {
+		return nil, errors.New("failed to count API keys: " + err.Error())
+	}
+
+	response := &dto.ServiceAccountResponse{
+		ID:          account.ID.String(),
+		UserID:      account.UserID.String(),
+		Name:        account.Name,
+		Description: account.Description,
+		Role:        account.User.Role,
+		IsActive:    account.IsActive,
+		DisabledAt:  account.DisabledAt,
+		ActiveKeys:  activeKeys,
+		CreatedBy:   account.CreatedBy.String(),
+		CreatedAt:   account.CreatedAt,
+		UpdatedAt:   account.UpdatedAt,
+	}
+	if keys != nil {
+		response.Keys = make([]dto.APIKeyResponse, 0, len(keys))
+		for i := range keys {
+			response.Keys = append(response.Keys, toKeyResponse(&keys[i], now))
+		}
+	}
+	return response, nil
+}
+
+func toKeyResponse(key *models.APIKey, now time.Time) dto.APIKeyResponse {
+	status := "active"
+	if key.RevokedAt != nil {
+		status = "revoked"
+	} else if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		status = "expired"
+	}
+
+	return dto.APIKeyResponse{
+		ID:                 key.ID.String(),
+		ServiceAccountID:   key.ServiceAccountID.String(),
+		Name:               key.Name,
+		Prefix:             keyPrefix + key.Prefix,
+		Scopes:             splitScopes(key.Scopes),
+		RateLimitPerMinute: key.RateLimitPerMinute,
+		ExpiresAt:          key.ExpiresAt,
+		LastUsedAt:         key.LastUsedAt,
+		LastUsedIP:         key.LastUsedIP,
+		RevokedAt:          key.RevokedAt,
+		Status:             status,
+		CreatedBy:          key.CreatedBy.String(),
+		CreatedAt:          key.CreatedAt,
+	}
+}
+
+// formatAPIKey builds the key handed to the client: the public prefix that finds the stored
+// key, then the secret whose hash is stored
+func formatAPIKey(prefix string, secret string) string {
+	return keyPrefix + prefix + "_" + secret
+}
+
+// parseAPIKey splits a key into its prefix and secret
+func parseAPIKey(rawKey string) (string, string, error) {
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(rawKey, keyPrefix), "_")
+	if !IsAPIKey(rawKey) || !ok || prefix == "" || secret == "" {
+		return "", "", errors.New("invalid API key")
+	}
+	return prefix, secret, nil
+}
+
+// verifyAPIKey checks the secret against a stored key and that the key and its service
+// account are still usable
+func verifyAPIKey(key *models.APIKey, secret string, now time.Time) error {
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.SecretHash)) != 1 {
+		return errors.New("invalid API key")
+	}
+	if key.RevokedAt != nil {
+		return errors.New("API key has been revoked")
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return errors.New("API key has expired")
+	}
+	if !key.ServiceAccount.IsActive {
+		return errors.New("service account is disabled")
+	}
+	return nil
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"crm-go/models"
+)
+
+func TestParseAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		rawKey     string
+		wantPrefix string
+		wantSecret string
+		wantErr    bool
+	}{
+		{name: "valid", rawKey: "crm_a1b2c3d4e5f6_c2VjcmV0", wantPrefix: "a1b2c3d4e5f6", wantSecret: "c2VjcmV0"},
+		{name: "secret containing underscores", rawKey: "crm_a1b2c3d4e5f6_se_cr_et", wantPrefix: "a1b2c3d4e5f6", wantSecret: "se_cr_et"},
+		{name: "round trip", rawKey: formatAPIKey("0011aabbccdd", "x-y_z"), wantPrefix: "0011aabbccdd", wantSecret: "x-y_z"},
+		{name: "empty", rawKey: "", wantErr: true},
+		{name: "bearer token", rawKey: "eyJhbGciOiJSUzI1NiJ9.e30.sig", wantErr: true},
+		{name: "wrong prefix", rawKey: "key_a1b2c3d4e5f6_secret", wantErr: true},
+		{name: "no separator", rawKey: "crm_a1b2c3d4e5f6secret", wantErr: true},
+		{name: "no key prefix", rawKey: "crm__secret", wantErr: true},
+		{name: "no secret", rawKey: "crm_a1b2c3d4e5f6_", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, secret, err := parseAPIKey(tt.rawKey)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseAPIKey(%q) = %q, %q, want an error", tt.rawKey, prefix, secret)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAPIKey(%q) error = %v", tt.rawKey, err)
+			}
+			if prefix != tt.wantPrefix || secret != tt.wantSecret {
+				t.Errorf("parseAPIKey(%q) = %q, %q, want %q, %q", tt.rawKey, prefix, secret, tt.wantPrefix, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	const secret = "c2VjcmV0LXNlY3JldA"
+
+	key := func(change func(*models.APIKey)) *models.APIKey {
+		k := &models.APIKey{
+			SecretHash:     hashSecret(secret),
+			ServiceAccount: models.ServiceAccount{IsActive: true},
+		}
+		if change != nil {
+			change(k)
+		}
+		return k
+	}
+
+	tests := []struct {
+		name    string
+		key     *models.APIKey
+		secret  string
+		wantErr string
+	}{
+		{name: "valid", key: key(nil), secret: secret},
+		{name: "valid until later", key: key(func(k *models.APIKey) { k.ExpiresAt = &future }), secret: secret},
+		{name: "wrong secret", key: key(nil), secret: "guess", wantErr: "invalid API key"},
+		{name: "empty secret", key: key(nil), secret: "", wantErr: "invalid API key"},
+		{name: "revoked", key: key(func(k *models.APIKey) { k.RevokedAt = &past }), secret: secret, wantErr: "API key has been revoked"},
+		{name: "expired", key: key(func(k *models.APIKey) { k.ExpiresAt = &past }), secret: secret, wantErr: "API key has expired"},
+		{name: "expires now", key: key(func(k *models.APIKey) { k.ExpiresAt = &now }), secret: secret, wantErr: "API key has expired"},
+		{
+			name:    "disabled account",
+			key:     key(func(k *models.APIKey) { k.ServiceAccount.IsActive = false }),
+			secret:  secret,
+			wantErr: "service account is disabled",
+		},
+		{
+			name:    "wrong secret on a revoked key",
+			key:     key(func(k *models.APIKey) { k.RevokedAt = &past }),
+			secret:  "guess",
+			wantErr: "invalid API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyAPIKey(tt.key, tt.secret, now)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("verifyAPIKey() error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("verifyAPIKey() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
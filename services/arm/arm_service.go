@@ -87,18 +87,25 @@ func (s *ArmService) CreateArm(req *dto.CreateArmRequest, userID uuid.UUID) (*dt
 		capacity = 30
 	}
 
+	// Verify form teacher if provided
+	formTeacherID, err := s.resolveFormTeacher(req.FormTeacherID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create new arm
 	arm := &models.Arm{
-		ID:          uuid.New(),
-		Name:        strings.TrimSpace(req.Name),
-		Description: strings.TrimSpace(req.Description),
-		Code:        strings.ToUpper(strings.TrimSpace(code)),
-		GradeID:     gradeID,
-		Status:      status,
-		Capacity:    capacity,
-		CreatedBy:   userID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:            uuid.New(),
+		Name:          strings.TrimSpace(req.Name),
+		Description:   strings.TrimSpace(req.Description),
+		Code:          strings.ToUpper(strings.TrimSpace(code)),
+		GradeID:       gradeID,
+		Status:        status,
+		Capacity:      capacity,
+		FormTeacherID: formTeacherID,
+		CreatedBy:     userID,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Save to database
@@ -311,11 +318,26 @@ func (s *ArmService) UpdateArm(id string, req *dto.UpdateArmRequest, userID uuid
 		arm.GradeID = gID
 	}
 	if req.Capacity > 0 {
+		// Capacity cannot drop below the students already seated
+		var occupied int64
+		if err := s.db.Model(&models.ClassMembership{}).Where("arm_id = ? AND status = ?", armID, "active").Count(&occupied).Error; err != nil {
+			return nil, errors.New("failed to check arm usage: " + err.Error())
+		}
+		if int64(req.Capacity) < occupied {
+			return nil, fmt.Errorf("capacity cannot be less than the %d students already in this arm", occupied)
+		}
 		arm.Capacity = req.Capacity
 	}
 	if req.Status != "" {
 		arm.Status = req.Status
 	}
+	if req.FormTeacherID != "" {
+		formTeacherID, err := s.resolveFormTeacher(req.FormTeacherID)
+		if err != nil {
+			return nil, err
+		}
+		arm.FormTeacherID = formTeacherID
+	}
 
 	// Update timestamp
 	arm.UpdatedAt = time.Now()
@@ -348,15 +370,14 @@ func (s *ArmService) DeleteArm(id string) error {
 		return errors.New("failed to fetch arm: " + err.Error())
 	}
 
-	// Check if arm has students (if you have a Student model)
-	// Uncomment if you have a Student model with arm_id
-	// var studentCount int64
-	// if err := s.db.Model(&models.Student{}).Where("arm_id = ?", armID).Count(&studentCount).Error; err != nil {
-	// 	return errors.New("failed to check arm usage: " + err.Error())
-	// }
-	// if studentCount > 0 {
-	// 	return errors.New("cannot delete arm: it has students assigned")
-	// }
+	// Check if arm has students
+	var studentCount int64
+	if err := s.db.Model(&models.ClassMembership{}).Where("arm_id = ? AND status = ?", armID, "active").Count(&studentCount).Error; err != nil {
+		return errors.New("failed to check arm usage: " + err.Error())
+	}
+	if studentCount > 0 {
+		return errors.New("cannot delete arm: it has students assigned")
+	}
 
 	if err := s.db.Delete(&arm).Error; err != nil {
 		return errors.New("failed to delete arm: " + err.Error())
@@ -421,6 +442,30 @@ func (s *ArmService) validateArmRequest(req *dto.CreateArmRequest) error {
 	return nil
 }
 
+// resolveFormTeacher parses and verifies a form teacher ID, which may be empty
+func (s *ArmService) resolveFormTeacher(id string) (*uuid.UUID, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, nil
+	}
+	teacherID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid form teacher ID")
+	}
+
+	var teacher models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", teacherID).First(&teacher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("form teacher not found")
+		}
+		return nil, errors.New("failed to verify form teacher: " + err.Error())
+	}
+	if teacher.Role != "tutor" && teacher.Role != "admin" {
+		return nil, errors.New("form teacher must be a tutor or admin")
+	}
+
+	return &teacherID, nil
+}
+
 // toArmResponse converts model to response DTO
 func (s *ArmService) toArmResponse(arm *models.Arm) *dto.ArmResponse {
 	response := &dto.ArmResponse{
@@ -436,19 +481,24 @@ func (s *ArmService) toArmResponse(arm *models.Arm) *dto.ArmResponse {
 		UpdatedAt:   arm.UpdatedAt,
 	}
 
+	if arm.FormTeacherID != nil {
+		formTeacherID := arm.FormTeacherID.String()
+		response.FormTeacherID = &formTeacherID
+	}
+
 	// Add grade details if preloaded
 	if arm.Grade.ID != uuid.Nil {
 		response.Grade = &dto.ClassGradeResponse{
-			ID:           arm.Grade.ID.String(),
-			Name:         arm.Grade.Name,
-			Code:         arm.Grade.Code,
-			Level:        arm.Grade.Level,
-			Description:  arm.Grade.Description,
+			ID:                arm.Grade.ID.String(),
+			Name:              arm.Grade.Name,
+			Code:              arm.Grade.Code,
+			Level:             arm.Grade.Level,
+			Description:       arm.Grade.Description,
 			AcademicSessionID: string(arm.Grade.AcademicSessionID.String()),
-			Capacity:     arm.Grade.Capacity,
-			Status:       arm.Grade.Status,
-			CreatedAt:    arm.Grade.CreatedAt,
-			UpdatedAt:    arm.Grade.UpdatedAt,
+			Capacity:          arm.Grade.Capacity,
+			Status:            arm.Grade.Status,
+			CreatedAt:         arm.Grade.CreatedAt,
+			UpdatedAt:         arm.Grade.UpdatedAt,
 		}
 	}
 
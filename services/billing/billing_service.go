@@ -0,0 +1,1136 @@
+// services/billing_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+const dateLayout = "2006-01-02"
+
+type BillingService struct {
+	db *gorm.DB
+}
+
+func NewBillingService(db *gorm.DB) *BillingService {
+	return &BillingService{db: db}
+}
+
+// CreateFeeSchedule creates a grade's fee schedule for a term
+func (s *BillingService) CreateFeeSchedule(req *dto.CreateFeeScheduleRequest, userID uuid.UUID) (*dto.FeeScheduleResponse, error) {
+	gradeID, err := uuid.Parse(req.ClassGradeID)
+	if err != nil {
+		return nil, errors.New("invalid class grade ID")
+	}
+	sessionID, err := uuid.Parse(req.AcademicSessionID)
+	if err != nil {
+		return nil, errors.New("invalid academic session ID")
+	}
+
+	// Check if grade exists
+	var grade models.ClassGrade
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", gradeID).First(&grade).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("class grade not found")
+		}
+		return nil, errors.New("failed to verify class grade: " + err.Error())
+	}
+
+	// Check if session exists
+	var session models.AcademicSession
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("academic session not found")
+		}
+		return nil, errors.New("failed to verify academic session: " + err.Error())
+	}
+
+	term := strings.TrimSpace(req.Term)
+	var existing models.FeeSchedule
+	if err := s.db.Where("class_grade_id = ? AND academic_session_id = ? AND term = ?", gradeID, sessionID, term).First(&existing).Error; err == nil {
+		return nil, errors.New("fee schedule already exists for this grade and term")
+	}
+
+	dueDate, err := parseOptionalDate(req.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = "NGN"
+	}
+
+	now := time.Now()
+	schedule := &models.FeeSchedule{
+		ID:                     uuid.New(),
+		Name:                   strings.TrimSpace(req.Name),
+		ClassGradeID:           gradeID,
+		AcademicSessionID:      sessionID,
+		Term:                   term,
+		Currency:               currency,
+		SiblingDiscountPercent: req.SiblingDiscountPercent,
+		DueDate:                dueDate,
+		Status:                 "active",
+		CreatedBy:              userID,
+		CreatedAt:              now,
+		UpdatedAt:              now,
+		Items:                  buildFeeItems(req.Items, now),
+	}
+
+	if err := s.db.Omit("ClassGrade", "AcademicSession").Create(schedule).Error; err != nil {
+		return nil, errors.New("failed to create fee schedule: " + err.Error())
+	}
+
+	return s.GetFeeScheduleByID(schedule.ID.String())
+}
+
+// GetFeeSchedules lists fee schedules with optional filters
+func (s *BillingService) GetFeeSchedules(params *dto.FeeScheduleQueryParams) ([]dto.FeeScheduleResponse, error) {
+	query := s.db.Model(&models.FeeSchedule{}).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC")
+	})
+	if params.ClassGradeID != "" {
+		query = query.Where("class_grade_id = ?", params.ClassGradeID)
+	}
+	if params.AcademicSessionID != "" {
+		query = query.Where("academic_session_id = ?", params.AcademicSessionID)
+	}
+	if params.Term != "" {
+		query = query.Where("term = ?", params.Term)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	var schedules []models.FeeSchedule
+	if err := query.Order("created_at DESC").Find(&schedules).Error; err != nil {
+		return nil, errors.New("failed to fetch fee schedules: " + err.Error())
+	}
+
+	responses := make([]dto.FeeScheduleResponse, 0, len(schedules))
+	for i := range schedules {
+		responses = append(responses, *s.toScheduleResponse(&schedules[i]))
+	}
+	return responses, nil
+}
+
+// GetFeeScheduleByID retrieves a fee schedule with its items
+func (s *BillingService) GetFeeScheduleByID(id string) (*dto.FeeScheduleResponse, error) {
+	schedule, err := s.loadSchedule(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toScheduleResponse(schedule), nil
+}
+
+// UpdateFeeSchedule updates a fee schedule; items can only change before anyone is invoiced
+func (s *BillingService) UpdateFeeSchedule(id string, req *dto.UpdateFeeScheduleRequest) (*dto.FeeScheduleResponse, error) {
+	schedule, err := s.loadSchedule(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if req.Name != "" {
+		updates["name"] = strings.TrimSpace(req.Name)
+	}
+	if req.SiblingDiscountPercent != nil {
+		updates["sibling_discount_percent"] = *req.SiblingDiscountPercent
+	}
+	if req.DueDate != "" {
+		dueDate, err := parseOptionalDate(req.DueDate)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		updates["due_date"] = dueDate
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+
+	if len(req.Items) > 0 {
+		var invoiced int64
+		if err := tx.Model(&models.Invoice{}).Where("schedule_id = ? AND status <> ?", schedule.ID, "cancelled").Count(&invoiced).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to check invoices: " + err.Error())
+		}
+		if invoiced > 0 {
+			tx.Rollback()
+			return nil, errors.New("fee items cannot change once students have been invoiced")
+		}
+
+		if err := tx.Where("schedule_id = ?", schedule.ID).Delete(&models.FeeItem{}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to replace fee items: " + err.Error())
+		}
+		items := buildFeeItems(req.Items, time.Now())
+		for i := range items {
+			items[i].ScheduleID = schedule.ID
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to replace fee items: " + err.Error())
+		}
+	}
+
+	if err := tx.Model(&models.FeeSchedule{}).Where("id = ?", schedule.ID).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update fee schedule: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to update fee schedule: " + err.Error())
+	}
+
+	return s.GetFeeScheduleByID(schedule.ID.String())
+}
+
+// DeleteFeeSchedule soft deletes a fee schedule that has no live invoices
+func (s *BillingService) DeleteFeeSchedule(id string) error {
+	schedule, err := s.loadSchedule(s.db, id)
+	if err != nil {
+		return err
+	}
+
+	var invoiced int64
+	if err := s.db.Model(&models.Invoice{}).Where("schedule_id = ? AND status <> ?", schedule.ID, "cancelled").Count(&invoiced).Error; err != nil {
+		return errors.New("failed to check invoices: " + err.Error())
+	}
+	if invoiced > 0 {
+		return errors.New("cannot delete fee schedule: it has invoices")
+	}
+
+	if err := s.db.Delete(schedule).Error; err != nil {
+		return errors.New("failed to delete fee schedule: " + err.Error())
+	}
+	return nil
+}
+
+// GenerateInvoices bills students against a fee schedule, skipping anyone already billed.
+// Siblings (students sharing an active guardian) get the schedule's sibling discount on tuition
+// when another sibling already holds a live invoice for the same session and term.
+func (s *BillingService) GenerateInvoices(req *dto.GenerateInvoicesRequest, userID uuid.UUID) (*dto.GenerateInvoicesResponse, error) {
+	schedule, err := s.loadSchedule(s.db, req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != "active" {
+		return nil, errors.New("fee schedule is not active")
+	}
+
+	optional := make(map[uuid.UUID]bool, len(req.OptionalItemIDs))
+	for _, id := range req.OptionalItemIDs {
+		itemID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, errors.New("invalid optional item ID: " + id)
+		}
+		optional[itemID] = true
+	}
+	for itemID := range optional {
+		found := false
+		for _, item := range schedule.Items {
+			if item.ID == itemID && item.Category == "optional" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("optional item " + itemID.String() + " not found on this schedule")
+		}
+	}
+
+	studentIDs, err := s.scheduleStudents(schedule, req.StudentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(studentIDs) == 0 {
+		return nil, errors.New("no students to invoice for this schedule")
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	response := &dto.GenerateInvoicesResponse{
+		ScheduleID: schedule.ID.String(),
+		Skipped:    []string{},
+		Invoices:   []dto.InvoiceResponse{},
+	}
+	created := make([]uuid.UUID, 0, len(studentIDs))
+
+	for _, studentID := range studentIDs {
+		var billed int64
+		if err := tx.Model(&models.Invoice{}).
+			Where("student_id = ? AND schedule_id = ? AND status <> ?", studentID, schedule.ID, "cancelled").
+			Count(&billed).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to check existing invoices: " + err.Error())
+		}
+		if billed > 0 {
+			response.Skipped = append(response.Skipped, studentID.String())
+			continue
+		}
+
+		invoice, err := s.createInvoice(tx, schedule, studentID, optional, req.Installments, userID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		created = append(created, invoice.ID)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to generate invoices: " + err.Error())
+	}
+
+	for _, id := range created {
+		invoice, err := s.GetInvoiceByID(id.String())
+		if err != nil {
+			return nil, err
+		}
+		response.Invoices = append(response.Invoices, *invoice)
+	}
+	response.Created = len(created)
+
+	return response, nil
+}
+
+// GetInvoices lists invoices with pagination and filters
+func (s *BillingService) GetInvoices(params *dto.InvoiceQueryParams) (*dto.InvoiceListResponse, error) {
+	// Set defaults
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	query := s.db.Model(&models.Invoice{})
+	if params.StudentID != "" {
+		query = query.Where("student_id = ?", params.StudentID)
+	}
+	if params.ScheduleID != "" {
+		query = query.Where("schedule_id = ?", params.ScheduleID)
+	}
+	if params.AcademicSessionID != "" {
+		query = query.Where("academic_session_id = ?", params.AcademicSessionID)
+	}
+	if params.Term != "" {
+		query = query.Where("term = ?", params.Term)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("failed to count invoices: " + err.Error())
+	}
+
+	var invoices []models.Invoice
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Preload("Student").Preload("Lines").Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Order("issued_at DESC").Offset(offset).Limit(params.Limit).Find(&invoices).Error; err != nil {
+		return nil, errors.New("failed to fetch invoices: " + err.Error())
+	}
+
+	responses := make([]dto.InvoiceResponse, 0, len(invoices))
+	for i := range invoices {
+		responses = append(responses, *s.toInvoiceResponse(&invoices[i]))
+	}
+
+	totalPages := int(total) / params.Limit
+	if int(total)%params.Limit > 0 {
+		totalPages++
+	}
+
+	return &dto.InvoiceListResponse{
+		Invoices:   responses,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetInvoiceByID retrieves an invoice with its lines and installments
+func (s *BillingService) GetInvoiceByID(id string) (*dto.InvoiceResponse, error) {
+	invoice, err := s.loadInvoice(s.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.toInvoiceResponse(invoice), nil
+}
+
+// SetInstallments replaces an invoice's installment plan, carrying over what has been paid
+func (s *BillingService) SetInstallments(id string, req *dto.SetInstallmentsRequest) (*dto.InvoiceResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	invoice, err := s.loadInvoice(tx, id, true)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if invoice.Status == "cancelled" || invoice.Status == "paid" {
+		tx.Rollback()
+		return nil, errors.New("installments cannot change on a " + invoice.Status + " invoice")
+	}
+
+	installments, err := buildInstallments(invoice.ID, invoice.TotalAmount, req.Installments)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Where("invoice_id = ?", invoice.ID).Delete(&models.InvoiceInstallment{}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to replace installments: " + err.Error())
+	}
+	applyToInstallments(installments, invoice.AmountPaid)
+	if err := tx.Create(&installments).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to replace installments: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to replace installments: " + err.Error())
+	}
+
+	return s.GetInvoiceByID(invoice.ID.String())
+}
+
+// CancelInvoice voids an unpaid invoice and reverses it on the ledger
+func (s *BillingService) CancelInvoice(id string, userID uuid.UUID) (*dto.InvoiceResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	invoice, err := s.loadInvoice(tx, id, true)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if invoice.Status == "cancelled" {
+		tx.Rollback()
+		return nil, errors.New("invoice is already cancelled")
+	}
+	if invoice.AmountPaid > 0 {
+		tx.Rollback()
+		return nil, errors.New("cannot cancel an invoice with payments")
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).Updates(map[string]interface{}{
+		"status":       "cancelled",
+		"cancelled_at": now,
+		"updated_at":   now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to cancel invoice: " + err.Error())
+	}
+
+	if err := writeLedger(tx, &models.LedgerEntry{
+		StudentID:   invoice.StudentID,
+		InvoiceID:   &invoice.ID,
+		EntryType:   "reversal",
+		Credit:      invoice.TotalAmount,
+		Description: "Invoice " + invoice.InvoiceNumber + " cancelled",
+		CreatedBy:   userID,
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to cancel invoice: " + err.Error())
+	}
+
+	return s.GetInvoiceByID(invoice.ID.String())
+}
+
+// RecordPayment records a full or partial payment against an invoice
+func (s *BillingService) RecordPayment(invoiceID string, req *dto.RecordPaymentRequest, userID uuid.UUID) (*dto.PaymentResponse, error) {
+	amount := roundMoney(req.Amount)
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	invoice, err := s.loadInvoice(tx, invoiceID, true)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if invoice.Status == "cancelled" {
+		tx.Rollback()
+		return nil, errors.New("cannot pay a cancelled invoice")
+	}
+	outstanding := roundMoney(invoice.TotalAmount - invoice.AmountPaid)
+	if outstanding <= 0 {
+		tx.Rollback()
+		return nil, errors.New("invoice is already paid")
+	}
+	if amount > outstanding {
+		tx.Rollback()
+		return nil, fmt.Errorf("payment of %.2f exceeds the outstanding balance of %.2f", amount, outstanding)
+	}
+
+	gateway := req.Gateway
+	if gateway == "" {
+		gateway = "manual"
+	}
+
+	now := time.Now()
+	payment := &models.Payment{
+		ID:               uuid.New(),
+		PaymentID:        referenceNumber("PAY", now),
+		GatewayReference: strings.TrimSpace(req.GatewayReference),
+		PayerID:          invoice.StudentID,
+		PayerEmail:       invoice.Student.Email,
+		PayerName:        strings.TrimSpace(invoice.Student.FirstName + " " + invoice.Student.LastName),
+		PayerPhone:       invoice.Student.Phone,
+		Amount:           amount,
+		Currency:         invoice.Currency,
+		PaymentMethod:    req.PaymentMethod,
+		Gateway:          gateway,
+		Status:           "completed",
+		InitiatedAt:      now,
+		ProcessedAt:      &now,
+		InvoiceID:        &invoice.ID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := tx.Omit("Payer", "Course").Create(payment).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to record payment: " + err.Error())
+	}
+
+	if err := writeLedger(tx, &models.LedgerEntry{
+		StudentID:   invoice.StudentID,
+		InvoiceID:   &invoice.ID,
+		PaymentID:   &payment.ID,
+		EntryType:   "payment",
+		Credit:      amount,
+		Description: "Payment " + payment.PaymentID + " on invoice " + invoice.InvoiceNumber,
+		CreatedBy:   userID,
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	paid := roundMoney(invoice.AmountPaid + amount)
+	status := "partially_paid"
+	if paid >= invoice.TotalAmount {
+		status = "paid"
+	}
+	if err := tx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).Updates(map[string]interface{}{
+		"amount_paid": paid,
+		"status":      status,
+		"updated_at":  now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update invoice: " + err.Error())
+	}
+
+	if len(invoice.Installments) > 0 {
+		applyToInstallments(invoice.Installments, paid)
+		for _, installment := range invoice.Installments {
+			if err := tx.Model(&models.InvoiceInstallment{}).Where("id = ?", installment.ID).Updates(map[string]interface{}{
+				"amount_paid": installment.AmountPaid,
+				"status":      installment.Status,
+				"updated_at":  now,
+			}).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.New("failed to update installments: " + err.Error())
+			}
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to record payment: " + err.Error())
+	}
+
+	return toPaymentResponse(payment), nil
+}
+
+// StudentBalance derives a student's outstanding balance from the ledger
+func (s *BillingService) StudentBalance(studentID uuid.UUID) (float64, error) {
+	var balance float64
+	if err := s.db.Model(&models.LedgerEntry{}).
+		Where("student_id = ?", studentID).
+		Select("COALESCE(SUM(debit - credit), 0)").
+		Scan(&balance).Error; err != nil {
+		return 0, errors.New("failed to compute balance: " + err.Error())
+	}
+	return roundMoney(balance), nil
+}
+
+// GetStatement builds a student's account statement from the ledger
+func (s *BillingService) GetStatement(studentID string) (*dto.StatementResponse, error) {
+	studentUUID, err := uuid.Parse(studentID)
+	if err != nil {
+		return nil, errors.New("invalid student ID")
+	}
+
+	var student models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", studentUUID).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("student not found")
+		}
+		return nil, errors.New("failed to fetch student: " + err.Error())
+	}
+
+	var entries []models.LedgerEntry
+	if err := s.db.Where("student_id = ?", studentUUID).Order("created_at ASC, id ASC").Find(&entries).Error; err != nil {
+		return nil, errors.New("failed to fetch ledger: " + err.Error())
+	}
+
+	statement := &dto.StatementResponse{
+		StudentID:           studentUUID.String(),
+		StudentName:         strings.TrimSpace(student.FirstName + " " + student.LastName),
+		OutstandingInvoices: []dto.InvoiceResponse{},
+		Entries:             make([]dto.LedgerEntryResponse, 0, len(entries)),
+	}
+
+	balance := 0.0
+	for _, entry := range entries {
+		balance = roundMoney(balance + entry.Debit - entry.Credit)
+		statement.TotalDebits += entry.Debit
+		statement.TotalCredits += entry.Credit
+
+		item := dto.LedgerEntryResponse{
+			ID:          entry.ID.String(),
+			EntryType:   entry.EntryType,
+			Description: entry.Description,
+			Debit:       entry.Debit,
+			Credit:      entry.Credit,
+			Balance:     balance,
+			CreatedAt:   entry.CreatedAt,
+		}
+		if entry.InvoiceID != nil {
+			invoiceID := entry.InvoiceID.String()
+			item.InvoiceID = &invoiceID
+		}
+		if entry.PaymentID != nil {
+			paymentID := entry.PaymentID.String()
+			item.PaymentID = &paymentID
+		}
+		statement.Entries = append(statement.Entries, item)
+	}
+	statement.TotalDebits = roundMoney(statement.TotalDebits)
+	statement.TotalCredits = roundMoney(statement.TotalCredits)
+	statement.OutstandingBalance = balance
+
+	var invoices []models.Invoice
+	if err := s.db.Preload("Lines").Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Where("student_id = ? AND status IN ?", studentUUID, []string{"issued", "partially_paid"}).
+		Order("issued_at ASC").Find(&invoices).Error; err != nil {
+		return nil, errors.New("failed to fetch outstanding invoices: " + err.Error())
+	}
+	for i := range invoices {
+		statement.OutstandingInvoices = append(statement.OutstandingInvoices, *s.toInvoiceResponse(&invoices[i]))
+	}
+
+	return statement, nil
+}
+
+// GetReceiptPDF renders a receipt for one of a student's invoice payments
+func (s *BillingService) GetReceiptPDF(studentID, paymentID string) ([]byte, string, error) {
+	studentUUID, err := uuid.Parse(studentID)
+	if err != nil {
+		return nil, "", errors.New("invalid student ID")
+	}
+	paymentUUID, err := uuid.Parse(paymentID)
+	if err != nil {
+		return nil, "", errors.New("invalid payment ID")
+	}
+
+	var payment models.Payment
+	if err := s.db.Where("id = ? AND payer_id = ? AND invoice_id IS NOT NULL", paymentUUID, studentUUID).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", errors.New("payment not found")
+		}
+		return nil, "", errors.New("failed to fetch payment: " + err.Error())
+	}
+
+	invoice, err := s.loadInvoice(s.db, payment.InvoiceID.String(), false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	processedAt := payment.CreatedAt
+	if payment.ProcessedAt != nil {
+		processedAt = *payment.ProcessedAt
+	}
+
+	lines := []string{
+		"Receipt number:   " + payment.PaymentID,
+		"Date:             " + processedAt.Format("2 January 2006 15:04"),
+		"Student:          " + strings.TrimSpace(invoice.Student.FirstName+" "+invoice.Student.LastName),
+		"Invoice:          " + invoice.InvoiceNumber + " (" + invoice.Term + ")",
+		"",
+		fmt.Sprintf("Amount received:  %s %.2f", payment.Currency, payment.Amount),
+		"Payment method:   " + strings.ReplaceAll(payment.PaymentMethod, "_", " "),
+	}
+	if payment.GatewayReference != "" {
+		lines = append(lines, "Reference:        "+payment.GatewayReference)
+	}
+	lines = append(lines, "", "Invoice summary")
+	for _, line := range invoice.Lines {
+		lines = append(lines, fmt.Sprintf("  %-40s %12.2f", line.Description, line.Amount))
+	}
+	lines = append(lines,
+		fmt.Sprintf("  %-40s %12.2f", "Total", invoice.TotalAmount),
+		fmt.Sprintf("  %-40s %12.2f", "Paid to date", invoice.AmountPaid),
+		fmt.Sprintf("  %-40s %12.2f", "Balance", roundMoney(invoice.TotalAmount-invoice.AmountPaid)),
+		"",
+		"Thank you for your payment.",
+	)
+
+	return utils.BuildTextPDF("Payment Receipt", lines), "receipt-" + payment.PaymentID + ".pdf", nil
+}
+
+// createInvoice writes one invoice, its lines, installments and ledger entries
+func (s *BillingService) createInvoice(tx *gorm.DB, schedule *models.FeeSchedule, studentID uuid.UUID, optional map[uuid.UUID]bool, plan []dto.InstallmentRequest, userID uuid.UUID) (*models.Invoice, error) {
+	now := time.Now()
+	invoice := &models.Invoice{
+		ID:                uuid.New(),
+		InvoiceNumber:     referenceNumber("INV", now),
+		StudentID:         studentID,
+		ScheduleID:        schedule.ID,
+		AcademicSessionID: schedule.AcademicSessionID,
+		Term:              schedule.Term,
+		Currency:          schedule.Currency,
+		Status:            "issued",
+		DueDate:           schedule.DueDate,
+		IssuedAt:          now,
+		CreatedBy:         userID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	tuition := 0.0
+	for _, item := range schedule.Items {
+		if item.Category == "optional" && !optional[item.ID] {
+			continue
+		}
+		itemID := item.ID
+		invoice.Lines = append(invoice.Lines, models.InvoiceLine{
+			ID:          uuid.New(),
+			InvoiceID:   invoice.ID,
+			FeeItemID:   &itemID,
+			Description: item.Name,
+			Category:    item.Category,
+			Amount:      item.Amount,
+			CreatedAt:   now,
+		})
+		invoice.Subtotal += item.Amount
+		if item.Category == "tuition" {
+			tuition += item.Amount
+		}
+	}
+	invoice.Subtotal = roundMoney(invoice.Subtotal)
+
+	if schedule.SiblingDiscountPercent > 0 && tuition > 0 {
+		hasSibling, err := s.hasInvoicedSibling(tx, studentID, schedule)
+		if err != nil {
+			return nil, err
+		}
+		if hasSibling {
+			invoice.DiscountAmount = roundMoney(tuition * schedule.SiblingDiscountPercent / 100)
+			invoice.Lines = append(invoice.Lines, models.InvoiceLine{
+				ID:          uuid.New(),
+				InvoiceID:   invoice.ID,
+				Description: fmt.Sprintf("Sibling discount (%.2f%% of tuition)", schedule.SiblingDiscountPercent),
+				Category:    "discount",
+				Amount:      -invoice.DiscountAmount,
+				CreatedAt:   now,
+			})
+		}
+	}
+	invoice.TotalAmount = roundMoney(invoice.Subtotal - invoice.DiscountAmount)
+
+	if len(plan) > 0 {
+		installments, err := buildInstallments(invoice.ID, invoice.TotalAmount, plan)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Installments = installments
+	}
+
+	if err := tx.Omit("Student", "Schedule").Create(invoice).Error; err != nil {
+		return nil, errors.New("failed to create invoice: " + err.Error())
+	}
+
+	if err := writeLedger(tx, &models.LedgerEntry{
+		StudentID:   studentID,
+		InvoiceID:   &invoice.ID,
+		EntryType:   "invoice",
+		Debit:       invoice.Subtotal,
+		Description: "Invoice " + invoice.InvoiceNumber + " - " + schedule.Name,
+		CreatedBy:   userID,
+	}); err != nil {
+		return nil, err
+	}
+	if invoice.DiscountAmount > 0 {
+		if err := writeLedger(tx, &models.LedgerEntry{
+			StudentID:   studentID,
+			InvoiceID:   &invoice.ID,
+			EntryType:   "discount",
+			Credit:      invoice.DiscountAmount,
+			Description: "Sibling discount on invoice " + invoice.InvoiceNumber,
+			CreatedBy:   userID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return invoice, nil
+}
+
+// hasInvoicedSibling reports whether a student who shares an active guardian is already billed for the term
+func (s *BillingService) hasInvoicedSibling(tx *gorm.DB, studentID uuid.UUID, schedule *models.FeeSchedule) (bool, error) {
+	guardianUsers := tx.Model(&models.Guardian{}).
+		Select("user_id").
+		Where("student_id = ? AND status = ? AND deleted_at IS NULL", studentID, "active")
+	siblings := tx.Model(&models.Guardian{}).
+		Select("student_id").
+		Where("user_id IN (?) AND student_id <> ? AND status = ? AND deleted_at IS NULL", guardianUsers, studentID, "active")
+
+	var count int64
+	if err := tx.Model(&models.Invoice{}).
+		Where("student_id IN (?) AND academic_session_id = ? AND term = ? AND status <> ?", siblings, schedule.AcademicSessionID, schedule.Term, "cancelled").
+		Count(&count).Error; err != nil {
+		return false, errors.New("failed to check sibling invoices: " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// scheduleStudents resolves the students to bill: those requested, or everyone seated in the grade
+func (s *BillingService) scheduleStudents(schedule *models.FeeSchedule, requested []string) ([]uuid.UUID, error) {
+	if len(requested) > 0 {
+		ids := make([]uuid.UUID, 0, len(requested))
+		for _, id := range requested {
+			studentID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, errors.New("invalid student ID: " + id)
+			}
+			ids = append(ids, studentID)
+		}
+
+		var found int64
+		if err := s.db.Model(&models.User{}).Where("id IN ? AND deleted_at IS NULL", ids).Count(&found).Error; err != nil {
+			return nil, errors.New("failed to verify students: " + err.Error())
+		}
+		if int(found) != len(ids) {
+			return nil, errors.New("one or more students not found")
+		}
+		return ids, nil
+	}
+
+	var ids []uuid.UUID
+	if err := s.db.Model(&models.ClassMembership{}).
+		Joins("JOIN arms ON arms.id = class_memberships.arm_id").
+		Where("arms.grade_id = ? AND class_memberships.academic_session_id = ? AND class_memberships.status = ?", schedule.ClassGradeID, schedule.AcademicSessionID, "active").
+		Order("class_memberships.placed_at ASC").
+		Pluck("class_memberships.student_id", &ids).Error; err != nil {
+		return nil, errors.New("failed to fetch students in grade: " + err.Error())
+	}
+	return ids, nil
+}
+
+// loadSchedule fetches a fee schedule with its items in display order
+func (s *BillingService) loadSchedule(db *gorm.DB, id string) (*models.FeeSchedule, error) {
+	scheduleID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid fee schedule ID")
+	}
+
+	var schedule models.FeeSchedule
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC")
+	}).Where("id = ?", scheduleID).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("fee schedule not found")
+		}
+		return nil, errors.New("failed to fetch fee schedule: " + err.Error())
+	}
+	return &schedule, nil
+}
+
+// loadInvoice fetches an invoice with its student, lines and installments, optionally locking it
+func (s *BillingService) loadInvoice(db *gorm.DB, id string, lock bool) (*models.Invoice, error) {
+	invoiceID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid invoice ID")
+	}
+
+	query := db.Preload("Student").Preload("Lines").Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	})
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var invoice models.Invoice
+	if err := query.Where("id = ?", invoiceID).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, errors.New("failed to fetch invoice: " + err.Error())
+	}
+	return &invoice, nil
+}
+
+// writeLedger appends a ledger entry
+func writeLedger(tx *gorm.DB, entry *models.LedgerEntry) error {
+	entry.ID = uuid.New()
+	entry.Debit = roundMoney(entry.Debit)
+	entry.Credit = roundMoney(entry.Credit)
+	entry.CreatedAt = time.Now()
+	if err := tx.Create(entry).Error; err != nil {
+		return errors.New("failed to write ledger entry: " + err.Error())
+	}
+	return nil
+}
+
+// buildFeeItems converts requested items into models, keeping their order
+func buildFeeItems(items []dto.FeeItemRequest, now time.Time) []models.FeeItem {
+	result := make([]models.FeeItem, 0, len(items))
+	for i, item := range items {
+		result = append(result, models.FeeItem{
+			ID:        uuid.New(),
+			Name:      strings.TrimSpace(item.Name),
+			Category:  item.Category,
+			Amount:    roundMoney(item.Amount),
+			SortOrder: i,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	return result
+}
+
+// buildInstallments validates a plan against the invoice total and orders it by due date
+func buildInstallments(invoiceID uuid.UUID, total float64, plan []dto.InstallmentRequest) ([]models.InvoiceInstallment, error) {
+	now := time.Now()
+	installments := make([]models.InvoiceInstallment, 0, len(plan))
+	sum := 0.0
+	for _, item := range plan {
+		dueDate, err := time.Parse(dateLayout, item.DueDate)
+		if err != nil {
+			return nil, errors.New("invalid installment due date format, use YYYY-MM-DD")
+		}
+		amount := roundMoney(item.Amount)
+		sum += amount
+		installments = append(installments, models.InvoiceInstallment{
+			ID:        uuid.New(),
+			InvoiceID: invoiceID,
+			DueDate:   dueDate,
+			Amount:    amount,
+			Status:    "pending",
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	if math.Abs(roundMoney(sum)-total) > 0.009 {
+		return nil, fmt.Errorf("installments add up to %.2f but the invoice total is %.2f", sum, total)
+	}
+
+	sort.SliceStable(installments, func(i, j int) bool {
+		return installments[i].DueDate.Before(installments[j].DueDate)
+	})
+	for i := range installments {
+		installments[i].Sequence = i + 1
+	}
+	return installments, nil
+}
+
+// applyToInstallments spreads the total paid across installments, earliest first
+func applyToInstallments(installments []models.InvoiceInstallment, paid float64) {
+	remaining := roundMoney(paid)
+	for i := range installments {
+		applied := math.Min(remaining, installments[i].Amount)
+		installments[i].AmountPaid = roundMoney(applied)
+		remaining = roundMoney(remaining - applied)
+
+		switch {
+		case installments[i].AmountPaid >= installments[i].Amount:
+			installments[i].Status = "paid"
+		case installments[i].AmountPaid > 0:
+			installments[i].Status = "partially_paid"
+		default:
+			installments[i].Status = "pending"
+		}
+	}
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date, allowing empty input
+func parseOptionalDate(value string) (*time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(dateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return nil, errors.New("invalid due date format, use YYYY-MM-DD")
+	}
+	return &parsed, nil
+}
+
+// referenceNumber builds a human-readable unique reference such as INV-20250101-1A2B3C4D
+func referenceNumber(prefix string, now time.Time) string {
+	return prefix + "-" + now.Format("20060102") + "-" + strings.ToUpper(uuid.New().String()[:8])
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// toScheduleResponse converts model to response DTO
+func (s *BillingService) toScheduleResponse(schedule *models.FeeSchedule) *dto.FeeScheduleResponse {
+	response := &dto.FeeScheduleResponse{
+		ID:                     schedule.ID.String(),
+		Name:                   schedule.Name,
+		ClassGradeID:           schedule.ClassGradeID.String(),
+		AcademicSessionID:      schedule.AcademicSessionID.String(),
+		Term:                   schedule.Term,
+		Currency:               schedule.Currency,
+		SiblingDiscountPercent: schedule.SiblingDiscountPercent,
+		DueDate:                schedule.DueDate,
+		Status:                 schedule.Status,
+		Items:                  make([]dto.FeeItemResponse, 0, len(schedule.Items)),
+		CreatedBy:              schedule.CreatedBy.String(),
+		CreatedAt:              schedule.CreatedAt,
+		UpdatedAt:              schedule.UpdatedAt,
+	}
+	for _, item := range schedule.Items {
+		if item.Category != "optional" {
+			response.RequiredTotal += item.Amount
+		}
+		response.Items = append(response.Items, dto.FeeItemResponse{
+			ID:       item.ID.String(),
+			Name:     item.Name,
+			Category: item.Category,
+			Amount:   item.Amount,
+		})
+	}
+	response.RequiredTotal = roundMoney(response.RequiredTotal)
+	return response
+}
+
+// toInvoiceResponse converts model to response DTO
+func (s *BillingService) toInvoiceResponse(invoice *models.Invoice) *dto.InvoiceResponse {
+	response := &dto.InvoiceResponse{
+		ID:                invoice.ID.String(),
+		InvoiceNumber:     invoice.InvoiceNumber,
+		StudentID:         invoice.StudentID.String(),
+		ScheduleID:        invoice.ScheduleID.String(),
+		AcademicSessionID: invoice.AcademicSessionID.String(),
+		Term:              invoice.Term,
+		Currency:          invoice.Currency,
+		Subtotal:          invoice.Subtotal,
+		DiscountAmount:    invoice.DiscountAmount,
+		TotalAmount:       invoice.TotalAmount,
+		AmountPaid:        invoice.AmountPaid,
+		Balance:           roundMoney(invoice.TotalAmount - invoice.AmountPaid),
+		Status:            invoice.Status,
+		DueDate:           invoice.DueDate,
+		IssuedAt:          invoice.IssuedAt,
+		CancelledAt:       invoice.CancelledAt,
+		Lines:             make([]dto.InvoiceLineResponse, 0, len(invoice.Lines)),
+		Installments:      make([]dto.InstallmentResponse, 0, len(invoice.Installments)),
+		CreatedAt:         invoice.CreatedAt,
+		UpdatedAt:         invoice.UpdatedAt,
+	}
+	if invoice.Status == "cancelled" {
+		response.Balance = 0
+	}
+	if invoice.Student.ID != uuid.Nil {
+		response.StudentName = strings.TrimSpace(invoice.Student.FirstName + " " + invoice.Student.LastName)
+	}
+	for _, line := range invoice.Lines {
+		response.Lines = append(response.Lines, dto.InvoiceLineResponse{
+			ID:          line.ID.String(),
+			Description: line.Description,
+			Category:    line.Category,
+			Amount:      line.Amount,
+		})
+	}
+	for _, installment := range invoice.Installments {
+		response.Installments = append(response.Installments, dto.InstallmentResponse{
+			ID:         installment.ID.String(),
+			Sequence:   installment.Sequence,
+			DueDate:    installment.DueDate,
+			Amount:     installment.Amount,
+			AmountPaid: installment.AmountPaid,
+			Status:     installment.Status,
+		})
+	}
+	return response
+}
+
+// toPaymentResponse converts model to response DTO
+func toPaymentResponse(payment *models.Payment) *dto.PaymentResponse {
+	response := &dto.PaymentResponse{
+		ID:               payment.ID.String(),
+		PaymentID:        payment.PaymentID,
+		Amount:           payment.Amount,
+		Currency:         payment.Currency,
+		PaymentMethod:    payment.PaymentMethod,
+		Gateway:          payment.Gateway,
+		GatewayReference: payment.GatewayReference,
+		Status:           payment.Status,
+		ProcessedAt:      payment.ProcessedAt,
+	}
+	if payment.InvoiceID != nil {
+		response.InvoiceID = payment.InvoiceID.String()
+	}
+	return response
+}
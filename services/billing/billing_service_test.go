@@ -0,0 +1,116 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"crm-go/dto"
+	"crm-go/models"
+)
+
+func TestBuildInstallmentsSortsByDueDate(t *testing.T) {
+	invoiceID := uuid.New()
+	plan := []dto.InstallmentRequest{
+		{DueDate: "2026-03-01", Amount: 30},
+		{DueDate: "2026-01-15", Amount: 50},
+		{DueDate: "2026-02-01", Amount: 20},
+	}
+
+	installments, err := buildInstallments(invoiceID, 100, plan)
+	if err != nil {
+		t.Fatalf("buildInstallments() error = %v", err)
+	}
+
+	var dueDates []string
+	for i, installment := range installments {
+		if installment.Sequence != i+1 {
+			t.Errorf("installment %d has sequence %d", i, installment.Sequence)
+		}
+		if installment.InvoiceID != invoiceID || installment.Status != "pending" {
+			t.Errorf("installment %d = %+v, want a pending installment of the invoice", i, installment)
+		}
+		dueDates = append(dueDates, installment.DueDate.Format(dateLayout))
+	}
+	want := []string{"2026-01-15", "2026-02-01", "2026-03-01"}
+	if !reflect.DeepEqual(dueDates, want) {
+		t.Errorf("due dates = %v, want %v", dueDates, want)
+	}
+}
+
+func TestBuildInstallmentsMustAddUpToTheTotal(t *testing.T) {
+	thirds := []dto.InstallmentRequest{
+		{DueDate: "2026-01-01", Amount: 33.33},
+		{DueDate: "2026-02-01", Amount: 33.33},
+		{DueDate: "2026-03-01", Amount: 33.34},
+	}
+	if _, err := buildInstallments(uuid.New(), 100, thirds); err != nil {
+		t.Errorf("installments of 33.33 + 33.33 + 33.34 for 100: error = %v", err)
+	}
+
+	short := []dto.InstallmentRequest{
+		{DueDate: "2026-01-01", Amount: 40},
+		{DueDate: "2026-02-01", Amount: 40},
+	}
+	if _, err := buildInstallments(uuid.New(), 100, short); err == nil {
+		t.Error("installments of 80 for 100: want an error")
+	}
+
+	// Amounts are rounded to cents before they are added up, so sub-cent shares cannot fill a gap
+	subCent := []dto.InstallmentRequest{
+		{DueDate: "2026-01-01", Amount: 33.333},
+		{DueDate: "2026-02-01", Amount: 33.333},
+		{DueDate: "2026-03-01", Amount: 33.333},
+	}
+	if _, err := buildInstallments(uuid.New(), 100, subCent); err == nil {
+		t.Error("installments of 3 x 33.333 for 100: want an error")
+	}
+}
+
+func TestBuildInstallmentsRejectsBadDates(t *testing.T) {
+	plan := []dto.InstallmentRequest{{DueDate: "01/02/2026", Amount: 100}}
+	if _, err := buildInstallments(uuid.New(), 100, plan); err == nil {
+		t.Error("buildInstallments() with a DD/MM/YYYY date: want an error")
+	}
+}
+
+func TestApplyToInstallments(t *testing.T) {
+	tests := []struct {
+		paid       float64
+		wantPaid   []float64
+		wantStatus []string
+	}{
+		{paid: 0, wantPaid: []float64{0, 0, 0}, wantStatus: []string{"pending", "pending", "pending"}},
+		{paid: 25, wantPaid: []float64{25, 0, 0}, wantStatus: []string{"partially_paid", "pending", "pending"}},
+		{paid: 50, wantPaid: []float64{50, 0, 0}, wantStatus: []string{"paid", "pending", "pending"}},
+		{paid: 80.01, wantPaid: []float64{50, 30, 0.01}, wantStatus: []string{"paid", "paid", "partially_paid"}},
+		{paid: 100, wantPaid: []float64{50, 30, 20}, wantStatus: []string{"paid", "paid", "paid"}},
+		{paid: 130, wantPaid: []float64{50, 30, 20}, wantStatus: []string{"paid", "paid", "paid"}},
+	}
+
+	for _, tt := range tests {
+		installments := []models.InvoiceInstallment{{Amount: 50}, {Amount: 30}, {Amount: 20}}
+		applyToInstallments(installments, tt.paid)
+
+		for i, installment := range installments {
+			if installment.AmountPaid != tt.wantPaid[i] || installment.Status != tt.wantStatus[i] {
+				t.Errorf("paid %.2f: installment %d = %.2f %s, want %.2f %s",
+					tt.paid, i+1, installment.AmountPaid, installment.Status, tt.wantPaid[i], tt.wantStatus[i])
+			}
+		}
+	}
+}
+
+func TestApplyToInstallmentsResetsEarlierPayments(t *testing.T) {
+	// The total paid is spread afresh each time; what the installments held before is ignored
+	installments := []models.InvoiceInstallment{
+		{Amount: 50, AmountPaid: 50, Status: "paid"},
+		{Amount: 50, AmountPaid: 50, Status: "paid"},
+	}
+	applyToInstallments(installments, 60)
+
+	if installments[0].Status != "paid" || installments[1].AmountPaid != 10 || installments[1].Status != "partially_paid" {
+		t.Errorf("after paying 60 of 100: %+v", installments)
+	}
+}
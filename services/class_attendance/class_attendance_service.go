@@ -0,0 +1,665 @@
+// services/class_attendance_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+const dateLayout = "2006-01-02"
+
+type ClassAttendanceService struct {
+	db *gorm.DB
+}
+
+func NewClassAttendanceService(db *gorm.DB) *ClassAttendanceService {
+	return &ClassAttendanceService{db: db}
+}
+
+// SubmitRegister takes or amends an arm's register for a day (and optionally a period)
+func (s *ClassAttendanceService) SubmitRegister(req *dto.SubmitAttendanceRequest, userID uuid.UUID, canManage bool) (*dto.AttendanceRegisterResponse, error) {
+	armID, err := uuid.Parse(req.ArmID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+	date, err := time.Parse(dateLayout, req.Date)
+	if err != nil {
+		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+	}
+	if date.After(time.Now()) {
+		return nil, errors.New("attendance cannot be taken for a future date")
+	}
+
+	// Load arm with its grade to find the academic session
+	var arm models.Arm
+	if err := s.db.Preload("Grade").Where("id = ? AND deleted_at IS NULL", armID).First(&arm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("arm not found")
+		}
+		return nil, errors.New("failed to fetch arm: " + err.Error())
+	}
+
+	var session models.AcademicSession
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", arm.Grade.AcademicSessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("academic session not found")
+		}
+		return nil, errors.New("failed to fetch academic session: " + err.Error())
+	}
+	if date.Before(session.StartDate) || date.After(session.EndDate) {
+		return nil, errors.New("date is outside the arm's academic session")
+	}
+
+	if err := s.canTakeRegister(&arm, req.Period, userID, canManage); err != nil {
+		return nil, err
+	}
+
+	// Every mark must belong to a student seated in the arm
+	roster, err := s.roster(arm.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	marks := make(map[uuid.UUID]dto.AttendanceMark, len(req.Marks))
+	for _, mark := range req.Marks {
+		studentID, err := uuid.Parse(mark.StudentID)
+		if err != nil {
+			return nil, errors.New("invalid student ID: " + mark.StudentID)
+		}
+		if !roster[studentID] {
+			return nil, errors.New("student " + mark.StudentID + " is not in this arm")
+		}
+		if _, ok := marks[studentID]; ok {
+			return nil, errors.New("student " + mark.StudentID + " is marked more than once")
+		}
+		mark.Reason = strings.TrimSpace(mark.Reason)
+		if mark.Status == "excused" && mark.Reason == "" {
+			return nil, errors.New("a reason is required for excused absences")
+		}
+		marks[studentID] = mark
+	}
+
+	missing := 0
+	for studentID := range roster {
+		if _, ok := marks[studentID]; ok {
+			continue
+		}
+		if req.MarkOthersPresent {
+			marks[studentID] = dto.AttendanceMark{StudentID: studentID.String(), Status: "present"}
+			continue
+		}
+		missing++
+	}
+	if missing > 0 {
+		return nil, fmt.Errorf("register incomplete: %d students not marked", missing)
+	}
+
+	now := time.Now()
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var register models.AttendanceRegister
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("arm_id = ? AND date = ? AND period = ?", arm.ID, date, req.Period).
+		First(&register).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		register = models.AttendanceRegister{
+			ID:                uuid.New(),
+			ArmID:             arm.ID,
+			AcademicSessionID: session.ID,
+			Date:              date,
+			Period:            req.Period,
+			LocksAt:           locksAt(date),
+			TakenBy:           userID,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		if now.After(register.LocksAt) && !canManage {
+			tx.Rollback()
+			return nil, errors.New("attendance register is locked: the cutoff has passed")
+		}
+		if err := tx.Omit("Arm", "Records").Create(&register).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to create attendance register: " + err.Error())
+		}
+	case err != nil:
+		tx.Rollback()
+		return nil, errors.New("failed to fetch attendance register: " + err.Error())
+	default:
+		if now.After(register.LocksAt) && !canManage {
+			tx.Rollback()
+			return nil, errors.New("attendance register is locked: the cutoff has passed")
+		}
+		if err := tx.Model(&register).Updates(map[string]interface{}{
+			"updated_by": userID,
+			"updated_at": now,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update attendance register: " + err.Error())
+		}
+	}
+
+	var existing []models.AttendanceRecord
+	if err := tx.Where("register_id = ?", register.ID).Find(&existing).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to fetch attendance records: " + err.Error())
+	}
+	existingByStudent := make(map[uuid.UUID]models.AttendanceRecord, len(existing))
+	for _, record := range existing {
+		existingByStudent[record.StudentID] = record
+	}
+
+	for studentID, mark := range marks {
+		if record, ok := existingByStudent[studentID]; ok {
+			if record.Status == mark.Status && record.Reason == mark.Reason {
+				continue
+			}
+			updates := map[string]interface{}{
+				"status":     mark.Status,
+				"reason":     mark.Reason,
+				"updated_at": now,
+			}
+			// A student re-marked absent gets a fresh notification
+			if mark.Status == "absent" && record.Status != "absent" {
+				updates["guardian_notified_at"] = nil
+			}
+			if err := tx.Model(&models.AttendanceRecord{}).Where("id = ?", record.ID).Updates(updates).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.New("failed to update attendance record: " + err.Error())
+			}
+			continue
+		}
+
+		record := models.AttendanceRecord{
+			ID:         uuid.New(),
+			RegisterID: register.ID,
+			StudentID:  studentID,
+			Status:     mark.Status,
+			Reason:     mark.Reason,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := tx.Omit("Student").Create(&record).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to save attendance record: " + err.Error())
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to save attendance register: " + err.Error())
+	}
+
+	// Let guardians know about absences without holding up the response
+	go s.notifyGuardians(register.ID)
+
+	return s.GetRegisterByID(register.ID.String())
+}
+
+// GetRegisterByID retrieves a register with its marks
+func (s *ClassAttendanceService) GetRegisterByID(id string) (*dto.AttendanceRegisterResponse, error) {
+	registerID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid register ID")
+	}
+
+	var register models.AttendanceRegister
+	if err := s.db.Preload("Records.Student").Where("id = ?", registerID).First(&register).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("attendance register not found")
+		}
+		return nil, errors.New("failed to fetch attendance register: " + err.Error())
+	}
+
+	return s.toRegisterResponse(&register), nil
+}
+
+// GetArmRegisters lists an arm's registers, newest first
+func (s *ClassAttendanceService) GetArmRegisters(armID string, params *dto.AttendanceRegisterQueryParams) ([]dto.AttendanceRegisterResponse, error) {
+	armUUID, err := uuid.Parse(armID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+
+	query := s.db.Model(&models.AttendanceRegister{}).Where("arm_id = ?", armUUID)
+	if params.From != "" {
+		from, err := time.Parse(dateLayout, params.From)
+		if err != nil {
+			return nil, errors.New("invalid from date format, use YYYY-MM-DD")
+		}
+		query = query.Where("date >= ?", from)
+	}
+	if params.To != "" {
+		to, err := time.Parse(dateLayout, params.To)
+		if err != nil {
+			return nil, errors.New("invalid to date format, use YYYY-MM-DD")
+		}
+		query = query.Where("date <= ?", to)
+	}
+	if params.Period != nil {
+		query = query.Where("period = ?", *params.Period)
+	}
+
+	var registers []models.AttendanceRegister
+	if err := query.Preload("Records").Order("date DESC, period ASC").Find(&registers).Error; err != nil {
+		return nil, errors.New("failed to fetch attendance registers: " + err.Error())
+	}
+
+	responses := make([]dto.AttendanceRegisterResponse, 0, len(registers))
+	for i := range registers {
+		responses = append(responses, *s.toRegisterResponse(&registers[i]))
+	}
+	return responses, nil
+}
+
+// GetArmSummary totals attendance for every student in an arm over a term
+func (s *ClassAttendanceService) GetArmSummary(armID string, params *dto.AttendanceSummaryQueryParams) (*dto.ArmAttendanceSummaryResponse, error) {
+	armUUID, err := uuid.Parse(armID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+
+	var arm models.Arm
+	if err := s.db.Preload("Grade").Where("id = ? AND deleted_at IS NULL", armUUID).First(&arm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("arm not found")
+		}
+		return nil, errors.New("failed to fetch arm: " + err.Error())
+	}
+
+	if params.AcademicSessionID == "" {
+		params.AcademicSessionID = arm.Grade.AcademicSessionID.String()
+	}
+	session, from, to, err := s.resolveTerm(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var registers int64
+	if err := s.db.Model(&models.AttendanceRegister{}).
+		Where("arm_id = ? AND academic_session_id = ? AND period = ? AND date BETWEEN ? AND ?", arm.ID, session.ID, params.Period, from, to).
+		Count(&registers).Error; err != nil {
+		return nil, errors.New("failed to count attendance registers: " + err.Error())
+	}
+
+	totals, err := s.tally(s.db.Where("attendance_registers.arm_id = ?", arm.ID), session.ID, params.Period, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Students currently seated with no marks yet still appear
+	roster, err := s.roster(arm.ID)
+	if err != nil {
+		return nil, err
+	}
+	for studentID := range roster {
+		if _, ok := totals[studentID]; !ok {
+			totals[studentID] = &dto.StudentAttendanceSummary{StudentID: studentID.String()}
+		}
+	}
+
+	students, err := s.withNames(totals)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ArmAttendanceSummaryResponse{
+		ArmID:             arm.ID.String(),
+		AcademicSessionID: session.ID.String(),
+		From:              from.Format(dateLayout),
+		To:                to.Format(dateLayout),
+		Registers:         int(registers),
+		Students:          students,
+	}, nil
+}
+
+// GetStudentSummary totals a student's attendance across all arms over a term
+func (s *ClassAttendanceService) GetStudentSummary(studentID string, params *dto.AttendanceSummaryQueryParams) (*dto.StudentAttendanceSummary, error) {
+	studentUUID, err := uuid.Parse(studentID)
+	if err != nil {
+		return nil, errors.New("invalid student ID")
+	}
+
+	session, from, to, err := s.resolveTerm(params)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.tally(s.db.Where("attendance_records.student_id = ?", studentUUID), session.ID, params.Period, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := totals[studentUUID]; !ok {
+		totals[studentUUID] = &dto.StudentAttendanceSummary{StudentID: studentUUID.String()}
+	}
+
+	students, err := s.withNames(totals)
+	if err != nil {
+		return nil, err
+	}
+	return &students[0], nil
+}
+
+// canTakeRegister allows attendance managers, the arm's form teacher and, for period registers, teachers allocated to the arm
+func (s *ClassAttendanceService) canTakeRegister(arm *models.Arm, period int, userID uuid.UUID, canManage bool) error {
+	if canManage {
+		return nil
+	}
+	if arm.FormTeacherID != nil && *arm.FormTeacherID == userID {
+		return nil
+	}
+	if period > 0 {
+		var allocated int64
+		if err := s.db.Model(&models.TeacherAllocation{}).
+			Where("teacher_id = ? AND arm_id = ? AND status = ?", userID, arm.ID, "active").
+			Count(&allocated).Error; err != nil {
+			return errors.New("failed to verify teacher allocation: " + err.Error())
+		}
+		if allocated > 0 {
+			return nil
+		}
+	}
+	return errors.New("not authorized to take attendance for this arm")
+}
+
+// roster returns the students currently seated in an arm
+func (s *ClassAttendanceService) roster(armID uuid.UUID) (map[uuid.UUID]bool, error) {
+	var studentIDs []uuid.UUID
+	if err := s.db.Model(&models.ClassMembership{}).
+		Where("arm_id = ? AND status = ?", armID, "active").
+		Pluck("student_id", &studentIDs).Error; err != nil {
+		return nil, errors.New("failed to fetch class list: " + err.Error())
+	}
+
+	roster := make(map[uuid.UUID]bool, len(studentIDs))
+	for _, id := range studentIDs {
+		roster[id] = true
+	}
+	return roster, nil
+}
+
+// resolveTerm picks the session (current by default) and the date range to summarise
+func (s *ClassAttendanceService) resolveTerm(params *dto.AttendanceSummaryQueryParams) (*models.AcademicSession, time.Time, time.Time, error) {
+	var session models.AcademicSession
+	query := s.db.Where("deleted_at IS NULL")
+	if params.AcademicSessionID != "" {
+		sessionID, err := uuid.Parse(params.AcademicSessionID)
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, errors.New("invalid academic session ID")
+		}
+		query = query.Where("id = ?", sessionID)
+	} else {
+		query = query.Where("is_current = ?", true)
+	}
+	if err := query.First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, time.Time{}, time.Time{}, errors.New("academic session not found")
+		}
+		return nil, time.Time{}, time.Time{}, errors.New("failed to fetch academic session: " + err.Error())
+	}
+
+	from, to := session.StartDate, session.EndDate
+	if params.From != "" {
+		parsed, err := time.Parse(dateLayout, params.From)
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, errors.New("invalid from date format, use YYYY-MM-DD")
+		}
+		from = parsed
+	}
+	if params.To != "" {
+		parsed, err := time.Parse(dateLayout, params.To)
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, errors.New("invalid to date format, use YYYY-MM-DD")
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		return nil, time.Time{}, time.Time{}, errors.New("to date must be on or after from date")
+	}
+
+	return &session, from, to, nil
+}
+
+// tally counts marks per student for registers matching the scope
+func (s *ClassAttendanceService) tally(scope *gorm.DB, sessionID uuid.UUID, period int, from, to time.Time) (map[uuid.UUID]*dto.StudentAttendanceSummary, error) {
+	type row struct {
+		StudentID uuid.UUID
+		Status    string
+		Count     int
+	}
+
+	var rows []row
+	if err := s.db.Table("attendance_records").
+		Select("attendance_records.student_id, attendance_records.status, COUNT(*) AS count").
+		Joins("JOIN attendance_registers ON attendance_registers.id = attendance_records.register_id").
+		Where(scope).
+		Where("attendance_registers.academic_session_id = ? AND attendance_registers.period = ?", sessionID, period).
+		Where("attendance_registers.date BETWEEN ? AND ?", from, to).
+		Group("attendance_records.student_id, attendance_records.status").
+		Scan(&rows).Error; err != nil {
+		return nil, errors.New("failed to summarise attendance: " + err.Error())
+	}
+
+	totals := make(map[uuid.UUID]*dto.StudentAttendanceSummary)
+	for _, r := range rows {
+		summary, ok := totals[r.StudentID]
+		if !ok {
+			summary = &dto.StudentAttendanceSummary{StudentID: r.StudentID.String()}
+			totals[r.StudentID] = summary
+		}
+		switch r.Status {
+		case "present":
+			summary.Present += r.Count
+		case "absent":
+			summary.Absent += r.Count
+		case "late":
+			summary.Late += r.Count
+		case "excused":
+			summary.Excused += r.Count
+		}
+		summary.Total += r.Count
+	}
+
+	// Late still counts as attended
+	for _, summary := range totals {
+		if summary.Total > 0 {
+			percentage := float64(summary.Present+summary.Late) / float64(summary.Total) * 100
+			summary.Percentage = math.Round(percentage*100) / 100
+		}
+	}
+
+	return totals, nil
+}
+
+// withNames attaches student names and sorts summaries by name
+func (s *ClassAttendanceService) withNames(totals map[uuid.UUID]*dto.StudentAttendanceSummary) ([]dto.StudentAttendanceSummary, error) {
+	ids := make([]uuid.UUID, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+
+	var students []models.User
+	if len(ids) > 0 {
+		if err := s.db.Where("id IN ?", ids).Find(&students).Error; err != nil {
+			return nil, errors.New("failed to fetch students: " + err.Error())
+		}
+	}
+	for _, student := range students {
+		if summary, ok := totals[student.ID]; ok {
+			summary.StudentName = strings.TrimSpace(student.FirstName + " " + student.LastName)
+		}
+	}
+
+	summaries := make([]dto.StudentAttendanceSummary, 0, len(totals))
+	for _, summary := range totals {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].StudentName != summaries[j].StudentName {
+			return summaries[i].StudentName < summaries[j].StudentName
+		}
+		return summaries[i].StudentID < summaries[j].StudentID
+	})
+	return summaries, nil
+}
+
+// locksAt is when a register for the given date locks: the cutoff hours after midnight of
+// that date in the school's time zone
+func locksAt(date time.Time) time.Time {
+	location, err := time.LoadLocation(cfg.SchoolTimezone)
+	if err != nil {
+		location = time.UTC
+	}
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+	return midnight.Add(time.Duration(cfg.AttendanceCutoffHours) * time.Hour)
+}
+
+// notifyGuardians emails active guardians of students marked absent who have not been told yet
+func (s *ClassAttendanceService) notifyGuardians(registerID uuid.UUID) {
+	var register models.AttendanceRegister
+	if err := s.db.Preload("Arm").Where("id = ?", registerID).First(&register).Error; err != nil {
+		log.Printf("❌ Failed to load attendance register %s for notifications: %v", registerID, err)
+		return
+	}
+
+	var records []models.AttendanceRecord
+	if err := s.db.Preload("Student").
+		Where("register_id = ? AND status = ? AND guardian_notified_at IS NULL", registerID, "absent").
+		Find(&records).Error; err != nil {
+		log.Printf("❌ Failed to load absences for register %s: %v", registerID, err)
+		return
+	}
+
+	for _, record := range records {
+		var guardians []models.Guardian
+		if err := s.db.Preload("User").
+			Where("student_id = ? AND status = ? AND deleted_at IS NULL", record.StudentID, "active").
+			Find(&guardians).Error; err != nil {
+			log.Printf("❌ Failed to load guardians for student %s: %v", record.StudentID, err)
+			continue
+		}
+
+		studentName := strings.TrimSpace(record.Student.FirstName + " " + record.Student.LastName)
+		subject := "Absence Notice - " + studentName
+		body := fmt.Sprintf(
+			"<p>Dear Parent/Guardian,</p><p>%s was marked <strong>absent</strong> from %s on %s.</p>",
+			studentName, register.Arm.Name, register.Date.Format("Monday, 2 January 2006"),
+		)
+		if record.Reason != "" {
+			body += fmt.Sprintf("<p>Reason recorded: %s</p>", record.Reason)
+		}
+		body += "<p>Please contact the school if you have any questions.</p>"
+
+		sent := false
+		for _, guardian := range guardians {
+			if guardian.User.Email == "" {
+				continue
+			}
+			if err := utils.SendEmail(guardian.User.Email, subject, body); err != nil {
+				log.Printf("absence email to %s failed: %v", guardian.User.Email, err)
+				continue
+			}
+			sent = true
+		}
+
+		if sent {
+			now := time.Now()
+			if err := s.db.Model(&models.AttendanceRecord{}).Where("id = ?", record.ID).Update("guardian_notified_at", now).Error; err != nil {
+				log.Printf("❌ Failed to mark guardian notification for record %s: %v", record.ID, err)
+			}
+		}
+	}
+}
+
+// toRegisterResponse converts model to response DTO
+func (s *ClassAttendanceService) toRegisterResponse(register *models.AttendanceRegister) *dto.AttendanceRegisterResponse {
+	response := &dto.AttendanceRegisterResponse{
+		ID:                register.ID.String(),
+		ArmID:             register.ArmID.String(),
+		AcademicSessionID: register.AcademicSessionID.String(),
+		Date:              register.Date.Format(dateLayout),
+		Period:            register.Period,
+		LocksAt:           register.LocksAt,
+		Locked:            time.Now().After(register.LocksAt),
+		TakenBy:           register.TakenBy.String(),
+		Records:           make([]dto.AttendanceRecordResponse, 0, len(register.Records)),
+		CreatedAt:         register.CreatedAt,
+		UpdatedAt:         register.UpdatedAt,
+	}
+	if register.UpdatedBy != nil {
+		updatedBy := register.UpdatedBy.String()
+		response.UpdatedBy = &updatedBy
+	}
+
+	for _, record := range register.Records {
+		switch record.Status {
+		case "present":
+			response.Present++
+		case "absent":
+			response.Absent++
+		case "late":
+			response.Late++
+		case "excused":
+			response.Excused++
+		}
+
+		item := dto.AttendanceRecordResponse{
+			ID:                 record.ID.String(),
+			StudentID:          record.StudentID.String(),
+			Status:             record.Status,
+			Reason:             record.Reason,
+			GuardianNotifiedAt: record.GuardianNotifiedAt,
+		}
+		if record.Student.ID != uuid.Nil {
+			item.Student = &dto.UserResponse{
+				ID:         record.Student.ID.String(),
+				FirstName:  record.Student.FirstName,
+				LastName:   record.Student.LastName,
+				MiddleName: record.Student.MiddleName,
+				FullName:   strings.TrimSpace(record.Student.FirstName + " " + record.Student.LastName),
+				Email:      record.Student.Email,
+				Phone:      record.Student.Phone,
+				Role:       record.Student.Role,
+				Picture:    record.Student.Picture,
+				IsVerified: record.Student.IsVerified,
+				IsActive:   record.Student.IsActive,
+				CreatedAt:  record.Student.CreatedAt,
+				UpdatedAt:  record.Student.UpdatedAt,
+			}
+		}
+		response.Records = append(response.Records, item)
+	}
+
+	sort.Slice(response.Records, func(i, j int) bool {
+		a, b := response.Records[i], response.Records[j]
+		if a.Student != nil && b.Student != nil && a.Student.FullName != b.Student.FullName {
+			return a.Student.FullName < b.Student.FullName
+		}
+		return a.StudentID < b.StudentID
+	})
+
+	return response
+}
@@ -0,0 +1,695 @@
+// services/class_membership_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+)
+
+type ClassMembershipService struct {
+	db *gorm.DB
+}
+
+func NewClassMembershipService(db *gorm.DB) *ClassMembershipService {
+	return &ClassMembershipService{db: db}
+}
+
+// PlaceStudent seats a student in an arm for the arm's academic session
+func (s *ClassMembershipService) PlaceStudent(req *dto.PlaceStudentRequest, userID uuid.UUID) (*dto.ClassMembershipResponse, error) {
+	studentID, err := uuid.Parse(req.StudentID)
+	if err != nil {
+		return nil, errors.New("invalid student ID")
+	}
+	armID, err := uuid.Parse(req.ArmID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+
+	// Check if student exists
+	var student models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", studentID).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("student not found")
+		}
+		return nil, errors.New("failed to verify student: " + err.Error())
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	arm, err := s.LockArmWithCapacity(tx, armID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	membership, err := s.seatStudent(tx, studentID, arm, strings.TrimSpace(req.Reason), userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to place student: " + err.Error())
+	}
+
+	return s.GetMembershipByID(membership.ID.String())
+}
+
+// PlaceStudentTx seats a student inside the caller's transaction, for flows that create the
+// student at the same time
+func (s *ClassMembershipService) PlaceStudentTx(tx *gorm.DB, studentID uuid.UUID, armID uuid.UUID, reason string, userID uuid.UUID) (*models.ClassMembership, error) {
+	arm, err := s.LockArmWithCapacity(tx, armID)
+	if err != nil {
+		return nil, err
+	}
+	return s.seatStudent(tx, studentID, arm, reason, userID)
+}
+
+// BulkPlaceStudents seats several students in one arm, all or nothing
+func (s *ClassMembershipService) BulkPlaceStudents(req *dto.BulkPlaceStudentsRequest, userID uuid.UUID) ([]dto.ClassMembershipResponse, error) {
+	armID, err := uuid.Parse(req.ArmID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+
+	studentIDs := make([]uuid.UUID, 0, len(req.StudentIDs))
+	for _, id := range req.StudentIDs {
+		studentID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, errors.New("invalid student ID: " + id)
+		}
+		studentIDs = append(studentIDs, studentID)
+	}
+
+	var found int64
+	if err := s.db.Model(&models.User{}).Where("id IN ? AND deleted_at IS NULL", studentIDs).Count(&found).Error; err != nil {
+		return nil, errors.New("failed to verify students: " + err.Error())
+	}
+	if int(found) != len(studentIDs) {
+		return nil, errors.New("one or more students not found")
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	arm, err := s.lockArm(tx, armID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	occupied, err := s.countOccupants(tx, arm.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if int(occupied)+len(studentIDs) > arm.Capacity {
+		tx.Rollback()
+		return nil, fmt.Errorf("arm capacity exceeded: %d of %d seats taken, %d requested", occupied, arm.Capacity, len(studentIDs))
+	}
+
+	ids := make([]uuid.UUID, 0, len(studentIDs))
+	for _, studentID := range studentIDs {
+		membership, err := s.seatStudent(tx, studentID, arm, "", userID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		ids = append(ids, membership.ID)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to place students: " + err.Error())
+	}
+
+	var memberships []models.ClassMembership
+	if err := s.db.Preload("Student").Preload("Arm").Where("id IN ?", ids).Find(&memberships).Error; err != nil {
+		return nil, errors.New("failed to load class memberships: " + err.Error())
+	}
+
+	responses := make([]dto.ClassMembershipResponse, len(memberships))
+	for i := range memberships {
+		responses[i] = *s.toMembershipResponse(&memberships[i])
+	}
+	return responses, nil
+}
+
+// TransferStudent moves an active membership to another arm in the same session
+func (s *ClassMembershipService) TransferStudent(id string, req *dto.TransferStudentRequest) (*dto.ClassMembershipResponse, error) {
+	membershipID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid class membership ID")
+	}
+	armID, err := uuid.Parse(req.ArmID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var membership models.ClassMembership
+	if err := tx.Where("id = ? AND deleted_at IS NULL", membershipID).First(&membership).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("class membership not found")
+		}
+		return nil, errors.New("failed to fetch class membership: " + err.Error())
+	}
+	if membership.Status != "active" {
+		tx.Rollback()
+		return nil, errors.New("only active class memberships can be transferred")
+	}
+	if membership.ArmID == armID {
+		tx.Rollback()
+		return nil, errors.New("student is already in this arm")
+	}
+
+	arm, err := s.LockArmWithCapacity(tx, armID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if arm.Grade.AcademicSessionID != membership.AcademicSessionID {
+		tx.Rollback()
+		return nil, errors.New("target arm belongs to a different academic session")
+	}
+
+	if err := tx.Model(&membership).Updates(map[string]interface{}{
+		"arm_id":     arm.ID,
+		"reason":     strings.TrimSpace(req.Reason),
+		"placed_at":  time.Now(),
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to transfer student: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to transfer student: " + err.Error())
+	}
+
+	return s.GetMembershipByID(membership.ID.String())
+}
+
+// WithdrawStudent frees the student's seat without deleting the membership history
+func (s *ClassMembershipService) WithdrawStudent(id string, req *dto.WithdrawStudentRequest) (*dto.ClassMembershipResponse, error) {
+	membershipID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid class membership ID")
+	}
+
+	var membership models.ClassMembership
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", membershipID).First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("class membership not found")
+		}
+		return nil, errors.New("failed to fetch class membership: " + err.Error())
+	}
+	if membership.Status != "active" {
+		return nil, errors.New("only active class memberships can be withdrawn")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&membership).Updates(map[string]interface{}{
+		"status":       "withdrawn",
+		"reason":       strings.TrimSpace(req.Reason),
+		"withdrawn_at": now,
+		"updated_at":   now,
+	}).Error; err != nil {
+		return nil, errors.New("failed to withdraw student: " + err.Error())
+	}
+
+	return s.GetMembershipByID(membership.ID.String())
+}
+
+// GetMembershipByID retrieves a single class membership
+func (s *ClassMembershipService) GetMembershipByID(id string) (*dto.ClassMembershipResponse, error) {
+	membershipID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid class membership ID")
+	}
+
+	var membership models.ClassMembership
+	if err := s.db.Preload("Student").Preload("Arm").
+		Where("id = ? AND deleted_at IS NULL", membershipID).
+		First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("class membership not found")
+		}
+		return nil, errors.New("failed to fetch class membership: " + err.Error())
+	}
+
+	return s.toMembershipResponse(&membership), nil
+}
+
+// GetClassList retrieves the students currently seated in an arm
+func (s *ClassMembershipService) GetClassList(armID string) (*dto.ClassListResponse, error) {
+	aID, err := uuid.Parse(armID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+
+	var arm models.Arm
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", aID).First(&arm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("arm not found")
+		}
+		return nil, errors.New("failed to fetch arm: " + err.Error())
+	}
+
+	var memberships []models.ClassMembership
+	if err := s.db.
+		Joins("JOIN users ON users.id = class_memberships.student_id").
+		Preload("Student").
+		Where("class_memberships.arm_id = ? AND class_memberships.status = ? AND class_memberships.deleted_at IS NULL", aID, "active").
+		Order("users.last_name ASC, users.first_name ASC").
+		Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch class list: %w", err)
+	}
+
+	students := make([]dto.ClassMembershipResponse, len(memberships))
+	for i := range memberships {
+		students[i] = *s.toMembershipResponse(&memberships[i])
+	}
+
+	available := arm.Capacity - len(memberships)
+	if available < 0 {
+		available = 0
+	}
+
+	return &dto.ClassListResponse{
+		ArmID:     arm.ID.String(),
+		ArmName:   arm.Name,
+		GradeID:   arm.GradeID.String(),
+		Capacity:  arm.Capacity,
+		Occupied:  len(memberships),
+		Available: available,
+		Students:  students,
+	}, nil
+}
+
+// GetStudentMemberships retrieves the placement history of a student across sessions
+func (s *ClassMembershipService) GetStudentMemberships(studentID string) ([]dto.ClassMembershipResponse, error) {
+	sID, err := uuid.Parse(studentID)
+	if err != nil {
+		return nil, errors.New("invalid student ID")
+	}
+
+	var memberships []models.ClassMembership
+	if err := s.db.Preload("Arm").
+		Where("student_id = ? AND deleted_at IS NULL", sID).
+		Order("placed_at DESC").
+		Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch class memberships: %w", err)
+	}
+
+	responses := make([]dto.ClassMembershipResponse, len(memberships))
+	for i := range memberships {
+		responses[i] = *s.toMembershipResponse(&memberships[i])
+	}
+	return responses, nil
+}
+
+// BalanceArms redistributes the active students of a grade evenly across its arms
+func (s *ClassMembershipService) BalanceArms(req *dto.BalanceArmsRequest) (*dto.BalanceArmsResponse, error) {
+	gradeID, err := uuid.Parse(req.GradeID)
+	if err != nil {
+		return nil, errors.New("invalid grade ID")
+	}
+
+	var grade models.ClassGrade
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", gradeID).First(&grade).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("grade not found")
+		}
+		return nil, errors.New("failed to verify grade: " + err.Error())
+	}
+
+	var arms []models.Arm
+	if err := s.db.Where("grade_id = ? AND status = ? AND deleted_at IS NULL", gradeID, "active").
+		Order("name ASC").Find(&arms).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch arms: %w", err)
+	}
+	if len(arms) < 2 {
+		return nil, errors.New("grade needs at least two active arms to balance")
+	}
+
+	armIDs := make([]uuid.UUID, len(arms))
+	totalCapacity := 0
+	for i, arm := range arms {
+		armIDs[i] = arm.ID
+		totalCapacity += arm.Capacity
+	}
+
+	var memberships []models.ClassMembership
+	if err := s.db.Preload("Student").
+		Where("arm_id IN ? AND academic_session_id = ? AND status = ? AND deleted_at IS NULL", armIDs, grade.AcademicSessionID, "active").
+		Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch class memberships: %w", err)
+	}
+	if len(memberships) > totalCapacity {
+		return nil, fmt.Errorf("arm capacity exceeded: %d students for %d seats", len(memberships), totalCapacity)
+	}
+
+	genders, err := s.studentGenders(memberships)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order students so the strategy is deterministic
+	sort.SliceStable(memberships, func(i, j int) bool {
+		a, b := memberships[i].Student, memberships[j].Student
+		if req.Strategy == "gender" {
+			ga, gb := genders[a.ID], genders[b.ID]
+			if ga != gb {
+				return ga < gb
+			}
+		}
+		if !strings.EqualFold(a.LastName, b.LastName) {
+			return strings.ToLower(a.LastName) < strings.ToLower(b.LastName)
+		}
+		return strings.ToLower(a.FirstName) < strings.ToLower(b.FirstName)
+	})
+
+	targets := balancedTargets(arms, len(memberships))
+	assigned := make([]uuid.UUID, len(memberships))
+	counts := make([]int, len(arms))
+
+	if req.Strategy == "surname" {
+		// Contiguous alphabetical blocks per arm
+		armIndex := 0
+		for i := range memberships {
+			for counts[armIndex] >= targets[armIndex] {
+				armIndex++
+			}
+			assigned[i] = arms[armIndex].ID
+			counts[armIndex]++
+		}
+	} else {
+		// Deal each gender group round-robin so every arm gets an even mix
+		next := 0
+		for i := range memberships {
+			for counts[next] >= targets[next] {
+				next = (next + 1) % len(arms)
+			}
+			assigned[i] = arms[next].ID
+			counts[next]++
+			next = (next + 1) % len(arms)
+		}
+	}
+
+	response := &dto.BalanceArmsResponse{
+		GradeID:     grade.ID.String(),
+		Strategy:    req.Strategy,
+		DryRun:      req.DryRun,
+		ArmCounts:   make(map[string]int, len(arms)),
+		Assignments: make([]dto.BalanceAssignment, len(memberships)),
+	}
+	for i, arm := range arms {
+		response.ArmCounts[arm.ID.String()] = counts[i]
+	}
+
+	for i, m := range memberships {
+		moved := m.ArmID != assigned[i]
+		if moved {
+			response.TotalMoved++
+		}
+		response.Assignments[i] = dto.BalanceAssignment{
+			StudentID:   m.StudentID.String(),
+			StudentName: strings.TrimSpace(m.Student.FirstName + " " + m.Student.LastName),
+			Gender:      genders[m.StudentID],
+			FromArmID:   m.ArmID.String(),
+			ToArmID:     assigned[i].String(),
+			Moved:       moved,
+		}
+	}
+
+	if req.DryRun || response.TotalMoved == 0 {
+		return response, nil
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	for i, m := range memberships {
+		if m.ArmID == assigned[i] {
+			continue
+		}
+		if err := tx.Model(&models.ClassMembership{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+			"arm_id":     assigned[i],
+			"reason":     "rebalanced by " + req.Strategy,
+			"placed_at":  now,
+			"updated_at": now,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to rebalance arms: " + err.Error())
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to rebalance arms: " + err.Error())
+	}
+
+	return response, nil
+}
+
+// balancedTargets spreads total students across arms as evenly as their capacities allow
+func balancedTargets(arms []models.Arm, total int) []int {
+	targets := make([]int, len(arms))
+	remaining := total
+	for remaining > 0 {
+		progressed := false
+		for i := range arms {
+			if remaining == 0 {
+				break
+			}
+			if targets[i] < arms[i].Capacity {
+				targets[i]++
+				remaining--
+				progressed = true
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+	return targets
+}
+
+// studentGenders reads genders from student profiles when that table is available
+func (s *ClassMembershipService) studentGenders(memberships []models.ClassMembership) (map[uuid.UUID]string, error) {
+	genders := make(map[uuid.UUID]string, len(memberships))
+	for _, m := range memberships {
+		genders[m.StudentID] = "unspecified"
+	}
+	if len(memberships) == 0 || !s.db.Migrator().HasTable(&models.StudentProfile{}) {
+		return genders, nil
+	}
+
+	studentIDs := make([]uuid.UUID, len(memberships))
+	for i, m := range memberships {
+		studentIDs[i] = m.StudentID
+	}
+
+	var profiles []models.StudentProfile
+	if err := s.db.Select("user_id", "gender").Where("user_id IN ?", studentIDs).Find(&profiles).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch student profiles: %w", err)
+	}
+	for _, p := range profiles {
+		if p.Gender != "" {
+			genders[p.UserID] = p.Gender
+		}
+	}
+	return genders, nil
+}
+
+// seatStudent creates or reactivates the student's membership for the arm's session
+func (s *ClassMembershipService) seatStudent(tx *gorm.DB, studentID uuid.UUID, arm *models.Arm, reason string, userID uuid.UUID) (*models.ClassMembership, error) {
+	now := time.Now()
+	sessionID := arm.Grade.AcademicSessionID
+
+	var existing models.ClassMembership
+	err := tx.Unscoped().Where("student_id = ? AND academic_session_id = ?", studentID, sessionID).First(&existing).Error
+	if err == nil {
+		if existing.Status == "active" && !existing.DeletedAt.Valid {
+			return nil, errors.New("student already exists in an arm for this academic session")
+		}
+		// Promoted, graduated and repeated records are the session's history, not a free seat
+		if existing.Status != "withdrawn" && !existing.DeletedAt.Valid {
+			return nil, fmt.Errorf("student already exists in an arm for this academic session (%s)", existing.Status)
+		}
+
+		// Reuse the withdrawn or deleted record so the unique index on student and session holds
+		if err := tx.Unscoped().Model(&existing).Updates(map[string]interface{}{
+			"arm_id":       arm.ID,
+			"status":       "active",
+			"reason":       reason,
+			"placed_at":    now,
+			"withdrawn_at": nil,
+			"deleted_at":   nil,
+			"updated_at":   now,
+		}).Error; err != nil {
+			return nil, errors.New("failed to place student: " + err.Error())
+		}
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to check existing placement: " + err.Error())
+	}
+
+	membership := &models.ClassMembership{
+		ID:                uuid.New(),
+		StudentID:         studentID,
+		ArmID:             arm.ID,
+		AcademicSessionID: sessionID,
+		Status:            "active",
+		Reason:            reason,
+		PlacedAt:          now,
+		CreatedBy:         userID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if err := tx.Create(membership).Error; err != nil {
+		return nil, errors.New("failed to place student: " + err.Error())
+	}
+	return membership, nil
+}
+
+// lockArm loads an active arm with its grade, holding a row lock until the transaction ends
+func (s *ClassMembershipService) lockArm(tx *gorm.DB, armID uuid.UUID) (*models.Arm, error) {
+	var arm models.Arm
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND deleted_at IS NULL", armID).
+		First(&arm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("arm not found")
+		}
+		return nil, errors.New("failed to fetch arm: " + err.Error())
+	}
+	if arm.Status != "active" {
+		return nil, errors.New("arm is not active")
+	}
+
+	if err := tx.Where("id = ?", arm.GradeID).First(&arm.Grade).Error; err != nil {
+		return nil, errors.New("failed to load arm grade: " + err.Error())
+	}
+	return &arm, nil
+}
+
+// LockArmWithCapacity locks the arm and ensures at least one seat is free. Services that
+// seat students themselves, such as promotions, call it inside their own transaction.
+func (s *ClassMembershipService) LockArmWithCapacity(tx *gorm.DB, armID uuid.UUID) (*models.Arm, error) {
+	arm, err := s.lockArm(tx, armID)
+	if err != nil {
+		return nil, err
+	}
+
+	occupied, err := s.countOccupants(tx, arm.ID)
+	if err != nil {
+		return nil, err
+	}
+	if int(occupied) >= arm.Capacity {
+		return nil, fmt.Errorf("arm capacity exceeded: all %d seats are taken", arm.Capacity)
+	}
+	return arm, nil
+}
+
+// countOccupants counts the active memberships of an arm
+func (s *ClassMembershipService) countOccupants(tx *gorm.DB, armID uuid.UUID) (int64, error) {
+	var occupied int64
+	if err := tx.Model(&models.ClassMembership{}).
+		Where("arm_id = ? AND status = ? AND deleted_at IS NULL", armID, "active").
+		Count(&occupied).Error; err != nil {
+		return 0, errors.New("failed to check arm capacity: " + err.Error())
+	}
+	return occupied, nil
+}
+
+// toMembershipResponse converts model to response DTO
+func (s *ClassMembershipService) toMembershipResponse(m *models.ClassMembership) *dto.ClassMembershipResponse {
+	response := &dto.ClassMembershipResponse{
+		ID:                m.ID.String(),
+		StudentID:         m.StudentID.String(),
+		ArmID:             m.ArmID.String(),
+		AcademicSessionID: m.AcademicSessionID.String(),
+		Status:            m.Status,
+		Reason:            m.Reason,
+		PlacedAt:          m.PlacedAt,
+		WithdrawnAt:       m.WithdrawnAt,
+		CreatedBy:         m.CreatedBy.String(),
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}
+
+	if m.Student.ID != uuid.Nil {
+		response.Student = &dto.UserResponse{
+			ID:         m.Student.ID.String(),
+			FirstName:  m.Student.FirstName,
+			LastName:   m.Student.LastName,
+			MiddleName: m.Student.MiddleName,
+			FullName:   strings.TrimSpace(m.Student.FirstName + " " + m.Student.LastName),
+			Email:      m.Student.Email,
+			Phone:      m.Student.Phone,
+			Role:       m.Student.Role,
+			Picture:    m.Student.Picture,
+			IsVerified: m.Student.IsVerified,
+			IsActive:   m.Student.IsActive,
+			CreatedAt:  m.Student.CreatedAt,
+			UpdatedAt:  m.Student.UpdatedAt,
+		}
+	}
+
+	if m.Arm.ID != uuid.Nil {
+		response.Arm = &dto.ArmResponse{
+			ID:          m.Arm.ID.String(),
+			Name:        m.Arm.Name,
+			Description: m.Arm.Description,
+			Code:        m.Arm.Code,
+			GradeID:     m.Arm.GradeID.String(),
+			Status:      m.Arm.Status,
+			Capacity:    m.Arm.Capacity,
+			CreatedBy:   m.Arm.CreatedBy.String(),
+			CreatedAt:   m.Arm.CreatedAt,
+			UpdatedAt:   m.Arm.UpdatedAt,
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,352 @@
+// services/clone.go
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+	rollupServices "crm-go/services/rollup"
+)
+
+// cloneBatchSize keeps multi-row inserts well under Postgres' parameter limit
+const cloneBatchSize = 100
+
+// courseCloner deep-copies a course tree inside a transaction. Every copied row gets a
+// fresh ID up front so children can be pointed at their new parents before insert.
+type courseCloner struct {
+	tx      *gorm.DB
+	source  *models.Course
+	target  *models.Course
+	ids     map[uuid.UUID]uuid.UUID
+	summary dto.CloneSummary
+}
+
+// cloneCourse copies a course with its modules, lessons, topics, materials, assignments,
+// objective questions with options, and module release rules. Categories are copied only
+// for standalone copies; versions take over the live course's links when published.
+func cloneCourse(tx *gorm.DB, source *models.Course, title string, tutorID uuid.UUID, withCategories bool) (*courseCloner, error) {
+	c := &courseCloner{
+		tx:     tx,
+		source: source,
+		ids:    make(map[uuid.UUID]uuid.UUID),
+	}
+
+	c.target = &models.Course{
+		ID:               uuid.New(),
+		Title:            title,
+		Description:      source.Description,
+		Image:            source.Image,
+		VideoURL:         source.VideoURL,
+		TutorID:          tutorID,
+		LearningOutcomes: source.LearningOutcomes,
+		Requirements:     source.Requirements,
+	}
+	if err := tx.Omit(clause.Associations).Create(c.target).Error; err != nil {
+		return nil, errors.New("failed to copy course: " + err.Error())
+	}
+	c.ids[source.ID] = c.target.ID
+
+	steps := []func() error{c.modules, c.lessons, c.topics, c.materials, c.assignments, c.questions, c.releaseRules}
+	if withCategories {
+		steps = append(steps, c.categories)
+	}
+	steps = append(steps, c.totals)
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// remap returns the copy's ID for a source ID, or uuid.Nil when the source row was not copied
+func (c *courseCloner) remap(id uuid.UUID) uuid.UUID {
+	return c.ids[id]
+}
+
+// remapPtr is remap for optional references
+func (c *courseCloner) remapPtr(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+	mapped, ok := c.ids[*id]
+	if !ok {
+		return nil
+	}
+	return &mapped
+}
+
+// idMap returns the source to copy ID mapping for the response
+func (c *courseCloner) idMap() map[string]string {
+	out := make(map[string]string, len(c.ids))
+	for from, to := range c.ids {
+		out[from.String()] = to.String()
+	}
+	return out
+}
+
+func (c *courseCloner) modules() error {
+	var modules []models.Module
+	if err := c.tx.Where("course_id = ?", c.source.ID).Order("module_number ASC").Find(&modules).Error; err != nil {
+		return errors.New("failed to fetch modules: " + err.Error())
+	}
+	if len(modules) == 0 {
+		return nil
+	}
+
+	for i := range modules {
+		newID := uuid.New()
+		c.ids[modules[i].ID] = newID
+		modules[i].ID = newID
+		modules[i].CourseID = c.target.ID
+		modules[i].CreatedAt, modules[i].UpdatedAt = c.target.CreatedAt, c.target.CreatedAt
+	}
+	if err := c.tx.Omit(clause.Associations).CreateInBatches(&modules, cloneBatchSize).Error; err != nil {
+		return errors.New("failed to copy modules: " + err.Error())
+	}
+	c.summary.Modules = len(modules)
+	return nil
+}
+
+func (c *courseCloner) lessons() error {
+	var lessons []models.Lesson
+	if err := c.tx.Where("course_id = ?", c.source.ID).Order(`"order" ASC`).Find(&lessons).Error; err != nil {
+		return errors.New("failed to fetch lessons: " + err.Error())
+	}
+
+	copies := make([]models.Lesson, 0, len(lessons))
+	for _, lesson := range lessons {
+		moduleID := c.remap(lesson.ModuleID)
+		if moduleID == uuid.Nil {
+			continue // orphaned lesson; its module is not part of this course
+		}
+		newID := uuid.New()
+		c.ids[lesson.ID] = newID
+		copies = append(copies, models.Lesson{
+			ID:          newID,
+			CourseID:    c.target.ID,
+			ModuleID:    moduleID,
+			TutorID:     c.target.TutorID,
+			Title:       lesson.Title,
+			Description: lesson.Description,
+			Order:       lesson.Order,
+		})
+	}
+	if len(copies) == 0 {
+		return nil
+	}
+	if err := c.tx.Omit(clause.Associations).CreateInBatches(&copies, cloneBatchSize).Error; err != nil {
+		return errors.New("failed to copy lessons: " + err.Error())
+	}
+	c.summary.Lessons = len(copies)
+	return nil
+}
+
+func (c *courseCloner) topics() error {
+	var topics []models.Topics
+	if err := c.tx.Where("course_id = ?", c.source.ID).Order(`"order" ASC`).Find(&topics).Error; err != nil {
+		return errors.New("failed to fetch topics: " + err.Error())
+	}
+
+	copies := make([]models.Topics, 0, len(topics))
+	for _, topic := range topics {
+		moduleID := c.remap(topic.ModuleID)
+		if moduleID == uuid.Nil {
+			continue
+		}
+		newID := uuid.New()
+		c.ids[topic.ID] = newID
+		copies = append(copies, models.Topics{
+			ID:          newID,
+			CourseID:    c.target.ID,
+			ModuleID:    moduleID,
+			LessonID:    c.remap(topic.LessonID),
+			TutorID:     c.target.TutorID,
+			Title:       topic.Title,
+			ContentType: topic.ContentType,
+			ContentURL:  topic.ContentURL,
+			ContentText: topic.ContentText,
+			Order:       topic.Order,
+
+			EstimatedDuration: topic.EstimatedDuration,
+			VideoDuration:     topic.VideoDuration,
+			Duration:          topic.Duration,
+		})
+	}
+	if len(copies) == 0 {
+		return nil
+	}
+	if err := c.tx.Omit(clause.Associations).CreateInBatches(&copies, cloneBatchSize).Error; err != nil {
+		return errors.New("failed to copy topics: " + err.Error())
+	}
+	c.summary.Topics = len(copies)
+	return nil
+}
+
+func (c *courseCloner) materials() error {
+	var materials []models.CourseMaterial
+	if err := c.tx.Where("course_id = ?", c.source.ID).Find(&materials).Error; err != nil {
+		return errors.New("failed to fetch course materials: " + err.Error())
+	}
+	if len(materials) == 0 {
+		return nil
+	}
+
+	for i := range materials {
+		newID := uuid.New()
+		c.ids[materials[i].ID] = newID
+		materials[i].ID = newID
+		materials[i].CourseID = c.target.ID
+		materials[i].CreatedAt = c.target.CreatedAt
+	}
+	if err := c.tx.Omit(clause.Associations).CreateInBatches(&materials, cloneBatchSize).Error; err != nil {
+		return errors.New("failed to copy course materials: " + err.Error())
+	}
+	c.summary.Materials = len(materials)
+	return nil
+}
+
+func (c *courseCloner) assignments() error {
+	var assignments []models.Assignment
+	if err := c.tx.Where("course_id = ?", c.source.ID).Find(&assignments).Error; err != nil {
+		return errors.New("failed to fetch assignments: " + err.Error())
+	}
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	// Assignment slugs are unique across courses, so copies carry the new course's prefix
+	suffix := "-" + strings.SplitN(c.target.ID.String(), "-", 2)[0]
+	for i := range assignments {
+		newID := uuid.New()
+		c.ids[assignments[i].ID] = newID
+		assignments[i].ID = newID
+		assignments[i].CourseID = c.target.ID
+		assignments[i].ModuleID = c.remapPtr(assignments[i].ModuleID)
+		assignments[i].TopicID = c.remapPtr(assignments[i].TopicID)
+		slug := assignments[i].Slug
+		if len(slug)+len(suffix) > 300 {
+			slug = slug[:300-len(suffix)]
+		}
+		assignments[i].Slug = slug + suffix
+		assignments[i].CreatedAt, assignments[i].UpdatedAt = c.target.CreatedAt, c.target.CreatedAt
+	}
+	if err := c.tx.Omit(clause.Associations).CreateInBatches(&assignments, cloneBatchSize).Error; err != nil {
+		return errors.New("failed to copy assignments: " + err.Error())
+	}
+	c.summary.Assignments = len(assignments)
+	return nil
+}
+
+func (c *courseCloner) questions() error {
+	var questions []models.ObjectiveQuestion
+	if err := c.tx.Preload("Options").Where("course_id = ?", c.source.ID).Find(&questions).Error; err != nil {
+		return errors.New("failed to fetch objective questions: " + err.Error())
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+
+	var options []models.QuestionOption
+	for i := range questions {
+		newID := uuid.New()
+		c.ids[questions[i].ID] = newID
+		for _, option := range questions[i].Options {
+			optionID := uuid.New()
+			c.ids[option.ID] = optionID
+			options = append(options, models.QuestionOption{
+				ID:          optionID,
+				QuestionID:  newID,
+				OptionText:  option.OptionText,
+				IsCorrect:   option.IsCorrect,
+				Explanation: option.Explanation,
+				SortOrder:   option.SortOrder,
+			})
+		}
+		questions[i].ID = newID
+		questions[i].CourseID = c.target.ID
+		questions[i].ModuleID = c.remap(questions[i].ModuleID)
+		questions[i].LessonID = c.remap(questions[i].LessonID)
+		questions[i].TutorID = c.target.TutorID
+		questions[i].Options = nil
+		questions[i].CreatedAt, questions[i].UpdatedAt = c.target.CreatedAt, c.target.CreatedAt
+	}
+	if err := c.tx.Omit(clause.Associations).CreateInBatches(&questions, cloneBatchSize).Error; err != nil {
+		return errors.New("failed to copy objective questions: " + err.Error())
+	}
+	if len(options) > 0 {
+		if err := c.tx.Omit(clause.Associations).CreateInBatches(&options, cloneBatchSize).Error; err != nil {
+			return errors.New("failed to copy question options: " + err.Error())
+		}
+	}
+	c.summary.Questions = len(questions)
+	c.summary.Options = len(options)
+	return nil
+}
+
+func (c *courseCloner) releaseRules() error {
+	var rules []models.ModuleReleaseRule
+	if err := c.tx.Where("course_id = ?", c.source.ID).Find(&rules).Error; err != nil {
+		return errors.New("failed to fetch release rules: " + err.Error())
+	}
+
+	copies := make([]models.ModuleReleaseRule, 0, len(rules))
+	for _, rule := range rules {
+		moduleID := c.remap(rule.ModuleID)
+		if moduleID == uuid.Nil {
+			continue
+		}
+		copies = append(copies, models.ModuleReleaseRule{
+			ModuleID:                 moduleID,
+			CourseID:                 c.target.ID,
+			RuleType:                 rule.RuleType,
+			DelayDays:                rule.DelayDays,
+			ReleaseAt:                rule.ReleaseAt,
+			PrerequisiteModuleID:     c.remapPtr(rule.PrerequisiteModuleID),
+			PrerequisiteAssignmentID: c.remapPtr(rule.PrerequisiteAssignmentID),
+			MinScore:                 rule.MinScore,
+			CreatedBy:                rule.CreatedBy,
+		})
+	}
+	if len(copies) == 0 {
+		return nil
+	}
+	if err := c.tx.Omit(clause.Associations).CreateInBatches(&copies, cloneBatchSize).Error; err != nil {
+		return errors.New("failed to copy release rules: " + err.Error())
+	}
+	c.summary.ReleaseRules = len(copies)
+	return nil
+}
+
+// totals rolls the copied content up into the copy's module and course totals
+func (c *courseCloner) totals() error {
+	return rollupServices.RecomputeCourses(c.tx, c.target.ID)
+}
+
+func (c *courseCloner) categories() error {
+	var links []models.CourseCategoryTable
+	if err := c.tx.Where("course_id = ?", c.source.ID).Find(&links).Error; err != nil {
+		return errors.New("failed to fetch course categories: " + err.Error())
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	copies := make([]models.CourseCategoryTable, 0, len(links))
+	for _, link := range links {
+		copies = append(copies, models.CourseCategoryTable{
+			CourseID:   c.target.ID,
+			CategoryID: link.CategoryID,
+		})
+	}
+	if err := c.tx.Create(&copies).Error; err != nil {
+		return errors.New("failed to copy course categories: " + err.Error())
+	}
+	return nil
+}
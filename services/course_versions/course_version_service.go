@@ -0,0 +1,497 @@
+// services/course_version_service.go
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+	publishingServices "crm-go/services/publishing"
+)
+
+type CourseVersionService struct {
+	db *gorm.DB
+}
+
+func NewCourseVersionService(db *gorm.DB) *CourseVersionService {
+	return &CourseVersionService{db: db}
+}
+
+// CloneCourse deep-copies a course into a new, independent course, e.g. to rerun it for a new
+// cohort. canEdit is whether the user may edit the source course, and canAssignTutor whether
+// they manage every course and so may give the copy to another tutor instead of keeping it.
+func (s *CourseVersionService) CloneCourse(courseID string, req *dto.CloneCourseRequest, userID uuid.UUID, canEdit bool, canAssignTutor bool) (*dto.CloneCourseResponse, error) {
+	source, err := s.loadCourse(s.db, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("not authorized to copy this course")
+	}
+
+	tutorID := source.TutorID
+	if !canAssignTutor {
+		tutorID = userID
+	} else if req.TutorID != "" {
+		if tutorID, err = s.validateTutor(req.TutorID); err != nil {
+			return nil, err
+		}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = source.Title + " (Copy)"
+	}
+	var count int64
+	if err := s.db.Model(&models.Course{}).Where("title = ?", title).Count(&count).Error; err != nil {
+		return nil, errors.New("failed to check course title: " + err.Error())
+	}
+	if count > 0 {
+		return nil, errors.New("a course with this title already exists")
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	cloner, err := cloneCourse(tx, source, title, tutorID, true)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	return &dto.CloneCourseResponse{
+		CourseID:       cloner.target.ID.String(),
+		SourceCourseID: source.ID.String(),
+		Title:          cloner.target.Title,
+		TutorID:        cloner.target.TutorID.String(),
+		Copied:         cloner.summary,
+		IDMap:          cloner.idMap(),
+	}, nil
+}
+
+// CreateVersion copies the published version of a course into a draft that can be edited
+// through the usual course endpoints without touching what enrolled students see
+func (s *CourseVersionService) CreateVersion(courseID string, req *dto.CreateCourseVersionRequest, userID uuid.UUID, canEdit bool) (*dto.CourseVersionResponse, error) {
+	source, err := s.loadCourse(s.db, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("not authorized to version this course")
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	current, err := s.ensureVersion(tx, source, userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if current.Status != "published" {
+		tx.Rollback()
+		return nil, errors.New("only the published version of a course can be copied into a draft")
+	}
+
+	var versions []models.CourseVersion
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("lineage_id = ?", current.LineageID).
+		Order("version_number ASC").
+		Find(&versions).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to fetch course versions: " + err.Error())
+	}
+	next := 1
+	for _, version := range versions {
+		if version.Status == "draft" || version.Status == "in_review" {
+			tx.Rollback()
+			return nil, errors.New("a draft version of this course already exists")
+		}
+		if version.VersionNumber >= next {
+			next = version.VersionNumber + 1
+		}
+	}
+
+	cloner, err := cloneCourse(tx, source, source.Title, source.TutorID, false)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	version := models.CourseVersion{
+		LineageID:      current.LineageID,
+		CourseID:       cloner.target.ID,
+		SourceCourseID: &source.ID,
+		VersionNumber:  next,
+		Status:         "draft",
+		Notes:          req.Notes,
+		CreatedBy:      userID,
+	}
+	if err := tx.Omit(clause.Associations).Create(&version).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create course version: " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	response, err := s.getVersion(version.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.Copied = &cloner.summary
+	return response, nil
+}
+
+// GetVersions lists every version in the lineage the course belongs to
+func (s *CourseVersionService) GetVersions(courseID string, userID uuid.UUID, canEdit bool) ([]dto.CourseVersionResponse, error) {
+	course, err := s.loadCourse(s.db, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("not authorized to view this course's versions")
+	}
+
+	lineageID := course.ID
+	var current models.CourseVersion
+	err = s.db.Where("course_id = ?", course.ID).First(&current).Error
+	if err == nil {
+		lineageID = current.LineageID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to fetch course version: " + err.Error())
+	}
+
+	var versions []models.CourseVersion
+	if err := s.db.Preload("Course").
+		Where("lineage_id = ?", lineageID).
+		Order("version_number DESC").
+		Find(&versions).Error; err != nil {
+		return nil, errors.New("failed to fetch course versions: " + err.Error())
+	}
+
+	responses := make([]dto.CourseVersionResponse, 0, len(versions))
+	for i := range versions {
+		response, err := s.toResponse(&versions[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// GetVersionByID returns a single course version
+func (s *CourseVersionService) GetVersionByID(id string, userID uuid.UUID, canEdit bool) (*dto.CourseVersionResponse, error) {
+	version, err := s.loadVersion(s.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("not authorized to manage this course version")
+	}
+	return s.getVersion(version.ID)
+}
+
+// SubmitVersion sends a draft version for review
+func (s *CourseVersionService) SubmitVersion(id string, userID uuid.UUID, canEdit bool) (*dto.CourseVersionResponse, error) {
+	version, err := s.loadVersion(s.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("not authorized to manage this course version")
+	}
+	if version.Status != "draft" {
+		return nil, errors.New("only draft versions can be submitted for review")
+	}
+	if err := s.requireChecks(s.db, version.CourseID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.db.Model(version).Updates(map[string]interface{}{
+		"status":       "in_review",
+		"submitted_at": now,
+	}).Error; err != nil {
+		return nil, errors.New("failed to submit course version: " + err.Error())
+	}
+	return s.getVersion(version.ID)
+}
+
+// PublishVersion makes a reviewed version the live course. The previous version is
+// superseded but kept, so its enrolled students finish on the content they started;
+// product and category links move to the new version so new enrollments land on it.
+func (s *CourseVersionService) PublishVersion(id string, adminID uuid.UUID) (*dto.CourseVersionResponse, error) {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	version, err := s.loadVersion(tx, id, true)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if version.Status != "in_review" {
+		tx.Rollback()
+		return nil, errors.New("only versions in review can be published")
+	}
+	if err := s.requireChecks(tx, version.CourseID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var live models.CourseVersion
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("lineage_id = ? AND status = ?", version.LineageID, "published").
+		First(&live).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, errors.New("failed to fetch published version: " + err.Error())
+	}
+
+	now := time.Now()
+	if err == nil {
+		if err := tx.Model(&live).Update("status", "superseded").Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to supersede published version: " + err.Error())
+		}
+		if err := tx.Model(&models.CourseProductTable{}).
+			Where("course_id = ?", live.CourseID).
+			Update("course_id", version.CourseID).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to move course products: " + err.Error())
+		}
+		if err := tx.Model(&models.CourseCategoryTable{}).
+			Where("course_id = ?", live.CourseID).
+			Update("course_id", version.CourseID).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to move course categories: " + err.Error())
+		}
+		// The old copy leaves listings but stays open to the students enrolled on it
+		if err := tx.Model(&models.Course{}).Where("id = ? AND status = ?", live.CourseID, "published").Updates(map[string]interface{}{
+			"status":      "archived",
+			"archived_at": now,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to archive previous version: " + err.Error())
+		}
+	}
+
+	if err := tx.Model(&models.Course{}).Where("id = ?", version.CourseID).Updates(map[string]interface{}{
+		"status":       "published",
+		"published_at": now,
+		"archived_at":  nil,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to publish course: " + err.Error())
+	}
+	if err := tx.Model(version).Updates(map[string]interface{}{
+		"status":       "published",
+		"published_at": now,
+		"published_by": adminID,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to publish course version: " + err.Error())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+	return s.getVersion(version.ID)
+}
+
+// DiscardVersion abandons a draft or in-review version; its course copy stays hidden
+func (s *CourseVersionService) DiscardVersion(id string, userID uuid.UUID, canEdit bool) (*dto.CourseVersionResponse, error) {
+	version, err := s.loadVersion(s.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("not authorized to manage this course version")
+	}
+	if version.Status != "draft" && version.Status != "in_review" {
+		return nil, errors.New("only draft or in-review versions can be discarded")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(version).Updates(map[string]interface{}{
+		"status":       "discarded",
+		"discarded_at": now,
+	}).Error; err != nil {
+		return nil, errors.New("failed to discard course version: " + err.Error())
+	}
+	return s.getVersion(version.ID)
+}
+
+// requireChecks fails with the first unmet course publish check
+func (s *CourseVersionService) requireChecks(db *gorm.DB, courseID uuid.UUID) error {
+	checks, err := publishingServices.NewPublishingService(s.db).CourseChecks(db, courseID)
+	if err != nil {
+		return err
+	}
+	for _, check := range checks.Checks {
+		if check.Passed || check.Name == "price" {
+			continue // products move over from the live version on publish
+		}
+		return errors.New("publish check failed: " + check.Message)
+	}
+	return nil
+}
+
+// HiddenCourseIDs selects courses that are unpublished or superseded versions, for
+// excluding them from public listings
+func HiddenCourseIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.CourseVersion{}).Select("course_id").Where("status <> ?", "published")
+}
+
+// ensureVersion returns the version row for a course, recording a course that predates
+// versioning as version 1 of its own lineage
+func (s *CourseVersionService) ensureVersion(tx *gorm.DB, course *models.Course, userID uuid.UUID) (*models.CourseVersion, error) {
+	var version models.CourseVersion
+	err := tx.Where("course_id = ?", course.ID).First(&version).Error
+	if err == nil {
+		return &version, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to fetch course version: " + err.Error())
+	}
+
+	publishedAt := course.CreatedAt
+	version = models.CourseVersion{
+		LineageID:     course.ID,
+		CourseID:      course.ID,
+		VersionNumber: 1,
+		Status:        "published",
+		CreatedBy:     userID,
+		PublishedAt:   &publishedAt,
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(&version).Error; err != nil {
+		return nil, errors.New("failed to record course version: " + err.Error())
+	}
+	if err := tx.Where("course_id = ?", course.ID).First(&version).Error; err != nil {
+		return nil, errors.New("failed to fetch course version: " + err.Error())
+	}
+	return &version, nil
+}
+
+// validateTutor checks a user ID belongs to a tutor
+func (s *CourseVersionService) validateTutor(tutorID string) (uuid.UUID, error) {
+	id, err := uuid.Parse(tutorID)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid tutor ID")
+	}
+	var user models.User
+	if err := s.db.Select("id, role").Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, errors.New("tutor not found")
+		}
+		return uuid.Nil, errors.New("failed to fetch tutor: " + err.Error())
+	}
+	if user.Role != "tutor" {
+		return uuid.Nil, errors.New("tutor_id must belong to a tutor")
+	}
+	return id, nil
+}
+
+func (s *CourseVersionService) loadCourse(db *gorm.DB, courseID string) (*models.Course, error) {
+	id, err := uuid.Parse(courseID)
+	if err != nil {
+		return nil, errors.New("invalid course ID")
+	}
+	var course models.Course
+	if err := db.Where("id = ?", id).First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course not found")
+		}
+		return nil, errors.New("failed to fetch course: " + err.Error())
+	}
+	return &course, nil
+}
+
+func (s *CourseVersionService) loadVersion(db *gorm.DB, id string, lock bool) (*models.CourseVersion, error) {
+	versionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid version ID")
+	}
+	query := db
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var version models.CourseVersion
+	if err := query.Where("id = ?", versionID).First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course version not found")
+		}
+		return nil, errors.New("failed to fetch course version: " + err.Error())
+	}
+	return &version, nil
+}
+
+func (s *CourseVersionService) getVersion(id uuid.UUID) (*dto.CourseVersionResponse, error) {
+	var version models.CourseVersion
+	if err := s.db.Preload("Course").Where("id = ?", id).First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course version not found")
+		}
+		return nil, errors.New("failed to fetch course version: " + err.Error())
+	}
+	return s.toResponse(&version)
+}
+
+func (s *CourseVersionService) toResponse(version *models.CourseVersion) (*dto.CourseVersionResponse, error) {
+	var enrollments int64
+	if err := s.db.Model(&models.Enrollment{}).
+		Where("course_id = ? AND status IN ?", version.CourseID, []string{"active", "completed"}).
+		Count(&enrollments).Error; err != nil {
+		return nil, errors.New("failed to count enrollments: " + err.Error())
+	}
+
+	response := &dto.CourseVersionResponse{
+		ID:            version.ID.String(),
+		LineageID:     version.LineageID.String(),
+		CourseID:      version.CourseID.String(),
+		CourseTitle:   version.Course.Title,
+		VersionNumber: version.VersionNumber,
+		Status:        version.Status,
+		Notes:         version.Notes,
+		CreatedBy:     version.CreatedBy.String(),
+		SubmittedAt:   version.SubmittedAt,
+		PublishedAt:   version.PublishedAt,
+		DiscardedAt:   version.DiscardedAt,
+		Enrollments:   enrollments,
+		CreatedAt:     version.CreatedAt,
+		UpdatedAt:     version.UpdatedAt,
+	}
+	if version.SourceCourseID != nil {
+		sourceID := version.SourceCourseID.String()
+		response.SourceCourseID = &sourceID
+	}
+	if version.PublishedBy != nil {
+		publishedBy := version.PublishedBy.String()
+		response.PublishedBy = &publishedBy
+	}
+	return response, nil
+}
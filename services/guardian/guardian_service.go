@@ -72,7 +72,11 @@ func (s *GuardianService) CreateGuardian(req *dto.CreateGuardianRequest, userID
 
 	parts := strings.SplitN(emailTrim, "@", 2)
 
-	loginID := parts[0]
+	// Login IDs are stored hashed like passwords
+	hashedLoginID, err := bcrypt.GenerateFromPassword([]byte(parts[0]), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("failed to hash login ID: " + err.Error())
+	}
 
 	guardianUser := &models.User{
 		ID:         uuid.New(),
@@ -81,8 +85,8 @@ func (s *GuardianService) CreateGuardian(req *dto.CreateGuardianRequest, userID
 		MiddleName: strings.TrimSpace(req.MiddleName),
 		Email:      strings.ToLower(strings.TrimSpace(req.Email)),
 		Password:   string(hashedPassword),
-		LoginID:    loginID,
-		Role:       "parent",
+		LoginID:    string(hashedLoginID),
+		Role:       "guardian",
 		Phone:      strings.TrimSpace(req.Phone),
 		Picture:    "",
 		Provider:   "local",
@@ -0,0 +1,455 @@
+// services/guardian_portal_service.go
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+	billingServices "crm-go/services/billing"
+	attendanceServices "crm-go/services/class_attendance"
+)
+
+const dateLayout = "2006-01-02"
+
+type GuardianPortalService struct {
+	db         *gorm.DB
+	attendance *attendanceServices.ClassAttendanceService
+	billing    *billingServices.BillingService
+}
+
+func NewGuardianPortalService(db *gorm.DB) *GuardianPortalService {
+	return &GuardianPortalService{
+		db:         db,
+		attendance: attendanceServices.NewClassAttendanceService(db),
+		billing:    billingServices.NewBillingService(db),
+	}
+}
+
+// GetWards lists the students the guardian is actively linked to
+func (s *GuardianPortalService) GetWards(guardianUserID uuid.UUID) ([]dto.WardResponse, error) {
+	var links []models.Guardian
+	if err := s.db.Preload("Student").
+		Where("user_id = ? AND status = ? AND deleted_at IS NULL", guardianUserID, "active").
+		Order("is_primary DESC, created_at ASC").
+		Find(&links).Error; err != nil {
+		return nil, errors.New("failed to fetch wards: " + err.Error())
+	}
+
+	wards := make([]dto.WardResponse, 0, len(links))
+	for _, link := range links {
+		ward := dto.WardResponse{
+			StudentID:    link.StudentID.String(),
+			Relationship: link.Relationship,
+			IsPrimary:    link.IsPrimary,
+			IsEmergency:  link.IsEmergency,
+		}
+		if link.Student.ID != uuid.Nil {
+			ward.Student = toUserResponse(&link.Student)
+		}
+
+		arm, err := s.currentArm(link.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		ward.CurrentArm = arm
+
+		wards = append(wards, ward)
+	}
+
+	return wards, nil
+}
+
+// GetWardGrades lists a ward's grades within a term
+func (s *GuardianPortalService) GetWardGrades(studentID string, params *dto.WardQueryParams) ([]dto.WardGradeResponse, error) {
+	wardID, err := uuid.Parse(studentID)
+	if err != nil {
+		return nil, errors.New("invalid student ID")
+	}
+	_, from, to, err := s.resolveTerm(params)
+	if err != nil {
+		return nil, err
+	}
+
+	grades, err := s.gradesBetween(wardID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WardGradeResponse, 0, len(grades))
+	for _, grade := range grades {
+		response := dto.WardGradeResponse{
+			ID:          grade.ID.String(),
+			CourseID:    grade.CourseID.String(),
+			CourseTitle: grade.Course.Title,
+			Score:       grade.Score,
+			Grade:       grade.Grade,
+			Remarks:     grade.Remarks,
+			CreatedAt:   grade.CreatedAt,
+		}
+		if grade.AssignmentID != nil {
+			assignmentID := grade.AssignmentID.String()
+			response.AssignmentID = &assignmentID
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// GetWardReportCard builds a term report card from grades and attendance
+func (s *GuardianPortalService) GetWardReportCard(studentID string, params *dto.WardQueryParams) (*dto.ReportCardResponse, error) {
+	wardID, err := uuid.Parse(studentID)
+	if err != nil {
+		return nil, errors.New("invalid student ID")
+	}
+
+	var student models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", wardID).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("student not found")
+		}
+		return nil, errors.New("failed to fetch student: " + err.Error())
+	}
+
+	session, from, to, err := s.resolveTerm(params)
+	if err != nil {
+		return nil, err
+	}
+
+	grades, err := s.gradesBetween(wardID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Average each course's assessments
+	type courseTotal struct {
+		title string
+		sum   float64
+		count int
+	}
+	totals := make(map[uuid.UUID]*courseTotal)
+	for _, grade := range grades {
+		total, ok := totals[grade.CourseID]
+		if !ok {
+			total = &courseTotal{title: grade.Course.Title}
+			totals[grade.CourseID] = total
+		}
+		total.sum += grade.Score
+		total.count++
+	}
+
+	lines := make([]dto.ReportCardLine, 0, len(totals))
+	overall := 0.0
+	for courseID, total := range totals {
+		average := roundTo2(total.sum / float64(total.count))
+		overall += average
+		lines = append(lines, dto.ReportCardLine{
+			CourseID:     courseID.String(),
+			CourseTitle:  total.title,
+			Assessments:  total.count,
+			AverageScore: average,
+			Grade:        letterGrade(average),
+		})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].CourseTitle < lines[j].CourseTitle })
+	if len(lines) > 0 {
+		overall = roundTo2(overall / float64(len(lines)))
+	}
+
+	attendance, err := s.attendance.GetStudentSummary(wardID.String(), &dto.AttendanceSummaryQueryParams{
+		AcademicSessionID: session.ID.String(),
+		From:              from.Format(dateLayout),
+		To:                to.Format(dateLayout),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	arm, err := s.currentArm(wardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReportCardResponse{
+		StudentID:         wardID.String(),
+		StudentName:       strings.TrimSpace(student.FirstName + " " + student.LastName),
+		AcademicSessionID: session.ID.String(),
+		From:              from.Format(dateLayout),
+		To:                to.Format(dateLayout),
+		Arm:               arm,
+		Lines:             lines,
+		OverallAverage:    overall,
+		Attendance:        *attendance,
+	}, nil
+}
+
+// GetWardAttendance returns a ward's attendance totals for a term
+func (s *GuardianPortalService) GetWardAttendance(studentID string, params *dto.WardQueryParams) (*dto.StudentAttendanceSummary, error) {
+	return s.attendance.GetStudentSummary(studentID, &dto.AttendanceSummaryQueryParams{
+		AcademicSessionID: params.AcademicSessionID,
+		From:              params.From,
+		To:                params.To,
+	})
+}
+
+// GetWardAssignments lists assignments for the ward's active courses with submission status
+func (s *GuardianPortalService) GetWardAssignments(studentID string) ([]dto.WardAssignmentResponse, error) {
+	wardID, err := uuid.Parse(studentID)
+	if err != nil {
+		return nil, errors.New("invalid student ID")
+	}
+
+	var courseIDs []uuid.UUID
+	if err := s.db.Model(&models.Enrollment{}).
+		Where("student_id = ? AND status IN ?", wardID, []string{"active", "completed"}).
+		Pluck("course_id", &courseIDs).Error; err != nil {
+		return nil, errors.New("failed to fetch enrollments: " + err.Error())
+	}
+	if len(courseIDs) == 0 {
+		return []dto.WardAssignmentResponse{}, nil
+	}
+
+	var assignments []models.Assignment
+	if err := s.db.Preload("Course").
+		Where("course_id IN ? AND archived_at IS NULL", courseIDs).
+		Order("due_date DESC").
+		Find(&assignments).Error; err != nil {
+		return nil, errors.New("failed to fetch assignments: " + err.Error())
+	}
+
+	var submissions []models.AssignmentSubmission
+	if err := s.db.Where("student_id = ?", wardID).Find(&submissions).Error; err != nil {
+		return nil, errors.New("failed to fetch submissions: " + err.Error())
+	}
+	byAssignment := make(map[uuid.UUID]models.AssignmentSubmission, len(submissions))
+	for _, submission := range submissions {
+		byAssignment[submission.AssignmentID] = submission
+	}
+
+	now := time.Now()
+	responses := make([]dto.WardAssignmentResponse, 0, len(assignments))
+	for _, assignment := range assignments {
+		response := dto.WardAssignmentResponse{
+			AssignmentID: assignment.ID.String(),
+			CourseID:     assignment.CourseID.String(),
+			CourseTitle:  assignment.Course.Title,
+			Title:        assignment.Title,
+			Type:         assignment.Type,
+			DueDate:      assignment.DueDate,
+			Status:       "not_submitted",
+		}
+		if submission, ok := byAssignment[assignment.ID]; ok {
+			response.Status = submission.Status
+			submittedAt := submission.SubmittedAt
+			response.SubmittedAt = &submittedAt
+		} else if now.After(assignment.DueDate) {
+			response.Status = "overdue"
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// GetWardFees returns a ward's outstanding school fees and the payments made
+func (s *GuardianPortalService) GetWardFees(studentID string) (*dto.WardFeesResponse, error) {
+	wardID, err := uuid.Parse(studentID)
+	if err != nil {
+		return nil, errors.New("invalid student ID")
+	}
+
+	response := &dto.WardFeesResponse{
+		StudentID: wardID.String(),
+		Payments:  []dto.WardPaymentResponse{},
+	}
+
+	statement, err := s.billing.GetStatement(wardID.String())
+	if err != nil {
+		return nil, err
+	}
+	response.OutstandingBalance = statement.OutstandingBalance
+	response.OutstandingInvoices = statement.OutstandingInvoices
+
+	var payments []models.Payment
+	if err := s.db.Where("payer_id = ?", wardID).Order("created_at DESC").Find(&payments).Error; err != nil {
+		return nil, errors.New("failed to fetch payments: " + err.Error())
+	}
+
+	for _, payment := range payments {
+		if payment.Status == "completed" {
+			response.TotalPaid += payment.Amount
+		}
+		response.Payments = append(response.Payments, dto.WardPaymentResponse{
+			ID:            payment.ID.String(),
+			PaymentID:     payment.PaymentID,
+			Amount:        payment.Amount,
+			Currency:      payment.Currency,
+			Status:        payment.Status,
+			PaymentMethod: payment.PaymentMethod,
+			CourseName:    payment.CourseName,
+			ProcessedAt:   payment.ProcessedAt,
+			CreatedAt:     payment.CreatedAt,
+		})
+	}
+	response.TotalPaid = roundTo2(response.TotalPaid)
+
+	return response, nil
+}
+
+// GetAnnouncements lists current announcements addressed to everyone or to guardians
+func (s *GuardianPortalService) GetAnnouncements() ([]dto.PortalAnnouncementResponse, error) {
+	now := time.Now()
+
+	var announcements []models.Announcement
+	if err := s.db.
+		Where("audience IN ?", []string{"all", "guardians"}).
+		Where("start_date IS NULL OR start_date <= ?", now).
+		Where("end_date IS NULL OR end_date >= ?", now).
+		Order("is_pinned DESC, created_at DESC").
+		Find(&announcements).Error; err != nil {
+		return nil, errors.New("failed to fetch announcements: " + err.Error())
+	}
+
+	responses := make([]dto.PortalAnnouncementResponse, 0, len(announcements))
+	for _, a := range announcements {
+		responses = append(responses, dto.PortalAnnouncementResponse{
+			ID:        a.ID.String(),
+			Title:     a.Title,
+			Message:   a.Message,
+			Type:      a.Type,
+			Audience:  a.Audience,
+			IsPinned:  a.IsPinned,
+			StartDate: a.StartDate,
+			EndDate:   a.EndDate,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// currentArm returns the arm a student is currently seated in, if any
+func (s *GuardianPortalService) currentArm(studentID uuid.UUID) (*dto.ArmResponse, error) {
+	var membership models.ClassMembership
+	err := s.db.Preload("Arm").
+		Joins("JOIN academic_sessions ON academic_sessions.id = class_memberships.academic_session_id").
+		Where("class_memberships.student_id = ? AND class_memberships.status = ?", studentID, "active").
+		Order("academic_sessions.start_date DESC").
+		First(&membership).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to fetch class membership: " + err.Error())
+	}
+
+	return &dto.ArmResponse{
+		ID:          membership.Arm.ID.String(),
+		Name:        membership.Arm.Name,
+		Description: membership.Arm.Description,
+		Code:        membership.Arm.Code,
+		GradeID:     membership.Arm.GradeID.String(),
+		Status:      membership.Arm.Status,
+		Capacity:    membership.Arm.Capacity,
+		CreatedBy:   membership.Arm.CreatedBy.String(),
+		CreatedAt:   membership.Arm.CreatedAt,
+		UpdatedAt:   membership.Arm.UpdatedAt,
+	}, nil
+}
+
+// gradesBetween loads a student's grades recorded within a date range
+func (s *GuardianPortalService) gradesBetween(studentID uuid.UUID, from, to time.Time) ([]models.Grade, error) {
+	var grades []models.Grade
+	if err := s.db.Preload("Course").
+		Where("student_id = ? AND created_at >= ? AND created_at < ?", studentID, from, to.AddDate(0, 0, 1)).
+		Order("created_at DESC").
+		Find(&grades).Error; err != nil {
+		return nil, errors.New("failed to fetch grades: " + err.Error())
+	}
+	return grades, nil
+}
+
+// resolveTerm picks the session (current by default) and the date range to report on
+func (s *GuardianPortalService) resolveTerm(params *dto.WardQueryParams) (*models.AcademicSession, time.Time, time.Time, error) {
+	var session models.AcademicSession
+	query := s.db.Where("deleted_at IS NULL")
+	if params.AcademicSessionID != "" {
+		sessionID, err := uuid.Parse(params.AcademicSessionID)
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, errors.New("invalid academic session ID")
+		}
+		query = query.Where("id = ?", sessionID)
+	} else {
+		query = query.Where("is_current = ?", true)
+	}
+	if err := query.First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, time.Time{}, time.Time{}, errors.New("academic session not found")
+		}
+		return nil, time.Time{}, time.Time{}, errors.New("failed to fetch academic session: " + err.Error())
+	}
+
+	from, to := session.StartDate, session.EndDate
+	if params.From != "" {
+		parsed, err := time.Parse(dateLayout, params.From)
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, errors.New("invalid from date format, use YYYY-MM-DD")
+		}
+		from = parsed
+	}
+	if params.To != "" {
+		parsed, err := time.Parse(dateLayout, params.To)
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, errors.New("invalid to date format, use YYYY-MM-DD")
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		return nil, time.Time{}, time.Time{}, errors.New("to date must be on or after from date")
+	}
+
+	return &session, from, to, nil
+}
+
+// letterGrade converts an average score to a letter, on the same scale as recorded grades
+func letterGrade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+func roundTo2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func toUserResponse(user *models.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		ID:         user.ID.String(),
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		MiddleName: user.MiddleName,
+		FullName:   strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Email:      user.Email,
+		Phone:      user.Phone,
+		Role:       user.Role,
+		Picture:    user.Picture,
+		IsVerified: user.IsVerified,
+		IsActive:   user.IsActive,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	}
+}
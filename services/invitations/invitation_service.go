@@ -0,0 +1,548 @@
+// services/invitation_service.go
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	membershipServices "crm-go/services/class_membership"
+	passwordServices "crm-go/services/passwords"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+// InvitationService creates accounts by invitation. An admin picks the role, and optionally a
+// class arm or course, and the invitee sets their own password from a single-use link.
+type InvitationService struct {
+	db *gorm.DB
+}
+
+func NewInvitationService(db *gorm.DB) *InvitationService {
+	return &InvitationService{db: db}
+}
+
+// CreateInvitation records an invitation and emails its link (Admin)
+func (s *InvitationService) CreateInvitation(req *dto.CreateInvitationRequest, invitedBy uuid.UUID) (*dto.InvitationResponse, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	if err := s.ensureEmailAvailable(s.db, email); err != nil {
+		return nil, err
+	}
+
+	var pending int64
+	if err := s.db.Model(&models.Invitation{}).
+		Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", email, time.Now()).
+		Count(&pending).Error; err != nil {
+		return nil, errors.New("failed to check existing invitations: " + err.Error())
+	}
+	if pending > 0 {
+		return nil, errors.New("a pending invitation already exists for this email; resend it instead")
+	}
+
+	invitation := &models.Invitation{
+		ID:        uuid.New(),
+		Email:     email,
+		Role:      req.Role,
+		FirstName: strings.TrimSpace(req.FirstName),
+		LastName:  strings.TrimSpace(req.LastName),
+		InvitedBy: invitedBy,
+	}
+	if err := s.resolveAssignments(invitation, req.ArmID, req.CourseID); err != nil {
+		return nil, err
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	token, err := s.issueToken(invitation)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Create(invitation).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create invitation: " + err.Error())
+	}
+
+	// The link only exists in the email, so an invitation that cannot be sent is not kept
+	if err := s.sendInvitation(invitation, token); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to create invitation: " + err.Error())
+	}
+
+	return s.toResponse(invitation, time.Now()), nil
+}
+
+// GetInvitations lists invitations, newest first, optionally by status (Admin)
+func (s *InvitationService) GetInvitations(status string) ([]dto.InvitationResponse, error) {
+	now := time.Now()
+	query := s.db.Model(&models.Invitation{})
+	switch status {
+	case "":
+	case "pending":
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	case "accepted":
+		query = query.Where("accepted_at IS NOT NULL")
+	case "revoked":
+		query = query.Where("revoked_at IS NOT NULL")
+	case "expired":
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	default:
+		return nil, errors.New("status must be 'pending', 'accepted', 'revoked' or 'expired'")
+	}
+
+	var invitations []models.Invitation
+	if err := query.Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, errors.New("failed to fetch invitations: " + err.Error())
+	}
+
+	responses := make([]dto.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		responses = append(responses, *s.toResponse(&invitations[i], now))
+	}
+	return responses, nil
+}
+
+// ResendInvitation emails a new link, replacing the old one and restarting the expiry (Admin)
+func (s *InvitationService) ResendInvitation(id string) (*dto.InvitationResponse, error) {
+	invitation, err := s.findByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, errors.New("invitation has already been accepted")
+	}
+	if invitation.RevokedAt != nil {
+		return nil, errors.New("invitation has been revoked")
+	}
+	if err := s.ensureEmailAvailable(s.db, invitation.Email); err != nil {
+		return nil, err
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	token, err := s.issueToken(invitation)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Model(invitation).Updates(map[string]interface{}{
+		"token_hash": invitation.TokenHash,
+		"expires_at": invitation.ExpiresAt,
+		"sent_at":    invitation.SentAt,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update invitation: " + err.Error())
+	}
+	if err := s.sendInvitation(invitation, token); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to update invitation: " + err.Error())
+	}
+
+	return s.toResponse(invitation, time.Now()), nil
+}
+
+// RevokeInvitation stops an unused invitation from being accepted (Admin)
+func (s *InvitationService) RevokeInvitation(id string) (*dto.InvitationResponse, error) {
+	invitation, err := s.findByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, errors.New("invitation has already been accepted")
+	}
+	if invitation.RevokedAt != nil {
+		return nil, errors.New("invitation has already been revoked")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(invitation).Update("revoked_at", now).Error; err != nil {
+		return nil, errors.New("failed to revoke invitation: " + err.Error())
+	}
+	invitation.RevokedAt = &now
+
+	return s.toResponse(invitation, now), nil
+}
+
+// PreviewInvitation returns what the accept page shows for a link
+func (s *InvitationService) PreviewInvitation(token string) (*dto.InvitationPreviewResponse, error) {
+	var invitation models.Invitation
+	if err := s.db.Preload("Arm").Preload("Course").
+		Where("token_hash = ?", hashToken(token)).
+		First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid invitation link")
+		}
+		return nil, errors.New("failed to fetch invitation: " + err.Error())
+	}
+	if err := checkUsable(&invitation, time.Now()); err != nil {
+		return nil, err
+	}
+
+	preview := &dto.InvitationPreviewResponse{
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		FirstName: invitation.FirstName,
+		LastName:  invitation.LastName,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+	if invitation.Arm != nil {
+		preview.ArmName = invitation.Arm.Name
+	}
+	if invitation.Course != nil {
+		preview.CourseTitle = invitation.Course.Title
+	}
+	return preview, nil
+}
+
+// AcceptInvitation creates the invitee's account with the invited role and applies any class
+// or course assignment. The email counts as verified because the link was delivered to it.
+func (s *InvitationService) AcceptInvitation(req *dto.AcceptInvitationRequest) (*models.User, error) {
+	hashedPassword, err := passwordServices.NewPasswordService(s.db).HashNewPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the invitation so a link cannot be accepted twice at once
+	var invitation models.Invitation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hashToken(req.Token)).
+		First(&invitation).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid invitation link")
+		}
+		return nil, errors.New("failed to fetch invitation: " + err.Error())
+	}
+	now := time.Now()
+	if err := checkUsable(&invitation, now); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := s.ensureEmailAvailable(tx, invitation.Email); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	firstName := firstNonEmpty(req.FirstName, invitation.FirstName)
+	lastName := firstNonEmpty(req.LastName, invitation.LastName)
+	if firstName == "" || lastName == "" {
+		tx.Rollback()
+		return nil, errors.New("first name and last name are required")
+	}
+
+	user := &models.User{
+		ID:         uuid.New(),
+		FirstName:  firstName,
+		LastName:   lastName,
+		Email:      invitation.Email,
+		Password:   hashedPassword,
+		Role:       invitation.Role,
+		Provider:   "local",
+		IsVerified: true,
+		VerifiedAt: &now,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create user: " + err.Error())
+	}
+
+	if err := s.applyAssignments(tx, &invitation, user, now); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&invitation).Updates(map[string]interface{}{
+		"accepted_at":      now,
+		"accepted_user_id": user.ID,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to accept invitation: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to accept invitation: " + err.Error())
+	}
+
+	return user, nil
+}
+
+// resolveAssignments validates the optional arm and course an invitation pre-assigns
+func (s *InvitationService) resolveAssignments(invitation *models.Invitation, armID string, courseID string) error {
+	armID, courseID = strings.TrimSpace(armID), strings.TrimSpace(courseID)
+	if armID == "" && courseID == "" {
+		return nil
+	}
+	if invitation.Role != "student" && invitation.Role != "tutor" {
+		return errors.New("only student and tutor invitations can pre-assign a class or course")
+	}
+
+	if armID != "" {
+		id, err := uuid.Parse(armID)
+		if err != nil {
+			return errors.New("invalid arm ID")
+		}
+		var arm models.Arm
+		if err := s.db.Where("id = ? AND deleted_at IS NULL", id).First(&arm).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("arm not found")
+			}
+			return errors.New("failed to fetch arm: " + err.Error())
+		}
+		if arm.Status != "active" {
+			return errors.New("arm is not active")
+		}
+		if invitation.Role == "tutor" && arm.FormTeacherID != nil {
+			return errors.New("arm already has a form teacher")
+		}
+		invitation.ArmID = &id
+	}
+
+	if courseID != "" {
+		id, err := uuid.Parse(courseID)
+		if err != nil {
+			return errors.New("invalid course ID")
+		}
+		var course models.Course
+		if err := s.db.Where("id = ?", id).First(&course).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("course not found")
+			}
+			return errors.New("failed to fetch course: " + err.Error())
+		}
+		invitation.CourseID = &id
+	}
+	return nil
+}
+
+// applyAssignments places an invited student in their arm and enrols them in their course, or
+// makes an invited tutor the arm's form teacher and the course's tutor
+func (s *InvitationService) applyAssignments(tx *gorm.DB, invitation *models.Invitation, user *models.User, now time.Time) error {
+	if invitation.ArmID != nil {
+		switch invitation.Role {
+		case "student":
+			if _, err := membershipServices.NewClassMembershipService(s.db).
+				PlaceStudentTx(tx, user.ID, *invitation.ArmID, "Placed by invitation", invitation.InvitedBy); err != nil {
+				return err
+			}
+		case "tutor":
+			// Someone may have been given the arm since the invitation was sent; the account is
+			// still created and the admin can reassign the arm
+			result := tx.Model(&models.Arm{}).
+				Where("id = ? AND form_teacher_id IS NULL", *invitation.ArmID).
+				Update("form_teacher_id", user.ID)
+			if result.Error != nil {
+				return errors.New("failed to assign form teacher: " + result.Error.Error())
+			}
+			if result.RowsAffected == 0 {
+				log.Printf("⚠️ Arm %s already has a form teacher; invitation %s left it unchanged", *invitation.ArmID, invitation.ID)
+			}
+		}
+	}
+
+	if invitation.CourseID != nil {
+		switch invitation.Role {
+		case "student":
+			enrollment := models.Enrollment{
+				ID:             uuid.New(),
+				StudentID:      user.ID,
+				CourseID:       *invitation.CourseID,
+				Status:         "active",
+				EnrollmentDate: now,
+				StartDate:      &now,
+				PaymentStatus:  "free",
+				AccessLevel:    "full",
+			}
+			if err := tx.Create(&enrollment).Error; err != nil {
+				return errors.New("failed to enroll student: " + err.Error())
+			}
+		case "tutor":
+			if err := tx.Model(&models.Course{}).Where("id = ?", *invitation.CourseID).
+				Update("tutor_id", user.ID).Error; err != nil {
+				return errors.New("failed to assign course tutor: " + err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// ensureEmailAvailable rejects invitations for addresses that already have an account
+func (s *InvitationService) ensureEmailAvailable(db *gorm.DB, email string) error {
+	var count int64
+	if err := db.Unscoped().Model(&models.User{}).Where("LOWER(email) = ?", email).Count(&count).Error; err != nil {
+		return errors.New("failed to check email: " + err.Error())
+	}
+	if count > 0 {
+		return errors.New("a user with this email already exists")
+	}
+	return nil
+}
+
+func (s *InvitationService) findByID(id string) (*models.Invitation, error) {
+	invitationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid invitation ID")
+	}
+
+	var invitation models.Invitation
+	if err := s.db.Where("id = ?", invitationID).First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, errors.New("failed to fetch invitation: " + err.Error())
+	}
+	return &invitation, nil
+}
+
+// issueToken gives the invitation a new link token, storing only its hash
+func (s *InvitationService) issueToken(invitation *models.Invitation) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate invitation link: " + err.Error())
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	invitation.TokenHash = hashToken(token)
+	invitation.SentAt = now
+	invitation.ExpiresAt = now.Add(time.Duration(cfg.InvitationHours) * time.Hour)
+	return token, nil
+}
+
+func (s *InvitationService) sendInvitation(invitation *models.Invitation, token string) error {
+	var inviter models.User
+	inviterName := "An administrator"
+	if err := s.db.Select("first_name, last_name").Where("id = ?", invitation.InvitedBy).First(&inviter).Error; err == nil {
+		inviterName = strings.TrimSpace(inviter.FirstName + " " + inviter.LastName)
+	}
+
+	greeting := "Hello"
+	if invitation.FirstName != "" {
+		greeting = "Hello " + invitation.FirstName
+	}
+	link := cfg.FrontendURL + "/accept-invitation?token=" + token
+	body := fmt.Sprintf("<p>%s,</p><p>%s has invited you to join Go CRM as a %s.</p>"+
+		"<a href='%s'>Accept invitation</a>"+
+		"<p>The link can be used once and expires on %s.</p>",
+		greeting, inviterName, invitation.Role, link, invitation.ExpiresAt.Format(time.RFC1123))
+	if err := utils.SendEmail(invitation.Email, "You have been invited to Go CRM", body); err != nil {
+		return errors.New("failed to send invitation email: " + err.Error())
+	}
+	return nil
+}
+
+// toResponse converts model to response DTO
+func (s *InvitationService) toResponse(invitation *models.Invitation, now time.Time) *dto.InvitationResponse {
+	response := &dto.InvitationResponse{
+		ID:         invitation.ID.String(),
+		Email:      invitation.Email,
+		Role:       invitation.Role,
+		FirstName:  invitation.FirstName,
+		LastName:   invitation.LastName,
+		Status:     invitationStatus(invitation, now),
+		InvitedBy:  invitation.InvitedBy.String(),
+		ExpiresAt:  invitation.ExpiresAt,
+		SentAt:     invitation.SentAt,
+		AcceptedAt: invitation.AcceptedAt,
+		RevokedAt:  invitation.RevokedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+	if invitation.ArmID != nil {
+		armID := invitation.ArmID.String()
+		response.ArmID = &armID
+	}
+	if invitation.CourseID != nil {
+		courseID := invitation.CourseID.String()
+		response.CourseID = &courseID
+	}
+	if invitation.AcceptedUserID != nil {
+		userID := invitation.AcceptedUserID.String()
+		response.AcceptedUserID = &userID
+	}
+	return response
+}
+
+func invitationStatus(invitation *models.Invitation, now time.Time) string {
+	switch {
+	case invitation.AcceptedAt != nil:
+		return "accepted"
+	case invitation.RevokedAt != nil:
+		return "revoked"
+	case !invitation.ExpiresAt.After(now):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+// checkUsable rejects links that were used, revoked or have expired
+func checkUsable(invitation *models.Invitation, now time.Time) error {
+	switch invitationStatus(invitation, now) {
+	case "accepted":
+		return errors.New("invitation has already been used")
+	case "revoked":
+		return errors.New("invitation has been revoked")
+	case "expired":
+		return errors.New("invitation has expired")
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
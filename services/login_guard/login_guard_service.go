@@ -0,0 +1,441 @@
+// services/login_guard_service.go
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+const (
+	maxRetryDelay       = 60 * time.Second
+	unlockTokenLifetime = 24 * time.Hour
+)
+
+// LoginGuardService tracks failed sign-ins per account and per address. Repeated failures
+// first slow the caller down, then require a CAPTCHA, then lock the account or block the
+// address for a while.
+type LoginGuardService struct {
+	db *gorm.DB
+}
+
+func NewLoginGuardService(db *gorm.DB) *LoginGuardService {
+	return &LoginGuardService{db: db}
+}
+
+// Check decides whether a sign-in may be attempted before the credentials are looked at.
+// user is nil when the email matched no account. Rejected checks are not counted as failures.
+func (s *LoginGuardService) Check(attempt *dto.LoginAttemptInfo, user *models.User) (*dto.LoginGuardStatus, error) {
+	now := time.Now()
+	ipFailures, lastIPFailure, firstIPFailure, err := s.addressFailures(attempt.IPAddress, now)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &dto.LoginGuardStatus{}
+	accountFailures := 0
+	if user != nil {
+		accountFailures = user.FailedLoginCount
+	}
+	status.CaptchaRequired = accountFailures >= cfg.LoginCaptchaAfter || int(ipFailures) >= cfg.LoginCaptchaAfter
+
+	if cfg.LoginIPMaxFailures > 0 && int(ipFailures) >= cfg.LoginIPMaxFailures {
+		status.RetryAfter = secondsUntil(firstIPFailure.Add(s.window()), now)
+		return status, errors.New("too many failed sign-in attempts from this address; try again later")
+	}
+
+	if user != nil && accountLocked(user, now) {
+		status.LockedUntil = user.LockedUntil
+		status.RetryAfter = secondsUntil(*user.LockedUntil, now)
+		return status, errors.New("account is temporarily locked; use the link sent by email or try again later")
+	}
+
+	// Progressive delay: each failure past the CAPTCHA threshold doubles the wait
+	var wait time.Duration
+	if user != nil && user.LastFailedLoginAt != nil {
+		wait = retryDelay(accountFailures) - now.Sub(*user.LastFailedLoginAt)
+	}
+	if lastIPFailure != nil {
+		if ipWait := retryDelay(int(ipFailures)) - now.Sub(*lastIPFailure); ipWait > wait {
+			wait = ipWait
+		}
+	}
+	if wait > 0 {
+		status.RetryAfter = int(math.Ceil(wait.Seconds()))
+		return status, errors.New("too many failed sign-in attempts; slow down")
+	}
+
+	if status.CaptchaRequired && utils.CaptchaEnabled() {
+		ok, err := utils.VerifyCaptcha(attempt.CaptchaToken, attempt.IPAddress)
+		if err != nil {
+			return status, err
+		}
+		if !ok {
+			return status, errors.New("CAPTCHA verification required")
+		}
+	}
+	return status, nil
+}
+
+// RecordFailure logs a failed sign-in and returns what the next attempt needs. The account
+// locks, and an unlock link is emailed, once LOGIN_LOCKOUT_THRESHOLD failures build up.
+func (s *LoginGuardService) RecordFailure(attempt *dto.LoginAttemptInfo, user *models.User, reason string) (*dto.LoginGuardStatus, error) {
+	now := time.Now()
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	record := s.attemptRecord(attempt, user, false, reason)
+	if err := tx.Create(&record).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to record sign-in attempt: " + err.Error())
+	}
+
+	status := &dto.LoginGuardStatus{}
+	var unlockToken string
+	if user != nil {
+		var account models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, failed_login_count, locked_until").
+			Where("id = ?", user.ID).
+			First(&account).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to fetch account: " + err.Error())
+		}
+
+		failures := account.FailedLoginCount + 1
+		updates := map[string]interface{}{
+			"failed_login_count":   failures,
+			"last_failed_login_at": now,
+		}
+		if lockedUntil := lockoutUntil(failures, now); lockedUntil != nil {
+			updates["locked_until"] = *lockedUntil
+			status.LockedUntil = lockedUntil
+
+			token, err := s.issueUnlockToken(tx, user.ID, now)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			unlockToken = token
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(updates).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update account: " + err.Error())
+		}
+		status.CaptchaRequired = failures >= cfg.LoginCaptchaAfter
+		status.RetryAfter = int(retryDelay(failures).Seconds())
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	ipFailures, _, _, err := s.addressFailures(attempt.IPAddress, now)
+	if err != nil {
+		return nil, err
+	}
+	if int(ipFailures) >= cfg.LoginCaptchaAfter {
+		status.CaptchaRequired = true
+	}
+	if ipDelay := int(retryDelay(int(ipFailures)).Seconds()); ipDelay > status.RetryAfter {
+		status.RetryAfter = ipDelay
+	}
+	if status.LockedUntil != nil {
+		status.RetryAfter = secondsUntil(*status.LockedUntil, now)
+	}
+
+	if unlockToken != "" {
+		s.sendUnlockEmail(user, unlockToken, *status.LockedUntil)
+	}
+	return status, nil
+}
+
+// RecordSuccess logs a successful sign-in and clears the account's failure count
+func (s *LoginGuardService) RecordSuccess(attempt *dto.LoginAttemptInfo, user *models.User) error {
+	now := time.Now()
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	record := s.attemptRecord(attempt, user, true, "")
+	if err := tx.Create(&record).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to record sign-in attempt: " + err.Error())
+	}
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+		"failed_login_count":   0,
+		"last_failed_login_at": nil,
+		"locked_until":         nil,
+		"last_login_at":        now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to update account: " + err.Error())
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to commit transaction: " + err.Error())
+	}
+	return nil
+}
+
+// UnlockWithToken unlocks an account from the link emailed when it locked
+func (s *LoginGuardService) UnlockWithToken(token string) error {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var unlock models.AccountUnlockToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hashToken(token)).
+		First(&unlock).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("invalid unlock link")
+		}
+		return errors.New("failed to fetch unlock link: " + err.Error())
+	}
+	if unlock.UsedAt != nil || !time.Now().Before(unlock.ExpiresAt) {
+		tx.Rollback()
+		return errors.New("unlock link has expired")
+	}
+
+	if err := tx.Model(&unlock).Update("used_at", time.Now()).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to use unlock link: " + err.Error())
+	}
+	if err := s.unlock(tx, unlock.UserID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to commit transaction: " + err.Error())
+	}
+	return nil
+}
+
+// UnlockUser clears a user's lockout and failure count (Admin)
+func (s *LoginGuardService) UnlockUser(userID string) (*dto.LoginSecurityResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+	if count == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	if err := s.unlock(s.db, id); err != nil {
+		return nil, err
+	}
+	return s.GetLoginSecurity(userID)
+}
+
+// GetLoginSecurity returns a user's failure count, lockout and most recent sign-in attempts (Admin)
+func (s *LoginGuardService) GetLoginSecurity(userID string) (*dto.LoginSecurityResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	var user models.User
+	if err := s.db.Select("id, failed_login_count, last_failed_login_at, locked_until").
+		Where("id = ?", id).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+
+	var attempts []models.LoginAttempt
+	if err := s.db.Where("user_id = ?", id).
+		Order("created_at DESC").
+		Limit(50).
+		Find(&attempts).Error; err != nil {
+		return nil, errors.New("failed to fetch sign-in attempts: " + err.Error())
+	}
+
+	response := &dto.LoginSecurityResponse{
+		UserID:            user.ID.String(),
+		FailedLoginCount:  user.FailedLoginCount,
+		LastFailedLoginAt: user.LastFailedLoginAt,
+		LockedUntil:       user.LockedUntil,
+		Locked:            user.LockedUntil != nil && user.LockedUntil.After(time.Now()),
+		RecentAttempts:    make([]dto.LoginAttemptResponse, 0, len(attempts)),
+	}
+	for _, attempt := range attempts {
+		response.RecentAttempts = append(response.RecentAttempts, dto.LoginAttemptResponse{
+			ID:        attempt.ID.String(),
+			Email:     attempt.Email,
+			IPAddress: attempt.IPAddress,
+			UserAgent: attempt.UserAgent,
+			Method:    attempt.Method,
+			Success:   attempt.Success,
+			Reason:    attempt.Reason,
+			CreatedAt: attempt.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+func (s *LoginGuardService) unlock(tx *gorm.DB, userID uuid.UUID) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+		"failed_login_count":   0,
+		"last_failed_login_at": nil,
+		"locked_until":         nil,
+	}).Error; err != nil {
+		return errors.New("failed to unlock account: " + err.Error())
+	}
+	if err := tx.Model(&models.AccountUnlockToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error; err != nil {
+		return errors.New("failed to void unlock links: " + err.Error())
+	}
+	return nil
+}
+
+// addressFailures counts failed sign-ins from an address within the window, with the
+// first and most recent of them
+func (s *LoginGuardService) addressFailures(ip string, now time.Time) (int64, *time.Time, time.Time, error) {
+	var result struct {
+		Failures int64
+		First    *time.Time
+		Last     *time.Time
+	}
+	if err := s.db.Model(&models.LoginAttempt{}).
+		Select("COUNT(*) AS failures, MIN(created_at) AS first, MAX(created_at) AS last").
+		Where("ip_address = ? AND success = false AND created_at > ?", ip, now.Add(-s.window())).
+		Scan(&result).Error; err != nil {
+		return 0, nil, time.Time{}, errors.New("failed to count sign-in failures: " + err.Error())
+	}
+	first := now
+	if result.First != nil {
+		first = *result.First
+	}
+	return result.Failures, result.Last, first, nil
+}
+
+func (s *LoginGuardService) attemptRecord(attempt *dto.LoginAttemptInfo, user *models.User, success bool, reason string) models.LoginAttempt {
+	record := models.LoginAttempt{
+		Email:     attempt.Email,
+		IPAddress: attempt.IPAddress,
+		UserAgent: attempt.UserAgent,
+		Method:    attempt.Method,
+		Success:   success,
+		Reason:    reason,
+	}
+	if user != nil {
+		record.UserID = &user.ID
+	}
+	return record
+}
+
+func (s *LoginGuardService) issueUnlockToken(tx *gorm.DB, userID uuid.UUID, now time.Time) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate unlock link: " + err.Error())
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	unlock := models.AccountUnlockToken{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(unlockTokenLifetime),
+	}
+	if err := tx.Create(&unlock).Error; err != nil {
+		return "", errors.New("failed to save unlock link: " + err.Error())
+	}
+	return token, nil
+}
+
+// sendUnlockEmail tells the owner their account locked; failures are logged, not returned,
+// so a mail outage does not change the sign-in response
+func (s *LoginGuardService) sendUnlockEmail(user *models.User, token string, lockedUntil time.Time) {
+	link := cfg.FrontendURL + "/unlock-account?token=" + token
+	body := fmt.Sprintf("<p>Hello %s,</p><p>Your account was locked after several failed sign-in attempts. "+
+		"It unlocks on its own at %s, or straight away with the link below.</p>"+
+		"<a href='%s'>Unlock my account</a>"+
+		"<p>If these attempts were not you, change your password after unlocking.</p>",
+		user.FirstName, lockedUntil.Format(time.RFC1123), link)
+	if err := utils.SendEmail(user.Email, "Your account has been locked - Go CRM", body); err != nil {
+		log.Printf("⚠️ Could not send unlock email to user %s: %v", user.ID, err)
+	}
+}
+
+func (s *LoginGuardService) window() time.Duration {
+	return time.Duration(cfg.LoginFailureWindowMinutes) * time.Minute
+}
+
+// retryDelay is the wait after a given number of consecutive failures: none until the
+// CAPTCHA threshold, then 1s, 2s, 4s... up to a minute
+func retryDelay(failures int) time.Duration {
+	if failures < cfg.LoginCaptchaAfter || failures <= 0 {
+		return 0
+	}
+	exponent := failures - cfg.LoginCaptchaAfter
+	if exponent > 6 {
+		return maxRetryDelay
+	}
+	delay := time.Duration(1<<exponent) * time.Second
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// lockoutUntil is when an account that has just reached the given number of consecutive
+// failures unlocks again, or nil when it stays unlocked
+func lockoutUntil(failures int, now time.Time) *time.Time {
+	if cfg.LoginLockoutThreshold <= 0 || failures < cfg.LoginLockoutThreshold {
+		return nil
+	}
+	lockedUntil := now.Add(time.Duration(cfg.LoginLockoutMinutes) * time.Minute)
+	return &lockedUntil
+}
+
+// accountLocked reports whether the account's lockout is still running
+func accountLocked(user *models.User, now time.Time) bool {
+	return user.LockedUntil != nil && user.LockedUntil.After(now)
+}
+
+func secondsUntil(t time.Time, now time.Time) int {
+	if !t.After(now) {
+		return 0
+	}
+	return int(math.Ceil(t.Sub(now).Seconds()))
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"crm-go/models"
+)
+
+// withGuardSettings swaps the sign-in protection settings for the length of a test
+func withGuardSettings(t *testing.T, captchaAfter, lockoutThreshold, lockoutMinutes int) {
+	t.Helper()
+	saved := *cfg
+	cfg.LoginCaptchaAfter = captchaAfter
+	cfg.LoginLockoutThreshold = lockoutThreshold
+	cfg.LoginLockoutMinutes = lockoutMinutes
+	t.Cleanup(func() { *cfg = saved })
+}
+
+func TestRetryDelay(t *testing.T) {
+	withGuardSettings(t, 3, 10, 15)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: -1, want: 0},
+		{failures: 0, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 8, want: 32 * time.Second},
+		{failures: 9, want: maxRetryDelay},
+		{failures: 10, want: maxRetryDelay},
+		{failures: 500, want: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := retryDelay(tt.failures); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelayWithoutCaptchaThreshold(t *testing.T) {
+	withGuardSettings(t, 0, 10, 15)
+
+	if got := retryDelay(0); got != 0 {
+		t.Errorf("retryDelay(0) = %v, want 0", got)
+	}
+	if got := retryDelay(1); got != 2*time.Second {
+		t.Errorf("retryDelay(1) = %v, want 2s", got)
+	}
+}
+
+func TestLockoutUntil(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		threshold int
+		minutes   int
+		failures  int
+		want      *time.Time
+	}{
+		{name: "below threshold", threshold: 10, minutes: 15, failures: 9},
+		{name: "at threshold", threshold: 10, minutes: 15, failures: 10, want: timePtr(now.Add(15 * time.Minute))},
+		{name: "past threshold", threshold: 10, minutes: 30, failures: 14, want: timePtr(now.Add(30 * time.Minute))},
+		{name: "lockout disabled", threshold: 0, minutes: 15, failures: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withGuardSettings(t, 3, tt.threshold, tt.minutes)
+
+			got := lockoutUntil(tt.failures, now)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("lockoutUntil(%d) = %v, want no lockout", tt.failures, *got)
+			case tt.want != nil && (got == nil || !got.Equal(*tt.want)):
+				t.Errorf("lockoutUntil(%d) = %v, want %v", tt.failures, got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountLocked(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		lockedUntil *time.Time
+		want        bool
+	}{
+		{name: "never locked"},
+		{name: "lock running", lockedUntil: timePtr(now.Add(time.Minute)), want: true},
+		{name: "lock ends now", lockedUntil: timePtr(now)},
+		{name: "lock expired", lockedUntil: timePtr(now.Add(-time.Minute))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{LockedUntil: tt.lockedUntil}
+			if got := accountLocked(user, now); got != tt.want {
+				t.Errorf("accountLocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
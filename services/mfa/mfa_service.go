@@ -0,0 +1,711 @@
+// services/mfa_service.go
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	sessionServices "crm-go/services/sessions"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+const (
+	challengeLifetime   = 10 * time.Minute
+	challengeAttempts   = 5
+	emailCodeDigits     = 6
+	emailCodeResendWait = 30 * time.Second
+	recoveryCodeCount   = 10
+)
+
+type MFAService struct {
+	db       *gorm.DB
+	sessions *sessionServices.SessionService
+}
+
+func NewMFAService(db *gorm.DB) *MFAService {
+	return &MFAService{
+		db:       db,
+		sessions: sessionServices.NewSessionService(db),
+	}
+}
+
+// BeginLogin continues a sign-in whose password (or identity provider) check passed. Users
+// without a second factor, and trusted devices, get a session straight away; everyone else
+// gets a challenge to answer at /auth/mfa/verify.
+func (s *MFAService) BeginLogin(user *models.User, loginType string, client *dto.SessionClient, trustedDeviceToken string) (*dto.LoginResult, error) {
+	settings, err := s.settings(s.db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !roleRequiresMFA(user.Role) && !settings.TOTPEnabled && !settings.EmailOTPEnabled {
+		tokens, err := s.sessions.StartSession(user, loginType, client)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResult{Tokens: tokens}, nil
+	}
+
+	if trustedDeviceToken != "" {
+		device, err := s.trustedDevice(user.ID, trustedDeviceToken)
+		if err != nil {
+			return nil, err
+		}
+		if device != nil {
+			tokens, err := s.sessions.StartVerifiedSession(user, loginType, "trusted_device", &device.ID, client)
+			if err != nil {
+				return nil, err
+			}
+			s.db.Model(device).Update("last_used_at", time.Now())
+			return &dto.LoginResult{Tokens: tokens}, nil
+		}
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	challenge := models.MFAChallenge{
+		UserID:    user.ID,
+		TokenHash: hashSecret(token),
+		LoginType: loginType,
+		ExpiresAt: time.Now().Add(challengeLifetime),
+	}
+	if err := s.db.Create(&challenge).Error; err != nil {
+		return nil, errors.New("failed to create MFA challenge: " + err.Error())
+	}
+
+	methods := []string{}
+	if settings.TOTPEnabled {
+		methods = append(methods, "totp")
+	}
+	// Emailed codes are the fallback for everyone who must pass a second factor
+	methods = append(methods, "email")
+
+	var remaining int64
+	if err := s.db.Model(&models.MFARecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining).Error; err != nil {
+		return nil, errors.New("failed to count recovery codes: " + err.Error())
+	}
+	if remaining > 0 {
+		methods = append(methods, "recovery")
+	}
+
+	return &dto.LoginResult{
+		Challenge: &dto.MFAChallengeResponse{
+			MFARequired:    true,
+			ChallengeToken: token,
+			Methods:        methods,
+			ExpiresAt:      challenge.ExpiresAt,
+		},
+	}, nil
+}
+
+// SendEmailCode emails a one-time sign-in code for a pending challenge. Sending again
+// replaces the previous code.
+func (s *MFAService) SendEmailCode(req *dto.MFAEmailCodeRequest) error {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	challenge, err := s.pendingChallenge(tx, req.ChallengeToken)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if challenge.EmailSentAt != nil && time.Since(*challenge.EmailSentAt) < emailCodeResendWait {
+		tx.Rollback()
+		return errors.New("a code was just sent; wait before requesting another")
+	}
+
+	var user models.User
+	if err := tx.Select("id, email, first_name").Where("id = ?", challenge.UserID).First(&user).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to fetch user: " + err.Error())
+	}
+
+	code, err := utils.GenerateNumericCode(emailCodeDigits)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	now := time.Now()
+	if err := tx.Model(challenge).Updates(map[string]interface{}{
+		"email_code_hash": hashSecret(challenge.ID.String() + ":" + code),
+		"email_sent_at":   now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to save sign-in code: " + err.Error())
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	body := fmt.Sprintf("<p>Hello %s,</p><p>Your sign-in code is <strong>%s</strong>. It expires in %d minutes.</p>"+
+		"<p>If you did not try to sign in, change your password.</p>",
+		user.FirstName, code, int(time.Until(challenge.ExpiresAt).Minutes())+1)
+	if err := utils.SendEmail(user.Email, "Your sign-in code - Go CRM", body); err != nil {
+		return errors.New("failed to send sign-in code: " + err.Error())
+	}
+	return nil
+}
+
+// VerifyLogin completes a two-step sign-in with a TOTP, emailed or recovery code and opens
+// the session. With RememberDevice the device skips the second factor for
+// MFA_TRUSTED_DEVICE_DAYS. The user is also returned with a wrong code so the failure can
+// count towards the account's lockout.
+func (s *MFAService) VerifyLogin(req *dto.MFAVerifyRequest, client *dto.SessionClient) (*models.User, *dto.LoginResult, error) {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	challenge, err := s.pendingChallenge(tx, req.ChallengeToken)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	var user models.User
+	if err := tx.Where("id = ?", challenge.UserID).First(&user).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, errors.New("failed to fetch user: " + err.Error())
+	}
+	if !user.IsActive {
+		tx.Rollback()
+		return nil, nil, errors.New("account has been deactivated")
+	}
+
+	var ok bool
+	switch req.Method {
+	case "totp":
+		ok, err = s.checkTOTP(tx, user.ID, req.Code)
+	case "email":
+		ok = challenge.EmailCodeHash != "" &&
+			subtle.ConstantTimeCompare([]byte(challenge.EmailCodeHash), []byte(hashSecret(challenge.ID.String()+":"+strings.TrimSpace(req.Code)))) == 1
+	case "recovery":
+		ok, err = s.useRecoveryCode(tx, user.ID, req.Code)
+	default:
+		err = errors.New("unsupported MFA method")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	if !ok {
+		// Failed attempts count against the challenge; once used up the sign-in starts over
+		updates := map[string]interface{}{"attempts": challenge.Attempts + 1}
+		if challenge.Attempts+1 >= challengeAttempts {
+			updates["expires_at"] = time.Now()
+		}
+		if err := tx.Model(challenge).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			return nil, nil, errors.New("failed to record attempt: " + err.Error())
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, nil, errors.New("failed to commit transaction: " + err.Error())
+		}
+		return &user, nil, errors.New("invalid verification code")
+	}
+
+	if err := tx.Model(challenge).Update("completed_at", time.Now()).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, errors.New("failed to complete MFA challenge: " + err.Error())
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	tokens, err := s.sessions.StartVerifiedSession(&user, challenge.LoginType, req.Method, nil, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &dto.LoginResult{Tokens: tokens}
+
+	if req.RememberDevice {
+		grant, err := s.rememberDevice(user.ID, tokens.SessionID, client)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.TrustedDevice = grant
+	}
+	return &user, result, nil
+}
+
+// GetStatus describes the caller's second-factor settings
+func (s *MFAService) GetStatus(userID uuid.UUID, role string) (*dto.MFAStatusResponse, error) {
+	settings, err := s.settings(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.MFAStatusResponse{
+		Required:        roleRequiresMFA(role),
+		TOTPEnabled:     settings.TOTPEnabled,
+		TOTPConfirmedAt: settings.TOTPConfirmedAt,
+		EmailOTPEnabled: settings.EmailOTPEnabled,
+	}
+	if err := s.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&response.RecoveryCodesRemaining).Error; err != nil {
+		return nil, errors.New("failed to count recovery codes: " + err.Error())
+	}
+	if err := s.db.Model(&models.TrustedDevice{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Count(&response.TrustedDevices).Error; err != nil {
+		return nil, errors.New("failed to count trusted devices: " + err.Error())
+	}
+	return response, nil
+}
+
+// SetupTOTP starts authenticator enrollment with a new secret. It takes effect once
+// confirmed with a code; until then any existing authenticator keeps working.
+func (s *MFAService) SetupTOTP(userID uuid.UUID) (*dto.TOTPSetupResponse, error) {
+	var user models.User
+	if err := s.db.Select("id, email").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+
+	settings, err := s.settings(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.TOTPEnabled {
+		return nil, errors.New("authenticator app is already enabled; disable it before enrolling a new one")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, encrypted, err := utils.SealMFASecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.TOTPSecret = sealed
+	settings.TOTPEncrypted = encrypted
+	if err := s.db.Save(settings).Error; err != nil {
+		return nil, errors.New("failed to save TOTP secret: " + err.Error())
+	}
+
+	return &dto.TOTPSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(cfg.MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP turns the authenticator on with a first code from the app and issues a new
+// set of recovery codes
+func (s *MFAService) ConfirmTOTP(userID uuid.UUID, code string) (*dto.RecoveryCodesResponse, error) {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	settings, err := s.settings(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if settings.TOTPEnabled {
+		tx.Rollback()
+		return nil, errors.New("authenticator app is already enabled")
+	}
+	if settings.TOTPSecret == "" {
+		tx.Rollback()
+		return nil, errors.New("start authenticator setup first")
+	}
+
+	step, ok, err := s.verifyTOTP(settings, code)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !ok {
+		tx.Rollback()
+		return nil, errors.New("invalid verification code")
+	}
+
+	now := time.Now()
+	if err := tx.Model(settings).Updates(map[string]interface{}{
+		"totp_enabled":      true,
+		"totp_confirmed_at": now,
+		"totp_last_step":    step,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to enable authenticator app: " + err.Error())
+	}
+
+	codes, err := s.replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP removes the authenticator after a current TOTP or recovery code. Users whose
+// role requires a second factor fall back to emailed codes.
+func (s *MFAService) DisableTOTP(userID uuid.UUID, code string) error {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	settings, err := s.settings(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !settings.TOTPEnabled {
+		tx.Rollback()
+		return errors.New("authenticator app is not enabled")
+	}
+
+	if err := s.confirmFactor(tx, userID, code); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(settings).Updates(map[string]interface{}{
+		"totp_enabled":      false,
+		"totp_secret":       "",
+		"totp_encrypted":    false,
+		"totp_confirmed_at": nil,
+		"totp_last_step":    0,
+	}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to disable authenticator app: " + err.Error())
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete recovery codes: " + err.Error())
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to commit transaction: " + err.Error())
+	}
+	return nil
+}
+
+// SetEmailOTP turns emailed sign-in codes on or off for users who want a second factor
+// without an authenticator app
+func (s *MFAService) SetEmailOTP(userID uuid.UUID, enabled bool) (*dto.MFAStatusResponse, error) {
+	settings, err := s.settings(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings.EmailOTPEnabled = enabled
+	if err := s.db.Save(settings).Error; err != nil {
+		return nil, errors.New("failed to update MFA settings: " + err.Error())
+	}
+
+	var user models.User
+	if err := s.db.Select("id, role").Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+	return s.GetStatus(userID, user.Role)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after a current TOTP or recovery code
+func (s *MFAService) RegenerateRecoveryCodes(userID uuid.UUID, code string) (*dto.RecoveryCodesResponse, error) {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	settings, err := s.settings(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !settings.TOTPEnabled {
+		tx.Rollback()
+		return nil, errors.New("recovery codes need an authenticator app; enable it first")
+	}
+	if err := s.confirmFactor(tx, userID, code); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// GetTrustedDevices lists the caller's remembered devices
+func (s *MFAService) GetTrustedDevices(userID uuid.UUID, currentSessionID uuid.UUID) ([]dto.TrustedDeviceResponse, error) {
+	var devices []models.TrustedDevice
+	if err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&devices).Error; err != nil {
+		return nil, errors.New("failed to fetch trusted devices: " + err.Error())
+	}
+
+	var current models.UserSession
+	s.db.Select("id, trusted_device_id").Where("id = ?", currentSessionID).First(&current)
+
+	responses := make([]dto.TrustedDeviceResponse, 0, len(devices))
+	for _, device := range devices {
+		isCurrent := (device.SessionID != nil && *device.SessionID == currentSessionID) ||
+			(current.TrustedDeviceID != nil && *current.TrustedDeviceID == device.ID)
+		responses = append(responses, dto.TrustedDeviceResponse{
+			ID:         device.ID.String(),
+			DeviceType: device.DeviceType,
+			DeviceOS:   device.DeviceOS,
+			Browser:    device.Browser,
+			IPAddress:  device.UserIP,
+			Current:    isCurrent,
+			LastUsedAt: device.LastUsedAt,
+			ExpiresAt:  device.ExpiresAt,
+			CreatedAt:  device.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// RevokeTrustedDevice forgets a remembered device; its next sign-in asks for a second factor
+func (s *MFAService) RevokeTrustedDevice(userID uuid.UUID, deviceID string) error {
+	id, err := uuid.Parse(deviceID)
+	if err != nil {
+		return errors.New("invalid trusted device ID")
+	}
+
+	result := s.db.Model(&models.TrustedDevice{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return errors.New("failed to revoke trusted device: " + result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("trusted device not found")
+	}
+	return nil
+}
+
+// settings loads a user's MFA row, returning an unsaved blank one when they have none
+func (s *MFAService) settings(db *gorm.DB, userID uuid.UUID) (*models.UserMFA, error) {
+	var settings models.UserMFA
+	if err := db.Where("user_id = ?", userID).First(&settings).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.UserMFA{UserID: userID}, nil
+		}
+		return nil, errors.New("failed to fetch MFA settings: " + err.Error())
+	}
+	return &settings, nil
+}
+
+// pendingChallenge locks an unexpired, unfinished challenge by its token
+func (s *MFAService) pendingChallenge(tx *gorm.DB, token string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hashSecret(token)).
+		First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid MFA challenge")
+		}
+		return nil, errors.New("failed to fetch MFA challenge: " + err.Error())
+	}
+	if challenge.CompletedAt != nil || !time.Now().Before(challenge.ExpiresAt) {
+		return nil, errors.New("MFA challenge has expired; sign in again")
+	}
+	return &challenge, nil
+}
+
+// checkTOTP verifies a code against the enrolled authenticator and spends its time step
+func (s *MFAService) checkTOTP(tx *gorm.DB, userID uuid.UUID, code string) (bool, error) {
+	settings, err := s.settings(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+	if err != nil {
+		return false, err
+	}
+	if !settings.TOTPEnabled {
+		return false, errors.New("authenticator app is not enabled")
+	}
+
+	step, ok, err := s.verifyTOTP(settings, code)
+	if err != nil || !ok {
+		return false, err
+	}
+	if step <= settings.TOTPLastStep {
+		return false, nil
+	}
+	if err := tx.Model(settings).Update("totp_last_step", step).Error; err != nil {
+		return false, errors.New("failed to record TOTP use: " + err.Error())
+	}
+	return true, nil
+}
+
+func (s *MFAService) verifyTOTP(settings *models.UserMFA, code string) (int64, bool, error) {
+	secret, err := utils.OpenMFASecret(settings.TOTPSecret, settings.TOTPEncrypted)
+	if err != nil {
+		return 0, false, errors.New("failed to read TOTP secret: " + err.Error())
+	}
+	step, ok := utils.VerifyTOTP(secret, code, time.Now())
+	return step, ok, nil
+}
+
+// confirmFactor accepts a current TOTP code or an unused recovery code for sensitive changes
+func (s *MFAService) confirmFactor(tx *gorm.DB, userID uuid.UUID, code string) error {
+	ok, err := s.checkTOTP(tx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if ok, err = s.useRecoveryCode(tx, userID, code); err != nil {
+			return err
+		}
+	}
+	if !ok {
+		return errors.New("invalid verification code")
+	}
+	return nil
+}
+
+// useRecoveryCode spends a recovery code, reporting whether it was valid
+func (s *MFAService) useRecoveryCode(tx *gorm.DB, userID uuid.UUID, code string) (bool, error) {
+	result := tx.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashSecret(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, errors.New("failed to use recovery code: " + result.Error.Error())
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// replaceRecoveryCodes deletes a user's recovery codes and returns a fresh set
+func (s *MFAService) replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, errors.New("failed to delete recovery codes: " + err.Error())
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, errors.New("failed to generate recovery code: " + err.Error())
+		}
+		encoded := strings.ToLower(base32.StdEncoding.EncodeToString(raw)) // 8 characters
+		code := encoded[:4] + "-" + encoded[4:]
+		codes = append(codes, code)
+		records = append(records, models.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: hashSecret(normalizeRecoveryCode(code)),
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, errors.New("failed to save recovery codes: " + err.Error())
+	}
+	return codes, nil
+}
+
+// trustedDevice finds a user's unexpired remembered device by its token
+func (s *MFAService) trustedDevice(userID uuid.UUID, token string) (*models.TrustedDevice, error) {
+	var device models.TrustedDevice
+	if err := s.db.Where("user_id = ? AND token_hash = ? AND revoked_at IS NULL AND expires_at > ?",
+		userID, hashSecret(token), time.Now()).
+		First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.New("failed to fetch trusted device: " + err.Error())
+	}
+	return &device, nil
+}
+
+// rememberDevice records the device behind a session that just passed a second factor
+func (s *MFAService) rememberDevice(userID uuid.UUID, sessionID string, client *dto.SessionClient) (*dto.TrustedDeviceGrant, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return nil, errors.New("invalid session ID")
+	}
+
+	now := time.Now()
+	device := models.TrustedDevice{
+		UserID:     userID,
+		SessionID:  &id,
+		TokenHash:  hashSecret(token),
+		DeviceType: client.DeviceType,
+		DeviceOS:   client.DeviceOS,
+		Browser:    client.Browser,
+		UserIP:     client.IPAddress,
+		ExpiresAt:  now.Add(time.Duration(cfg.TrustedDeviceDays) * 24 * time.Hour),
+		LastUsedAt: now,
+	}
+	if err := s.db.Create(&device).Error; err != nil {
+		return nil, errors.New("failed to remember device: " + err.Error())
+	}
+	return &dto.TrustedDeviceGrant{Token: token, ExpiresAt: device.ExpiresAt}, nil
+}
+
+// roleRequiresMFA reports whether MFA_REQUIRED_ROLES lists the role
+func roleRequiresMFA(role string) bool {
+	for _, required := range strings.Split(cfg.MFARequiredRoles, ",") {
+		if strings.EqualFold(strings.TrimSpace(required), role) && role != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(strings.ReplaceAll(code, "-", ""), " ", "")
+}
+
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate token: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashSecret(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
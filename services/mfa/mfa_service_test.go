@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+func TestRoleRequiresMFA(t *testing.T) {
+	saved := cfg.MFARequiredRoles
+	t.Cleanup(func() { cfg.MFARequiredRoles = saved })
+
+	cfg.MFARequiredRoles = "admin, Teacher ,,"
+	for role, want := range map[string]bool{
+		"admin":   true,
+		"teacher": true,
+		"TEACHER": true,
+		"student": false,
+		"":        false,
+	} {
+		if got := roleRequiresMFA(role); got != want {
+			t.Errorf("with %q required: roleRequiresMFA(%q) = %v, want %v", cfg.MFARequiredRoles, role, got, want)
+		}
+	}
+
+	cfg.MFARequiredRoles = ""
+	if roleRequiresMFA("admin") {
+		t.Error("with no roles required: roleRequiresMFA(\"admin\") = true")
+	}
+}
+
+func TestRecoveryCodesMatchHoweverTyped(t *testing.T) {
+	issued := hashSecret(normalizeRecoveryCode("k3xq-7mzd"))
+
+	for _, typed := range []string{"k3xq-7mzd", "K3XQ-7MZD", "k3xq7mzd", " k3xq 7mzd ", "K3XQ - 7MZD"} {
+		if hashSecret(normalizeRecoveryCode(typed)) != issued {
+			t.Errorf("recovery code typed as %q does not match the issued code", typed)
+		}
+	}
+	if hashSecret(normalizeRecoveryCode("k3xq-7mze")) == issued {
+		t.Error("a different recovery code matches the issued code")
+	}
+}
+
+func TestVerifyTOTPRefusals(t *testing.T) {
+	saved := cfg.MFAEncryptionKey
+	t.Cleanup(func() { cfg.MFAEncryptionKey = saved })
+	cfg.MFAEncryptionKey = ""
+
+	s := &MFAService{}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+
+	if _, ok, err := s.verifyTOTP(&models.UserMFA{TOTPSecret: secret}, "000000x"); ok || err != nil {
+		t.Errorf("verifyTOTP() with a malformed code = %v, %v, want a plain refusal", ok, err)
+	}
+
+	// A secret sealed with a key that is no longer configured cannot be read
+	if _, _, err := s.verifyTOTP(&models.UserMFA{TOTPSecret: "c2VhbGVk", TOTPEncrypted: true}, "123456"); err == nil {
+		t.Error("verifyTOTP() read a sealed secret without MFA_ENCRYPTION_KEY")
+	}
+}
@@ -0,0 +1,269 @@
+// services/module_release_service.go
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+	accessServices "crm-go/services/access"
+)
+
+type ModuleReleaseService struct {
+	db     *gorm.DB
+	access *accessServices.AccessService
+}
+
+func NewModuleReleaseService(db *gorm.DB) *ModuleReleaseService {
+	return &ModuleReleaseService{
+		db:     db,
+		access: accessServices.NewAccessService(db),
+	}
+}
+
+// SetRule creates or replaces the release rule for a module
+func (s *ModuleReleaseService) SetRule(moduleID string, req *dto.SetReleaseRuleRequest, adminID uuid.UUID) (*dto.ReleaseRuleResponse, error) {
+	module, err := s.loadModule(moduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := models.ModuleReleaseRule{
+		ModuleID:  module.ID,
+		CourseID:  module.CourseID,
+		RuleType:  req.RuleType,
+		CreatedBy: adminID,
+	}
+
+	switch req.RuleType {
+	case "after_enrollment":
+		if req.DelayDays <= 0 {
+			return nil, errors.New("delay_days must be at least 1 for after_enrollment rules")
+		}
+		rule.DelayDays = req.DelayDays
+	case "fixed_date":
+		if req.ReleaseAt == nil {
+			return nil, errors.New("release_at is required for fixed_date rules")
+		}
+		releaseAt := req.ReleaseAt.UTC()
+		rule.ReleaseAt = &releaseAt
+	case "prerequisite":
+		if req.PrerequisiteModuleID == "" && req.PrerequisiteAssignmentID == "" {
+			return nil, errors.New("prerequisite rules need a prerequisite module or assignment")
+		}
+		if req.PrerequisiteModuleID != "" {
+			prerequisiteID, err := s.validatePrerequisiteModule(module, req.PrerequisiteModuleID)
+			if err != nil {
+				return nil, err
+			}
+			rule.PrerequisiteModuleID = &prerequisiteID
+		}
+		if req.PrerequisiteAssignmentID != "" {
+			assignmentID, err := s.validatePrerequisiteAssignment(module, req.PrerequisiteAssignmentID)
+			if err != nil {
+				return nil, err
+			}
+			rule.PrerequisiteAssignmentID = &assignmentID
+			rule.MinScore = req.MinScore
+		}
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "module_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"rule_type", "delay_days", "release_at", "prerequisite_module_id",
+			"prerequisite_assignment_id", "min_score", "created_by", "updated_at",
+		}),
+	}).Create(&rule).Error; err != nil {
+		return nil, errors.New("failed to save release rule: " + err.Error())
+	}
+
+	return s.GetRule(moduleID)
+}
+
+// GetRule returns a module's release rule
+func (s *ModuleReleaseService) GetRule(moduleID string) (*dto.ReleaseRuleResponse, error) {
+	id, err := uuid.Parse(moduleID)
+	if err != nil {
+		return nil, errors.New("invalid module ID")
+	}
+
+	var rule models.ModuleReleaseRule
+	if err := s.db.Where("module_id = ?", id).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("release rule not found")
+		}
+		return nil, errors.New("failed to fetch release rule: " + err.Error())
+	}
+	return s.toResponse(&rule), nil
+}
+
+// DeleteRule removes a module's release rule, releasing it to everyone with course access
+func (s *ModuleReleaseService) DeleteRule(moduleID string) error {
+	id, err := uuid.Parse(moduleID)
+	if err != nil {
+		return errors.New("invalid module ID")
+	}
+
+	result := s.db.Where("module_id = ?", id).Delete(&models.ModuleReleaseRule{})
+	if result.Error != nil {
+		return errors.New("failed to delete release rule: " + result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("release rule not found")
+	}
+	return nil
+}
+
+// CompleteModule records that a student finished a module they can open; repeat calls keep the first completion
+func (s *ModuleReleaseService) CompleteModule(moduleID string, userID uuid.UUID, canPreview bool) (*dto.ModuleCompletionResponse, error) {
+	access, err := s.access.ModuleAccess(userID, canPreview, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.Allowed {
+		return nil, errors.New("not authorized to complete a locked module")
+	}
+
+	module, err := s.loadModule(moduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	completion := models.ModuleCompletion{
+		ModuleID:    module.ID,
+		StudentID:   userID,
+		CourseID:    module.CourseID,
+		CompletedAt: time.Now(),
+	}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&completion).Error; err != nil {
+		return nil, errors.New("failed to record module completion: " + err.Error())
+	}
+
+	var saved models.ModuleCompletion
+	if err := s.db.Where("module_id = ? AND student_id = ?", module.ID, userID).First(&saved).Error; err != nil {
+		return nil, errors.New("failed to fetch module completion: " + err.Error())
+	}
+
+	return &dto.ModuleCompletionResponse{
+		ModuleID:    saved.ModuleID.String(),
+		StudentID:   saved.StudentID.String(),
+		CourseID:    saved.CourseID.String(),
+		CompletedAt: saved.CompletedAt,
+	}, nil
+}
+
+// validatePrerequisiteModule checks the prerequisite is another module of the same course
+// and that following prerequisites from it never leads back to this module
+func (s *ModuleReleaseService) validatePrerequisiteModule(module *models.Module, prerequisiteID string) (uuid.UUID, error) {
+	id, err := uuid.Parse(prerequisiteID)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid prerequisite module ID")
+	}
+	if id == module.ID {
+		return uuid.Nil, errors.New("a module cannot be its own prerequisite")
+	}
+
+	var prerequisite models.Module
+	if err := s.db.Select("id, course_id").Where("id = ?", id).First(&prerequisite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, errors.New("prerequisite module not found")
+		}
+		return uuid.Nil, errors.New("failed to fetch prerequisite module: " + err.Error())
+	}
+	if prerequisite.CourseID != module.CourseID {
+		return uuid.Nil, errors.New("prerequisite module must belong to the same course")
+	}
+
+	seen := map[uuid.UUID]bool{id: true}
+	current := id
+	for {
+		var rule models.ModuleReleaseRule
+		err := s.db.Select("prerequisite_module_id").Where("module_id = ?", current).First(&rule).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && rule.PrerequisiteModuleID == nil) {
+			return id, nil
+		}
+		if err != nil {
+			return uuid.Nil, errors.New("failed to check prerequisite chain: " + err.Error())
+		}
+		next := *rule.PrerequisiteModuleID
+		if next == module.ID {
+			return uuid.Nil, errors.New("prerequisite would create a cycle")
+		}
+		if seen[next] {
+			return id, nil
+		}
+		seen[next] = true
+		current = next
+	}
+}
+
+// validatePrerequisiteAssignment checks the prerequisite is a quiz or exam in the same course
+func (s *ModuleReleaseService) validatePrerequisiteAssignment(module *models.Module, assignmentID string) (uuid.UUID, error) {
+	id, err := uuid.Parse(assignmentID)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid prerequisite assignment ID")
+	}
+
+	var assignment models.Assignment
+	if err := s.db.Select("id, course_id, type").Where("id = ?", id).First(&assignment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, errors.New("prerequisite assignment not found")
+		}
+		return uuid.Nil, errors.New("failed to fetch prerequisite assignment: " + err.Error())
+	}
+	if assignment.CourseID != module.CourseID {
+		return uuid.Nil, errors.New("prerequisite assignment must belong to the same course")
+	}
+	if assignment.Type != "quiz" && assignment.Type != "exam" {
+		return uuid.Nil, errors.New("prerequisite assignment must be a quiz or exam")
+	}
+	return id, nil
+}
+
+// loadModule fetches the columns release rules need
+func (s *ModuleReleaseService) loadModule(moduleID string) (*models.Module, error) {
+	id, err := uuid.Parse(moduleID)
+	if err != nil {
+		return nil, errors.New("invalid module ID")
+	}
+
+	var module models.Module
+	if err := s.db.Select("id, course_id, is_free").Where("id = ?", id).First(&module).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("module not found")
+		}
+		return nil, errors.New("failed to fetch module: " + err.Error())
+	}
+	return &module, nil
+}
+
+// toResponse converts a release rule model to its response
+func (s *ModuleReleaseService) toResponse(rule *models.ModuleReleaseRule) *dto.ReleaseRuleResponse {
+	response := &dto.ReleaseRuleResponse{
+		ID:        rule.ID.String(),
+		ModuleID:  rule.ModuleID.String(),
+		CourseID:  rule.CourseID.String(),
+		RuleType:  rule.RuleType,
+		DelayDays: rule.DelayDays,
+		ReleaseAt: rule.ReleaseAt,
+		MinScore:  rule.MinScore,
+		CreatedBy: rule.CreatedBy.String(),
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+	if rule.PrerequisiteModuleID != nil {
+		id := rule.PrerequisiteModuleID.String()
+		response.PrerequisiteModuleID = &id
+	}
+	if rule.PrerequisiteAssignmentID != nil {
+		id := rule.PrerequisiteAssignmentID.String()
+		response.PrerequisiteAssignmentID = &id
+	}
+	return response
+}
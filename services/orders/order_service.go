@@ -0,0 +1,1277 @@
+// services/order_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/services/activity"
+	"crm-go/services/payments"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+// maxCartQuantity caps a single cart line
+const maxCartQuantity = 100
+
+// chargeTimeout bounds a gateway charge; an order left charging for twice as long was
+// abandoned by a request that died mid-charge
+const chargeTimeout = 45 * time.Second
+
+// nextStatuses lists the fulfilment moves allowed from each paid order status
+var nextStatuses = map[string][]string{
+	"paid":       {"processing", "shipped", "completed"},
+	"processing": {"shipped", "completed"},
+	"shipped":    {"completed"},
+}
+
+type OrderService struct {
+	db       *gorm.DB
+	activity *activity.Service
+	tax      TaxCalculator
+	shipping ShippingCalculator
+}
+
+func NewOrderService(db *gorm.DB, activitySvc *activity.Service) *OrderService {
+	return &OrderService{
+		db:       db,
+		activity: activitySvc,
+		tax:      FlatRateTax{RatePercent: cfg.TaxRatePercent},
+		shipping: FlatRateShipping{Fee: cfg.ShippingFlatFee, FreeThreshold: cfg.FreeShippingThreshold},
+	}
+}
+
+// UseCalculators replaces the default flat-rate tax and shipping calculators
+func (s *OrderService) UseCalculators(tax TaxCalculator, shipping ShippingCalculator) {
+	if tax != nil {
+		s.tax = tax
+	}
+	if shipping != nil {
+		s.shipping = shipping
+	}
+}
+
+// GetCart returns the user's cart priced with an estimate of tax and shipping
+func (s *OrderService) GetCart(userID uuid.UUID) (*dto.CartResponse, error) {
+	var cart models.Cart
+	err := s.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Items.Product").Where("user_id = ?", userID).First(&cart).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &dto.CartResponse{Currency: s.currency(), Items: []dto.CartItemResponse{}}, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to fetch cart: " + err.Error())
+	}
+
+	return s.toCartResponse(userID, &cart)
+}
+
+// AddCartItem adds a product to the cart; course products can only be bought once
+func (s *OrderService) AddCartItem(userID uuid.UUID, req *dto.AddCartItemRequest) (*dto.CartResponse, error) {
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return nil, errors.New("invalid product ID")
+	}
+	quantity := req.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	product, err := s.loadProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Status != "active" {
+		return nil, errors.New("product is not available for sale")
+	}
+
+	links, err := s.courseLinks(s.db, []uuid.UUID{productID})
+	if err != nil {
+		return nil, err
+	}
+	isCourse := len(links[productID]) > 0
+	if isCourse {
+		if quantity > 1 {
+			return nil, errors.New("course products can only be purchased once")
+		}
+		if err := s.checkPublished(links[productID]); err != nil {
+			return nil, err
+		}
+		if err := s.checkNotEnrolled(userID, links[productID]); err != nil {
+			return nil, err
+		}
+	}
+
+	cart, err := s.findOrCreateCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var item models.CartItem
+	err = s.db.Where("cart_id = ? AND product_id = ?", cart.ID, productID).First(&item).Error
+	switch {
+	case err == nil:
+		if isCourse {
+			return nil, errors.New("course is already in the cart")
+		}
+		if item.Quantity+quantity > maxCartQuantity {
+			return nil, fmt.Errorf("quantity cannot exceed %d", maxCartQuantity)
+		}
+		if err := s.db.Model(&item).Updates(map[string]interface{}{
+			"quantity":   item.Quantity + quantity,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return nil, errors.New("failed to update cart: " + err.Error())
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		now := time.Now()
+		item = models.CartItem{
+			ID:        uuid.New(),
+			CartID:    cart.ID,
+			ProductID: productID,
+			Quantity:  quantity,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.db.Omit("Product").Create(&item).Error; err != nil {
+			return nil, errors.New("failed to add to cart: " + err.Error())
+		}
+	default:
+		return nil, errors.New("failed to fetch cart item: " + err.Error())
+	}
+
+	return s.GetCart(userID)
+}
+
+// UpdateCartItem sets the quantity of a product already in the cart
+func (s *OrderService) UpdateCartItem(userID uuid.UUID, productID string, req *dto.UpdateCartItemRequest) (*dto.CartResponse, error) {
+	item, err := s.findCartItem(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Quantity > 1 {
+		links, err := s.courseLinks(s.db, []uuid.UUID{item.ProductID})
+		if err != nil {
+			return nil, err
+		}
+		if len(links[item.ProductID]) > 0 {
+			return nil, errors.New("course products can only be purchased once")
+		}
+	}
+
+	if err := s.db.Model(item).Updates(map[string]interface{}{
+		"quantity":   req.Quantity,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		return nil, errors.New("failed to update cart: " + err.Error())
+	}
+
+	return s.GetCart(userID)
+}
+
+// RemoveCartItem removes a product from the cart
+func (s *OrderService) RemoveCartItem(userID uuid.UUID, productID string) (*dto.CartResponse, error) {
+	item, err := s.findCartItem(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Delete(&models.CartItem{}, "id = ?", item.ID).Error; err != nil {
+		return nil, errors.New("failed to remove cart item: " + err.Error())
+	}
+
+	return s.GetCart(userID)
+}
+
+// ClearCart empties the user's cart
+func (s *OrderService) ClearCart(userID uuid.UUID) error {
+	if err := s.db.Where("cart_id IN (?)", s.db.Model(&models.Cart{}).Select("id").Where("user_id = ?", userID)).
+		Delete(&models.CartItem{}).Error; err != nil {
+		return errors.New("failed to clear cart: " + err.Error())
+	}
+	return nil
+}
+
+// Checkout turns the cart into an order at current prices. Free orders are fulfilled at
+// once; orders with a saved gateway customer are charged now; the rest stay pending
+// until a payment is recorded.
+func (s *OrderService) Checkout(userID uuid.UUID, req *dto.CheckoutRequest) (*dto.OrderResponse, error) {
+	var cart models.Cart
+	if err := s.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Items.Product").Where("user_id = ?", userID).First(&cart).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart is empty")
+		}
+		return nil, errors.New("failed to fetch cart: " + err.Error())
+	}
+	if len(cart.Items) == 0 {
+		return nil, errors.New("cart is empty")
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	links, err := s.courseLinks(s.db, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order := &models.Order{
+		ID:            uuid.New(),
+		OrderNumber:   referenceNumber("ORD", now),
+		UserID:        userID,
+		Status:        "pending",
+		Currency:      s.currency(),
+		Notes:         strings.TrimSpace(req.Notes),
+		Gateway:       req.Gateway,
+		PaymentMethod: req.PaymentMethod,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if order.Gateway == "" {
+		order.Gateway = "manual"
+	}
+
+	lines := make([]PricingLine, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if item.Product.Status != "active" {
+			return nil, errors.New("product '" + item.Product.Name + "' is no longer available")
+		}
+
+		courseIDs := links[item.ProductID]
+		isCourse := len(courseIDs) > 0
+		if isCourse {
+			if item.Quantity > 1 {
+				return nil, errors.New("course products can only be purchased once")
+			}
+			if err := s.checkNotEnrolled(userID, courseIDs); err != nil {
+				return nil, err
+			}
+		}
+
+		line := PricingLine{
+			ProductID: item.ProductID,
+			UnitPrice: roundMoney(item.Product.Price),
+			Quantity:  item.Quantity,
+			LineTotal: roundMoney(item.Product.Price * float64(item.Quantity)),
+			// Courses are delivered online whatever the product's shipping flag says
+			RequiresShipping: item.Product.RequiresShipping && !isCourse,
+			IsCourse:         isCourse,
+		}
+		lines = append(lines, line)
+
+		order.Items = append(order.Items, models.OrderItem{
+			ID:               uuid.New(),
+			OrderID:          order.ID,
+			ProductID:        item.ProductID,
+			ProductName:      item.Product.Name,
+			UnitPrice:        line.UnitPrice,
+			Quantity:         line.Quantity,
+			LineTotal:        line.LineTotal,
+			RequiresShipping: line.RequiresShipping,
+			IsCourse:         isCourse,
+			CreatedAt:        now,
+		})
+		if line.RequiresShipping {
+			order.RequiresShipping = true
+		}
+	}
+
+	var address *models.Address
+	if order.RequiresShipping {
+		if req.ShippingAddressID == "" {
+			return nil, errors.New("shipping_address_id is required for items that ship")
+		}
+		address, err = s.loadAddress(userID, req.ShippingAddressID)
+		if err != nil {
+			return nil, err
+		}
+		order.ShippingAddressID = &address.ID
+		order.ShippingAddress = formatAddress(address)
+	}
+
+	if err := s.price(order, lines, address); err != nil {
+		return nil, err
+	}
+
+	// Charge up front when the payer has a saved gateway customer. The order is saved as
+	// charging first so it cannot be paid or cancelled while the gateway call runs.
+	payNow := order.TotalAmount > 0 && strings.TrimSpace(req.GatewayCustomerID) != ""
+	if payNow {
+		if req.PaymentMethod == "" {
+			return nil, errors.New("payment_method is required to pay now")
+		}
+		order.Status = "charging"
+		order.PaymentAttempts = 1
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Omit("User").Create(order).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create order: " + err.Error())
+	}
+	if err := tx.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to clear cart: " + err.Error())
+	}
+	if order.TotalAmount == 0 {
+		if err := s.markPaid(tx, order, nil, userID, now); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to create order: " + err.Error())
+	}
+
+	if !payNow {
+		return s.getOrder(order.ID)
+	}
+	payment := s.newPayment(order, &user, req.PaymentMethod, order.Gateway, now)
+	payment.PaymentID = chargeReference(order)
+	result, err := s.charge(order, &user, payment, strings.TrimSpace(req.GatewayCustomerID), strings.TrimSpace(req.GatewayPaymentMethod))
+	if err != nil {
+		s.abandonCharge(order.ID)
+		return nil, fmt.Errorf("payment failed: %s; order %s is saved and can be paid later", err.Error(), order.OrderNumber)
+	}
+	payment.GatewayReference = result.Reference
+
+	return s.settle(order.ID, payment, userID, now, "charging")
+}
+
+// PayOrder charges a pending order through the payer's saved gateway customer
+func (s *OrderService) PayOrder(id string, userID uuid.UUID, req *dto.PayOrderRequest) (*dto.OrderResponse, error) {
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+
+	order, err := s.beginCharge(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	payment := s.newPayment(order, &user, req.PaymentMethod, req.Gateway, now)
+	payment.PaymentID = chargeReference(order)
+	result, err := s.charge(order, &user, payment, strings.TrimSpace(req.GatewayCustomerID), strings.TrimSpace(req.GatewayPaymentMethod))
+	if err != nil {
+		s.abandonCharge(order.ID)
+		return nil, errors.New("payment failed: " + err.Error())
+	}
+	payment.GatewayReference = result.Reference
+
+	return s.settle(order.ID, payment, userID, now, "charging")
+}
+
+// beginCharge moves a pending order to charging under a row lock, so only one gateway charge
+// runs for it at a time and it cannot be cancelled meanwhile. A charge abandoned past the
+// gateway timeout is taken over with the same reference, which the gateway will not charge twice.
+func (s *OrderService) beginCharge(id string, userID uuid.UUID) (*models.Order, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.lockOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if order.UserID != userID {
+		tx.Rollback()
+		return nil, errors.New("not authorized to pay this order")
+	}
+
+	now := time.Now()
+	switch {
+	case order.Status == "pending":
+		order.PaymentAttempts++
+	case order.Status == "charging" && now.Sub(order.UpdatedAt) > 2*chargeTimeout:
+		// Resumed with the abandoned attempt's reference
+	case order.Status == "charging":
+		tx.Rollback()
+		return nil, errors.New("order payment is already in progress")
+	default:
+		tx.Rollback()
+		return nil, errors.New("order is already " + order.Status)
+	}
+
+	order.Status = "charging"
+	order.UpdatedAt = now
+	if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+		"status":           order.Status,
+		"payment_attempts": order.PaymentAttempts,
+		"updated_at":       now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update order: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to update order: " + err.Error())
+	}
+	return order, nil
+}
+
+// abandonCharge returns an order whose charge failed to pending so it can be paid again
+func (s *OrderService) abandonCharge(orderID uuid.UUID) {
+	if err := s.db.Model(&models.Order{}).Where("id = ? AND status = ?", orderID, "charging").Updates(map[string]interface{}{
+		"status":     "pending",
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		log.Printf("failed to reopen order %s after a failed charge: %v", orderID, err)
+	}
+}
+
+// chargeReference is the payment reference for an order's current charge attempt. The gateway
+// uses it as the idempotency key, so retrying an attempt never charges twice while a new
+// attempt after a failure is charged afresh.
+func chargeReference(order *models.Order) string {
+	return fmt.Sprintf("PAY-%s-%d", strings.TrimPrefix(order.OrderNumber, "ORD-"), order.PaymentAttempts)
+}
+
+// RecordPayment settles a pending order paid outside the platform, e.g. by bank transfer
+func (s *OrderService) RecordPayment(id string, adminID uuid.UUID, req *dto.RecordOrderPaymentRequest) (*dto.OrderResponse, error) {
+	order, err := s.loadOrder(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != "pending" {
+		return nil, errors.New("order is already " + order.Status)
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", order.UserID).First(&user).Error; err != nil {
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+
+	gateway := req.Gateway
+	if gateway == "" {
+		gateway = "manual"
+	}
+
+	now := time.Now()
+	payment := s.newPayment(order, &user, req.PaymentMethod, gateway, now)
+	payment.GatewayReference = strings.TrimSpace(req.GatewayReference)
+
+	return s.settle(order.ID, payment, adminID, now, "pending")
+}
+
+// CancelOrder cancels an unpaid order; paid orders are refunded through refund requests
+func (s *OrderService) CancelOrder(id string, userID uuid.UUID, canManage bool) (*dto.OrderResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.lockOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !canManage && order.UserID != userID {
+		tx.Rollback()
+		return nil, errors.New("not authorized to cancel this order")
+	}
+	if order.Status == "cancelled" {
+		tx.Rollback()
+		return nil, errors.New("order is already cancelled")
+	}
+	if order.Status == "charging" {
+		tx.Rollback()
+		return nil, errors.New("order payment is already in progress")
+	}
+	if order.Status != "pending" {
+		tx.Rollback()
+		return nil, errors.New("only pending orders can be cancelled; request a refund for paid orders")
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+		"status":       "cancelled",
+		"cancelled_at": now,
+		"cancelled_by": userID,
+		"updated_at":   now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to cancel order: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to cancel order: " + err.Error())
+	}
+
+	return s.getOrder(order.ID)
+}
+
+// UpdateOrderStatus moves a paid order that ships through processing, shipped and completed
+func (s *OrderService) UpdateOrderStatus(id string, req *dto.UpdateOrderStatusRequest) (*dto.OrderResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.lockOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if order.Status == req.Status {
+		tx.Rollback()
+		return nil, errors.New("order is already " + order.Status)
+	}
+
+	allowed := false
+	for _, next := range nextStatuses[order.Status] {
+		if next == req.Status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		tx.Rollback()
+		return nil, fmt.Errorf("cannot move order from %s to %s", order.Status, req.Status)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":     req.Status,
+		"updated_at": now,
+	}
+	if tracking := strings.TrimSpace(req.TrackingNumber); tracking != "" {
+		updates["tracking_number"] = tracking
+	}
+	switch req.Status {
+	case "shipped":
+		updates["shipped_at"] = now
+	case "completed":
+		if order.ShippedAt == nil && order.RequiresShipping {
+			updates["shipped_at"] = now
+		}
+		updates["completed_at"] = now
+	}
+
+	if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update order: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to update order: " + err.Error())
+	}
+
+	response, err := s.getOrder(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status == "shipped" {
+		s.notifyShipped(order.UserID, response)
+	}
+	return response, nil
+}
+
+// GetOrders lists orders with pagination and filters
+func (s *OrderService) GetOrders(params *dto.OrderQueryParams) (*dto.OrderListResponse, error) {
+	// Set defaults
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	query := s.db.Model(&models.Order{})
+	if params.UserID != "" {
+		query = query.Where("user_id = ?", params.UserID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.From != "" {
+		from, err := time.Parse("2006-01-02", params.From)
+		if err != nil {
+			return nil, errors.New("invalid from date format, use YYYY-MM-DD")
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if params.To != "" {
+		to, err := time.Parse("2006-01-02", params.To)
+		if err != nil {
+			return nil, errors.New("invalid to date format, use YYYY-MM-DD")
+		}
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("failed to count orders: " + err.Error())
+	}
+
+	var orders []models.Order
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Preload("User").Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Order("created_at DESC").Offset(offset).Limit(params.Limit).Find(&orders).Error; err != nil {
+		return nil, errors.New("failed to fetch orders: " + err.Error())
+	}
+
+	responses := make([]dto.OrderResponse, 0, len(orders))
+	for i := range orders {
+		responses = append(responses, *s.toOrderResponse(&orders[i]))
+	}
+
+	totalPages := int(total) / params.Limit
+	if int(total)%params.Limit > 0 {
+		totalPages++
+	}
+
+	return &dto.OrderListResponse{
+		Orders:     responses,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetOrderByID retrieves an order; non-admins may only see their own
+func (s *OrderService) GetOrderByID(id string, userID uuid.UUID, canManage bool) (*dto.OrderResponse, error) {
+	orderID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid order ID")
+	}
+
+	order, err := s.getOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage && order.UserID != userID.String() {
+		return nil, errors.New("not authorized to view this order")
+	}
+	return order, nil
+}
+
+// settle records a captured payment against an order still in the from status and fulfils it
+func (s *OrderService) settle(orderID uuid.UUID, payment *models.Payment, actorID uuid.UUID, now time.Time, from string) (*dto.OrderResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.lockOrder(tx, orderID.String())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if order.Status != from {
+		tx.Rollback()
+		return nil, errors.New("order is already " + order.Status)
+	}
+
+	if err := s.markPaid(tx, order, payment, actorID, now); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to record payment: " + err.Error())
+	}
+
+	return s.getOrder(order.ID)
+}
+
+// markPaid stores the payment, marks the order paid and converts course lines into
+// enrollments. Orders with nothing to ship are completed straight away.
+func (s *OrderService) markPaid(tx *gorm.DB, order *models.Order, payment *models.Payment, actorID uuid.UUID, now time.Time) error {
+	transactionID := order.OrderNumber
+	paymentStatus := "free"
+	if payment != nil {
+		if err := tx.Omit("Payer", "Course").Create(payment).Error; err != nil {
+			return errors.New("failed to record payment: " + err.Error())
+		}
+		order.PaymentID = &payment.ID
+		order.PaymentMethod = payment.PaymentMethod
+		order.Gateway = payment.Gateway
+		transactionID = payment.PaymentID
+		paymentStatus = "paid"
+	}
+
+	order.Status = "paid"
+	order.PaidAt = &now
+	if !order.RequiresShipping {
+		order.Status = "completed"
+		order.CompletedAt = &now
+	}
+	if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+		"status":         order.Status,
+		"payment_id":     order.PaymentID,
+		"payment_method": order.PaymentMethod,
+		"gateway":        order.Gateway,
+		"paid_at":        order.PaidAt,
+		"completed_at":   order.CompletedAt,
+		"updated_at":     now,
+	}).Error; err != nil {
+		return errors.New("failed to update order: " + err.Error())
+	}
+
+	if err := s.fulfilCourses(tx, order, transactionID, paymentStatus, actorID, now); err != nil {
+		return err
+	}
+
+	if err := s.activity.Orders.Paid(tx, actorID, *order); err != nil {
+		return errors.New("failed to log order payment: " + err.Error())
+	}
+	return nil
+}
+
+// fulfilCourses enrolls the buyer in every course sold by the order's course lines,
+// splitting each line's price across the courses it grants
+func (s *OrderService) fulfilCourses(tx *gorm.DB, order *models.Order, transactionID, paymentStatus string, actorID uuid.UUID, now time.Time) error {
+	var items []models.OrderItem
+	if err := tx.Where("order_id = ? AND is_course = ? AND fulfilled_at IS NULL", order.ID, true).Find(&items).Error; err != nil {
+		return errors.New("failed to fetch order items: " + err.Error())
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	links, err := s.courseLinks(tx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		courseIDs := links[item.ProductID]
+		for i, courseID := range courseIDs {
+			share := roundMoney(item.LineTotal / float64(len(courseIDs)))
+			if i == len(courseIDs)-1 {
+				share = roundMoney(item.LineTotal - share*float64(len(courseIDs)-1))
+			}
+			if err := s.enroll(tx, order, courseID, share, transactionID, paymentStatus, actorID, now); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.OrderItem{}).Where("id = ?", item.ID).Update("fulfilled_at", now).Error; err != nil {
+			return errors.New("failed to update order item: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// enroll creates or reactivates the buyer's enrollment in a course; a student who
+// already has live access keeps their existing enrollment
+func (s *OrderService) enroll(tx *gorm.DB, order *models.Order, courseID uuid.UUID, pricePaid float64, transactionID, paymentStatus string, actorID uuid.UUID, now time.Time) error {
+	var enrollment models.Enrollment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("student_id = ? AND course_id = ?", order.UserID, courseID).
+		First(&enrollment).Error
+
+	switch {
+	case err == nil:
+		live := enrollment.Status == "active" || enrollment.Status == "completed"
+		expired := enrollment.ExpirationDate != nil && !enrollment.ExpirationDate.After(now)
+		if live && !expired && enrollment.AccessLevel == "full" {
+			return nil
+		}
+
+		enrollment.Status = "active"
+		enrollment.StartDate = &now
+		enrollment.ExpirationDate = nil
+		enrollment.PricePaid = pricePaid
+		enrollment.Currency = order.Currency
+		enrollment.PaymentMethod = order.PaymentMethod
+		enrollment.PaymentStatus = paymentStatus
+		enrollment.TransactionID = transactionID
+		enrollment.AccessLevel = "full"
+		enrollment.TrialEndsAt = nil
+		if err := tx.Model(&models.Enrollment{}).Where("id = ?", enrollment.ID).Updates(map[string]interface{}{
+			"status":          enrollment.Status,
+			"start_date":      enrollment.StartDate,
+			"expiration_date": nil,
+			"price_paid":      enrollment.PricePaid,
+			"currency":        enrollment.Currency,
+			"payment_method":  enrollment.PaymentMethod,
+			"payment_status":  enrollment.PaymentStatus,
+			"transaction_id":  enrollment.TransactionID,
+			"access_level":    enrollment.AccessLevel,
+			"trial_ends_at":   nil,
+		}).Error; err != nil {
+			return errors.New("failed to update enrollment: " + err.Error())
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		enrollment = models.Enrollment{
+			ID:             uuid.New(),
+			StudentID:      order.UserID,
+			CourseID:       courseID,
+			Status:         "active",
+			EnrollmentDate: now,
+			StartDate:      &now,
+			PricePaid:      pricePaid,
+			Currency:       order.Currency,
+			PaymentMethod:  order.PaymentMethod,
+			PaymentStatus:  paymentStatus,
+			TransactionID:  transactionID,
+			AccessLevel:    "full",
+		}
+		if err := tx.Omit("Student", "Course", "Certificate").Create(&enrollment).Error; err != nil {
+			return errors.New("failed to create enrollment: " + err.Error())
+		}
+	default:
+		return errors.New("failed to fetch enrollment: " + err.Error())
+	}
+
+	if err := s.activity.Enrollments.Enrolled(tx, actorID, enrollment, *order); err != nil {
+		return errors.New("failed to log enrollment: " + err.Error())
+	}
+	return nil
+}
+
+// price fills in the order's subtotal, tax, shipping and total
+func (s *OrderService) price(order *models.Order, lines []PricingLine, address *models.Address) error {
+	subtotal := 0.0
+	for _, line := range lines {
+		subtotal += line.LineTotal
+	}
+
+	pricing := PricingContext{
+		UserID:   order.UserID,
+		Currency: order.Currency,
+		Subtotal: roundMoney(subtotal),
+		Lines:    lines,
+		Address:  address,
+	}
+
+	tax, err := s.tax.Tax(pricing)
+	if err != nil {
+		return errors.New("failed to calculate tax: " + err.Error())
+	}
+	shipping, err := s.shipping.Shipping(pricing)
+	if err != nil {
+		return errors.New("failed to calculate shipping: " + err.Error())
+	}
+
+	order.Subtotal = pricing.Subtotal
+	order.TaxAmount = roundMoney(tax)
+	order.ShippingAmount = roundMoney(shipping)
+	order.TotalAmount = roundMoney(order.Subtotal + order.TaxAmount + order.ShippingAmount)
+	return nil
+}
+
+// charge takes the order total through the chosen gateway
+func (s *OrderService) charge(order *models.Order, user *models.User, payment *models.Payment, customerID, paymentMethod string) (*payments.ChargeResult, error) {
+	provider, err := payments.ProviderFor(payment.Gateway)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chargeTimeout)
+	defer cancel()
+	return provider.Charge(ctx, payments.ChargeRequest{
+		Reference:     payment.PaymentID,
+		CustomerID:    customerID,
+		PaymentMethod: paymentMethod,
+		Email:         user.Email,
+		Amount:        order.TotalAmount,
+		Currency:      order.Currency,
+		Description:   "Order " + order.OrderNumber,
+	})
+}
+
+// newPayment builds a completed payment row for an order
+func (s *OrderService) newPayment(order *models.Order, user *models.User, method, gateway string, now time.Time) *models.Payment {
+	return &models.Payment{
+		ID:            uuid.New(),
+		PaymentID:     referenceNumber("PAY", now),
+		PayerID:       order.UserID,
+		PayerEmail:    user.Email,
+		PayerName:     strings.TrimSpace(user.FirstName + " " + user.LastName),
+		PayerPhone:    user.Phone,
+		Amount:        order.TotalAmount,
+		Currency:      order.Currency,
+		PaymentMethod: method,
+		Gateway:       gateway,
+		Status:        "completed",
+		InitiatedAt:   now,
+		ProcessedAt:   &now,
+		OrderID:       &order.ID,
+		CourseName:    "Order " + order.OrderNumber,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// notifyShipped emails the customer when their order ships
+func (s *OrderService) notifyShipped(userID uuid.UUID, order *dto.OrderResponse) {
+	var user models.User
+	if err := s.db.Select("id, email, first_name").Where("id = ?", userID).First(&user).Error; err != nil || user.Email == "" {
+		return
+	}
+
+	subject := "Your order " + order.OrderNumber + " has shipped"
+	body := fmt.Sprintf("<p>Hello %s,</p><p>Your order <strong>%s</strong> is on its way to:</p><p>%s</p>",
+		user.FirstName, order.OrderNumber, strings.ReplaceAll(order.ShippingAddress, "\n", "<br>"))
+	if order.TrackingNumber != "" {
+		body += fmt.Sprintf("<p>Tracking number: <strong>%s</strong></p>", order.TrackingNumber)
+	}
+
+	if err := utils.SendEmail(user.Email, subject, body); err != nil {
+		log.Printf("order email to %s failed: %v", user.Email, err)
+	}
+}
+
+// courseLinks maps each product to the courses it sells
+func (s *OrderService) courseLinks(db *gorm.DB, productIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	links := make(map[uuid.UUID][]uuid.UUID, len(productIDs))
+	if len(productIDs) == 0 {
+		return links, nil
+	}
+
+	var rows []models.CourseProductTable
+	if err := db.Where("product_id IN ?", productIDs).Order("created_at ASC").Find(&rows).Error; err != nil {
+		return nil, errors.New("failed to fetch product courses: " + err.Error())
+	}
+	for _, row := range rows {
+		links[row.ProductID] = append(links[row.ProductID], row.CourseID)
+	}
+	return links, nil
+}
+
+// checkNotEnrolled rejects buying a course the user can already open
+func (s *OrderService) checkNotEnrolled(userID uuid.UUID, courseIDs []uuid.UUID) error {
+	var enrollment models.Enrollment
+	err := s.db.Preload("Course").
+		Where("student_id = ? AND course_id IN ? AND status IN ? AND access_level = ?", userID, courseIDs, []string{"active", "completed"}, "full").
+		Where("expiration_date IS NULL OR expiration_date > ?", time.Now()).
+		First(&enrollment).Error
+	if err == nil {
+		title := enrollment.CourseID.String()
+		if enrollment.Course != nil {
+			title = enrollment.Course.Title
+		}
+		return errors.New("already enrolled in course '" + title + "'")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("failed to check enrollments: " + err.Error())
+	}
+	return nil
+}
+
+// checkPublished rejects products that would enroll the buyer on a course that is not live
+func (s *OrderService) checkPublished(courseIDs []uuid.UUID) error {
+	var course models.Course
+	err := s.db.Select("id, title").Where("id IN ? AND status <> ?", courseIDs, "published").First(&course).Error
+	if err == nil {
+		return errors.New("course '" + course.Title + "' is not available for purchase")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("failed to check courses: " + err.Error())
+	}
+	return nil
+}
+
+// findOrCreateCart returns the user's cart, creating it on first use
+func (s *OrderService) findOrCreateCart(userID uuid.UUID) (*models.Cart, error) {
+	now := time.Now()
+	cart := models.Cart{ID: uuid.New(), UserID: userID, CreatedAt: now, UpdatedAt: now}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Items").Create(&cart).Error; err != nil {
+		return nil, errors.New("failed to create cart: " + err.Error())
+	}
+
+	// Re-read so a cart created concurrently is the one returned
+	var existing models.Cart
+	if err := s.db.Where("user_id = ?", userID).First(&existing).Error; err != nil {
+		return nil, errors.New("failed to fetch cart: " + err.Error())
+	}
+	return &existing, nil
+}
+
+// findCartItem fetches a product's line in the user's cart
+func (s *OrderService) findCartItem(userID uuid.UUID, productID string) (*models.CartItem, error) {
+	id, err := uuid.Parse(productID)
+	if err != nil {
+		return nil, errors.New("invalid product ID")
+	}
+
+	var item models.CartItem
+	if err := s.db.Joins("JOIN carts ON carts.id = cart_items.cart_id").
+		Where("carts.user_id = ? AND cart_items.product_id = ?", userID, id).
+		First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart item not found")
+		}
+		return nil, errors.New("failed to fetch cart item: " + err.Error())
+	}
+	return &item, nil
+}
+
+// loadProduct fetches a product by ID
+func (s *OrderService) loadProduct(id uuid.UUID) (*models.Product, error) {
+	var product models.Product
+	if err := s.db.Where("id = ?", id).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, errors.New("failed to fetch product: " + err.Error())
+	}
+	return &product, nil
+}
+
+// loadAddress fetches one of the user's active addresses
+func (s *OrderService) loadAddress(userID uuid.UUID, id string) (*models.Address, error) {
+	addressID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid shipping address ID")
+	}
+
+	var address models.Address
+	if err := s.db.Where("id = ? AND user_id = ? AND status = ?", addressID, userID, "active").First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("shipping address not found")
+		}
+		return nil, errors.New("failed to fetch address: " + err.Error())
+	}
+	return &address, nil
+}
+
+// loadOrder fetches an order by ID
+func (s *OrderService) loadOrder(db *gorm.DB, id string) (*models.Order, error) {
+	orderID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid order ID")
+	}
+
+	var order models.Order
+	if err := db.Where("id = ?", orderID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, errors.New("failed to fetch order: " + err.Error())
+	}
+	return &order, nil
+}
+
+// lockOrder fetches an order under a row lock
+func (s *OrderService) lockOrder(tx *gorm.DB, id string) (*models.Order, error) {
+	return s.loadOrder(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+// getOrder loads an order with its lines for a response
+func (s *OrderService) getOrder(id uuid.UUID) (*dto.OrderResponse, error) {
+	var order models.Order
+	if err := s.db.Preload("User").Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("id = ?", id).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, errors.New("failed to fetch order: " + err.Error())
+	}
+	return s.toOrderResponse(&order), nil
+}
+
+// currency is the store's order currency
+func (s *OrderService) currency() string {
+	currency := strings.ToUpper(strings.TrimSpace(cfg.StoreCurrency))
+	if currency == "" {
+		return "USD"
+	}
+	return currency
+}
+
+// formatAddress snapshots an address onto an order
+func formatAddress(address *models.Address) string {
+	parts := []string{strings.TrimSpace(address.Address)}
+	cityLine := strings.TrimSpace(strings.Join(nonEmpty(address.City, address.State, address.PostalCode), ", "))
+	if cityLine != "" {
+		parts = append(parts, cityLine)
+	}
+	if address.Country != "" {
+		parts = append(parts, address.Country)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func referenceNumber(prefix string, now time.Time) string {
+	return prefix + "-" + now.Format("20060102") + "-" + strings.ToUpper(uuid.New().String()[:8])
+}
+
+// toCartResponse prices a cart with the current tax and shipping calculators
+func (s *OrderService) toCartResponse(userID uuid.UUID, cart *models.Cart) (*dto.CartResponse, error) {
+	productIDs := make([]uuid.UUID, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	links, err := s.courseLinks(s.db, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CartResponse{
+		ID:       cart.ID.String(),
+		Currency: s.currency(),
+		Items:    make([]dto.CartItemResponse, 0, len(cart.Items)),
+	}
+
+	lines := make([]PricingLine, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		isCourse := len(links[item.ProductID]) > 0
+		available := item.Product.Status == "active"
+		line := PricingLine{
+			ProductID:        item.ProductID,
+			UnitPrice:        roundMoney(item.Product.Price),
+			Quantity:         item.Quantity,
+			LineTotal:        roundMoney(item.Product.Price * float64(item.Quantity)),
+			RequiresShipping: item.Product.RequiresShipping && !isCourse,
+			IsCourse:         isCourse,
+		}
+
+		response.Items = append(response.Items, dto.CartItemResponse{
+			ID:               item.ID.String(),
+			ProductID:        item.ProductID.String(),
+			ProductName:      item.Product.Name,
+			Image:            item.Product.Image,
+			UnitPrice:        line.UnitPrice,
+			Quantity:         line.Quantity,
+			LineTotal:        line.LineTotal,
+			RequiresShipping: line.RequiresShipping,
+			IsCourse:         isCourse,
+			Available:        available,
+		})
+		if !available {
+			continue
+		}
+		lines = append(lines, line)
+		if line.RequiresShipping {
+			response.RequiresShipping = true
+		}
+	}
+
+	// Estimate without an address; the final figures are fixed at checkout
+	estimate := &models.Order{UserID: userID, Currency: response.Currency}
+	if err := s.price(estimate, lines, nil); err != nil {
+		return nil, err
+	}
+	response.Subtotal = estimate.Subtotal
+	response.TaxAmount = estimate.TaxAmount
+	response.ShippingAmount = estimate.ShippingAmount
+	response.TotalAmount = estimate.TotalAmount
+	return response, nil
+}
+
+// toOrderResponse converts model to response DTO
+func (s *OrderService) toOrderResponse(order *models.Order) *dto.OrderResponse {
+	response := &dto.OrderResponse{
+		ID:               order.ID.String(),
+		OrderNumber:      order.OrderNumber,
+		UserID:           order.UserID.String(),
+		CustomerName:     strings.TrimSpace(order.User.FirstName + " " + order.User.LastName),
+		Status:           order.Status,
+		Currency:         order.Currency,
+		Subtotal:         order.Subtotal,
+		TaxAmount:        order.TaxAmount,
+		ShippingAmount:   order.ShippingAmount,
+		TotalAmount:      order.TotalAmount,
+		RequiresShipping: order.RequiresShipping,
+		ShippingAddress:  order.ShippingAddress,
+		TrackingNumber:   order.TrackingNumber,
+		Notes:            order.Notes,
+		Gateway:          order.Gateway,
+		PaymentMethod:    order.PaymentMethod,
+		PaidAt:           order.PaidAt,
+		ShippedAt:        order.ShippedAt,
+		CompletedAt:      order.CompletedAt,
+		CancelledAt:      order.CancelledAt,
+		Items:            make([]dto.OrderItemResponse, 0, len(order.Items)),
+		CreatedAt:        order.CreatedAt,
+		UpdatedAt:        order.UpdatedAt,
+	}
+	if order.ShippingAddressID != nil {
+		addressID := order.ShippingAddressID.String()
+		response.ShippingAddressID = &addressID
+	}
+	if order.PaymentID != nil {
+		paymentID := order.PaymentID.String()
+		response.PaymentID = &paymentID
+	}
+
+	for _, item := range order.Items {
+		response.Items = append(response.Items, dto.OrderItemResponse{
+			ID:               item.ID.String(),
+			ProductID:        item.ProductID.String(),
+			ProductName:      item.ProductName,
+			UnitPrice:        item.UnitPrice,
+			Quantity:         item.Quantity,
+			LineTotal:        item.LineTotal,
+			RequiresShipping: item.RequiresShipping,
+			IsCourse:         item.IsCourse,
+			FulfilledAt:      item.FulfilledAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+
+	"crm-go/models"
+)
+
+func TestChargeReference(t *testing.T) {
+	order := &models.Order{OrderNumber: "ORD-20260301-1A2B3C4D", PaymentAttempts: 1}
+
+	first := chargeReference(order)
+	if first != "PAY-20260301-1A2B3C4D-1" {
+		t.Errorf("chargeReference() = %q", first)
+	}
+	// Retrying the same attempt must reuse the reference so the gateway can spot the repeat
+	if again := chargeReference(order); again != first {
+		t.Errorf("chargeReference() changed between calls: %q then %q", first, again)
+	}
+
+	order.PaymentAttempts++
+	if next := chargeReference(order); next == first {
+		t.Errorf("a new attempt reused reference %q", next)
+	}
+
+	other := &models.Order{OrderNumber: "ORD-20260301-5E6F7A8B", PaymentAttempts: 1}
+	if chargeReference(other) == first {
+		t.Error("two orders share a charge reference")
+	}
+}
@@ -0,0 +1,80 @@
+// services/pricing.go
+package services
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+
+	"crm-go/models"
+)
+
+// PricingLine is an order or cart line as seen by the tax and shipping calculators
+type PricingLine struct {
+	ProductID        uuid.UUID
+	UnitPrice        float64
+	Quantity         int
+	LineTotal        float64
+	RequiresShipping bool
+	IsCourse         bool
+}
+
+// PricingContext is everything a calculator may base its amount on
+type PricingContext struct {
+	UserID   uuid.UUID
+	Currency string
+	Subtotal float64
+	Lines    []PricingLine
+	Address  *models.Address // nil until an address is chosen at checkout
+}
+
+// TaxCalculator returns the tax to add to an order
+type TaxCalculator interface {
+	Tax(ctx PricingContext) (float64, error)
+}
+
+// ShippingCalculator returns the shipping charge for an order's shippable lines
+type ShippingCalculator interface {
+	Shipping(ctx PricingContext) (float64, error)
+}
+
+// FlatRateTax charges a single percentage on the subtotal
+type FlatRateTax struct {
+	RatePercent float64
+}
+
+func (t FlatRateTax) Tax(ctx PricingContext) (float64, error) {
+	if t.RatePercent <= 0 {
+		return 0, nil
+	}
+	return roundMoney(ctx.Subtotal * t.RatePercent / 100), nil
+}
+
+// FlatRateShipping charges one fee per order that ships, waived above a threshold
+type FlatRateShipping struct {
+	Fee           float64
+	FreeThreshold float64 // 0 disables free shipping
+}
+
+func (s FlatRateShipping) Shipping(ctx PricingContext) (float64, error) {
+	shippable := 0.0
+	ships := false
+	for _, line := range ctx.Lines {
+		if line.RequiresShipping {
+			ships = true
+			shippable += line.LineTotal
+		}
+	}
+	if !ships || s.Fee <= 0 {
+		return 0, nil
+	}
+	if s.FreeThreshold > 0 && shippable >= s.FreeThreshold {
+		return 0, nil
+	}
+	return roundMoney(s.Fee), nil
+}
+
+// roundMoney rounds an amount to cents
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"crm-go/models"
+)
+
+func TestFlatRateTax(t *testing.T) {
+	t.Run("charges the rate on the subtotal", func(t *testing.T) {
+		tax, err := FlatRateTax{RatePercent: 7.5}.Tax(PricingContext{Subtotal: 59.99})
+		if err != nil || tax != 4.5 {
+			t.Errorf("Tax() = %v, %v, want 4.5", tax, err)
+		}
+	})
+
+	t.Run("no rate, no tax", func(t *testing.T) {
+		tax, err := FlatRateTax{}.Tax(PricingContext{Subtotal: 100})
+		if err != nil || tax != 0 {
+			t.Errorf("Tax() = %v, %v, want 0", tax, err)
+		}
+	})
+}
+
+func TestFlatRateShipping(t *testing.T) {
+	shipping := FlatRateShipping{Fee: 9.99, FreeThreshold: 50}
+	book := PricingLine{LineTotal: 30, RequiresShipping: true}
+	course := PricingLine{LineTotal: 200, IsCourse: true}
+
+	t.Run("nothing ships", func(t *testing.T) {
+		if fee, _ := shipping.Shipping(PricingContext{Lines: []PricingLine{course}}); fee != 0 {
+			t.Errorf("Shipping() = %v, want 0", fee)
+		}
+	})
+
+	t.Run("one fee per order", func(t *testing.T) {
+		if fee, _ := shipping.Shipping(PricingContext{Lines: []PricingLine{book, course}}); fee != 9.99 {
+			t.Errorf("Shipping() = %v, want 9.99", fee)
+		}
+	})
+
+	t.Run("free above the threshold counts only shipped lines", func(t *testing.T) {
+		if fee, _ := shipping.Shipping(PricingContext{Lines: []PricingLine{book, book}}); fee != 0 {
+			t.Errorf("Shipping() with 60 of shipped goods = %v, want 0", fee)
+		}
+	})
+
+	t.Run("no threshold always charges", func(t *testing.T) {
+		noThreshold := FlatRateShipping{Fee: 5}
+		big := PricingLine{LineTotal: 10000, RequiresShipping: true}
+		if fee, _ := noThreshold.Shipping(PricingContext{Lines: []PricingLine{big}}); fee != 5 {
+			t.Errorf("Shipping() = %v, want 5", fee)
+		}
+	})
+}
+
+// fixedCalculator returns the same amount, or error, for every order
+type fixedCalculator struct {
+	amount float64
+	err    error
+	seen   *PricingContext
+}
+
+func (c fixedCalculator) Tax(ctx PricingContext) (float64, error) {
+	if c.seen != nil {
+		*c.seen = ctx
+	}
+	return c.amount, c.err
+}
+
+func (c fixedCalculator) Shipping(ctx PricingContext) (float64, error) {
+	return c.amount, c.err
+}
+
+func TestPriceUsesTheConfiguredCalculators(t *testing.T) {
+	var seen PricingContext
+	s := &OrderService{}
+	s.UseCalculators(fixedCalculator{amount: 1.234, seen: &seen}, fixedCalculator{amount: 4.5})
+
+	order := &models.Order{UserID: uuid.New(), Currency: "USD"}
+	lines := []PricingLine{{LineTotal: 10.10}, {LineTotal: 20.21}}
+	if err := s.price(order, lines, nil); err != nil {
+		t.Fatalf("price() error = %v", err)
+	}
+
+	if seen.Subtotal != 30.31 || seen.UserID != order.UserID || len(seen.Lines) != 2 {
+		t.Errorf("tax calculator saw %+v", seen)
+	}
+	if order.Subtotal != 30.31 || order.TaxAmount != 1.23 || order.ShippingAmount != 4.5 || order.TotalAmount != 36.04 {
+		t.Errorf("order priced at %.2f + %.2f + %.2f = %.2f, want 30.31 + 1.23 + 4.50 = 36.04",
+			order.Subtotal, order.TaxAmount, order.ShippingAmount, order.TotalAmount)
+	}
+}
+
+func TestPriceReportsCalculatorFailures(t *testing.T) {
+	s := &OrderService{tax: FlatRateTax{}, shipping: fixedCalculator{err: errors.New("no rate for this country")}}
+
+	err := s.price(&models.Order{}, []PricingLine{{LineTotal: 10, RequiresShipping: true}}, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to calculate shipping") {
+		t.Errorf("price() error = %v, want a shipping failure", err)
+	}
+}
+
+func TestUseCalculatorsKeepsDefaultsForNil(t *testing.T) {
+	tax := FlatRateTax{RatePercent: 10}
+	s := &OrderService{tax: tax}
+	s.UseCalculators(nil, nil)
+	if s.tax != tax {
+		t.Errorf("UseCalculators(nil, nil) replaced the tax calculator with %v", s.tax)
+	}
+}
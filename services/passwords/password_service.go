@@ -0,0 +1,320 @@
+// services/password_service.go
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/models"
+	sessionServices "crm-go/services/sessions"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+// bcrypt ignores everything after the 72nd byte, so longer passwords are refused rather than truncated
+const maxPasswordBytes = 72
+
+// PasswordService applies the password policy and owns every change to a user's password:
+// signup, invitation acceptance, change and reset
+type PasswordService struct {
+	db *gorm.DB
+}
+
+func NewPasswordService(db *gorm.DB) *PasswordService {
+	return &PasswordService{db: db}
+}
+
+// Validate checks a password against the policy. user is nil for accounts that do not exist
+// yet, which have no previous passwords to compare against.
+func (s *PasswordService) Validate(user *models.User, password string) error {
+	if len([]rune(password)) < cfg.PasswordMinLength {
+		return fmt.Errorf("password must be at least %d characters", cfg.PasswordMinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("password must be at most %d bytes", maxPasswordBytes)
+	}
+	if user != nil && strings.EqualFold(password, user.Email) {
+		return errors.New("password must not be your email address")
+	}
+
+	breached, err := utils.IsBreachedPassword(password)
+	if err != nil {
+		// A missing or unreadable list should not stop everyone changing their password
+		log.Printf("⚠️ Breached password check skipped: %v", err)
+	} else if breached {
+		return errors.New("this password has appeared in a data breach; choose a different one")
+	}
+
+	if user != nil && cfg.PasswordHistoryCount > 0 {
+		reused, err := s.recentlyUsed(s.db, user, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			return fmt.Errorf("password must differ from your last %d passwords", cfg.PasswordHistoryCount)
+		}
+	}
+	return nil
+}
+
+// HashNewPassword validates and hashes the password for an account being created
+func (s *PasswordService) HashNewPassword(password string) (string, error) {
+	if err := s.Validate(nil, password); err != nil {
+		return "", err
+	}
+	return hashPassword(password)
+}
+
+// SetPassword validates and stores a new password inside the caller's transaction, moving the
+// old hash into the user's history
+func (s *PasswordService) SetPassword(tx *gorm.DB, user *models.User, password string) error {
+	if err := s.Validate(user, password); err != nil {
+		return err
+	}
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	// The current password is always checked, so the history holds the ones before it
+	if user.Password != "" && cfg.PasswordHistoryCount > 1 {
+		if err := tx.Create(&models.PasswordHistory{
+			UserID:       user.ID,
+			PasswordHash: user.Password,
+			CreatedAt:    now,
+		}).Error; err != nil {
+			return errors.New("failed to record password history: " + err.Error())
+		}
+		if err := tx.Exec(`DELETE FROM password_histories WHERE user_id = ? AND id NOT IN (
+			SELECT id FROM password_histories WHERE user_id = ? ORDER BY created_at DESC LIMIT ?)`,
+			user.ID, user.ID, cfg.PasswordHistoryCount-1).Error; err != nil {
+			return errors.New("failed to trim password history: " + err.Error())
+		}
+	}
+
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"password":            hashed,
+		"password_changed_at": now,
+	}).Error; err != nil {
+		return errors.New("failed to update password: " + err.Error())
+	}
+	user.Password = hashed
+	user.PasswordChangedAt = &now
+	return nil
+}
+
+// ChangePassword replaces the signed-in user's password after checking the current one, and
+// signs out every other session
+func (s *PasswordService) ChangePassword(userID uuid.UUID, currentSessionID uuid.UUID, currentPassword string, newPassword string) (int64, error) {
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("user not found")
+		}
+		return 0, errors.New("failed to fetch user: " + err.Error())
+	}
+	// Accounts created through Google have no password until they set one
+	if user.Password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)) != nil {
+		return 0, errors.New("current password is incorrect")
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := s.SetPassword(tx, &user, newPassword); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	var except []uuid.UUID
+	if currentSessionID != uuid.Nil {
+		except = append(except, currentSessionID)
+	}
+	revoked, err := sessionServices.RevokeUserSessions(tx, user.ID, "password_change", except...)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Where("user_id = ?", user.ID.String()).Delete(&models.PasswordReset{}).Error; err != nil {
+		tx.Rollback()
+		return 0, errors.New("failed to clear reset links: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return 0, errors.New("failed to change password: " + err.Error())
+	}
+	return revoked, nil
+}
+
+// RequestReset emails a reset link to the account, replacing any link sent before. It succeeds
+// silently when the address is unknown so callers cannot probe for accounts.
+func (s *PasswordService) RequestReset(email string) error {
+	var user models.User
+	if err := s.db.Where("email = ?", strings.TrimSpace(email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return errors.New("failed to fetch user: " + err.Error())
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return errors.New("failed to generate reset link: " + err.Error())
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt := time.Now().Add(time.Duration(cfg.PasswordResetMinutes) * time.Minute)
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("user_id = ?", user.ID.String()).Delete(&models.PasswordReset{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to replace reset links: " + err.Error())
+	}
+	if err := tx.Create(&models.PasswordReset{
+		UserID:    user.ID.String(),
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to create reset link: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to create reset link: " + err.Error())
+	}
+
+	link := cfg.FrontendURL + "/reset-password?token=" + token
+	body := fmt.Sprintf("<p>Hello %s,</p><p>Click the link below to reset your password:</p>"+
+		"<a href='%s'>Reset Password</a>"+
+		"<p>The link works once and expires at %s. If you did not ask for it, ignore this email.</p>",
+		user.FirstName, link, expiresAt.Format(time.RFC1123))
+	if err := utils.SendEmail(user.Email, "Password Reset - Go CRM", body); err != nil {
+		return errors.New("failed to send reset email: " + err.Error())
+	}
+	return nil
+}
+
+// ResetPassword sets a new password from a reset link, uses up every outstanding link for the
+// account and signs out all of its sessions
+func (s *PasswordService) ResetPassword(token string, newPassword string) error {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the link so it cannot be used twice at once
+	var reset models.PasswordReset
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hashToken(token)).
+		First(&reset).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("invalid or expired reset link")
+		}
+		return errors.New("failed to fetch reset link: " + err.Error())
+	}
+	if time.Now().After(reset.ExpiresAt) {
+		tx.Rollback()
+		return errors.New("invalid or expired reset link")
+	}
+
+	var user models.User
+	if err := tx.Where("id = ?", reset.UserID).First(&user).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("invalid or expired reset link")
+		}
+		return errors.New("failed to fetch user: " + err.Error())
+	}
+
+	if err := s.SetPassword(tx, &user, newPassword); err != nil {
+		tx.Rollback()
+		return err
+	}
+	// Whoever knew the old password may still be signed in
+	if _, err := sessionServices.RevokeUserSessions(tx, user.ID, "password_change"); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("user_id = ?", reset.UserID).Delete(&models.PasswordReset{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to clear reset links: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to reset password: " + err.Error())
+	}
+	return nil
+}
+
+// recentlyUsed compares a password with the current one and the last entries of the history
+func (s *PasswordService) recentlyUsed(db *gorm.DB, user *models.User, password string) (bool, error) {
+	hashes := []string{}
+	if user.Password != "" {
+		hashes = append(hashes, user.Password)
+	}
+
+	if cfg.PasswordHistoryCount > 1 {
+		var history []models.PasswordHistory
+		if err := db.Where("user_id = ?", user.ID).
+			Order("created_at DESC").
+			Limit(cfg.PasswordHistoryCount - 1).
+			Find(&history).Error; err != nil {
+			return false, errors.New("failed to check password history: " + err.Error())
+		}
+		for _, entry := range history {
+			hashes = append(hashes, entry.PasswordHash)
+		}
+	}
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.New("failed to hash password: " + err.Error())
+	}
+	return string(hashed), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
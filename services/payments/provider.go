@@ -0,0 +1,315 @@
+// services/payments/provider.go
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"crm-go/config"
+)
+
+var cfg = config.LoadEnv()
+
+// RefundRequest describes money to return against a captured payment
+type RefundRequest struct {
+	PaymentID        string // internal payment reference
+	GatewayReference string // gateway transaction ID the refund applies to
+	Amount           float64
+	Currency         string
+	Reason           string
+}
+
+// RefundResult is the gateway's acknowledgement of a refund
+type RefundResult struct {
+	Reference string // gateway refund ID
+	Status    string // gateway-reported status, e.g. "succeeded" or "pending"
+}
+
+// ChargeRequest describes an off-session charge against a saved customer, used for renewals
+type ChargeRequest struct {
+	Reference     string // internal payment reference, used for idempotency
+	CustomerID    string // gateway customer ID or reusable authorization
+	PaymentMethod string // gateway payment method, where the gateway needs one
+	Email         string
+	Amount        float64
+	Currency      string
+	Description   string
+}
+
+// ChargeResult is the gateway's acknowledgement of a charge
+type ChargeResult struct {
+	Reference string // gateway transaction ID
+	Status    string
+}
+
+// Provider is implemented by each payment gateway the platform settles through
+type Provider interface {
+	Name() string
+	Charge(ctx context.Context, req ChargeRequest) (*ChargeResult, error)
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+}
+
+// ErrManualCharge is returned when a gateway cannot take money without the payer
+var ErrManualCharge = errors.New("payment must be collected manually")
+
+// ProviderFor returns the provider for a Payment.Gateway value
+func ProviderFor(gateway string) (Provider, error) {
+	switch gateway {
+	case "manual", "bank":
+		return manualProvider{name: gateway}, nil
+	case "stripe":
+		if cfg.StripeAPIKey == "" {
+			return nil, errors.New("stripe refunds are not configured")
+		}
+		return stripeProvider{apiKey: cfg.StripeAPIKey, client: &http.Client{Timeout: 30 * time.Second}}, nil
+	case "paystack":
+		if cfg.PaystackSecretKey == "" {
+			return nil, errors.New("paystack refunds are not configured")
+		}
+		return paystackProvider{secretKey: cfg.PaystackSecretKey, client: &http.Client{Timeout: 30 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("gateway %q does not support automated refunds", gateway)
+	}
+}
+
+// manualProvider covers cash, cheque and bank payments, which finance refunds outside the platform
+type manualProvider struct {
+	name string
+}
+
+func (p manualProvider) Name() string {
+	return p.name
+}
+
+func (p manualProvider) Charge(ctx context.Context, req ChargeRequest) (*ChargeResult, error) {
+	return nil, ErrManualCharge
+}
+
+func (p manualProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	return &RefundResult{
+		Reference: "MANUAL-" + strings.ToUpper(uuid.New().String()[:8]),
+		Status:    "pending_manual",
+	}, nil
+}
+
+type stripeProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+func (p stripeProvider) Name() string {
+	return "stripe"
+}
+
+func (p stripeProvider) Charge(ctx context.Context, req ChargeRequest) (*ChargeResult, error) {
+	if req.CustomerID == "" || req.PaymentMethod == "" {
+		return nil, errors.New("no saved stripe payment method")
+	}
+
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(minorUnits(req.Amount), 10))
+	form.Set("currency", strings.ToLower(req.Currency))
+	form.Set("customer", req.CustomerID)
+	form.Set("payment_method", req.PaymentMethod)
+	form.Set("off_session", "true")
+	form.Set("confirm", "true")
+	form.Set("description", req.Description)
+	form.Set("metadata[payment_id]", req.Reference)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.stripe.com/v1/payment_intents", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Idempotency-Key", "charge-"+req.Reference)
+
+	var body struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Error  struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	status, err := doJSON(p.client, httpReq, &body)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 || body.Status != "succeeded" {
+		message := body.Error.Message
+		if message == "" {
+			message = "payment " + body.Status
+		}
+		return nil, fmt.Errorf("stripe charge failed: %s", message)
+	}
+	return &ChargeResult{Reference: body.ID, Status: body.Status}, nil
+}
+
+func (p stripeProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	if req.GatewayReference == "" {
+		return nil, errors.New("payment has no stripe reference to refund")
+	}
+
+	form := url.Values{}
+	if strings.HasPrefix(req.GatewayReference, "pi_") {
+		form.Set("payment_intent", req.GatewayReference)
+	} else {
+		form.Set("charge", req.GatewayReference)
+	}
+	form.Set("amount", strconv.FormatInt(minorUnits(req.Amount), 10))
+	form.Set("metadata[payment_id]", req.PaymentID)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.stripe.com/v1/refunds", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Idempotency-Key", "refund-"+req.PaymentID+"-"+strconv.FormatInt(minorUnits(req.Amount), 10))
+
+	var body struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Error  struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	status, err := doJSON(p.client, httpReq, &body)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("stripe refund failed: %s", body.Error.Message)
+	}
+	return &RefundResult{Reference: body.ID, Status: body.Status}, nil
+}
+
+type paystackProvider struct {
+	secretKey string
+	client    *http.Client
+}
+
+func (p paystackProvider) Name() string {
+	return "paystack"
+}
+
+func (p paystackProvider) Charge(ctx context.Context, req ChargeRequest) (*ChargeResult, error) {
+	if req.CustomerID == "" || req.Email == "" {
+		return nil, errors.New("no saved paystack authorization")
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"authorization_code": req.CustomerID,
+		"email":              req.Email,
+		"amount":             minorUnits(req.Amount),
+		"currency":           req.Currency,
+		"reference":          req.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.paystack.co/transaction/charge_authorization", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.secretKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	var body struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			Reference       string `json:"reference"`
+			Status          string `json:"status"`
+			GatewayResponse string `json:"gateway_response"`
+		} `json:"data"`
+	}
+	status, err := doJSON(p.client, httpReq, &body)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 || !body.Status || body.Data.Status != "success" {
+		message := body.Data.GatewayResponse
+		if message == "" {
+			message = body.Message
+		}
+		return nil, fmt.Errorf("paystack charge failed: %s", message)
+	}
+	return &ChargeResult{Reference: body.Data.Reference, Status: body.Data.Status}, nil
+}
+
+func (p paystackProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	if req.GatewayReference == "" {
+		return nil, errors.New("payment has no paystack reference to refund")
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"transaction":   req.GatewayReference,
+		"amount":        minorUnits(req.Amount),
+		"currency":      req.Currency,
+		"merchant_note": req.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.paystack.co/refund", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.secretKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	var body struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			ID     int64  `json:"id"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	status, err := doJSON(p.client, httpReq, &body)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 || !body.Status {
+		return nil, fmt.Errorf("paystack refund failed: %s", body.Message)
+	}
+	return &RefundResult{Reference: strconv.FormatInt(body.Data.ID, 10), Status: body.Data.Status}, nil
+}
+
+// doJSON sends a request and decodes the JSON response body, returning the HTTP status
+func doJSON(client *http.Client, req *http.Request, out interface{}) (int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("gateway request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("gateway response unreadable: %w", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return resp.StatusCode, fmt.Errorf("gateway response invalid: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// minorUnits converts an amount to the smallest currency unit (cents, kobo)
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
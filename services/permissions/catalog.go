@@ -0,0 +1,208 @@
+// services/catalog.go
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+)
+
+// catalogEntry is one permission routes can require. ownScope marks permissions that can be
+// granted for the user's own resources only.
+type catalogEntry struct {
+	key         string
+	description string
+	ownScope    bool
+}
+
+// catalog lists every permission, grouped by resource
+var catalog = []catalogEntry{
+	{"addresses:read", "View users' addresses", true},
+	{"admin:access", "Open the admin dashboard and export data", false},
+	{"announcements:write", "Create, update and delete announcements", false},
+	{"assignments:write", "Record assignment submissions on a student's behalf", false},
+	{"attendance:write", "Take class registers and read attendance", false},
+	{"attendance:manage", "Take any arm's register and correct registers after they lock", false},
+	{"billing:manage", "Manage fee schedules, invoices, payments and ledgers", false},
+	{"billing:account", "View the billing statements and receipts of students", true},
+	{"categories:write", "Create, update and delete categories", false},
+	{"classes:read", "View class levels, arms, class lists and students' placement history", true},
+	{"classes:write", "Manage class levels and arms and place, transfer or withdraw students", false},
+	{"content:write", "Manage modules, lessons, topics, course materials and release rules", false},
+	{"content:reorder", "Reorder and move modules, lessons and topics", false},
+	{"courses:preview", "Open every course and module, including drafts, without enrolling", false},
+	{"courses:write", "Create, update and delete courses and their categories and products", true},
+	{"course_versions:write", "Clone courses and draft, submit or discard course versions", false},
+	{"course_versions:publish", "Publish course versions", false},
+	{"departments:read", "View departments", false},
+	{"departments:write", "Create, update and delete departments", true},
+	{"grades:read", "View student grades and grade statistics", true},
+	{"grades:write", "Record and update student grades", true},
+	{"guardians:read", "View guardians", false},
+	{"guardians:write", "Create, update and delete guardians", false},
+	{"invitations:manage", "Invite users and manage invitations", false},
+	{"modules:complete", "Mark modules complete as a student", false},
+	{"orders:manage", "View orders, record payments and change order status", false},
+	{"products:write", "Create, update and delete products", false},
+	{"promotions:manage", "Run end-of-session promotions", false},
+	{"publishing:submit", "Submit, review and comment on courses and modules in the publishing workflow", false},
+	{"publishing:manage", "Assign reviewers and archive courses and modules", false},
+	{"refunds:request", "Request refunds", false},
+	{"refunds:manage", "Review and process refunds", false},
+	{"roles:manage", "Edit roles and assign them to users", false},
+	{"security:manage", "Manage sessions, sign-in security, signing keys and single sign-on providers", false},
+	{"service_accounts:manage", "Manage service accounts and issue or revoke their API keys", false},
+	{"subjects:read", "View subjects and the subjects each class level takes", false},
+	{"subjects:write", "Manage subjects and the subjects each class level takes", true},
+	{"subscriptions:manage", "Manage subscription plans, subscriptions and renewals", false},
+	{"subscriptions:subscribe", "Subscribe to a plan", false},
+	{"timetable:write", "Manage teacher allocations, availability and timetables", false},
+	{"users:read", "View users", false},
+	{"users:write", "Activate, deactivate and delete users", false},
+}
+
+// builtinRole is a role created at startup. Admins may change the permissions of every
+// built-in role except admin, which always holds everything so it cannot be locked out.
+type builtinRole struct {
+	name        string
+	displayName string
+	description string
+	permissions []dto.PermissionGrant
+}
+
+func grants(scope string, keys ...string) []dto.PermissionGrant {
+	list := make([]dto.PermissionGrant, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, dto.PermissionGrant{Permission: key, Scope: scope})
+	}
+	return list
+}
+
+// adminRole is the role that always holds every permission
+const adminRole = "admin"
+
+var builtinRoles = []builtinRole{
+	{adminRole, "Administrator", "Full access to everything", grants("all", "*")},
+	{"staff", "Staff", "School office staff",
+		grants("all", "addresses:read", "classes:read", "departments:read", "subjects:read", "guardians:read", "guardians:write",
+			"users:read")},
+	{"tutor", "Tutor", "Teaches courses and classes; heads of department also manage their department's subjects",
+		append(grants("all", "attendance:write", "classes:read", "content:reorder", "courses:preview", "course_versions:write",
+			"departments:read", "publishing:submit", "subjects:read"),
+			grants("own", "addresses:read", "courses:write", "grades:read", "grades:write", "subjects:write")...)},
+	{"student", "Student", "Learners",
+		append(grants("all", "departments:read", "modules:complete", "refunds:request",
+			"subjects:read", "subscriptions:subscribe"),
+			grants("own", "addresses:read", "billing:account", "classes:read", "grades:read")...)},
+	{"guardian", "Guardian", "Parents and guardians of students; read-only",
+		append(grants("all", "departments:read", "subjects:read"), grants("own", "billing:account")...)},
+	{"parent", "Parent", "Guardian accounts created before the guardian role existed",
+		append(grants("all", "departments:read", "subjects:read"), grants("own", "billing:account")...)},
+	{"user", "User", "Accounts without a school role",
+		append(grants("all", "departments:read", "subjects:read"), grants("own", "addresses:read")...)},
+}
+
+// SeedBuiltinRoles creates any built-in role that does not exist yet, with its default
+// permissions. Existing roles are left alone so admins' changes survive restarts.
+func SeedBuiltinRoles(db *gorm.DB) {
+	for _, builtin := range builtinRoles {
+		var count int64
+		if err := db.Model(&models.Role{}).Where("name = ?", builtin.name).Count(&count).Error; err != nil {
+			log.Printf("⚠️ Could not check role %s: %v", builtin.name, err)
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+
+		role := models.Role{
+			Name:        builtin.name,
+			DisplayName: builtin.displayName,
+			Description: builtin.description,
+			IsSystem:    true,
+		}
+		for _, grant := range builtin.permissions {
+			role.Permissions = append(role.Permissions, models.RolePermission{Permission: grant.Permission, Scope: grant.Scope})
+		}
+		if err := db.Create(&role).Error; err != nil {
+			log.Printf("⚠️ Could not create role %s: %v", builtin.name, err)
+		}
+	}
+}
+
+// Catalog lists every permission routes can require
+func Catalog() []dto.PermissionInfo {
+	list := make([]dto.PermissionInfo, 0, len(catalog))
+	for _, entry := range catalog {
+		scopes := []string{"all"}
+		if entry.ownScope {
+			scopes = append(scopes, "own")
+		}
+		list = append(list, dto.PermissionInfo{Key: entry.key, Description: entry.description, Scopes: scopes})
+	}
+	return list
+}
+
+// validateGrant checks a permission against the catalog. Wildcards cover a whole resource
+// ("grades:*") or everything ("*") and can only be granted for all resources.
+func validateGrant(grant *dto.PermissionGrant) error {
+	if grant.Scope == "" {
+		grant.Scope = "all"
+	}
+	key := strings.TrimSpace(grant.Permission)
+	grant.Permission = key
+
+	if key == "*" || strings.HasSuffix(key, ":*") {
+		if grant.Scope != "all" {
+			return errors.New("wildcard permissions cannot be limited to own resources")
+		}
+		if key == "*" {
+			return nil
+		}
+		prefix := strings.TrimSuffix(key, "*")
+		for _, entry := range catalog {
+			if strings.HasPrefix(entry.key, prefix) {
+				return nil
+			}
+		}
+		return errors.New("permission '" + key + "' not found")
+	}
+
+	for _, entry := range catalog {
+		if entry.key == key {
+			if grant.Scope == "own" && !entry.ownScope {
+				return errors.New("permission '" + key + "' cannot be limited to own resources")
+			}
+			return nil
+		}
+	}
+	return errors.New("permission '" + key + "' not found")
+}
+
+// matches reports whether a granted permission covers the one required
+func matches(granted string, required string) bool {
+	if granted == "*" || granted == required {
+		return true
+	}
+	return strings.HasSuffix(granted, ":*") && strings.HasPrefix(required, strings.TrimSuffix(granted, "*"))
+}
+
+// ValidatePermission checks a permission, or a wildcard, against the catalog
+func ValidatePermission(key string) error {
+	return validateGrant(&dto.PermissionGrant{Permission: key})
+}
+
+// AllowsAny reports whether any of the granted permissions covers the one required
+func AllowsAny(granted []string, required string) bool {
+	for _, g := range granted {
+		if matches(g, required) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,721 @@
+// services/permission_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+	sessionServices "crm-go/services/sessions"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// PermissionService decides what users may do, and lets admins edit roles and assign them
+type PermissionService struct {
+	db *gorm.DB
+}
+
+func NewPermissionService(db *gorm.DB) *PermissionService {
+	return &PermissionService{db: db}
+}
+
+// grant is one permission a user holds through their primary role or an assignment
+type grant struct {
+	Role       string
+	Permission string
+	Scope      string
+	ScopeType  string
+	ScopeID    *uuid.UUID
+}
+
+// Authorize reports whether the user holds the permission for every resource given. With no
+// resources only grants that cover everything count; "own" and assignment-scoped grants apply
+// when the route names a resource they can be checked against.
+func (s *PermissionService) Authorize(userID uuid.UUID, permission string, resources ...*dto.PermissionResource) (bool, error) {
+	held, err := s.grants(userID)
+	if err != nil {
+		return false, err
+	}
+	if !holds(held, permission) {
+		return false, nil
+	}
+
+	facts := make([]*resourceFacts, 0, len(resources))
+	for _, resource := range resources {
+		resourceFacts, err := s.resourceFacts(resource)
+		if err != nil {
+			return false, err
+		}
+		facts = append(facts, resourceFacts)
+	}
+	return authorize(held, userID, permission, facts), nil
+}
+
+// holds reports whether any grant names the permission, whatever its scope
+func holds(held []grant, permission string) bool {
+	for _, g := range held {
+		if matches(g.Permission, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize decides a permission check once the user's grants and the facts about each named
+// resource are loaded
+func authorize(held []grant, userID uuid.UUID, permission string, facts []*resourceFacts) bool {
+	matching := make([]grant, 0, len(held))
+	for _, g := range held {
+		if matches(g.Permission, permission) {
+			matching = append(matching, g)
+		}
+	}
+
+	if len(facts) == 0 {
+		for _, g := range matching {
+			if g.Scope == "all" && g.ScopeType == "" {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, resourceFacts := range facts {
+		allowed := false
+		for _, g := range matching {
+			if covers(g, userID, resourceFacts) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// EffectivePermissions lists every permission a user holds and where it comes from
+func (s *PermissionService) EffectivePermissions(userID uuid.UUID) ([]dto.EffectivePermission, error) {
+	held, err := s.grants(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]dto.EffectivePermission, 0, len(held))
+	for _, g := range held {
+		permission := dto.EffectivePermission{
+			Permission: g.Permission,
+			Scope:      g.Scope,
+			Role:       g.Role,
+			ScopeType:  g.ScopeType,
+		}
+		if g.ScopeID != nil {
+			scopeID := g.ScopeID.String()
+			permission.ScopeID = &scopeID
+		}
+		permissions = append(permissions, permission)
+	}
+	sort.SliceStable(permissions, func(i, j int) bool { return permissions[i].Permission < permissions[j].Permission })
+	return permissions, nil
+}
+
+// grants loads the permissions of the user's primary role and of their assignments
+func (s *PermissionService) grants(userID uuid.UUID) ([]grant, error) {
+	var user models.User
+	if err := s.db.Select("id, role").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+
+	var held []grant
+	if err := s.db.Table("role_permissions").
+		Select("roles.name AS role, role_permissions.permission, role_permissions.scope").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ?", user.Role).
+		Scan(&held).Error; err != nil {
+		return nil, errors.New("failed to fetch role permissions: " + err.Error())
+	}
+
+	var assigned []grant
+	if err := s.db.Table("role_assignments").
+		Select("roles.name AS role, role_permissions.permission, role_permissions.scope, role_assignments.scope_type, role_assignments.scope_id").
+		Joins("JOIN roles ON roles.id = role_assignments.role_id").
+		Joins("JOIN role_permissions ON role_permissions.role_id = role_assignments.role_id").
+		Where("role_assignments.user_id = ?", userID).
+		Scan(&assigned).Error; err != nil {
+		return nil, errors.New("failed to fetch role assignments: " + err.Error())
+	}
+	return append(held, assigned...), nil
+}
+
+// covers reports whether a grant applies to a resource
+func covers(g grant, userID uuid.UUID, facts *resourceFacts) bool {
+	if g.ScopeType != "" {
+		if g.ScopeID == nil {
+			return false
+		}
+		switch g.ScopeType {
+		case "course":
+			if facts.courseID != *g.ScopeID {
+				return false
+			}
+		case "department":
+			if facts.departmentID != *g.ScopeID {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	if g.Scope == "own" {
+		for _, ownerID := range facts.ownerIDs {
+			if ownerID != uuid.Nil && ownerID == userID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// resourceFacts is what scoped grants are checked against: the course and department a
+// resource belongs to, and the users responsible for it
+type resourceFacts struct {
+	courseID     uuid.UUID
+	departmentID uuid.UUID
+	ownerIDs     []uuid.UUID
+}
+
+// resourceFacts looks a resource up. Resources that do not exist yield no facts, so only
+// grants that cover everything apply and the handler reports the missing record.
+func (s *PermissionService) resourceFacts(resource *dto.PermissionResource) (*resourceFacts, error) {
+	facts := &resourceFacts{}
+	id, err := uuid.Parse(resource.ID)
+	if err != nil {
+		return facts, nil
+	}
+
+	switch resource.Type {
+	case "course":
+		var course models.Course
+		if err := s.db.Select("id, tutor_id").Where("id = ?", id).First(&course).Error; err != nil {
+			return facts, ignoreMissing(err, "course")
+		}
+		facts.courseID, facts.ownerIDs = course.ID, []uuid.UUID{course.TutorID}
+
+	case "grade":
+		var grade models.Grade
+		if err := s.db.Select("id, course_id").Where("id = ?", id).First(&grade).Error; err != nil {
+			return facts, ignoreMissing(err, "grade")
+		}
+		return s.resourceFacts(&dto.PermissionResource{Type: "course", ID: grade.CourseID.String()})
+
+	case "course_version":
+		var version models.CourseVersion
+		if err := s.db.Select("id, course_id").Where("id = ?", id).First(&version).Error; err != nil {
+			return facts, ignoreMissing(err, "course version")
+		}
+		return s.resourceFacts(&dto.PermissionResource{Type: "course", ID: version.CourseID.String()})
+
+	case "subject":
+		var subject models.Subject
+		if err := s.db.Select("id, department_id").Where("id = ?", id).First(&subject).Error; err != nil {
+			return facts, ignoreMissing(err, "subject")
+		}
+		return s.resourceFacts(&dto.PermissionResource{Type: "department", ID: subject.DepartmentID.String()})
+
+	case "department":
+		var department models.Department
+		if err := s.db.Select("id, head_of_dept").Where("id = ?", id).First(&department).Error; err != nil {
+			return facts, ignoreMissing(err, "department")
+		}
+		facts.departmentID = department.ID
+		if department.HeadOfDept != nil {
+			facts.ownerIDs = []uuid.UUID{*department.HeadOfDept}
+		}
+
+	case "student":
+		// A student's own records belong to them and to their linked guardians
+		var guardianIDs []uuid.UUID
+		if err := s.db.Model(&models.Guardian{}).
+			Where("student_id = ? AND status = ?", id, "active").
+			Pluck("user_id", &guardianIDs).Error; err != nil {
+			return nil, errors.New("failed to fetch guardians: " + err.Error())
+		}
+		facts.ownerIDs = append([]uuid.UUID{id}, guardianIDs...)
+
+	case "user":
+		facts.ownerIDs = []uuid.UUID{id}
+
+	case "address":
+		var address models.Address
+		if err := s.db.Select("id, user_id").Where("id = ?", id).First(&address).Error; err != nil {
+			return facts, ignoreMissing(err, "address")
+		}
+		facts.ownerIDs = []uuid.UUID{address.UserID}
+
+	default:
+		return nil, fmt.Errorf("failed to check permissions: unknown resource type %q", resource.Type)
+	}
+	return facts, nil
+}
+
+func ignoreMissing(err error, what string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return errors.New("failed to fetch " + what + ": " + err.Error())
+}
+
+// GetRoles lists every role with its permissions (Admin)
+func (s *PermissionService) GetRoles() ([]dto.RoleResponse, error) {
+	var roles []models.Role
+	if err := s.db.Preload("Permissions").Order("is_system DESC, name ASC").Find(&roles).Error; err != nil {
+		return nil, errors.New("failed to fetch roles: " + err.Error())
+	}
+
+	responses := make([]dto.RoleResponse, 0, len(roles))
+	for i := range roles {
+		response, err := s.toRoleResponse(&roles[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// GetRole fetches one role (Admin)
+func (s *PermissionService) GetRole(id string) (*dto.RoleResponse, error) {
+	role, err := s.findRole(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toRoleResponse(role)
+}
+
+// CreateRole adds a role with its permission set (Admin)
+func (s *PermissionService) CreateRole(req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.New("role name may only contain lower-case letters, digits and underscores, starting with a letter")
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.Role{}).Where("name = ?", name).Count(&existing).Error; err != nil {
+		return nil, errors.New("failed to check role name: " + err.Error())
+	}
+	if existing > 0 {
+		return nil, errors.New("a role with this name already exists")
+	}
+
+	permissions, err := buildPermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	role := &models.Role{
+		Name:        name,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Description: strings.TrimSpace(req.Description),
+		Permissions: permissions,
+	}
+	if err := s.db.Create(role).Error; err != nil {
+		return nil, errors.New("failed to create role: " + err.Error())
+	}
+	return s.toRoleResponse(role)
+}
+
+// UpdateRole changes a role's details and, when given, replaces its permissions. The admin
+// role's permissions cannot change (Admin).
+func (s *PermissionService) UpdateRole(id string, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	role, err := s.findRole(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.DisplayName != nil {
+		updates["display_name"] = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Description != nil {
+		updates["description"] = strings.TrimSpace(*req.Description)
+	}
+	if len(updates) > 0 {
+		if err := tx.Model(role).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update role: " + err.Error())
+		}
+	}
+
+	if req.Permissions != nil {
+		if role.Name == adminRole {
+			tx.Rollback()
+			return nil, errors.New("the admin role always holds every permission")
+		}
+		permissions, err := buildPermissions(req.Permissions)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to replace role permissions: " + err.Error())
+		}
+		for i := range permissions {
+			permissions[i].RoleID = role.ID
+		}
+		if len(permissions) > 0 {
+			if err := tx.Create(&permissions).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.New("failed to replace role permissions: " + err.Error())
+			}
+		}
+		// Touch the role so its UpdatedAt reflects the new permission set
+		if err := tx.Model(role).Update("updated_at", gorm.Expr("NOW()")).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update role: " + err.Error())
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to update role: " + err.Error())
+	}
+	return s.GetRole(id)
+}
+
+// DeleteRole removes a custom role and its assignments. Built-in roles and roles that are
+// still someone's primary role are kept (Admin).
+func (s *PermissionService) DeleteRole(id string) error {
+	role, err := s.findRole(s.db, id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return errors.New("built-in roles cannot be deleted")
+	}
+
+	var holders int64
+	if err := s.db.Model(&models.User{}).Where("role = ?", role.Name).Count(&holders).Error; err != nil {
+		return errors.New("failed to check role holders: " + err.Error())
+	}
+	if holders > 0 {
+		return fmt.Errorf("role is the primary role of %d users; give them another role first", holders)
+	}
+
+	if err := s.db.Delete(role).Error; err != nil {
+		return errors.New("failed to delete role: " + err.Error())
+	}
+	return nil
+}
+
+// GetUserRoles shows a user's primary role, additional assignments and resulting permissions (Admin)
+func (s *PermissionService) GetUserRoles(userID string) (*dto.UserRolesResponse, error) {
+	user, err := s.findUser(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []models.RoleAssignment
+	if err := s.db.Preload("Role").Where("user_id = ?", user.ID).Order("created_at ASC").Find(&assignments).Error; err != nil {
+		return nil, errors.New("failed to fetch role assignments: " + err.Error())
+	}
+	permissions, err := s.EffectivePermissions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.UserRolesResponse{
+		UserID:      user.ID.String(),
+		PrimaryRole: user.Role,
+		Assignments: make([]dto.RoleAssignmentResponse, 0, len(assignments)),
+		Permissions: permissions,
+	}
+	for i := range assignments {
+		response.Assignments = append(response.Assignments, *s.toAssignmentResponse(&assignments[i]))
+	}
+	return response, nil
+}
+
+// SetPrimaryRole changes a user's primary role. The user's sessions are revoked because
+// tokens carry the role they were issued with (Admin).
+func (s *PermissionService) SetPrimaryRole(userID string, req *dto.SetPrimaryRoleRequest) (*dto.UserRolesResponse, error) {
+	roleName := strings.TrimSpace(req.Role)
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	user, err := s.findUser(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var role models.Role
+	if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("role not found")
+		}
+		return nil, errors.New("failed to fetch role: " + err.Error())
+	}
+	if user.Role == role.Name {
+		tx.Rollback()
+		return nil, errors.New("user already has this role")
+	}
+	if user.Provider == "service" {
+		tx.Rollback()
+		return nil, errors.New("service account roles are changed through the service account")
+	}
+
+	// Someone must always be able to manage roles; service accounts cannot sign in to do so
+	if user.Role == adminRole && user.Provider != "service" {
+		var admins int64
+		if err := tx.Model(&models.User{}).Where("role = ? AND provider <> ?", adminRole, "service").Count(&admins).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to count admins: " + err.Error())
+		}
+		if admins <= 1 {
+			tx.Rollback()
+			return nil, errors.New("cannot change the role of the last admin")
+		}
+	}
+
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("role", role.Name).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update role: " + err.Error())
+	}
+	if _, err := sessionServices.RevokeUserSessions(tx, user.ID, "role_change"); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to update role: " + err.Error())
+	}
+	return s.GetUserRoles(userID)
+}
+
+// AssignRole gives a user an additional role, everywhere or within one department or course (Admin)
+func (s *PermissionService) AssignRole(userID string, req *dto.AssignRoleRequest, assignedBy uuid.UUID) (*dto.RoleAssignmentResponse, error) {
+	user, err := s.findUser(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	// API keys are limited by their service account's role, which extra roles would get around
+	if user.Provider == "service" {
+		return nil, errors.New("service accounts cannot be assigned additional roles")
+	}
+	var role models.Role
+	if err := s.db.Where("name = ?", strings.TrimSpace(req.Role)).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("role not found")
+		}
+		return nil, errors.New("failed to fetch role: " + err.Error())
+	}
+
+	assignment := &models.RoleAssignment{
+		UserID:     user.ID,
+		RoleID:     role.ID,
+		ScopeType:  req.ScopeType,
+		AssignedBy: assignedBy,
+	}
+	if (req.ScopeType == "") != (req.ScopeID == "") {
+		return nil, errors.New("scope_type and scope_id must be given together")
+	}
+	if req.ScopeType != "" {
+		scopeID, err := uuid.Parse(req.ScopeID)
+		if err != nil {
+			return nil, errors.New("invalid scope ID")
+		}
+		if _, err := s.scopeName(req.ScopeType, scopeID); err != nil {
+			return nil, err
+		}
+		assignment.ScopeID = &scopeID
+	}
+
+	query := s.db.Model(&models.RoleAssignment{}).
+		Where("user_id = ? AND role_id = ? AND scope_type = ?", user.ID, role.ID, assignment.ScopeType)
+	if assignment.ScopeID != nil {
+		query = query.Where("scope_id = ?", *assignment.ScopeID)
+	} else {
+		query = query.Where("scope_id IS NULL")
+	}
+	var existing int64
+	if err := query.Count(&existing).Error; err != nil {
+		return nil, errors.New("failed to check role assignments: " + err.Error())
+	}
+	if existing > 0 {
+		return nil, errors.New("user already has this role for that scope")
+	}
+
+	if err := s.db.Create(assignment).Error; err != nil {
+		return nil, errors.New("failed to assign role: " + err.Error())
+	}
+	assignment.Role = role
+	return s.toAssignmentResponse(assignment), nil
+}
+
+// RemoveAssignment takes an additional role away from a user (Admin)
+func (s *PermissionService) RemoveAssignment(userID string, assignmentID string) error {
+	user, err := s.findUser(s.db, userID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(assignmentID)
+	if err != nil {
+		return errors.New("invalid assignment ID")
+	}
+
+	result := s.db.Where("id = ? AND user_id = ?", id, user.ID).Delete(&models.RoleAssignment{})
+	if result.Error != nil {
+		return errors.New("failed to remove role assignment: " + result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("role assignment not found")
+	}
+	return nil
+}
+
+func (s *PermissionService) findRole(db *gorm.DB, id string) (*models.Role, error) {
+	roleID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid role ID")
+	}
+
+	var role models.Role
+	if err := db.Preload("Permissions").Where("id = ?", roleID).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("role not found")
+		}
+		return nil, errors.New("failed to fetch role: " + err.Error())
+	}
+	return &role, nil
+}
+
+func (s *PermissionService) findUser(db *gorm.DB, id string) (*models.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	var user models.User
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+	return &user, nil
+}
+
+// scopeName checks an assignment scope exists and returns its name for display
+func (s *PermissionService) scopeName(scopeType string, scopeID uuid.UUID) (string, error) {
+	switch scopeType {
+	case "department":
+		var department models.Department
+		if err := s.db.Select("id, name").Where("id = ?", scopeID).First(&department).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", errors.New("department not found")
+			}
+			return "", errors.New("failed to fetch department: " + err.Error())
+		}
+		return department.Name, nil
+	case "course":
+		var course models.Course
+		if err := s.db.Select("id, title").Where("id = ?", scopeID).First(&course).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", errors.New("course not found")
+			}
+			return "", errors.New("failed to fetch course: " + err.Error())
+		}
+		return course.Title, nil
+	}
+	return "", errors.New("scope_type must be 'department' or 'course'")
+}
+
+func buildPermissions(grants []dto.PermissionGrant) ([]models.RolePermission, error) {
+	seen := map[string]bool{}
+	permissions := make([]models.RolePermission, 0, len(grants))
+	for i := range grants {
+		if err := validateGrant(&grants[i]); err != nil {
+			return nil, err
+		}
+		if seen[grants[i].Permission] {
+			return nil, errors.New("permission '" + grants[i].Permission + "' is listed more than once")
+		}
+		seen[grants[i].Permission] = true
+		permissions = append(permissions, models.RolePermission{Permission: grants[i].Permission, Scope: grants[i].Scope})
+	}
+	return permissions, nil
+}
+
+func (s *PermissionService) toRoleResponse(role *models.Role) (*dto.RoleResponse, error) {
+	response := &dto.RoleResponse{
+		ID:          role.ID.String(),
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: make([]dto.PermissionGrant, 0, len(role.Permissions)),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+	for _, permission := range role.Permissions {
+		response.Permissions = append(response.Permissions, dto.PermissionGrant{Permission: permission.Permission, Scope: permission.Scope})
+	}
+	sort.Slice(response.Permissions, func(i, j int) bool {
+		return response.Permissions[i].Permission < response.Permissions[j].Permission
+	})
+
+	if err := s.db.Model(&models.User{}).Where("role = ?", role.Name).Count(&response.UserCount).Error; err != nil {
+		return nil, errors.New("failed to count role holders: " + err.Error())
+	}
+	if err := s.db.Model(&models.RoleAssignment{}).Where("role_id = ?", role.ID).Count(&response.Assignments).Error; err != nil {
+		return nil, errors.New("failed to count role assignments: " + err.Error())
+	}
+	return response, nil
+}
+
+func (s *PermissionService) toAssignmentResponse(assignment *models.RoleAssignment) *dto.RoleAssignmentResponse {
+	response := &dto.RoleAssignmentResponse{
+		ID:          assignment.ID.String(),
+		Role:        assignment.Role.Name,
+		DisplayName: assignment.Role.DisplayName,
+		ScopeType:   assignment.ScopeType,
+		AssignedBy:  assignment.AssignedBy.String(),
+		CreatedAt:   assignment.CreatedAt,
+	}
+	if assignment.ScopeID != nil {
+		scopeID := assignment.ScopeID.String()
+		response.ScopeID = &scopeID
+		// A scope removed since the assignment simply shows without a name
+		response.ScopeName, _ = s.scopeName(assignment.ScopeType, *assignment.ScopeID)
+	}
+	return response
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAuthorize(t *testing.T) {
+	user := uuid.New()
+	other := uuid.New()
+	course := uuid.New()
+	otherCourse := uuid.New()
+	department := uuid.New()
+
+	ownCourse := &resourceFacts{courseID: course, ownerIDs: []uuid.UUID{user}}
+	othersCourse := &resourceFacts{courseID: otherCourse, ownerIDs: []uuid.UUID{other}}
+	departmentSubject := &resourceFacts{departmentID: department, ownerIDs: []uuid.UUID{other}}
+	missing := &resourceFacts{}
+
+	tests := []struct {
+		name       string
+		held       []grant
+		permission string
+		facts      []*resourceFacts
+		want       bool
+	}{
+		{
+			name:       "no grants",
+			permission: "grades:write",
+			want:       false,
+		},
+		{
+			name:       "all scope without resources",
+			held:       []grant{{Permission: "grades:write", Scope: "all"}},
+			permission: "grades:write",
+			want:       true,
+		},
+		{
+			name:       "other permission",
+			held:       []grant{{Permission: "grades:read", Scope: "all"}},
+			permission: "grades:write",
+			want:       false,
+		},
+		{
+			name:       "wildcard",
+			held:       []grant{{Permission: "*", Scope: "all"}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{othersCourse},
+			want:       true,
+		},
+		{
+			name:       "resource wildcard",
+			held:       []grant{{Permission: "grades:*", Scope: "all"}},
+			permission: "grades:write",
+			want:       true,
+		},
+		{
+			name:       "resource wildcard does not cross resources",
+			held:       []grant{{Permission: "grades:*", Scope: "all"}},
+			permission: "gradebooks:write",
+			want:       false,
+		},
+		{
+			name:       "own scope without resources",
+			held:       []grant{{Permission: "grades:write", Scope: "own"}},
+			permission: "grades:write",
+			want:       false,
+		},
+		{
+			name:       "own scope on own resource",
+			held:       []grant{{Permission: "grades:write", Scope: "own"}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{ownCourse},
+			want:       true,
+		},
+		{
+			name:       "own scope on someone else's resource",
+			held:       []grant{{Permission: "grades:write", Scope: "own"}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{othersCourse},
+			want:       false,
+		},
+		{
+			name:       "own scope on a missing resource",
+			held:       []grant{{Permission: "grades:write", Scope: "own"}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{missing},
+			want:       false,
+		},
+		{
+			name:       "all scope on a missing resource",
+			held:       []grant{{Permission: "grades:write", Scope: "all"}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{missing},
+			want:       true,
+		},
+		{
+			name:       "own scope must cover every resource",
+			held:       []grant{{Permission: "grades:write", Scope: "own"}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{ownCourse, othersCourse},
+			want:       false,
+		},
+		{
+			name:       "course assignment on its course",
+			held:       []grant{{Permission: "grades:write", Scope: "all", ScopeType: "course", ScopeID: &otherCourse}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{othersCourse},
+			want:       true,
+		},
+		{
+			name:       "course assignment on another course",
+			held:       []grant{{Permission: "grades:write", Scope: "all", ScopeType: "course", ScopeID: &course}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{othersCourse},
+			want:       false,
+		},
+		{
+			name:       "course assignment without resources",
+			held:       []grant{{Permission: "grades:write", Scope: "all", ScopeType: "course", ScopeID: &course}},
+			permission: "grades:write",
+			want:       false,
+		},
+		{
+			name:       "department assignment on its department",
+			held:       []grant{{Permission: "subjects:write", Scope: "all", ScopeType: "department", ScopeID: &department}},
+			permission: "subjects:write",
+			facts:      []*resourceFacts{departmentSubject},
+			want:       true,
+		},
+		{
+			name:       "own department assignment needs ownership too",
+			held:       []grant{{Permission: "subjects:write", Scope: "own", ScopeType: "department", ScopeID: &department}},
+			permission: "subjects:write",
+			facts:      []*resourceFacts{departmentSubject},
+			want:       false,
+		},
+		{
+			name:       "assignment scope without an ID",
+			held:       []grant{{Permission: "grades:write", Scope: "all", ScopeType: "course"}},
+			permission: "grades:write",
+			facts:      []*resourceFacts{ownCourse},
+			want:       false,
+		},
+		{
+			name: "different grants cover different resources",
+			held: []grant{
+				{Permission: "grades:write", Scope: "own"},
+				{Permission: "grades:write", Scope: "all", ScopeType: "course", ScopeID: &otherCourse},
+			},
+			permission: "grades:write",
+			facts:      []*resourceFacts{ownCourse, othersCourse},
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authorize(tt.held, user, tt.permission, tt.facts); got != tt.want {
+				t.Errorf("authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowsAny(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required string
+		want     bool
+	}{
+		{"no scopes", nil, "grades:read", false},
+		{"exact", []string{"grades:read"}, "grades:read", true},
+		{"everything", []string{"*"}, "grades:read", true},
+		{"resource wildcard", []string{"grades:*"}, "grades:read", true},
+		{"other resource", []string{"courses:*"}, "grades:read", false},
+		{"prefix is not a wildcard", []string{"grades"}, "grades:read", false},
+		{"any of several", []string{"courses:write", "grades:read"}, "grades:read", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AllowsAny(tt.granted, tt.required); got != tt.want {
+				t.Errorf("AllowsAny(%v, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+			}
+		})
+	}
+}
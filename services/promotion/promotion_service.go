@@ -0,0 +1,837 @@
+// services/promotion_service.go
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+	membershipServices "crm-go/services/class_membership"
+)
+
+const defaultPassMark = 50.0
+
+// passMarkFor returns the requested pass mark, or the default when none was given
+func passMarkFor(req *dto.PromotionRequest) float64 {
+	if req.PassMark != nil {
+		return *req.PassMark
+	}
+	return defaultPassMark
+}
+
+type PromotionService struct {
+	db *gorm.DB
+}
+
+func NewPromotionService(db *gorm.DB) *PromotionService {
+	return &PromotionService{db: db}
+}
+
+// promotionPlan holds a computed decision together with the records needed to execute it
+type promotionPlan struct {
+	membership models.ClassMembership
+	toArm      *models.Arm
+	decision   dto.PromotionDecisionResponse
+}
+
+// RolloverSession clones class grades, arms and grade-subject allocations into a new session
+func (s *PromotionService) RolloverSession(req *dto.SessionRolloverRequest, userID uuid.UUID) (*dto.SessionRolloverResponse, error) {
+	fromSession, toSession, err := s.loadSessions(req.FromSessionID, req.ToSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	includeArms := req.IncludeArms == nil || *req.IncludeArms
+	includeSubjects := req.IncludeSubjects == nil || *req.IncludeSubjects
+
+	gradeQuery := s.db.Where("academic_session_id = ? AND deleted_at IS NULL", fromSession.ID)
+	if req.SkipInactiveRecords {
+		gradeQuery = gradeQuery.Where("status = ?", "active")
+	}
+
+	var grades []models.ClassGrade
+	if err := gradeQuery.Order("level ASC, name ASC").Find(&grades).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch class grades: %w", err)
+	}
+	if len(grades) == 0 {
+		return nil, errors.New("source session has no class grades to roll over")
+	}
+
+	response := &dto.SessionRolloverResponse{
+		FromSessionID: fromSession.ID.String(),
+		ToSessionID:   toSession.ID.String(),
+		GradeMapping:  make(map[string]string),
+		ArmMapping:    make(map[string]string),
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	for _, grade := range grades {
+		// Reuse a grade already rolled over with the same code
+		var target models.ClassGrade
+		err := tx.Where("academic_session_id = ? AND code = ? AND deleted_at IS NULL", toSession.ID, grade.Code).First(&target).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			return nil, errors.New("failed to check existing grade: " + err.Error())
+		}
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			target = models.ClassGrade{
+				ID:                uuid.New(),
+				Name:              grade.Name,
+				Code:              grade.Code,
+				Level:             grade.Level,
+				Description:       grade.Description,
+				AcademicSessionID: toSession.ID,
+				Capacity:          grade.Capacity,
+				Status:            grade.Status,
+				CreatedBy:         userID,
+				CreatedAt:         now,
+				UpdatedAt:         now,
+			}
+			if err := tx.Create(&target).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.New("failed to clone class grade: " + err.Error())
+			}
+			response.GradesCreated++
+		} else {
+			response.GradesSkipped++
+		}
+		response.GradeMapping[grade.ID.String()] = target.ID.String()
+
+		if includeArms {
+			created, err := s.cloneArms(tx, grade.ID, target.ID, req.SkipInactiveRecords, userID, response.ArmMapping)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			response.ArmsCreated += created
+		}
+
+		if includeSubjects {
+			created, err := s.cloneGradeSubjects(tx, grade.ID, target.ID, req.SkipInactiveRecords, userID)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			response.GradeSubjectsCreated += created
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to complete rollover: " + err.Error())
+	}
+
+	return response, nil
+}
+
+// PreviewPromotion computes promotion decisions without writing anything
+func (s *PromotionService) PreviewPromotion(req *dto.PromotionRequest) (*dto.PromotionResponse, error) {
+	fromSession, toSession, err := s.loadSessions(req.FromSessionID, req.ToSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := s.buildPlans(req, fromSession, toSession)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.toPromotionResponse(plans, req, fromSession.ID, toSession.ID)
+	response.DryRun = true
+	return response, nil
+}
+
+// ExecutePromotion applies promotion decisions in a single transaction and records an audit run.
+// Students whose target arm turns out to be full are skipped and keep their current seat.
+func (s *PromotionService) ExecutePromotion(req *dto.PromotionRequest, userID uuid.UUID) (*dto.PromotionResponse, error) {
+	fromSession, toSession, err := s.loadSessions(req.FromSessionID, req.ToSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := s.buildPlans(req, fromSession, toSession)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, errors.New("no active class memberships found in source session")
+	}
+
+	rules, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.New("failed to encode promotion rules: " + err.Error())
+	}
+
+	passMark := passMarkFor(req)
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	run := models.PromotionRun{
+		ID:            uuid.New(),
+		FromSessionID: fromSession.ID,
+		ToSessionID:   toSession.ID,
+		PassMark:      passMark,
+		Rules:         datatypes.JSON(rules),
+		TotalStudents: len(plans),
+		ExecutedBy:    userID,
+		CreatedAt:     now,
+	}
+	if err := tx.Create(&run).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create promotion run: " + err.Error())
+	}
+
+	seats := membershipServices.NewClassMembershipService(s.db)
+	for i := range plans {
+		plan := &plans[i]
+
+		// The plan was built outside the transaction; lock the source membership so a
+		// concurrent run or transfer cannot move the student at the same time
+		var current models.ClassMembership
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ? AND deleted_at IS NULL", plan.membership.ID, "active").
+			First(&current).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				tx.Rollback()
+				return nil, errors.New("failed to lock class membership: " + err.Error())
+			}
+			plan.unseat("class membership changed since the promotion was planned")
+		} else if current.ArmID != plan.membership.ArmID {
+			plan.unseat("class membership changed since the promotion was planned")
+		}
+
+		// Seat the student first, under the arm's lock, so a full arm or an existing placement
+		// in the new session leaves them where they are
+		if plan.toArm != nil {
+			if _, err := seats.PlaceStudentTx(tx, plan.membership.StudentID, plan.toArm.ID, "", userID); err != nil {
+				if strings.HasPrefix(err.Error(), "failed to") {
+					tx.Rollback()
+					return nil, err
+				}
+				plan.unseat("could not seat student: " + err.Error())
+			}
+		}
+
+		record := models.PromotionDecision{
+			ID:           uuid.New(),
+			RunID:        run.ID,
+			StudentID:    plan.membership.StudentID,
+			FromArmID:    plan.membership.ArmID,
+			Outcome:      plan.decision.Outcome,
+			AverageScore: plan.decision.AverageScore,
+			Reason:       plan.decision.Reason,
+			CreatedAt:    now,
+		}
+		if plan.toArm != nil {
+			record.ToArmID = &plan.toArm.ID
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to record promotion decision: " + err.Error())
+		}
+
+		if plan.decision.Outcome == "skipped" {
+			continue
+		}
+
+		// Close the old membership with the outcome; graduates have no new seat
+		if err := tx.Model(&models.ClassMembership{}).
+			Where("id = ?", plan.membership.ID).
+			Updates(map[string]interface{}{"status": plan.decision.Outcome, "updated_at": now}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update class membership: " + err.Error())
+		}
+	}
+
+	response := s.toPromotionResponse(plans, req, fromSession.ID, toSession.ID)
+	if err := tx.Model(&run).Updates(map[string]interface{}{
+		"promoted_count":  response.PromotedCount,
+		"repeated_count":  response.RepeatedCount,
+		"graduated_count": response.GraduatedCount,
+		"skipped_count":   response.SkippedCount,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update promotion run: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to execute promotion: " + err.Error())
+	}
+
+	response.RunID = run.ID.String()
+	response.ExecutedBy = userID.String()
+	response.CreatedAt = &run.CreatedAt
+	return response, nil
+}
+
+// GetPromotionRuns retrieves executed promotion runs with pagination
+func (s *PromotionService) GetPromotionRuns(params *dto.PromotionRunQueryParams) (*dto.PromotionRunListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	query := s.db.Model(&models.PromotionRun{})
+	if params.FromSessionID != "" {
+		if id, err := uuid.Parse(params.FromSessionID); err == nil {
+			query = query.Where("from_session_id = ?", id)
+		}
+	}
+	if params.ToSessionID != "" {
+		if id, err := uuid.Parse(params.ToSessionID); err == nil {
+			query = query.Where("to_session_id = ?", id)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count promotion runs: %w", err)
+	}
+
+	var runs []models.PromotionRun
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Order("created_at DESC").Offset(offset).Limit(params.Limit).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch promotion runs: %w", err)
+	}
+
+	responses := make([]dto.PromotionResponse, len(runs))
+	for i := range runs {
+		responses[i] = *s.toRunResponse(&runs[i])
+	}
+
+	return &dto.PromotionRunListResponse{
+		Runs:       responses,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: int((total + int64(params.Limit) - 1) / int64(params.Limit)),
+	}, nil
+}
+
+// GetPromotionRunByID retrieves a promotion run with all its decisions
+func (s *PromotionService) GetPromotionRunByID(id string) (*dto.PromotionResponse, error) {
+	runID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid promotion run ID")
+	}
+
+	var run models.PromotionRun
+	if err := s.db.Preload("Decisions").Where("id = ?", runID).First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion run not found")
+		}
+		return nil, errors.New("failed to fetch promotion run: " + err.Error())
+	}
+
+	return s.toRunResponse(&run), nil
+}
+
+// buildPlans evaluates the promotion rules for every active membership in the source session
+func (s *PromotionService) buildPlans(req *dto.PromotionRequest, fromSession, toSession *models.AcademicSession) ([]promotionPlan, error) {
+	passMark := passMarkFor(req)
+
+	overrides := make(map[uuid.UUID]dto.PromotionOverride)
+	for _, o := range req.Overrides {
+		studentID, err := uuid.Parse(o.StudentID)
+		if err != nil {
+			return nil, errors.New("invalid student ID in overrides: " + o.StudentID)
+		}
+		overrides[studentID] = o
+	}
+
+	// Load active memberships of the source session
+	query := s.db.
+		Joins("JOIN arms ON arms.id = class_memberships.arm_id AND arms.deleted_at IS NULL").
+		Where("class_memberships.academic_session_id = ? AND class_memberships.status = ? AND class_memberships.deleted_at IS NULL", fromSession.ID, "active")
+	if len(req.GradeIDs) > 0 {
+		gradeIDs := make([]uuid.UUID, 0, len(req.GradeIDs))
+		for _, id := range req.GradeIDs {
+			gID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, errors.New("invalid grade ID: " + id)
+			}
+			gradeIDs = append(gradeIDs, gID)
+		}
+		query = query.Where("arms.grade_id IN ?", gradeIDs)
+	}
+
+	var memberships []models.ClassMembership
+	if err := query.Preload("Student").Preload("Arm.Grade").Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch class memberships: %w", err)
+	}
+
+	// Work out the final level so graduates can be detected
+	finalLevel := req.FinalLevel
+	if finalLevel == 0 {
+		if err := s.db.Model(&models.ClassGrade{}).
+			Where("academic_session_id = ? AND deleted_at IS NULL", fromSession.ID).
+			Select("COALESCE(MAX(level), 0)").Scan(&finalLevel).Error; err != nil {
+			return nil, fmt.Errorf("failed to determine final level: %w", err)
+		}
+	}
+
+	// Target session structure
+	var targetGrades []models.ClassGrade
+	if err := s.db.Where("academic_session_id = ? AND deleted_at IS NULL", toSession.ID).
+		Order("name ASC").Find(&targetGrades).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch target grades: %w", err)
+	}
+	targetGradeIDs := make([]uuid.UUID, len(targetGrades))
+	for i, g := range targetGrades {
+		targetGradeIDs[i] = g.ID
+	}
+	var targetArms []models.Arm
+	if len(targetGradeIDs) > 0 {
+		if err := s.db.Where("grade_id IN ? AND deleted_at IS NULL", targetGradeIDs).
+			Order("name ASC").Find(&targetArms).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch target arms: %w", err)
+		}
+	}
+
+	// Students already seated in the target session are left alone; withdrawn records are
+	// reused when the student is seated again
+	var alreadyPlaced []uuid.UUID
+	if err := s.db.Model(&models.ClassMembership{}).
+		Where("academic_session_id = ? AND status <> ? AND deleted_at IS NULL", toSession.ID, "withdrawn").
+		Pluck("student_id", &alreadyPlaced).Error; err != nil {
+		return nil, fmt.Errorf("failed to check target memberships: %w", err)
+	}
+	placed := make(map[uuid.UUID]bool, len(alreadyPlaced))
+	for _, id := range alreadyPlaced {
+		placed[id] = true
+	}
+
+	averages, err := s.averageScores(memberships, fromSession)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]promotionPlan, 0, len(memberships))
+	for _, m := range memberships {
+		plan := promotionPlan{
+			membership: m,
+			decision: dto.PromotionDecisionResponse{
+				StudentID:   m.StudentID.String(),
+				StudentName: strings.TrimSpace(m.Student.FirstName + " " + m.Student.LastName),
+				FromArmID:   m.ArmID.String(),
+				FromArmName: m.Arm.Name,
+				FromLevel:   m.Arm.Grade.Level,
+			},
+		}
+		if avg, ok := averages[m.StudentID]; ok {
+			score := avg
+			plan.decision.AverageScore = &score
+		}
+
+		if placed[m.StudentID] {
+			plan.decision.Outcome = "skipped"
+			plan.decision.Reason = "already placed in target session"
+			plans = append(plans, plan)
+			continue
+		}
+
+		override, hasOverride := overrides[m.StudentID]
+		switch {
+		case hasOverride:
+			plan.decision.Outcome = override.Outcome
+			plan.decision.Reason = "manual override"
+			if override.Reason != "" {
+				plan.decision.Reason = "manual override: " + override.Reason
+			}
+		case plan.decision.AverageScore == nil && req.RepeatWithoutScore:
+			plan.decision.Outcome = "repeated"
+			plan.decision.Reason = "no scores recorded"
+		case plan.decision.AverageScore != nil && *plan.decision.AverageScore < passMark:
+			plan.decision.Outcome = "repeated"
+			plan.decision.Reason = fmt.Sprintf("average below pass mark of %.2f", passMark)
+		case m.Arm.Grade.Level >= finalLevel:
+			plan.decision.Outcome = "graduated"
+			plan.decision.Reason = "completed final level"
+		default:
+			plan.decision.Outcome = "promoted"
+			plan.decision.Reason = "met pass mark"
+			if plan.decision.AverageScore == nil {
+				plan.decision.Reason = "no scores recorded"
+			}
+		}
+
+		// Resolve the arm the student moves into
+		if plan.decision.Outcome == "promoted" || plan.decision.Outcome == "repeated" {
+			var arm *models.Arm
+			if hasOverride && override.TargetArmID != "" {
+				armID, err := uuid.Parse(override.TargetArmID)
+				if err != nil {
+					return nil, errors.New("invalid target arm ID in overrides: " + override.TargetArmID)
+				}
+				for i := range targetArms {
+					if targetArms[i].ID == armID {
+						arm = &targetArms[i]
+						break
+					}
+				}
+				if arm == nil {
+					return nil, errors.New("override target arm not found in target session: " + override.TargetArmID)
+				}
+			} else {
+				level := m.Arm.Grade.Level
+				if plan.decision.Outcome == "promoted" {
+					level++
+				}
+				arm = s.matchTargetArm(m.Arm, level, plan.decision.Outcome == "repeated", targetGrades, targetArms)
+			}
+
+			if arm == nil {
+				plan.decision.Outcome = "skipped"
+				plan.decision.Reason = "no matching arm in target session"
+			} else {
+				plan.toArm = arm
+				plan.decision.ToArmID = arm.ID.String()
+				plan.decision.ToArmName = arm.Name
+			}
+		}
+
+		plans = append(plans, plan)
+	}
+
+	sort.SliceStable(plans, func(i, j int) bool {
+		if plans[i].decision.FromLevel != plans[j].decision.FromLevel {
+			return plans[i].decision.FromLevel < plans[j].decision.FromLevel
+		}
+		if plans[i].decision.FromArmName != plans[j].decision.FromArmName {
+			return plans[i].decision.FromArmName < plans[j].decision.FromArmName
+		}
+		return plans[i].decision.StudentName < plans[j].decision.StudentName
+	})
+
+	if err := s.allocateSeats(plans, toSession); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// allocateSeats walks the plans in order and skips students whose target arm has no seat
+// left once its current occupants and the students placed before them are counted
+func (s *PromotionService) allocateSeats(plans []promotionPlan, toSession *models.AcademicSession) error {
+	armIDs := make([]uuid.UUID, 0)
+	for _, plan := range plans {
+		if plan.toArm != nil {
+			armIDs = append(armIDs, plan.toArm.ID)
+		}
+	}
+	if len(armIDs) == 0 {
+		return nil
+	}
+
+	var counts []struct {
+		ArmID uuid.UUID
+		Total int
+	}
+	if err := s.db.Model(&models.ClassMembership{}).
+		Select("arm_id, COUNT(*) AS total").
+		Where("arm_id IN ? AND academic_session_id = ? AND status = ? AND deleted_at IS NULL", armIDs, toSession.ID, "active").
+		Group("arm_id").Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to check arm capacity: %w", err)
+	}
+	occupied := make(map[uuid.UUID]int, len(counts))
+	for _, c := range counts {
+		occupied[c.ArmID] = c.Total
+	}
+
+	for i := range plans {
+		arm := plans[i].toArm
+		if arm == nil {
+			continue
+		}
+		if occupied[arm.ID] >= arm.Capacity {
+			plans[i].unseat(fmt.Sprintf("could not seat student: arm capacity exceeded: all %d seats are taken", arm.Capacity))
+			continue
+		}
+		occupied[arm.ID]++
+	}
+	return nil
+}
+
+// unseat turns a placement that cannot go ahead into a skipped decision; the student keeps
+// their current membership
+func (p *promotionPlan) unseat(reason string) {
+	p.toArm = nil
+	p.decision.Outcome = "skipped"
+	p.decision.Reason = reason
+	p.decision.ToArmID = ""
+	p.decision.ToArmName = ""
+}
+
+// matchTargetArm finds the arm in the target session matching the current arm at the given level
+func (s *PromotionService) matchTargetArm(current models.Arm, level int, sameGrade bool, grades []models.ClassGrade, arms []models.Arm) *models.Arm {
+	var candidates []uuid.UUID
+	for _, g := range grades {
+		if sameGrade && g.Code == current.Grade.Code {
+			candidates = append([]uuid.UUID{g.ID}, candidates...)
+			continue
+		}
+		if g.Level == level {
+			candidates = append(candidates, g.ID)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	gradeID := candidates[0]
+
+	var fallback *models.Arm
+	for i := range arms {
+		if arms[i].GradeID != gradeID {
+			continue
+		}
+		if arms[i].Code == current.Code || arms[i].Name == current.Name {
+			return &arms[i]
+		}
+		if fallback == nil {
+			fallback = &arms[i]
+		}
+	}
+	return fallback
+}
+
+// averageScores returns the mean score recorded for each student during the session
+func (s *PromotionService) averageScores(memberships []models.ClassMembership, session *models.AcademicSession) (map[uuid.UUID]float64, error) {
+	averages := make(map[uuid.UUID]float64)
+	if len(memberships) == 0 {
+		return averages, nil
+	}
+
+	studentIDs := make([]uuid.UUID, len(memberships))
+	for i, m := range memberships {
+		studentIDs[i] = m.StudentID
+	}
+
+	var rows []struct {
+		StudentID uuid.UUID
+		Average   float64
+	}
+	if err := s.db.Model(&models.Grade{}).
+		Select("student_id, AVG(score) AS average").
+		Where("student_id IN ? AND created_at >= ? AND created_at < ?", studentIDs, session.StartDate, session.EndDate.AddDate(0, 0, 1)).
+		Group("student_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute average scores: %w", err)
+	}
+
+	for _, row := range rows {
+		averages[row.StudentID] = row.Average
+	}
+	return averages, nil
+}
+
+// cloneArms copies the arms of a grade into its rolled-over counterpart
+func (s *PromotionService) cloneArms(tx *gorm.DB, fromGradeID, toGradeID uuid.UUID, activeOnly bool, userID uuid.UUID, mapping map[string]string) (int, error) {
+	query := tx.Where("grade_id = ? AND deleted_at IS NULL", fromGradeID)
+	if activeOnly {
+		query = query.Where("status = ?", "active")
+	}
+
+	var arms []models.Arm
+	if err := query.Find(&arms).Error; err != nil {
+		return 0, errors.New("failed to fetch arms: " + err.Error())
+	}
+
+	created := 0
+	for _, arm := range arms {
+		var existing models.Arm
+		err := tx.Where("grade_id = ? AND code = ? AND deleted_at IS NULL", toGradeID, arm.Code).First(&existing).Error
+		if err == nil {
+			mapping[arm.ID.String()] = existing.ID.String()
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("failed to check existing arm: " + err.Error())
+		}
+
+		clone := models.Arm{
+			ID:          uuid.New(),
+			Name:        arm.Name,
+			Description: arm.Description,
+			Code:        arm.Code,
+			GradeID:     toGradeID,
+			Status:      arm.Status,
+			Capacity:    arm.Capacity,
+			CreatedBy:   userID,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return 0, errors.New("failed to clone arm: " + err.Error())
+		}
+		mapping[arm.ID.String()] = clone.ID.String()
+		created++
+	}
+
+	return created, nil
+}
+
+// cloneGradeSubjects copies the subject allocations of a grade into its rolled-over counterpart
+func (s *PromotionService) cloneGradeSubjects(tx *gorm.DB, fromGradeID, toGradeID uuid.UUID, activeOnly bool, userID uuid.UUID) (int, error) {
+	query := tx.Where("grade_id = ? AND deleted_at IS NULL", fromGradeID)
+	if activeOnly {
+		query = query.Where("status = ?", "active")
+	}
+
+	var gradeSubjects []models.GradeSubject
+	if err := query.Find(&gradeSubjects).Error; err != nil {
+		return 0, errors.New("failed to fetch grade subjects: " + err.Error())
+	}
+
+	created := 0
+	for _, gs := range gradeSubjects {
+		// The unique index also covers soft-deleted rows
+		var count int64
+		if err := tx.Unscoped().Model(&models.GradeSubject{}).
+			Where("grade_id = ? AND subject_id = ?", toGradeID, gs.SubjectID).
+			Count(&count).Error; err != nil {
+			return 0, errors.New("failed to check existing grade subject: " + err.Error())
+		}
+		if count > 0 {
+			continue
+		}
+
+		clone := models.GradeSubject{
+			ID:           uuid.New(),
+			GradeID:      toGradeID,
+			SubjectID:    gs.SubjectID,
+			Status:       gs.Status,
+			IsCompulsory: gs.IsCompulsory,
+			CreatedBy:    userID,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return 0, errors.New("failed to clone grade subject: " + err.Error())
+		}
+		created++
+	}
+
+	return created, nil
+}
+
+// loadSessions parses and fetches the source and target academic sessions
+func (s *PromotionService) loadSessions(fromID, toID string) (*models.AcademicSession, *models.AcademicSession, error) {
+	fromSessionID, err := uuid.Parse(fromID)
+	if err != nil {
+		return nil, nil, errors.New("invalid source session ID")
+	}
+	toSessionID, err := uuid.Parse(toID)
+	if err != nil {
+		return nil, nil, errors.New("invalid target session ID")
+	}
+	if fromSessionID == toSessionID {
+		return nil, nil, errors.New("source and target sessions must be different")
+	}
+
+	var fromSession, toSession models.AcademicSession
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", fromSessionID).First(&fromSession).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("source session not found")
+		}
+		return nil, nil, errors.New("failed to fetch source session: " + err.Error())
+	}
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", toSessionID).First(&toSession).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("target session not found")
+		}
+		return nil, nil, errors.New("failed to fetch target session: " + err.Error())
+	}
+
+	return &fromSession, &toSession, nil
+}
+
+// toPromotionResponse tallies plans into a response DTO
+func (s *PromotionService) toPromotionResponse(plans []promotionPlan, req *dto.PromotionRequest, fromID, toID uuid.UUID) *dto.PromotionResponse {
+	passMark := passMarkFor(req)
+
+	response := &dto.PromotionResponse{
+		FromSessionID: fromID.String(),
+		ToSessionID:   toID.String(),
+		PassMark:      passMark,
+		TotalStudents: len(plans),
+		Decisions:     make([]dto.PromotionDecisionResponse, len(plans)),
+	}
+
+	for i, plan := range plans {
+		response.Decisions[i] = plan.decision
+		switch plan.decision.Outcome {
+		case "promoted":
+			response.PromotedCount++
+		case "repeated":
+			response.RepeatedCount++
+		case "graduated":
+			response.GraduatedCount++
+		default:
+			response.SkippedCount++
+		}
+	}
+
+	return response
+}
+
+// toRunResponse converts a stored promotion run to a response DTO
+func (s *PromotionService) toRunResponse(run *models.PromotionRun) *dto.PromotionResponse {
+	response := &dto.PromotionResponse{
+		RunID:          run.ID.String(),
+		FromSessionID:  run.FromSessionID.String(),
+		ToSessionID:    run.ToSessionID.String(),
+		PassMark:       run.PassMark,
+		TotalStudents:  run.TotalStudents,
+		PromotedCount:  run.PromotedCount,
+		RepeatedCount:  run.RepeatedCount,
+		GraduatedCount: run.GraduatedCount,
+		SkippedCount:   run.SkippedCount,
+		ExecutedBy:     run.ExecutedBy.String(),
+		CreatedAt:      &run.CreatedAt,
+		Decisions:      make([]dto.PromotionDecisionResponse, len(run.Decisions)),
+	}
+
+	for i, d := range run.Decisions {
+		decision := dto.PromotionDecisionResponse{
+			StudentID:    d.StudentID.String(),
+			FromArmID:    d.FromArmID.String(),
+			Outcome:      d.Outcome,
+			AverageScore: d.AverageScore,
+			Reason:       d.Reason,
+		}
+		if d.ToArmID != nil {
+			decision.ToArmID = d.ToArmID.String()
+		}
+		response.Decisions[i] = decision
+	}
+
+	return response
+}
@@ -0,0 +1,607 @@
+// services/publishing_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+	permissionServices "crm-go/services/permissions"
+	"crm-go/utils"
+)
+
+// PublishingService moves courses and modules through draft -> review -> published -> archived
+type PublishingService struct {
+	db *gorm.DB
+}
+
+func NewPublishingService(db *gorm.DB) *PublishingService {
+	return &PublishingService{db: db}
+}
+
+// reviewItem is the workflow view shared by courses and modules
+type reviewItem struct {
+	Type        string
+	ID          uuid.UUID
+	CourseID    uuid.UUID
+	Title       string
+	Status      string
+	OwnerID     uuid.UUID // tutor of the course
+	ReviewerID  *uuid.UUID
+	SubmittedAt *time.Time
+	PublishedAt *time.Time
+	ArchivedAt  *time.Time
+}
+
+// GetStatus returns where a course or module is in the workflow
+func (s *PublishingService) GetStatus(itemType, id string, userID uuid.UUID, canManage bool) (*dto.PublishingStatusResponse, error) {
+	item, err := s.loadItem(s.db, itemType, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(item, userID, canManage) && !canReview(item, userID, canManage) {
+		return nil, errors.New("not authorized to view this " + itemType)
+	}
+	return toStatusResponse(item), nil
+}
+
+// Checks runs the publish checks without changing anything
+func (s *PublishingService) Checks(itemType, id string, userID uuid.UUID, canManage bool) (*dto.PublishChecksResponse, error) {
+	item, err := s.loadItem(s.db, itemType, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(item, userID, canManage) && !canReview(item, userID, canManage) {
+		return nil, errors.New("not authorized to view this " + itemType)
+	}
+	return s.runChecks(s.db, item)
+}
+
+// Submit sends a draft for review once its publish checks pass
+func (s *PublishingService) Submit(itemType, id string, userID uuid.UUID, canManage bool, note string) (*dto.PublishingStatusResponse, error) {
+	return s.transition(itemType, id, func(tx *gorm.DB, item *reviewItem) (*workflowStep, error) {
+		if !canEdit(item, userID, canManage) {
+			return nil, errors.New("not authorized to submit this " + itemType)
+		}
+		if item.Status != "draft" {
+			return nil, errors.New("only draft items can be submitted for review")
+		}
+		if err := s.requireChecks(tx, item); err != nil {
+			return nil, err
+		}
+		return &workflowStep{
+			updates: map[string]interface{}{
+				"status":       "review",
+				"submitted_at": time.Now(),
+			},
+			entry: &models.ReviewComment{
+				AuthorID: userID,
+				Action:   "submitted",
+				Body:     note,
+			},
+		}, nil
+	}, func(item *reviewItem) {
+		if item.ReviewerID != nil {
+			s.notify(*item.ReviewerID, item, "is ready for your review", "")
+		}
+	})
+}
+
+// AssignReviewer sets the user who reviews an item, who must be able to take part in publishing; owners cannot review their own work
+func (s *PublishingService) AssignReviewer(itemType, id string, req *dto.AssignReviewerRequest, adminID uuid.UUID) (*dto.PublishingStatusResponse, error) {
+	reviewerID, err := uuid.Parse(req.ReviewerID)
+	if err != nil {
+		return nil, errors.New("invalid reviewer ID")
+	}
+
+	var reviewer models.User
+	if err := s.db.Select("id").Where("id = ?", reviewerID).First(&reviewer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("reviewer not found")
+		}
+		return nil, errors.New("failed to fetch reviewer: " + err.Error())
+	}
+	eligible, err := permissionServices.NewPermissionService(s.db).Authorize(reviewer.ID, "publishing:submit")
+	if err != nil {
+		return nil, err
+	}
+	if !eligible {
+		return nil, errors.New("reviewer must hold the publishing:submit permission")
+	}
+
+	return s.transition(itemType, id, func(tx *gorm.DB, item *reviewItem) (*workflowStep, error) {
+		if item.Status != "draft" && item.Status != "review" {
+			return nil, errors.New("reviewers can only be assigned before an item is published")
+		}
+		if item.OwnerID == reviewerID {
+			return nil, errors.New("the course tutor cannot review their own work")
+		}
+		return &workflowStep{
+			updates: map[string]interface{}{
+				"reviewer_id": reviewerID,
+			},
+			entry: &models.ReviewComment{
+				AuthorID: adminID,
+				Action:   "reviewer_assigned",
+				Body:     "Reviewer assigned: " + reviewerID.String(),
+			},
+		}, nil
+	}, func(item *reviewItem) {
+		s.notify(reviewerID, item, "has been assigned to you for review", "")
+	})
+}
+
+// RequestChanges sends an item in review back to draft with the reviewer's feedback
+func (s *PublishingService) RequestChanges(itemType, id string, req *dto.ReviewCommentRequest, userID uuid.UUID, canManage bool) (*dto.PublishingStatusResponse, error) {
+	return s.transition(itemType, id, func(tx *gorm.DB, item *reviewItem) (*workflowStep, error) {
+		if !canReview(item, userID, canManage) {
+			return nil, errors.New("not authorized to review this " + itemType)
+		}
+		if item.Status != "review" && item.Status != "approved" {
+			return nil, errors.New("only items in review can be sent back")
+		}
+		return &workflowStep{
+			updates: map[string]interface{}{
+				"status": "draft",
+			},
+			entry: &models.ReviewComment{
+				AuthorID: userID,
+				Action:   "changes_requested",
+				Body:     req.Body,
+			},
+		}, nil
+	}, func(item *reviewItem) {
+		s.notify(item.OwnerID, item, "needs changes before it can be published", req.Body)
+	})
+}
+
+// Publish makes an item in review live once its publish checks pass
+func (s *PublishingService) Publish(itemType, id string, userID uuid.UUID, canManage bool) (*dto.PublishingStatusResponse, error) {
+	return s.transition(itemType, id, func(tx *gorm.DB, item *reviewItem) (*workflowStep, error) {
+		if !canReview(item, userID, canManage) {
+			return nil, errors.New("not authorized to publish this " + itemType)
+		}
+		if item.Status != "review" && item.Status != "approved" {
+			return nil, errors.New("only items in review can be published")
+		}
+		if err := s.requireChecks(tx, item); err != nil {
+			return nil, err
+		}
+		return &workflowStep{
+			updates: map[string]interface{}{
+				"status":       "published",
+				"published_at": time.Now(),
+				"archived_at":  nil,
+			},
+			entry: &models.ReviewComment{
+				AuthorID: userID,
+				Action:   "published",
+			},
+		}, nil
+	}, func(item *reviewItem) {
+		s.notify(item.OwnerID, item, "has been published", "")
+	})
+}
+
+// Archive takes a published item out of listings; enrolled students keep their access
+func (s *PublishingService) Archive(itemType, id string, adminID uuid.UUID, note string) (*dto.PublishingStatusResponse, error) {
+	return s.transition(itemType, id, func(tx *gorm.DB, item *reviewItem) (*workflowStep, error) {
+		if item.Status != "published" {
+			return nil, errors.New("only published items can be archived")
+		}
+		return &workflowStep{
+			updates: map[string]interface{}{
+				"status":      "archived",
+				"archived_at": time.Now(),
+			},
+			entry: &models.ReviewComment{
+				AuthorID: adminID,
+				Action:   "archived",
+				Body:     note,
+			},
+		}, nil
+	}, nil)
+}
+
+// AddComment adds a comment to an item's review thread
+func (s *PublishingService) AddComment(itemType, id string, req *dto.ReviewCommentRequest, userID uuid.UUID, canManage bool) (*dto.ReviewCommentResponse, error) {
+	item, err := s.loadItem(s.db, itemType, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(item, userID, canManage) && !canReview(item, userID, canManage) {
+		return nil, errors.New("not authorized to comment on this " + itemType)
+	}
+
+	comment := models.ReviewComment{
+		ItemType: item.Type,
+		ItemID:   item.ID,
+		AuthorID: userID,
+		Action:   "comment",
+		Body:     req.Body,
+	}
+	if err := s.db.Omit(clause.Associations).Create(&comment).Error; err != nil {
+		return nil, errors.New("failed to save comment: " + err.Error())
+	}
+
+	var saved models.ReviewComment
+	if err := s.db.Preload("Author").Where("id = ?", comment.ID).First(&saved).Error; err != nil {
+		return nil, errors.New("failed to fetch comment: " + err.Error())
+	}
+	return toCommentResponse(&saved), nil
+}
+
+// GetComments returns an item's review thread, oldest first
+func (s *PublishingService) GetComments(itemType, id string, userID uuid.UUID, canManage bool) ([]dto.ReviewCommentResponse, error) {
+	item, err := s.loadItem(s.db, itemType, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(item, userID, canManage) && !canReview(item, userID, canManage) {
+		return nil, errors.New("not authorized to view this " + itemType)
+	}
+
+	var comments []models.ReviewComment
+	if err := s.db.Preload("Author").
+		Where("item_type = ? AND item_id = ?", item.Type, item.ID).
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return nil, errors.New("failed to fetch comments: " + err.Error())
+	}
+
+	responses := make([]dto.ReviewCommentResponse, 0, len(comments))
+	for i := range comments {
+		responses = append(responses, *toCommentResponse(&comments[i]))
+	}
+	return responses, nil
+}
+
+// GetQueue lists courses and modules waiting in review; reviewers who do not manage publishing only see items assigned to them
+func (s *PublishingService) GetQueue(params *dto.ReviewQueueParams, userID uuid.UUID, canManage bool) ([]dto.PublishingStatusResponse, error) {
+	filter := func(query *gorm.DB) (*gorm.DB, error) {
+		switch {
+		case !canManage:
+			query = query.Where("reviewer_id = ?", userID)
+		case params.Unassigned:
+			query = query.Where("reviewer_id IS NULL")
+		case params.ReviewerID != "":
+			reviewerID, err := uuid.Parse(params.ReviewerID)
+			if err != nil {
+				return nil, errors.New("invalid reviewer ID")
+			}
+			query = query.Where("reviewer_id = ?", reviewerID)
+		}
+		return query, nil
+	}
+
+	queue := []dto.PublishingStatusResponse{}
+
+	if params.Type == "" || params.Type == "course" {
+		query, err := filter(s.db.Model(&models.Course{}).Where("status = ?", "review"))
+		if err != nil {
+			return nil, err
+		}
+		var courses []models.Course
+		if err := query.Order("submitted_at ASC").Find(&courses).Error; err != nil {
+			return nil, errors.New("failed to fetch courses in review: " + err.Error())
+		}
+		for i := range courses {
+			queue = append(queue, *toStatusResponse(courseItem(&courses[i])))
+		}
+	}
+
+	if params.Type == "" || params.Type == "module" {
+		query, err := filter(s.db.Model(&models.Module{}).Where("status IN ?", []string{"review", "approved"}))
+		if err != nil {
+			return nil, err
+		}
+		var modules []models.Module
+		if err := query.Preload("Course").Order("submitted_at ASC").Find(&modules).Error; err != nil {
+			return nil, errors.New("failed to fetch modules in review: " + err.Error())
+		}
+		for i := range modules {
+			queue = append(queue, *toStatusResponse(moduleItem(&modules[i])))
+		}
+	}
+
+	return queue, nil
+}
+
+// CourseChecks runs the course publish checks; exported for course versions, which
+// publish a reviewed copy of a course
+func (s *PublishingService) CourseChecks(db *gorm.DB, courseID uuid.UUID) (*dto.PublishChecksResponse, error) {
+	var course models.Course
+	if err := db.Where("id = ?", courseID).First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course not found")
+		}
+		return nil, errors.New("failed to fetch course: " + err.Error())
+	}
+	return s.runChecks(db, courseItem(&course))
+}
+
+// workflowStep is the outcome of a workflow action: column updates and the review thread entry recording it
+type workflowStep struct {
+	updates map[string]interface{}
+	entry   *models.ReviewComment
+}
+
+// transition loads and locks an item, applies a workflow step and records it in the review thread
+func (s *PublishingService) transition(
+	itemType, id string,
+	step func(tx *gorm.DB, item *reviewItem) (*workflowStep, error),
+	after func(item *reviewItem),
+) (*dto.PublishingStatusResponse, error) {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	item, err := s.loadItem(tx, itemType, id, true)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result, err := step(tx, item)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var model interface{} = &models.Course{}
+	if item.Type == "module" {
+		model = &models.Module{}
+	}
+	if err := tx.Model(model).Where("id = ?", item.ID).Updates(result.updates).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update " + item.Type + ": " + err.Error())
+	}
+
+	if result.entry != nil {
+		result.entry.ItemType = item.Type
+		result.entry.ItemID = item.ID
+		if err := tx.Omit(clause.Associations).Create(result.entry).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to record review history: " + err.Error())
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	updated, err := s.loadItem(s.db, item.Type, item.ID.String(), false)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		after(updated)
+	}
+	return toStatusResponse(updated), nil
+}
+
+// requireChecks fails with the first unmet publish check
+func (s *PublishingService) requireChecks(db *gorm.DB, item *reviewItem) error {
+	checks, err := s.runChecks(db, item)
+	if err != nil {
+		return err
+	}
+	for _, check := range checks.Checks {
+		if !check.Passed {
+			return errors.New("publish check failed: " + check.Message)
+		}
+	}
+	return nil
+}
+
+// runChecks evaluates the publish checks: a course needs an image, a price, and modules with
+// lessons of which at least one module is published; a module needs lessons and topics
+func (s *PublishingService) runChecks(db *gorm.DB, item *reviewItem) (*dto.PublishChecksResponse, error) {
+	response := &dto.PublishChecksResponse{
+		ItemType: item.Type,
+		ItemID:   item.ID.String(),
+		Status:   item.Status,
+		Ready:    true,
+	}
+
+	count := func(model interface{}, query string, args ...interface{}) (int64, error) {
+		var n int64
+		if err := db.Model(model).Where(query, args...).Count(&n).Error; err != nil {
+			return 0, errors.New("failed to run publish checks: " + err.Error())
+		}
+		return n, nil
+	}
+	add := func(name string, passed bool, message string) {
+		response.Checks = append(response.Checks, dto.PublishCheck{Name: name, Passed: passed, Message: message})
+		if !passed {
+			response.Ready = false
+		}
+	}
+
+	if item.Type == "course" {
+		var course models.Course
+		if err := db.Select("id, image").Where("id = ?", item.ID).First(&course).Error; err != nil {
+			return nil, errors.New("failed to run publish checks: " + err.Error())
+		}
+		add("image", course.Image != "", "course needs a cover image")
+
+		modules, err := count(&models.Module{}, "course_id = ?", item.ID)
+		if err != nil {
+			return nil, err
+		}
+		add("modules", modules > 0, "course needs at least one module")
+
+		published, err := count(&models.Module{}, "course_id = ? AND status = ?", item.ID, "published")
+		if err != nil {
+			return nil, err
+		}
+		add("published_modules", published > 0, "course needs at least one published module")
+
+		lessons, err := count(&models.Lesson{}, "course_id = ?", item.ID)
+		if err != nil {
+			return nil, err
+		}
+		add("lessons", lessons > 0, "course needs at least one lesson")
+
+		priced, err := count(&models.Product{},
+			"status = ? AND price > 0 AND id IN (?)", "active",
+			db.Model(&models.CourseProductTable{}).Select("product_id").Where("course_id = ?", item.ID))
+		if err != nil {
+			return nil, err
+		}
+		add("price", priced > 0, "course needs an active product with a price")
+		return response, nil
+	}
+
+	lessons, err := count(&models.Lesson{}, "module_id = ?", item.ID)
+	if err != nil {
+		return nil, err
+	}
+	add("lessons", lessons > 0, "module needs at least one lesson")
+
+	topics, err := count(&models.Topics{}, "module_id = ?", item.ID)
+	if err != nil {
+		return nil, err
+	}
+	add("topics", topics > 0, "module needs at least one topic")
+	return response, nil
+}
+
+// loadItem fetches a course or module as a review item, optionally locking its row
+func (s *PublishingService) loadItem(db *gorm.DB, itemType, id string, lock bool) (*reviewItem, error) {
+	itemID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid " + itemType + " ID")
+	}
+
+	query := db
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	switch itemType {
+	case "course":
+		var course models.Course
+		if err := query.Where("id = ?", itemID).First(&course).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("course not found")
+			}
+			return nil, errors.New("failed to fetch course: " + err.Error())
+		}
+		return courseItem(&course), nil
+	case "module":
+		var module models.Module
+		if err := query.Where("id = ?", itemID).First(&module).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("module not found")
+			}
+			return nil, errors.New("failed to fetch module: " + err.Error())
+		}
+		if err := db.Select("id, title, tutor_id").Where("id = ?", module.CourseID).First(&module.Course).Error; err != nil {
+			return nil, errors.New("failed to fetch course: " + err.Error())
+		}
+		return moduleItem(&module), nil
+	}
+	return nil, errors.New("invalid item type")
+}
+
+// notify emails a workflow update to a user; failures are logged, not returned
+func (s *PublishingService) notify(userID uuid.UUID, item *reviewItem, what, note string) {
+	var user models.User
+	if err := s.db.Select("id, email, first_name").Where("id = ?", userID).First(&user).Error; err != nil || user.Email == "" {
+		return
+	}
+
+	subject := fmt.Sprintf("The %s \"%s\" %s", item.Type, item.Title, what)
+	body := fmt.Sprintf("<p>Hello %s,</p><p>The %s <strong>%s</strong> %s.</p>", user.FirstName, item.Type, item.Title, what)
+	if note != "" {
+		body += fmt.Sprintf("<p>Reviewer notes:</p><blockquote>%s</blockquote>", note)
+	}
+
+	if err := utils.SendEmail(user.Email, subject, body); err != nil {
+		log.Printf("publishing email to %s failed: %v", user.Email, err)
+	}
+}
+
+func canEdit(item *reviewItem, userID uuid.UUID, canManage bool) bool {
+	return canManage || item.OwnerID == userID
+}
+
+func canReview(item *reviewItem, userID uuid.UUID, canManage bool) bool {
+	return canManage || (item.ReviewerID != nil && *item.ReviewerID == userID)
+}
+
+func courseItem(course *models.Course) *reviewItem {
+	return &reviewItem{
+		Type:        "course",
+		ID:          course.ID,
+		CourseID:    course.ID,
+		Title:       course.Title,
+		Status:      course.Status,
+		OwnerID:     course.TutorID,
+		ReviewerID:  course.ReviewerID,
+		SubmittedAt: course.SubmittedAt,
+		PublishedAt: course.PublishedAt,
+		ArchivedAt:  course.ArchivedAt,
+	}
+}
+
+func moduleItem(module *models.Module) *reviewItem {
+	return &reviewItem{
+		Type:        "module",
+		ID:          module.ID,
+		CourseID:    module.CourseID,
+		Title:       module.Title,
+		Status:      module.Status,
+		OwnerID:     module.Course.TutorID,
+		ReviewerID:  module.ReviewerID,
+		SubmittedAt: module.SubmittedAt,
+		PublishedAt: module.PublishedAt,
+		ArchivedAt:  module.ArchivedAt,
+	}
+}
+
+func toStatusResponse(item *reviewItem) *dto.PublishingStatusResponse {
+	response := &dto.PublishingStatusResponse{
+		ItemType:    item.Type,
+		ItemID:      item.ID.String(),
+		CourseID:    item.CourseID.String(),
+		Title:       item.Title,
+		Status:      item.Status,
+		OwnerID:     item.OwnerID.String(),
+		SubmittedAt: item.SubmittedAt,
+		PublishedAt: item.PublishedAt,
+		ArchivedAt:  item.ArchivedAt,
+	}
+	if item.ReviewerID != nil {
+		reviewerID := item.ReviewerID.String()
+		response.ReviewerID = &reviewerID
+	}
+	return response
+}
+
+func toCommentResponse(comment *models.ReviewComment) *dto.ReviewCommentResponse {
+	response := &dto.ReviewCommentResponse{
+		ID:        comment.ID.String(),
+		ItemType:  comment.ItemType,
+		ItemID:    comment.ItemID.String(),
+		AuthorID:  comment.AuthorID.String(),
+		Action:    comment.Action,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+	}
+	if comment.Author.ID != uuid.Nil {
+		response.AuthorName = comment.Author.FirstName + " " + comment.Author.LastName
+	}
+	return response
+}
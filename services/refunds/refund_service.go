@@ -0,0 +1,542 @@
+// services/refund_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/services/activity"
+	"crm-go/services/payments"
+)
+
+var cfg = config.LoadEnv()
+
+type RefundService struct {
+	db       *gorm.DB
+	activity *activity.Service
+}
+
+func NewRefundService(db *gorm.DB, activitySvc *activity.Service) *RefundService {
+	return &RefundService{
+		db:       db,
+		activity: activitySvc,
+	}
+}
+
+// refundTerms is the outcome of applying the refund policy to an enrollment
+type refundTerms struct {
+	payment         *models.Payment
+	paid            float64 // share of the payment that bought this enrollment
+	rule            string
+	amount          float64
+	fullRefundUntil time.Time
+}
+
+// QuoteRefund previews what the refund policy allows for an enrollment
+func (s *RefundService) QuoteRefund(enrollmentID string, userID uuid.UUID, canManage bool) (*dto.RefundQuoteResponse, error) {
+	enrollment, err := s.loadEnrollment(s.db, enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage && enrollment.StudentID != userID {
+		return nil, errors.New("not authorized to view this enrollment")
+	}
+
+	quote := &dto.RefundQuoteResponse{
+		EnrollmentID:       enrollment.ID.String(),
+		Currency:           enrollment.Currency,
+		ProgressPercentage: enrollment.ProgressPercentage,
+	}
+
+	terms, err := s.evaluate(s.db, enrollment)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "failed to") {
+			return nil, err
+		}
+		quote.Reason = err.Error()
+		return quote, nil
+	}
+
+	quote.Eligible = true
+	quote.PolicyRule = terms.rule
+	quote.AmountPaid = terms.paid
+	quote.RefundAmount = terms.amount
+	quote.Currency = terms.payment.Currency
+	quote.FullRefundUntil = &terms.fullRefundUntil
+	return quote, nil
+}
+
+// RequestRefund opens a refund request for a paid enrollment
+func (s *RefundService) RequestRefund(req *dto.CreateRefundRequest, userID uuid.UUID, canManage bool) (*dto.RefundResponse, error) {
+	enrollment, err := s.loadEnrollment(s.db, req.EnrollmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage && enrollment.StudentID != userID {
+		return nil, errors.New("not authorized to request a refund for this enrollment")
+	}
+
+	var open int64
+	if err := s.db.Model(&models.RefundRequest{}).
+		Where("enrollment_id = ? AND status IN ?", enrollment.ID, []string{"pending", "failed"}).
+		Count(&open).Error; err != nil {
+		return nil, errors.New("failed to check existing refund requests: " + err.Error())
+	}
+	if open > 0 {
+		return nil, errors.New("a refund request already exists for this enrollment")
+	}
+
+	terms, err := s.evaluate(s.db, enrollment)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refund := &models.RefundRequest{
+		ID:                uuid.New(),
+		EnrollmentID:      enrollment.ID,
+		StudentID:         enrollment.StudentID,
+		CourseID:          enrollment.CourseID,
+		PaymentID:         &terms.payment.ID,
+		Reason:            strings.TrimSpace(req.Reason),
+		Status:            "pending",
+		PolicyRule:        terms.rule,
+		AmountPaid:        terms.paid,
+		RefundAmount:      terms.amount,
+		Currency:          terms.payment.Currency,
+		ProgressAtRequest: enrollment.ProgressPercentage,
+		Gateway:           terms.payment.Gateway,
+		RequestedBy:       userID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.db.Omit("Enrollment", "Student", "Course").Create(refund).Error; err != nil {
+		return nil, errors.New("failed to create refund request: " + err.Error())
+	}
+
+	return s.getRefund(refund.ID)
+}
+
+// GetRefunds lists refund requests with pagination and filters
+func (s *RefundService) GetRefunds(params *dto.RefundQueryParams) (*dto.RefundListResponse, error) {
+	// Set defaults
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	query := s.db.Model(&models.RefundRequest{})
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.StudentID != "" {
+		query = query.Where("student_id = ?", params.StudentID)
+	}
+	if params.CourseID != "" {
+		query = query.Where("course_id = ?", params.CourseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("failed to count refund requests: " + err.Error())
+	}
+
+	var refunds []models.RefundRequest
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Preload("Student").Preload("Course").
+		Order("created_at DESC").Offset(offset).Limit(params.Limit).
+		Find(&refunds).Error; err != nil {
+		return nil, errors.New("failed to fetch refund requests: " + err.Error())
+	}
+
+	responses := make([]dto.RefundResponse, 0, len(refunds))
+	for i := range refunds {
+		responses = append(responses, *s.toResponse(&refunds[i]))
+	}
+
+	totalPages := int(total) / params.Limit
+	if int(total)%params.Limit > 0 {
+		totalPages++
+	}
+
+	return &dto.RefundListResponse{
+		Refunds:    responses,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetRefundByID retrieves a refund request; students may only see their own
+func (s *RefundService) GetRefundByID(id string, userID uuid.UUID, canManage bool) (*dto.RefundResponse, error) {
+	refundID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid refund request ID")
+	}
+
+	refund, err := s.getRefund(refundID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage && refund.StudentID != userID.String() {
+		return nil, errors.New("not authorized to view this refund request")
+	}
+	return refund, nil
+}
+
+// ApproveRefund re-checks the policy, refunds through the payment gateway and cancels the enrollment
+func (s *RefundService) ApproveRefund(id string, reviewerID uuid.UUID, req *dto.ReviewRefundRequest) (*dto.RefundResponse, error) {
+	refundID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid refund request ID")
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var refund models.RefundRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", refundID).First(&refund).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refund request not found")
+		}
+		return nil, errors.New("failed to fetch refund request: " + err.Error())
+	}
+	if refund.Status != "pending" && refund.Status != "failed" {
+		tx.Rollback()
+		return nil, errors.New("refund request is already " + refund.Status)
+	}
+
+	enrollment, err := s.loadEnrollment(tx.Clauses(clause.Locking{Strength: "UPDATE"}), refund.EnrollmentID.String())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Progress or certification may have changed since the request was made
+	terms, err := s.evaluate(tx, enrollment)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	provider, err := payments.ProviderFor(terms.payment.Gateway)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	result, gatewayErr := provider.Refund(context.Background(), payments.RefundRequest{
+		PaymentID:        terms.payment.PaymentID,
+		GatewayReference: terms.payment.GatewayReference,
+		Amount:           terms.amount,
+		Currency:         terms.payment.Currency,
+		Reason:           refund.Reason,
+	})
+	if gatewayErr != nil {
+		// Keep the failure on record so the refund can be retried
+		if err := tx.Model(&models.RefundRequest{}).Where("id = ?", refund.ID).Updates(map[string]interface{}{
+			"status":         "failed",
+			"failure_reason": truncate(gatewayErr.Error(), 255),
+			"reviewed_by":    reviewerID,
+			"reviewed_at":    now,
+			"updated_at":     now,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to record refund failure: " + err.Error())
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, errors.New("failed to record refund failure: " + err.Error())
+		}
+		return nil, errors.New("gateway refund failed: " + gatewayErr.Error())
+	}
+
+	refund.Status = "processed"
+	refund.PolicyRule = terms.rule
+	refund.RefundAmount = terms.amount
+	refund.ProgressAtRequest = enrollment.ProgressPercentage
+	refund.GatewayRefundID = result.Reference
+	refund.FailureReason = ""
+	refund.ReviewedBy = &reviewerID
+	refund.ReviewNote = strings.TrimSpace(req.Note)
+	refund.ReviewedAt = &now
+	refund.ProcessedAt = &now
+	refund.UpdatedAt = now
+	if err := tx.Model(&models.RefundRequest{}).Where("id = ?", refund.ID).Updates(map[string]interface{}{
+		"status":              refund.Status,
+		"policy_rule":         refund.PolicyRule,
+		"refund_amount":       refund.RefundAmount,
+		"progress_at_request": refund.ProgressAtRequest,
+		"gateway_refund_id":   refund.GatewayRefundID,
+		"failure_reason":      "",
+		"reviewed_by":         reviewerID,
+		"review_note":         refund.ReviewNote,
+		"reviewed_at":         now,
+		"processed_at":        now,
+		"updated_at":          now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update refund request: " + err.Error())
+	}
+
+	// Earlier refunds against the same payment, e.g. other courses from one order
+	var refundedBefore float64
+	if err := tx.Model(&models.RefundRequest{}).
+		Where("payment_id = ? AND status = ? AND id <> ?", terms.payment.ID, "processed", refund.ID).
+		Select("COALESCE(SUM(refund_amount), 0)").Scan(&refundedBefore).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to total earlier refunds: " + err.Error())
+	}
+
+	paymentStatus := "partially_refunded"
+	if refundedBefore+terms.amount >= terms.payment.Amount {
+		paymentStatus = "refunded"
+	}
+	if err := tx.Model(&models.Payment{}).Where("id = ?", terms.payment.ID).Updates(map[string]interface{}{
+		"status":      paymentStatus,
+		"refunded_at": now,
+		"updated_at":  now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update payment: " + err.Error())
+	}
+
+	// Revoke course access
+	if err := tx.Model(&models.Enrollment{}).Where("id = ?", enrollment.ID).Updates(map[string]interface{}{
+		"status":          "cancelled",
+		"payment_status":  "refunded",
+		"expiration_date": now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to cancel enrollment: " + err.Error())
+	}
+
+	if err := s.activity.Enrollments.Unenrolled(tx, reviewerID, *enrollment, refund); err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to log unenrollment: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to approve refund: " + err.Error())
+	}
+
+	return s.getRefund(refund.ID)
+}
+
+// RejectRefund declines a refund request and leaves the enrollment untouched
+func (s *RefundService) RejectRefund(id string, reviewerID uuid.UUID, req *dto.ReviewRefundRequest) (*dto.RefundResponse, error) {
+	refundID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid refund request ID")
+	}
+	if strings.TrimSpace(req.Note) == "" {
+		return nil, errors.New("a note is required when rejecting a refund")
+	}
+
+	var refund models.RefundRequest
+	if err := s.db.Where("id = ?", refundID).First(&refund).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refund request not found")
+		}
+		return nil, errors.New("failed to fetch refund request: " + err.Error())
+	}
+	if refund.Status != "pending" && refund.Status != "failed" {
+		return nil, errors.New("refund request is already " + refund.Status)
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.RefundRequest{}).
+		Where("id = ? AND status IN ?", refund.ID, []string{"pending", "failed"}).
+		Updates(map[string]interface{}{
+			"status":      "rejected",
+			"reviewed_by": reviewerID,
+			"review_note": strings.TrimSpace(req.Note),
+			"reviewed_at": now,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return nil, errors.New("failed to reject refund request: " + result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("refund request was reviewed by someone else")
+	}
+
+	return s.getRefund(refund.ID)
+}
+
+// evaluate applies the refund policy to an enrollment and the payment that bought it
+func (s *RefundService) evaluate(db *gorm.DB, enrollment *models.Enrollment) (*refundTerms, error) {
+	if err := checkRefundable(enrollment); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.findPayment(db, enrollment)
+	if err != nil {
+		return nil, err
+	}
+	return refundTermsFor(enrollment, payment, time.Now())
+}
+
+// checkRefundable rules out enrollments that cannot be refunded whatever was paid
+func checkRefundable(enrollment *models.Enrollment) error {
+	if enrollment.Status == "cancelled" || enrollment.Status == "expired" {
+		return errors.New("enrollment is already " + enrollment.Status)
+	}
+	if enrollment.CertificateIssued || enrollment.CertificateID != nil {
+		return errors.New("refunds are not available once a certificate has been issued")
+	}
+	if enrollment.PaymentStatus != "paid" {
+		return errors.New("enrollment has no payment to refund")
+	}
+	return nil
+}
+
+// refundTermsFor works out the refund at the given time: the full amount within the refund
+// window, and a share pro-rated by progress after it
+func refundTermsFor(enrollment *models.Enrollment, payment *models.Payment, now time.Time) (*refundTerms, error) {
+	paidAt := payment.CreatedAt
+	if payment.ProcessedAt != nil {
+		paidAt = *payment.ProcessedAt
+	}
+
+	// An order payment can cover several courses; only this enrollment's share is refundable
+	paid := payment.Amount
+	if payment.OrderID != nil {
+		paid = enrollment.PricePaid
+	}
+
+	terms := &refundTerms{
+		payment:         payment,
+		paid:            paid,
+		fullRefundUntil: paidAt.AddDate(0, 0, cfg.RefundFullWindowDays),
+	}
+	if now.Before(terms.fullRefundUntil) {
+		terms.rule = "full"
+		terms.amount = paid
+		return terms, nil
+	}
+
+	terms.rule = "prorated"
+	terms.amount = math.Round(paid*float64(100-enrollment.ProgressPercentage)) / 100
+	if terms.amount <= 0 {
+		return nil, errors.New("nothing left to refund: the course has been fully completed")
+	}
+	return terms, nil
+}
+
+// findPayment locates the completed payment that paid for an enrollment
+func (s *RefundService) findPayment(db *gorm.DB, enrollment *models.Enrollment) (*models.Payment, error) {
+	query := db.Where("payer_id = ? AND status IN ?", enrollment.StudentID, []string{"completed", "partially_refunded"})
+	if enrollment.TransactionID != "" {
+		query = query.Where("(course_id = ? OR payment_id = ? OR gateway_reference = ?)", enrollment.CourseID, enrollment.TransactionID, enrollment.TransactionID)
+	} else {
+		query = query.Where("course_id = ?", enrollment.CourseID)
+	}
+
+	var payment models.Payment
+	if err := query.Order("created_at DESC").First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no completed payment found for this enrollment")
+		}
+		return nil, errors.New("failed to fetch payment: " + err.Error())
+	}
+	return &payment, nil
+}
+
+// loadEnrollment fetches an enrollment by ID
+func (s *RefundService) loadEnrollment(db *gorm.DB, id string) (*models.Enrollment, error) {
+	enrollmentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid enrollment ID")
+	}
+
+	var enrollment models.Enrollment
+	if err := db.Where("id = ?", enrollmentID).First(&enrollment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("enrollment not found")
+		}
+		return nil, errors.New("failed to fetch enrollment: " + err.Error())
+	}
+	return &enrollment, nil
+}
+
+// getRefund fetches a refund request with its student and course
+func (s *RefundService) getRefund(id uuid.UUID) (*dto.RefundResponse, error) {
+	var refund models.RefundRequest
+	if err := s.db.Preload("Student").Preload("Course").Where("id = ?", id).First(&refund).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refund request not found")
+		}
+		return nil, errors.New("failed to fetch refund request: " + err.Error())
+	}
+	return s.toResponse(&refund), nil
+}
+
+// toResponse converts model to response DTO
+func (s *RefundService) toResponse(refund *models.RefundRequest) *dto.RefundResponse {
+	response := &dto.RefundResponse{
+		ID:                refund.ID.String(),
+		EnrollmentID:      refund.EnrollmentID.String(),
+		StudentID:         refund.StudentID.String(),
+		CourseID:          refund.CourseID.String(),
+		Reason:            refund.Reason,
+		Status:            refund.Status,
+		PolicyRule:        refund.PolicyRule,
+		AmountPaid:        refund.AmountPaid,
+		RefundAmount:      refund.RefundAmount,
+		Currency:          refund.Currency,
+		ProgressAtRequest: refund.ProgressAtRequest,
+		Gateway:           refund.Gateway,
+		GatewayRefundID:   refund.GatewayRefundID,
+		FailureReason:     refund.FailureReason,
+		RequestedBy:       refund.RequestedBy.String(),
+		ReviewNote:        refund.ReviewNote,
+		ReviewedAt:        refund.ReviewedAt,
+		ProcessedAt:       refund.ProcessedAt,
+		CreatedAt:         refund.CreatedAt,
+		UpdatedAt:         refund.UpdatedAt,
+	}
+	if refund.Student.ID != uuid.Nil {
+		response.StudentName = strings.TrimSpace(refund.Student.FirstName + " " + refund.Student.LastName)
+	}
+	if refund.Course.ID != uuid.Nil {
+		response.CourseTitle = refund.Course.Title
+	}
+	if refund.PaymentID != nil {
+		paymentID := refund.PaymentID.String()
+		response.PaymentID = &paymentID
+	}
+	if refund.ReviewedBy != nil {
+		reviewedBy := refund.ReviewedBy.String()
+		response.ReviewedBy = &reviewedBy
+	}
+	return response
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
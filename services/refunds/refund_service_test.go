@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"crm-go/models"
+)
+
+func TestCheckRefundable(t *testing.T) {
+	certificateID := uuid.New()
+
+	tests := []struct {
+		name       string
+		enrollment models.Enrollment
+		wantErr    string
+	}{
+		{
+			name:       "paid and active",
+			enrollment: models.Enrollment{Status: "active", PaymentStatus: "paid"},
+		},
+		{
+			name:       "already cancelled",
+			enrollment: models.Enrollment{Status: "cancelled", PaymentStatus: "paid"},
+			wantErr:    "enrollment is already cancelled",
+		},
+		{
+			name:       "already expired",
+			enrollment: models.Enrollment{Status: "expired", PaymentStatus: "paid"},
+			wantErr:    "enrollment is already expired",
+		},
+		{
+			name:       "certificate issued",
+			enrollment: models.Enrollment{Status: "completed", PaymentStatus: "paid", CertificateIssued: true},
+			wantErr:    "refunds are not available once a certificate has been issued",
+		},
+		{
+			name:       "certificate linked",
+			enrollment: models.Enrollment{Status: "completed", PaymentStatus: "paid", CertificateID: &certificateID},
+			wantErr:    "refunds are not available once a certificate has been issued",
+		},
+		{
+			name:       "not paid",
+			enrollment: models.Enrollment{Status: "active", PaymentStatus: "pending"},
+			wantErr:    "enrollment has no payment to refund",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRefundable(&tt.enrollment)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("checkRefundable() error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("checkRefundable() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefundTermsFor(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	window := time.Duration(cfg.RefundFullWindowDays) * 24 * time.Hour
+	insideWindow := now.Add(-window + time.Hour)
+	outsideWindow := now.Add(-window - time.Hour)
+	orderID := uuid.New()
+
+	tests := []struct {
+		name       string
+		enrollment models.Enrollment
+		payment    models.Payment
+		wantRule   string
+		wantAmount float64
+		wantErr    bool
+	}{
+		{
+			name:       "inside the window refunds in full",
+			enrollment: models.Enrollment{ProgressPercentage: 80},
+			payment:    models.Payment{Amount: 120, CreatedAt: insideWindow},
+			wantRule:   "full",
+			wantAmount: 120,
+		},
+		{
+			name:       "processing time wins over creation time",
+			enrollment: models.Enrollment{ProgressPercentage: 50},
+			payment:    models.Payment{Amount: 120, CreatedAt: outsideWindow, ProcessedAt: &insideWindow},
+			wantRule:   "full",
+			wantAmount: 120,
+		},
+		{
+			name:       "after the window is pro-rated by progress",
+			enrollment: models.Enrollment{ProgressPercentage: 25},
+			payment:    models.Payment{Amount: 120, CreatedAt: outsideWindow},
+			wantRule:   "prorated",
+			wantAmount: 90,
+		},
+		{
+			name:       "pro-rated amounts round to cents",
+			enrollment: models.Enrollment{ProgressPercentage: 33},
+			payment:    models.Payment{Amount: 99.99, CreatedAt: outsideWindow},
+			wantRule:   "prorated",
+			wantAmount: 66.99,
+		},
+		{
+			name:       "order payments refund only the enrollment's share",
+			enrollment: models.Enrollment{ProgressPercentage: 0, PricePaid: 40},
+			payment:    models.Payment{Amount: 150, OrderID: &orderID, CreatedAt: insideWindow},
+			wantRule:   "full",
+			wantAmount: 40,
+		},
+		{
+			name:       "pro-rated order share",
+			enrollment: models.Enrollment{ProgressPercentage: 50, PricePaid: 40},
+			payment:    models.Payment{Amount: 150, OrderID: &orderID, CreatedAt: outsideWindow},
+			wantRule:   "prorated",
+			wantAmount: 20,
+		},
+		{
+			name:       "completed course after the window",
+			enrollment: models.Enrollment{ProgressPercentage: 100},
+			payment:    models.Payment{Amount: 120, CreatedAt: outsideWindow},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms, err := refundTermsFor(&tt.enrollment, &tt.payment, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("refundTermsFor() = %+v, want an error", terms)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("refundTermsFor() error = %v", err)
+			}
+			if terms.rule != tt.wantRule || terms.amount != tt.wantAmount {
+				t.Errorf("refundTermsFor() = %s %.2f, want %s %.2f", terms.rule, terms.amount, tt.wantRule, tt.wantAmount)
+			}
+		})
+	}
+}
@@ -0,0 +1,493 @@
+// services/reorder_service.go
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+	permissionServices "crm-go/services/permissions"
+	rollupServices "crm-go/services/rollup"
+)
+
+type ReorderService struct {
+	db *gorm.DB
+}
+
+func NewReorderService(db *gorm.DB) *ReorderService {
+	return &ReorderService{db: db}
+}
+
+// siblings describes how one kind of item is numbered within its parent
+type siblings struct {
+	table  string
+	column string
+	parent string
+}
+
+var (
+	moduleSiblings = siblings{table: "modules", column: "module_number", parent: "course_id"}
+	lessonSiblings = siblings{table: "lessons", column: "order", parent: "module_id"}
+	topicSiblings  = siblings{table: "topics", column: "order", parent: "lesson_id"}
+)
+
+// resequence places ids under parentID numbered 1..n in the given order. Rows are parked
+// at distinct negative positions first so the unique order index never sees a clash
+// while the rows are being renumbered.
+func (k siblings) resequence(tx *gorm.DB, parentID uuid.UUID, ids []uuid.UUID, extra map[string]interface{}) error {
+	now := time.Now()
+	for i, id := range ids {
+		updates := map[string]interface{}{k.parent: parentID, k.column: -(i + 1)}
+		for column, value := range extra {
+			updates[column] = value
+		}
+		if err := tx.Table(k.table).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return errors.New("failed to move " + k.table + ": " + err.Error())
+		}
+	}
+	for i, id := range ids {
+		if err := tx.Table(k.table).Where("id = ?", id).Updates(map[string]interface{}{
+			k.column:     i + 1,
+			"updated_at": now,
+		}).Error; err != nil {
+			return errors.New("failed to renumber " + k.table + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// renumber closes the gaps left in a parent after items were moved out of it
+func (k siblings) renumber(tx *gorm.DB, parentID uuid.UUID) error {
+	var ids []uuid.UUID
+	if err := tx.Table(k.table).
+		Where(k.parent+" = ?", parentID).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: k.column}}).
+		Order("created_at, id").
+		Pluck("id", &ids).Error; err != nil {
+		return errors.New("failed to fetch " + k.table + ": " + err.Error())
+	}
+	return k.resequence(tx, parentID, ids, nil)
+}
+
+// list returns the children of a parent in their saved order
+func (k siblings) list(db *gorm.DB, parentType string, parentID uuid.UUID) (*dto.ReorderResponse, error) {
+	var rows []struct {
+		ID       uuid.UUID
+		Title    string
+		Position int
+	}
+	if err := db.Table(k.table).
+		Select(`id, title, "`+k.column+`" AS position`).
+		Where(k.parent+" = ?", parentID).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: k.column}}).
+		Scan(&rows).Error; err != nil {
+		return nil, errors.New("failed to fetch " + k.table + ": " + err.Error())
+	}
+
+	response := &dto.ReorderResponse{
+		ParentType: parentType,
+		ParentID:   parentID.String(),
+		Items:      make([]dto.OrderedItem, 0, len(rows)),
+	}
+	for _, row := range rows {
+		response.Items = append(response.Items, dto.OrderedItem{
+			ID:       row.ID.String(),
+			Title:    row.Title,
+			Position: row.Position,
+		})
+	}
+	return response, nil
+}
+
+// ReorderModules sets the order of a course's modules; the list must name every module of the course
+func (s *ReorderService) ReorderModules(courseID string, req *dto.ReorderRequest, userID uuid.UUID) (*dto.ReorderResponse, error) {
+	id, err := uuid.Parse(courseID)
+	if err != nil {
+		return nil, errors.New("invalid course ID")
+	}
+	if err := s.authorize(id, userID); err != nil {
+		return nil, err
+	}
+	ids, err := parseIDs(req.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.inTransaction(func(tx *gorm.DB) error {
+		var modules []models.Module
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").Where("course_id = ?", id).Find(&modules).Error; err != nil {
+			return errors.New("failed to fetch modules: " + err.Error())
+		}
+		current := make(map[uuid.UUID]bool, len(modules))
+		for _, module := range modules {
+			current[module.ID] = true
+		}
+		if len(ids) != len(current) {
+			return errors.New("ids must list every module of the course exactly once")
+		}
+		for _, moduleID := range ids {
+			if !current[moduleID] {
+				return errors.New("module " + moduleID.String() + " does not belong to this course")
+			}
+		}
+		return moduleSiblings.resequence(tx, id, ids, nil)
+	}); err != nil {
+		return nil, err
+	}
+
+	return moduleSiblings.list(s.db, "course", id)
+}
+
+// ReorderLessons sets the order of a module's lessons. Lessons from other modules of the
+// same course may be listed to move them into this module.
+func (s *ReorderService) ReorderLessons(moduleID string, req *dto.ReorderRequest, userID uuid.UUID) (*dto.ReorderResponse, error) {
+	module, err := s.loadModule(moduleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(module.CourseID, userID); err != nil {
+		return nil, err
+	}
+	ids, err := parseIDs(req.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.inTransaction(func(tx *gorm.DB) error {
+		return s.placeLessons(tx, module, ids)
+	}); err != nil {
+		return nil, err
+	}
+
+	return lessonSiblings.list(s.db, "module", module.ID)
+}
+
+// MoveLesson moves a lesson to a position within another module of the same course,
+// or to a new position within its own module
+func (s *ReorderService) MoveLesson(lessonID string, req *dto.MoveLessonRequest, userID uuid.UUID) (*dto.ReorderResponse, error) {
+	lesson, err := s.loadLesson(lessonID)
+	if err != nil {
+		return nil, err
+	}
+	module, err := s.loadModule(req.ModuleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(lesson.CourseID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.inTransaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Model(&models.Lesson{}).
+			Where("module_id = ? AND id <> ?", module.ID, lesson.ID).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: "order"}}).
+			Pluck("id", &ids).Error; err != nil {
+			return errors.New("failed to fetch lessons: " + err.Error())
+		}
+		return s.placeLessons(tx, module, insertAt(ids, lesson.ID, req.Position))
+	}); err != nil {
+		return nil, err
+	}
+
+	return lessonSiblings.list(s.db, "module", module.ID)
+}
+
+// ReorderTopics sets the order of a lesson's topics. Topics from other lessons of the
+// same course may be listed to move them into this lesson.
+func (s *ReorderService) ReorderTopics(lessonID string, req *dto.ReorderRequest, userID uuid.UUID) (*dto.ReorderResponse, error) {
+	lesson, err := s.loadLesson(lessonID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(lesson.CourseID, userID); err != nil {
+		return nil, err
+	}
+	ids, err := parseIDs(req.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.inTransaction(func(tx *gorm.DB) error {
+		return s.placeTopics(tx, lesson, ids)
+	}); err != nil {
+		return nil, err
+	}
+
+	return topicSiblings.list(s.db, "lesson", lesson.ID)
+}
+
+// MoveTopic moves a topic to a position within another lesson of the same course,
+// or to a new position within its own lesson
+func (s *ReorderService) MoveTopic(topicID string, req *dto.MoveTopicRequest, userID uuid.UUID) (*dto.ReorderResponse, error) {
+	id, err := uuid.Parse(topicID)
+	if err != nil {
+		return nil, errors.New("invalid topic ID")
+	}
+	var topic models.Topics
+	if err := s.db.Select("id, course_id, lesson_id").Where("id = ?", id).First(&topic).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("topic not found")
+		}
+		return nil, errors.New("failed to fetch topic: " + err.Error())
+	}
+	lesson, err := s.loadLesson(req.LessonID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(topic.CourseID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.inTransaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Model(&models.Topics{}).
+			Where("lesson_id = ? AND id <> ?", lesson.ID, topic.ID).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: "order"}}).
+			Pluck("id", &ids).Error; err != nil {
+			return errors.New("failed to fetch topics: " + err.Error())
+		}
+		return s.placeTopics(tx, lesson, insertAt(ids, topic.ID, req.Position))
+	}); err != nil {
+		return nil, err
+	}
+
+	return topicSiblings.list(s.db, "lesson", lesson.ID)
+}
+
+// placeLessons renumbers a module's lessons to match ids, moving in any listed lessons
+// from other modules along with their topics, quiz questions, assignments and duration totals
+func (s *ReorderService) placeLessons(tx *gorm.DB, module *models.Module, ids []uuid.UUID) error {
+	var lessons []models.Lesson
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, course_id, module_id").
+		Where("id IN ? OR module_id = ?", ids, module.ID).
+		Find(&lessons).Error; err != nil {
+		return errors.New("failed to fetch lessons: " + err.Error())
+	}
+
+	listed := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		listed[id] = true
+	}
+	found := make(map[uuid.UUID]bool, len(lessons))
+	var moved []models.Lesson
+	for _, lesson := range lessons {
+		found[lesson.ID] = true
+		if lesson.ModuleID == module.ID {
+			if !listed[lesson.ID] {
+				return errors.New("ids must include every lesson of the module")
+			}
+			continue
+		}
+		if lesson.CourseID != module.CourseID {
+			return errors.New("lessons can only be moved between modules of the same course")
+		}
+		moved = append(moved, lesson)
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return errors.New("lesson " + id.String() + " not found")
+		}
+	}
+
+	if err := lessonSiblings.resequence(tx, module.ID, ids, nil); err != nil {
+		return err
+	}
+
+	sources := map[uuid.UUID]bool{}
+	for _, lesson := range moved {
+		sources[lesson.ModuleID] = true
+		if err := tx.Model(&models.Topics{}).Where("lesson_id = ?", lesson.ID).
+			Update("module_id", module.ID).Error; err != nil {
+			return errors.New("failed to move lesson topics: " + err.Error())
+		}
+		if err := tx.Model(&models.ObjectiveQuestion{}).Where("lesson_id = ?", lesson.ID).
+			Update("module_id", module.ID).Error; err != nil {
+			return errors.New("failed to move lesson questions: " + err.Error())
+		}
+		if err := tx.Model(&models.Assignment{}).
+			Where("topic_id IN (?)", tx.Model(&models.Topics{}).Select("id").Where("lesson_id = ?", lesson.ID)).
+			Update("module_id", module.ID).Error; err != nil {
+			return errors.New("failed to move lesson assignments: " + err.Error())
+		}
+	}
+	affected := []uuid.UUID{module.ID}
+	for sourceID := range sources {
+		if err := lessonSiblings.renumber(tx, sourceID); err != nil {
+			return err
+		}
+		affected = append(affected, sourceID)
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+	return rollupServices.RecomputeModules(tx, affected...)
+}
+
+// placeTopics renumbers a lesson's topics to match ids, moving in any listed topics
+// from other lessons along with their assignments and duration totals
+func (s *ReorderService) placeTopics(tx *gorm.DB, lesson *models.Lesson, ids []uuid.UUID) error {
+	var topics []models.Topics
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, course_id, module_id, lesson_id").
+		Where("id IN ? OR lesson_id = ?", ids, lesson.ID).
+		Find(&topics).Error; err != nil {
+		return errors.New("failed to fetch topics: " + err.Error())
+	}
+
+	listed := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		listed[id] = true
+	}
+	found := make(map[uuid.UUID]bool, len(topics))
+	sources := map[uuid.UUID]bool{}
+	var moved []uuid.UUID
+	modules := []uuid.UUID{lesson.ModuleID}
+	for _, topic := range topics {
+		found[topic.ID] = true
+		if topic.LessonID == lesson.ID {
+			if !listed[topic.ID] {
+				return errors.New("ids must include every topic of the lesson")
+			}
+			continue
+		}
+		if topic.CourseID != lesson.CourseID {
+			return errors.New("topics can only be moved between lessons of the same course")
+		}
+		moved = append(moved, topic.ID)
+		modules = append(modules, topic.ModuleID)
+		if topic.LessonID != uuid.Nil {
+			sources[topic.LessonID] = true
+		}
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return errors.New("topic " + id.String() + " not found")
+		}
+	}
+
+	if err := topicSiblings.resequence(tx, lesson.ID, ids, map[string]interface{}{"module_id": lesson.ModuleID}); err != nil {
+		return err
+	}
+
+	if len(moved) > 0 {
+		if err := tx.Model(&models.Assignment{}).Where("topic_id IN ?", moved).
+			Update("module_id", lesson.ModuleID).Error; err != nil {
+			return errors.New("failed to move topic assignments: " + err.Error())
+		}
+	}
+	for sourceID := range sources {
+		if err := topicSiblings.renumber(tx, sourceID); err != nil {
+			return err
+		}
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+	return rollupServices.RecomputeModules(tx, modules...)
+}
+
+// inTransaction runs fn in a transaction, rolling back on error or panic
+func (s *ReorderService) inTransaction(fn func(tx *gorm.DB) error) error {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to commit transaction: " + err.Error())
+	}
+	return nil
+}
+
+// authorize allows users who may edit the course, such as its own tutor
+func (s *ReorderService) authorize(courseID, userID uuid.UUID) error {
+	var course models.Course
+	if err := s.db.Select("id").Where("id = ?", courseID).First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("course not found")
+		}
+		return errors.New("failed to fetch course: " + err.Error())
+	}
+	allowed, err := permissionServices.NewPermissionService(s.db).Authorize(userID, "courses:write",
+		&dto.PermissionResource{Type: "course", ID: course.ID.String()})
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("not authorized to reorder this course's content")
+	}
+	return nil
+}
+
+func (s *ReorderService) loadModule(moduleID string) (*models.Module, error) {
+	id, err := uuid.Parse(moduleID)
+	if err != nil {
+		return nil, errors.New("invalid module ID")
+	}
+	var module models.Module
+	if err := s.db.Select("id, course_id").Where("id = ?", id).First(&module).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("module not found")
+		}
+		return nil, errors.New("failed to fetch module: " + err.Error())
+	}
+	return &module, nil
+}
+
+func (s *ReorderService) loadLesson(lessonID string) (*models.Lesson, error) {
+	id, err := uuid.Parse(lessonID)
+	if err != nil {
+		return nil, errors.New("invalid lesson ID")
+	}
+	var lesson models.Lesson
+	if err := s.db.Select("id, course_id, module_id").Where("id = ?", id).First(&lesson).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("lesson not found")
+		}
+		return nil, errors.New("failed to fetch lesson: " + err.Error())
+	}
+	return &lesson, nil
+}
+
+// parseIDs parses an ordered ID list, rejecting duplicates
+func parseIDs(raw []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(raw))
+	seen := make(map[uuid.UUID]bool, len(raw))
+	for _, value := range raw {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, errors.New("invalid ID: " + value)
+		}
+		if seen[id] {
+			return nil, errors.New("duplicate ID in list: " + value)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// insertAt places id at a 1-based position, appending when position is 0 or past the end
+func insertAt(ids []uuid.UUID, id uuid.UUID, position int) []uuid.UUID {
+	if position <= 0 || position > len(ids) {
+		return append(ids, id)
+	}
+	result := make([]uuid.UUID, 0, len(ids)+1)
+	result = append(result, ids[:position-1]...)
+	result = append(result, id)
+	return append(result, ids[position-1:]...)
+}
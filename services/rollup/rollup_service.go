@@ -0,0 +1,190 @@
+// services/rollup_service.go
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+// RecomputeModules refreshes the topic count and duration of each module from its topics,
+// then the totals of the courses the modules belong to. Call it inside the transaction that
+// changed the content so the totals commit together with the change.
+func RecomputeModules(tx *gorm.DB, moduleIDs ...uuid.UUID) error {
+	moduleIDs = distinct(moduleIDs)
+	if len(moduleIDs) == 0 {
+		return nil
+	}
+
+	// Module rows are locked first so concurrent changes to the same module roll up one at a time
+	var modules []models.Module
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, course_id").
+		Where("id IN ?", moduleIDs).
+		Order("id").
+		Find(&modules).Error; err != nil {
+		return errors.New("failed to fetch modules: " + err.Error())
+	}
+
+	courseIDs := make([]uuid.UUID, 0, len(modules))
+	for _, module := range modules {
+		if err := recomputeModule(tx, module.ID); err != nil {
+			return err
+		}
+		courseIDs = append(courseIDs, module.CourseID)
+	}
+	return recomputeCourses(tx, courseIDs)
+}
+
+// RecomputeCourses refreshes every module of the given courses and then the course totals.
+// Use it when modules or lessons were added or removed.
+func RecomputeCourses(tx *gorm.DB, courseIDs ...uuid.UUID) error {
+	courseIDs = distinct(courseIDs)
+	if len(courseIDs) == 0 {
+		return nil
+	}
+
+	var modules []models.Module
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("course_id IN ?", courseIDs).
+		Order("id").
+		Find(&modules).Error; err != nil {
+		return errors.New("failed to fetch modules: " + err.Error())
+	}
+	for _, module := range modules {
+		if err := recomputeModule(tx, module.ID); err != nil {
+			return err
+		}
+	}
+	return recomputeCourses(tx, courseIDs)
+}
+
+// ProbeTopic measures a video topic's length and rolls it up into the module and course.
+// It is best effort: failures are logged and the author's estimate stays in use.
+func ProbeTopic(db *gorm.DB, topicID uuid.UUID) {
+	var topic models.Topics
+	if err := db.Select("id, content_type, content_url").Where("id = ?", topicID).First(&topic).Error; err != nil {
+		log.Printf("⚠️ Could not load topic %s for probing: %v", topicID, err)
+		return
+	}
+	if topic.ContentType != "video" {
+		return
+	}
+
+	seconds, err := utils.ProbeVideoDuration(topic.ContentURL)
+	if err != nil {
+		log.Printf("⚠️ Could not probe video for topic %s: %v", topicID, err)
+		return
+	}
+
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// The topic may have been edited while the probe ran; only apply the length to the same video
+	result := tx.Model(&models.Topics{}).
+		Where("id = ? AND content_url = ?", topic.ID, topic.ContentURL).
+		UpdateColumns(map[string]interface{}{
+			"video_duration": seconds,
+			"duration":       models.TopicDuration(0, seconds),
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		log.Printf("⚠️ Could not save video length for topic %s: %v", topicID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return
+	}
+
+	var moduleIDs []uuid.UUID
+	if err := tx.Model(&models.Topics{}).Where("id = ?", topic.ID).Pluck("module_id", &moduleIDs).Error; err != nil {
+		tx.Rollback()
+		log.Printf("⚠️ Could not load module for topic %s: %v", topicID, err)
+		return
+	}
+	if err := RecomputeModules(tx, moduleIDs...); err != nil {
+		tx.Rollback()
+		log.Printf("⚠️ Could not roll up totals for topic %s: %v", topicID, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		log.Printf("⚠️ Could not save video length for topic %s: %v", topicID, err)
+	}
+}
+
+func recomputeModule(tx *gorm.DB, moduleID uuid.UUID) error {
+	var totals struct {
+		Topics   int
+		Duration int
+	}
+	if err := tx.Model(&models.Topics{}).
+		Select("COUNT(*) AS topics, COALESCE(SUM(duration), 0) AS duration").
+		Where("module_id = ?", moduleID).
+		Scan(&totals).Error; err != nil {
+		return errors.New("failed to total module topics: " + err.Error())
+	}
+
+	if err := tx.Model(&models.Module{}).Where("id = ?", moduleID).UpdateColumns(map[string]interface{}{
+		"total_topics":   totals.Topics,
+		"total_duration": totals.Duration,
+	}).Error; err != nil {
+		return errors.New("failed to update module totals: " + err.Error())
+	}
+	return nil
+}
+
+func recomputeCourses(tx *gorm.DB, courseIDs []uuid.UUID) error {
+	for _, courseID := range distinct(courseIDs) {
+		var totals struct {
+			Modules  int
+			Topics   int
+			Duration int
+		}
+		if err := tx.Model(&models.Module{}).
+			Select("COUNT(*) AS modules, COALESCE(SUM(total_topics), 0) AS topics, COALESCE(SUM(total_duration), 0) AS duration").
+			Where("course_id = ?", courseID).
+			Scan(&totals).Error; err != nil {
+			return errors.New("failed to total course modules: " + err.Error())
+		}
+
+		var lessons int64
+		if err := tx.Model(&models.Lesson{}).Where("course_id = ?", courseID).Count(&lessons).Error; err != nil {
+			return errors.New("failed to count course lessons: " + err.Error())
+		}
+
+		if err := tx.Model(&models.Course{}).Where("id = ?", courseID).UpdateColumns(map[string]interface{}{
+			"total_modules":  totals.Modules,
+			"total_lessons":  lessons,
+			"total_topics":   totals.Topics,
+			"total_duration": totals.Duration,
+		}).Error; err != nil {
+			return errors.New("failed to update course totals: " + err.Error())
+		}
+	}
+	return nil
+}
+
+func distinct(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
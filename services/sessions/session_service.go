@@ -0,0 +1,496 @@
+// services/session_service.go
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+type SessionService struct {
+	db *gorm.DB
+}
+
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// IdleCutoff is the last-used time before which a session counts as abandoned. Every
+// authenticated request and refresh moves LastUsedAt forward, so active sessions slide.
+func IdleCutoff(now time.Time) time.Time {
+	return now.Add(-time.Duration(cfg.RefreshTokenHours) * time.Hour)
+}
+
+// StartSession opens a session for a user who has just signed in and issues its first
+// access and refresh tokens
+func (s *SessionService) StartSession(user *models.User, loginType string, client *dto.SessionClient) (*dto.TokenPair, error) {
+	return s.StartVerifiedSession(user, loginType, "", nil, client)
+}
+
+// StartVerifiedSession opens a session after a second factor, recording which method was
+// passed and the trusted device that stood in for it, if any
+func (s *SessionService) StartVerifiedSession(user *models.User, loginType string, mfaMethod string, trustedDeviceID *uuid.UUID, client *dto.SessionClient) (*dto.TokenPair, error) {
+	now := time.Now()
+	session := models.UserSession{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		UserIP:     client.IPAddress,
+		DeviceType: client.DeviceType,
+		DeviceOS:   client.DeviceOS,
+		Browser:    client.Browser,
+		IsActive:   true,
+		LoginType:  loginType,
+		IssuedAt:   now,
+		ExpiresAt:  now.Add(time.Duration(cfg.SessionMaxDays) * 24 * time.Hour),
+		LastUsedAt: now,
+		MFAMethod:  mfaMethod,
+
+		TrustedDeviceID: trustedDeviceID,
+	}
+
+	accessToken, accessExpiresAt, err := utils.GenerateJWT(user.ID.String(), user.Email, string(user.Role), session.ID.String())
+	if err != nil {
+		return nil, errors.New("failed to generate token: " + err.Error())
+	}
+	session.SessionToken = accessToken
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Create(&session).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create session: " + err.Error())
+	}
+
+	refreshToken, refreshExpiresAt, err := s.issueRefreshToken(tx, &session, nil, now)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	return &dto.TokenPair{
+		SessionID:             session.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt,
+		SessionExpiresAt:      session.ExpiresAt,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token. The
+// presented token is spent; presenting it again revokes the whole session, since either
+// the client or an attacker holds a copy.
+func (s *SessionService) Refresh(refreshToken string, client *dto.SessionClient) (*dto.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, errors.New("refresh token is required")
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var token models.RefreshToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hashRefreshToken(refreshToken)).
+		First(&token).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, errors.New("failed to fetch refresh token: " + err.Error())
+	}
+
+	var session models.UserSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", token.SessionID).
+		First(&session).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, errors.New("failed to fetch session: " + err.Error())
+	}
+
+	now := time.Now()
+	if revokeReason, err := checkRefresh(&token, &session, now); err != nil {
+		if revokeReason == "" {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := s.revoke(tx, session.ID, revokeReason, now); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, errors.New("failed to commit transaction: " + err.Error())
+		}
+		if revokeReason == "refresh_token_reuse" {
+			log.Printf("⚠️ Refresh token reuse on session %s for user %s; session revoked", session.ID, session.UserID)
+		}
+		return nil, err
+	}
+
+	// Role or email may have changed since sign-in; the new access token carries current values
+	var user models.User
+	if err := tx.Where("id = ?", session.UserID).First(&user).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+	if !user.IsActive {
+		if err := s.revoke(tx, session.ID, "account_deactivated", now); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, errors.New("failed to commit transaction: " + err.Error())
+		}
+		return nil, errors.New("account has been deactivated")
+	}
+
+	if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to spend refresh token: " + err.Error())
+	}
+
+	accessToken, accessExpiresAt, err := utils.GenerateJWT(user.ID.String(), user.Email, string(user.Role), session.ID.String())
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to generate token: " + err.Error())
+	}
+
+	updates := map[string]interface{}{
+		"session_token": accessToken,
+		"last_used_at":  now,
+	}
+	if client != nil && client.IPAddress != "" {
+		updates["user_ip"] = client.IPAddress
+	}
+	if err := tx.Model(&session).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update session: " + err.Error())
+	}
+
+	newRefreshToken, refreshExpiresAt, err := s.issueRefreshToken(tx, &session, &token.ID, now)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	return &dto.TokenPair{
+		SessionID:             session.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          newRefreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt,
+		SessionExpiresAt:      session.ExpiresAt,
+	}, nil
+}
+
+// RevokeSession signs a session out and voids its refresh tokens. It reports whether the
+// session was still active.
+func (s *SessionService) RevokeSession(sessionID uuid.UUID, reason string) (bool, error) {
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var session models.UserSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", sessionID).
+		First(&session).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, errors.New("failed to fetch session: " + err.Error())
+	}
+	if !session.IsActive {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := s.revoke(tx, session.ID, reason, time.Now()); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return false, errors.New("failed to commit transaction: " + err.Error())
+	}
+	return true, nil
+}
+
+// ListSessions returns a user's signed-in devices, most recently used first. currentID marks
+// the session making the request.
+func (s *SessionService) ListSessions(userID uuid.UUID, currentID uuid.UUID) ([]dto.SessionResponse, error) {
+	now := time.Now()
+	var sessions []models.UserSession
+	if err := s.db.Where("user_id = ? AND is_active = true AND expires_at > ? AND last_used_at > ?",
+		userID, now, IdleCutoff(now)).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, errors.New("failed to fetch sessions: " + err.Error())
+	}
+
+	responses := make([]dto.SessionResponse, 0, len(sessions))
+	for i := range sessions {
+		responses = append(responses, s.toResponse(&sessions[i], currentID))
+	}
+	return responses, nil
+}
+
+// RevokeUserSession signs out one of a user's own sessions
+func (s *SessionService) RevokeUserSession(userID uuid.UUID, sessionID string) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return errors.New("invalid session ID")
+	}
+
+	var count int64
+	if err := s.db.Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND is_active = true", id, userID).
+		Count(&count).Error; err != nil {
+		return errors.New("failed to fetch session: " + err.Error())
+	}
+	if count == 0 {
+		return errors.New("session not found")
+	}
+
+	if _, err := s.RevokeSession(id, "user_revoked"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs a user out everywhere except the session making the request
+func (s *SessionService) RevokeOtherSessions(userID uuid.UUID, currentID uuid.UUID) (*dto.RevokeSessionsResponse, error) {
+	return s.revokeInTransaction(userID, "user_revoked", currentID)
+}
+
+// ForceLogout signs a user out of every session, for administrators responding to a
+// compromised or misused account
+func (s *SessionService) ForceLogout(userID string) (*dto.RevokeSessionsResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+	if count == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	return s.revokeInTransaction(id, "admin_revoked")
+}
+
+// revokeInTransaction runs RevokeUserSessions in its own transaction, so sessions, refresh
+// tokens and trusted devices are revoked together or not at all
+func (s *SessionService) revokeInTransaction(userID uuid.UUID, reason string, except ...uuid.UUID) (*dto.RevokeSessionsResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	revoked, err := RevokeUserSessions(tx, userID, reason, except...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+	return &dto.RevokeSessionsResponse{Revoked: revoked}, nil
+}
+
+// RevokeUserSessions signs a user out of every active session except those listed, and
+// voids their refresh tokens. Pass a transaction to revoke together with the change that
+// calls for it, such as a password change or account removal.
+func RevokeUserSessions(tx *gorm.DB, userID uuid.UUID, reason string, except ...uuid.UUID) (int64, error) {
+	query := tx.Model(&models.UserSession{}).Where("user_id = ? AND is_active = true", userID)
+	if len(except) > 0 {
+		query = query.Where("id NOT IN ?", except)
+	}
+
+	var ids []uuid.UUID
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return 0, errors.New("failed to fetch sessions: " + err.Error())
+	}
+
+	now := time.Now()
+	if len(ids) > 0 {
+		if err := tx.Model(&models.UserSession{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"is_active":      false,
+			"logged_out_at":  now,
+			"revoked_reason": reason,
+		}).Error; err != nil {
+			return 0, errors.New("failed to revoke sessions: " + err.Error())
+		}
+		if err := tx.Model(&models.RefreshToken{}).
+			Where("session_id IN ? AND revoked_at IS NULL", ids).
+			Update("revoked_at", now).Error; err != nil {
+			return 0, errors.New("failed to revoke refresh tokens: " + err.Error())
+		}
+	}
+
+	// Signing out everywhere also deletes every remembered device
+	if len(except) == 0 {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TrustedDevice{}).Error; err != nil {
+			return 0, errors.New("failed to delete trusted devices: " + err.Error())
+		}
+	} else if len(ids) > 0 {
+		if err := forgetTrustedDevices(tx, ids, reason, now); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(ids)), nil
+}
+
+// revoke deactivates a session and every refresh token issued to it
+func (s *SessionService) revoke(tx *gorm.DB, sessionID uuid.UUID, reason string, now time.Time) error {
+	if err := tx.Model(&models.UserSession{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"is_active":      false,
+		"logged_out_at":  now,
+		"revoked_reason": reason,
+	}).Error; err != nil {
+		return errors.New("failed to revoke session: " + err.Error())
+	}
+	if err := tx.Model(&models.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", now).Error; err != nil {
+		return errors.New("failed to revoke refresh tokens: " + err.Error())
+	}
+	return forgetTrustedDevices(tx, []uuid.UUID{sessionID}, reason, now)
+}
+
+// forgetTrustedDevices revokes the trusted devices tied to sessions revoked for cause: the
+// device each session was remembered from and the device it was opened with. A plain logout
+// or expiry keeps the device remembered.
+func forgetTrustedDevices(tx *gorm.DB, sessionIDs []uuid.UUID, reason string, now time.Time) error {
+	if reason == "logout" || reason == "expired" {
+		return nil
+	}
+	if err := tx.Model(&models.TrustedDevice{}).
+		Where("revoked_at IS NULL").
+		Where("session_id IN ? OR id IN (?)", sessionIDs,
+			tx.Model(&models.UserSession{}).Select("trusted_device_id").Where("id IN ? AND trusted_device_id IS NOT NULL", sessionIDs)).
+		Update("revoked_at", now).Error; err != nil {
+		return errors.New("failed to revoke trusted devices: " + err.Error())
+	}
+	return nil
+}
+
+// issueRefreshToken stores the hash of a new opaque refresh token for the session. It lasts
+// for the idle window but never past the session's absolute expiry.
+func (s *SessionService) issueRefreshToken(tx *gorm.DB, session *models.UserSession, parentID *uuid.UUID, now time.Time) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, errors.New("failed to generate refresh token: " + err.Error())
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	expiresAt := now.Add(time.Duration(cfg.RefreshTokenHours) * time.Hour)
+	if expiresAt.After(session.ExpiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
+	record := models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: hashRefreshToken(token),
+		ParentID:  parentID,
+		ExpiresAt: expiresAt,
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", time.Time{}, errors.New("failed to save refresh token: " + err.Error())
+	}
+	return token, expiresAt, nil
+}
+
+func (s *SessionService) toResponse(session *models.UserSession, currentID uuid.UUID) dto.SessionResponse {
+	idleExpiresAt := session.LastUsedAt.Add(time.Duration(cfg.RefreshTokenHours) * time.Hour)
+	if idleExpiresAt.After(session.ExpiresAt) {
+		idleExpiresAt = session.ExpiresAt
+	}
+	return dto.SessionResponse{
+		ID:            session.ID.String(),
+		UserID:        session.UserID.String(),
+		DeviceType:    session.DeviceType,
+		DeviceOS:      session.DeviceOS,
+		Browser:       session.Browser,
+		UserAgent:     session.UserAgent,
+		IPAddress:     session.UserIP,
+		LoginType:     session.LoginType,
+		Current:       session.ID == currentID,
+		IssuedAt:      session.IssuedAt,
+		LastUsedAt:    session.LastUsedAt,
+		ExpiresAt:     session.ExpiresAt,
+		IdleExpiresAt: idleExpiresAt,
+	}
+}
+
+// checkRefresh decides whether a refresh token may be exchanged. When it may not, it also
+// returns the reason to revoke the session with, or "" when the session is already inactive.
+// A token that was already spent or revoked means a copy exists, so its session is revoked.
+func checkRefresh(token *models.RefreshToken, session *models.UserSession, now time.Time) (string, error) {
+	if token.UsedAt != nil || token.RevokedAt != nil {
+		if !session.IsActive {
+			return "", errors.New("refresh token has already been used; session revoked")
+		}
+		return "refresh_token_reuse", errors.New("refresh token has already been used; session revoked")
+	}
+	if !session.IsActive {
+		return "", errors.New("session has been revoked")
+	}
+	if !now.Before(session.ExpiresAt) || !now.Before(token.ExpiresAt) || session.LastUsedAt.Before(IdleCutoff(now)) {
+		return "expired", errors.New("session has expired")
+	}
+	return "", nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
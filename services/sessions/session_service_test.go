@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"crm-go/models"
+)
+
+func TestCheckRefresh(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Minute)
+
+	// fresh returns an unspent token on a live session that was used a moment ago
+	fresh := func() (*models.RefreshToken, *models.UserSession) {
+		return &models.RefreshToken{ExpiresAt: now.Add(time.Hour)},
+			&models.UserSession{IsActive: true, ExpiresAt: now.Add(24 * time.Hour), LastUsedAt: earlier}
+	}
+
+	t.Run("unspent token on a live session", func(t *testing.T) {
+		token, session := fresh()
+		if reason, err := checkRefresh(token, session, now); err != nil || reason != "" {
+			t.Errorf("checkRefresh() = %q, %v, want the token accepted", reason, err)
+		}
+	})
+
+	t.Run("spent token revokes the session", func(t *testing.T) {
+		token, session := fresh()
+		token.UsedAt = &earlier
+		reason, err := checkRefresh(token, session, now)
+		if err == nil || reason != "refresh_token_reuse" {
+			t.Errorf("checkRefresh() = %q, %v, want a refusal that revokes for reuse", reason, err)
+		}
+	})
+
+	t.Run("revoked token revokes the session", func(t *testing.T) {
+		token, session := fresh()
+		token.RevokedAt = &earlier
+		if reason, _ := checkRefresh(token, session, now); reason != "refresh_token_reuse" {
+			t.Errorf("checkRefresh() revoke reason = %q, want refresh_token_reuse", reason)
+		}
+	})
+
+	t.Run("reuse is still refused once the session is gone", func(t *testing.T) {
+		token, session := fresh()
+		token.UsedAt = &earlier
+		session.IsActive = false
+		reason, err := checkRefresh(token, session, now)
+		if err == nil || reason != "" {
+			t.Errorf("checkRefresh() = %q, %v, want a refusal with nothing left to revoke", reason, err)
+		}
+	})
+
+	t.Run("reuse is reported ahead of expiry", func(t *testing.T) {
+		token, session := fresh()
+		token.UsedAt = &earlier
+		token.ExpiresAt = earlier
+		if reason, _ := checkRefresh(token, session, now); reason != "refresh_token_reuse" {
+			t.Errorf("checkRefresh() revoke reason = %q, want refresh_token_reuse", reason)
+		}
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		token, session := fresh()
+		session.IsActive = false
+		reason, err := checkRefresh(token, session, now)
+		if err == nil || err.Error() != "session has been revoked" || reason != "" {
+			t.Errorf("checkRefresh() = %q, %v, want a refusal for the revoked session", reason, err)
+		}
+	})
+
+	expiries := map[string]func(*models.RefreshToken, *models.UserSession){
+		"session past its absolute expiry": func(_ *models.RefreshToken, s *models.UserSession) { s.ExpiresAt = now },
+		"token past its expiry":            func(tok *models.RefreshToken, _ *models.UserSession) { tok.ExpiresAt = earlier },
+		"session idle too long": func(_ *models.RefreshToken, s *models.UserSession) {
+			s.LastUsedAt = IdleCutoff(now).Add(-time.Second)
+		},
+	}
+	for name, expire := range expiries {
+		t.Run(name, func(t *testing.T) {
+			token, session := fresh()
+			expire(token, session)
+			reason, err := checkRefresh(token, session, now)
+			if err == nil || reason != "expired" {
+				t.Errorf("checkRefresh() = %q, %v, want a refusal that revokes as expired", reason, err)
+			}
+		})
+	}
+}
+
+func TestHashRefreshToken(t *testing.T) {
+	a := hashRefreshToken("token-a")
+	if a != hashRefreshToken("token-a") {
+		t.Error("hashRefreshToken() is not stable")
+	}
+	if a == hashRefreshToken("token-b") {
+		t.Error("different tokens hash alike")
+	}
+	if len(a) != 64 || a == "token-a" {
+		t.Errorf("hashRefreshToken() = %q, want a hex SHA-256 digest", a)
+	}
+}
@@ -0,0 +1,166 @@
+// services/signing_key_service.go
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+// retireLeeway keeps a rotated key verifying a little past the last token it signed, to
+// cover clock skew between instances
+const retireLeeway = 5 * time.Minute
+
+type SigningKeyService struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyService(db *gorm.DB) *SigningKeyService {
+	return &SigningKeyService{db: db}
+}
+
+// JWKS returns the public keys tokens may currently be verified with
+func (s *SigningKeyService) JWKS() (utils.JSONWebKeySet, error) {
+	set, err := utils.PublicJWKS()
+	if err != nil {
+		return utils.JSONWebKeySet{}, errors.New("failed to load signing keys: " + err.Error())
+	}
+	return set, nil
+}
+
+// GetKeys lists every signing key, newest first
+func (s *SigningKeyService) GetKeys() ([]dto.SigningKeyResponse, error) {
+	var keys []models.SigningKey
+	if err := s.db.Order("activated_at DESC").Find(&keys).Error; err != nil {
+		return nil, errors.New("failed to fetch signing keys: " + err.Error())
+	}
+
+	responses := make([]dto.SigningKeyResponse, 0, len(keys))
+	for i := range keys {
+		responses = append(responses, *s.toResponse(&keys[i]))
+	}
+	return responses, nil
+}
+
+// RotateKey makes a new key active. The previous key keeps verifying until every token it
+// signed has expired, so users are not signed out by a routine rotation.
+func (s *SigningKeyService) RotateKey(req *dto.RotateSigningKeyRequest, adminID uuid.UUID) (*dto.SigningKeyResponse, error) {
+	algorithm := req.Algorithm
+	if algorithm == "" {
+		algorithm = cfg.JWTAlgorithm
+	}
+
+	key, err := utils.GenerateSigningKey(algorithm, &adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var previous []models.SigningKey
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("status = ?", "active").
+		Find(&previous).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to fetch active signing key: " + err.Error())
+	}
+
+	now := time.Now()
+	retireAt := now.Add(time.Duration(cfg.AccessTokenMinutes)*time.Minute + retireLeeway)
+	for _, old := range previous {
+		if err := tx.Model(&models.SigningKey{}).Where("id = ?", old.ID).Updates(map[string]interface{}{
+			"status":    "retiring",
+			"retire_at": retireAt,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to retire previous signing key: " + err.Error())
+		}
+	}
+
+	key.ActivatedAt = now
+	if err := tx.Create(key).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to save signing key: " + err.Error())
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	if err := utils.ReloadSigningKeys(); err != nil {
+		return nil, errors.New("failed to reload signing keys: " + err.Error())
+	}
+	return s.toResponse(key), nil
+}
+
+// RetireKey withdraws a retiring key straight away, for example when it may have leaked.
+// Tokens it signed stop working; the active key must be rotated out first.
+func (s *SigningKeyService) RetireKey(keyID string) (*dto.SigningKeyResponse, error) {
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return nil, errors.New("invalid signing key ID")
+	}
+
+	var key models.SigningKey
+	if err := s.db.Where("id = ?", id).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("signing key not found")
+		}
+		return nil, errors.New("failed to fetch signing key: " + err.Error())
+	}
+
+	switch key.Status {
+	case "active":
+		return nil, errors.New("rotate to a new key before retiring the active key")
+	case "retired":
+		return nil, errors.New("signing key is already retired")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&key).Updates(map[string]interface{}{
+		"status":     "retired",
+		"retired_at": now,
+	}).Error; err != nil {
+		return nil, errors.New("failed to retire signing key: " + err.Error())
+	}
+	if err := utils.ReloadSigningKeys(); err != nil {
+		return nil, errors.New("failed to reload signing keys: " + err.Error())
+	}
+
+	key.Status, key.RetiredAt = "retired", &now
+	return s.toResponse(&key), nil
+}
+
+func (s *SigningKeyService) toResponse(key *models.SigningKey) *dto.SigningKeyResponse {
+	response := &dto.SigningKeyResponse{
+		ID:          key.ID.String(),
+		KID:         key.KID,
+		Algorithm:   key.Algorithm,
+		Status:      key.Status,
+		PublicKey:   key.PublicKey,
+		Encrypted:   key.Encrypted,
+		ActivatedAt: key.ActivatedAt,
+		RetireAt:    key.RetireAt,
+		RetiredAt:   key.RetiredAt,
+		CreatedAt:   key.CreatedAt,
+	}
+	if key.CreatedBy != nil {
+		createdBy := key.CreatedBy.String()
+		response.CreatedBy = &createdBy
+	}
+	return response
+}
@@ -0,0 +1,808 @@
+// services/sso_login.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/crewjam/saml"
+	"github.com/google/uuid"
+	validator "github.com/mattermost/xml-roundtrip-validator"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+	sessionServices "crm-go/services/sessions"
+	"crm-go/utils"
+)
+
+// metadataRefresh is how long IdP metadata fetched by URL is used before it is fetched again
+const metadataRefresh = time.Hour
+
+// maxMetadataBytes bounds the IdP metadata document read from a URL
+const maxMetadataBytes = 5 << 20
+
+var ssoHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// Discovery documents and IdP metadata are cached per process; admin changes to a provider
+// drop its entries
+var (
+	cacheMu       sync.Mutex
+	oidcProviders = map[string]*oidc.Provider{}    // by issuer
+	idpMetadata   = map[string]cachedIDPMetadata{} // by provider slug
+)
+
+type cachedIDPMetadata struct {
+	descriptor *saml.EntityDescriptor
+	version    time.Time // UpdatedAt of the provider it was loaded for
+	fetchedAt  time.Time
+}
+
+var (
+	spKeyOnce sync.Once
+	spKey     crypto.Signer
+	spCert    *x509.Certificate
+)
+
+// ssoProfile is what an identity provider told us about the person signing in
+type ssoProfile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	Picture       string
+	Claims        map[string]interface{}
+}
+
+// BeginLogin builds the redirect to the identity provider and the signed state for the
+// browser to keep until it comes back
+func (s *SSOService) BeginLogin(ctx context.Context, slug string) (*dto.SSOLoginStart, error) {
+	provider, err := s.findEnabled(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	binding, err := randomValue()
+	if err != nil {
+		return nil, err
+	}
+	claims := utils.SSOStateClaims{Provider: provider.Slug, Binding: hashValue(binding)}
+
+	var redirect string
+	switch provider.Type {
+	case "oidc":
+		oauthConfig, _, err := s.oidcConfig(ctx, provider)
+		if err != nil {
+			return nil, err
+		}
+		if claims.Nonce, err = randomValue(); err != nil {
+			return nil, err
+		}
+		claims.Verifier = oauth2.GenerateVerifier()
+		redirect = oauthConfig.AuthCodeURL(binding, oauth2.S256ChallengeOption(claims.Verifier), oidc.Nonce(claims.Nonce))
+
+	case "saml":
+		sp, err := s.serviceProvider(ctx, provider)
+		if err != nil {
+			return nil, err
+		}
+		idpURL := sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+		if idpURL == "" {
+			return nil, errors.New("IdP metadata has no HTTP-Redirect sign-in endpoint")
+		}
+		request, err := sp.MakeAuthenticationRequest(idpURL, saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+		if err != nil {
+			return nil, errors.New("failed to build SAML request: " + err.Error())
+		}
+		claims.RequestID = request.ID
+		location, err := request.Redirect(binding, sp)
+		if err != nil {
+			return nil, errors.New("failed to build SAML request: " + err.Error())
+		}
+		redirect = location.String()
+
+	default:
+		return nil, errors.New("unsupported provider type")
+	}
+
+	state, err := utils.SignSSOState(claims)
+	if err != nil {
+		return nil, errors.New("failed to sign sign-in state: " + err.Error())
+	}
+	return &dto.SSOLoginStart{RedirectURL: redirect, Cookie: state}, nil
+}
+
+// CompleteOIDC finishes an OpenID Connect sign-in: it redeems the code with the PKCE
+// verifier, verifies the ID token and its nonce, and resolves the account. The bool reports
+// whether the account was created by this sign-in.
+func (s *SSOService) CompleteOIDC(ctx context.Context, slug string, stateCookie string, state string, code string) (*models.User, bool, error) {
+	claims, provider, err := s.checkState(slug, stateCookie, state)
+	if err != nil {
+		return nil, false, err
+	}
+	if provider.Type != "oidc" {
+		return nil, false, errors.New("provider does not use OpenID Connect")
+	}
+	if code == "" {
+		return nil, false, errors.New("authorization code is missing")
+	}
+
+	oauthConfig, oidcProvider, err := s.oidcConfig(ctx, provider)
+	if err != nil {
+		return nil, false, err
+	}
+	ctx = oidc.ClientContext(ctx, ssoHTTPClient)
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(claims.Verifier))
+	if err != nil {
+		log.Printf("❌ %s token exchange failed: %v", provider.Slug, err)
+		return nil, false, errors.New("the identity provider rejected the authorization code")
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, false, errors.New("the identity provider did not return an ID token")
+	}
+	idToken, err := oidcProvider.Verifier(&oidc.Config{ClientID: oauthConfig.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		log.Printf("❌ %s ID token rejected: %v", provider.Slug, err)
+		return nil, false, errors.New("invalid ID token")
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(claims.Nonce)) != 1 {
+		return nil, false, errors.New("invalid ID token nonce")
+	}
+
+	values := map[string]interface{}{}
+	if err := idToken.Claims(&values); err != nil {
+		return nil, false, errors.New("invalid ID token claims")
+	}
+	// Some providers only put the profile in the userinfo response
+	if _, ok := values["email"].(string); !ok && oidcProvider.UserInfoEndpoint() != "" {
+		info, err := oidcProvider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			log.Printf("⚠️ %s userinfo request failed: %v", provider.Slug, err)
+		} else if info.Subject == idToken.Subject {
+			extra := map[string]interface{}{}
+			if err := info.Claims(&extra); err == nil {
+				for key, value := range extra {
+					if _, exists := values[key]; !exists {
+						values[key] = value
+					}
+				}
+			}
+		}
+	}
+
+	profile := &ssoProfile{
+		Subject:       idToken.Subject,
+		Email:         stringClaim(values, "email"),
+		EmailVerified: boolClaim(values["email_verified"]),
+		FirstName:     firstNonEmpty(stringClaim(values, "given_name"), stringClaim(values, "name")),
+		LastName:      stringClaim(values, "family_name"),
+		Picture:       stringClaim(values, "picture"),
+		Claims:        values,
+	}
+	return s.resolveUser(provider, profile)
+}
+
+// CompleteSAML finishes a SAML sign-in from the response the IdP posted to the assertion
+// consumer service. The response must be signed and answer the request this browser started.
+func (s *SSOService) CompleteSAML(ctx context.Context, slug string, stateCookie string, relayState string, samlResponse string) (*models.User, bool, error) {
+	claims, provider, err := s.checkState(slug, stateCookie, relayState)
+	if err != nil {
+		return nil, false, err
+	}
+	if provider.Type != "saml" {
+		return nil, false, errors.New("provider does not use SAML")
+	}
+
+	sp, err := s.serviceProvider(ctx, provider)
+	if err != nil {
+		return nil, false, err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(samlResponse)
+	if err != nil {
+		return nil, false, errors.New("SAMLResponse is not valid base64")
+	}
+	assertion, err := sp.ParseXMLResponse(decoded, []string{claims.RequestID}, sp.AcsURL)
+	if err != nil {
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) {
+			log.Printf("❌ %s SAML response rejected: %v", provider.Slug, invalid.PrivateErr)
+		}
+		return nil, false, errors.New("invalid SAML response")
+	}
+	if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+		return nil, false, errors.New("SAML assertion has no subject")
+	}
+
+	values := map[string]interface{}{}
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			list := make([]interface{}, 0, len(attribute.Values))
+			for _, value := range attribute.Values {
+				list = append(list, strings.TrimSpace(value.Value))
+			}
+			values[attribute.Name] = list
+			if attribute.FriendlyName != "" {
+				values[attribute.FriendlyName] = list
+			}
+		}
+	}
+
+	nameID := assertion.Subject.NameID
+	email := firstClaim(values, "email", "mail", "urn:oid:0.9.2342.19200300.100.1.3",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress")
+	if email == "" && nameID.Format == string(saml.EmailAddressNameIDFormat) {
+		email = nameID.Value
+	}
+	profile := &ssoProfile{
+		Subject: nameID.Value,
+		Email:   email,
+		// SAML has no standard verified-email claim, so the provider setting decides
+		EmailVerified: provider.TrustEmail,
+		FirstName: firstClaim(values, "givenName", "urn:oid:2.5.4.42",
+			"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname", "displayName"),
+		LastName: firstClaim(values, "sn", "surname", "urn:oid:2.5.4.4",
+			"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname"),
+		Claims: values,
+	}
+	return s.resolveUser(provider, profile)
+}
+
+// SPMetadata returns this application's SAML service provider metadata for a provider, for
+// the district's IdP administrator to import
+func (s *SSOService) SPMetadata(slug string) ([]byte, error) {
+	provider, err := s.findEnabled(slug)
+	if err != nil {
+		return nil, err
+	}
+	if provider.Type != "saml" {
+		return nil, errors.New("provider does not use SAML")
+	}
+
+	metadata, err := xml.MarshalIndent(newServiceProvider(provider).Metadata(), "", "  ")
+	if err != nil {
+		return nil, errors.New("failed to build SAML metadata: " + err.Error())
+	}
+	return append([]byte(xml.Header), metadata...), nil
+}
+
+// checkState verifies the signed state cookie and that the value which came back through the
+// identity provider belongs to it
+func (s *SSOService) checkState(slug string, stateCookie string, state string) (*utils.SSOStateClaims, *models.SSOProvider, error) {
+	if stateCookie == "" {
+		return nil, nil, errors.New("sign-in state is missing or expired; start the sign-in again from this browser")
+	}
+	claims, err := utils.ParseSSOState(stateCookie)
+	if err != nil {
+		return nil, nil, errors.New("sign-in state is missing or expired; start the sign-in again from this browser")
+	}
+	if claims.Provider != slug || state == "" ||
+		subtle.ConstantTimeCompare([]byte(hashValue(state)), []byte(claims.Binding)) != 1 {
+		return nil, nil, errors.New("invalid sign-in state")
+	}
+
+	provider, err := s.findEnabled(slug)
+	if err != nil {
+		return nil, nil, err
+	}
+	return claims, provider, nil
+}
+
+// resolveUser finds the account for a verified sign-in: by a previous link to the provider,
+// then by verified email, and otherwise by creating one when the provider allows it
+func (s *SSOService) resolveUser(provider *models.SSOProvider, profile *ssoProfile) (*models.User, bool, error) {
+	if profile.Subject == "" {
+		return nil, false, errors.New("the identity provider did not identify the user")
+	}
+	email := strings.ToLower(strings.TrimSpace(profile.Email))
+	if provider.AllowedDomains != "" && !domainAllowed(provider, email) {
+		return nil, false, errors.New("sign-in with this email domain is not allowed for this provider")
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	var user models.User
+	created := false
+
+	var identity models.SSOIdentity
+	err := tx.Where("provider = ? AND subject = ?", provider.Slug, profile.Subject).First(&identity).Error
+	switch {
+	case err == nil:
+		if err := tx.Where("id = ?", identity.UserID).First(&user).Error; err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, false, errors.New("linked account not found")
+			}
+			return nil, false, errors.New("failed to fetch user: " + err.Error())
+		}
+		if provider.SyncAttributes {
+			if err := s.syncUser(tx, provider, profile, &user); err != nil {
+				tx.Rollback()
+				return nil, false, err
+			}
+		}
+		if err := tx.Model(&identity).Updates(map[string]interface{}{
+			"email":         email,
+			"last_login_at": now,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, false, errors.New("failed to update linked identity: " + err.Error())
+		}
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if email == "" {
+			tx.Rollback()
+			return nil, false, errors.New("the identity provider did not return an email address")
+		}
+
+		err := tx.Where("LOWER(email) = ?", email).First(&user).Error
+		switch {
+		case err == nil:
+			// Only a provider that vouches for the address may take over an existing account
+			if !profile.EmailVerified {
+				tx.Rollback()
+				return nil, false, errors.New("an account with this email already exists, but the identity provider has not verified the address")
+			}
+			if provider.SyncAttributes {
+				if err := s.syncUser(tx, provider, profile, &user); err != nil {
+					tx.Rollback()
+					return nil, false, err
+				}
+			}
+			if !user.IsVerified {
+				if err := tx.Model(&user).Updates(map[string]interface{}{"is_verified": true, "verified_at": now}).Error; err != nil {
+					tx.Rollback()
+					return nil, false, errors.New("failed to verify user: " + err.Error())
+				}
+				user.IsVerified, user.VerifiedAt = true, &now
+			}
+
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			var removed int64
+			if err := tx.Unscoped().Model(&models.User{}).Where("LOWER(email) = ?", email).Count(&removed).Error; err != nil {
+				tx.Rollback()
+				return nil, false, errors.New("failed to check existing accounts: " + err.Error())
+			}
+			if removed > 0 {
+				tx.Rollback()
+				return nil, false, errors.New("the account for this email has been removed; ask an administrator to restore it")
+			}
+			if !provider.AutoProvision {
+				tx.Rollback()
+				return nil, false, errors.New("no account exists for this email; ask an administrator for an invitation")
+			}
+			user = models.User{
+				FirstName:  profile.FirstName,
+				LastName:   profile.LastName,
+				Email:      email,
+				Role:       provider.DefaultRole,
+				Picture:    profile.Picture,
+				Provider:   provider.Slug,
+				IsVerified: profile.EmailVerified,
+			}
+			if profile.EmailVerified {
+				user.VerifiedAt = &now
+			}
+			if role, ok := mappedRole(provider, profile); ok {
+				user.Role = role
+			}
+			if user.DepartmentID, err = s.mappedDepartment(tx, provider, profile); err != nil {
+				tx.Rollback()
+				return nil, false, err
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				tx.Rollback()
+				return nil, false, errors.New("failed to create user: " + err.Error())
+			}
+			created = true
+
+		default:
+			tx.Rollback()
+			return nil, false, errors.New("failed to fetch user: " + err.Error())
+		}
+
+		if err := tx.Create(&models.SSOIdentity{
+			UserID:      user.ID,
+			Provider:    provider.Slug,
+			Subject:     profile.Subject,
+			Email:       email,
+			LastLoginAt: &now,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, false, errors.New("failed to link identity: " + err.Error())
+		}
+
+	default:
+		tx.Rollback()
+		return nil, false, errors.New("failed to fetch linked identity: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, false, errors.New("failed to complete sign-in: " + err.Error())
+	}
+	return &user, created, nil
+}
+
+// syncUser re-applies the provider's claim mappings to an existing account. A configured claim
+// that maps to nothing falls back to the default role, or clears the department. A changed role
+// signs the user out of their existing sessions; the one being created comes after this.
+func (s *SSOService) syncUser(tx *gorm.DB, provider *models.SSOProvider, profile *ssoProfile, user *models.User) error {
+	updates := map[string]interface{}{}
+	if profile.FirstName != "" && profile.FirstName != user.FirstName {
+		updates["first_name"], user.FirstName = profile.FirstName, profile.FirstName
+	}
+	if profile.LastName != "" && profile.LastName != user.LastName {
+		updates["last_name"], user.LastName = profile.LastName, profile.LastName
+	}
+	if provider.RoleClaim != "" {
+		role, ok := mappedRole(provider, profile)
+		if !ok {
+			role = provider.DefaultRole
+		}
+		if role != user.Role {
+			updates["role"], user.Role = role, role
+		}
+	}
+	if provider.DepartmentClaim != "" {
+		departmentID, err := s.mappedDepartment(tx, provider, profile)
+		if err != nil {
+			return err
+		}
+		updates["department_id"], user.DepartmentID = departmentID, departmentID
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+		return errors.New("failed to update user from identity provider: " + err.Error())
+	}
+	if _, changed := updates["role"]; changed {
+		if _, err := sessionServices.RevokeUserSessions(tx, user.ID, "role_change"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mappedRole picks the highest role the role claim maps to
+func mappedRole(provider *models.SSOProvider, profile *ssoProfile) (string, bool) {
+	if provider.RoleClaim == "" {
+		return "", false
+	}
+	mapping := decodeMapping(provider.RoleMapping)
+	granted := map[string]bool{}
+	for _, value := range claimValues(profile.Claims, provider.RoleClaim) {
+		if role, ok := mapping[value]; ok {
+			granted[role] = true
+		}
+	}
+	for _, role := range ssoRoles {
+		if granted[role] {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// mappedDepartment finds the department the first mapped department claim value names
+func (s *SSOService) mappedDepartment(tx *gorm.DB, provider *models.SSOProvider, profile *ssoProfile) (*uuid.UUID, error) {
+	if provider.DepartmentClaim == "" {
+		return nil, nil
+	}
+	mapping := decodeMapping(provider.DepartmentMapping)
+	for _, value := range claimValues(profile.Claims, provider.DepartmentClaim) {
+		code, ok := mapping[value]
+		if !ok {
+			continue
+		}
+		var department models.Department
+		if err := tx.Where("code = ?", code).First(&department).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("⚠️ %s maps '%s' to unknown department '%s'", provider.Slug, value, code)
+				continue
+			}
+			return nil, errors.New("failed to fetch department: " + err.Error())
+		}
+		return &department.ID, nil
+	}
+	return nil, nil
+}
+
+// oidcConfig discovers the issuer and builds the OAuth2 client for a provider
+func (s *SSOService) oidcConfig(ctx context.Context, provider *models.SSOProvider) (*oauth2.Config, *oidc.Provider, error) {
+	cacheMu.Lock()
+	oidcProvider, ok := oidcProviders[provider.Issuer]
+	cacheMu.Unlock()
+	if !ok {
+		// The provider keeps the context for fetching signing keys later, so it must outlive the request
+		discovered, err := oidc.NewProvider(oidc.ClientContext(context.Background(), ssoHTTPClient), provider.Issuer)
+		if err != nil {
+			log.Printf("❌ OIDC discovery for %s failed: %v", provider.Issuer, err)
+			return nil, nil, errors.New("failed to reach the identity provider")
+		}
+		cacheMu.Lock()
+		oidcProviders[provider.Issuer] = discovered
+		cacheMu.Unlock()
+		oidcProvider = discovered
+	}
+
+	secret, err := utils.OpenSSOSecret(provider.ClientSecret, provider.SecretEncrypted)
+	if err != nil {
+		return nil, nil, errors.New("failed to read client secret: " + err.Error())
+	}
+	scopes := []string{oidc.ScopeOpenID, "email", "profile"}
+	scopes = append(scopes, strings.Fields(provider.Scopes)...)
+
+	return &oauth2.Config{
+		ClientID:     provider.ClientID,
+		ClientSecret: secret,
+		Endpoint:     oidcProvider.Endpoint(),
+		RedirectURL:  callbackURL(provider.Slug),
+		Scopes:       scopes,
+	}, oidcProvider, nil
+}
+
+// serviceProvider builds this application's SAML service provider for a provider, with the
+// IdP's metadata loaded
+func (s *SSOService) serviceProvider(ctx context.Context, provider *models.SSOProvider) (*saml.ServiceProvider, error) {
+	descriptor, err := loadIDPMetadata(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	sp := newServiceProvider(provider)
+	sp.IDPMetadata = descriptor
+	return sp, nil
+}
+
+func newServiceProvider(provider *models.SSOProvider) *saml.ServiceProvider {
+	metadata, _ := url.Parse(metadataURL(provider.Slug))
+	acs, _ := url.Parse(acsURL(provider.Slug))
+	sp := &saml.ServiceProvider{
+		EntityID:          metadata.String(),
+		MetadataURL:       *metadata,
+		AcsURL:            *acs,
+		HTTPClient:        ssoHTTPClient,
+		AuthnNameIDFormat: saml.PersistentNameIDFormat, // a stable subject to link the account to
+	}
+	if key, cert := spKeyPair(); key != nil {
+		sp.Key, sp.Certificate = key, cert
+	}
+	return sp
+}
+
+// spKeyPair loads the optional SAML signing and decryption key pair once
+func spKeyPair() (crypto.Signer, *x509.Certificate) {
+	spKeyOnce.Do(func() {
+		if cfg.SAMLCertFile == "" || cfg.SAMLKeyFile == "" {
+			return
+		}
+		pair, err := tls.LoadX509KeyPair(cfg.SAMLCertFile, cfg.SAMLKeyFile)
+		if err != nil {
+			log.Printf("⚠️ SAML service provider key not loaded: %v", err)
+			return
+		}
+		signer, ok := pair.PrivateKey.(crypto.Signer)
+		if !ok {
+			log.Printf("⚠️ SAML service provider key is not a signing key")
+			return
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			log.Printf("⚠️ SAML service provider certificate not loaded: %v", err)
+			return
+		}
+		spKey, spCert = signer, cert
+	})
+	return spKey, spCert
+}
+
+// loadIDPMetadata returns the provider's IdP metadata, fetching it by URL when needed
+func loadIDPMetadata(ctx context.Context, provider *models.SSOProvider) (*saml.EntityDescriptor, error) {
+	cacheMu.Lock()
+	cached, ok := idpMetadata[provider.Slug]
+	cacheMu.Unlock()
+	if ok && cached.version.Equal(provider.UpdatedAt) &&
+		(provider.IDPMetadataXML != "" || time.Since(cached.fetchedAt) < metadataRefresh) {
+		return cached.descriptor, nil
+	}
+
+	data := []byte(provider.IDPMetadataXML)
+	if len(data) == 0 {
+		fetched, err := fetchMetadata(ctx, provider.IDPMetadataURL)
+		if err != nil {
+			log.Printf("❌ Fetching IdP metadata for %s failed: %v", provider.Slug, err)
+			if ok {
+				// An unreachable IdP metadata URL should not stop sign-ins that already worked
+				return cached.descriptor, nil
+			}
+			return nil, errors.New("failed to fetch IdP metadata")
+		}
+		data = fetched
+	}
+
+	descriptor, err := parseIDPMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+	cacheMu.Lock()
+	idpMetadata[provider.Slug] = cachedIDPMetadata{descriptor: descriptor, version: provider.UpdatedAt, fetchedAt: time.Now()}
+	cacheMu.Unlock()
+	return descriptor, nil
+}
+
+func fetchMetadata(ctx context.Context, metadataURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ssoHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata URL returned %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxMetadataBytes))
+}
+
+// parseIDPMetadata reads an EntityDescriptor, or the first IdP in an EntitiesDescriptor as
+// published by federations
+func parseIDPMetadata(data []byte) (*saml.EntityDescriptor, error) {
+	// encoding/xml does not round-trip every document, which signature checks rely on
+	if err := validator.Validate(bytes.NewReader(data)); err != nil {
+		return nil, errors.New("invalid IdP metadata: " + err.Error())
+	}
+
+	entity := &saml.EntityDescriptor{}
+	if err := xml.Unmarshal(data, entity); err == nil {
+		if len(entity.IDPSSODescriptors) == 0 {
+			return nil, errors.New("IdP metadata has no identity provider descriptor")
+		}
+		return entity, nil
+	}
+
+	entities := &saml.EntitiesDescriptor{}
+	if err := xml.Unmarshal(data, entities); err != nil {
+		return nil, errors.New("invalid IdP metadata: " + err.Error())
+	}
+	for i := range entities.EntityDescriptors {
+		if len(entities.EntityDescriptors[i].IDPSSODescriptors) > 0 {
+			return &entities.EntityDescriptors[i], nil
+		}
+	}
+	return nil, errors.New("IdP metadata has no identity provider descriptor")
+}
+
+func forgetProvider(provider *models.SSOProvider) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	delete(idpMetadata, provider.Slug)
+	if provider.Issuer != "" {
+		delete(oidcProviders, provider.Issuer)
+	}
+}
+
+func domainAllowed(provider *models.SSOProvider, email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, allowed := range splitDomains(provider.AllowedDomains) {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// claimValues reads a claim as a list of strings. Names are tried whole first, since SAML
+// attribute names are often URIs, then as a dotted path into nested OIDC claims.
+func claimValues(claims map[string]interface{}, name string) []string {
+	value, ok := claims[name]
+	if !ok && strings.Contains(name, ".") {
+		var current interface{} = claims
+		for _, part := range strings.Split(name, ".") {
+			object, isObject := current.(map[string]interface{})
+			if !isObject {
+				current = nil
+				break
+			}
+			current = object[part]
+		}
+		value = current
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			} else if item != nil {
+				values = append(values, fmt.Sprint(item))
+			}
+		}
+		return values
+	case nil:
+		return nil
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return strings.TrimSpace(value)
+}
+
+func firstClaim(claims map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		for _, value := range claimValues(claims, name) {
+			if value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// boolClaim reads email_verified, which some providers send as a string
+func boolClaim(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	case json.Number:
+		return v.String() == "1"
+	}
+	return false
+}
+
+func randomValue() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate sign-in state: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
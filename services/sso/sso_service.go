@@ -0,0 +1,468 @@
+// services/sso_service.go
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"crm-go/config"
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+// googleSlug is the built-in provider configured from GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET.
+// A registered provider with the same slug replaces it.
+const googleSlug = "google"
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Roles an identity provider may grant, highest first; a user matching several gets the highest
+var ssoRoles = []string{"admin", "staff", "tutor", "student"}
+
+// SSOService manages the identity providers users can sign in with and turns a verified
+// sign-in at one of them into an account here
+type SSOService struct {
+	db *gorm.DB
+}
+
+func NewSSOService(db *gorm.DB) *SSOService {
+	return &SSOService{db: db}
+}
+
+// CreateProvider registers an identity provider (Admin)
+func (s *SSOService) CreateProvider(req *dto.CreateSSOProviderRequest, createdBy uuid.UUID) (*dto.SSOProviderResponse, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !slugPattern.MatchString(slug) {
+		return nil, errors.New("slug may only contain lower-case letters, digits and dashes")
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.SSOProvider{}).Where("slug = ?", slug).Count(&existing).Error; err != nil {
+		return nil, errors.New("failed to check provider slug: " + err.Error())
+	}
+	if existing > 0 {
+		return nil, errors.New("a provider with this slug already exists")
+	}
+
+	provider := &models.SSOProvider{
+		ID:              uuid.New(),
+		Slug:            slug,
+		Name:            strings.TrimSpace(req.Name),
+		Type:            req.Type,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+		Issuer:          strings.TrimRight(strings.TrimSpace(req.Issuer), "/"),
+		ClientID:        strings.TrimSpace(req.ClientID),
+		Scopes:          strings.TrimSpace(req.Scopes),
+		IDPMetadataURL:  strings.TrimSpace(req.IDPMetadataURL),
+		IDPMetadataXML:  strings.TrimSpace(req.IDPMetadataXML),
+		AllowedDomains:  joinDomains(req.AllowedDomains),
+		AutoProvision:   req.AutoProvision,
+		TrustEmail:      req.TrustEmail,
+		RoleClaim:       strings.TrimSpace(req.RoleClaim),
+		DefaultRole:     firstNonEmpty(req.DefaultRole, "student"),
+		DepartmentClaim: strings.TrimSpace(req.DepartmentClaim),
+		SyncAttributes:  req.SyncAttributes,
+		CreatedBy:       createdBy,
+	}
+	if err := s.setSecret(provider, req.ClientSecret); err != nil {
+		return nil, err
+	}
+	if err := s.setMappings(provider, req.RoleMapping, req.DepartmentMapping); err != nil {
+		return nil, err
+	}
+	if err := validateProvider(provider); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(provider).Error; err != nil {
+		return nil, errors.New("failed to create provider: " + err.Error())
+	}
+	return toProviderResponse(provider), nil
+}
+
+// GetProviders lists the registered identity providers (Admin)
+func (s *SSOService) GetProviders() ([]dto.SSOProviderResponse, error) {
+	var providers []models.SSOProvider
+	if err := s.db.Order("name ASC").Find(&providers).Error; err != nil {
+		return nil, errors.New("failed to fetch providers: " + err.Error())
+	}
+
+	responses := make([]dto.SSOProviderResponse, 0, len(providers))
+	for i := range providers {
+		responses = append(responses, *toProviderResponse(&providers[i]))
+	}
+	return responses, nil
+}
+
+// GetProvider fetches one identity provider (Admin)
+func (s *SSOService) GetProvider(id string) (*dto.SSOProviderResponse, error) {
+	provider, err := s.findByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toProviderResponse(provider), nil
+}
+
+// UpdateProvider changes an identity provider. The slug is part of the URLs registered at the
+// provider, so it cannot change (Admin).
+func (s *SSOService) UpdateProvider(id string, req *dto.UpdateSSOProviderRequest) (*dto.SSOProviderResponse, error) {
+	provider, err := s.findByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		provider.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Enabled != nil {
+		provider.Enabled = *req.Enabled
+	}
+	if req.Issuer != nil {
+		provider.Issuer = strings.TrimRight(strings.TrimSpace(*req.Issuer), "/")
+	}
+	if req.ClientID != nil {
+		provider.ClientID = strings.TrimSpace(*req.ClientID)
+	}
+	if req.ClientSecret != nil {
+		if err := s.setSecret(provider, *req.ClientSecret); err != nil {
+			return nil, err
+		}
+	}
+	if req.Scopes != nil {
+		provider.Scopes = strings.TrimSpace(*req.Scopes)
+	}
+	if req.IDPMetadataURL != nil {
+		provider.IDPMetadataURL = strings.TrimSpace(*req.IDPMetadataURL)
+	}
+	if req.IDPMetadataXML != nil {
+		provider.IDPMetadataXML = strings.TrimSpace(*req.IDPMetadataXML)
+	}
+	if req.AllowedDomains != nil {
+		provider.AllowedDomains = joinDomains(req.AllowedDomains)
+	}
+	if req.AutoProvision != nil {
+		provider.AutoProvision = *req.AutoProvision
+	}
+	if req.TrustEmail != nil {
+		provider.TrustEmail = *req.TrustEmail
+	}
+	if req.RoleClaim != nil {
+		provider.RoleClaim = strings.TrimSpace(*req.RoleClaim)
+	}
+	if req.DefaultRole != nil {
+		provider.DefaultRole = *req.DefaultRole
+	}
+	if req.DepartmentClaim != nil {
+		provider.DepartmentClaim = strings.TrimSpace(*req.DepartmentClaim)
+	}
+	if req.SyncAttributes != nil {
+		provider.SyncAttributes = *req.SyncAttributes
+	}
+	if err := s.setMappings(provider, req.RoleMapping, req.DepartmentMapping); err != nil {
+		return nil, err
+	}
+	if err := validateProvider(provider); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Save(provider).Error; err != nil {
+		return nil, errors.New("failed to update provider: " + err.Error())
+	}
+	forgetProvider(provider)
+	return toProviderResponse(provider), nil
+}
+
+// DeleteProvider removes an identity provider and the links users had to it. Users keep their
+// accounts and can still sign in any other way they have (Admin).
+func (s *SSOService) DeleteProvider(id string) error {
+	provider, err := s.findByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("provider = ?", provider.Slug).Delete(&models.SSOIdentity{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to unlink users: " + err.Error())
+	}
+	if err := tx.Delete(provider).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete provider: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to delete provider: " + err.Error())
+	}
+	forgetProvider(provider)
+	return nil
+}
+
+// ListEnabledProviders lists the providers the sign-in page should offer, including the
+// built-in Google provider when it is configured
+func (s *SSOService) ListEnabledProviders() ([]dto.SSOProviderSummary, error) {
+	var providers []models.SSOProvider
+	if err := s.db.Where("enabled = ?", true).Order("name ASC").Find(&providers).Error; err != nil {
+		return nil, errors.New("failed to fetch providers: " + err.Error())
+	}
+
+	summaries := make([]dto.SSOProviderSummary, 0, len(providers)+1)
+	hasGoogle := false
+	for _, provider := range providers {
+		hasGoogle = hasGoogle || provider.Slug == googleSlug
+		summaries = append(summaries, dto.SSOProviderSummary{
+			Slug:     provider.Slug,
+			Name:     provider.Name,
+			Type:     provider.Type,
+			LoginURL: loginURL(provider.Slug),
+		})
+	}
+	if !hasGoogle && builtinGoogle() != nil {
+		// Google is offered first, as it was before other providers existed
+		summaries = append([]dto.SSOProviderSummary{{
+			Slug:     googleSlug,
+			Name:     "Google",
+			Type:     "oidc",
+			LoginURL: loginURL(googleSlug),
+		}}, summaries...)
+	}
+	return summaries, nil
+}
+
+// findEnabled looks up the provider a sign-in is for, falling back to the built-in Google one
+func (s *SSOService) findEnabled(slug string) (*models.SSOProvider, error) {
+	var provider models.SSOProvider
+	err := s.db.Where("slug = ?", slug).First(&provider).Error
+	if err == nil {
+		if !provider.Enabled {
+			return nil, errors.New("sign-in with this provider is disabled")
+		}
+		return &provider, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to fetch provider: " + err.Error())
+	}
+	if slug == googleSlug {
+		if google := builtinGoogle(); google != nil {
+			return google, nil
+		}
+	}
+	return nil, errors.New("sign-in provider not found")
+}
+
+func (s *SSOService) findByID(id string) (*models.SSOProvider, error) {
+	providerID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid provider ID")
+	}
+
+	var provider models.SSOProvider
+	if err := s.db.Where("id = ?", providerID).First(&provider).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("provider not found")
+		}
+		return nil, errors.New("failed to fetch provider: " + err.Error())
+	}
+	return &provider, nil
+}
+
+func (s *SSOService) setSecret(provider *models.SSOProvider, secret string) error {
+	sealed, encrypted, err := utils.SealSSOSecret(strings.TrimSpace(secret))
+	if err != nil {
+		return err
+	}
+	provider.ClientSecret, provider.SecretEncrypted = sealed, encrypted
+	return nil
+}
+
+// setMappings validates and stores the claim mappings; a nil map leaves the stored one alone
+func (s *SSOService) setMappings(provider *models.SSOProvider, roles map[string]string, departments map[string]string) error {
+	if roles != nil {
+		for value, role := range roles {
+			if !isSSORole(role) {
+				return errors.New("role mapping for '" + value + "' must be admin, staff, tutor or student")
+			}
+		}
+		encoded, err := json.Marshal(roles)
+		if err != nil {
+			return errors.New("failed to encode role mapping: " + err.Error())
+		}
+		provider.RoleMapping = datatypes.JSON(encoded)
+	}
+
+	if departments != nil {
+		for value, code := range departments {
+			var count int64
+			if err := s.db.Model(&models.Department{}).Where("code = ?", code).Count(&count).Error; err != nil {
+				return errors.New("failed to check department mapping: " + err.Error())
+			}
+			if count == 0 {
+				return errors.New("department '" + code + "' mapped from '" + value + "' not found")
+			}
+		}
+		encoded, err := json.Marshal(departments)
+		if err != nil {
+			return errors.New("failed to encode department mapping: " + err.Error())
+		}
+		provider.DepartmentMapping = datatypes.JSON(encoded)
+	}
+	return nil
+}
+
+func validateProvider(provider *models.SSOProvider) error {
+	if provider.Name == "" {
+		return errors.New("name is required")
+	}
+	if !isSSORole(provider.DefaultRole) {
+		return errors.New("default role must be admin, staff, tutor or student")
+	}
+	switch provider.Type {
+	case "oidc":
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return errors.New("OIDC providers need an issuer and a client ID")
+		}
+	case "saml":
+		if provider.IDPMetadataURL == "" && provider.IDPMetadataXML == "" {
+			return errors.New("SAML providers need IdP metadata, by URL or as XML")
+		}
+		if provider.IDPMetadataXML != "" {
+			if _, err := parseIDPMetadata([]byte(provider.IDPMetadataXML)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// builtinGoogle describes the provider configured from the environment, or nil when it is not
+func builtinGoogle() *models.SSOProvider {
+	if cfg.GoogleClientID == "" || cfg.GoogleClientSecret == "" {
+		return nil
+	}
+	return &models.SSOProvider{
+		Slug:          googleSlug,
+		Name:          "Google",
+		Type:          "oidc",
+		Enabled:       true,
+		Issuer:        "https://accounts.google.com",
+		ClientID:      cfg.GoogleClientID,
+		ClientSecret:  cfg.GoogleClientSecret,
+		AutoProvision: true,
+		DefaultRole:   "student",
+	}
+}
+
+func toProviderResponse(provider *models.SSOProvider) *dto.SSOProviderResponse {
+	response := &dto.SSOProviderResponse{
+		ID:                provider.ID.String(),
+		Slug:              provider.Slug,
+		Name:              provider.Name,
+		Type:              provider.Type,
+		Enabled:           provider.Enabled,
+		Issuer:            provider.Issuer,
+		ClientID:          provider.ClientID,
+		HasClientSecret:   provider.ClientSecret != "",
+		Scopes:            provider.Scopes,
+		IDPMetadataURL:    provider.IDPMetadataURL,
+		HasIDPMetadataXML: provider.IDPMetadataXML != "",
+		AllowedDomains:    splitDomains(provider.AllowedDomains),
+		AutoProvision:     provider.AutoProvision,
+		TrustEmail:        provider.TrustEmail,
+		RoleClaim:         provider.RoleClaim,
+		RoleMapping:       decodeMapping(provider.RoleMapping),
+		DefaultRole:       provider.DefaultRole,
+		DepartmentClaim:   provider.DepartmentClaim,
+		DepartmentMapping: decodeMapping(provider.DepartmentMapping),
+		SyncAttributes:    provider.SyncAttributes,
+		LoginURL:          loginURL(provider.Slug),
+		CreatedAt:         provider.CreatedAt,
+		UpdatedAt:         provider.UpdatedAt,
+	}
+	if provider.Type == "saml" {
+		response.EntityID = metadataURL(provider.Slug)
+		response.ACSURL = acsURL(provider.Slug)
+	} else {
+		response.CallbackURL = callbackURL(provider.Slug)
+	}
+	return response
+}
+
+func loginURL(slug string) string {
+	return cfg.AppURL + "/auth/sso/" + slug + "/login"
+}
+
+func callbackURL(slug string) string {
+	if slug == googleSlug && cfg.GoogleRedirectURL != "" {
+		// Keeps the redirect URI already registered with Google working
+		return cfg.GoogleRedirectURL
+	}
+	return cfg.AppURL + "/auth/sso/" + slug + "/callback"
+}
+
+func metadataURL(slug string) string {
+	return cfg.AppURL + "/auth/sso/" + slug + "/metadata"
+}
+
+func acsURL(slug string) string {
+	return cfg.AppURL + "/auth/sso/" + slug + "/acs"
+}
+
+func isSSORole(role string) bool {
+	for _, r := range ssoRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeMapping(raw datatypes.JSON) map[string]string {
+	mapping := map[string]string{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &mapping)
+	}
+	return mapping
+}
+
+func joinDomains(domains []string) string {
+	cleaned := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain != "" {
+			cleaned = append(cleaned, domain)
+		}
+	}
+	return strings.Join(cleaned, ",")
+}
+
+func splitDomains(domains string) []string {
+	if domains == "" {
+		return []string{}
+	}
+	return strings.Split(domains, ",")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
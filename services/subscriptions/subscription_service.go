@@ -0,0 +1,1025 @@
+// services/subscription_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"crm-go/dto"
+	"crm-go/models"
+	"crm-go/services/activity"
+	"crm-go/services/payments"
+	"crm-go/utils"
+)
+
+// liveStatuses are the subscription states that still bill and may grant access
+var liveStatuses = []string{"trialing", "active", "past_due"}
+
+type SubscriptionService struct {
+	db       *gorm.DB
+	activity *activity.Service
+}
+
+func NewSubscriptionService(db *gorm.DB, activitySvc *activity.Service) *SubscriptionService {
+	return &SubscriptionService{
+		db:       db,
+		activity: activitySvc,
+	}
+}
+
+// CreatePlan creates a subscription plan and its bundle
+func (s *SubscriptionService) CreatePlan(req *dto.CreatePlanRequest) (*dto.PlanResponse, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	var existing models.SubscriptionPlan
+	if err := s.db.Where("slug = ?", slug).First(&existing).Error; err == nil {
+		return nil, errors.New("plan with slug '" + slug + "' already exists")
+	}
+
+	items, err := s.buildPlanItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = "USD"
+	}
+
+	now := time.Now()
+	plan := &models.SubscriptionPlan{
+		ID:               uuid.New(),
+		Name:             strings.TrimSpace(req.Name),
+		Slug:             slug,
+		Description:      req.Description,
+		BillingInterval:  req.BillingInterval,
+		Price:            req.Price,
+		Currency:         currency,
+		TrialDays:        req.TrialDays,
+		GracePeriodDays:  3,
+		MaxRetryAttempts: 3,
+		Status:           "active",
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		Items:            items,
+	}
+	if req.GracePeriodDays != nil {
+		plan.GracePeriodDays = *req.GracePeriodDays
+	}
+	if req.MaxRetryAttempts != nil {
+		plan.MaxRetryAttempts = *req.MaxRetryAttempts
+	}
+
+	if err := s.db.Create(plan).Error; err != nil {
+		return nil, errors.New("failed to create plan: " + err.Error())
+	}
+
+	return s.GetPlanByID(plan.ID.String())
+}
+
+// GetPlans lists subscription plans
+func (s *SubscriptionService) GetPlans(params *dto.PlanQueryParams) ([]dto.PlanResponse, error) {
+	query := s.db.Preload("Items")
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	var plans []models.SubscriptionPlan
+	if err := query.Order("price ASC, name ASC").Find(&plans).Error; err != nil {
+		return nil, errors.New("failed to fetch plans: " + err.Error())
+	}
+
+	responses := make([]dto.PlanResponse, 0, len(plans))
+	for i := range plans {
+		responses = append(responses, *s.toPlanResponse(&plans[i]))
+	}
+	return responses, nil
+}
+
+// GetPlanByID retrieves a subscription plan
+func (s *SubscriptionService) GetPlanByID(id string) (*dto.PlanResponse, error) {
+	plan, err := s.loadPlan(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPlanResponse(plan), nil
+}
+
+// UpdatePlan updates a plan; existing subscribers pick up price changes at their next renewal
+func (s *SubscriptionService) UpdatePlan(id string, req *dto.UpdatePlanRequest) (*dto.PlanResponse, error) {
+	plan, err := s.loadPlan(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if req.Name != "" {
+		updates["name"] = strings.TrimSpace(req.Name)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Price != nil {
+		updates["price"] = *req.Price
+	}
+	if req.TrialDays != nil {
+		updates["trial_days"] = *req.TrialDays
+	}
+	if req.GracePeriodDays != nil {
+		updates["grace_period_days"] = *req.GracePeriodDays
+	}
+	if req.MaxRetryAttempts != nil {
+		updates["max_retry_attempts"] = *req.MaxRetryAttempts
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+
+	var items []models.SubscriptionPlanItem
+	if len(req.Items) > 0 {
+		if items, err = s.buildPlanItems(req.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Model(&models.SubscriptionPlan{}).Where("id = ?", plan.ID).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update plan: " + err.Error())
+	}
+
+	if len(items) > 0 {
+		if err := tx.Where("plan_id = ?", plan.ID).Delete(&models.SubscriptionPlanItem{}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to replace plan items: " + err.Error())
+		}
+		for i := range items {
+			items[i].PlanID = plan.ID
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to replace plan items: " + err.Error())
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to update plan: " + err.Error())
+	}
+
+	return s.GetPlanByID(plan.ID.String())
+}
+
+// DeletePlan soft deletes a plan nobody is subscribed to
+func (s *SubscriptionService) DeletePlan(id string) error {
+	plan, err := s.loadPlan(s.db, id)
+	if err != nil {
+		return err
+	}
+
+	var live int64
+	if err := s.db.Model(&models.Subscription{}).Where("plan_id = ? AND status IN ?", plan.ID, liveStatuses).Count(&live).Error; err != nil {
+		return errors.New("failed to check subscriptions: " + err.Error())
+	}
+	if live > 0 {
+		return fmt.Errorf("cannot delete plan: it has %d live subscriptions, deactivate it instead", live)
+	}
+
+	if err := s.db.Delete(plan).Error; err != nil {
+		return errors.New("failed to delete plan: " + err.Error())
+	}
+	return nil
+}
+
+// Subscribe starts a subscription: a trial on first use of a plan with trial days,
+// otherwise a paid period backed by a completed checkout or an immediate charge
+func (s *SubscriptionService) Subscribe(actorID uuid.UUID, canManage bool, req *dto.SubscribeRequest) (*dto.SubscriptionResponse, error) {
+	userID := actorID
+	if req.UserID != "" {
+		if !canManage {
+			return nil, errors.New("not authorized to subscribe another user")
+		}
+		parsed, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return nil, errors.New("invalid user ID")
+		}
+		userID = parsed
+	}
+	if strings.TrimSpace(req.GatewayReference) != "" && !canManage {
+		return nil, errors.New("not authorized to record an external payment reference")
+	}
+
+	plan, err := s.loadPlan(s.db, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Status != "active" {
+		return nil, errors.New("plan is not available")
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+
+	var live int64
+	if err := s.db.Model(&models.Subscription{}).
+		Where("user_id = ? AND plan_id = ? AND status IN ?", userID, plan.ID, liveStatuses).
+		Count(&live).Error; err != nil {
+		return nil, errors.New("failed to check subscriptions: " + err.Error())
+	}
+	if live > 0 {
+		return nil, errors.New("subscription to this plan already exists")
+	}
+
+	var previous int64
+	if err := s.db.Model(&models.Subscription{}).Where("user_id = ? AND plan_id = ?", userID, plan.ID).Count(&previous).Error; err != nil {
+		return nil, errors.New("failed to check subscriptions: " + err.Error())
+	}
+
+	gateway := req.Gateway
+	if gateway == "" {
+		gateway = "manual"
+	}
+
+	now := time.Now()
+	subscription := &models.Subscription{
+		ID:                   uuid.New(),
+		UserID:               userID,
+		PlanID:               plan.ID,
+		CurrentPeriodStart:   now,
+		Gateway:              gateway,
+		PaymentMethod:        req.PaymentMethod,
+		GatewayCustomerID:    strings.TrimSpace(req.GatewayCustomerID),
+		GatewayPaymentMethod: strings.TrimSpace(req.GatewayPaymentMethod),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	var payment *models.Payment
+	switch {
+	case plan.TrialDays > 0 && previous == 0:
+		// One trial per plan per user
+		trialEnd := now.AddDate(0, 0, plan.TrialDays)
+		subscription.Status = "trialing"
+		subscription.TrialEndsAt = &trialEnd
+		subscription.CurrentPeriodEnd = trialEnd
+	case plan.Price == 0:
+		subscription.Status = "active"
+		subscription.CurrentPeriodEnd = addInterval(now, plan.BillingInterval)
+	default:
+		if req.PaymentMethod == "" {
+			return nil, errors.New("payment_method is required for this plan")
+		}
+		payment = s.newPayment(subscription, plan, &user, "completed", now)
+		if reference := strings.TrimSpace(req.GatewayReference); reference != "" {
+			payment.GatewayReference = reference
+		} else {
+			result, err := s.charge(subscription, plan, &user, payment)
+			if err != nil {
+				return nil, errors.New("payment failed: " + err.Error())
+			}
+			payment.GatewayReference = result.Reference
+		}
+		subscription.Status = "active"
+		subscription.CurrentPeriodEnd = addInterval(now, plan.BillingInterval)
+		subscription.LastPaymentID = &payment.ID
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Omit("User", "Plan").Create(subscription).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create subscription: " + err.Error())
+	}
+	if payment != nil {
+		if err := tx.Omit("Payer", "Course").Create(payment).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to record payment: " + err.Error())
+		}
+	}
+	if err := s.activity.Subscriptions.Started(tx, actorID, *subscription); err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to log subscription: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to create subscription: " + err.Error())
+	}
+
+	return s.getSubscription(subscription.ID)
+}
+
+// GetSubscriptions lists subscriptions with pagination and filters
+func (s *SubscriptionService) GetSubscriptions(params *dto.SubscriptionQueryParams) (*dto.SubscriptionListResponse, error) {
+	// Set defaults
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	query := s.db.Model(&models.Subscription{})
+	if params.UserID != "" {
+		query = query.Where("user_id = ?", params.UserID)
+	}
+	if params.PlanID != "" {
+		query = query.Where("plan_id = ?", params.PlanID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("failed to count subscriptions: " + err.Error())
+	}
+
+	var subscriptions []models.Subscription
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Preload("User").Preload("Plan", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Plan.Items").Order("created_at DESC").Offset(offset).Limit(params.Limit).Find(&subscriptions).Error; err != nil {
+		return nil, errors.New("failed to fetch subscriptions: " + err.Error())
+	}
+
+	responses := make([]dto.SubscriptionResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		responses = append(responses, *s.toSubscriptionResponse(&subscriptions[i]))
+	}
+
+	totalPages := int(total) / params.Limit
+	if int(total)%params.Limit > 0 {
+		totalPages++
+	}
+
+	return &dto.SubscriptionListResponse{
+		Subscriptions: responses,
+		Total:         total,
+		Page:          params.Page,
+		Limit:         params.Limit,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+// GetSubscriptionByID retrieves a subscription; non-admins may only see their own
+func (s *SubscriptionService) GetSubscriptionByID(id string, userID uuid.UUID, canManage bool) (*dto.SubscriptionResponse, error) {
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid subscription ID")
+	}
+
+	subscription, err := s.getSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage && subscription.UserID != userID.String() {
+		return nil, errors.New("not authorized to view this subscription")
+	}
+	return subscription, nil
+}
+
+// CancelSubscription stops renewal; access continues until the period ends unless an admin ends it now
+func (s *SubscriptionService) CancelSubscription(id string, userID uuid.UUID, canManage bool, req *dto.CancelSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	subscription, err := s.lockSubscription(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !canManage && subscription.UserID != userID {
+		tx.Rollback()
+		return nil, errors.New("not authorized to cancel this subscription")
+	}
+	if !isLive(subscription.Status) {
+		tx.Rollback()
+		return nil, errors.New("subscription has already ended")
+	}
+	if req.Immediately && !canManage {
+		tx.Rollback()
+		return nil, errors.New("not authorized to end a subscription immediately")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"cancel_at_period_end": true,
+		"cancelled_at":         now,
+		"updated_at":           now,
+	}
+	// A past-due subscription has nothing left to run out, so it ends straight away
+	if req.Immediately || subscription.Status == "past_due" {
+		updates["status"] = "cancelled"
+		updates["ended_at"] = now
+		updates["next_retry_at"] = nil
+		subscription.Status = "cancelled"
+		if err := s.activity.Subscriptions.Ended(tx, userID, *subscription, "cancelled"); err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to log subscription end: " + err.Error())
+		}
+	}
+
+	if err := tx.Model(&models.Subscription{}).Where("id = ?", subscription.ID).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to cancel subscription: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to cancel subscription: " + err.Error())
+	}
+
+	return s.getSubscription(subscription.ID)
+}
+
+// ResumeSubscription undoes a pending cancellation before the period ends
+func (s *SubscriptionService) ResumeSubscription(id string, userID uuid.UUID, canManage bool) (*dto.SubscriptionResponse, error) {
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid subscription ID")
+	}
+
+	var subscription models.Subscription
+	if err := s.db.Where("id = ?", subscriptionID).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("subscription not found")
+		}
+		return nil, errors.New("failed to fetch subscription: " + err.Error())
+	}
+	if !canManage && subscription.UserID != userID {
+		return nil, errors.New("not authorized to resume this subscription")
+	}
+	if !isLive(subscription.Status) || !subscription.CancelAtPeriodEnd {
+		return nil, errors.New("subscription is not pending cancellation")
+	}
+
+	if err := s.db.Model(&models.Subscription{}).Where("id = ?", subscription.ID).Updates(map[string]interface{}{
+		"cancel_at_period_end": false,
+		"cancelled_at":         nil,
+		"updated_at":           time.Now(),
+	}).Error; err != nil {
+		return nil, errors.New("failed to resume subscription: " + err.Error())
+	}
+
+	return s.getSubscription(subscription.ID)
+}
+
+// RecordPayment settles a renewal collected outside the automatic charge and starts the next period
+func (s *SubscriptionService) RecordPayment(id string, req *dto.SubscriptionPaymentRequest) (*dto.SubscriptionResponse, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	subscription, err := s.lockSubscription(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !isLive(subscription.Status) {
+		tx.Rollback()
+		return nil, errors.New("subscription has already ended")
+	}
+
+	now := time.Now()
+	payment := s.newPayment(subscription, &subscription.Plan, &subscription.User, "completed", now)
+	payment.PaymentMethod = req.PaymentMethod
+	if req.Gateway != "" {
+		payment.Gateway = req.Gateway
+	}
+	payment.GatewayReference = strings.TrimSpace(req.GatewayReference)
+	if err := tx.Omit("Payer", "Course").Create(payment).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to record payment: " + err.Error())
+	}
+
+	if err := s.advancePeriod(tx, subscription, payment, now); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to record payment: " + err.Error())
+	}
+
+	return s.getSubscription(subscription.ID)
+}
+
+// ProcessRenewals renews subscriptions whose period has ended, retries failed payments
+// and ends subscriptions that were cancelled or ran out of retries or grace
+func (s *SubscriptionService) ProcessRenewals(now time.Time) (*dto.RenewalRunResponse, error) {
+	var due []models.Subscription
+	if err := s.db.Where(
+		"(status IN ? AND current_period_end <= ?) OR (status = ? AND (next_retry_at <= ? OR grace_ends_at <= ?))",
+		[]string{"trialing", "active"}, now, "past_due", now, now,
+	).Find(&due).Error; err != nil {
+		return nil, errors.New("failed to fetch due subscriptions: " + err.Error())
+	}
+
+	result := &dto.RenewalRunResponse{}
+	for _, candidate := range due {
+		outcome, err := s.processRenewal(candidate.ID, now)
+		if err != nil {
+			log.Printf("subscription renewal %s: %v", candidate.ID, err)
+			continue
+		}
+		switch outcome {
+		case "renewed":
+			result.Renewed++
+		case "failed":
+			result.Failed++
+		case "ended":
+			result.Ended++
+		case "expired":
+			result.Expired++
+		}
+	}
+	return result, nil
+}
+
+// StartRenewalWorker runs ProcessRenewals on an interval in the background
+func (s *SubscriptionService) StartRenewalWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := s.ProcessRenewals(time.Now()); err != nil {
+				log.Printf("subscription renewals: %v", err)
+			}
+		}
+	}()
+}
+
+// processRenewal handles one due subscription under a row lock so concurrent runs cannot double charge
+func (s *SubscriptionService) processRenewal(id uuid.UUID, now time.Time) (string, error) {
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	subscription, err := s.lockSubscription(tx, id.String())
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	plan := &subscription.Plan
+
+	var outcome string
+	switch {
+	case isLive(subscription.Status) && subscription.Status != "past_due" && subscription.CurrentPeriodEnd.After(now):
+		// Renewed since it was selected
+		tx.Rollback()
+		return "", nil
+
+	case subscription.CancelAtPeriodEnd:
+		outcome = "ended"
+		if err := s.endSubscription(tx, subscription, "cancelled", "cancelled at period end", now); err != nil {
+			tx.Rollback()
+			return "", err
+		}
+
+	case subscription.Status == "past_due" && (subscription.FailedPaymentCount > plan.MaxRetryAttempts ||
+		(subscription.GraceEndsAt != nil && !subscription.GraceEndsAt.After(now))):
+		outcome = "expired"
+		if err := s.endSubscription(tx, subscription, "expired", "payment retries exhausted", now); err != nil {
+			tx.Rollback()
+			return "", err
+		}
+		go s.notify(subscription.User, plan, "expired", nil)
+
+	case subscription.Status == "past_due" && subscription.NextRetryAt != nil && subscription.NextRetryAt.After(now):
+		// Not yet time to retry
+		tx.Rollback()
+		return "", nil
+
+	case plan.Price == 0:
+		if err := s.advancePeriod(tx, subscription, nil, now); err != nil {
+			tx.Rollback()
+			return "", err
+		}
+		outcome = "renewed"
+
+	default:
+		payment := s.newPayment(subscription, plan, &subscription.User, "completed", now)
+		result, chargeErr := s.charge(subscription, plan, &subscription.User, payment)
+		if chargeErr == nil {
+			payment.GatewayReference = result.Reference
+			if err := tx.Omit("Payer", "Course").Create(payment).Error; err != nil {
+				tx.Rollback()
+				return "", errors.New("failed to record payment: " + err.Error())
+			}
+			if err := s.advancePeriod(tx, subscription, payment, now); err != nil {
+				tx.Rollback()
+				return "", err
+			}
+			outcome = "renewed"
+			break
+		}
+
+		// Dunning: record the failure, enter or stay in grace, and schedule a retry
+		payment.Status = "failed"
+		payment.ProcessedAt = nil
+		payment.FailureReason = truncate(chargeErr.Error(), 255)
+		if err := tx.Omit("Payer", "Course").Create(payment).Error; err != nil {
+			tx.Rollback()
+			return "", errors.New("failed to record payment: " + err.Error())
+		}
+
+		failures := subscription.FailedPaymentCount + 1
+		nextRetry := now.Add(time.Duration(failures) * 24 * time.Hour)
+		updates := map[string]interface{}{
+			"status":               "past_due",
+			"failed_payment_count": failures,
+			"next_retry_at":        nextRetry,
+			"updated_at":           now,
+		}
+		if subscription.GraceEndsAt == nil {
+			graceEnd := subscription.CurrentPeriodEnd.AddDate(0, 0, plan.GracePeriodDays)
+			updates["grace_ends_at"] = graceEnd
+			subscription.GraceEndsAt = &graceEnd
+		}
+		if err := tx.Model(&models.Subscription{}).Where("id = ?", subscription.ID).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			return "", errors.New("failed to update subscription: " + err.Error())
+		}
+		outcome = "failed"
+		go s.notify(subscription.User, plan, "payment_failed", subscription.GraceEndsAt)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return "", errors.New("failed to process renewal: " + err.Error())
+	}
+	return outcome, nil
+}
+
+// advancePeriod starts the next billing period after a successful payment (nil for free plans)
+func (s *SubscriptionService) advancePeriod(tx *gorm.DB, subscription *models.Subscription, payment *models.Payment, now time.Time) error {
+	// Periods run back to back; a subscription that lapsed past its period restarts from today
+	start := subscription.CurrentPeriodEnd
+	if subscription.Status != "past_due" && start.Before(now.AddDate(0, 0, -1)) {
+		start = now
+	}
+	end := addInterval(start, subscription.Plan.BillingInterval)
+
+	updates := map[string]interface{}{
+		"status":               "active",
+		"current_period_start": start,
+		"current_period_end":   end,
+		"failed_payment_count": 0,
+		"next_retry_at":        nil,
+		"grace_ends_at":        nil,
+		"updated_at":           now,
+	}
+	if payment != nil {
+		updates["last_payment_id"] = payment.ID
+	}
+	if err := tx.Model(&models.Subscription{}).Where("id = ?", subscription.ID).Updates(updates).Error; err != nil {
+		return errors.New("failed to renew subscription: " + err.Error())
+	}
+	return nil
+}
+
+// endSubscription closes a subscription and logs it
+func (s *SubscriptionService) endSubscription(tx *gorm.DB, subscription *models.Subscription, status, reason string, now time.Time) error {
+	if err := tx.Model(&models.Subscription{}).Where("id = ?", subscription.ID).Updates(map[string]interface{}{
+		"status":        status,
+		"ended_at":      now,
+		"next_retry_at": nil,
+		"updated_at":    now,
+	}).Error; err != nil {
+		return errors.New("failed to end subscription: " + err.Error())
+	}
+
+	subscription.Status = status
+	if err := s.activity.Subscriptions.Ended(tx, subscription.UserID, *subscription, reason); err != nil {
+		return errors.New("failed to log subscription end: " + err.Error())
+	}
+	return nil
+}
+
+// charge takes a renewal or first payment through the subscription's gateway
+func (s *SubscriptionService) charge(subscription *models.Subscription, plan *models.SubscriptionPlan, user *models.User, payment *models.Payment) (*payments.ChargeResult, error) {
+	provider, err := payments.ProviderFor(subscription.Gateway)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	defer cancel()
+	return provider.Charge(ctx, payments.ChargeRequest{
+		Reference:     payment.PaymentID,
+		CustomerID:    subscription.GatewayCustomerID,
+		PaymentMethod: subscription.GatewayPaymentMethod,
+		Email:         user.Email,
+		Amount:        plan.Price,
+		Currency:      plan.Currency,
+		Description:   plan.Name + " subscription",
+	})
+}
+
+// newPayment builds a payment row for a subscription charge
+func (s *SubscriptionService) newPayment(subscription *models.Subscription, plan *models.SubscriptionPlan, user *models.User, status string, now time.Time) *models.Payment {
+	method := subscription.PaymentMethod
+	if method == "" {
+		method = "credit_card"
+	}
+	return &models.Payment{
+		ID:             uuid.New(),
+		PaymentID:      "SUB-" + now.Format("20060102") + "-" + strings.ToUpper(uuid.New().String()[:8]),
+		PayerID:        subscription.UserID,
+		PayerEmail:     user.Email,
+		PayerName:      strings.TrimSpace(user.FirstName + " " + user.LastName),
+		PayerPhone:     user.Phone,
+		Amount:         plan.Price,
+		Currency:       plan.Currency,
+		PaymentMethod:  method,
+		Gateway:        subscription.Gateway,
+		Status:         status,
+		InitiatedAt:    now,
+		ProcessedAt:    &now,
+		SubscriptionID: &subscription.ID,
+		CourseName:     plan.Name,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// notify emails the subscriber about a failed renewal or an expired subscription
+func (s *SubscriptionService) notify(user models.User, plan *models.SubscriptionPlan, event string, graceEndsAt *time.Time) {
+	if user.Email == "" {
+		return
+	}
+
+	var subject, body string
+	switch event {
+	case "payment_failed":
+		subject = "Action needed: your " + plan.Name + " payment failed"
+		body = fmt.Sprintf("<p>Hello %s,</p><p>We could not collect %.2f %s for your <strong>%s</strong> subscription.</p>",
+			user.FirstName, plan.Price, plan.Currency, plan.Name)
+		if graceEndsAt != nil {
+			body += fmt.Sprintf("<p>Your access continues until <strong>%s</strong> while we retry. Please update your payment details to avoid interruption.</p>",
+				graceEndsAt.Format("2 January 2006"))
+		}
+	case "expired":
+		subject = "Your " + plan.Name + " subscription has ended"
+		body = fmt.Sprintf("<p>Hello %s,</p><p>We were unable to renew your <strong>%s</strong> subscription, so it has ended. You can subscribe again at any time.</p>",
+			user.FirstName, plan.Name)
+	default:
+		return
+	}
+
+	if err := utils.SendEmail(user.Email, subject, body); err != nil {
+		log.Printf("subscription email to %s failed: %v", user.Email, err)
+	}
+}
+
+// buildPlanItems validates bundled products and categories
+func (s *SubscriptionService) buildPlanItems(items []dto.PlanItemRequest) ([]models.SubscriptionPlanItem, error) {
+	seen := make(map[string]bool, len(items))
+	result := make([]models.SubscriptionPlanItem, 0, len(items))
+	for _, item := range items {
+		itemID, err := uuid.Parse(item.ItemID)
+		if err != nil {
+			return nil, errors.New("invalid " + item.ItemType + " ID: " + item.ItemID)
+		}
+		key := item.ItemType + ":" + itemID.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var count int64
+		switch item.ItemType {
+		case "product":
+			err = s.db.Model(&models.Product{}).Where("id = ?", itemID).Count(&count).Error
+		case "category":
+			err = s.db.Model(&models.Category{}).Where("id = ?", itemID).Count(&count).Error
+		}
+		if err != nil {
+			return nil, errors.New("failed to verify plan items: " + err.Error())
+		}
+		if count == 0 {
+			return nil, errors.New(item.ItemType + " " + itemID.String() + " not found")
+		}
+
+		result = append(result, models.SubscriptionPlanItem{
+			ID:        uuid.New(),
+			ItemType:  item.ItemType,
+			ItemID:    itemID,
+			CreatedAt: time.Now(),
+		})
+	}
+	return result, nil
+}
+
+// loadPlan fetches a plan with its bundle
+func (s *SubscriptionService) loadPlan(db *gorm.DB, id string) (*models.SubscriptionPlan, error) {
+	planID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid plan ID")
+	}
+
+	var plan models.SubscriptionPlan
+	if err := db.Preload("Items").Where("id = ?", planID).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("plan not found")
+		}
+		return nil, errors.New("failed to fetch plan: " + err.Error())
+	}
+	return &plan, nil
+}
+
+// lockSubscription fetches a subscription for update along with its user and plan
+func (s *SubscriptionService) lockSubscription(tx *gorm.DB, id string) (*models.Subscription, error) {
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid subscription ID")
+	}
+
+	var subscription models.Subscription
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("User").
+		Preload("Plan", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Where("id = ?", subscriptionID).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("subscription not found")
+		}
+		return nil, errors.New("failed to fetch subscription: " + err.Error())
+	}
+	return &subscription, nil
+}
+
+// getSubscription fetches a subscription for display
+func (s *SubscriptionService) getSubscription(id uuid.UUID) (*dto.SubscriptionResponse, error) {
+	var subscription models.Subscription
+	if err := s.db.Preload("User").Preload("Plan", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Plan.Items").Where("id = ?", id).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("subscription not found")
+		}
+		return nil, errors.New("failed to fetch subscription: " + err.Error())
+	}
+	return s.toSubscriptionResponse(&subscription), nil
+}
+
+// HasAccess reports whether a subscription currently grants access to its bundle
+func HasAccess(subscription *models.Subscription, now time.Time) bool {
+	switch subscription.Status {
+	case "trialing", "active":
+		return subscription.CurrentPeriodEnd.After(now)
+	case "past_due":
+		return subscription.GraceEndsAt != nil && subscription.GraceEndsAt.After(now)
+	default:
+		return false
+	}
+}
+
+func isLive(status string) bool {
+	for _, live := range liveStatuses {
+		if status == live {
+			return true
+		}
+	}
+	return false
+}
+
+// addInterval moves a time forward by one billing interval
+func addInterval(from time.Time, interval string) time.Time {
+	if interval == "yearly" {
+		return from.AddDate(1, 0, 0)
+	}
+	return from.AddDate(0, 1, 0)
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
+
+// toPlanResponse converts model to response DTO
+func (s *SubscriptionService) toPlanResponse(plan *models.SubscriptionPlan) *dto.PlanResponse {
+	response := &dto.PlanResponse{
+		ID:               plan.ID.String(),
+		Name:             plan.Name,
+		Slug:             plan.Slug,
+		Description:      plan.Description,
+		BillingInterval:  plan.BillingInterval,
+		Price:            plan.Price,
+		Currency:         plan.Currency,
+		TrialDays:        plan.TrialDays,
+		GracePeriodDays:  plan.GracePeriodDays,
+		MaxRetryAttempts: plan.MaxRetryAttempts,
+		Status:           plan.Status,
+		Items:            make([]dto.PlanItemResponse, 0, len(plan.Items)),
+		CreatedAt:        plan.CreatedAt,
+		UpdatedAt:        plan.UpdatedAt,
+	}
+
+	var productIDs, categoryIDs []uuid.UUID
+	for _, item := range plan.Items {
+		if item.ItemType == "product" {
+			productIDs = append(productIDs, item.ItemID)
+		} else {
+			categoryIDs = append(categoryIDs, item.ItemID)
+		}
+	}
+	names := make(map[uuid.UUID]string, len(plan.Items))
+	if len(productIDs) > 0 {
+		var products []models.Product
+		s.db.Select("id, name").Where("id IN ?", productIDs).Find(&products)
+		for _, product := range products {
+			names[product.ID] = product.Name
+		}
+	}
+	if len(categoryIDs) > 0 {
+		var categories []models.Category
+		s.db.Select("id, name").Where("id IN ?", categoryIDs).Find(&categories)
+		for _, category := range categories {
+			names[category.ID] = category.Name
+		}
+	}
+
+	for _, item := range plan.Items {
+		response.Items = append(response.Items, dto.PlanItemResponse{
+			ItemType: item.ItemType,
+			ItemID:   item.ItemID.String(),
+			Name:     names[item.ItemID],
+		})
+	}
+	return response
+}
+
+// toSubscriptionResponse converts model to response DTO
+func (s *SubscriptionService) toSubscriptionResponse(subscription *models.Subscription) *dto.SubscriptionResponse {
+	response := &dto.SubscriptionResponse{
+		ID:                 subscription.ID.String(),
+		UserID:             subscription.UserID.String(),
+		PlanID:             subscription.PlanID.String(),
+		Status:             subscription.Status,
+		HasAccess:          HasAccess(subscription, time.Now()),
+		CurrentPeriodStart: subscription.CurrentPeriodStart,
+		CurrentPeriodEnd:   subscription.CurrentPeriodEnd,
+		TrialEndsAt:        subscription.TrialEndsAt,
+		GraceEndsAt:        subscription.GraceEndsAt,
+		CancelAtPeriodEnd:  subscription.CancelAtPeriodEnd,
+		CancelledAt:        subscription.CancelledAt,
+		EndedAt:            subscription.EndedAt,
+		FailedPaymentCount: subscription.FailedPaymentCount,
+		NextRetryAt:        subscription.NextRetryAt,
+		Gateway:            subscription.Gateway,
+		PaymentMethod:      subscription.PaymentMethod,
+		CreatedAt:          subscription.CreatedAt,
+		UpdatedAt:          subscription.UpdatedAt,
+	}
+	if subscription.User.ID != uuid.Nil {
+		response.UserName = strings.TrimSpace(subscription.User.FirstName + " " + subscription.User.LastName)
+	}
+	if subscription.Plan.ID != uuid.Nil {
+		response.Plan = s.toPlanResponse(&subscription.Plan)
+	}
+	return response
+}
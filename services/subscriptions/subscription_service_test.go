@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"crm-go/models"
+)
+
+func TestHasAccess(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	cases := map[string]struct {
+		subscription models.Subscription
+		want         bool
+	}{
+		"active in period":             {models.Subscription{Status: "active", CurrentPeriodEnd: later}, true},
+		"active after period":          {models.Subscription{Status: "active", CurrentPeriodEnd: earlier}, false},
+		"period ends now":              {models.Subscription{Status: "active", CurrentPeriodEnd: now}, false},
+		"trialing in trial":            {models.Subscription{Status: "trialing", CurrentPeriodEnd: later}, true},
+		"past due in grace":            {models.Subscription{Status: "past_due", CurrentPeriodEnd: earlier, GraceEndsAt: &later}, true},
+		"past due after grace":         {models.Subscription{Status: "past_due", CurrentPeriodEnd: earlier, GraceEndsAt: &earlier}, false},
+		"past due without grace":       {models.Subscription{Status: "past_due", CurrentPeriodEnd: later}, false},
+		"cancelled before period ends": {models.Subscription{Status: "cancelled", CurrentPeriodEnd: later}, false},
+		"expired":                      {models.Subscription{Status: "expired", CurrentPeriodEnd: later, GraceEndsAt: &later}, false},
+	}
+
+	for name, c := range cases {
+		if got := HasAccess(&c.subscription, now); got != c.want {
+			t.Errorf("%s: HasAccess() = %v, want %v", name, got, c.want)
+		}
+	}
+}
+
+func TestAddInterval(t *testing.T) {
+	start := time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC)
+
+	if got, want := addInterval(start, "monthly"), time.Date(2026, 2, 15, 9, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("monthly: got %v, want %v", got, want)
+	}
+	if got, want := addInterval(start, "yearly"), time.Date(2027, 1, 15, 9, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("yearly: got %v, want %v", got, want)
+	}
+	// Anything that is not yearly bills monthly
+	if got, want := addInterval(start, ""), time.Date(2026, 2, 15, 9, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("unset interval: got %v, want %v", got, want)
+	}
+}
+
+func TestIsLive(t *testing.T) {
+	for _, status := range []string{"trialing", "active", "past_due"} {
+		if !isLive(status) {
+			t.Errorf("isLive(%q) = false, want true", status)
+		}
+	}
+	for _, status := range []string{"cancelled", "expired", ""} {
+		if isLive(status) {
+			t.Errorf("isLive(%q) = true, want false", status)
+		}
+	}
+}
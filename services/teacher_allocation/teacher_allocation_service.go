@@ -0,0 +1,476 @@
+// services/teacher_allocation_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+)
+
+// teachingRoles are the user roles that may be allocated to teach
+var teachingRoles = []string{"tutor", "admin"}
+
+type TeacherAllocationService struct {
+	db *gorm.DB
+}
+
+func NewTeacherAllocationService(db *gorm.DB) *TeacherAllocationService {
+	return &TeacherAllocationService{db: db}
+}
+
+// CreateAllocation allocates a teacher to teach a subject to an arm
+func (s *TeacherAllocationService) CreateAllocation(req *dto.CreateTeacherAllocationRequest, userID uuid.UUID) (*dto.TeacherAllocationResponse, error) {
+	teacherID, err := uuid.Parse(req.TeacherID)
+	if err != nil {
+		return nil, errors.New("invalid teacher ID")
+	}
+	subjectID, err := uuid.Parse(req.SubjectID)
+	if err != nil {
+		return nil, errors.New("invalid subject ID")
+	}
+	armID, err := uuid.Parse(req.ArmID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+
+	if err := s.verifyTeacher(teacherID); err != nil {
+		return nil, err
+	}
+
+	// Check if arm exists
+	var arm models.Arm
+	if err := s.db.Preload("Grade").Where("id = ? AND deleted_at IS NULL", armID).First(&arm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("arm not found")
+		}
+		return nil, errors.New("failed to verify arm: " + err.Error())
+	}
+
+	// Check if subject exists
+	var subject models.Subject
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", subjectID).First(&subject).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("subject not found")
+		}
+		return nil, errors.New("failed to verify subject: " + err.Error())
+	}
+
+	// Subject must be offered in the arm's grade
+	var offered int64
+	if err := s.db.Model(&models.GradeSubject{}).
+		Where("grade_id = ? AND subject_id = ? AND status = ? AND deleted_at IS NULL", arm.GradeID, subjectID, "active").
+		Count(&offered).Error; err != nil {
+		return nil, errors.New("failed to verify grade subject: " + err.Error())
+	}
+	if offered == 0 {
+		return nil, errors.New("subject is not offered in this arm's grade")
+	}
+
+	// One teacher per subject per arm per session
+	var existing models.TeacherAllocation
+	if err := s.db.Where("subject_id = ? AND arm_id = ? AND academic_session_id = ?", subjectID, armID, arm.Grade.AcademicSessionID).
+		First(&existing).Error; err == nil {
+		return nil, errors.New("allocation for this subject and arm already exists")
+	}
+
+	periods := req.PeriodsPerWeek
+	if periods == 0 {
+		periods = subject.Credits
+	}
+	if periods < 1 {
+		periods = 1
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+
+	allocation := &models.TeacherAllocation{
+		ID:                uuid.New(),
+		TeacherID:         teacherID,
+		SubjectID:         subjectID,
+		ArmID:             armID,
+		AcademicSessionID: arm.Grade.AcademicSessionID,
+		PeriodsPerWeek:    periods,
+		Status:            status,
+		CreatedBy:         userID,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	if err := s.db.Create(allocation).Error; err != nil {
+		return nil, errors.New("failed to create allocation: " + err.Error())
+	}
+
+	return s.GetAllocationByID(allocation.ID.String())
+}
+
+// GetAllAllocations retrieves teacher allocations with pagination and filters
+func (s *TeacherAllocationService) GetAllAllocations(params *dto.TeacherAllocationQueryParams) (*dto.TeacherAllocationListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	query := s.db.Model(&models.TeacherAllocation{}).Where("deleted_at IS NULL")
+
+	if params.TeacherID != "" {
+		if id, err := uuid.Parse(params.TeacherID); err == nil {
+			query = query.Where("teacher_id = ?", id)
+		}
+	}
+	if params.SubjectID != "" {
+		if id, err := uuid.Parse(params.SubjectID); err == nil {
+			query = query.Where("subject_id = ?", id)
+		}
+	}
+	if params.ArmID != "" {
+		if id, err := uuid.Parse(params.ArmID); err == nil {
+			query = query.Where("arm_id = ?", id)
+		}
+	}
+	if params.AcademicSessionID != "" {
+		if id, err := uuid.Parse(params.AcademicSessionID); err == nil {
+			query = query.Where("academic_session_id = ?", id)
+		}
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count allocations: %w", err)
+	}
+
+	var allocations []models.TeacherAllocation
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Preload("Teacher").Preload("Subject").Preload("Arm").
+		Order("created_at DESC").Offset(offset).Limit(params.Limit).
+		Find(&allocations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+
+	responses := make([]dto.TeacherAllocationResponse, len(allocations))
+	for i := range allocations {
+		responses[i] = *s.toAllocationResponse(&allocations[i])
+	}
+
+	return &dto.TeacherAllocationListResponse{
+		Allocations: responses,
+		Total:       total,
+		Page:        params.Page,
+		Limit:       params.Limit,
+		TotalPages:  int((total + int64(params.Limit) - 1) / int64(params.Limit)),
+	}, nil
+}
+
+// GetAllocationByID retrieves a single teacher allocation
+func (s *TeacherAllocationService) GetAllocationByID(id string) (*dto.TeacherAllocationResponse, error) {
+	allocationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid allocation ID")
+	}
+
+	var allocation models.TeacherAllocation
+	if err := s.db.Preload("Teacher").Preload("Subject").Preload("Arm").
+		Where("id = ? AND deleted_at IS NULL", allocationID).
+		First(&allocation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("allocation not found")
+		}
+		return nil, errors.New("failed to fetch allocation: " + err.Error())
+	}
+
+	return s.toAllocationResponse(&allocation), nil
+}
+
+// UpdateAllocation changes the teacher, weekly periods or status of an allocation
+func (s *TeacherAllocationService) UpdateAllocation(id string, req *dto.UpdateTeacherAllocationRequest) (*dto.TeacherAllocationResponse, error) {
+	allocationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid allocation ID")
+	}
+
+	var allocation models.TeacherAllocation
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", allocationID).First(&allocation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("allocation not found")
+		}
+		return nil, errors.New("failed to fetch allocation: " + err.Error())
+	}
+
+	if req.TeacherID != "" {
+		teacherID, err := uuid.Parse(req.TeacherID)
+		if err != nil {
+			return nil, errors.New("invalid teacher ID")
+		}
+		if err := s.verifyTeacher(teacherID); err != nil {
+			return nil, err
+		}
+		allocation.TeacherID = teacherID
+	}
+	if req.PeriodsPerWeek > 0 {
+		allocation.PeriodsPerWeek = req.PeriodsPerWeek
+	}
+	if req.Status != "" {
+		allocation.Status = req.Status
+	}
+	allocation.UpdatedAt = time.Now()
+
+	if err := s.db.Save(&allocation).Error; err != nil {
+		return nil, errors.New("failed to update allocation: " + err.Error())
+	}
+
+	return s.GetAllocationByID(allocation.ID.String())
+}
+
+// DeleteAllocation soft deletes an allocation and its timetable periods
+func (s *TeacherAllocationService) DeleteAllocation(id string) error {
+	allocationID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.New("invalid allocation ID")
+	}
+
+	var allocation models.TeacherAllocation
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", allocationID).First(&allocation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("allocation not found")
+		}
+		return errors.New("failed to fetch allocation: " + err.Error())
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("allocation_id = ?", allocation.ID).Delete(&models.TimetableSlot{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to clear timetable periods: " + err.Error())
+	}
+	if err := tx.Delete(&allocation).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete allocation: " + err.Error())
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to delete allocation: " + err.Error())
+	}
+
+	return nil
+}
+
+// GetWorkloads summarises the teaching load of every allocated teacher in a session
+func (s *TeacherAllocationService) GetWorkloads(params *dto.WorkloadQueryParams) ([]dto.TeacherWorkloadResponse, error) {
+	sessionID, err := s.resolveSession(params.AcademicSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations []models.TeacherAllocation
+	if err := s.db.Preload("Teacher").Preload("Subject").Preload("Arm").
+		Where("academic_session_id = ? AND status = ? AND deleted_at IS NULL", sessionID, "active").
+		Find(&allocations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+
+	scheduled, err := s.scheduledPeriods(sessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make(map[uuid.UUID][]models.TeacherAllocation)
+	for _, a := range allocations {
+		grouped[a.TeacherID] = append(grouped[a.TeacherID], a)
+	}
+
+	workloads := make([]dto.TeacherWorkloadResponse, 0, len(grouped))
+	for teacherID, list := range grouped {
+		workload := s.buildWorkload(teacherID, sessionID, list, scheduled[teacherID], false)
+		workloads = append(workloads, *workload)
+	}
+
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].TotalPeriods != workloads[j].TotalPeriods {
+			return workloads[i].TotalPeriods > workloads[j].TotalPeriods
+		}
+		return workloads[i].TeacherName < workloads[j].TeacherName
+	})
+
+	return workloads, nil
+}
+
+// GetTeacherWorkload summarises the teaching load of one teacher in a session
+func (s *TeacherAllocationService) GetTeacherWorkload(teacherID string, params *dto.WorkloadQueryParams) (*dto.TeacherWorkloadResponse, error) {
+	tID, err := uuid.Parse(teacherID)
+	if err != nil {
+		return nil, errors.New("invalid teacher ID")
+	}
+
+	sessionID, err := s.resolveSession(params.AcademicSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var teacher models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", tID).First(&teacher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("teacher not found")
+		}
+		return nil, errors.New("failed to verify teacher: " + err.Error())
+	}
+
+	var allocations []models.TeacherAllocation
+	if err := s.db.Preload("Subject").Preload("Arm").
+		Where("teacher_id = ? AND academic_session_id = ? AND status = ? AND deleted_at IS NULL", tID, sessionID, "active").
+		Find(&allocations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+	for i := range allocations {
+		allocations[i].Teacher = teacher
+	}
+
+	scheduled, err := s.scheduledPeriods(sessionID, &tID)
+	if err != nil {
+		return nil, err
+	}
+
+	workload := s.buildWorkload(tID, sessionID, allocations, scheduled[tID], true)
+	workload.TeacherName = strings.TrimSpace(teacher.FirstName + " " + teacher.LastName)
+	return workload, nil
+}
+
+// buildWorkload aggregates a teacher's allocations into a workload summary
+func (s *TeacherAllocationService) buildWorkload(teacherID, sessionID uuid.UUID, allocations []models.TeacherAllocation, scheduled int, withDetails bool) *dto.TeacherWorkloadResponse {
+	workload := &dto.TeacherWorkloadResponse{
+		TeacherID:         teacherID.String(),
+		AcademicSessionID: sessionID.String(),
+		TotalAllocations:  len(allocations),
+		ScheduledPeriods:  scheduled,
+	}
+
+	subjects := make(map[uuid.UUID]bool)
+	arms := make(map[uuid.UUID]bool)
+	for i := range allocations {
+		a := &allocations[i]
+		workload.TotalPeriods += a.PeriodsPerWeek
+		subjects[a.SubjectID] = true
+		arms[a.ArmID] = true
+		if workload.TeacherName == "" && a.Teacher.ID != uuid.Nil {
+			workload.TeacherName = strings.TrimSpace(a.Teacher.FirstName + " " + a.Teacher.LastName)
+		}
+		if withDetails {
+			workload.Allocations = append(workload.Allocations, *s.toAllocationResponse(a))
+		}
+	}
+	workload.SubjectCount = len(subjects)
+	workload.ArmCount = len(arms)
+
+	return workload
+}
+
+// scheduledPeriods counts timetabled periods per teacher in a session
+func (s *TeacherAllocationService) scheduledPeriods(sessionID uuid.UUID, teacherID *uuid.UUID) (map[uuid.UUID]int, error) {
+	query := s.db.Model(&models.TimetableSlot{}).
+		Select("teacher_id, COUNT(*) AS total").
+		Where("academic_session_id = ?", sessionID)
+	if teacherID != nil {
+		query = query.Where("teacher_id = ?", *teacherID)
+	}
+
+	var rows []struct {
+		TeacherID uuid.UUID
+		Total     int
+	}
+	if err := query.Group("teacher_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count scheduled periods: %w", err)
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.TeacherID] = row.Total
+	}
+	return counts, nil
+}
+
+// resolveSession parses the given session ID or falls back to the current session
+func (s *TeacherAllocationService) resolveSession(id string) (uuid.UUID, error) {
+	if id != "" {
+		sessionID, err := uuid.Parse(id)
+		if err != nil {
+			return uuid.Nil, errors.New("invalid academic session ID")
+		}
+		return sessionID, nil
+	}
+
+	var session models.AcademicSession
+	if err := s.db.Where("is_current = ? AND deleted_at IS NULL", true).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, errors.New("current academic session not found")
+		}
+		return uuid.Nil, errors.New("failed to fetch current academic session: " + err.Error())
+	}
+	return session.ID, nil
+}
+
+// verifyTeacher checks the user exists and holds a teaching role
+func (s *TeacherAllocationService) verifyTeacher(teacherID uuid.UUID) error {
+	var teacher models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", teacherID).First(&teacher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("teacher not found")
+		}
+		return errors.New("failed to verify teacher: " + err.Error())
+	}
+
+	for _, role := range teachingRoles {
+		if teacher.Role == role {
+			return nil
+		}
+	}
+	return errors.New("user is not a teacher")
+}
+
+// toAllocationResponse converts model to response DTO
+func (s *TeacherAllocationService) toAllocationResponse(a *models.TeacherAllocation) *dto.TeacherAllocationResponse {
+	response := &dto.TeacherAllocationResponse{
+		ID:                a.ID.String(),
+		TeacherID:         a.TeacherID.String(),
+		SubjectID:         a.SubjectID.String(),
+		ArmID:             a.ArmID.String(),
+		AcademicSessionID: a.AcademicSessionID.String(),
+		PeriodsPerWeek:    a.PeriodsPerWeek,
+		Status:            a.Status,
+		CreatedBy:         a.CreatedBy.String(),
+		CreatedAt:         a.CreatedAt,
+		UpdatedAt:         a.UpdatedAt,
+	}
+
+	if a.Teacher.ID != uuid.Nil {
+		response.TeacherName = strings.TrimSpace(a.Teacher.FirstName + " " + a.Teacher.LastName)
+	}
+	if a.Subject.ID != uuid.Nil {
+		response.SubjectName = a.Subject.Name
+	}
+	if a.Arm.ID != uuid.Nil {
+		response.ArmName = a.Arm.Name
+	}
+
+	return response
+}
@@ -0,0 +1,553 @@
+// services/timetable_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"crm-go/dto"
+	"crm-go/models"
+)
+
+const (
+	defaultDaysPerWeek   = 5
+	defaultPeriodsPerDay = 8
+)
+
+var dayNames = []string{"", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+type TimetableService struct {
+	db *gorm.DB
+}
+
+func NewTimetableService(db *gorm.DB) *TimetableService {
+	return &TimetableService{db: db}
+}
+
+// slotKey identifies a period of the week for an arm or teacher
+type slotKey struct {
+	owner  uuid.UUID
+	day    int
+	period int
+}
+
+// dayKey identifies a day of the week for an arm or teacher
+type dayKey struct {
+	owner uuid.UUID
+	day   int
+}
+
+// subjectDayKey identifies how often a subject is taught to an arm on a day
+type subjectDayKey struct {
+	arm     uuid.UUID
+	subject uuid.UUID
+	day     int
+}
+
+// GenerateTimetable assigns periods to every active allocation of a session
+func (s *TimetableService) GenerateTimetable(req *dto.GenerateTimetableRequest, userID uuid.UUID) (*dto.GenerateTimetableResponse, error) {
+	sessionID, err := uuid.Parse(req.AcademicSessionID)
+	if err != nil {
+		return nil, errors.New("invalid academic session ID")
+	}
+
+	var session models.AcademicSession
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("academic session not found")
+		}
+		return nil, errors.New("failed to fetch academic session: " + err.Error())
+	}
+
+	days := req.DaysPerWeek
+	if days == 0 {
+		days = defaultDaysPerWeek
+	}
+	periods := req.PeriodsPerDay
+	if periods == 0 {
+		periods = defaultPeriodsPerDay
+	}
+
+	// Load the allocations to schedule
+	query := s.db.Preload("Subject").Preload("Teacher").Preload("Arm").
+		Where("academic_session_id = ? AND status = ? AND deleted_at IS NULL", sessionID, "active")
+	if len(req.ArmIDs) > 0 {
+		armIDs := make([]uuid.UUID, 0, len(req.ArmIDs))
+		for _, id := range req.ArmIDs {
+			armID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, errors.New("invalid arm ID: " + id)
+			}
+			armIDs = append(armIDs, armID)
+		}
+		query = query.Where("arm_id IN ?", armIDs)
+	}
+
+	var allocations []models.TeacherAllocation
+	if err := query.Find(&allocations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+	if len(allocations) == 0 {
+		return nil, errors.New("no active teacher allocations found for this session")
+	}
+
+	targetArms := make(map[uuid.UUID]bool)
+	teacherLoad := make(map[uuid.UUID]int)
+	required := 0
+	for _, a := range allocations {
+		targetArms[a.ArmID] = true
+		teacherLoad[a.TeacherID] += a.PeriodsPerWeek
+		required += a.PeriodsPerWeek
+	}
+	armIDs := make([]uuid.UUID, 0, len(targetArms))
+	for id := range targetArms {
+		armIDs = append(armIDs, id)
+	}
+
+	if !req.ReplaceExisting && !req.DryRun {
+		var existing int64
+		if err := s.db.Model(&models.TimetableSlot{}).
+			Where("academic_session_id = ? AND arm_id IN ?", sessionID, armIDs).
+			Count(&existing).Error; err != nil {
+			return nil, errors.New("failed to check existing timetable: " + err.Error())
+		}
+		if existing > 0 {
+			return nil, errors.New("timetable already exists for one or more arms; set replace_existing to regenerate")
+		}
+	}
+
+	// Teachers stay busy in arms that are not being regenerated
+	teacherBusy := make(map[slotKey]bool)
+	var kept []models.TimetableSlot
+	if err := s.db.Where("academic_session_id = ? AND arm_id NOT IN ?", sessionID, armIDs).Find(&kept).Error; err != nil {
+		return nil, errors.New("failed to load existing timetable: " + err.Error())
+	}
+	for _, slot := range kept {
+		teacherBusy[slotKey{slot.TeacherID, slot.DayOfWeek, slot.Period}] = true
+	}
+
+	// Teacher availability constraints
+	var blocks []models.TeacherUnavailability
+	if err := s.db.Where("academic_session_id = ?", sessionID).Find(&blocks).Error; err != nil {
+		return nil, errors.New("failed to load teacher availability: " + err.Error())
+	}
+	unavailable := make(map[slotKey]bool)
+	for _, b := range blocks {
+		if b.Period == 0 {
+			for p := 1; p <= periods; p++ {
+				unavailable[slotKey{b.TeacherID, b.DayOfWeek, p}] = true
+			}
+			continue
+		}
+		unavailable[slotKey{b.TeacherID, b.DayOfWeek, b.Period}] = true
+	}
+
+	// Most constrained allocations first
+	sort.SliceStable(allocations, func(i, j int) bool {
+		a, b := allocations[i], allocations[j]
+		if a.PeriodsPerWeek != b.PeriodsPerWeek {
+			return a.PeriodsPerWeek > b.PeriodsPerWeek
+		}
+		if teacherLoad[a.TeacherID] != teacherLoad[b.TeacherID] {
+			return teacherLoad[a.TeacherID] > teacherLoad[b.TeacherID]
+		}
+		if a.Arm.Name != b.Arm.Name {
+			return a.Arm.Name < b.Arm.Name
+		}
+		return a.Subject.Name < b.Subject.Name
+	})
+
+	armBusy := make(map[slotKey]bool)
+	subjectPerDay := make(map[subjectDayKey]int)
+	teacherPerDay := make(map[dayKey]int)
+
+	now := time.Now()
+	var slots []models.TimetableSlot
+	response := &dto.GenerateTimetableResponse{
+		AcademicSessionID: sessionID.String(),
+		DryRun:            req.DryRun,
+		DaysPerWeek:       days,
+		PeriodsPerDay:     periods,
+		ArmCount:          len(targetArms),
+		RequiredPeriods:   required,
+		Unplaced:          []dto.UnplacedPeriod{},
+	}
+
+	for i := range allocations {
+		a := &allocations[i]
+		missing := 0
+
+		for n := 0; n < a.PeriodsPerWeek; n++ {
+			bestDay, bestPeriod, bestScore := 0, 0, -1
+			for day := 1; day <= days; day++ {
+				for period := 1; period <= periods; period++ {
+					if armBusy[slotKey{a.ArmID, day, period}] ||
+						teacherBusy[slotKey{a.TeacherID, day, period}] ||
+						unavailable[slotKey{a.TeacherID, day, period}] {
+						continue
+					}
+
+					// Spread a subject across the week, then even out the teacher's days
+					score := subjectPerDay[subjectDayKey{a.ArmID, a.SubjectID, day}]*1000 +
+						teacherPerDay[dayKey{a.TeacherID, day}]*20 +
+						period
+					if bestScore < 0 || score < bestScore {
+						bestDay, bestPeriod, bestScore = day, period, score
+					}
+				}
+			}
+
+			if bestScore < 0 {
+				missing++
+				continue
+			}
+
+			armBusy[slotKey{a.ArmID, bestDay, bestPeriod}] = true
+			teacherBusy[slotKey{a.TeacherID, bestDay, bestPeriod}] = true
+			subjectPerDay[subjectDayKey{a.ArmID, a.SubjectID, bestDay}]++
+			teacherPerDay[dayKey{a.TeacherID, bestDay}]++
+
+			slots = append(slots, models.TimetableSlot{
+				ID:                uuid.New(),
+				AcademicSessionID: sessionID,
+				ArmID:             a.ArmID,
+				AllocationID:      a.ID,
+				SubjectID:         a.SubjectID,
+				TeacherID:         a.TeacherID,
+				DayOfWeek:         bestDay,
+				Period:            bestPeriod,
+				GeneratedBy:       userID,
+				CreatedAt:         now,
+				Arm:               a.Arm,
+				Subject:           a.Subject,
+				Teacher:           a.Teacher,
+			})
+		}
+
+		if missing > 0 {
+			response.Unplaced = append(response.Unplaced, dto.UnplacedPeriod{
+				AllocationID: a.ID.String(),
+				ArmID:        a.ArmID.String(),
+				SubjectID:    a.SubjectID.String(),
+				TeacherID:    a.TeacherID.String(),
+				Missing:      missing,
+			})
+		}
+	}
+	response.PlacedPeriods = len(slots)
+
+	if req.DryRun {
+		sortSlots(slots)
+		response.Slots = make([]dto.TimetableSlotResponse, len(slots))
+		for i := range slots {
+			response.Slots[i] = *s.toSlotResponse(&slots[i])
+		}
+		return response, nil
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("academic_session_id = ? AND arm_id IN ?", sessionID, armIDs).
+		Delete(&models.TimetableSlot{}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to clear existing timetable: " + err.Error())
+	}
+
+	if len(slots) > 0 {
+		if err := tx.Omit("Arm", "Subject", "Teacher").CreateInBatches(&slots, 200).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to save timetable: " + err.Error())
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to save timetable: " + err.Error())
+	}
+
+	return response, nil
+}
+
+// GetArmTimetable retrieves the weekly timetable of an arm
+func (s *TimetableService) GetArmTimetable(armID string, params *dto.TimetableQueryParams) (*dto.TimetableViewResponse, error) {
+	aID, err := uuid.Parse(armID)
+	if err != nil {
+		return nil, errors.New("invalid arm ID")
+	}
+
+	var arm models.Arm
+	if err := s.db.Preload("Grade").Where("id = ? AND deleted_at IS NULL", aID).First(&arm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("arm not found")
+		}
+		return nil, errors.New("failed to fetch arm: " + err.Error())
+	}
+
+	sessionID := arm.Grade.AcademicSessionID
+	if params.AcademicSessionID != "" {
+		if sessionID, err = uuid.Parse(params.AcademicSessionID); err != nil {
+			return nil, errors.New("invalid academic session ID")
+		}
+	}
+
+	var slots []models.TimetableSlot
+	if err := s.db.Preload("Arm").Preload("Subject").Preload("Teacher").
+		Where("arm_id = ? AND academic_session_id = ?", aID, sessionID).
+		Find(&slots).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch timetable: %w", err)
+	}
+
+	return s.buildView("arm", aID, sessionID, slots), nil
+}
+
+// GetTeacherTimetable retrieves the weekly timetable of a teacher
+func (s *TimetableService) GetTeacherTimetable(teacherID string, params *dto.TimetableQueryParams) (*dto.TimetableViewResponse, error) {
+	tID, err := uuid.Parse(teacherID)
+	if err != nil {
+		return nil, errors.New("invalid teacher ID")
+	}
+
+	var teacher models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", tID).First(&teacher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("teacher not found")
+		}
+		return nil, errors.New("failed to verify teacher: " + err.Error())
+	}
+
+	sessionID, err := s.resolveSession(params.AcademicSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var slots []models.TimetableSlot
+	if err := s.db.Preload("Arm").Preload("Subject").Preload("Teacher").
+		Where("teacher_id = ? AND academic_session_id = ?", tID, sessionID).
+		Find(&slots).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch timetable: %w", err)
+	}
+
+	return s.buildView("teacher", tID, sessionID, slots), nil
+}
+
+// ClearArmTimetable removes every period of an arm's timetable
+func (s *TimetableService) ClearArmTimetable(armID string) error {
+	aID, err := uuid.Parse(armID)
+	if err != nil {
+		return errors.New("invalid arm ID")
+	}
+
+	if err := s.db.Where("arm_id = ?", aID).Delete(&models.TimetableSlot{}).Error; err != nil {
+		return errors.New("failed to clear timetable: " + err.Error())
+	}
+	return nil
+}
+
+// CreateUnavailability blocks a teacher from being scheduled on a day or period
+func (s *TimetableService) CreateUnavailability(req *dto.CreateUnavailabilityRequest, userID uuid.UUID) (*dto.UnavailabilityResponse, error) {
+	teacherID, err := uuid.Parse(req.TeacherID)
+	if err != nil {
+		return nil, errors.New("invalid teacher ID")
+	}
+	sessionID, err := uuid.Parse(req.AcademicSessionID)
+	if err != nil {
+		return nil, errors.New("invalid academic session ID")
+	}
+
+	var teacher models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", teacherID).First(&teacher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("teacher not found")
+		}
+		return nil, errors.New("failed to verify teacher: " + err.Error())
+	}
+
+	var session models.AcademicSession
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("academic session not found")
+		}
+		return nil, errors.New("failed to fetch academic session: " + err.Error())
+	}
+
+	block := &models.TeacherUnavailability{
+		ID:                uuid.New(),
+		TeacherID:         teacherID,
+		AcademicSessionID: sessionID,
+		DayOfWeek:         req.DayOfWeek,
+		Period:            req.Period,
+		Reason:            strings.TrimSpace(req.Reason),
+		CreatedBy:         userID,
+		CreatedAt:         time.Now(),
+	}
+	if err := s.db.Create(block).Error; err != nil {
+		return nil, errors.New("failed to save unavailability: " + err.Error())
+	}
+
+	return s.toUnavailabilityResponse(block), nil
+}
+
+// GetTeacherUnavailability lists the blocked periods of a teacher
+func (s *TimetableService) GetTeacherUnavailability(teacherID string, params *dto.TimetableQueryParams) ([]dto.UnavailabilityResponse, error) {
+	tID, err := uuid.Parse(teacherID)
+	if err != nil {
+		return nil, errors.New("invalid teacher ID")
+	}
+
+	query := s.db.Where("teacher_id = ?", tID)
+	if params.AcademicSessionID != "" {
+		sessionID, err := uuid.Parse(params.AcademicSessionID)
+		if err != nil {
+			return nil, errors.New("invalid academic session ID")
+		}
+		query = query.Where("academic_session_id = ?", sessionID)
+	}
+
+	var blocks []models.TeacherUnavailability
+	if err := query.Order("day_of_week ASC, period ASC").Find(&blocks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch unavailability: %w", err)
+	}
+
+	responses := make([]dto.UnavailabilityResponse, len(blocks))
+	for i := range blocks {
+		responses[i] = *s.toUnavailabilityResponse(&blocks[i])
+	}
+	return responses, nil
+}
+
+// DeleteUnavailability removes a blocked period
+func (s *TimetableService) DeleteUnavailability(id string) error {
+	blockID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.New("invalid unavailability ID")
+	}
+
+	result := s.db.Where("id = ?", blockID).Delete(&models.TeacherUnavailability{})
+	if result.Error != nil {
+		return errors.New("failed to delete unavailability: " + result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("unavailability not found")
+	}
+	return nil
+}
+
+// buildView groups slots into a day-by-day weekly grid
+func (s *TimetableService) buildView(ownerType string, ownerID, sessionID uuid.UUID, slots []models.TimetableSlot) *dto.TimetableViewResponse {
+	sortSlots(slots)
+
+	view := &dto.TimetableViewResponse{
+		OwnerType:         ownerType,
+		OwnerID:           ownerID.String(),
+		AcademicSessionID: sessionID.String(),
+		TotalPeriods:      len(slots),
+		Days:              []dto.TimetableDayResponse{},
+	}
+
+	for i := range slots {
+		slot := s.toSlotResponse(&slots[i])
+		last := len(view.Days) - 1
+		if last < 0 || view.Days[last].DayOfWeek != slot.DayOfWeek {
+			view.Days = append(view.Days, dto.TimetableDayResponse{
+				DayOfWeek: slot.DayOfWeek,
+				DayName:   slot.DayName,
+			})
+			last++
+		}
+		view.Days[last].Periods = append(view.Days[last].Periods, *slot)
+	}
+
+	return view
+}
+
+// resolveSession parses the given session ID or falls back to the current session
+func (s *TimetableService) resolveSession(id string) (uuid.UUID, error) {
+	if id != "" {
+		sessionID, err := uuid.Parse(id)
+		if err != nil {
+			return uuid.Nil, errors.New("invalid academic session ID")
+		}
+		return sessionID, nil
+	}
+
+	var session models.AcademicSession
+	if err := s.db.Where("is_current = ? AND deleted_at IS NULL", true).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, errors.New("current academic session not found")
+		}
+		return uuid.Nil, errors.New("failed to fetch current academic session: " + err.Error())
+	}
+	return session.ID, nil
+}
+
+// sortSlots orders slots by day, period and arm
+func sortSlots(slots []models.TimetableSlot) {
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].DayOfWeek != slots[j].DayOfWeek {
+			return slots[i].DayOfWeek < slots[j].DayOfWeek
+		}
+		if slots[i].Period != slots[j].Period {
+			return slots[i].Period < slots[j].Period
+		}
+		return slots[i].Arm.Name < slots[j].Arm.Name
+	})
+}
+
+// dayName returns the display name of a day of the week
+func dayName(day int) string {
+	if day < 1 || day >= len(dayNames) {
+		return ""
+	}
+	return dayNames[day]
+}
+
+// toSlotResponse converts model to response DTO
+func (s *TimetableService) toSlotResponse(slot *models.TimetableSlot) *dto.TimetableSlotResponse {
+	response := &dto.TimetableSlotResponse{
+		ID:           slot.ID.String(),
+		DayOfWeek:    slot.DayOfWeek,
+		DayName:      dayName(slot.DayOfWeek),
+		Period:       slot.Period,
+		ArmID:        slot.ArmID.String(),
+		SubjectID:    slot.SubjectID.String(),
+		TeacherID:    slot.TeacherID.String(),
+		AllocationID: slot.AllocationID.String(),
+	}
+
+	if slot.Arm.ID != uuid.Nil {
+		response.ArmName = slot.Arm.Name
+	}
+	if slot.Subject.ID != uuid.Nil {
+		response.SubjectName = slot.Subject.Name
+	}
+	if slot.Teacher.ID != uuid.Nil {
+		response.TeacherName = strings.TrimSpace(slot.Teacher.FirstName + " " + slot.Teacher.LastName)
+	}
+
+	return response
+}
+
+// toUnavailabilityResponse converts model to response DTO
+func (s *TimetableService) toUnavailabilityResponse(b *models.TeacherUnavailability) *dto.UnavailabilityResponse {
+	return &dto.UnavailabilityResponse{
+		ID:                b.ID.String(),
+		TeacherID:         b.TeacherID.String(),
+		AcademicSessionID: b.AcademicSessionID.String(),
+		DayOfWeek:         b.DayOfWeek,
+		DayName:           dayName(b.DayOfWeek),
+		Period:            b.Period,
+		Reason:            b.Reason,
+	}
+}
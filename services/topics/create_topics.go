@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"crm-go/models"
+	rollupServices "crm-go/services/rollup"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -82,6 +83,11 @@ func (s *CreateLessonService) CreateLessonWithTx(tx *gorm.DB, req models.LessonI
 		return nil, err
 	}
 
+	// Keep the course's lesson count in step
+	if err := rollupServices.RecomputeCourses(tx, lesson.CourseID); err != nil {
+		return nil, err
+	}
+
 	// Convert to response
 	response := s.lessonToResponse(&lesson, req.TutorID)
 	return response, nil
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"crm-go/models"
+	rollupServices "crm-go/services/rollup"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -37,6 +38,18 @@ func (s *UpdateLessonService) UpdateLessonWithTx(tx *gorm.DB, lessonID uuid.UUID
 		return nil, errors.New("module does not belong to this course")
 	}
 
+	// Ensure the order number is free in the target module
+	var lessonWithSameOrder models.Lesson
+	err := tx.Where("module_id = ? AND \"order\" = ? AND id <> ?", req.ModuleID, req.Order, lesson.ID).
+		First(&lessonWithSameOrder).Error
+	if err == nil {
+		return nil, errors.New("a lesson with this order number already exists in this module")
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	previousCourseID := lesson.CourseID
+
 	// Update fields
 	lesson.CourseID = req.CourseID
 	lesson.ModuleID = req.ModuleID
@@ -51,6 +64,11 @@ func (s *UpdateLessonService) UpdateLessonWithTx(tx *gorm.DB, lessonID uuid.UUID
 		return nil, err
 	}
 
+	// Lesson counts change when a lesson moves between courses
+	if err := rollupServices.RecomputeCourses(tx, previousCourseID, lesson.CourseID); err != nil {
+		return nil, err
+	}
+
 	// Convert to response
 	response := s.lessonToResponse(&lesson, req.TutorID)
 	return response, nil
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"crm-go/models"
 	"crm-go/dto"
+	sessionServices "crm-go/services/sessions"
 )
 
 type UserService struct {
@@ -158,13 +160,81 @@ func (s *UserService) DeleteUser(id string) error {
 		return errors.New("cannot delete user: student has guardians assigned")
 	}
 
-	if err := s.db.Delete(&user).Error; err != nil {
+	// A removed account is signed out everywhere together with the delete
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Delete(&user).Error; err != nil {
+		tx.Rollback()
 		return errors.New("failed to delete user: " + err.Error())
 	}
+	if _, err := sessionServices.RevokeUserSessions(tx, user.ID, "account_deactivated"); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to commit transaction: " + err.Error())
+	}
 
 	return nil
 }
 
+// UpdateUserStatus activates or deactivates a user. A deactivated account is signed out
+// everywhere together with the change.
+func (s *UserService) UpdateUserStatus(id string, req *dto.UpdateUserStatusRequest) (*dto.UserResponse, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+
+	// Start transaction
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var deactivatedAt *time.Time
+	if !*req.IsActive {
+		now := time.Now()
+		deactivatedAt = &now
+	}
+	if err := tx.Model(&user).Updates(map[string]interface{}{
+		"is_active":      *req.IsActive,
+		"deactivated_at": deactivatedAt,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update user status: " + err.Error())
+	}
+	if !*req.IsActive {
+		if _, err := sessionServices.RevokeUserSessions(tx, user.ID, "account_deactivated"); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	response := s.toUserResponse(&user)
+	return &response, nil
+}
+
 // toUserResponse converts model to response DTO
 func (s *UserService) toUserResponse(user *models.User) dto.UserResponse {
 	return dto.UserResponse{
@@ -0,0 +1,99 @@
+// services/email_verification_service.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"crm-go/config"
+	"crm-go/models"
+	"crm-go/utils"
+)
+
+var cfg = config.LoadEnv()
+
+// EmailVerificationService confirms that users own the address they signed up with, using
+// signed links so nothing needs to be stored until the link is used
+type EmailVerificationService struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationService(db *gorm.DB) *EmailVerificationService {
+	return &EmailVerificationService{db: db}
+}
+
+// SendVerification emails the user a signed link to confirm their address
+func (s *EmailVerificationService) SendVerification(user *models.User) error {
+	token, expiresAt, err := utils.GenerateEmailVerificationToken(user.ID.String(), user.Email)
+	if err != nil {
+		return errors.New("failed to create verification link: " + err.Error())
+	}
+
+	link := cfg.FrontendURL + "/verify-email?token=" + token
+	body := fmt.Sprintf("<p>Hello %s,</p><p>Confirm your email address to finish setting up your account.</p>"+
+		"<a href='%s'>Verify my email</a>"+
+		"<p>The link expires on %s. If you did not create an account, ignore this email.</p>",
+		user.FirstName, link, expiresAt.Format(time.RFC1123))
+	if err := utils.SendEmail(user.Email, "Verify your email - Go CRM", body); err != nil {
+		return errors.New("failed to send verification email: " + err.Error())
+	}
+	return nil
+}
+
+// Verify marks the user named by a verification link as verified. Using a link again is harmless.
+func (s *EmailVerificationService) Verify(token string) (*models.User, error) {
+	claims, err := utils.ParseEmailVerificationToken(token)
+	if err != nil {
+		return nil, errors.New("invalid or expired verification link")
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", claims.Subject).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid or expired verification link")
+		}
+		return nil, errors.New("failed to fetch user: " + err.Error())
+	}
+	// A link sent before the address changed does not verify the new one
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return nil, errors.New("invalid or expired verification link")
+	}
+	if user.IsVerified {
+		return &user, nil
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&user).Updates(map[string]interface{}{
+		"is_verified": true,
+		"verified_at": now,
+	}).Error; err != nil {
+		return nil, errors.New("failed to verify email: " + err.Error())
+	}
+	return &user, nil
+}
+
+// Resend sends a fresh link to an unverified account. It succeeds silently when the address
+// is unknown or already verified so callers cannot probe for accounts.
+func (s *EmailVerificationService) Resend(email string) error {
+	var user models.User
+	if err := s.db.Where("email = ?", strings.TrimSpace(email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return errors.New("failed to fetch user: " + err.Error())
+	}
+	if user.IsVerified {
+		return nil
+	}
+
+	if err := s.SendVerification(&user); err != nil {
+		log.Printf("⚠️ Could not resend verification email to user %s: %v", user.ID, err)
+		return err
+	}
+	return nil
+}
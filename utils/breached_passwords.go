@@ -0,0 +1,63 @@
+// utils/breached_passwords.go
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsBreachedPassword reports whether a password appears in the local breached-password list at
+// BREACHED_PASSWORDS_PATH. The path is either a directory of k-anonymity range files, where
+// ABCDE.txt holds "SUFFIX:COUNT" lines for every hash starting ABCDE, or one file of full SHA-1
+// hashes. It always reports false when no path is configured.
+func IsBreachedPassword(password string) (bool, error) {
+	if cfg.BreachedPasswordsPath == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	info, err := os.Stat(cfg.BreachedPasswordsPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	if info.IsDir() {
+		return scanHashList(filepath.Join(cfg.BreachedPasswordsPath, hash[:5]+".txt"), hash[5:], true)
+	}
+	return scanHashList(cfg.BreachedPasswordsPath, hash, false)
+}
+
+// scanHashList looks for a hash, or the suffix of one, at the start of a line. Counts after a
+// colon are ignored. A missing range file means no breached password shares the prefix.
+func scanHashList(path string, want string, rangeFile bool) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if rangeFile && errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+		if strings.EqualFold(line, want) {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,46 @@
+// utils/captcha.go
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var captchaClient = &http.Client{Timeout: 10 * time.Second}
+
+// CaptchaEnabled reports whether CAPTCHA tokens can be checked
+func CaptchaEnabled() bool {
+	return cfg.CaptchaSecret != ""
+}
+
+// VerifyCaptcha checks a CAPTCHA response token with the provider's siteverify endpoint.
+// reCAPTCHA, hCaptcha and Turnstile all accept the same form fields.
+func VerifyCaptcha(token, remoteIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	form := url.Values{}
+	form.Set("secret", cfg.CaptchaSecret)
+	form.Set("response", token)
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	resp, err := captchaClient.PostForm(cfg.CaptchaVerifyURL, form)
+	if err != nil {
+		return false, fmt.Errorf("failed to reach CAPTCHA provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to read CAPTCHA response: %w", err)
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,75 @@
+// utils/email_verification.go
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// EmailVerificationClaims are carried by the signed link that confirms a user owns an
+// address. The subject is the user ID.
+type EmailVerificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// emailVerificationAudience differs from the access token audience so a verification link
+// cannot be used as an access token, or the other way round
+func emailVerificationAudience() string {
+	return cfg.JWTAudience + ":email-verification"
+}
+
+// GenerateEmailVerificationToken signs a token confirming that the user owns email. A token
+// stops working if the user's email changes.
+func GenerateEmailVerificationToken(userID string, email string) (string, time.Time, error) {
+	key, err := keys.current()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Hour * time.Duration(cfg.EmailVerificationHours))
+	claims := EmailVerificationClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.JWTIssuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{emailVerificationAudience()},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	signed, err := token.SignedString(key.private)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseEmailVerificationToken verifies a token from GenerateEmailVerificationToken
+func ParseEmailVerificationToken(tokenString string) (*EmailVerificationClaims, error) {
+	claims := &EmailVerificationClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(cfg.JWTIssuer),
+		jwt.WithAudience(emailVerificationAudience()),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	if claims.Subject == "" || claims.Email == "" {
+		return nil, errors.New("token has no subject")
+	}
+	return claims, nil
+}
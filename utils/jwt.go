@@ -1,22 +1,88 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte(cfg.JWTSecret) // 🔥 change this to env variable in prod
+// Claims are the claims carried by access tokens; the subject is the user ID and sid the
+// session the token was issued for
+type Claims struct {
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Generate a short-lived access token for a session, signed with the active signing key.
+// It returns the token and when it expires.
+func GenerateJWT(userID string, email string, role string, sessionID string) (string, time.Time, error) {
+	key, err := keys.current()
+	if err != nil {
+		return "", time.Time{}, err
+	}
 
-// Generate JWT token
-func GenerateJWT(userID string, email string, role string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"email": email,
-		"role":    role,
-		"exp":     time.Now().Add(time.Hour * time.Duration(cfg.JWTExpire)).Unix(), 
+	now := time.Now()
+	expiresAt := now.Add(time.Minute * time.Duration(cfg.AccessTokenMinutes))
+	claims := Claims{
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.JWTIssuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{cfg.JWTAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	signed, err := token.SignedString(key.private)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseJWT verifies a token against the key named in its kid header and checks the
+// issuer, audience and lifetime
+func ParseJWT(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(cfg.JWTIssuer),
+		jwt.WithAudience(cfg.JWTAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	return claims, nil
+}
+
+// verificationKey finds the public key named in a token's kid header
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := keys.verification(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.public, nil
 }
@@ -0,0 +1,104 @@
+// utils/pdf.go
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pdfPageWidth    = 595 // A4 in points
+	pdfPageHeight   = 842
+	pdfMargin       = 56
+	pdfFontSize     = 11
+	pdfTitleSize    = 16
+	pdfLineHeight   = 16
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin - 2*pdfLineHeight) / pdfLineHeight
+)
+
+// BuildTextPDF renders a title and plain text lines as a simple multi-page PDF document
+// using the built-in Helvetica font, so no font files or external libraries are needed.
+func BuildTextPDF(title string, lines []string) []byte {
+	// Split lines into pages
+	var pages [][]string
+	for start := 0; start < len(lines) || start == 0; start += pdfLinesPerPage {
+		end := start + pdfLinesPerPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+		pages = append(pages, lines[start:end])
+		if end == len(lines) {
+			break
+		}
+	}
+
+	// Object numbers: 1 catalog, 2 pages, 3 font, 4 bold font, then a page and content stream per page
+	var objects []string
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+i*2))
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+
+	for i, pageLines := range pages {
+		var content bytes.Buffer
+		y := pdfPageHeight - pdfMargin
+		if i == 0 {
+			fmt.Fprintf(&content, "BT /F2 %d Tf %d %d Td (%s) Tj ET\n", pdfTitleSize, pdfMargin, y, pdfEscape(title))
+			y -= 2 * pdfLineHeight
+		}
+		for _, line := range pageLines {
+			fmt.Fprintf(&content, "BT /F1 %d Tf %d %d Td (%s) Tj ET\n", pdfFontSize, pdfMargin, y, pdfEscape(line))
+			y -= pdfLineHeight
+		}
+		fmt.Fprintf(&content, "BT /F1 8 Tf %d %d Td (Page %d of %d) Tj ET\n", pdfMargin, pdfMargin/2, i+1, len(pages))
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 6+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+
+	// Write objects and the cross-reference table
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
+
+// pdfEscape escapes text for a PDF string literal and drops characters Helvetica cannot show
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString("    ")
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
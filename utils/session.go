@@ -11,6 +11,10 @@ func CleanExpiredSessions() {
 	// Run this as a cron job to clean up expired sessions
 	config.DB.Where("expires_at < ? OR is_active = false", time.Now()).
 		Delete(&models.UserSession{})
+	config.DB.Where("expires_at < ?", time.Now()).
+		Delete(&models.MFAChallenge{})
+	config.DB.Where("expires_at < ?", time.Now()).
+		Delete(&models.PasswordReset{})
 }
 
 func GetUserActiveSessions(userID string) ([]models.UserSession, error) {
@@ -0,0 +1,396 @@
+// utils/signing_keys.go
+package utils
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"crm-go/config"
+	"crm-go/models"
+)
+
+const (
+	// keyRingTTL bounds how long a rotation on another instance goes unnoticed
+	keyRingTTL = time.Minute
+	// keyRingMissInterval throttles reloads triggered by tokens naming an unknown kid
+	keyRingMissInterval = 10 * time.Second
+	rsaKeyBits          = 2048
+)
+
+// JSONWebKey is the public half of a signing key in JWK form (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type signingKey struct {
+	kid     string
+	alg     string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+	jwk     JSONWebKey
+}
+
+// keyRing caches signing keys so tokens are signed and verified without a database round
+// trip. It reloads on a timer, and early when a token names a key it has not seen, so a
+// rotation made on another instance is picked up quickly.
+type keyRing struct {
+	mu         sync.RWMutex
+	signing    *signingKey
+	verify     map[string]*signingKey
+	loadedAt   time.Time
+	lastMissAt time.Time
+}
+
+var keys = &keyRing{verify: map[string]*signingKey{}}
+
+// GenerateSigningKey creates a new RS256 or EdDSA key pair ready to be saved as the active key
+func GenerateSigningKey(algorithm string, createdBy *uuid.UUID) (*models.SigningKey, error) {
+	var private crypto.PrivateKey
+	var public crypto.PublicKey
+	switch algorithm {
+	case "RS256":
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		private, public = key, &key.PublicKey
+	case "EdDSA":
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		private, public = key, pub
+	default:
+		return nil, errors.New("unsupported signing algorithm: " + algorithm)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	jwk, err := publicJWK(algorithm, public)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := thumbprint(jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	privatePEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	sealed, encrypted, err := sealPrivateKey(privatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		KID:         kid,
+		Algorithm:   algorithm,
+		Status:      "active",
+		PublicKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		PrivateKey:  sealed,
+		Encrypted:   encrypted,
+		ActivatedAt: time.Now(),
+		CreatedBy:   createdBy,
+	}, nil
+}
+
+// EnsureSigningKey creates an active signing key on first start and loads the key ring
+func EnsureSigningKey() error {
+	var count int64
+	if err := config.DB.Model(&models.SigningKey{}).Where("status = ?", "active").Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check signing keys: %w", err)
+	}
+	if count == 0 {
+		key, err := GenerateSigningKey(cfg.JWTAlgorithm, nil)
+		if err != nil {
+			return err
+		}
+		// Another instance may have created one first; the unique active index keeps just one
+		if err := config.DB.Create(key).Error; err != nil {
+			log.Printf("⚠️ Signing key not created, using the existing one: %v", err)
+		} else {
+			log.Printf("🔑 Created %s signing key %s", key.Algorithm, key.KID)
+		}
+	}
+	return ReloadSigningKeys()
+}
+
+// ReloadSigningKeys retires keys past their retirement time and reloads the key ring
+func ReloadSigningKeys() error {
+	now := time.Now()
+	if err := config.DB.Model(&models.SigningKey{}).
+		Where("status = ? AND retire_at <= ?", "retiring", now).
+		Updates(map[string]interface{}{"status": "retired", "retired_at": now}).Error; err != nil {
+		return fmt.Errorf("failed to retire signing keys: %w", err)
+	}
+
+	var rows []models.SigningKey
+	if err := config.DB.Where("status IN ?", []string{"active", "retiring"}).
+		Order("activated_at DESC").
+		Find(&rows).Error; err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	verify := make(map[string]*signingKey, len(rows))
+	var signing *signingKey
+	for _, row := range rows {
+		key, err := decodeSigningKey(&row)
+		if err != nil {
+			log.Printf("⚠️ Skipping signing key %s: %v", row.KID, err)
+			continue
+		}
+		verify[key.kid] = key
+		if row.Status == "active" && signing == nil {
+			signing = key
+		}
+	}
+
+	keys.mu.Lock()
+	keys.signing, keys.verify, keys.loadedAt = signing, verify, now
+	keys.mu.Unlock()
+	return nil
+}
+
+// PublicJWKS returns the keys other services should accept tokens from
+func PublicJWKS() (JSONWebKeySet, error) {
+	if err := keys.refresh(false); err != nil {
+		return JSONWebKeySet{}, err
+	}
+
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys.verify))}
+	if keys.signing != nil {
+		set.Keys = append(set.Keys, keys.signing.jwk)
+	}
+	for kid, key := range keys.verify {
+		if keys.signing == nil || kid != keys.signing.kid {
+			set.Keys = append(set.Keys, key.jwk)
+		}
+	}
+	return set, nil
+}
+
+// current returns the key new tokens are signed with
+func (r *keyRing) current() (*signingKey, error) {
+	if err := r.refresh(false); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.signing == nil {
+		return nil, errors.New("no active signing key")
+	}
+	return r.signing, nil
+}
+
+// verification returns the key a token names in its kid header
+func (r *keyRing) verification(kid string) (*signingKey, error) {
+	if kid == "" {
+		return nil, errors.New("token has no key ID")
+	}
+	if err := r.refresh(false); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	key, ok := r.verify[kid]
+	r.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if err := r.refresh(true); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if key, ok := r.verify[kid]; ok {
+		return key, nil
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+// refresh reloads stale keys; miss asks for an early reload, throttled to keyRingMissInterval
+func (r *keyRing) refresh(miss bool) error {
+	r.mu.Lock()
+	now := time.Now()
+	stale := now.Sub(r.loadedAt) > keyRingTTL
+	if miss && !stale {
+		if now.Sub(r.lastMissAt) < keyRingMissInterval {
+			r.mu.Unlock()
+			return nil
+		}
+		r.lastMissAt = now
+		stale = true
+	}
+	if stale {
+		// Claim the reload so concurrent requests keep using the cached keys meanwhile
+		r.loadedAt = now
+	}
+	r.mu.Unlock()
+
+	if !stale {
+		return nil
+	}
+	return ReloadSigningKeys()
+}
+
+func decodeSigningKey(row *models.SigningKey) (*signingKey, error) {
+	privatePEM, err := openPrivateKey(row.PrivateKey, row.Encrypted)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	key := &signingKey{kid: row.KID, alg: row.Algorithm, private: private}
+	switch row.Algorithm {
+	case "RS256":
+		rsaKey, ok := private.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("RS256 key is not an RSA key")
+		}
+		key.method, key.public = jwt.SigningMethodRS256, &rsaKey.PublicKey
+	case "EdDSA":
+		edKey, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("EdDSA key is not an Ed25519 key")
+		}
+		key.method, key.public = jwt.SigningMethodEdDSA, edKey.Public()
+	default:
+		return nil, errors.New("unsupported signing algorithm: " + row.Algorithm)
+	}
+
+	if key.jwk, err = publicJWK(row.Algorithm, key.public); err != nil {
+		return nil, err
+	}
+	key.jwk.Kid = row.KID
+	return key, nil
+}
+
+func publicJWK(algorithm string, public crypto.PublicKey) (JSONWebKey, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: algorithm,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: algorithm,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	}
+	return JSONWebKey{}, errors.New("unsupported public key type")
+}
+
+// thumbprint derives a key ID from the key itself (RFC 7638)
+func thumbprint(jwk JSONWebKey) (string, error) {
+	var canonical string
+	switch jwk.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, jwk.Crv, jwk.X)
+	default:
+		return "", errors.New("unsupported key type: " + jwk.Kty)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// sealPrivateKey encrypts a private key with AES-GCM when an encryption key is configured
+func sealPrivateKey(privatePEM string) (string, bool, error) {
+	if cfg.SigningKeyEncryptionKey == "" {
+		return privatePEM, false, nil
+	}
+	gcm, err := signingKeyCipher()
+	if err != nil {
+		return "", false, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", false, fmt.Errorf("failed to seal private key: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(privatePEM), nil)
+	return base64.StdEncoding.EncodeToString(sealed), true, nil
+}
+
+func openPrivateKey(stored string, encrypted bool) (string, error) {
+	if !encrypted {
+		return stored, nil
+	}
+	if cfg.SigningKeyEncryptionKey == "" {
+		return "", errors.New("key is encrypted but SIGNING_KEY_ENCRYPTION_KEY is not set")
+	}
+	gcm, err := signingKeyCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid sealed private key")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("failed to open private key; check SIGNING_KEY_ENCRYPTION_KEY")
+	}
+	return string(plain), nil
+}
+
+func signingKeyCipher() (cipher.AEAD, error) {
+	secret := sha256.Sum256([]byte(cfg.SigningKeyEncryptionKey))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,124 @@
+// utils/sso.go
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ssoStateLifetime bounds how long a user may spend at the identity provider
+const ssoStateLifetime = 10 * time.Minute
+
+// SSOStateClaims describe a single sign-on attempt. They are signed into a cookie on the
+// browser that started it, and Binding is a hash of the random value sent through the identity
+// provider as the OAuth state or SAML RelayState, so a callback only completes when both match.
+type SSOStateClaims struct {
+	Provider  string `json:"provider"`
+	Binding   string `json:"binding"`
+	Nonce     string `json:"nonce,omitempty"`      // OIDC nonce the ID token must carry
+	Verifier  string `json:"verifier,omitempty"`   // OIDC PKCE code verifier
+	RequestID string `json:"request_id,omitempty"` // SAML AuthnRequest the response must answer
+	jwt.RegisteredClaims
+}
+
+func ssoStateAudience() string {
+	return cfg.JWTAudience + ":sso-state"
+}
+
+// SignSSOState signs the state for a single sign-on attempt with the active signing key
+func SignSSOState(claims SSOStateClaims) (string, error) {
+	key, err := keys.current()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    cfg.JWTIssuer,
+		Audience:  jwt.ClaimStrings{ssoStateAudience()},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ssoStateLifetime)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        uuid.NewString(),
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// ParseSSOState verifies a state from SignSSOState
+func ParseSSOState(tokenString string) (*SSOStateClaims, error) {
+	claims := &SSOStateClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(cfg.JWTIssuer),
+		jwt.WithAudience(ssoStateAudience()),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.Provider == "" || claims.Binding == "" {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+// SealSSOSecret encrypts an identity provider client secret with AES-GCM when
+// SSO_ENCRYPTION_KEY is configured
+func SealSSOSecret(secret string) (string, bool, error) {
+	if cfg.SSOEncryptionKey == "" || secret == "" {
+		return secret, false, nil
+	}
+	gcm, err := ssoCipher()
+	if err != nil {
+		return "", false, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", false, fmt.Errorf("failed to seal client secret: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), true, nil
+}
+
+// OpenSSOSecret reverses SealSSOSecret
+func OpenSSOSecret(stored string, encrypted bool) (string, error) {
+	if !encrypted {
+		return stored, nil
+	}
+	if cfg.SSOEncryptionKey == "" {
+		return "", errors.New("client secret is encrypted but SSO_ENCRYPTION_KEY is not set")
+	}
+	gcm, err := ssoCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid sealed client secret")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("failed to open client secret; check SSO_ENCRYPTION_KEY")
+	}
+	return string(plain), nil
+}
+
+func ssoCipher() (cipher.AEAD, error) {
+	secret := sha256.Sum256([]byte(cfg.SSOEncryptionKey))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSO cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,143 @@
+// utils/totp.go
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpDigits = 6
+	totpPeriod = 30 // seconds per time step
+	totpSkew   = 1  // steps either side of now still accepted, for clock drift
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a new random base32 secret for an authenticator app
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPProvisioningURI builds the otpauth:// URI authenticator apps read from a QR code
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// VerifyTOTP checks a code against the secret (RFC 6238) and returns the time step it
+// matched. Callers store the step and reject codes at or before it so a code works once.
+func VerifyTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateNumericCode returns a random decimal code of the given length, for codes sent by email
+func GenerateNumericCode(digits int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// SealMFASecret encrypts a TOTP secret with AES-GCM when MFA_ENCRYPTION_KEY is configured
+func SealMFASecret(secret string) (string, bool, error) {
+	if cfg.MFAEncryptionKey == "" {
+		return secret, false, nil
+	}
+	gcm, err := mfaCipher()
+	if err != nil {
+		return "", false, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", false, fmt.Errorf("failed to seal TOTP secret: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), true, nil
+}
+
+// OpenMFASecret reverses SealMFASecret
+func OpenMFASecret(stored string, encrypted bool) (string, error) {
+	if !encrypted {
+		return stored, nil
+	}
+	if cfg.MFAEncryptionKey == "" {
+		return "", errors.New("TOTP secret is encrypted but MFA_ENCRYPTION_KEY is not set")
+	}
+	gcm, err := mfaCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid sealed TOTP secret")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("failed to open TOTP secret; check MFA_ENCRYPTION_KEY")
+	}
+	return string(plain), nil
+}
+
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation (RFC 4226 section 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+func mfaCipher() (cipher.AEAD, error) {
+	secret := sha256.Sum256([]byte(cfg.MFAEncryptionKey))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MFA cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
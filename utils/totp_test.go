@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from RFC 6238 appendix B, "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPMatchesRFC6238(t *testing.T) {
+	// The RFC lists eight-digit codes; authenticator apps show the last six
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		now := time.Unix(v.unix, 0)
+		step, ok := VerifyTOTP(rfcSecret, v.code, now)
+		if !ok {
+			t.Errorf("at %d: code %s refused", v.unix, v.code)
+			continue
+		}
+		if step != v.unix/totpPeriod {
+			t.Errorf("at %d: matched step %d, want %d", v.unix, step, v.unix/totpPeriod)
+		}
+	}
+}
+
+func TestVerifyTOTPAllowsOneStepOfDrift(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+	key := []byte("12345678901234567890")
+
+	for offset := int64(-3); offset <= 3; offset++ {
+		code := totpCode(key, current+offset)
+		step, ok := VerifyTOTP(rfcSecret, code, now)
+
+		within := offset >= -totpSkew && offset <= totpSkew
+		if ok != within {
+			t.Errorf("code from %+d steps: accepted = %v, want %v", offset, ok, within)
+		}
+		if ok && step != current+offset {
+			t.Errorf("code from %+d steps matched step %d, want %d", offset, step, current+offset)
+		}
+	}
+}
+
+func TestVerifyTOTPInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	if _, ok := VerifyTOTP(strings.ToLower(rfcSecret), "287082", now); !ok {
+		t.Error("lower-case secret refused")
+	}
+	if _, ok := VerifyTOTP(rfcSecret, " 287082 ", now); !ok {
+		t.Error("code with surrounding spaces refused")
+	}
+	for _, code := range []string{"", "28708", "2870820", "94287082", "abcdef"} {
+		if _, ok := VerifyTOTP(rfcSecret, code, now); ok {
+			t.Errorf("code %q accepted", code)
+		}
+	}
+	if _, ok := VerifyTOTP("not base32!", "287082", now); ok {
+		t.Error("code accepted against an unreadable secret")
+	}
+}
+
+func TestGenerateTOTPSecretRoundTrips(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes, %v; want 20", secret, len(key), err)
+	}
+
+	now := time.Now()
+	if _, ok := VerifyTOTP(secret, totpCode(key, now.Unix()/totpPeriod), now); !ok {
+		t.Error("current code for a new secret refused")
+	}
+}
+
+func TestSealMFASecret(t *testing.T) {
+	saved := cfg.MFAEncryptionKey
+	t.Cleanup(func() { cfg.MFAEncryptionKey = saved })
+
+	cfg.MFAEncryptionKey = ""
+	stored, encrypted, err := SealMFASecret(rfcSecret)
+	if err != nil || encrypted || stored != rfcSecret {
+		t.Errorf("without a key: SealMFASecret() = %q, %v, %v, want the secret as is", stored, encrypted, err)
+	}
+
+	cfg.MFAEncryptionKey = "test-key"
+	stored, encrypted, err = SealMFASecret(rfcSecret)
+	if err != nil || !encrypted || strings.Contains(stored, rfcSecret) {
+		t.Fatalf("with a key: SealMFASecret() = %q, %v, %v, want it sealed", stored, encrypted, err)
+	}
+	if opened, err := OpenMFASecret(stored, true); err != nil || opened != rfcSecret {
+		t.Errorf("OpenMFASecret() = %q, %v, want the original secret", opened, err)
+	}
+
+	cfg.MFAEncryptionKey = "another-key"
+	if _, err := OpenMFASecret(stored, true); err == nil {
+		t.Error("secret opened with the wrong key")
+	}
+	cfg.MFAEncryptionKey = ""
+	if _, err := OpenMFASecret(stored, true); err == nil {
+		t.Error("sealed secret opened without a key")
+	}
+}
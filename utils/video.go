@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProbeVideoDuration returns the length of a remote video in whole seconds using ffprobe.
+// Only http(s) URLs are probed, and it fails when ffprobe is not installed, so callers
+// should treat the result as best effort.
+func ProbeVideoDuration(url string) (int, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return 0, errors.New("only http and https videos can be probed")
+	}
+	path, err := exec.LookPath(cfg.FFProbePath)
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe not available: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path,
+		"-v", "error",
+		"-protocol_whitelist", "http,https,tcp,tls",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		url,
+	).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe video: %w", err)
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil || seconds <= 0 {
+		return 0, errors.New("video duration unavailable")
+	}
+	return int(math.Ceil(seconds)), nil
+}